@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"flowk/internal/secrets"
 	uiserver "flowk/internal/server/ui"
 	"flowk/internal/shared/expansion"
+	"flowk/internal/shared/tracing"
 )
 
 const (
@@ -25,6 +28,11 @@ const (
 	cliColorRed   = "\033[31m"
 )
 
+// cliColor reports whether CLI error output is wrapped in ANSI color codes.
+// main turns it off for -no-color, NO_COLOR or a stderr that is not a
+// terminal.
+var cliColor = true
+
 var (
 	version = "dev"
 	commit  = "none"
@@ -45,32 +53,87 @@ type runArguments struct {
 	flowPath      string
 	beginFromTask string
 	runTaskID     string
+	runTaskIDs    []string
+	tags          []string
+	tagMatch      string
 	runFlowID     string
 	runSubtaskID  string
 	validateOnly  bool
+	strict        bool
 	serveUI       bool
+	watch         bool
 	uiAddress     string
 	uiDir         string
 	flowsDir      string
 	configPath    string
+	uiToken       string
+	uiAllowRemote bool
+	uiTLSCert     string
+	uiTLSKey      string
+	uiSelfSigned  bool
+	uiSaveDir     string
+	uiLang        string
+	uiMaxSubs     int
+	uiMetrics     bool
+	logsDir       string
+	logFormat     string
+	junitPath     string
+	profile       string
+	tracing       config.TracingConfig
 }
 
 func main() {
 	log.SetFlags(0)
 
-	if err := execute(os.Args[0], os.Args[1:]); err != nil {
+	args, noColor := stripNoColorFlag(os.Args[1:])
+	cliColor = useCLIColor(noColor, os.Getenv("NO_COLOR"), os.Stderr)
+
+	if err := execute(os.Args[0], args); err != nil {
 		var usageErr *usageError
 		switch {
 		case errors.Is(err, flag.ErrHelp):
 			return
 		case errors.As(err, &usageErr):
-			fmt.Fprintf(os.Stderr, "%s%v%s\n", cliColorRed, usageErr.err, cliColorReset)
+			fmt.Fprintln(os.Stderr, colorError(usageErr.err.Error()))
 			fmt.Fprintln(os.Stderr, usageErr.helpMessage)
 			os.Exit(1)
 		default:
-			log.Fatalf("%sError: %v%s", cliColorRed, err, cliColorReset)
+			log.Fatal(colorError(fmt.Sprintf("Error: %v", err)))
+		}
+	}
+}
+
+// stripNoColorFlag removes the global -no-color flag, accepted anywhere on
+// the command line and also spelled --no-color, from args.
+func stripNoColorFlag(args []string) ([]string, bool) {
+	remaining := make([]string, 0, len(args))
+	noColor := false
+	for _, arg := range args {
+		if arg == "-no-color" || arg == "--no-color" {
+			noColor = true
+			continue
 		}
+		remaining = append(remaining, arg)
+	}
+	return remaining, noColor
+}
+
+// useCLIColor reports whether error output written to out is colored. Any
+// non-empty NO_COLOR value disables color, following https://no-color.org.
+func useCLIColor(noColor bool, noColorEnv string, out *os.File) bool {
+	if noColor || noColorEnv != "" {
+		return false
 	}
+	info, err := out.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorError wraps an error message in red when cliColor is set.
+func colorError(message string) string {
+	if !cliColor {
+		return message
+	}
+	return cliColorRed + message + cliColorReset
 }
 
 func execute(program string, args []string) error {
@@ -115,15 +178,26 @@ func execute(program string, args []string) error {
 			fmt.Fprintln(os.Stdout, generalHelpMessage(program))
 			return nil
 		}
-		if len(args) > 1 {
-			return &usageError{err: fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " ")), helpMessage: generalHelpMessage(program)}
+		var profile string
+		for i := 1; i < len(args); i++ {
+			value, consumed, err := parseFlagValue(args, &i, "-profile")
+			if err != nil {
+				return &usageError{err: err, helpMessage: generalHelpMessage(program)}
+			}
+			if !consumed {
+				return &usageError{err: fmt.Errorf("unexpected arguments: %s", strings.Join(args[i:], " ")), helpMessage: generalHelpMessage(program)}
+			}
+			profile = value
 		}
-		return printInfo(os.Stdout)
+		return printInfo(os.Stdout, profile)
 
 	case "help":
 		if len(args) > 1 && strings.EqualFold(args[1], "action") {
 			return executeActionHelp(program, args[2:])
 		}
+		if len(args) > 1 && strings.EqualFold(args[1], "actions") {
+			return executeActionHelp(program, append([]string{"-all"}, args[2:]...))
+		}
 		fmt.Fprintln(os.Stdout, generalHelpMessage(program))
 		return nil
 	case "-help", "--help":
@@ -167,6 +241,12 @@ func parseRunArgs(args []string) (runArguments, error) {
 		case "-validate-only":
 			cfg.validateOnly = true
 			continue
+		case "-strict":
+			cfg.strict = true
+			continue
+		case "-watch":
+			cfg.watch = true
+			continue
 		}
 
 		if value, consumed, err := parseFlagValue(args, &i, "-config"); err != nil {
@@ -176,6 +256,31 @@ func parseRunArgs(args []string) (runArguments, error) {
 			continue
 		}
 
+		if value, consumed, err := parseFlagValue(args, &i, "-profile"); err != nil {
+			return runArguments{}, err
+		} else if consumed {
+			cfg.profile = value
+			continue
+		}
+
+		if value, consumed, err := parseFlagValue(args, &i, "-log-format"); err != nil {
+			return runArguments{}, err
+		} else if consumed {
+			format, err := app.ParseLogFormat(value)
+			if err != nil {
+				return runArguments{}, err
+			}
+			cfg.logFormat = format
+			continue
+		}
+
+		if value, consumed, err := parseFlagValue(args, &i, "-junit"); err != nil {
+			return runArguments{}, err
+		} else if consumed {
+			cfg.junitPath = strings.TrimSpace(value)
+			continue
+		}
+
 		if value, consumed, err := parseFlagValue(args, &i, "-flow"); err != nil {
 			return runArguments{}, err
 		} else if consumed {
@@ -197,6 +302,39 @@ func parseRunArgs(args []string) (runArguments, error) {
 			continue
 		}
 
+		if value, consumed, err := parseFlagValue(args, &i, "-run-tasks"); err != nil {
+			return runArguments{}, err
+		} else if consumed {
+			taskIDs, err := parseTaskIDList(value)
+			if err != nil {
+				return runArguments{}, err
+			}
+			cfg.runTaskIDs = taskIDs
+			continue
+		}
+
+		if value, consumed, err := parseFlagValue(args, &i, "-tag-match"); err != nil {
+			return runArguments{}, err
+		} else if consumed {
+			match, err := app.ParseTagMatch(value)
+			if err != nil {
+				return runArguments{}, err
+			}
+			cfg.tagMatch = match
+			continue
+		}
+
+		if value, consumed, err := parseFlagValue(args, &i, "-tag"); err != nil {
+			return runArguments{}, err
+		} else if consumed {
+			tag := strings.TrimSpace(value)
+			if tag == "" {
+				return runArguments{}, errors.New("flag -tag requires a non-empty value")
+			}
+			cfg.tags = append(cfg.tags, tag)
+			continue
+		}
+
 		if value, consumed, err := parseFlagValue(args, &i, "-run-flow"); err != nil {
 			return runArguments{}, err
 		} else if consumed {
@@ -225,18 +363,53 @@ func parseRunArgs(args []string) (runArguments, error) {
 		if cfg.serveUI {
 			return runArguments{}, errors.New("flag -validate-only cannot be combined with -serve-ui")
 		}
-		if strings.TrimSpace(cfg.beginFromTask) != "" || strings.TrimSpace(cfg.runTaskID) != "" || strings.TrimSpace(cfg.runFlowID) != "" || strings.TrimSpace(cfg.runSubtaskID) != "" {
-			return runArguments{}, errors.New("flag -validate-only cannot be combined with -begin-from-task, -run-task, -run-subtask, or -run-flow")
+		if strings.TrimSpace(cfg.beginFromTask) != "" || strings.TrimSpace(cfg.runTaskID) != "" || len(cfg.runTaskIDs) > 0 || strings.TrimSpace(cfg.runFlowID) != "" || strings.TrimSpace(cfg.runSubtaskID) != "" {
+			return runArguments{}, errors.New("flag -validate-only cannot be combined with -begin-from-task, -run-task, -run-tasks, -run-subtask, or -run-flow")
 		}
 	}
 
+	if cfg.junitPath != "" && (cfg.validateOnly || cfg.serveUI) {
+		return runArguments{}, errors.New("flag -junit cannot be combined with -validate-only or -serve-ui")
+	}
+
 	if cfg.flowPath == "" && !cfg.serveUI {
 		return runArguments{}, errors.New("missing required -flow flag")
 	}
 
+	if cfg.watch {
+		if cfg.validateOnly {
+			return runArguments{}, errors.New("flag -watch cannot be combined with -validate-only")
+		}
+		if cfg.flowPath == "" {
+			return runArguments{}, errors.New("flag -watch requires -flow")
+		}
+	}
+
 	if cfg.flowPath == "" {
+		if strings.TrimSpace(cfg.beginFromTask) != "" || strings.TrimSpace(cfg.runTaskID) != "" || len(cfg.runTaskIDs) > 0 || strings.TrimSpace(cfg.runFlowID) != "" || strings.TrimSpace(cfg.runSubtaskID) != "" {
+			return runArguments{}, errors.New("flags -begin-from-task, -run-task, -run-tasks, -run-subtask, and -run-flow require a flow when -flow is not provided")
+		}
+	}
+
+	if cfg.tagMatch != "" && len(cfg.tags) == 0 {
+		return runArguments{}, errors.New("flag -tag-match requires -tag")
+	}
+
+	if len(cfg.tags) > 0 {
+		if cfg.validateOnly {
+			return runArguments{}, errors.New("flag -tag cannot be combined with -validate-only")
+		}
+		if cfg.flowPath == "" {
+			return runArguments{}, errors.New("flag -tag requires a flow when -flow is not provided")
+		}
+		if strings.TrimSpace(cfg.beginFromTask) != "" || strings.TrimSpace(cfg.runTaskID) != "" || len(cfg.runTaskIDs) > 0 || strings.TrimSpace(cfg.runFlowID) != "" || strings.TrimSpace(cfg.runSubtaskID) != "" {
+			return runArguments{}, errors.New("flag -tag cannot be combined with -begin-from-task, -run-task, -run-tasks, -run-subtask, or -run-flow")
+		}
+	}
+
+	if len(cfg.runTaskIDs) > 0 {
 		if strings.TrimSpace(cfg.beginFromTask) != "" || strings.TrimSpace(cfg.runTaskID) != "" || strings.TrimSpace(cfg.runFlowID) != "" || strings.TrimSpace(cfg.runSubtaskID) != "" {
-			return runArguments{}, errors.New("flags -begin-from-task, -run-task, -run-subtask, and -run-flow require a flow when -flow is not provided")
+			return runArguments{}, errors.New("flag -run-tasks cannot be combined with -begin-from-task, -run-task, -run-subtask, or -run-flow")
 		}
 	}
 
@@ -260,14 +433,30 @@ func parseRunArgs(args []string) (runArguments, error) {
 		return runArguments{}, fmt.Errorf("unexpected arguments: %s", strings.Join(positionals, " "))
 	}
 
-	configResult, err := config.LoadFrom(configPath)
+	configResult, err := config.LoadProfile(configPath, cfg.profile)
 	if err != nil {
 		return runArguments{}, err
 	}
-	cfg.uiAddress = fmt.Sprintf("%s:%d", configResult.Config.UI.Host, configResult.Config.UI.Port)
+	cfg.uiAddress = net.JoinHostPort(configResult.Config.UI.Host, strconv.Itoa(configResult.Config.UI.Port))
 	cfg.uiDir = configResult.Config.UI.Dir
 	cfg.flowsDir = configResult.Config.FlowsDir
 	cfg.configPath = configResult.Path
+	cfg.profile = configResult.Profile
+	cfg.uiToken = configResult.Config.UI.Token
+	cfg.uiAllowRemote = configResult.Config.UI.AllowRemote
+	cfg.uiTLSCert = configResult.Config.UI.TLSCertFile
+	cfg.uiTLSKey = configResult.Config.UI.TLSKeyFile
+	cfg.uiSelfSigned = configResult.Config.UI.TLSSelfSigned
+	cfg.uiSaveDir = configResult.Config.UI.SaveDir
+	cfg.uiLang = configResult.Config.UI.Lang
+	cfg.uiMaxSubs = configResult.Config.UI.MaxEventSubscribers
+	cfg.uiMetrics = configResult.Config.UI.Metrics
+	cfg.logsDir = configResult.Config.Defaults.LogDir
+	if cfg.logFormat == "" {
+		cfg.logFormat = configResult.Config.Defaults.LogFormat
+	}
+	cfg.tracing = configResult.Config.Tracing
+	config.SetActionDefaults(configResult.Config.Defaults)
 
 	resolver, err := secrets.BuildResolver(secrets.Config{
 		Provider: configResult.Config.Secrets.Provider,
@@ -287,11 +476,11 @@ func parseRunArgs(args []string) (runArguments, error) {
 }
 
 func generalHelpMessage(program string) string {
-	return fmt.Sprintf("Usage:\n  %[1]s <command> [options]\n\nAvailable commands:\n  run               Execute a test flow.\n  version           Show build information.\n  info              Show configuration paths and defaults.\n  help              Show this help message.\n\nHelpful references:\n  %[1]s run -help           More information about running flows.\n  %[1]s help action [name]  List actions or display the fields for an action.", program)
+	return fmt.Sprintf("Usage:\n  %[1]s <command> [options]\n\nAvailable commands:\n  run               Execute a test flow.\n  version           Show build information.\n  info              Show configuration paths and defaults (-profile <name> selects a profile).\n  help              Show this help message.\n\nGlobal flags:\n  -no-color         Print errors without ANSI colors (also set NO_COLOR; colors are off when stderr is not a terminal).\n\nHelpful references:\n  %[1]s run -help           More information about running flows.\n  %[1]s help action [name]  List actions or display the fields for an action.\n  %[1]s help actions        Print the complete action guide.", program)
 }
 
 func runHelpMessage(program string) string {
-	return fmt.Sprintf("Usage:\n  %[1]s run [-flow=<action-flow>] [-begin-from-task=<task-id>] [-run-task=<task-id>] [-run-subtask=<task-id>] [-run-flow=<flow-id>] [options]\n\nFlags:\n  -flow              Path to the action flow to execute (required unless -serve-ui is used without an initial run).\n  -begin-from-task   Start executing the flow from the provided task identifier.\n  -run-task          Execute only the specified task identifier.\n  -run-subtask       Execute only the specified subtask identifier (nested in PARALLEL/FOR).\n  -run-flow          Execute the specified nested flow identifier.\n  -validate-only     Validate the flow definition and exit without running tasks.\n  -serve-ui          Start an HTTP server to serve the visual UI and live execution events (UI host/port/dir/flows_dir are read from config.yaml).\n  -config            Path to a config.yaml file that overrides the XDG config location.", program)
+	return fmt.Sprintf("Usage:\n  %[1]s run [-flow=<action-flow>] [-begin-from-task=<task-id>] [-run-task=<task-id>] [-run-tasks=<task-id>,...] [-run-subtask=<task-id>] [-run-flow=<flow-id>] [-tag=<tag>...] [options]\n\nFlags:\n  -flow              Path to the action flow to execute (required unless -serve-ui is used without an initial run).\n  -begin-from-task   Start executing the flow from the provided task identifier.\n  -run-task          Execute only the specified task identifier.\n  -run-tasks         Execute only the comma-separated task identifiers, in flow order.\n  -run-subtask       Execute only the specified subtask identifier (nested in PARALLEL/FOR).\n  -run-flow          Execute the specified nested flow identifier.\n  -tag               Execute only the tasks carrying this tag; repeat the flag to select several tags.\n  -tag-match         How several -tag values combine: any (default) runs tasks with at least one, all runs tasks with every one.\n  -validate-only     Validate the flow definition and exit without running tasks.\n  -strict            Fail instead of warning when the flow references variables no task declares.\n  -log-format        Log format: text (default) or json, which also writes flow_log.jsonl to the flow logs directory.\n  -junit             Write a JUnit XML report of the task results to the given path when the run ends.\n  -watch             Run the flow again whenever the flow file, its imports or included fragments change, cancelling the run in progress.\n  -serve-ui          Start an HTTP server to serve the visual UI and live execution events (UI host/port/dir/token/TLS/flows_dir are read from config.yaml).\n  -config            Path to a config.yaml file that overrides the XDG config location.\n  -profile           Name of a config.yaml profile to merge over the base settings (defaults to FLOWK_PROFILE).", program)
 }
 
 func formatFlowDuration(d time.Duration) string {
@@ -324,14 +513,47 @@ func formatDurationUnit(value int, singular, plural string) string {
 }
 
 func runFlowWithOptions(ctx context.Context, args runArguments) (err error) {
+	if args.strict {
+		ctx = app.WithStrictVariables(ctx)
+	}
+	ctx = app.WithLogsDir(ctx, args.logsDir)
+	ctx = app.WithLogFormat(ctx, args.logFormat)
+	ctx = app.WithJUnitReport(ctx, args.junitPath)
+	ctx = app.WithRunTasks(ctx, args.runTaskIDs)
+	ctx = app.WithTagFilter(ctx, args.tags, args.tagMatch)
 	if args.validateOnly {
-		return app.ValidateFlow(args.flowPath)
+		return app.ValidateFlow(ctx, args.flowPath, log.Default())
 	}
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Enabled:     args.tracing.Enabled,
+		Endpoint:    args.tracing.Endpoint,
+		ServiceName: args.tracing.ServiceName,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if shutdownErr := shutdownTracing(shutdownCtx); shutdownErr != nil {
+			log.Printf("WARNING: flushing traces: %v", shutdownErr)
+		}
+	}()
+
 	if !args.serveUI {
+		if args.watch {
+			return watchFlow(ctx, args.flowPath, func(runCtx context.Context) error {
+				return app.Run(runCtx, args.flowPath, log.Default(), args.beginFromTask, args.runTaskID, args.runFlowID, args.runSubtaskID)
+			})
+		}
 		return app.Run(ctx, args.flowPath, log.Default(), args.beginFromTask, args.runTaskID, args.runFlowID, args.runSubtaskID)
 	}
 
 	hub := uiserver.NewEventHub()
+	if args.uiMaxSubs > 0 {
+		hub.SetMaxSubscribers(args.uiMaxSubs)
+	}
 	observer := uiserver.NewHubObserver(hub)
 
 	uiCtx, cancel := context.WithCancel(ctx)
@@ -341,7 +563,7 @@ func runFlowWithOptions(ctx context.Context, args runArguments) (err error) {
 	staticDir, uiFound := resolveUIStaticDir(args.uiDir)
 	if !uiFound {
 		log.Printf("UI assets not found at %s; static UI will be unavailable", staticDir)
-		_ = printInfo(os.Stderr)
+		_ = printInfo(os.Stderr, args.profile)
 		return fmt.Errorf("ui assets not found at %s", staticDir)
 	} else {
 		log.Printf("Using UI assets from %s", staticDir)
@@ -356,12 +578,32 @@ func runFlowWithOptions(ctx context.Context, args runArguments) (err error) {
 		Runner:        flowRunner,
 		FlowUploadDir: "",
 		ConfigPath:    args.configPath,
+		Token:         args.uiToken,
+		AllowRemote:   args.uiAllowRemote,
+		LogsDir:       args.logsDir,
+		FlowSaveDir:   args.uiSaveDir,
+		Language:      args.uiLang,
+		Build:         uiserver.BuildInfo{Version: version, Commit: commit, Date: date},
+		Metrics:       args.uiMetrics,
 	})
 	if err != nil {
 		return err
 	}
 
 	runner := uiserver.NewRunner(args.uiAddress, server.Handle())
+	switch {
+	case strings.TrimSpace(args.uiTLSCert) != "":
+		if err := runner.EnableTLS(args.uiTLSCert, args.uiTLSKey); err != nil {
+			return err
+		}
+	case args.uiSelfSigned:
+		fingerprint, err := runner.EnableSelfSignedTLS()
+		if err != nil {
+			return err
+		}
+		log.Printf("Serving the UI with a self-signed certificate (SHA-256 %s); browsers will show a warning", fingerprint)
+	}
+	uiURL := fmt.Sprintf("%s://%s", runner.Scheme(), args.uiAddress)
 	if bindErr := runner.Bind(); bindErr != nil {
 		log.Printf("UI server failed to bind %s: %v", args.uiAddress, bindErr)
 		return bindErr
@@ -375,6 +617,7 @@ func runFlowWithOptions(ctx context.Context, args runArguments) (err error) {
 		serverErr    error
 		serverClosed bool
 		initialRunCh <-chan error
+		watchErrCh   chan error
 	)
 
 	defer func() {
@@ -407,12 +650,33 @@ func runFlowWithOptions(ctx context.Context, args runArguments) (err error) {
 	}
 
 	if uiFound {
-		log.Printf("Flowk UI is available at http://%s", args.uiAddress)
+		log.Printf("Flowk UI is available at %s", uiURL)
 	} else {
-		log.Printf("Flowk API is available at http://%s (UI assets missing)", args.uiAddress)
-	}
-
-	if flowRunner != nil && strings.TrimSpace(args.flowPath) != "" {
+		log.Printf("Flowk API is available at %s (UI assets missing)", uiURL)
+	}
+
+	switch {
+	case args.watch:
+		// Watched runs go through the UI runner, so the UI shows each new
+		// definition as its run loads it.
+		watchErrCh = make(chan error, 1)
+		go func() {
+			watchErrCh <- watchFlow(uiCtx, args.flowPath, func(runCtx context.Context) error {
+				flowRunner.UpdateFlowPath(args.flowPath)
+				done, err := flowRunner.Restart(nil)
+				if err != nil {
+					return err
+				}
+				select {
+				case err := <-done:
+					return err
+				case <-runCtx.Done():
+					_ = flowRunner.Cancel()
+					return <-done
+				}
+			})
+		}()
+	case flowRunner != nil && strings.TrimSpace(args.flowPath) != "":
 		initialRunCh, err = flowRunner.Start(nil)
 		if err != nil {
 			return err
@@ -427,7 +691,9 @@ func runFlowWithOptions(ctx context.Context, args runArguments) (err error) {
 				err = runErr
 				return err
 			}
-			log.Printf("Flow completed successfully. The Flowk UI will remain available at http://%s until you terminate the process.", args.uiAddress)
+			log.Printf("Flow completed successfully. The Flowk UI will remain available at %s until you terminate the process.", uiURL)
+		case err = <-watchErrCh:
+			return err
 		case serverErr = <-serverErrCh:
 			serverClosed = true
 			if serverErr != nil {
@@ -505,6 +771,26 @@ func parseFlagValue(args []string, index *int, name string) (string, bool, error
 	return "", false, nil
 }
 
+// parseTaskIDList splits the comma-separated value of -run-tasks, rejecting
+// empty and repeated identifiers.
+func parseTaskIDList(value string) ([]string, error) {
+	parts := strings.Split(value, ",")
+	taskIDs := make([]string, 0, len(parts))
+	seen := make(map[string]struct{}, len(parts))
+	for _, part := range parts {
+		id := strings.TrimSpace(part)
+		if id == "" {
+			return nil, fmt.Errorf("flag -run-tasks contains an empty task id in %q", value)
+		}
+		if _, exists := seen[id]; exists {
+			return nil, fmt.Errorf("flag -run-tasks lists task id %q more than once", id)
+		}
+		seen[id] = struct{}{}
+		taskIDs = append(taskIDs, id)
+	}
+	return taskIDs, nil
+}
+
 func isHelpFlag(arg string) bool {
 	switch arg {
 	case "-h", "--help", "help":
@@ -514,7 +800,76 @@ func isHelpFlag(arg string) bool {
 }
 
 func executeActionHelp(program string, args []string) error {
+	all := false
+	example := false
+	format := "markdown"
+	operation := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "-json", "--json":
+			format = "json"
+			continue
+		case "-all", "--all":
+			all = true
+			continue
+		case "-example", "--example":
+			example = true
+			continue
+		}
+
+		matched := false
+		for _, flag := range []struct {
+			name   string
+			target *string
+		}{{"format", &format}, {"operation", &operation}} {
+			value, ok, err := parseFlagValue(args, &i, "-"+flag.name)
+			if !ok && err == nil {
+				value, ok, err = parseFlagValue(args, &i, "--"+flag.name)
+			}
+			if err != nil {
+				return &usageError{err: err, helpMessage: actionhelp.Usage(program)}
+			}
+			if ok {
+				*flag.target = strings.TrimSpace(value)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			remaining = append(remaining, arg)
+		}
+	}
+	args = remaining
+	format = strings.ToLower(format)
+
+	if format != "markdown" && format != "json" {
+		return &usageError{err: fmt.Errorf("unsupported format %q (use markdown or json)", format), helpMessage: actionhelp.Usage(program)}
+	}
+	asJSON := format == "json"
+
+	if operation != "" && !example {
+		return &usageError{err: errors.New("flag -operation requires -example"), helpMessage: actionhelp.Usage(program)}
+	}
+	if example && (all || asJSON) {
+		return &usageError{err: errors.New("flag -example cannot be combined with -all or -json"), helpMessage: actionhelp.Usage(program)}
+	}
+
+	if all {
+		if len(args) > 0 {
+			return &usageError{err: fmt.Errorf("unexpected arguments: %s", strings.Join(args, " ")), helpMessage: actionhelp.Usage(program)}
+		}
+		return printActionGuide(asJSON)
+	}
+
 	if len(args) == 0 {
+		if asJSON {
+			return &usageError{err: errors.New("flag -json requires an action name or -all"), helpMessage: actionhelp.Usage(program)}
+		}
+		if example {
+			return &usageError{err: errors.New("flag -example requires an action name"), helpMessage: actionhelp.Usage(program)}
+		}
 		fmt.Fprintln(os.Stdout, actionhelp.Index(program))
 		return nil
 	}
@@ -529,10 +884,20 @@ func executeActionHelp(program string, args []string) error {
 		return nil
 	}
 
-	message, err := actionhelp.Build(actionName)
+	build := actionhelp.Build
+	switch {
+	case example:
+		build = func(name string) (string, error) {
+			return actionhelp.BuildExample(name, operation)
+		}
+	case asJSON:
+		build = actionhelp.BuildJSON
+	}
+	message, err := build(actionName)
 	if err != nil {
 		var lookupErr actionhelp.LookupError
-		if errors.As(err, &lookupErr) {
+		var operationErr actionhelp.OperationError
+		if errors.As(err, &lookupErr) || errors.As(err, &operationErr) {
 			return &usageError{err: err, helpMessage: actionhelp.Usage(program)}
 		}
 		return err
@@ -542,8 +907,27 @@ func executeActionHelp(program string, args []string) error {
 	return nil
 }
 
-func printInfo(out io.Writer) error {
-	configResult, err := config.Load()
+func printActionGuide(asJSON bool) error {
+	guide, err := actionhelp.BuildGuide()
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		message, err := actionhelp.FormatGuideJSON(guide)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, message)
+		return nil
+	}
+
+	fmt.Fprint(os.Stdout, actionhelp.FormatGuideMarkdown(guide))
+	return nil
+}
+
+func printInfo(out io.Writer, profile string) error {
+	configResult, err := config.LoadProfile("", profile)
 	if err != nil {
 		return err
 	}
@@ -556,9 +940,73 @@ func printInfo(out io.Writer) error {
 	} else {
 		fmt.Fprintln(out, "Config loaded: no (using defaults)")
 	}
-	fmt.Fprintf(out, "UI host: %s\n", configResult.Config.UI.Host)
-	fmt.Fprintf(out, "UI port: %d\n", configResult.Config.UI.Port)
-	fmt.Fprintf(out, "UI dir: %s\n", configResult.Config.UI.Dir)
+	if configResult.Profile != "" {
+		fmt.Fprintf(out, "Profile: %s\n", configResult.Profile)
+	} else {
+		fmt.Fprintln(out, "Profile: none")
+	}
+	fmt.Fprintf(out, "UI host: %s (%s)\n", configResult.Config.UI.Host, infoSource(configResult, "ui.host", config.UIHostEnv))
+	fmt.Fprintf(out, "UI port: %d (%s)\n", configResult.Config.UI.Port, infoSource(configResult, "ui.port", config.UIPortEnv))
+	fmt.Fprintf(out, "UI dir: %s (%s)\n", configResult.Config.UI.Dir, infoSource(configResult, "ui.dir", config.UIDirEnv))
+	fmt.Fprintf(out, "UI allow remote: %t\n", configResult.Config.UI.AllowRemote)
+	fmt.Fprintf(out, "UI metrics: %t\n", configResult.Config.UI.Metrics)
+	switch {
+	case configResult.Config.UI.TLSCertFile != "":
+		fmt.Fprintf(out, "UI TLS: %s\n", configResult.Config.UI.TLSCertFile)
+	case configResult.Config.UI.TLSSelfSigned:
+		fmt.Fprintln(out, "UI TLS: self-signed")
+	default:
+		fmt.Fprintln(out, "UI TLS: off")
+	}
+	if configResult.Config.UI.Token != "" {
+		fmt.Fprintf(out, "UI token: set (%s)\n", infoSource(configResult, "ui.token", config.UITokenEnv))
+	} else {
+		fmt.Fprintln(out, "UI token: not set")
+	}
 	fmt.Fprintf(out, "Flows dir: %s\n", configResult.Config.FlowsDir)
+
+	defaults := configResult.Config.Defaults
+	fmt.Fprintf(out, "Log dir: %s (%s)\n", chooseInfoValue(defaults.LogDir, app.LogsDir), infoSource(configResult, "defaults.log_dir", config.LogDirEnv))
+	fmt.Fprintf(out, "Log format: %s\n", chooseInfoValue(defaults.LogFormat, app.LogFormatText))
+	if tracingCfg := configResult.Config.Tracing; tracingCfg.Enabled {
+		fmt.Fprintf(out, "Tracing: on (%s)\n", chooseInfoValue(strings.TrimSpace(os.Getenv(tracing.EndpointEnv)), chooseInfoValue(tracingCfg.Endpoint, "http://localhost:4318")))
+	} else {
+		fmt.Fprintln(out, "Tracing: off")
+	}
+	fmt.Fprintf(out, "Kubernetes kubeconfig: %s\n", chooseInfoValue(defaults.Kubernetes.Kubeconfig, "KUBECONFIG or ~/.kube/config"))
+	fmt.Fprintf(out, "Kubernetes context: %s\n", chooseInfoValue(defaults.Kubernetes.Context, "not set"))
+	if defaults.SSH.TimeoutSeconds > 0 {
+		fmt.Fprintf(out, "SSH timeout: %gs\n", defaults.SSH.TimeoutSeconds)
+	} else {
+		fmt.Fprintln(out, "SSH timeout: none")
+	}
+	fmt.Fprintf(out, "SSH host key mode: %s\n", chooseInfoValue(defaults.SSH.HostKeyMode, "insecure"))
+	if len(defaults.SSH.KnownHostsFiles) > 0 {
+		fmt.Fprintf(out, "SSH known hosts files: %s\n", strings.Join(defaults.SSH.KnownHostsFiles, ", "))
+	}
 	return nil
 }
+
+// infoSource describes where the value at key came from, naming the
+// environment variable that set it.
+func infoSource(result config.LoadResult, key, envName string) string {
+	switch result.Source(key) {
+	case config.SourceEnv:
+		return "from " + envName
+	case config.SourceFile:
+		return "from config file"
+	case config.SourceProfile:
+		return "from profile"
+	default:
+		return "default"
+	}
+}
+
+// chooseInfoValue returns the configured value, or the built-in default
+// actions fall back to.
+func chooseInfoValue(configured, builtIn string) string {
+	if configured != "" {
+		return configured
+	}
+	return builtIn
+}
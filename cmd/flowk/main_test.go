@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"log"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	actionhelp "flowk/internal/cli/actionhelp"
+	"flowk/internal/config"
 )
 
 func TestParseRunArgsSupportsFlagsInAnyOrder(t *testing.T) {
@@ -196,6 +198,140 @@ func TestParseRunArgsRunSubtaskConflictsWithBeginFromTask(t *testing.T) {
 	}
 }
 
+func TestParseRunArgsRunTasks(t *testing.T) {
+	setTempConfigHome(t)
+	args, err := parseRunArgs([]string{"-flow=flow.json", "-run-tasks=build, test ,deploy"})
+	if err != nil {
+		t.Fatalf("parseRunArgs() error = %v", err)
+	}
+	if got := strings.Join(args.runTaskIDs, "|"); got != "build|test|deploy" {
+		t.Fatalf("runTaskIDs = %q, want build|test|deploy", got)
+	}
+	if args.runTaskID != "" {
+		t.Fatalf("runTask = %q, want empty", args.runTaskID)
+	}
+}
+
+func TestParseRunArgsRunTasksConflicts(t *testing.T) {
+	setTempConfigHome(t)
+	for _, extra := range [][]string{
+		{"-run-task", "task1"},
+		{"-begin-from-task", "task1"},
+		{"-run-subtask", "task1"},
+		{"-run-flow", "subflow"},
+	} {
+		_, err := parseRunArgs(append([]string{"-flow=flow.json", "-run-tasks", "task1,task2"}, extra...))
+		if err == nil {
+			t.Fatalf("parseRunArgs(%v) error = nil, want error", extra)
+		}
+		if !strings.Contains(err.Error(), "-run-tasks cannot be combined") {
+			t.Fatalf("error message = %q, want mention of run-tasks conflict", err)
+		}
+	}
+}
+
+func TestParseRunArgsRunTasksRejectsInvalidLists(t *testing.T) {
+	setTempConfigHome(t)
+	for value, want := range map[string]string{
+		"task1,,task2":  "empty task id",
+		"task1,task1":   "more than once",
+		"task1, task1 ": "more than once",
+	} {
+		_, err := parseRunArgs([]string{"-flow=flow.json", "-run-tasks=" + value})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("parseRunArgs(%q) error = %v, want %q", value, err, want)
+		}
+	}
+}
+
+func TestStripNoColorFlag(t *testing.T) {
+	args, noColor := stripNoColorFlag([]string{"run", "-flow", "flow.json", "--no-color"})
+	if !noColor || strings.Join(args, " ") != "run -flow flow.json" {
+		t.Fatalf("stripNoColorFlag() = %q, %v", args, noColor)
+	}
+
+	args, noColor = stripNoColorFlag([]string{"-no-color", "version"})
+	if !noColor || strings.Join(args, " ") != "version" {
+		t.Fatalf("stripNoColorFlag() = %q, %v", args, noColor)
+	}
+
+	if _, noColor = stripNoColorFlag([]string{"run", "-flow=flow.json"}); noColor {
+		t.Fatal("stripNoColorFlag() reported -no-color without the flag")
+	}
+}
+
+func TestUseCLIColor(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "stderr.log"))
+	if err != nil {
+		t.Fatalf("creating file: %v", err)
+	}
+	defer file.Close()
+	if useCLIColor(false, "", file) {
+		t.Fatal("expected no color when stderr is redirected to a file")
+	}
+
+	// The null device is a character device, like a terminal.
+	device, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("opening %s: %v", os.DevNull, err)
+	}
+	defer device.Close()
+	if !useCLIColor(false, "", device) {
+		t.Fatal("expected color on a character device")
+	}
+	if useCLIColor(true, "", device) || useCLIColor(false, "1", device) {
+		t.Fatal("expected -no-color and NO_COLOR to disable color")
+	}
+}
+
+func TestColorError(t *testing.T) {
+	t.Cleanup(func() { cliColor = true })
+
+	cliColor = true
+	if got := colorError("Error: boom"); got != cliColorRed+"Error: boom"+cliColorReset {
+		t.Fatalf("colorError() = %q", got)
+	}
+	cliColor = false
+	if got := colorError("Error: boom"); got != "Error: boom" {
+		t.Fatalf("colorError() = %q, want plain text", got)
+	}
+}
+
+func TestParseRunArgsTags(t *testing.T) {
+	setTempConfigHome(t)
+	args, err := parseRunArgs([]string{"-flow=flow.json", "-tag", "deploy", "-tag=smoke", "-tag-match", "ALL"})
+	if err != nil {
+		t.Fatalf("parseRunArgs() error = %v", err)
+	}
+	if got := strings.Join(args.tags, "|"); got != "deploy|smoke" {
+		t.Fatalf("tags = %q, want deploy|smoke", got)
+	}
+	if args.tagMatch != "all" {
+		t.Fatalf("tagMatch = %q, want all", args.tagMatch)
+	}
+}
+
+func TestParseRunArgsTagsRejected(t *testing.T) {
+	setTempConfigHome(t)
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-flow=flow.json", "-tag", "deploy", "-run-task", "task1"}, "-tag cannot be combined"},
+		{[]string{"-flow=flow.json", "-tag", "deploy", "-run-tasks", "task1,task2"}, "-tag cannot be combined"},
+		{[]string{"-flow=flow.json", "-tag", "deploy", "-validate-only"}, "-tag cannot be combined with -validate-only"},
+		{[]string{"-flow=flow.json", "-tag-match", "all"}, "-tag-match requires -tag"},
+		{[]string{"-flow=flow.json", "-tag", "deploy", "-tag-match", "some"}, "unsupported tag match"},
+		{[]string{"-flow=flow.json", "-tag", " "}, "non-empty value"},
+	}
+	for _, tt := range tests {
+		_, err := parseRunArgs(tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("parseRunArgs(%v) error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
 func TestParseRunArgsValidateOnly(t *testing.T) {
 	setTempConfigHome(t)
 	args, err := parseRunArgs([]string{"-flow=flow.json", "-validate-only"})
@@ -264,6 +400,127 @@ func TestParseRunArgsConfigOverride(t *testing.T) {
 	}
 }
 
+func TestParseRunArgsAppliesConfigDefaults(t *testing.T) {
+	xdgHome := setTempConfigHome(t)
+	writeConfig(t, xdgHome, "defaults:\n  log_dir: ./run-logs\n  kubernetes:\n    context: prod\n")
+	t.Cleanup(func() { config.SetActionDefaults(config.DefaultsConfig{}) })
+
+	args, err := parseRunArgs([]string{"-flow", "flow.json"})
+	if err != nil {
+		t.Fatalf("parseRunArgs() error = %v", err)
+	}
+	if args.logsDir != "./run-logs" {
+		t.Fatalf("logsDir = %q, want ./run-logs", args.logsDir)
+	}
+	if got := config.ActionDefaults().Kubernetes.Context; got != "prod" {
+		t.Fatalf("kubernetes context default = %q, want prod", got)
+	}
+}
+
+func TestPrintInfoShowsConfigDefaults(t *testing.T) {
+	xdgHome := setTempConfigHome(t)
+	writeConfig(t, xdgHome, "ui:\n  port: 9000\ndefaults:\n  kubernetes:\n    context: prod\n  ssh:\n    timeout_seconds: 15\n")
+	t.Setenv(config.UIHostEnv, "0.0.0.0")
+
+	var out bytes.Buffer
+	if err := printInfo(&out, ""); err != nil {
+		t.Fatalf("printInfo() error = %v", err)
+	}
+	for _, want := range []string{"UI host: 0.0.0.0 (from FLOWK_UI_HOST)\n", "UI port: 9000 (from config file)\n", "UI dir: ui/dist (default)\n", "Log dir: logs (default)\n", "Kubernetes context: prod\n", "SSH timeout: 15s\n", "SSH host key mode: insecure\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("printInfo() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestParseRunArgsProfile(t *testing.T) {
+	xdgHome := setTempConfigHome(t)
+	writeConfig(t, xdgHome, "ui:\n  port: 8080\nprofiles:\n  prod:\n    ui:\n      port: 9443\n")
+	t.Setenv(config.ProfileEnv, "")
+
+	args, err := parseRunArgs([]string{"-flow", "flow.json", "-profile", "prod"})
+	if err != nil {
+		t.Fatalf("parseRunArgs() error = %v", err)
+	}
+	if args.profile != "prod" || args.uiAddress != "127.0.0.1:9443" {
+		t.Fatalf("profile, uiAddress = %q, %q, want prod and the profile port", args.profile, args.uiAddress)
+	}
+
+	if _, err := parseRunArgs([]string{"-flow", "flow.json", "-profile=staging"}); err == nil || !strings.Contains(err.Error(), `profile "staging" is not defined (available: prod)`) {
+		t.Fatalf("parseRunArgs() error = %v, want unknown profile error", err)
+	}
+
+	var out bytes.Buffer
+	if err := printInfo(&out, "prod"); err != nil {
+		t.Fatalf("printInfo() error = %v", err)
+	}
+	for _, want := range []string{"Profile: prod\n", "UI port: 9443 (from profile)\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("printInfo() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestParseRunArgsLogFormat(t *testing.T) {
+	xdgHome := setTempConfigHome(t)
+	writeConfig(t, xdgHome, "defaults:\n  log_format: json\n")
+
+	args, err := parseRunArgs([]string{"-flow", "flow.json"})
+	if err != nil {
+		t.Fatalf("parseRunArgs() error = %v", err)
+	}
+	if args.logFormat != "json" {
+		t.Fatalf("logFormat = %q, want the config value json", args.logFormat)
+	}
+
+	args, err = parseRunArgs([]string{"-flow", "flow.json", "-log-format", "TEXT"})
+	if err != nil {
+		t.Fatalf("parseRunArgs() error = %v", err)
+	}
+	if args.logFormat != "text" {
+		t.Fatalf("logFormat = %q, want the flag to override config", args.logFormat)
+	}
+
+	if _, err := parseRunArgs([]string{"-flow", "flow.json", "-log-format=xml"}); err == nil || !strings.Contains(err.Error(), `log format "xml" is not supported`) {
+		t.Fatalf("parseRunArgs() error = %v, want unsupported log format error", err)
+	}
+}
+
+func TestParseRunArgsJUnit(t *testing.T) {
+	setTempConfigHome(t)
+
+	args, err := parseRunArgs([]string{"-flow", "flow.json", "-junit", "reports/junit.xml"})
+	if err != nil {
+		t.Fatalf("parseRunArgs() error = %v", err)
+	}
+	if args.junitPath != "reports/junit.xml" {
+		t.Fatalf("junitPath = %q, want reports/junit.xml", args.junitPath)
+	}
+
+	if _, err := parseRunArgs([]string{"-flow", "flow.json", "-validate-only", "-junit=junit.xml"}); err == nil || !strings.Contains(err.Error(), "-junit cannot be combined") {
+		t.Fatalf("parseRunArgs() error = %v, want -junit combination error", err)
+	}
+}
+
+func TestParseRunArgsWatch(t *testing.T) {
+	setTempConfigHome(t)
+
+	args, err := parseRunArgs([]string{"-watch", "-flow", "flow.json"})
+	if err != nil {
+		t.Fatalf("parseRunArgs() error = %v", err)
+	}
+	if !args.watch {
+		t.Fatal("watch = false, want true")
+	}
+
+	if _, err := parseRunArgs([]string{"-watch", "-flow", "flow.json", "-validate-only"}); err == nil || !strings.Contains(err.Error(), "-watch cannot be combined") {
+		t.Fatalf("parseRunArgs() error = %v, want -watch combination error", err)
+	}
+	if _, err := parseRunArgs([]string{"-watch", "-serve-ui"}); err == nil || !strings.Contains(err.Error(), "-watch requires -flow") {
+		t.Fatalf("parseRunArgs() error = %v, want -watch without flow error", err)
+	}
+}
+
 func TestBuildActionHelpPrint(t *testing.T) {
 	help, err := actionhelp.Build("print")
 	if err != nil {
@@ -351,6 +608,7 @@ func TestRunFlowWithServeUIKeepsServerRunningUntilContextCancelled(t *testing.T)
 		serveUI:   true,
 		uiAddress: addr,
 		uiDir:     dir,
+		logsDir:   filepath.Join(dir, "logs"),
 	}
 
 	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0o600); err != nil {
@@ -482,18 +740,29 @@ func captureStdout(t *testing.T, fn func()) string {
 		t.Fatalf("pipe creation failed: %v", err)
 	}
 
+	// Drain the pipe while fn runs so large outputs do not fill its buffer.
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := io.ReadAll(r)
+		done <- result{data: data, err: err}
+	}()
+
 	os.Stdout = w
 	fn()
 	w.Close()
 	os.Stdout = original
 
-	data, err := io.ReadAll(r)
-	if err != nil {
-		t.Fatalf("reading stdout failed: %v", err)
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("reading stdout failed: %v", res.err)
 	}
 	r.Close()
 
-	return string(data)
+	return string(res.data)
 }
 
 func setTempConfigHome(t *testing.T) string {
@@ -515,3 +784,83 @@ func writeConfig(t *testing.T, configHome, contents string) string {
 	}
 	return path
 }
+
+func TestExecuteActionHelpJSON(t *testing.T) {
+	output := captureStdout(t, func() {
+		if err := executeActionHelp("flowk", []string{"for", "-json"}); err != nil {
+			t.Fatalf("executeActionHelp() error = %v", err)
+		}
+	})
+
+	var doc actionhelp.ActionDocumentation
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, output)
+	}
+	if !strings.EqualFold(doc.Name, "for") || len(doc.Required) == 0 {
+		t.Fatalf("unexpected documentation: %+v", doc)
+	}
+
+	var usageErr *usageError
+	if err := executeActionHelp("flowk", []string{"-json"}); !errors.As(err, &usageErr) {
+		t.Fatalf("executeActionHelp(-json) error = %v, want usage error", err)
+	}
+}
+
+func TestExecuteActionHelpAll(t *testing.T) {
+	markdown := captureStdout(t, func() {
+		if err := executeActionHelp("flowk", []string{"-all"}); err != nil {
+			t.Fatalf("executeActionHelp(-all) error = %v", err)
+		}
+	})
+	if !strings.HasPrefix(markdown, "# FlowK: action and operation catalog") || !strings.Contains(markdown, "## FOR") {
+		t.Fatalf("unexpected guide output: %s", markdown)
+	}
+
+	output := captureStdout(t, func() {
+		if err := execute("flowk", []string{"help", "actions", "-format", "json"}); err != nil {
+			t.Fatalf("help actions -format json error = %v", err)
+		}
+	})
+	var guide actionhelp.GuideDocument
+	if err := json.Unmarshal([]byte(output), &guide); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if strings.TrimSpace(guide.Primer) == "" || len(guide.Actions) == 0 {
+		t.Fatalf("unexpected guide: primer=%q actions=%d", guide.Primer, len(guide.Actions))
+	}
+
+	var usageErr *usageError
+	if err := executeActionHelp("flowk", []string{"-all", "-format", "xml"}); !errors.As(err, &usageErr) {
+		t.Fatalf("executeActionHelp(-format xml) error = %v, want usage error", err)
+	}
+	if err := executeActionHelp("flowk", []string{"-all", "for"}); !errors.As(err, &usageErr) {
+		t.Fatalf("executeActionHelp(-all for) error = %v, want usage error", err)
+	}
+}
+
+func TestExecuteActionHelpExample(t *testing.T) {
+	output := captureStdout(t, func() {
+		if err := executeActionHelp("flowk", []string{"kubernetes", "-example", "-operation", "SCALE"}); err != nil {
+			t.Fatalf("executeActionHelp(-example) error = %v", err)
+		}
+	})
+	var task map[string]any
+	if err := json.Unmarshal([]byte(output), &task); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, output)
+	}
+	if task["action"] != "KUBERNETES" || task["operation"] != "SCALE" {
+		t.Fatalf("unexpected example: %v", task)
+	}
+
+	var usageErr *usageError
+	for _, args := range [][]string{
+		{"kubernetes", "-example"},
+		{"kubernetes", "-operation", "SCALE"},
+		{"print", "-example", "-json"},
+		{"-example"},
+	} {
+		if err := executeActionHelp("flowk", args); !errors.As(err, &usageErr) {
+			t.Fatalf("executeActionHelp(%v) error = %v, want usage error", args, err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"flowk/internal/flow"
+)
+
+// watchDebounce is how long -watch waits after the last change before running
+// the flow again, so an editor saving in several writes triggers one run.
+var watchDebounce = 300 * time.Millisecond
+
+// flowWatcher reports changes to a flow file, its imports and its $include
+// fragments. It watches their directories rather than the files themselves,
+// so files that editors replace on save, or that are deleted and created
+// again, keep being watched.
+type flowWatcher struct {
+	flowPath string
+	watcher  *fsnotify.Watcher
+	files    map[string]struct{}
+	dirs     map[string]struct{}
+}
+
+func newFlowWatcher(flowPath string) (*flowWatcher, error) {
+	absPath, err := filepath.Abs(flowPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolving flow path: %w", err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+	w := &flowWatcher{
+		flowPath: absPath,
+		watcher:  watcher,
+		files:    make(map[string]struct{}),
+		dirs:     make(map[string]struct{}),
+	}
+	w.refresh()
+	return w, nil
+}
+
+// refresh watches the files the flow currently loads. While the flow does not
+// load, for example halfway through an edit, the files already watched are
+// kept so the fix is picked up.
+func (w *flowWatcher) refresh() {
+	files := []string{w.flowPath}
+	if definition, err := flow.LoadDefinition(w.flowPath); err == nil {
+		files = definition.Files
+	} else {
+		for file := range w.files {
+			files = append(files, file)
+		}
+	}
+
+	w.files = make(map[string]struct{}, len(files))
+	dirs := make(map[string]struct{})
+	for _, file := range files {
+		w.files[file] = struct{}{}
+		dirs[filepath.Dir(file)] = struct{}{}
+	}
+	for dir := range w.dirs {
+		if _, keep := dirs[dir]; !keep {
+			_ = w.watcher.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	for dir := range dirs {
+		if _, watched := w.dirs[dir]; watched {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			log.Printf("WARNING: watching %s: %v", dir, err)
+			continue
+		}
+		w.dirs[dir] = struct{}{}
+	}
+}
+
+// relevant reports whether event changes the content of a watched file.
+func (w *flowWatcher) relevant(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	_, watched := w.files[filepath.Clean(event.Name)]
+	return watched
+}
+
+func (w *flowWatcher) close() {
+	_ = w.watcher.Close()
+}
+
+// watchFlow calls run with the flow and calls it again each time one of the
+// flow files changes, cancelling the run in progress first. A failed run is
+// reported and the watch goes on; watchFlow only returns when ctx ends.
+func watchFlow(ctx context.Context, flowPath string, run func(context.Context) error) error {
+	w, err := newFlowWatcher(flowPath)
+	if err != nil {
+		return err
+	}
+	defer w.close()
+
+	var (
+		done    chan error
+		cancel  context.CancelFunc = func() {}
+		started time.Time
+		pending <-chan time.Time
+		changed string
+	)
+	start := func() {
+		if _, err := os.Stat(w.flowPath); err != nil {
+			log.Printf("Flow file %s is missing; waiting for it to be created again", w.flowPath)
+			return
+		}
+		var runCtx context.Context
+		runCtx, cancel = context.WithCancel(ctx)
+		done = make(chan error, 1)
+		started = time.Now()
+		go func() {
+			done <- run(runCtx)
+		}()
+	}
+	waiting := func() {
+		log.Printf("Watching %d file(s) for changes; press Ctrl+C to stop.", len(w.files))
+	}
+
+	start()
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			if done != nil {
+				<-done
+			}
+			return ctx.Err()
+		case runErr := <-done:
+			done = nil
+			cancel()
+			if runErr != nil {
+				log.Print(colorError(fmt.Sprintf("Error: %v", runErr)))
+			}
+			log.Printf("Flow execution time: %s", formatFlowDuration(time.Since(started)))
+			waiting()
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return errors.New("file watcher stopped")
+			}
+			if !w.relevant(event) {
+				continue
+			}
+			if changed == "" {
+				changed = event.Name
+			}
+			pending = time.After(watchDebounce)
+		case watchErr, ok := <-w.watcher.Errors:
+			if !ok {
+				return errors.New("file watcher stopped")
+			}
+			log.Printf("WARNING: watching flow files: %v", watchErr)
+		case <-pending:
+			pending = nil
+			if done != nil {
+				cancel()
+				<-done
+				done = nil
+				log.Printf("Cancelled the run in progress.")
+			}
+			log.Print(watchSeparator(changed))
+			changed = ""
+			w.refresh()
+			start()
+		}
+	}
+}
+
+// watchSeparator is printed between two watched runs.
+func watchSeparator(changed string) string {
+	rule := strings.Repeat("=", 20)
+	return fmt.Sprintf("\n%s %s changed, running the flow again %s", rule, changed, rule)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeWatchedFlow(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func TestWatchFlowRerunsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	flowPath := filepath.Join(dir, "flow.json")
+	subPath := filepath.Join(dir, "sub.json")
+	writeWatchedFlow(t, subPath, `{"description":"sub","id":"watch.sub","name":"watch.sub","tasks":[{"action":"PRINT","description":"sub","id":"sub","name":"sub","entries":[{"message":"sub"}]}]}`)
+	flowContent := `{"description":"watch","id":"watch.flow","name":"watch.flow","imports":["sub.json"],"tasks":[{"action":"PRINT","description":"main","id":"main","name":"main","entries":[{"message":"main"}]}]}`
+	writeWatchedFlow(t, flowPath, flowContent)
+
+	originalDebounce := watchDebounce
+	watchDebounce = 50 * time.Millisecond
+	t.Cleanup(func() { watchDebounce = originalDebounce })
+
+	origWriter := log.Writer()
+	buf := &threadSafeBuffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(origWriter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first run blocks until it is cancelled, the later ones finish.
+	starts := make(chan int, 10)
+	cancelled := make(chan struct{})
+	runs := 0
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watchFlow(ctx, flowPath, func(runCtx context.Context) error {
+			runs++
+			starts <- runs
+			if runs > 1 {
+				return nil
+			}
+			<-runCtx.Done()
+			close(cancelled)
+			return runCtx.Err()
+		})
+	}()
+
+	waitForRun := func(want int) {
+		t.Helper()
+		select {
+		case got := <-starts:
+			if got != want {
+				t.Fatalf("run %d started, want run %d", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("run %d did not start:\n%s", want, buf.String())
+		}
+	}
+
+	waitForRun(1)
+	// Several quick writes to an imported file make a single run.
+	for i := 0; i < 3; i++ {
+		writeWatchedFlow(t, subPath, `{"description":"sub","id":"watch.sub","name":"watch.sub","tasks":[{"action":"PRINT","description":"sub","id":"sub","name":"sub","entries":[{"message":"changed"}]}]}`)
+	}
+	waitForRun(2)
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("the first run was not cancelled before the second started")
+	}
+
+	if err := os.Remove(flowPath); err != nil {
+		t.Fatalf("removing flow: %v", err)
+	}
+	time.Sleep(4 * watchDebounce)
+	select {
+	case got := <-starts:
+		t.Fatalf("run %d started while the flow file was missing", got)
+	default:
+	}
+	writeWatchedFlow(t, flowPath, flowContent)
+	waitForRun(3)
+
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Fatalf("watchFlow() error = %v, want context.Canceled", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"sub.json changed, running the flow again", "Cancelled the run in progress", "is missing; waiting for it to be created again"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("output missing %q:\n%s", want, output)
+		}
+	}
+	if got := strings.Count(output, "changed, running the flow again"); got != 3 {
+		t.Fatalf("printed %d separators, want 3:\n%s", got, output)
+	}
+}
@@ -0,0 +1,55 @@
+package datetime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+type action struct{}
+
+func init() {
+	registry.Register(action{})
+}
+
+func (action) Name() string {
+	return ActionName
+}
+
+func (action) Execute(_ context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	var cfg Payload
+	if err := json.Unmarshal(payload, &cfg); err != nil {
+		return registry.Result{}, fmt.Errorf("decoding datetime task payload: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return registry.Result{}, err
+	}
+
+	result, err := Execute(cfg, time.Now())
+	if err != nil {
+		return registry.Result{}, err
+	}
+	if execCtx.Logger != nil {
+		execCtx.Logger.Printf("DATETIME: %v", result.Value)
+	}
+
+	if cfg.Variable != "" {
+		if execCtx.Variables == nil {
+			execCtx.Variables = make(map[string]registry.Variable)
+		}
+		variable := registry.Variable{Name: cfg.Variable, Type: "string", Value: result.Value}
+		if number, ok := result.Value.(int64); ok {
+			variable.Type, variable.Value = "number", float64(number)
+		}
+		execCtx.Variables[cfg.Variable] = variable
+		if execCtx.Logger != nil {
+			execCtx.Logger.Printf("DATETIME: stored %s", cfg.Variable)
+		}
+	}
+
+	return registry.Result{Value: result, Type: flow.ResultTypeJSON}, nil
+}
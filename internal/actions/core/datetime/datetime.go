@@ -0,0 +1,363 @@
+package datetime
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embedded zone data keeps timezone names working on hosts without a
+	// zoneinfo database, such as Windows and minimal containers.
+	_ "time/tzdata"
+)
+
+const (
+	// ActionName identifies the Datetime action in the flow definition.
+	ActionName = "DATETIME"
+
+	OperationNow   = "NOW"
+	OperationParse = "PARSE"
+
+	// LayoutUnix and LayoutUnixMilli format timestamps as numbers of
+	// seconds and milliseconds since the Unix epoch.
+	LayoutUnix      = "UNIX"
+	LayoutUnixMilli = "UNIX_MS"
+)
+
+// namedLayouts are the layouts that can be referred to by name, keyed by
+// their upper-cased name.
+var namedLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339NANO": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"ANSIC":       time.ANSIC,
+	"UNIXDATE":    time.UnixDate,
+	"KITCHEN":     time.Kitchen,
+	"DATETIME":    time.DateTime,
+	"DATEONLY":    time.DateOnly,
+	"TIMEONLY":    time.TimeOnly,
+}
+
+// parseLayouts are tried in order by PARSE when no input_layout is given.
+var parseLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	time.DateTime,
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.RubyDate,
+	time.UnixDate,
+	time.ANSIC,
+}
+
+// strftimeDirectives translates strftime directives to Go layout elements.
+var strftimeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'p': "PM",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'%': "%",
+}
+
+var (
+	offsetPattern     = regexp.MustCompile(`^[+-]?(\d+(\.\d+)?(ns|us|µs|ms|s|m|h|d|w))+$`)
+	offsetTermPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h|d|w)`)
+	numberPattern     = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+)
+
+// Payload describes the configuration accepted by the DATETIME action.
+type Payload struct {
+	// Operation is NOW, which starts from the current time, or PARSE, which
+	// starts from Value.
+	Operation string `json:"operation"`
+	// Value is the timestamp PARSE reads: a string, or a number of seconds
+	// since the Unix epoch.
+	Value any `json:"value,omitempty"`
+	// InputLayout is the layout of Value. When empty, PARSE accepts Unix
+	// seconds, RFC 3339 and the other common layouts.
+	InputLayout string `json:"input_layout,omitempty"`
+	// Offset is added to the time, for example "-24h", "1h30m" or "-7d".
+	// Days (d) and weeks (w) are 24 and 168 hours.
+	Offset string `json:"offset,omitempty"`
+	// Timezone is the IANA zone the result is expressed in, and the zone
+	// PARSE assumes for values without one. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+	// Layout formats the result: a named layout, unix, unix_ms, a Go
+	// reference layout or a strftime pattern. Defaults to RFC3339.
+	Layout string `json:"layout,omitempty"`
+	// Variable, when set, stores the formatted value as a flow variable.
+	Variable string `json:"variable,omitempty"`
+}
+
+// Result is the value stored as the task result.
+type Result struct {
+	// Value is the formatted time: a string, or an integer for the Unix
+	// layouts.
+	Value    any    `json:"value"`
+	RFC3339  string `json:"rfc3339"`
+	Unix     int64  `json:"unix"`
+	Timezone string `json:"timezone"`
+}
+
+// Validate normalizes the payload and ensures it is well formed.
+func (p *Payload) Validate() error {
+	p.Operation = strings.ToUpper(strings.TrimSpace(p.Operation))
+	p.Offset = strings.TrimSpace(p.Offset)
+	p.Timezone = strings.TrimSpace(p.Timezone)
+	p.Variable = strings.TrimSpace(p.Variable)
+
+	switch p.Operation {
+	case OperationNow:
+		if p.Value != nil || p.InputLayout != "" {
+			return fmt.Errorf("datetime task: value and input_layout are only supported for PARSE operation")
+		}
+	case OperationParse:
+		if p.Value == nil {
+			return fmt.Errorf("datetime task: value is required for PARSE operation")
+		}
+		if p.InputLayout != "" {
+			if _, err := resolveLayout(p.InputLayout); err != nil {
+				return fmt.Errorf("datetime task: input_layout: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("datetime task: unsupported operation %q", p.Operation)
+	}
+
+	if _, err := parseOffset(p.Offset); err != nil {
+		return fmt.Errorf("datetime task: %w", err)
+	}
+	if _, err := loadLocation(p.Timezone); err != nil {
+		return fmt.Errorf("datetime task: %w", err)
+	}
+	if _, err := resolveLayout(p.Layout); err != nil {
+		return fmt.Errorf("datetime task: layout: %w", err)
+	}
+	for _, r := range p.Variable {
+		if !(r == '_' || r == '-' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return fmt.Errorf("datetime task: variable %q contains invalid character %q", p.Variable, r)
+		}
+	}
+	return nil
+}
+
+// Execute computes the time described by payload, starting from now for
+// NOW, and formats it.
+func Execute(payload Payload, now time.Time) (Result, error) {
+	location, err := loadLocation(payload.Timezone)
+	if err != nil {
+		return Result{}, fmt.Errorf("datetime task: %w", err)
+	}
+
+	moment := now
+	if payload.Operation == OperationParse {
+		if moment, err = parseValue(payload.Value, payload.InputLayout, location); err != nil {
+			return Result{}, fmt.Errorf("datetime task: %w", err)
+		}
+	}
+
+	offset, err := parseOffset(payload.Offset)
+	if err != nil {
+		return Result{}, fmt.Errorf("datetime task: %w", err)
+	}
+	moment = moment.Add(offset).In(location)
+
+	layout, err := resolveLayout(payload.Layout)
+	if err != nil {
+		return Result{}, fmt.Errorf("datetime task: layout: %w", err)
+	}
+
+	return Result{
+		Value:    format(moment, layout),
+		RFC3339:  moment.Format(time.RFC3339),
+		Unix:     moment.Unix(),
+		Timezone: location.String(),
+	}, nil
+}
+
+func format(moment time.Time, layout string) any {
+	switch layout {
+	case LayoutUnix:
+		return moment.Unix()
+	case LayoutUnixMilli:
+		return moment.UnixMilli()
+	default:
+		return moment.Format(layout)
+	}
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return location, nil
+}
+
+// resolveLayout returns the Go layout for a layout name, strftime pattern or
+// Go reference layout, or LayoutUnix/LayoutUnixMilli. An empty layout is
+// RFC 3339.
+func resolveLayout(layout string) (string, error) {
+	trimmed := strings.TrimSpace(layout)
+	if trimmed == "" {
+		return time.RFC3339, nil
+	}
+	switch upper := strings.ToUpper(trimmed); upper {
+	case LayoutUnix, LayoutUnixMilli:
+		return upper, nil
+	default:
+		if named, ok := namedLayouts[upper]; ok {
+			return named, nil
+		}
+	}
+
+	if strings.Contains(layout, "%") {
+		return translateStrftime(layout)
+	}
+
+	// A layout without reference time elements formats every time the same.
+	reference := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if reference.Format(layout) == layout {
+		return "", fmt.Errorf("%q is not a known layout name and has neither Go reference time elements (2006-01-02 15:04:05) nor strftime directives (%%Y-%%m-%%d)", layout)
+	}
+	return layout, nil
+}
+
+func translateStrftime(pattern string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		if i+1 == len(pattern) {
+			return "", fmt.Errorf("%q ends with an incomplete %% directive", pattern)
+		}
+		i++
+		element, ok := strftimeDirectives[pattern[i]]
+		if !ok {
+			return "", fmt.Errorf("unsupported strftime directive %%%c in %q", pattern[i], pattern)
+		}
+		b.WriteString(element)
+	}
+	return b.String(), nil
+}
+
+// parseOffset parses a signed duration such as "-24h" or "1d12h". Besides
+// the units time.ParseDuration accepts, it accepts d (24h) and w (168h).
+func parseOffset(offset string) (time.Duration, error) {
+	if offset == "" {
+		return 0, nil
+	}
+	if !offsetPattern.MatchString(offset) {
+		return 0, fmt.Errorf("invalid offset %q; use a signed duration such as -24h, 90m or -7d", offset)
+	}
+
+	var total time.Duration
+	for _, term := range offsetTermPattern.FindAllStringSubmatch(offset, -1) {
+		amount, unit := term[1], term[2]
+		var value time.Duration
+		switch unit {
+		case "d", "w":
+			number, err := strconv.ParseFloat(amount, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid offset %q: %w", offset, err)
+			}
+			hours := 24.0
+			if unit == "w" {
+				hours *= 7
+			}
+			value = time.Duration(number * hours * float64(time.Hour))
+		default:
+			parsed, err := time.ParseDuration(amount + unit)
+			if err != nil {
+				return 0, fmt.Errorf("invalid offset %q: %w", offset, err)
+			}
+			value = parsed
+		}
+		total += value
+	}
+	if strings.HasPrefix(offset, "-") {
+		total = -total
+	}
+	return total, nil
+}
+
+// parseValue reads the timestamp PARSE starts from. Values without a zone
+// are read in location.
+func parseValue(value any, inputLayout string, location *time.Location) (time.Time, error) {
+	var text string
+	switch v := value.(type) {
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		text = strings.TrimSpace(v)
+	default:
+		return time.Time{}, fmt.Errorf("value must be a string or a number, got %T", value)
+	}
+
+	layout := ""
+	if inputLayout != "" {
+		var err error
+		if layout, err = resolveLayout(inputLayout); err != nil {
+			return time.Time{}, err
+		}
+	} else if numberPattern.MatchString(text) {
+		layout = LayoutUnix
+	}
+
+	switch layout {
+	case LayoutUnix, LayoutUnixMilli:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("value %q is not a Unix timestamp", text)
+		}
+		if layout == LayoutUnixMilli {
+			number /= 1000
+		}
+		seconds, fraction := math.Modf(number)
+		return time.Unix(int64(seconds), int64(math.Round(fraction*1e9))), nil
+	case "":
+		for _, candidate := range parseLayouts {
+			if parsed, err := time.ParseInLocation(candidate, text, location); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("value %q is not in a recognized layout; set input_layout", text)
+	default:
+		parsed, err := time.ParseInLocation(layout, text, location)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("value %q does not match input_layout %q", text, inputLayout)
+		}
+		return parsed, nil
+	}
+}
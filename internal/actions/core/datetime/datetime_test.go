@@ -0,0 +1,129 @@
+package datetime
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"flowk/internal/actions/registry"
+)
+
+var fixedNow = time.Date(2024, time.March, 10, 12, 30, 45, 0, time.UTC)
+
+func TestPayloadValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		payload Payload
+		wantErr string
+	}{
+		{name: "unknown operation", payload: Payload{Operation: "TODAY"}, wantErr: "unsupported operation"},
+		{name: "now with value", payload: Payload{Operation: OperationNow, Value: "2024-01-01"}, wantErr: "only supported for PARSE"},
+		{name: "parse without value", payload: Payload{Operation: OperationParse}, wantErr: "value is required"},
+		{name: "bad offset", payload: Payload{Operation: OperationNow, Offset: "yesterday"}, wantErr: "invalid offset"},
+		{name: "bad timezone", payload: Payload{Operation: OperationNow, Timezone: "Mars/Olympus"}, wantErr: "unknown timezone"},
+		{name: "layout without elements", payload: Payload{Operation: OperationNow, Layout: "YYYY-MM-DD"}, wantErr: "not a known layout name"},
+		{name: "bad strftime", payload: Payload{Operation: OperationNow, Layout: "%Q"}, wantErr: "unsupported strftime directive %Q"},
+		{name: "bad variable", payload: Payload{Operation: OperationNow, Variable: "since time"}, wantErr: "invalid character"},
+		{name: "valid now", payload: Payload{Operation: "now", Offset: "-1h", Timezone: "Europe/Madrid", Layout: "unix", Variable: "since"}},
+		{name: "valid parse", payload: Payload{Operation: OperationParse, Value: "2024-01-01", InputLayout: "DateOnly"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.payload.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		payload Payload
+		want    any
+	}{
+		{name: "now defaults to RFC 3339 in UTC", payload: Payload{Operation: OperationNow}, want: "2024-03-10T12:30:45Z"},
+		{name: "offset in hours", payload: Payload{Operation: OperationNow, Offset: "-1h"}, want: "2024-03-10T11:30:45Z"},
+		{name: "offset in days and hours", payload: Payload{Operation: OperationNow, Offset: "-1d12h"}, want: "2024-03-09T00:30:45Z"},
+		{name: "offset in weeks", payload: Payload{Operation: OperationNow, Offset: "+1w", Layout: "DateOnly"}, want: "2024-03-17"},
+		{name: "timezone", payload: Payload{Operation: OperationNow, Timezone: "America/New_York", Layout: "rfc3339"}, want: "2024-03-10T08:30:45-04:00"},
+		{name: "go layout", payload: Payload{Operation: OperationNow, Layout: "20060102-150405"}, want: "20240310-123045"},
+		{name: "strftime", payload: Payload{Operation: OperationNow, Layout: "build-%Y%m%d-%H%M%S %% %a %b %j"}, want: "build-20240310-123045 % Sun Mar 070"},
+		{name: "unix", payload: Payload{Operation: OperationNow, Layout: "unix"}, want: fixedNow.Unix()},
+		{name: "unix milliseconds", payload: Payload{Operation: OperationNow, Offset: "1500ms", Layout: "UNIX_MS"}, want: fixedNow.UnixMilli() + 1500},
+		{name: "parse rfc 3339 offset", payload: Payload{Operation: OperationParse, Value: "2024-01-02T03:04:05+02:00"}, want: "2024-01-02T01:04:05Z"},
+		{name: "parse date in timezone", payload: Payload{Operation: OperationParse, Value: "2024-07-01 09:00:00", Timezone: "Europe/Madrid"}, want: "2024-07-01T09:00:00+02:00"},
+		{name: "parse unix seconds", payload: Payload{Operation: OperationParse, Value: float64(1700000000)}, want: "2023-11-14T22:13:20Z"},
+		{name: "parse unix string", payload: Payload{Operation: OperationParse, Value: "1700000000.5", Layout: "RFC3339Nano"}, want: "2023-11-14T22:13:20.5Z"},
+		{name: "parse unix milliseconds", payload: Payload{Operation: OperationParse, Value: "1700000000000", InputLayout: "unix_ms"}, want: "2023-11-14T22:13:20Z"},
+		{name: "parse rfc 1123", payload: Payload{Operation: OperationParse, Value: "Mon, 02 Jan 2006 15:04:05 GMT", Layout: "DateTime"}, want: "2006-01-02 15:04:05"},
+		{name: "parse strftime input", payload: Payload{Operation: OperationParse, Value: "10/03/2024", InputLayout: "%d/%m/%Y", Offset: "-24h", Layout: "DateOnly"}, want: "2024-03-09"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.payload.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			result, err := Execute(tt.payload, fixedNow)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.Value != tt.want {
+				t.Fatalf("value = %#v, want %#v", result.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRejectsUnparsableValue(t *testing.T) {
+	t.Parallel()
+
+	for _, payload := range []Payload{
+		{Operation: OperationParse, Value: "next tuesday"},
+		{Operation: OperationParse, Value: "2024-01-02", InputLayout: "RFC3339"},
+		{Operation: OperationParse, Value: true},
+	} {
+		if err := payload.Validate(); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		if _, err := Execute(payload, fixedNow); err == nil {
+			t.Fatalf("Execute(%v) error = nil, want a parse error", payload.Value)
+		}
+	}
+}
+
+func TestActionStoresVariable(t *testing.T) {
+	t.Parallel()
+
+	execCtx := &registry.ExecutionContext{}
+	payload := json.RawMessage(`{"operation":"PARSE","value":"2024-01-02T03:04:05Z","layout":"unix","variable":"since"}`)
+	result, err := action{}.Execute(context.Background(), payload, execCtx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	stored, ok := execCtx.Variables["since"]
+	if !ok || stored.Type != "number" || stored.Value != float64(1704164645) {
+		t.Fatalf("stored variable = %+v, want number 1704164645", stored)
+	}
+	value := result.Value.(Result)
+	if value.RFC3339 != "2024-01-02T03:04:05Z" || value.Unix != 1704164645 || value.Timezone != "UTC" {
+		t.Fatalf("result = %+v", value)
+	}
+}
@@ -32,6 +32,7 @@ type branchActions struct {
 
 type taskConfig struct {
 	IfConditions []Condition  `json:"if_conditions"`
+	Logic        *Logic       `json:"logic,omitempty"`
 	Then         branchConfig `json:"then"`
 	Else         branchConfig `json:"else"`
 
@@ -48,6 +49,9 @@ func (c *taskConfig) Validate() error {
 			return fmt.Errorf("evaluate task: if_conditions[%d]: %w", i, err)
 		}
 	}
+	if err := c.Logic.Validate(len(c.IfConditions)); err != nil {
+		return fmt.Errorf("evaluate task: %w", err)
+	}
 
 	var err error
 	c.thenActions, err = c.Then.toActions("then")
@@ -168,7 +172,7 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 		}
 	}
 
-	matches, resultType, err := Execute(execCtx.Task, execCtx.Tasks, variableValues, cfg.IfConditions, execCtx.Logger)
+	matches, resultType, err := ExecuteWithLogic(execCtx.Task, execCtx.Tasks, variableValues, cfg.IfConditions, cfg.Logic, execCtx.Logger)
 	if err != nil {
 		return registry.Result{}, err
 	}
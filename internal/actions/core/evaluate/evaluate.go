@@ -2,9 +2,11 @@ package evaluate
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"flowk/internal/actions/shared/placeholders"
@@ -70,7 +72,7 @@ func (c Condition) Validate() error {
 	if strings.TrimSpace(c.leftOperand()) == "" {
 		return fmt.Errorf("field is required")
 	}
-	operation := strings.TrimSpace(c.Operation)
+	operation := normalizeOperation(c.Operation)
 	if operation == "" {
 		return fmt.Errorf("operation is required")
 	}
@@ -84,83 +86,138 @@ func (c Condition) Validate() error {
 	return nil
 }
 
+// normalizeOperation trims the operation and upper-cases named operators so
+// "contains" and "CONTAINS" are equivalent.
+func normalizeOperation(operation string) string {
+	return strings.ToUpper(strings.TrimSpace(operation))
+}
+
 // Execute validates the provided conditions against the referenced task. The
 // returned boolean indicates whether all conditions were satisfied.
 func Execute(task *flow.Task, tasks []flow.Task, variables map[string]any, conditions []Condition, logger Logger) (bool, flow.ResultType, error) {
+	return ExecuteWithLogic(task, tasks, variables, conditions, nil, logger)
+}
+
+// ExecuteWithLogic behaves like Execute but combines the conditions using
+// logic. A nil logic requires every condition to hold.
+func ExecuteWithLogic(task *flow.Task, tasks []flow.Task, variables map[string]any, conditions []Condition, logic *Logic, logger Logger) (bool, flow.ResultType, error) {
 	for idx, condition := range conditions {
 		if err := condition.Validate(); err != nil {
 			return false, "", fmt.Errorf("validate condition %d: %w", idx, err)
 		}
+	}
+	if err := logic.Validate(len(conditions)); err != nil {
+		return false, "", err
+	}
 
-		leftValue, err := resolveOperandValue(task, tasks, variables, condition.leftOperand(), true)
-		if err != nil {
-			return false, "", fmt.Errorf("conditions[%d]: %w", idx, err)
-		}
+	matches, err := logic.evaluate(len(conditions), func(idx int) (bool, error) {
+		return evaluateCondition(task, tasks, variables, idx, conditions[idx], logger)
+	})
+	if err != nil {
+		return false, "", err
+	}
+	return matches, flow.ResultTypeBool, nil
+}
 
-		if slice, ok := leftValue.([]any); ok && len(slice) == 0 {
-			switch condition.rightOperand().(type) {
-			case []any, map[string]any:
-				// allow comparing empty collections
-			default:
-				// specific behavior for some operators
-				op := strings.TrimSpace(condition.Operation)
-				if op == "NOT_IN" || op == "!=" {
-					// continue to evaluation
-				} else {
-					logger.Printf("conditions[%d]: field %q did not return any results", idx, strings.TrimSpace(condition.leftOperand()))
-					return false, flow.ResultTypeBool, nil
-				}
+func evaluateCondition(task *flow.Task, tasks []flow.Task, variables map[string]any, idx int, condition Condition, logger Logger) (bool, error) {
+	var warnf func(format string, args ...any)
+	if logger != nil {
+		warnf = logger.Printf
+	}
+
+	leftValue, err := resolveOperandValue(task, tasks, variables, condition.leftOperand(), true, warnf)
+	if err != nil {
+		return false, fmt.Errorf("conditions[%d]: %w", idx, err)
+	}
+
+	if slice, ok := leftValue.([]any); ok && len(slice) == 0 {
+		switch condition.rightOperand().(type) {
+		case []any, map[string]any:
+			// allow comparing empty collections
+		default:
+			// specific behavior for some operators
+			op := normalizeOperation(condition.Operation)
+			if op == "NOT_IN" || op == "!=" {
+				// continue to evaluation
+			} else {
+				logger.Printf("conditions[%d]: field %q did not return any results", idx, strings.TrimSpace(condition.leftOperand()))
+				return false, nil
 			}
 		}
+	}
 
-		rightValue, err := resolveOperandValue(task, tasks, variables, condition.rightOperand(), false)
-		if err != nil {
-			return false, "", fmt.Errorf("conditions[%d]: %w", idx, err)
+	rightValue, err := resolveOperandValue(task, tasks, variables, condition.rightOperand(), false, warnf)
+	if err != nil {
+		return false, fmt.Errorf("conditions[%d]: %w", idx, err)
+	}
+
+	operation := normalizeOperation(condition.Operation)
+
+	matches, compareErr := compareValues(leftValue, rightValue, operation)
+	if compareErr != nil {
+		return false, fmt.Errorf("conditions[%d]: %w", idx, compareErr)
+	}
+
+	plain, colored := conditionLogMessages(idx, operation, rightValue, leftValue, matches)
+	if coloredLogger, ok := logger.(coloredLogger); ok {
+		coloredLogger.PrintColored(plain, colored)
+	} else {
+		logger.Printf("%s", plain)
+	}
+
+	return matches, nil
+}
+
+// Matches reports whether every condition holds. Both operands are passed
+// through resolve, which expands placeholders into the values to compare; this
+// backs the task-level run_if and skip_if conditions.
+func Matches(conditions []Condition, resolve func(any) (any, error)) (bool, error) {
+	for idx, condition := range conditions {
+		if err := condition.Validate(); err != nil {
+			return false, fmt.Errorf("validate condition %d: %w", idx, err)
 		}
 
-		operation := strings.TrimSpace(condition.Operation)
-
-		var (
-			matches    bool
-			compareErr error
-		)
-		switch operation {
-		case "=":
-			matches, compareErr = evaluateEqual(leftValue, rightValue)
-		case "!=":
-			matches, compareErr = evaluateEqual(leftValue, rightValue)
-			matches = !matches
-		case ">", "<", ">=", "<=":
-			matches, compareErr = evaluateComparison(leftValue, rightValue, operation)
-		case "STARTS_WITH", "ENDS_WITH", "MATCHES":
-			matches, compareErr = evaluateStringOp(leftValue, rightValue, operation)
-		case "CONTAINS":
-			matches, compareErr = evaluateContains(leftValue, rightValue)
-		case "NOT_CONTAINS":
-			matches, compareErr = evaluateContains(leftValue, rightValue)
-			matches = !matches
-		case "IN", "NOT_IN":
-			matches, compareErr = evaluateCollectionOp(leftValue, rightValue, operation)
-		default:
-			return false, "", fmt.Errorf("conditions[%d]: unsupported operation %q", idx, operation)
+		left, err := resolve(condition.leftOperand())
+		if err != nil {
+			return false, fmt.Errorf("conditions[%d]: %w", idx, err)
 		}
-		if compareErr != nil {
-			return false, "", fmt.Errorf("conditions[%d]: %w", idx, compareErr)
+		right, err := resolve(condition.rightOperand())
+		if err != nil {
+			return false, fmt.Errorf("conditions[%d]: %w", idx, err)
 		}
 
-		plain, colored := conditionLogMessages(idx, operation, rightValue, leftValue, matches)
-		if coloredLogger, ok := logger.(coloredLogger); ok {
-			coloredLogger.PrintColored(plain, colored)
-		} else {
-			logger.Printf("%s", plain)
+		matches, err := compareValues(left, right, normalizeOperation(condition.Operation))
+		if err != nil {
+			return false, fmt.Errorf("conditions[%d]: %w", idx, err)
 		}
-
 		if !matches {
-			return false, flow.ResultTypeBool, nil
+			return false, nil
 		}
 	}
+	return true, nil
+}
 
-	return true, flow.ResultTypeBool, nil
+func compareValues(left, right any, operation string) (bool, error) {
+	switch operation {
+	case "=":
+		return evaluateEqual(left, right)
+	case "!=":
+		matches, err := evaluateEqual(left, right)
+		return !matches, err
+	case ">", "<", ">=", "<=":
+		return evaluateComparison(left, right, operation)
+	case "STARTS_WITH", "ENDS_WITH", "MATCHES":
+		return evaluateStringOp(left, right, operation)
+	case "CONTAINS":
+		return evaluateContains(left, right)
+	case "NOT_CONTAINS":
+		matches, err := evaluateContains(left, right)
+		return !matches, err
+	case "IN", "NOT_IN":
+		return evaluateCollectionOp(left, right, operation)
+	default:
+		return false, fmt.Errorf("unsupported operation %q", operation)
+	}
 }
 
 func evaluateStringOp(actual, expected any, operation string) (bool, error) {
@@ -342,19 +399,19 @@ func resolveConditionFieldValue(target *flow.Task, tasks []flow.Task, field stri
 		return nil, fmt.Errorf("field is required")
 	}
 
-	return resolveStringOperand(target, tasks, nil, field, true)
+	return resolveStringOperand(target, tasks, nil, field, true, nil)
 }
 
-func resolveOperandValue(target *flow.Task, tasks []flow.Task, variables map[string]any, operand any, allowFieldResolution bool) (any, error) {
+func resolveOperandValue(target *flow.Task, tasks []flow.Task, variables map[string]any, operand any, allowFieldResolution bool, warnf func(format string, args ...any)) (any, error) {
 	switch v := operand.(type) {
 	case string:
-		return resolveStringOperand(target, tasks, variables, v, allowFieldResolution)
+		return resolveStringOperand(target, tasks, variables, v, allowFieldResolution, warnf)
 	default:
 		return operand, nil
 	}
 }
 
-func resolveStringOperand(target *flow.Task, tasks []flow.Task, variables map[string]any, raw string, allowFieldResolution bool) (any, error) {
+func resolveStringOperand(target *flow.Task, tasks []flow.Task, variables map[string]any, raw string, allowFieldResolution bool, warnf func(format string, args ...any)) (any, error) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
 		if allowFieldResolution {
@@ -368,7 +425,7 @@ func resolveStringOperand(target *flow.Task, tasks []flow.Task, variables map[st
 		if expr == "" {
 			return nil, fmt.Errorf("placeholder is empty")
 		}
-		return resolveTaskPlaceholder(tasks, expr)
+		return resolveTaskPlaceholder(tasks, expr, warnf)
 	}
 
 	if secretPlaceholderPattern.MatchString(trimmed) {
@@ -397,7 +454,20 @@ func resolveStringOperand(target *flow.Task, tasks []flow.Task, variables map[st
 	return raw, nil
 }
 
-func resolveTaskPlaceholder(tasks []flow.Task, expr string) (any, error) {
+// resolveTaskPlaceholder resolves a from.task reference. When warnf is set, a
+// path missing from the referenced result is reported through it and resolves
+// to an empty string, matching the other task payloads.
+func resolveTaskPlaceholder(tasks []flow.Task, expr string, warnf func(format string, args ...any)) (any, error) {
+	if !strings.Contains(expr, "$") {
+		value, err := flow.ResolveTaskReference(tasks, expr)
+		var missing *flow.MissingPathError
+		if err != nil && warnf != nil && errors.As(err, &missing) {
+			warnf("WARNING: from.task:%s resolved to empty: %v", strings.TrimSpace(expr), err)
+			return "", nil
+		}
+		return value, err
+	}
+
 	taskID, fieldName, err := parsePlaceholder(expr)
 	if err != nil {
 		return nil, err
@@ -478,19 +548,29 @@ func evaluateEqual(actual, expected any) (bool, error) {
 	case nil:
 		return actual == nil, nil
 	case bool:
-		value, ok := actual.(bool)
+		value, ok := coerceBool(actual)
 		if !ok {
 			return false, fmt.Errorf("expected boolean value, got %T", actual)
 		}
 		return value == exp, nil
 	case string:
-		value, ok := actual.(string)
-		if !ok {
+		switch value := actual.(type) {
+		case string:
+			return value == exp, nil
+		case bool:
+			return evaluateEqual(exp, value)
+		default:
+			if actualNumber, ok := toFloat64(actual); ok {
+				expectedNumber, ok := coerceNumber(exp)
+				if !ok {
+					return false, fmt.Errorf("expected numeric value, got string %q", exp)
+				}
+				return actualNumber == expectedNumber, nil
+			}
 			return false, fmt.Errorf("expected string value, got %T", actual)
 		}
-		return value == exp, nil
 	case float64:
-		actualNumber, ok := toFloat64(actual)
+		actualNumber, ok := coerceNumber(actual)
 		if !ok {
 			return false, fmt.Errorf("expected numeric value, got %T", actual)
 		}
@@ -514,22 +594,14 @@ func evaluateEqual(actual, expected any) (bool, error) {
 func evaluateComparison(actual, expected any, operation string) (bool, error) {
 	actual = unwrapSingleElement(actual)
 
-	actualNumber, ok := toFloat64(actual)
+	actualNumber, ok := coerceNumber(actual)
 	if !ok {
-		return false, fmt.Errorf("expected numeric value, got %T", actual)
+		return false, fmt.Errorf("operation %q requires numeric operands, got %s on the left side", operation, describeValue(actual))
 	}
 
-	expectedNumber, ok := toFloat64(expected)
+	expectedNumber, ok := coerceNumber(expected)
 	if !ok {
-		if number, ok := expected.(json.Number); ok {
-			parsed, err := number.Float64()
-			if err != nil {
-				return false, fmt.Errorf("parsing expected number: %w", err)
-			}
-			expectedNumber = parsed
-		} else {
-			return false, fmt.Errorf("expected numeric value, got %T", expected)
-		}
+		return false, fmt.Errorf("operation %q requires numeric operands, got %s on the right side", operation, describeValue(expected))
 	}
 
 	switch operation {
@@ -583,6 +655,36 @@ func toFloat64(value any) (float64, bool) {
 	}
 }
 
+// coerceNumber extends toFloat64 to strings holding a number, such as values
+// read from text output or string variables.
+func coerceNumber(value any) (float64, bool) {
+	if str, ok := value.(string); ok {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+		return parsed, err == nil
+	}
+	return toFloat64(value)
+}
+
+// coerceBool accepts booleans and their "true"/"false" string forms.
+func coerceBool(value any) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(v))
+		return parsed, err == nil
+	default:
+		return false, false
+	}
+}
+
+func describeValue(value any) string {
+	if str, ok := value.(string); ok {
+		return fmt.Sprintf("string %q", str)
+	}
+	return fmt.Sprintf("%T", value)
+}
+
 func unwrapSingleElement(value any) any {
 	switch v := value.(type) {
 	case []any:
@@ -701,6 +701,31 @@ func TestExecuteErrorsWhenPlaceholderTaskMissing(t *testing.T) {
 	}
 }
 
+func TestExecuteWarnsWhenPlaceholderPathMissing(t *testing.T) {
+	logger := newStubLogger()
+	tasks := []flow.Task{{
+		ID:         "http.task",
+		Status:     flow.TaskStatusCompleted,
+		Success:    true,
+		ResultType: flow.ResultTypeJSON,
+		Result:     map[string]any{"status": "ok"},
+	}}
+	conditions := []Condition{
+		{Left: "${from.task:http.task.result.missing}", Operation: "=", Right: ""},
+	}
+
+	ok, _, err := Execute(nil, tasks, nil, conditions, logger)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !ok {
+		t.Fatalf("Execute() result = false, want true for the empty value")
+	}
+	if !logger.contains("WARNING: from.task:http.task.result.missing resolved to empty") {
+		t.Fatalf("expected missing path warning, got %v", logger.messages)
+	}
+}
+
 func TestExecuteErrorsWithoutTaskAndPlaceholder(t *testing.T) {
 	logger := newStubLogger()
 	conditions := []Condition{{Left: "success", Operation: "=", Right: true}}
@@ -942,3 +967,204 @@ func TestExecuteHandlesExtendedOperators(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesResolvesOperandsBeforeComparing(t *testing.T) {
+	values := map[string]any{"${env}": "prod", "${replicas}": float64(3)}
+	resolve := func(value any) (any, error) {
+		if str, ok := value.(string); ok {
+			if resolved, found := values[str]; found {
+				return resolved, nil
+			}
+		}
+		return value, nil
+	}
+
+	matches, err := Matches([]Condition{
+		{Left: "${env}", Operation: "=", Right: "prod"},
+		{Left: "${replicas}", Operation: ">=", Right: 2},
+	}, resolve)
+	if err != nil {
+		t.Fatalf("Matches() error = %v", err)
+	}
+	if !matches {
+		t.Fatal("expected conditions to match")
+	}
+
+	matches, err = Matches([]Condition{{Left: "${env}", Operation: "IN", Right: []any{"dev", "qa"}}}, resolve)
+	if err != nil {
+		t.Fatalf("Matches() error = %v", err)
+	}
+	if matches {
+		t.Fatal("expected IN condition not to match")
+	}
+
+	if _, err := Matches([]Condition{{Left: "${env}", Operation: "~"}}, resolve); err == nil {
+		t.Fatal("expected unsupported operation error")
+	}
+}
+
+func TestExecuteWithLogicCombinesConditions(t *testing.T) {
+	variables := map[string]any{"status": "degraded", "errors": float64(0)}
+	conditions := []Condition{
+		{Left: "${status}", Operation: "=", Right: "healthy"},
+		{Left: "${errors}", Operation: ">", Right: float64(0)},
+		{Left: "${status}", Operation: "=", Right: "degraded"},
+	}
+
+	decode := func(raw string) *Logic {
+		t.Helper()
+		var logic Logic
+		if err := json.Unmarshal([]byte(raw), &logic); err != nil {
+			t.Fatalf("decoding logic %s: %v", raw, err)
+		}
+		if err := logic.Validate(len(conditions)); err != nil {
+			t.Fatalf("validating logic %s: %v", raw, err)
+		}
+		return &logic
+	}
+
+	tests := []struct {
+		name      string
+		logic     *Logic
+		want      bool
+		evaluated int
+	}{
+		{name: "default and stops at first mismatch", logic: nil, want: false, evaluated: 1},
+		{name: "or", logic: decode(`"or"`), want: true, evaluated: 3},
+		{name: "any short-circuits", logic: decode(`{"any": [2, 0, 1]}`), want: true, evaluated: 1},
+		{name: "not", logic: decode(`{"not": [0]}`), want: true, evaluated: 1},
+		{name: "nested", logic: decode(`{"all": [2, {"not": [{"any": [0, 1]}]}]}`), want: true, evaluated: 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := newStubLogger()
+			got, resultType, err := ExecuteWithLogic(nil, nil, variables, conditions, tc.logic, logger)
+			if err != nil {
+				t.Fatalf("ExecuteWithLogic() error = %v", err)
+			}
+			if got != tc.want || resultType != flow.ResultTypeBool {
+				t.Fatalf("ExecuteWithLogic() = %v, %s, want %v", got, resultType, tc.want)
+			}
+			if evaluated := len(logger.coloredMessages); evaluated != tc.evaluated {
+				t.Fatalf("expected %d conditions to be evaluated, got %d", tc.evaluated, evaluated)
+			}
+		})
+	}
+}
+
+func TestLogicValidateErrors(t *testing.T) {
+	tests := map[string]string{
+		`"xor"`:                    "unsupported mode",
+		`{"any": [3]}`:             "out of range",
+		`{"any": [0], "all": [1]}`: "exactly one of all, any or not",
+		`{"not": []}`:              "at least one item",
+	}
+
+	for raw, wantErr := range tests {
+		var logic Logic
+		if err := json.Unmarshal([]byte(raw), &logic); err != nil {
+			t.Fatalf("decoding logic %s: %v", raw, err)
+		}
+		err := logic.Validate(2)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("logic %s: expected error containing %q, got %v", raw, wantErr, err)
+		}
+	}
+}
+
+func TestExecuteCoercesOperandsAndAcceptsLowercaseOperators(t *testing.T) {
+	logger := newStubLogger()
+	variables := map[string]any{
+		"replicas": "3",
+		"enabled":  "true",
+		"name":     "api-gateway",
+		"tier":     "gold",
+	}
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{name: "numeric string compared to number", condition: Condition{Left: "${replicas}", Operation: ">=", Right: float64(3)}, want: true},
+		{name: "numeric string equality", condition: Condition{Left: "${replicas}", Operation: "=", Right: float64(3)}, want: true},
+		{name: "boolean string equality", condition: Condition{Left: "${enabled}", Operation: "=", Right: true}, want: true},
+		{name: "lowercase contains", condition: Condition{Left: "${name}", Operation: "contains", Right: "gate"}, want: true},
+		{name: "lowercase starts_with", condition: Condition{Left: "${name}", Operation: "starts_with", Right: "web"}, want: false},
+		{name: "lowercase ends_with", condition: Condition{Left: "${name}", Operation: "ends_with", Right: "way"}, want: true},
+		{name: "lowercase in", condition: Condition{Left: "${tier}", Operation: "in", Right: []any{"silver", "gold"}}, want: true},
+		{name: "lowercase matches", condition: Condition{Left: "${name}", Operation: "matches", Right: "^api-[a-z]+$"}, want: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _, err := Execute(nil, nil, variables, []Condition{tc.condition}, logger)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("Execute() result = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteReportsIncompatibleOperandTypes(t *testing.T) {
+	logger := newStubLogger()
+	variables := map[string]any{"name": "api", "count": float64(2)}
+
+	tests := []struct {
+		condition Condition
+		wantErr   string
+	}{
+		{condition: Condition{Left: "${name}", Operation: ">", Right: float64(1)}, wantErr: `operation ">" requires numeric operands, got string "api" on the left side`},
+		{condition: Condition{Left: "${count}", Operation: "starts_with", Right: "2"}, wantErr: "expected string value on left side"},
+		{condition: Condition{Left: "${name}", Operation: "=", Right: true}, wantErr: "expected boolean value"},
+	}
+
+	for _, tc := range tests {
+		_, _, err := Execute(nil, nil, variables, []Condition{tc.condition}, logger)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("condition %+v: expected error containing %q, got %v", tc.condition, tc.wantErr, err)
+		}
+	}
+}
+
+func TestActionExecuteBranchesOnDottedTaskReference(t *testing.T) {
+	act := action{}
+	payload := map[string]any{
+		"if_conditions": []map[string]any{
+			{"left": "${from.task:x.result.readyReplicas}", "operation": ">=", "right": 3},
+		},
+		"then": map[string]any{"gototask": "scaled"},
+		"else": map[string]any{"gototask": "wait"},
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	for readyReplicas, wantTarget := range map[float64]string{3: "scaled", 2: "wait"} {
+		execCtx := &registry.ExecutionContext{
+			Task: &flow.Task{ID: "evaluate"},
+			Tasks: []flow.Task{{
+				ID:         "x",
+				Status:     flow.TaskStatusCompleted,
+				Success:    true,
+				ResultType: flow.ResultTypeJSON,
+				Result:     map[string]any{"readyReplicas": readyReplicas},
+			}},
+			Logger: newStubLogger(),
+		}
+
+		result, err := act.Execute(context.Background(), raw, execCtx)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if result.Control == nil || result.Control.JumpToTaskID != wantTarget {
+			t.Fatalf("readyReplicas=%v: expected jump to %q, got %#v", readyReplicas, wantTarget, result.Control)
+		}
+	}
+}
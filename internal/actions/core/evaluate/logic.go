@@ -0,0 +1,190 @@
+package evaluate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	logicAnd = "and"
+	logicOr  = "or"
+)
+
+// Logic describes how if_conditions are combined. Either Mode applies "and"
+// (the default) or "or" to the flat list, or Group holds an all/any/not tree
+// whose leaves are indexes into if_conditions.
+type Logic struct {
+	Mode  string
+	Group *LogicGroup
+}
+
+// LogicGroup is a single all/any/not node. Exactly one of the fields is set.
+// A not group matches when its items do not all match.
+type LogicGroup struct {
+	All []LogicNode `json:"all,omitempty"`
+	Any []LogicNode `json:"any,omitempty"`
+	Not []LogicNode `json:"not,omitempty"`
+}
+
+// LogicNode is either an index into if_conditions or a nested group.
+type LogicNode struct {
+	Index *int
+	Group *LogicGroup
+}
+
+// UnmarshalJSON accepts either a mode string or a group object.
+func (l *Logic) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		return json.Unmarshal(trimmed, &l.Mode)
+	}
+
+	var group LogicGroup
+	if err := json.Unmarshal(trimmed, &group); err != nil {
+		return err
+	}
+	l.Group = &group
+	return nil
+}
+
+// UnmarshalJSON accepts either a condition index or a nested group object.
+func (n *LogicNode) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var group LogicGroup
+		if err := json.Unmarshal(trimmed, &group); err != nil {
+			return err
+		}
+		n.Group = &group
+		return nil
+	}
+
+	var index int
+	if err := json.Unmarshal(trimmed, &index); err != nil {
+		return fmt.Errorf("logic item must be a condition index or a group: %w", err)
+	}
+	n.Index = &index
+	return nil
+}
+
+// Validate ensures the logic is well formed for the given number of
+// conditions.
+func (l *Logic) Validate(conditionCount int) error {
+	if l == nil {
+		return nil
+	}
+	if l.Group != nil {
+		return l.Group.validate(conditionCount, "logic")
+	}
+	switch strings.ToLower(strings.TrimSpace(l.Mode)) {
+	case "", logicAnd, logicOr:
+		return nil
+	default:
+		return fmt.Errorf("logic: unsupported mode %q", l.Mode)
+	}
+}
+
+func (g *LogicGroup) validate(conditionCount int, path string) error {
+	operator, items := g.operands()
+	if operator == "" {
+		return fmt.Errorf("%s: exactly one of all, any or not is required", path)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("%s.%s: at least one item is required", path, operator)
+	}
+
+	for i, item := range items {
+		itemPath := fmt.Sprintf("%s.%s[%d]", path, operator, i)
+		switch {
+		case item.Group != nil:
+			if err := item.Group.validate(conditionCount, itemPath); err != nil {
+				return err
+			}
+		case item.Index != nil:
+			if *item.Index < 0 || *item.Index >= conditionCount {
+				return fmt.Errorf("%s: condition index %d is out of range", itemPath, *item.Index)
+			}
+		default:
+			return fmt.Errorf("%s: item is empty", itemPath)
+		}
+	}
+	return nil
+}
+
+// operands returns the group operator and its items, or an empty operator
+// when zero or several operators are set.
+func (g *LogicGroup) operands() (string, []LogicNode) {
+	var operator string
+	var items []LogicNode
+	set := 0
+	if g.All != nil {
+		operator, items = "all", g.All
+		set++
+	}
+	if g.Any != nil {
+		operator, items = "any", g.Any
+		set++
+	}
+	if g.Not != nil {
+		operator, items = "not", g.Not
+		set++
+	}
+	if set != 1 {
+		return "", nil
+	}
+	return operator, items
+}
+
+// evaluate combines the conditions, calling eval lazily so that "or" and
+// "any" stop at the first match and "and", "all" and "not" at the first
+// mismatch.
+func (l *Logic) evaluate(conditionCount int, eval func(idx int) (bool, error)) (bool, error) {
+	if l != nil && l.Group != nil {
+		return l.Group.evaluate(eval)
+	}
+
+	stopOn := false
+	if l != nil && strings.EqualFold(strings.TrimSpace(l.Mode), logicOr) {
+		stopOn = true
+	}
+	for idx := 0; idx < conditionCount; idx++ {
+		matches, err := eval(idx)
+		if err != nil {
+			return false, err
+		}
+		if matches == stopOn {
+			return stopOn, nil
+		}
+	}
+	return !stopOn, nil
+}
+
+func (g *LogicGroup) evaluate(eval func(idx int) (bool, error)) (bool, error) {
+	operator, items := g.operands()
+
+	stopOn := operator == "any"
+	result := !stopOn
+	for _, item := range items {
+		var matches bool
+		var err error
+		if item.Group != nil {
+			matches, err = item.Group.evaluate(eval)
+		} else {
+			matches, err = eval(*item.Index)
+		}
+		if err != nil {
+			return false, err
+		}
+		if matches == stopOn {
+			result = stopOn
+			break
+		}
+	}
+
+	if operator == "not" {
+		return !result, nil
+	}
+	return result, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"flowk/internal/actions/core/variables"
@@ -30,6 +31,7 @@ type Payload struct {
 	RequireBreak  bool          `json:"require_break,omitempty"`
 	MaxIterations *int          `json:"max_iterations,omitempty"`
 	Values        []string      `json:"values"`
+	ValuesFrom    string        `json:"values_from,omitempty"`
 	Tasks         []flow.Task   `json:"tasks"`
 }
 
@@ -43,6 +45,7 @@ type LoopCondition struct {
 type iterationSummary struct {
 	Index   int              `json:"index"`
 	Counter *float64         `json:"counter,omitempty"`
+	Key     string           `json:"key,omitempty"`
 	Value   any              `json:"value,omitempty"`
 	Tasks   []subtaskSummary `json:"tasks"`
 }
@@ -96,6 +99,29 @@ func (cfg Payload) normalize(execCtx *registry.ExecutionContext) (normalizedPayl
 		tasks[i] = task
 	}
 
+	if cfg.Values != nil && strings.TrimSpace(cfg.ValuesFrom) != "" {
+		return normalizedPayload{}, fmt.Errorf("for action: values and values_from cannot be combined")
+	}
+
+	if ref := strings.TrimSpace(cfg.ValuesFrom); ref != "" {
+		if cfg.Initial != "" || cfg.Step != "" || strings.TrimSpace(cfg.Condition.Operator) != "" || cfg.Condition.Value != "" {
+			return normalizedPayload{}, fmt.Errorf("for action: values_from cannot be combined with numeric loop configuration")
+		}
+
+		items, err := resolveValuesFrom(ref, execCtx)
+		if err != nil {
+			return normalizedPayload{}, err
+		}
+
+		return normalizedPayload{
+			variable:      variable,
+			requireBreak:  cfg.RequireBreak,
+			maxIterations: cfg.MaxIterations,
+			items:         items,
+			tasks:         tasks,
+		}, nil
+	}
+
 	if cfg.Values != nil {
 		if cfg.Initial != "" || cfg.Step != "" || strings.TrimSpace(cfg.Condition.Operator) != "" || cfg.Condition.Value != "" {
 			return normalizedPayload{}, fmt.Errorf("for action: values cannot be combined with numeric loop configuration")
@@ -180,9 +206,17 @@ type normalizedPayload struct {
 	maxIterations *int
 	requireBreak  bool
 	values        []string
+	items         []loopItem
 	tasks         []flow.Task
 }
 
+// loopItem is a single element resolved from values_from. Key is only set
+// when iterating over an object.
+type loopItem struct {
+	key   *string
+	value any
+}
+
 type numericLoop struct {
 	initial      float64
 	conditionOp  string
@@ -218,34 +252,65 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 	var finalControl *registry.Control
 	loopBroken := false
 
-	if normalized.values != nil {
+	if normalized.values != nil || normalized.items != nil {
 		executed := false
 		var lastAssigned registry.Variable
 
-		for iteration := 0; iteration < len(normalized.values); iteration++ {
+		// key and value only live for the duration of a values_from loop;
+		// whatever the flow held under those names before is restored once it
+		// ends.
+		var entryScope map[string]*registry.Variable
+		if normalized.items != nil {
+			entryScope = saveVariables(currentVariables, "key", "value")
+		}
+
+		count := len(normalized.values)
+		if normalized.items != nil {
+			count = len(normalized.items)
+		}
+
+		for iteration := 0; iteration < count; iteration++ {
 			if normalized.maxIterations != nil && *normalized.maxIterations > 0 && iteration >= *normalized.maxIterations {
 				break
 			}
 
-			value, err := expandLoopValue(normalized.values[iteration], currentVariables)
-			if err != nil {
-				execCtx.Variables = currentVariables
-				return registry.Result{}, fmt.Errorf("for action: evaluating values[%d]: %w", iteration, err)
+			var assignment registry.Variable
+			var key *string
+			if normalized.items != nil {
+				item := normalized.items[iteration]
+				key = item.key
+				assignment = registry.Variable{
+					Name:  normalized.variable,
+					Type:  loopValueType(item.value),
+					Value: item.value,
+				}
+			} else {
+				value, err := expandLoopValue(normalized.values[iteration], currentVariables)
+				if err != nil {
+					restoreVariables(currentVariables, entryScope)
+					execCtx.Variables = currentVariables
+					return registry.Result{}, fmt.Errorf("for action: evaluating values[%d]: %w", iteration, err)
+				}
+				assignment = registry.Variable{
+					Name:  normalized.variable,
+					Type:  "string",
+					Value: value,
+				}
 			}
 			executed = true
 
-			assignment := registry.Variable{
-				Name:  normalized.variable,
-				Type:  "string",
-				Value: value,
-			}
 			lastAssigned = assignment
 			currentVariables[normalized.variable] = assignment
+			if key != nil {
+				currentVariables["key"] = registry.Variable{Name: "key", Type: "string", Value: *key}
+				currentVariables["value"] = registry.Variable{Name: "value", Type: assignment.Type, Value: assignment.Value}
+			}
 
 			iterDir := loopDir
 			if iterDir != "" {
 				iterDir = filepath.Join(loopDir, fmt.Sprintf("%d", iteration))
 				if err := os.MkdirAll(iterDir, 0o755); err != nil {
+					restoreVariables(currentVariables, entryScope)
 					execCtx.Variables = currentVariables
 					return registry.Result{}, fmt.Errorf("for action: creating iteration log dir: %w", err)
 				}
@@ -253,7 +318,10 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 
 			summary := iterationSummary{
 				Index: iteration,
-				Value: value,
+				Value: assignment.Value,
+			}
+			if key != nil {
+				summary.Key = *key
 			}
 
 			taskSummaries, updatedVars, ctrl, brokeLoop, execErr := executeIterationTasks(ctx, iterDir, normalized.tasks, baseTasks, currentVariables, execCtx, assignment)
@@ -262,6 +330,7 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 
 			if execErr != nil {
 				summaries = append(summaries, summary)
+				restoreVariables(currentVariables, entryScope)
 				execCtx.Variables = currentVariables
 				return registry.Result{Value: summaries, Type: flow.ResultTypeJSON}, execErr
 			}
@@ -285,10 +354,12 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 		}
 
 		if normalized.requireBreak && !loopBroken {
+			restoreVariables(currentVariables, entryScope)
 			execCtx.Variables = currentVariables
 			return registry.Result{Value: summaries, Type: flow.ResultTypeJSON}, fmt.Errorf("for action: require_break enabled but loop ended without break after %d iterations", len(summaries))
 		}
 
+		restoreVariables(currentVariables, entryScope)
 		execCtx.Variables = currentVariables
 
 		return registry.Result{
@@ -468,6 +539,117 @@ func executeIterationTasks(
 	return summaries, updated, finalControl, brokeLoop, nil
 }
 
+// resolveValuesFrom resolves a values_from reference into loop items. Task
+// placeholders are resolved first, then plain variable placeholders, so both
+// ${from.task:...} and ${list_variable} references are accepted.
+func resolveValuesFrom(ref string, execCtx *registry.ExecutionContext) ([]loopItem, error) {
+	var tasks []flow.Task
+	var vars map[string]registry.Variable
+	if execCtx != nil {
+		tasks = execCtx.Tasks
+		vars = execCtx.Variables
+	}
+
+	resolved, err := variables.ResolveTaskPlaceholders(ref, tasks)
+	if err != nil {
+		return nil, fmt.Errorf("for action: resolving values_from: %w", err)
+	}
+
+	if str, ok := resolved.(string); ok && strings.Contains(str, "${") {
+		resolved, err = expansion.ExpandStringValue(str, toExpansionVariables(vars))
+		if err != nil {
+			return nil, fmt.Errorf("for action: resolving values_from: %w", err)
+		}
+	}
+
+	switch v := normalizeJSONValue(resolved).(type) {
+	case []any:
+		items := make([]loopItem, len(v))
+		for i, value := range v {
+			items[i] = loopItem{value: value}
+		}
+		return items, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := make([]loopItem, len(keys))
+		for i, key := range keys {
+			key := key
+			items[i] = loopItem{key: &key, value: v[key]}
+		}
+		return items, nil
+	default:
+		kind := loopValueType(v)
+		if v == nil {
+			kind = "null"
+		}
+		return nil, fmt.Errorf("for action: values_from %q must resolve to a list or object, got %s", ref, kind)
+	}
+}
+
+// normalizeJSONValue converts typed slices and maps, such as results produced
+// by other actions, into their generic JSON representation.
+func normalizeJSONValue(value any) any {
+	switch value.(type) {
+	case nil, []any, map[string]any, string, bool, float64:
+		return value
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+// loopValueType maps an iterated value onto the VARIABLES type vocabulary.
+func loopValueType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64, int, int64:
+		return "number"
+	case []any:
+		return "list"
+	default:
+		return "json"
+	}
+}
+
+// saveVariables records the current values of names so that
+// restoreVariables can put them back, removing the ones that did not exist.
+func saveVariables(vars map[string]registry.Variable, names ...string) map[string]*registry.Variable {
+	saved := make(map[string]*registry.Variable, len(names))
+	for _, name := range names {
+		if existing, ok := vars[name]; ok {
+			saved[name] = &existing
+		} else {
+			saved[name] = nil
+		}
+	}
+	return saved
+}
+
+func restoreVariables(vars map[string]registry.Variable, saved map[string]*registry.Variable) {
+	for name, previous := range saved {
+		if previous == nil {
+			delete(vars, name)
+			continue
+		}
+		vars[name] = *previous
+	}
+}
+
 func expandLoopValue(template string, vars map[string]registry.Variable) (string, error) {
 	if template == "" {
 		return template, nil
@@ -14,7 +14,10 @@ import (
 )
 
 func TestPayloadNormalizeValidation(t *testing.T) {
-	execCtx := &registry.ExecutionContext{Task: &flow.Task{FlowID: "flow"}}
+	execCtx := &registry.ExecutionContext{
+		Task:      &flow.Task{FlowID: "flow"},
+		Variables: map[string]registry.Variable{"count": {Name: "count", Type: "number", Value: float64(3)}},
+	}
 
 	tests := []struct {
 		name    string
@@ -84,6 +87,25 @@ func TestPayloadNormalizeValidation(t *testing.T) {
 			},
 			wantErr: "values cannot be combined",
 		},
+		{
+			name: "values and values_from",
+			payload: Payload{
+				Variable:   "item",
+				Values:     []string{"a"},
+				ValuesFrom: "${from.task:list.result}",
+				Tasks:      []flow.Task{{ID: "child"}},
+			},
+			wantErr: "values and values_from cannot be combined",
+		},
+		{
+			name: "values_from scalar",
+			payload: Payload{
+				Variable:   "item",
+				ValuesFrom: "${count}",
+				Tasks:      []flow.Task{{ID: "child"}},
+			},
+			wantErr: "must resolve to a list or object, got number",
+		},
 	}
 
 	for _, tc := range tests {
@@ -288,6 +310,123 @@ func TestExecuteIteratesValues(t *testing.T) {
 	}
 }
 
+func TestExecuteIteratesValuesFromTaskResult(t *testing.T) {
+	act := action{}
+	execCtx := &registry.ExecutionContext{
+		Task: &flow.Task{ID: "parent", FlowID: "flow"},
+		Tasks: []flow.Task{
+			{
+				ID:         "deployments",
+				FlowID:     "flow",
+				Status:     flow.TaskStatusCompleted,
+				Success:    true,
+				ResultType: flow.ResultTypeJSON,
+				Result: map[string]any{
+					"items": []any{
+						map[string]any{"name": "api", "replicas": float64(2)},
+						map[string]any{"name": "worker", "replicas": float64(1)},
+					},
+				},
+			},
+		},
+		LogDir: filepath.Join(t.TempDir(), "parent"),
+	}
+
+	var seen []string
+	execCtx.ExecuteTask = func(ctx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+		variable := req.Variables["deployment"]
+		if variable.Type != "json" {
+			t.Fatalf("unexpected variable type %q", variable.Type)
+		}
+		item, _ := variable.Value.(map[string]any)
+		name, _ := item["name"].(string)
+		seen = append(seen, name)
+		return registry.TaskExecutionResponse{Variables: cloneVariables(req.Variables)}, nil
+	}
+
+	raw, _ := json.Marshal(map[string]any{
+		"variable":    "deployment",
+		"values_from": "${from.task:deployments.result.items}",
+		"tasks":       []map[string]any{{"id": "child"}},
+	})
+	if _, err := act.Execute(context.Background(), raw, execCtx); err != nil {
+		t.Fatalf("execute returned error: %v", err)
+	}
+
+	if strings.Join(seen, ",") != "api,worker" {
+		t.Fatalf("unexpected iteration order: %v", seen)
+	}
+}
+
+func TestExecuteIteratesValuesFromObject(t *testing.T) {
+	act := action{}
+	execCtx := &registry.ExecutionContext{
+		Task: &flow.Task{ID: "parent", FlowID: "flow"},
+		Variables: map[string]registry.Variable{
+			"limits": {Name: "limits", Type: "object", Value: map[string]any{"memory": "512Mi", "cpu": "250m"}},
+		},
+		LogDir: filepath.Join(t.TempDir(), "parent"),
+	}
+
+	var seen []string
+	execCtx.ExecuteTask = func(ctx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+		seen = append(seen, fmt.Sprintf("%v=%v", req.Variables["key"].Value, req.Variables["value"].Value))
+		return registry.TaskExecutionResponse{Variables: cloneVariables(req.Variables)}, nil
+	}
+
+	raw, _ := json.Marshal(map[string]any{
+		"variable":    "limit",
+		"values_from": "${limits}",
+		"tasks":       []map[string]any{{"id": "child"}},
+	})
+	result, err := act.Execute(context.Background(), raw, execCtx)
+	if err != nil {
+		t.Fatalf("execute returned error: %v", err)
+	}
+
+	if strings.Join(seen, ",") != "cpu=250m,memory=512Mi" {
+		t.Fatalf("unexpected iterations: %v", seen)
+	}
+	summaries, _ := result.Value.([]iterationSummary)
+	if len(summaries) != 2 || summaries[0].Key != "cpu" || summaries[0].Value != "250m" {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+}
+
+func TestExecuteValuesFromObjectRestoresKeyAndValue(t *testing.T) {
+	act := action{}
+	execCtx := &registry.ExecutionContext{
+		Task: &flow.Task{ID: "parent", FlowID: "flow"},
+		Variables: map[string]registry.Variable{
+			"limits": {Name: "limits", Type: "object", Value: map[string]any{"memory": "512Mi", "cpu": "250m"}},
+			"key":    {Name: "key", Type: "string", Value: "outer"},
+		},
+		LogDir: filepath.Join(t.TempDir(), "parent"),
+	}
+	execCtx.ExecuteTask = func(ctx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+		return registry.TaskExecutionResponse{Variables: cloneVariables(req.Variables)}, nil
+	}
+
+	raw, _ := json.Marshal(map[string]any{
+		"variable":    "limit",
+		"values_from": "${limits}",
+		"tasks":       []map[string]any{{"id": "child"}},
+	})
+	if _, err := act.Execute(context.Background(), raw, execCtx); err != nil {
+		t.Fatalf("execute returned error: %v", err)
+	}
+
+	if got := execCtx.Variables["key"].Value; got != "outer" {
+		t.Fatalf("expected key to be restored to outer, got %v", got)
+	}
+	if _, exists := execCtx.Variables["value"]; exists {
+		t.Fatalf("expected value to be removed after the loop, got %+v", execCtx.Variables["value"])
+	}
+	if got := execCtx.Variables["limit"]; got.Value != "512Mi" || got.Type != "string" {
+		t.Fatalf("unexpected loop variable after loop: %+v", got)
+	}
+}
+
 func TestExecuteValuesExpandsVariables(t *testing.T) {
 	act := action{}
 	execCtx := &registry.ExecutionContext{
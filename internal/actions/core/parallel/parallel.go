@@ -3,11 +3,15 @@ package parallel
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"flowk/internal/actions/registry"
 	"flowk/internal/flow"
@@ -17,8 +21,23 @@ const (
 	// ActionName identifies the parallel action in flow definitions.
 	ActionName = "PARALLEL"
 
-	mergeStrategyLastWrite      = "last_write_wins"
-	mergeStrategyFailOnConflict = "fail_on_conflict"
+	// MergeStrategyLastWriteWins keeps the value of the last subtask in
+	// merge order that wrote a variable. It is the default.
+	MergeStrategyLastWriteWins = "last_write_wins"
+	// MergeStrategyFirstWriteWins keeps the value of the first subtask in
+	// merge order that wrote a variable.
+	MergeStrategyFirstWriteWins = "first_write_wins"
+	// MergeStrategyFailOnConflict fails the action when two subtasks write
+	// different values to the same variable. MergeStrategyErrorOnConflict
+	// is accepted as an alias.
+	MergeStrategyFailOnConflict  = "fail_on_conflict"
+	MergeStrategyErrorOnConflict = "error_on_conflict"
+	// MergeStrategyNamespaceBySubtask stores each variable a subtask wrote as
+	// "<subtask id>.<name>", so subtasks never collide.
+	MergeStrategyNamespaceBySubtask = "namespace_by_subtask"
+
+	// errorsKey holds the per-subtask error summary in the aggregated result.
+	errorsKey = "errors"
 )
 
 // Payload describes the configuration supported by the PARALLEL action.
@@ -27,6 +46,11 @@ type Payload struct {
 	FailFast      bool        `json:"fail_fast"`
 	MergeStrategy string      `json:"merge_strategy"`
 	MergeOrder    []string    `json:"merge_order"`
+	// MaxConcurrency bounds how many subtasks run at once. Zero means unlimited.
+	MaxConcurrency int `json:"max_concurrency"`
+	// GroupTimeoutSeconds bounds the whole group. Subtasks still running when
+	// it expires are cancelled and reported as failed.
+	GroupTimeoutSeconds float64 `json:"group_timeout_seconds"`
 }
 
 type action struct{}
@@ -53,11 +77,21 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 		return registry.Result{}, fmt.Errorf("parallel action: tasks is required")
 	}
 
+	if cfg.MaxConcurrency < 0 {
+		return registry.Result{}, fmt.Errorf("parallel action: max_concurrency must be greater than or equal to zero")
+	}
+
+	if cfg.GroupTimeoutSeconds < 0 {
+		return registry.Result{}, fmt.Errorf("parallel action: group_timeout_seconds must be greater than or equal to zero")
+	}
+
 	strategy := strings.ToLower(strings.TrimSpace(cfg.MergeStrategy))
 	switch strategy {
-	case "", mergeStrategyLastWrite:
-		strategy = mergeStrategyLastWrite
-	case mergeStrategyFailOnConflict:
+	case "", MergeStrategyLastWriteWins:
+		strategy = MergeStrategyLastWriteWins
+	case MergeStrategyErrorOnConflict:
+		strategy = MergeStrategyFailOnConflict
+	case MergeStrategyFirstWriteWins, MergeStrategyFailOnConflict, MergeStrategyNamespaceBySubtask:
 	default:
 		return registry.Result{}, fmt.Errorf("parallel action: unsupported merge_strategy %q", cfg.MergeStrategy)
 	}
@@ -68,6 +102,9 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 		if cfg.Tasks[i].ID == "" {
 			return registry.Result{}, fmt.Errorf("parallel action: tasks[%d]: id is required", i)
 		}
+		if cfg.Tasks[i].ID == errorsKey {
+			return registry.Result{}, fmt.Errorf("parallel action: tasks[%d]: id %q is reserved", i, errorsKey)
+		}
 		taskIDs[cfg.Tasks[i].ID] = struct{}{}
 		if cfg.Tasks[i].FlowID == "" && execCtx.Task != nil {
 			cfg.Tasks[i].FlowID = execCtx.Task.FlowID
@@ -89,13 +126,18 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 
 	var mu sync.Mutex
 
-	ctxForTasks := ctx
-	var cancel context.CancelFunc
-	if cfg.FailFast {
-		ctxForTasks, cancel = context.WithCancel(ctx)
+	ctxForTasks, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if cfg.GroupTimeoutSeconds > 0 {
+		ctxForTasks, cancel = context.WithTimeout(ctxForTasks, time.Duration(cfg.GroupTimeoutSeconds*float64(time.Second)))
 		defer cancel()
 	}
 
+	var slots chan struct{}
+	if cfg.MaxConcurrency > 0 && cfg.MaxConcurrency < len(cfg.Tasks) {
+		slots = make(chan struct{}, cfg.MaxConcurrency)
+	}
+
 	var wg sync.WaitGroup
 
 	for i := range cfg.Tasks {
@@ -105,6 +147,18 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 		go func(task flow.Task) {
 			defer wg.Done()
 
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-ctxForTasks.Done():
+					mu.Lock()
+					taskErrors[task.ID] = ctxForTasks.Err()
+					mu.Unlock()
+					return
+				}
+			}
+
 			req := registry.TaskExecutionRequest{
 				Task:      &task,
 				Tasks:     baseTasks,
@@ -119,7 +173,7 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 
 			if execErr != nil {
 				taskErrors[task.ID] = execErr
-				if cfg.FailFast && cancel != nil {
+				if cfg.FailFast {
 					cancel()
 				}
 				return
@@ -152,6 +206,9 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 				failures = append(failures, fmt.Sprintf("%s: %v", task.ID, err))
 			}
 		}
+		if errors.Is(ctxForTasks.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return finalResult, fmt.Errorf("parallel action: timed out after %gs: %d subtasks failed (%s)", cfg.GroupTimeoutSeconds, len(taskErrors), strings.Join(failures, "; "))
+		}
 		return finalResult, fmt.Errorf("parallel action: %d subtasks failed (%s)", len(taskErrors), strings.Join(failures, "; "))
 	}
 
@@ -187,28 +244,38 @@ func buildMergeSequence(mergeOrder []string, tasks []flow.Task, taskIDs map[stri
 	return order, nil
 }
 
+// mergeVariables applies the variables written by each successful subtask on
+// top of base, following sequence. A subtask writes a variable when it creates
+// it or changes its value; the unchanged copies of base variables every
+// subtask returns are ignored, so they never override another subtask's write.
 func mergeVariables(strategy string, sequence []string, base map[string]registry.Variable, updates map[string]map[string]registry.Variable, taskErrors map[string]error) (map[string]registry.Variable, error) {
 	merged := cloneRegistryVariables(base)
 	origin := make(map[string]string)
 
-	for name := range merged {
-		origin[name] = "base"
-	}
-
 	for _, taskID := range sequence {
 		if taskErrors[taskID] != nil {
 			continue
 		}
 
 		vars := updates[taskID]
-		if len(vars) == 0 {
-			continue
-		}
+		for _, name := range slices.Sorted(maps.Keys(vars)) {
+			variable := vars[name]
+			if existing, inBase := base[name]; inBase && registryVariableEqual(existing, variable) {
+				continue
+			}
+
+			if strategy == MergeStrategyNamespaceBySubtask {
+				variable.Name = taskID + "." + name
+				merged[variable.Name] = variable
+				continue
+			}
 
-		for name, variable := range vars {
-			if existing, exists := merged[name]; exists {
-				if strategy == mergeStrategyFailOnConflict && !registryVariableEqual(existing, variable) {
-					return nil, fmt.Errorf("parallel action: variable %q conflict between tasks %s and %s", name, origin[name], taskID)
+			if writer, written := origin[name]; written {
+				if strategy == MergeStrategyFirstWriteWins {
+					continue
+				}
+				if strategy == MergeStrategyFailOnConflict && !registryVariableEqual(merged[name], variable) {
+					return nil, fmt.Errorf("parallel action: variable %q conflict between tasks %s and %s", name, writer, taskID)
 				}
 			}
 
@@ -220,21 +287,32 @@ func mergeVariables(strategy string, sequence []string, base map[string]registry
 	return merged, nil
 }
 
+// aggregateResults builds the action result: one entry per subtask keyed by
+// its id, plus an errors entry mapping each failed subtask to its error when
+// at least one subtask failed.
 func aggregateResults(tasks []flow.Task, results map[string]registry.Result, taskErrors map[string]error) map[string]map[string]any {
-	aggregated := make(map[string]map[string]any, len(tasks))
+	aggregated := make(map[string]map[string]any, len(tasks)+1)
+	failures := map[string]any{}
 
 	for _, task := range tasks {
 		entry := map[string]any{}
-		if res, exists := results[task.ID]; exists {
+		res, succeeded := results[task.ID]
+		if succeeded {
 			entry["result"] = res.Value
 			entry["type"] = string(res.Type)
 		}
 		if err := taskErrors[task.ID]; err != nil {
 			entry["error"] = err.Error()
+			failures[task.ID] = err.Error()
+			succeeded = false
 		}
+		entry["success"] = succeeded
 		aggregated[task.ID] = entry
 	}
 
+	if len(failures) > 0 {
+		aggregated[errorsKey] = failures
+	}
 	return aggregated
 }
 
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"flowk/internal/actions/registry"
 	"flowk/internal/flow"
@@ -127,6 +128,10 @@ func TestActionExecuteSuccess(t *testing.T) {
 		t.Fatalf("alpha result mismatch: %v", got)
 	}
 
+	if _, exists := aggregated["errors"]; exists {
+		t.Fatalf("unexpected errors entry when all subtasks succeed: %v", aggregated["errors"])
+	}
+
 	bravoEntry, exists := aggregated["bravo"]
 	if !exists {
 		t.Fatalf("missing bravo entry in aggregated result")
@@ -267,3 +272,258 @@ func TestActionExecuteMergeConflict(t *testing.T) {
 		}
 	}
 }
+
+func TestActionExecuteMergeStrategies(t *testing.T) {
+	t.Parallel()
+
+	str := func(name, value string) registry.Variable {
+		return registry.Variable{Name: name, Type: "string", Value: value}
+	}
+	base := map[string]registry.Variable{
+		"shared": str("shared", "base"),
+		"kept":   str("kept", "base"),
+	}
+	// Every subtask returns its whole scope, as the task executor does: the
+	// base variables it did not touch come back unchanged.
+	subtaskVars := map[string]map[string]registry.Variable{
+		"alpha": {"shared": str("shared", "from-alpha"), "kept": str("kept", "base"), "alpha_only": str("alpha_only", "a")},
+		"bravo": {"shared": str("shared", "from-bravo"), "kept": str("kept", "from-bravo")},
+		"delta": {"shared": str("shared", "base"), "kept": str("kept", "base")},
+	}
+
+	tests := []struct {
+		strategy string
+		want     map[string]string
+		wantErr  string
+	}{
+		{strategy: "last_write_wins", want: map[string]string{"shared": "from-alpha", "kept": "from-bravo", "alpha_only": "a"}},
+		{strategy: "first_write_wins", want: map[string]string{"shared": "from-bravo", "kept": "from-bravo", "alpha_only": "a"}},
+		{strategy: "error_on_conflict", wantErr: `variable "shared" conflict between tasks bravo and alpha`},
+		{strategy: "namespace_by_subtask", want: map[string]string{
+			"shared": "base", "kept": "base",
+			"alpha.shared": "from-alpha", "alpha.alpha_only": "a",
+			"bravo.shared": "from-bravo", "bravo.kept": "from-bravo",
+		}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.strategy, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := json.Marshal(map[string]any{
+				"tasks": []map[string]any{
+					{"id": "alpha", "action": "VARIABLES"},
+					{"id": "bravo", "action": "VARIABLES"},
+					{"id": "delta", "action": "VARIABLES"},
+				},
+				"merge_order":    []string{"delta", "bravo", "alpha"},
+				"merge_strategy": tt.strategy,
+			})
+			if err != nil {
+				t.Fatalf("marshal payload: %v", err)
+			}
+
+			execCtx := &registry.ExecutionContext{
+				Task:      &flow.Task{ID: "parent", FlowID: "main"},
+				Variables: cloneRegistryVariables(base),
+				LogDir:    t.TempDir(),
+			}
+			execCtx.ExecuteTask = func(ctx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+				return registry.TaskExecutionResponse{Variables: cloneRegistryVariables(subtaskVars[req.Task.ID])}, nil
+			}
+
+			_, err = action{}.Execute(context.Background(), raw, execCtx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("execute parallel action: %v", err)
+			}
+
+			got := make(map[string]string, len(execCtx.Variables))
+			for name, variable := range execCtx.Variables {
+				if variable.Name != name {
+					t.Fatalf("variable %q has name %q", name, variable.Name)
+				}
+				got[name] = variable.Value.(string)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("variables = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActionExecuteHonorsMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]any{
+		"tasks": []map[string]any{
+			{"id": "one", "action": "PRINT"},
+			{"id": "two", "action": "PRINT"},
+			{"id": "three", "action": "PRINT"},
+		},
+		"max_concurrency": 1,
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	execCtx := &registry.ExecutionContext{
+		Task:   &flow.Task{ID: "parent", FlowID: "main"},
+		LogDir: t.TempDir(),
+	}
+
+	var running, peak atomic.Int64
+
+	execCtx.ExecuteTask = func(ctx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			observed := peak.Load()
+			if current <= observed || peak.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return registry.TaskExecutionResponse{
+			Result: registry.Result{Value: req.Task.ID, Type: flow.ResultTypeString},
+		}, nil
+	}
+
+	result, err := action{}.Execute(context.Background(), raw, execCtx)
+	if err != nil {
+		t.Fatalf("execute parallel action: %v", err)
+	}
+
+	if got := peak.Load(); got != 1 {
+		t.Fatalf("expected at most one subtask running at a time, observed %d", got)
+	}
+
+	aggregated, ok := result.Value.(map[string]map[string]any)
+	if !ok {
+		t.Fatalf("result value type %T, want map[string]map[string]any", result.Value)
+	}
+	for _, id := range []string{"one", "two", "three"} {
+		if got := aggregated[id]["result"]; got != id {
+			t.Fatalf("result for %s mismatch: %v", id, got)
+		}
+	}
+}
+
+func TestActionExecuteRejectsNegativeMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	raw := json.RawMessage(`{"tasks":[{"id":"one","action":"PRINT"}],"max_concurrency":-1}`)
+	execCtx := &registry.ExecutionContext{
+		ExecuteTask: func(context.Context, registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+			return registry.TaskExecutionResponse{}, nil
+		},
+	}
+
+	_, err := action{}.Execute(context.Background(), raw, execCtx)
+	if err == nil || !strings.Contains(err.Error(), "max_concurrency") {
+		t.Fatalf("expected max_concurrency error, got %v", err)
+	}
+}
+
+func TestActionExecuteSummarizesFailuresWithoutFailFast(t *testing.T) {
+	t.Parallel()
+
+	raw := json.RawMessage(`{"tasks":[{"id":"ok","action":"PRINT"},{"id":"broken","action":"PRINT"}],"fail_fast":false}`)
+	execCtx := &registry.ExecutionContext{
+		Task:   &flow.Task{ID: "parent", FlowID: "main"},
+		LogDir: t.TempDir(),
+	}
+
+	execCtx.ExecuteTask = func(ctx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+		if req.Task.ID == "broken" {
+			return registry.TaskExecutionResponse{}, errors.New("boom")
+		}
+		return registry.TaskExecutionResponse{
+			Result: registry.Result{Value: "done", Type: flow.ResultTypeString},
+			Variables: map[string]registry.Variable{
+				"from_ok": {Name: "from_ok", Type: "string", Value: "kept"},
+			},
+		}, nil
+	}
+
+	result, err := action{}.Execute(context.Background(), raw, execCtx)
+	if err == nil || !strings.Contains(err.Error(), "1 subtasks failed (broken: boom)") {
+		t.Fatalf("expected subtask failure error, got %v", err)
+	}
+
+	aggregated, ok := result.Value.(map[string]map[string]any)
+	if !ok {
+		t.Fatalf("result value type %T, want map[string]map[string]any", result.Value)
+	}
+	if got := aggregated["ok"]["success"]; got != true {
+		t.Fatalf("ok success = %v, want true", got)
+	}
+	if got := aggregated["broken"]["success"]; got != false {
+		t.Fatalf("broken success = %v, want false", got)
+	}
+	if got := aggregated["errors"]; len(got) != 1 || got["broken"] != "boom" {
+		t.Fatalf("errors summary = %v, want broken: boom", got)
+	}
+
+	if got := execCtx.Variables["from_ok"].Value; got != "kept" {
+		t.Fatalf("variables from completed subtasks should be merged, got %v", got)
+	}
+}
+
+func TestActionExecuteCancelsSubtasksOnTimeout(t *testing.T) {
+	t.Parallel()
+
+	raw := json.RawMessage(`{"tasks":[{"id":"fast","action":"PRINT"},{"id":"slow","action":"PRINT"}],"group_timeout_seconds":0.05}`)
+	execCtx := &registry.ExecutionContext{
+		Task:   &flow.Task{ID: "parent", FlowID: "main"},
+		LogDir: t.TempDir(),
+	}
+
+	execCtx.ExecuteTask = func(ctx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+		if req.Task.ID == "slow" {
+			<-ctx.Done()
+			return registry.TaskExecutionResponse{}, ctx.Err()
+		}
+		return registry.TaskExecutionResponse{Result: registry.Result{Value: true, Type: flow.ResultTypeBool}}, nil
+	}
+
+	result, err := action{}.Execute(context.Background(), raw, execCtx)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 0.05s") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+
+	aggregated, ok := result.Value.(map[string]map[string]any)
+	if !ok {
+		t.Fatalf("result value type %T, want map[string]map[string]any", result.Value)
+	}
+	if got := aggregated["fast"]["success"]; got != true {
+		t.Fatalf("fast success = %v, want true", got)
+	}
+	if _, failed := aggregated["errors"]["slow"]; !failed {
+		t.Fatalf("expected slow in errors summary, got %v", aggregated["errors"])
+	}
+}
+
+func TestActionExecuteRejectsReservedTaskID(t *testing.T) {
+	t.Parallel()
+
+	raw := json.RawMessage(`{"tasks":[{"id":"errors","action":"PRINT"}]}`)
+	execCtx := &registry.ExecutionContext{
+		ExecuteTask: func(context.Context, registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+			return registry.TaskExecutionResponse{}, nil
+		},
+	}
+
+	_, err := action{}.Execute(context.Background(), raw, execCtx)
+	if err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Fatalf("expected reserved id error, got %v", err)
+	}
+}
@@ -28,6 +28,8 @@ func (action) Execute(_ context.Context, payload json.RawMessage, execCtx *regis
 		return registry.Result{}, err
 	}
 
+	cfg.Mask = execCtx.Mask
+
 	vars := make(map[string]variables.Variable, len(execCtx.Variables))
 	for name, variable := range execCtx.Variables {
 		vars[name] = variables.Variable{
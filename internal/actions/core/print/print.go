@@ -3,6 +3,8 @@ package print
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -14,6 +16,10 @@ import (
 const (
 	// ActionName identifies the Print action in the flow definition.
 	ActionName = "PRINT"
+
+	levelInfo  = "info"
+	levelWarn  = "warn"
+	levelError = "error"
 )
 
 // Logger matches the subset of the standard logger used by the executor.
@@ -24,6 +30,10 @@ type Logger interface {
 // Payload describes the configuration accepted by the PRINT action.
 type Payload struct {
 	Entries []Entry `json:"entries"`
+	Level   string  `json:"level,omitempty"`
+	File    string  `json:"file,omitempty"`
+	// Mask, when set, is applied to the lines appended to File.
+	Mask func(string) string `json:"-"`
 }
 
 // Entry represents a single value to render.
@@ -50,6 +60,11 @@ func (p Payload) Validate() error {
 	if len(p.Entries) == 0 {
 		return fmt.Errorf("print task: entries is required")
 	}
+	switch strings.ToLower(strings.TrimSpace(p.Level)) {
+	case "", levelInfo, levelWarn, levelError:
+	default:
+		return fmt.Errorf("print task: unsupported level %q", p.Level)
+	}
 
 	for i := range p.Entries {
 		if err := p.Entries[i].Validate(); err != nil {
@@ -91,6 +106,8 @@ func Execute(payload Payload, vars map[string]variables.Variable, tasks []flow.T
 	}
 
 	results := make([]ResultEntry, 0, len(payload.Entries))
+	prefix := levelPrefix(payload.Level)
+	var lines []string
 
 	for idx := range payload.Entries {
 		entry := payload.Entries[idx]
@@ -135,18 +152,67 @@ func Execute(payload Payload, vars map[string]variables.Variable, tasks []flow.T
 			}
 		}
 
-		if logger != nil {
-			if rendered != "" {
-				logger.Printf("%s", rendered)
+		if rendered != "" {
+			line := prefix + rendered
+			lines = append(lines, line)
+			if logger != nil {
+				logger.Printf("%s", line)
 			}
 		}
 
 		results = append(results, ResultEntry{Message: message, Value: value})
 	}
 
+	if path := strings.TrimSpace(payload.File); path != "" {
+		resolved, err := replaceVariablePlaceholders(path, vars)
+		if err != nil {
+			return nil, "", fmt.Errorf("print task: file: %w", err)
+		}
+		if payload.Mask != nil {
+			for i := range lines {
+				lines[i] = payload.Mask(lines[i])
+			}
+		}
+		if err := appendLines(resolved, lines); err != nil {
+			return nil, "", fmt.Errorf("print task: %w", err)
+		}
+	}
+
 	return results, flow.ResultTypeJSON, nil
 }
 
+// levelPrefix returns the marker prepended to every line for an explicit
+// level. Lines are left untouched when no level is configured.
+func levelPrefix(level string) string {
+	normalized := strings.ToLower(strings.TrimSpace(level))
+	if normalized == "" {
+		return ""
+	}
+	return "[" + strings.ToUpper(normalized) + "] "
+}
+
+func appendLines(path string, lines []string) error {
+	cleaned := filepath.Clean(path)
+	if dir := filepath.Dir(cleaned); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create directories for %s: %w", cleaned, err)
+		}
+	}
+
+	file, err := os.OpenFile(cleaned, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open file %s: %w", cleaned, err)
+	}
+	defer file.Close()
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(file, line); err != nil {
+			return fmt.Errorf("write file %s: %w", cleaned, err)
+		}
+	}
+	return nil
+}
+
 func resolveEntryValue(entry Entry, vars map[string]variables.Variable, tasks []flow.Task) (value any, hasValue bool, defaultMessage string, err error) {
 	if trimmedVar := strings.TrimSpace(entry.Variable); trimmedVar != "" {
 		if vars == nil {
@@ -161,7 +227,7 @@ func resolveEntryValue(entry Entry, vars map[string]variables.Variable, tasks []
 		hasValue = true
 		defaultMessage = fmt.Sprintf("variable %s", trimmedVar)
 		if variable.Secret {
-			value = "****"
+			value = "***"
 		} else {
 			value = variable.Value
 		}
@@ -260,7 +326,7 @@ func renderValueTemplate(template string, vars map[string]variables.Variable, ta
 			return nil, fmt.Errorf("variable %q not defined", name)
 		}
 		if variable.Secret {
-			return "****", nil
+			return "***", nil
 		}
 		return variable.Value, nil
 	}
@@ -304,7 +370,7 @@ func replaceVariablePlaceholders(value string, vars map[string]variables.Variabl
 		}
 
 		if variable.Secret {
-			return "****"
+			return "***"
 		}
 
 		return formatValue(variable.Value)
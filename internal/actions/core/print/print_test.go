@@ -2,6 +2,8 @@ package print
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"flowk/internal/actions/core/variables"
@@ -108,7 +110,7 @@ func TestExecuteMasksSecretVariables(t *testing.T) {
 	}
 
 	entries := result.([]ResultEntry)
-	if entries[0].Value != "****" {
+	if entries[0].Value != "***" {
 		t.Fatalf("secret variable was not masked: %+v", entries[0])
 	}
 }
@@ -194,25 +196,25 @@ func TestExecuteMasksSecretPlaceholders(t *testing.T) {
 		t.Fatalf("entries length = %d, want 2", len(entries))
 	}
 
-	if entries[0].Message != "Token ****" {
-		t.Fatalf("entries[0].Message = %q, want Token ****", entries[0].Message)
+	if entries[0].Message != "Token ***" {
+		t.Fatalf("entries[0].Message = %q, want Token ***", entries[0].Message)
 	}
 	if entries[0].Value != nil {
 		t.Fatalf("entries[0].Value = %v, want nil", entries[0].Value)
 	}
 
-	if entries[1].Value != "Secret is ****" {
-		t.Fatalf("entries[1].Value = %v, want Secret is ****", entries[1].Value)
+	if entries[1].Value != "Secret is ***" {
+		t.Fatalf("entries[1].Value = %v, want Secret is ***", entries[1].Value)
 	}
 
 	if len(logger.messages) != 2 {
 		t.Fatalf("logged messages = %d, want 2", len(logger.messages))
 	}
-	if logger.messages[0] != "Token ****" {
-		t.Fatalf("logger.messages[0] = %q, want Token ****", logger.messages[0])
+	if logger.messages[0] != "Token ***" {
+		t.Fatalf("logger.messages[0] = %q, want Token ***", logger.messages[0])
 	}
-	if logger.messages[1] != "Secret is ****" {
-		t.Fatalf("logger.messages[1] = %q, want Secret is ****", logger.messages[1])
+	if logger.messages[1] != "Secret is ***" {
+		t.Fatalf("logger.messages[1] = %q, want Secret is ***", logger.messages[1])
 	}
 }
 
@@ -285,6 +287,14 @@ func TestExecuteErrors(t *testing.T) {
 			name:    "missing task placeholder",
 			payload: Payload{Entries: []Entry{{Value: "${from.task:unknown.result$}"}}},
 		},
+		{
+			name:    "unsupported level",
+			payload: Payload{Entries: []Entry{{Message: "hi"}}, Level: "debug"},
+		},
+		{
+			name:    "unknown variable in file",
+			payload: Payload{Entries: []Entry{{Message: "hi"}}, File: "${missing}/report.txt"},
+		},
 	}
 
 	tasks := []flow.Task{}
@@ -298,3 +308,40 @@ func TestExecuteErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutePrefixesLevelAndAppendsToFile(t *testing.T) {
+	dir := t.TempDir()
+	vars := map[string]variables.Variable{
+		"reportDir": {Name: "reportDir", Type: "string", Value: dir},
+		"token":     {Name: "token", Type: "secret", Value: "s3cr3t", Secret: true},
+	}
+
+	payload := Payload{
+		Entries: []Entry{
+			{Message: "Deployment failed"},
+			{Message: "Token", Value: "${token}"},
+		},
+		Level: "error",
+		File:  "${reportDir}/reports/summary.txt",
+	}
+
+	logger := &stubLogger{}
+	for i := 0; i < 2; i++ {
+		if _, _, err := Execute(payload, vars, nil, logger); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	if len(logger.messages) != 4 || logger.messages[0] != "[ERROR] Deployment failed" || logger.messages[1] != "[ERROR] Token: ***" {
+		t.Fatalf("unexpected log messages: %v", logger.messages)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "reports", "summary.txt"))
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+	want := "[ERROR] Deployment failed\n[ERROR] Token: ***\n[ERROR] Deployment failed\n[ERROR] Token: ***\n"
+	if string(data) != want {
+		t.Fatalf("report = %q, want %q", string(data), want)
+	}
+}
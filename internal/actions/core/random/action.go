@@ -0,0 +1,57 @@
+package random
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+type action struct{}
+
+func init() {
+	registry.Register(action{})
+}
+
+func (action) Name() string {
+	return ActionName
+}
+
+func (action) Execute(_ context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	var cfg Payload
+	if err := json.Unmarshal(payload, &cfg); err != nil {
+		return registry.Result{}, fmt.Errorf("decoding random task payload: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return registry.Result{}, err
+	}
+
+	value, resultType, err := Execute(cfg)
+	if err != nil {
+		return registry.Result{}, err
+	}
+
+	if cfg.Variable != "" {
+		if execCtx.Variables == nil {
+			execCtx.Variables = make(map[string]registry.Variable)
+		}
+		variable := registry.Variable{Name: cfg.Variable, Type: "string", Value: value, Secret: cfg.Secret}
+		if number, ok := value.(int64); ok {
+			variable.Type, variable.Value = "number", float64(number)
+		}
+		execCtx.Variables[cfg.Variable] = variable
+		if execCtx.Logger != nil {
+			execCtx.Logger.Printf("RANDOM: stored %s", cfg.Variable)
+		}
+	}
+
+	if cfg.Secret {
+		return registry.Result{Value: "***", Type: flow.ResultTypeString}, nil
+	}
+	if execCtx.Logger != nil {
+		execCtx.Logger.Printf("RANDOM: %v", value)
+	}
+	return registry.Result{Value: value, Type: resultType}, nil
+}
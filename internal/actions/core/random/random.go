@@ -0,0 +1,157 @@
+package random
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	mathrand "math/rand/v2"
+	"strings"
+
+	"flowk/internal/flow"
+)
+
+const (
+	// ActionName identifies the Random action in the flow definition.
+	ActionName = "RANDOM"
+
+	OperationUUID      = "UUID"
+	OperationHex       = "HEX"
+	OperationBase64    = "BASE64"
+	OperationBase64URL = "BASE64_URL"
+	OperationInt       = "INT"
+
+	// DefaultBytes is the number of random bytes HEX and BASE64 encode when
+	// bytes is not set.
+	DefaultBytes = 16
+	// MaxBytes bounds bytes so a typo cannot produce a huge value.
+	MaxBytes = 1024
+)
+
+// Payload describes the configuration accepted by the RANDOM action.
+type Payload struct {
+	// Operation selects what is generated: UUID (version 4), HEX, BASE64 or
+	// BASE64_URL of Bytes random bytes, or INT between Min and Max.
+	Operation string `json:"operation"`
+	// Bytes is the number of random bytes HEX and BASE64 encode.
+	Bytes int `json:"bytes,omitempty"`
+	// Min and Max are the inclusive bounds of INT. Min defaults to 0.
+	Min *int64 `json:"min,omitempty"`
+	Max *int64 `json:"max,omitempty"`
+	// Seed makes the value deterministic: the same seed always produces the
+	// same value. Without it values come from crypto/rand.
+	Seed *int64 `json:"seed,omitempty"`
+	// Variable, when set, stores the value as a flow variable.
+	Variable string `json:"variable,omitempty"`
+	// Secret masks the value in logs and in the task result.
+	Secret bool `json:"secret,omitempty"`
+}
+
+// Validate normalizes the payload and ensures it is well formed.
+func (p *Payload) Validate() error {
+	p.Operation = strings.ToUpper(strings.TrimSpace(p.Operation))
+	p.Variable = strings.TrimSpace(p.Variable)
+
+	switch p.Operation {
+	case OperationUUID:
+		if p.Bytes != 0 || p.Min != nil || p.Max != nil {
+			return fmt.Errorf("random task: bytes, min and max are not supported for UUID operation")
+		}
+	case OperationHex, OperationBase64, OperationBase64URL:
+		if p.Min != nil || p.Max != nil {
+			return fmt.Errorf("random task: min and max are only supported for INT operation")
+		}
+		if p.Bytes == 0 {
+			p.Bytes = DefaultBytes
+		}
+		if p.Bytes < 0 || p.Bytes > MaxBytes {
+			return fmt.Errorf("random task: bytes must be between 1 and %d", MaxBytes)
+		}
+	case OperationInt:
+		if p.Bytes != 0 {
+			return fmt.Errorf("random task: bytes is only supported for HEX, BASE64 and BASE64_URL operations")
+		}
+		if p.Max == nil {
+			return fmt.Errorf("random task: max is required for INT operation")
+		}
+		if p.Min == nil {
+			p.Min = new(int64)
+		}
+		if *p.Min > *p.Max {
+			return fmt.Errorf("random task: min %d is greater than max %d", *p.Min, *p.Max)
+		}
+	default:
+		return fmt.Errorf("random task: unsupported operation %q", p.Operation)
+	}
+
+	for _, r := range p.Variable {
+		if !(r == '_' || r == '-' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return fmt.Errorf("random task: variable %q contains invalid character %q", p.Variable, r)
+		}
+	}
+	return nil
+}
+
+// Execute generates the value described by payload. Strings are returned for
+// UUID, HEX and BASE64, an int64 for INT.
+func Execute(payload Payload) (any, flow.ResultType, error) {
+	source := io.Reader(rand.Reader)
+	if payload.Seed != nil {
+		source = seededSource(*payload.Seed)
+	}
+
+	switch payload.Operation {
+	case OperationUUID:
+		id, err := newUUID(source)
+		if err != nil {
+			return nil, "", err
+		}
+		return id, flow.ResultTypeString, nil
+	case OperationHex, OperationBase64, OperationBase64URL:
+		buf := make([]byte, payload.Bytes)
+		if _, err := io.ReadFull(source, buf); err != nil {
+			return nil, "", fmt.Errorf("random task: reading random bytes: %w", err)
+		}
+		switch payload.Operation {
+		case OperationHex:
+			return hex.EncodeToString(buf), flow.ResultTypeString, nil
+		case OperationBase64:
+			return base64.StdEncoding.EncodeToString(buf), flow.ResultTypeString, nil
+		default:
+			return base64.RawURLEncoding.EncodeToString(buf), flow.ResultTypeString, nil
+		}
+	case OperationInt:
+		// The span is computed with big integers so the full int64 range
+		// does not overflow.
+		span := new(big.Int).Sub(big.NewInt(*payload.Max), big.NewInt(*payload.Min))
+		span.Add(span, big.NewInt(1))
+		n, err := rand.Int(source, span)
+		if err != nil {
+			return nil, "", fmt.Errorf("random task: generating integer: %w", err)
+		}
+		return n.Add(n, big.NewInt(*payload.Min)).Int64(), flow.ResultTypeInt, nil
+	default:
+		return nil, "", fmt.Errorf("random task: unsupported operation %q", payload.Operation)
+	}
+}
+
+// seededSource returns a deterministic stream of bytes for seed.
+func seededSource(seed int64) io.Reader {
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[:8], uint64(seed))
+	return mathrand.NewChaCha8(key)
+}
+
+// newUUID returns a version 4 UUID (RFC 9562) read from source.
+func newUUID(source io.Reader) (string, error) {
+	var id [16]byte
+	if _, err := io.ReadFull(source, id[:]); err != nil {
+		return "", fmt.Errorf("random task: reading random bytes: %w", err)
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16]), nil
+}
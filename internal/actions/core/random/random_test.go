@@ -0,0 +1,156 @@
+package random
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestPayloadValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		payload   Payload
+		wantErr   string
+		wantBytes int
+	}{
+		{name: "unknown operation", payload: Payload{Operation: "WORD"}, wantErr: "unsupported operation"},
+		{name: "uuid with bytes", payload: Payload{Operation: OperationUUID, Bytes: 8}, wantErr: "not supported for UUID"},
+		{name: "hex with max", payload: Payload{Operation: OperationHex, Max: int64Ptr(3)}, wantErr: "only supported for INT"},
+		{name: "too many bytes", payload: Payload{Operation: OperationBase64, Bytes: MaxBytes + 1}, wantErr: "bytes must be between"},
+		{name: "int without max", payload: Payload{Operation: OperationInt}, wantErr: "max is required"},
+		{name: "int with inverted range", payload: Payload{Operation: OperationInt, Min: int64Ptr(5), Max: int64Ptr(1)}, wantErr: "greater than max"},
+		{name: "bad variable", payload: Payload{Operation: OperationUUID, Variable: "run id"}, wantErr: "invalid character"},
+		{name: "hex defaults bytes", payload: Payload{Operation: "hex"}, wantBytes: DefaultBytes},
+		{name: "valid int", payload: Payload{Operation: OperationInt, Max: int64Ptr(10), Variable: "port"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.payload.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if tt.wantBytes != 0 && tt.payload.Bytes != tt.wantBytes {
+				t.Fatalf("bytes = %d, want %d", tt.payload.Bytes, tt.wantBytes)
+			}
+		})
+	}
+}
+
+func generate(t *testing.T, payload Payload) (any, flow.ResultType) {
+	t.Helper()
+	if err := payload.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	value, resultType, err := Execute(payload)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	return value, resultType
+}
+
+func TestExecuteFormats(t *testing.T) {
+	t.Parallel()
+
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first, resultType := generate(t, Payload{Operation: OperationUUID})
+	second, _ := generate(t, Payload{Operation: OperationUUID})
+	if resultType != flow.ResultTypeString || !uuidPattern.MatchString(first.(string)) {
+		t.Fatalf("UUID = %v (%s), want a version 4 UUID string", first, resultType)
+	}
+	if first == second {
+		t.Fatalf("two unseeded UUIDs are both %v", first)
+	}
+
+	hexValue, _ := generate(t, Payload{Operation: OperationHex, Bytes: 4})
+	if !regexp.MustCompile(`^[0-9a-f]{8}$`).MatchString(hexValue.(string)) {
+		t.Fatalf("HEX = %v, want 8 hex characters", hexValue)
+	}
+
+	encoded, _ := generate(t, Payload{Operation: OperationBase64})
+	if decoded, err := base64.StdEncoding.DecodeString(encoded.(string)); err != nil || len(decoded) != DefaultBytes {
+		t.Fatalf("BASE64 = %v, want %d encoded bytes (err %v)", encoded, DefaultBytes, err)
+	}
+
+	urlEncoded, _ := generate(t, Payload{Operation: OperationBase64URL, Bytes: 32})
+	if decoded, err := base64.RawURLEncoding.DecodeString(urlEncoded.(string)); err != nil || len(decoded) != 32 {
+		t.Fatalf("BASE64_URL = %v, want 32 encoded bytes (err %v)", urlEncoded, err)
+	}
+
+	for i := 0; i < 50; i++ {
+		n, resultType := generate(t, Payload{Operation: OperationInt, Min: int64Ptr(-2), Max: int64Ptr(2)})
+		if resultType != flow.ResultTypeInt || n.(int64) < -2 || n.(int64) > 2 {
+			t.Fatalf("INT = %v (%s), want an int between -2 and 2", n, resultType)
+		}
+	}
+	full, _ := generate(t, Payload{Operation: OperationInt, Min: int64Ptr(-1 << 63), Max: int64Ptr(1<<63 - 1)})
+	if _, ok := full.(int64); !ok {
+		t.Fatalf("INT over the full range = %T, want int64", full)
+	}
+}
+
+func TestExecuteSeedIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	for _, operation := range []string{OperationUUID, OperationHex, OperationBase64, OperationBase64URL, OperationInt} {
+		payload := Payload{Operation: operation, Seed: int64Ptr(42)}
+		if operation == OperationInt {
+			payload.Max = int64Ptr(1_000_000)
+		}
+		first, _ := generate(t, payload)
+		second, _ := generate(t, payload)
+		if first != second {
+			t.Fatalf("%s with seed 42 = %v then %v, want the same value", operation, first, second)
+		}
+
+		payload.Seed = int64Ptr(43)
+		other, _ := generate(t, payload)
+		if other == first {
+			t.Fatalf("%s with seeds 42 and 43 both = %v", operation, first)
+		}
+	}
+}
+
+func TestActionStoresSecretVariable(t *testing.T) {
+	t.Parallel()
+
+	execCtx := &registry.ExecutionContext{}
+	payload := json.RawMessage(`{"operation":"HEX","bytes":8,"variable":"token","secret":true}`)
+	result, err := action{}.Execute(context.Background(), payload, execCtx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	stored := execCtx.Variables["token"]
+	if !stored.Secret || stored.Type != "string" || len(stored.Value.(string)) != 16 {
+		t.Fatalf("stored variable = %+v, want a secret 16-character string", stored)
+	}
+	if result.Value != "***" {
+		t.Fatalf("result = %v, want the masked value", result.Value)
+	}
+
+	payload = json.RawMessage(`{"operation":"INT","min":1,"max":1,"variable":"one"}`)
+	if _, err := (action{}).Execute(context.Background(), payload, execCtx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if stored := execCtx.Variables["one"]; stored.Type != "number" || stored.Value != float64(1) {
+		t.Fatalf("stored variable = %+v, want number 1", stored)
+	}
+}
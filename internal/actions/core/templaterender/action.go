@@ -0,0 +1,39 @@
+package templaterender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"flowk/internal/actions/registry"
+)
+
+type action struct{}
+
+func init() {
+	registry.Register(action{})
+}
+
+func (action) Name() string {
+	return ActionName
+}
+
+func (action) Execute(_ context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	var cfg Payload
+	if err := json.Unmarshal(payload, &cfg); err != nil {
+		return registry.Result{}, fmt.Errorf("decoding template render task payload: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return registry.Result{}, err
+	}
+
+	value, resultType, err := Execute(cfg, execCtx.Variables, execCtx.Tasks)
+	if err != nil {
+		return registry.Result{}, err
+	}
+
+	if result, ok := value.(Result); ok && execCtx.Logger != nil {
+		execCtx.Logger.Printf("TEMPLATE_RENDER: wrote %d bytes to %s", result.Bytes, result.Path)
+	}
+	return registry.Result{Value: value, Type: resultType}, nil
+}
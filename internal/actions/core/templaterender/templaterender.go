@@ -0,0 +1,133 @@
+package templaterender
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+	"flowk/internal/shared/jsonpathutil"
+)
+
+const (
+	// ActionName identifies the Template Render action in the flow definition.
+	ActionName = "TEMPLATE_RENDER"
+
+	// OptionMissingKeyError fails the task when the template references a
+	// key that does not exist. It is the default.
+	OptionMissingKeyError = "missingkey=error"
+	// OptionMissingKeyZero renders a missing key as empty text.
+	OptionMissingKeyZero = "missingkey=zero"
+
+	// TasksKey is the name under which prior task results are exposed to
+	// the template. It shadows a flow variable with the same name.
+	TasksKey = "tasks"
+
+	// noValue is what text/template prints for a missing key of a map of
+	// interfaces, even with missingkey=zero.
+	noValue = "<no value>"
+)
+
+// Payload describes the configuration accepted by the TEMPLATE_RENDER action.
+type Payload struct {
+	// Template is the inline Go text/template. Use this or TemplatePath.
+	Template string `json:"template,omitempty"`
+	// TemplatePath reads the template from a file instead.
+	TemplatePath string `json:"template_path,omitempty"`
+	// OutputPath, when set, receives the rendered text instead of the task
+	// result. Missing parent directories are created.
+	OutputPath string `json:"output_path,omitempty"`
+	// Option controls references to missing keys: missingkey=error (the
+	// default) fails the task, missingkey=zero renders them as empty text.
+	Option string `json:"option,omitempty"`
+}
+
+// Result describes a template written to OutputPath.
+type Result struct {
+	Path  string `json:"path"`
+	Bytes int    `json:"bytes"`
+}
+
+// Validate normalizes the payload and ensures it is well formed.
+func (p *Payload) Validate() error {
+	p.TemplatePath = strings.TrimSpace(p.TemplatePath)
+	p.OutputPath = strings.TrimSpace(p.OutputPath)
+	p.Option = strings.ToLower(strings.TrimSpace(p.Option))
+
+	if (p.Template == "") == (p.TemplatePath == "") {
+		return fmt.Errorf("template render task: exactly one of template or template_path is required")
+	}
+
+	switch p.Option {
+	case "":
+		p.Option = OptionMissingKeyError
+	case OptionMissingKeyError, OptionMissingKeyZero:
+	default:
+		return fmt.Errorf("template render task: unsupported option %q (use %s or %s)", p.Option, OptionMissingKeyError, OptionMissingKeyZero)
+	}
+	return nil
+}
+
+// Execute renders the template against the flow variables and the results of
+// the completed tasks. The rendered text is returned as a string, or written
+// to OutputPath and described by a Result.
+func Execute(payload Payload, vars map[string]registry.Variable, tasks []flow.Task) (any, flow.ResultType, error) {
+	name, text := "template", payload.Template
+	if payload.TemplatePath != "" {
+		data, err := os.ReadFile(payload.TemplatePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("template render task: reading template %s: %w", payload.TemplatePath, err)
+		}
+		name, text = filepath.Base(payload.TemplatePath), string(data)
+	}
+
+	tmpl, err := template.New(name).Option(payload.Option).Funcs(sprig.TxtFuncMap()).Parse(text)
+	if err != nil {
+		return nil, "", fmt.Errorf("template render task: parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData(vars, tasks)); err != nil {
+		return nil, "", fmt.Errorf("template render task: rendering template: %w", err)
+	}
+	rendered := buf.String()
+	if payload.Option == OptionMissingKeyZero {
+		rendered = strings.ReplaceAll(rendered, noValue, "")
+	}
+
+	if payload.OutputPath == "" {
+		return rendered, flow.ResultTypeString, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(payload.OutputPath), 0o755); err != nil {
+		return nil, "", fmt.Errorf("template render task: creating directory for %s: %w", payload.OutputPath, err)
+	}
+	if err := os.WriteFile(payload.OutputPath, []byte(rendered), 0o644); err != nil {
+		return nil, "", fmt.Errorf("template render task: writing %s: %w", payload.OutputPath, err)
+	}
+	return Result{Path: payload.OutputPath, Bytes: len(rendered)}, flow.ResultTypeJSON, nil
+}
+
+// templateData exposes every variable by name, so {{ .db_host }} renders the
+// value of db_host, and the results of completed tasks keyed by task ID under
+// TasksKey, so {{ .tasks.fetch.status_code }} walks the result of fetch.
+func templateData(vars map[string]registry.Variable, tasks []flow.Task) map[string]any {
+	data := make(map[string]any, len(vars)+1)
+	for name, variable := range vars {
+		data[name] = variable.Value
+	}
+
+	results := make(map[string]any)
+	for _, task := range tasks {
+		if task.Status == flow.TaskStatusCompleted {
+			results[task.ID] = jsonpathutil.NormalizeContainer(task.Result)
+		}
+	}
+	data[TasksKey] = results
+	return data
+}
@@ -0,0 +1,146 @@
+package templaterender
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+func TestPayloadValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		payload    Payload
+		wantErr    string
+		wantOption string
+	}{
+		{name: "no template", payload: Payload{}, wantErr: "exactly one of template or template_path"},
+		{name: "both templates", payload: Payload{Template: "x", TemplatePath: "x.tmpl"}, wantErr: "exactly one of template or template_path"},
+		{name: "unknown option", payload: Payload{Template: "x", Option: "missingkey=default"}, wantErr: "unsupported option"},
+		{name: "defaults to error", payload: Payload{Template: "x"}, wantOption: OptionMissingKeyError},
+		{name: "zero", payload: Payload{TemplatePath: " x.tmpl ", Option: " MissingKey=Zero"}, wantOption: OptionMissingKeyZero},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.payload.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if tt.wantOption != "" && tt.payload.Option != tt.wantOption {
+				t.Fatalf("option = %q, want %q", tt.payload.Option, tt.wantOption)
+			}
+		})
+	}
+}
+
+func render(t *testing.T, payload Payload, vars map[string]registry.Variable, tasks []flow.Task) (string, error) {
+	t.Helper()
+	if err := payload.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	value, _, err := Execute(payload, vars, tasks)
+	if err != nil {
+		return "", err
+	}
+	return value.(string), nil
+}
+
+func TestExecuteRendersVariablesAndTasks(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]registry.Variable{
+		"env":   {Name: "env", Type: "string", Value: "prod"},
+		"hosts": {Name: "hosts", Type: "array", Value: []any{"a", "b"}},
+	}
+	tasks := []flow.Task{
+		{ID: "fetch", Status: flow.TaskStatusCompleted, Result: json.RawMessage(`{"status_code":200,"items":[{"name":"x"}]}`)},
+		{ID: "pending", Status: flow.TaskStatusNotStarted, Result: "stale"},
+	}
+
+	got, err := render(t, Payload{
+		Template: `{{ .env | upper }} {{ join "," .hosts }} {{ .tasks.fetch.status_code }} {{ (index .tasks.fetch.items 0).name }} {{ hasKey .tasks "pending" }}`,
+	}, vars, tasks)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := "PROD a,b 200 x false"; got != want {
+		t.Fatalf("rendered %q, want %q", got, want)
+	}
+}
+
+func TestExecuteMissingKeyOption(t *testing.T) {
+	t.Parallel()
+
+	if _, err := render(t, Payload{Template: "port={{ .port }}"}, nil, nil); err == nil || !strings.Contains(err.Error(), `map has no entry for key "port"`) {
+		t.Fatalf("expected a missing key error, got %v", err)
+	}
+
+	got, err := render(t, Payload{Template: `port={{ .port }} host={{ .host | default "localhost" }}`, Option: OptionMissingKeyZero}, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := "port= host=localhost"; got != want {
+		t.Fatalf("rendered %q, want %q", got, want)
+	}
+}
+
+func TestExecuteTemplateFileToOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "app.conf.tmpl")
+	if err := os.WriteFile(templatePath, []byte("host={{ .db_host }}\n"), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	outputPath := filepath.Join(dir, "out", "app.conf")
+
+	payload := Payload{TemplatePath: templatePath, OutputPath: outputPath}
+	if err := payload.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	vars := map[string]registry.Variable{"db_host": {Name: "db_host", Type: "string", Value: "db.local"}}
+	value, resultType, err := Execute(payload, vars, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if resultType != flow.ResultTypeJSON || value != (Result{Path: outputPath, Bytes: 14}) {
+		t.Fatalf("result = %+v (%s)", value, resultType)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil || string(data) != "host=db.local\n" {
+		t.Fatalf("output = %q (err %v)", data, err)
+	}
+}
+
+func TestActionRendersTemplate(t *testing.T) {
+	t.Parallel()
+
+	execCtx := &registry.ExecutionContext{
+		Variables: map[string]registry.Variable{"name": {Name: "name", Type: "string", Value: "flowk"}},
+	}
+	payload := json.RawMessage(`{"template":"hello {{ .name }}"}`)
+	result, err := action{}.Execute(context.Background(), payload, execCtx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Value != "hello flowk" || result.Type != flow.ResultTypeString {
+		t.Fatalf("result = %+v", result)
+	}
+
+	if _, err := (action{}).Execute(context.Background(), json.RawMessage(`{"template":"{{ .name "}`), execCtx); err == nil || !strings.Contains(err.Error(), "parsing template") {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+}
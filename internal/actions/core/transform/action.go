@@ -0,0 +1,35 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"flowk/internal/actions/registry"
+)
+
+type action struct{}
+
+func init() {
+	registry.Register(action{})
+}
+
+func (action) Name() string {
+	return ActionName
+}
+
+func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	var cfg Payload
+	if err := json.Unmarshal(payload, &cfg); err != nil {
+		return registry.Result{}, fmt.Errorf("decoding transform task payload: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return registry.Result{}, err
+	}
+
+	value, resultType, err := Execute(ctx, cfg, execCtx.Variables)
+	if err != nil {
+		return registry.Result{}, err
+	}
+	return registry.Result{Value: value, Type: resultType}, nil
+}
@@ -0,0 +1,198 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/itchyny/gojq"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+const (
+	// ActionName identifies the Transform action in the flow definition.
+	ActionName = "TRANSFORM"
+)
+
+// Payload describes the configuration accepted by the TRANSFORM action.
+type Payload struct {
+	// Input is the JSON value to transform. It is usually a
+	// ${from.task:...} reference, which the engine expands to the typed
+	// result of the referenced task before the action runs.
+	Input json.RawMessage `json:"input"`
+	// Program is a jq program evaluated against Input with gojq.
+	Program string `json:"program,omitempty"`
+	// Template is a Go text/template rendered with Input as its data. The
+	// rendered text must be valid JSON.
+	Template string `json:"template,omitempty"`
+	// ParseJSON decodes a string Input as JSON before transforming it.
+	ParseJSON bool `json:"parse_json,omitempty"`
+}
+
+// Validate ensures the payload is well formed.
+func (p Payload) Validate() error {
+	if len(bytes.TrimSpace(p.Input)) == 0 {
+		return fmt.Errorf("transform task: input is required")
+	}
+	hasProgram := strings.TrimSpace(p.Program) != ""
+	hasTemplate := strings.TrimSpace(p.Template) != ""
+	if hasProgram == hasTemplate {
+		return fmt.Errorf("transform task: exactly one of program or template is required")
+	}
+	return nil
+}
+
+// Execute evaluates the configured program or template against the input
+// and returns the produced JSON value. A program that yields exactly one
+// output returns it as is; any other number of outputs is wrapped in an
+// array, so zero outputs produce [].
+func Execute(ctx context.Context, payload Payload, vars map[string]registry.Variable) (any, flow.ResultType, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	input, err := decodeInput(payload)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var value any
+	if strings.TrimSpace(payload.Program) != "" {
+		value, err = runProgram(ctx, payload.Program, input, vars)
+	} else {
+		value, err = runTemplate(payload.Template, input, vars)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return value, flow.ResultTypeJSON, nil
+}
+
+func decodeInput(payload Payload) (any, error) {
+	var input any
+	if err := json.Unmarshal(payload.Input, &input); err != nil {
+		return nil, fmt.Errorf("transform: decode input: %w", err)
+	}
+	if !payload.ParseJSON {
+		return input, nil
+	}
+	text, ok := input.(string)
+	if !ok {
+		return nil, fmt.Errorf("transform: parse_json requires a string input, got %s", gojq.TypeOf(input))
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		return nil, fmt.Errorf("transform: input is not valid JSON: %w", err)
+	}
+	return parsed, nil
+}
+
+// runProgram compiles program with gojq and runs it against input. Flow
+// variables are bound as $name and the process environment is available
+// through env and $ENV.
+func runProgram(ctx context.Context, program string, input any, vars map[string]registry.Variable) (any, error) {
+	query, err := gojq.Parse(program)
+	if err != nil {
+		return nil, fmt.Errorf("transform: compile program: %w", err)
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	variables := make([]string, len(names))
+	values := make([]any, len(names))
+	for i, name := range names {
+		value, err := normalize(vars[name].Value)
+		if err != nil {
+			return nil, fmt.Errorf("transform: variable %q: %w", name, err)
+		}
+		variables[i] = "$" + name
+		values[i] = value
+	}
+
+	code, err := gojq.Compile(query, gojq.WithVariables(variables), gojq.WithEnvironLoader(os.Environ))
+	if err != nil {
+		return nil, fmt.Errorf("transform: compile program: %w", err)
+	}
+
+	outputs := []any{}
+	iter := code.RunWithContext(ctx, input, values...)
+	for {
+		output, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := output.(error); ok {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				break
+			}
+			return nil, fmt.Errorf("transform: run program: %w", err)
+		}
+		value, err := normalize(output)
+		if err != nil {
+			return nil, fmt.Errorf("transform: encode program output: %w", err)
+		}
+		outputs = append(outputs, value)
+	}
+	if len(outputs) == 1 {
+		return outputs[0], nil
+	}
+	return outputs, nil
+}
+
+func runTemplate(text string, input any, vars map[string]registry.Variable) (any, error) {
+	funcs := template.FuncMap{
+		"toJson": func(value any) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+		"var": func(name string) (any, error) {
+			variable, ok := vars[name]
+			if !ok {
+				return nil, fmt.Errorf("variable %q not defined", name)
+			}
+			return variable.Value, nil
+		},
+	}
+	tmpl, err := template.New("transform").Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("transform: parse template: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, input); err != nil {
+		return nil, fmt.Errorf("transform: render template: %w", err)
+	}
+
+	var value any
+	if err := json.Unmarshal(rendered.Bytes(), &value); err != nil {
+		return nil, fmt.Errorf("transform: template output is not valid JSON: %w", err)
+	}
+	return value, nil
+}
+
+// normalize converts a Go value into its generic JSON representation, the
+// form gojq accepts as input and the form task results are stored in.
+func normalize(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
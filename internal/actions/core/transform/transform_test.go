@@ -0,0 +1,268 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+const ordersJSON = `{
+  "orders": [
+    {"id": "a1", "customer": "ana", "total": 30, "tags": ["new"]},
+    {"id": "b2", "customer": "bob", "total": 5, "tags": []},
+    {"id": "c3", "customer": "ana", "total": 12.5, "tags": ["vip", "new"]}
+  ],
+  "meta": {"page": 1, "next": null}
+}`
+
+func decode(t *testing.T, text string) any {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", text, err)
+	}
+	return value
+}
+
+func TestProgramEvaluation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		program string
+		want    string
+	}{
+		{program: ".", want: ordersJSON},
+		{program: ".meta.page", want: `1`},
+		{program: ".meta.missing.deeper", want: `null`},
+		{program: `.["meta"]."page"`, want: `1`},
+		{program: ".orders[0].id", want: `"a1"`},
+		{program: ".orders[-1].id", want: `"c3"`},
+		{program: ".orders[1:].[0].id", want: `"b2"`},
+		{program: "[.orders[1:][] | .id]", want: `["b2", "c3"]`},
+		{program: "[.orders[].id]", want: `["a1", "b2", "c3"]`},
+		{program: ".orders | map(.total) | add", want: `47.5`},
+		{program: ".orders | map(select(.total > 10) | .id)", want: `["a1", "c3"]`},
+		{program: "[.orders[] | select(.tags | contains([\"new\"])) | .id]", want: `["a1", "c3"]`},
+		{program: ".orders | group_by(.customer) | map({customer: .[0].customer, total: (map(.total) | add)})", want: `[{"customer": "ana", "total": 42.5}, {"customer": "bob", "total": 5}]`},
+		{program: ".orders | sort_by(.total) | map(.id)", want: `["b2", "c3", "a1"]`},
+		{program: ".orders | max_by(.total) | .id", want: `"a1"`},
+		{program: ".orders | map(.customer) | unique", want: `["ana", "bob"]`},
+		{program: ".orders | length", want: `3`},
+		{program: ".meta | keys", want: `["next", "page"]`},
+		{program: ".meta | to_entries | map(.key)", want: `["next", "page"]`},
+		{program: ".meta | with_entries(select(.value != null))", want: `{"page": 1}`},
+		{program: ".meta | has(\"next\"), has(\"prev\")", want: `[true, false]`},
+		{program: ".meta.next // \"none\"", want: `"none"`},
+		{program: ".orders[0] | {id, label: \"\\(.customer) spent \\(.total)\"}", want: `{"id": "a1", "label": "ana spent 30"}`},
+		{program: "{(.orders[0].id): .orders[0].total}", want: `{"a1": 30}`},
+		{program: ".orders[] | if .total > 20 then \"big\" elif .total > 10 then \"medium\" else \"small\" end", want: `["big", "small", "medium"]`},
+		{program: ".orders[0].total as $t | .orders | map(select(.total < $t)) | length", want: `2`},
+		{program: ".orders | map(.tags) | flatten | join(\",\")", want: `"new,vip,new"`},
+		{program: ".meta.page + 1, .meta.page * 10 - 2, 7 % 3, -.meta.page", want: `[2, 8, 1, -1]`},
+		{program: "[range(3)], [limit(2; .orders[].id)]", want: `[[0, 1, 2], ["a1", "b2"]]`},
+		{program: ".orders[0].id | ascii_upcase | test(\"^A\\\\d\")", want: `true`},
+		{program: "\"a,b\" | split(\",\") | reverse", want: `["b", "a"]`},
+		{program: ".meta * {extra: {x: 1}} | tojson | fromjson | .extra.x", want: `1`},
+		{program: ".meta.page | tostring | tonumber", want: `1`},
+		{program: "[.[] | type]", want: `["object", "array"]`},
+		{program: "[.orders[] | .total >= 12.5 and (.tags | length) > 0]", want: `[true, false, true]`},
+		{program: ".orders[].missing", want: `[null, null, null]`},
+		{program: "empty", want: `[]`},
+		{program: ".orders[].id # comment\n| select(. == \"zz\")", want: `[]`},
+		{program: "(.meta.page | tostring) as $p | \"page-\" + $p", want: `"page-1"`},
+		{program: ".orders[0].tags[0]? // \"\", (.meta.page[0])?", want: `"new"`},
+		{program: "reduce .orders[] as $o ({}; .[$o.customer] += $o.total)", want: `{"ana": 42.5, "bob": 5}`},
+		{program: "try error(\"boom\") catch .", want: `"boom"`},
+		{program: ".meta.page |= . + 1 | .meta.page", want: `2`},
+		{program: "{a: 1, b: 2} | with_entries(.value += 1)", want: `{"a": 2, "b": 3}`},
+		{program: "del(.orders) | keys", want: `["meta"]`},
+		{program: "[.meta | paths]", want: `[["next"], ["page"]]`},
+		{program: "getpath([\"orders\", 0, \"id\"])", want: `"a1"`},
+		{program: ".orders[0].id | @base64", want: `"YTE="`},
+		{program: "[.orders[0] | .id, .customer, .total] | @csv", want: `"\"a1\",\"ana\",30"`},
+		{program: ".orders[0] as {id: $id, tags: [$tag]} | [$id, $tag]", want: `["a1", "new"]`},
+		{program: "def total: map(.total) | add; .orders | total", want: `47.5`},
+		{program: "first(.orders[] | select(.customer == \"ana\")) | .id", want: `"a1"`},
+		{program: "[\"a1,b2\" | splits(\",\")]", want: `["a1", "b2"]`},
+		{program: "[.meta | recurse | numbers]", want: `[1]`},
+		{program: "label $out | .orders[] | if .total < 10 then break $out else .id end", want: `"a1"`},
+		{program: "now | type", want: `"number"`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.program, func(t *testing.T) {
+			t.Parallel()
+			got, err := runProgram(context.Background(), tt.program, decode(t, ordersJSON), nil)
+			if err != nil {
+				t.Fatalf("runProgram() error = %v", err)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.Marshal(got)
+				t.Fatalf("runProgram() = %s, want %s", gotJSON, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgramErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		program string
+		wantErr string
+	}{
+		{program: ".orders |", wantErr: "compile program: unexpected EOF"},
+		{program: "nope(.)", wantErr: "function not defined: nope/1"},
+		{program: "if . then 1", wantErr: "compile program"},
+		{program: "\"unterminated", wantErr: "unterminated string"},
+		{program: ".orders[0].id + 1", wantErr: "cannot add: string"},
+		{program: ".meta.page[]", wantErr: "cannot iterate over: number"},
+		{program: ".orders.id", wantErr: "expected an object but got: array"},
+		{program: "$missing", wantErr: "variable not defined: $missing"},
+		{program: ".orders | map(.total / 0)", wantErr: "divide"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.program, func(t *testing.T) {
+			t.Parallel()
+			_, err := runProgram(context.Background(), tt.program, decode(t, ordersJSON), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPayloadValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		payload Payload
+		wantErr string
+	}{
+		{name: "missing input", payload: Payload{Program: "."}, wantErr: "input is required"},
+		{name: "no program or template", payload: Payload{Input: json.RawMessage(`{}`)}, wantErr: "exactly one of program or template"},
+		{name: "program and template", payload: Payload{Input: json.RawMessage(`{}`), Program: ".", Template: "{}"}, wantErr: "exactly one of program or template"},
+		{name: "null input", payload: Payload{Input: json.RawMessage(`null`), Program: "."}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.payload.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestActionExecute(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]registry.Variable{
+		"threshold": {Name: "threshold", Type: "number", Value: 10},
+		"labels":    {Name: "labels", Type: "json", Value: map[string]string{"ana": "Ana"}},
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "program with variables",
+			payload: `{"input": ` + ordersJSON + `, "program": ".orders | map(select(.total > $threshold) | $labels[.customer])"}`,
+			want:    `["Ana", "Ana"]`,
+		},
+		{
+			name:    "multiple outputs are wrapped",
+			payload: `{"input": [1, 2, 3], "program": ".[] | . * 2"}`,
+			want:    `[2, 4, 6]`,
+		},
+		{
+			name:    "parse_json decodes string input",
+			payload: `{"input": "{\"items\": [{\"name\": \"x\"}]}", "parse_json": true, "program": ".items[0].name"}`,
+			want:    `"x"`,
+		},
+		{
+			name:    "parse_json rejects objects",
+			payload: `{"input": {}, "parse_json": true, "program": "."}`,
+			wantErr: "parse_json requires a string input",
+		},
+		{
+			name:    "template",
+			payload: `{"input": ` + ordersJSON + `, "template": "{\"first\": {{ toJson (index .orders 0).id }}, \"count\": {{ len .orders }}, \"threshold\": {{ var \"threshold\" }}}"}`,
+			want:    `{"first": "a1", "count": 3, "threshold": 10}`,
+		},
+		{
+			name:    "template must produce JSON",
+			payload: `{"input": {"name": "x"}, "template": "hello {{ .name }}"}`,
+			wantErr: "template output is not valid JSON",
+		},
+		{
+			name:    "template missing key",
+			payload: `{"input": {"name": "x"}, "template": "{{ toJson .other }}"}`,
+			wantErr: "render template",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res, err := action{}.Execute(context.Background(), json.RawMessage(tt.payload), &registry.ExecutionContext{Variables: vars})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if res.Type != flow.ResultTypeJSON {
+				t.Fatalf("result type = %s, want %s", res.Type, flow.ResultTypeJSON)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(res.Value, want) {
+				gotJSON, _ := json.Marshal(res.Value)
+				t.Fatalf("Execute() = %s, want %s", gotJSON, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgramEnvironment(t *testing.T) {
+	t.Setenv("FLOWK_TRANSFORM_TEST", "from-env")
+
+	got, err := runProgram(context.Background(), "[env.FLOWK_TRANSFORM_TEST, $ENV.FLOWK_TRANSFORM_TEST]", nil, nil)
+	if err != nil {
+		t.Fatalf("runProgram() error = %v", err)
+	}
+	if want := []any{"from-env", "from-env"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("runProgram() = %v, want %v", got, want)
+	}
+}
+
+func TestProgramHonoursContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := runProgram(ctx, "repeat(1)", nil, nil); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Fatalf("expected context cancellation error, got %v", err)
+	}
+}
@@ -2,7 +2,9 @@ package variables
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 
@@ -25,10 +27,17 @@ var (
 		"bool":   {},
 		"array":  {},
 		"object": {},
+		"list":   {},
+		"json":   {},
 		"secret": {},
 		"proxy":  {},
 	}
 
+	// typeAliases maps alternative type names onto the stored type.
+	typeAliases = map[string]string{
+		"list": "array",
+	}
+
 	placeholderPattern         = regexp.MustCompile(`\{\{\s*from\.task:([^{}]+)\s*\}\}|\$\{\s*from\.task:([^{}]+)\s*\}`)
 	variablePlaceholderPattern = regexp.MustCompile(`\$\{\s*([A-Za-z0-9_.-]+)\s*\}|\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
 )
@@ -37,7 +46,17 @@ var (
 // When the full string is a single placeholder, the resolved value retains its original type. If the
 // placeholder is embedded within a larger string, the rendered output is returned as a string.
 func ResolveTaskPlaceholders(value string, tasks []flow.Task) (any, error) {
-	return resolveTaskPlaceholders(value, tasks)
+	return resolveTaskPlaceholders(value, tasks, nil)
+}
+
+// ResolveTaskPlaceholdersWithWarnings behaves like ResolveTaskPlaceholders but
+// resolves dotted references to missing result paths to an empty string,
+// reporting each one through warnf instead of failing.
+func ResolveTaskPlaceholdersWithWarnings(value string, tasks []flow.Task, warnf func(format string, args ...any)) (any, error) {
+	if warnf == nil {
+		warnf = func(string, ...any) {}
+	}
+	return resolveTaskPlaceholders(value, tasks, warnf)
 }
 
 // Payload describes the expected configuration for a VARIABLES task.
@@ -53,6 +72,13 @@ type VariableConfig struct {
 	Type      string         `json:"type"`
 	Value     any            `json:"value"`
 	Operation *MathOperation `json:"operation"`
+	// Secret masks the value in logs and snapshots regardless of its type.
+	Secret bool `json:"secret"`
+	// FromEnv and FromFile load the value from the OS environment or a file
+	// when the task runs. Default applies when the FromEnv variable is unset.
+	FromEnv  string `json:"from_env"`
+	FromFile string `json:"from_file"`
+	Default  any    `json:"default"`
 }
 
 // MathOperation defines a math transformation for number variables.
@@ -107,6 +133,18 @@ func (v VariableConfig) Validate() error {
 	if _, ok := supportedTypes[normalizedType]; !ok {
 		return fmt.Errorf("unsupported type %q", v.Type)
 	}
+	sources := 0
+	for _, set := range []bool{v.Value != nil, v.Operation != nil, strings.TrimSpace(v.FromEnv) != "", strings.TrimSpace(v.FromFile) != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("value, operation, from_env and from_file are mutually exclusive")
+	}
+	if v.Default != nil && strings.TrimSpace(v.FromEnv) == "" {
+		return fmt.Errorf("default requires from_env")
+	}
 	if v.Operation != nil {
 		if normalizedType != "number" {
 			return fmt.Errorf("operation requires number type")
@@ -151,6 +189,9 @@ func Execute(payload Payload, existing map[string]Variable, tasks []flow.Task) (
 	for _, cfg := range payload.Vars {
 		name := strings.TrimSpace(cfg.Name)
 		varType := strings.ToLower(strings.TrimSpace(cfg.Type))
+		if alias, ok := typeAliases[varType]; ok {
+			varType = alias
+		}
 
 		if _, exists := existing[name]; exists && !payload.Overwrite {
 			return nil, "", fmt.Errorf("variables task: variable %q already defined", name)
@@ -168,7 +209,7 @@ func Execute(payload Payload, existing map[string]Variable, tasks []flow.Task) (
 			Name:   name,
 			Type:   varType,
 			Value:  coerced,
-			Secret: varType == "secret",
+			Secret: varType == "secret" || cfg.Secret,
 		}
 
 		updates[name] = variable
@@ -188,10 +229,10 @@ func resolveValue(value any, tasks []flow.Task) (any, error) {
 		return value, nil
 	}
 
-	return resolveTaskPlaceholders(str, tasks)
+	return resolveTaskPlaceholders(str, tasks, nil)
 }
 
-func resolveTaskPlaceholders(str string, tasks []flow.Task) (any, error) {
+func resolveTaskPlaceholders(str string, tasks []flow.Task, warnf func(format string, args ...any)) (any, error) {
 	matches := placeholderPattern.FindAllStringSubmatch(str, -1)
 	if len(matches) == 0 {
 		return str, nil
@@ -207,7 +248,12 @@ func resolveTaskPlaceholders(str string, tasks []flow.Task) (any, error) {
 
 		replacement, err := resolveFromTaskPlaceholder(expr, tasks)
 		if err != nil {
-			return nil, err
+			var missing *flow.MissingPathError
+			if warnf == nil || !errors.As(err, &missing) {
+				return nil, err
+			}
+			warnf("WARNING: from.task:%s resolved to empty: %v", strings.TrimSpace(expr), err)
+			replacement = ""
 		}
 
 		if full == str && len(matches) == 1 {
@@ -238,7 +284,10 @@ func resolveFromTaskPlaceholder(expr string, tasks []flow.Task) (any, error) {
 	}
 
 	dollarIdx := strings.Index(trimmed, "$")
-	if dollarIdx <= 0 {
+	if dollarIdx < 0 {
+		return flow.ResolveTaskReference(tasks, trimmed)
+	}
+	if dollarIdx == 0 {
 		return nil, fmt.Errorf("placeholder %q missing json path", expr)
 	}
 
@@ -323,6 +372,8 @@ func coerceValue(varType string, value any) (any, error) {
 			return obj, nil
 		}
 		return nil, fmt.Errorf("expected object value, got %T", value)
+	case "json":
+		return toJSON(value)
 	case "proxy":
 		proxies, err := toProxy(value)
 		if err != nil {
@@ -343,6 +394,18 @@ func resolveVariableValue(cfg VariableConfig, name, varType string, tasks []flow
 		return value, nil
 	}
 
+	if strings.TrimSpace(cfg.FromEnv) != "" || strings.TrimSpace(cfg.FromFile) != "" {
+		value, err := readExternalValue(cfg, existing, updates)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", name, err)
+		}
+		coerced, err := coerceValue(varType, value)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", name, err)
+		}
+		return coerced, nil
+	}
+
 	value, err := resolveValue(cfg.Value, tasks)
 	if err != nil {
 		return nil, fmt.Errorf("variable %q: %w", name, err)
@@ -354,7 +417,7 @@ func resolveVariableValue(cfg VariableConfig, name, varType string, tasks []flow
 	}
 
 	if str, ok := value.(string); ok && placeholderPattern.MatchString(str) {
-		value, err = resolveTaskPlaceholders(str, tasks)
+		value, err = resolveTaskPlaceholders(str, tasks, nil)
 		if err != nil {
 			return nil, fmt.Errorf("variable %q: %w", name, err)
 		}
@@ -367,6 +430,35 @@ func resolveVariableValue(cfg VariableConfig, name, varType string, tasks []flow
 	return coerced, nil
 }
 
+// readExternalValue loads a from_env or from_file value. The contents are
+// used verbatim, without placeholder expansion, so secrets containing "${"
+// are preserved.
+func readExternalValue(cfg VariableConfig, existing, updates map[string]Variable) (any, error) {
+	if envName := strings.TrimSpace(cfg.FromEnv); envName != "" {
+		if value, ok := os.LookupEnv(envName); ok {
+			return value, nil
+		}
+		if cfg.Default != nil {
+			return cfg.Default, nil
+		}
+		return nil, fmt.Errorf("environment variable %q is not set", envName)
+	}
+
+	path, err := resolveVariablePlaceholders(strings.TrimSpace(cfg.FromFile), existing, updates)
+	if err != nil {
+		return nil, fmt.Errorf("from_file: %w", err)
+	}
+	pathStr, err := toString(path)
+	if err != nil {
+		return nil, fmt.Errorf("from_file: %w", err)
+	}
+	data, err := os.ReadFile(pathStr)
+	if err != nil {
+		return nil, fmt.Errorf("reading from_file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
 func resolveVariablePlaceholders(value any, existing, updates map[string]Variable) (any, error) {
 	str, ok := value.(string)
 	if !ok {
@@ -573,7 +665,8 @@ func toArray(value any) ([]any, bool) {
 		}
 		return out, true
 	default:
-		return nil, false
+		arr, ok := decodeJSONValue(value).([]any)
+		return arr, ok
 	}
 }
 
@@ -582,8 +675,60 @@ func toObject(value any) (map[string]any, bool) {
 	case map[string]any:
 		return v, true
 	default:
-		return nil, false
+		obj, ok := decodeJSONValue(value).(map[string]any)
+		return obj, ok
+	}
+}
+
+// toJSON stores any JSON value. Strings must hold a JSON document, which is
+// decoded so later path references can navigate it.
+func toJSON(value any) (any, error) {
+	if str, ok := value.(string); ok {
+		var decoded any
+		if err := json.Unmarshal([]byte(str), &decoded); err != nil {
+			return nil, fmt.Errorf("expected JSON value: %w", err)
+		}
+		return decoded, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected JSON value: %w", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("expected JSON value: %w", err)
+	}
+	return decoded, nil
+}
+
+// decodeJSONValue converts strings holding JSON, and typed slices or maps
+// produced by other actions, into generic JSON values. Anything else is
+// returned unchanged.
+func decodeJSONValue(value any) any {
+	var data []byte
+	switch v := value.(type) {
+	case nil, bool, float64, json.Number:
+		return value
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "{") {
+			return value
+		}
+		data = []byte(trimmed)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return value
+		}
+		data = encoded
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
 	}
+	return decoded
 }
 
 func toProxy(value any) (map[string]string, error) {
@@ -632,7 +777,7 @@ func toProxy(value any) (map[string]string, error) {
 
 func formatResultValue(variable Variable) any {
 	if variable.Secret {
-		return "****"
+		return "***"
 	}
 	return variable.Value
 }
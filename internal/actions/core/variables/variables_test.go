@@ -3,6 +3,9 @@ package variables
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -218,6 +221,52 @@ func TestExecuteCoercesTypes(t *testing.T) {
 	}
 }
 
+func TestExecuteCoercesListAndJSONTypes(t *testing.T) {
+	payload := Payload{
+		Scope: scopeFlow,
+		Vars: []VariableConfig{
+			{Name: "zones", Type: "list", Value: `["a", "b"]`},
+			{Name: "limits", Type: "object", Value: `{"cpu": "250m"}`},
+			{Name: "spec", Type: "json", Value: `{"replicas": 3, "ports": [80, 443]}`},
+			{Name: "raw", Type: "json", Value: []string{"x"}},
+		},
+	}
+
+	existing := make(map[string]Variable)
+	if _, _, err := Execute(payload, existing, nil); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if got := existing["zones"]; got.Type != "array" || !reflect.DeepEqual(got.Value, []any{"a", "b"}) {
+		t.Fatalf("expected zones to be an array, got %#v", got)
+	}
+	if got := existing["limits"].Value; !reflect.DeepEqual(got, map[string]any{"cpu": "250m"}) {
+		t.Fatalf("expected limits to be decoded, got %#v", got)
+	}
+	spec, ok := existing["spec"].Value.(map[string]any)
+	if !ok || spec["replicas"] != float64(3) {
+		t.Fatalf("expected spec to be decoded JSON, got %#v", existing["spec"].Value)
+	}
+	if got := existing["raw"].Value; !reflect.DeepEqual(got, []any{"x"}) {
+		t.Fatalf("expected raw to be normalized, got %#v", got)
+	}
+}
+
+func TestExecuteRejectsValuesThatCannotBeCoerced(t *testing.T) {
+	tests := []VariableConfig{
+		{Name: "count", Type: "number", Value: "three"},
+		{Name: "zones", Type: "list", Value: `{"a": 1}`},
+		{Name: "spec", Type: "json", Value: "not json"},
+	}
+
+	for _, cfg := range tests {
+		payload := Payload{Scope: scopeFlow, Vars: []VariableConfig{cfg}}
+		if _, _, err := Execute(payload, map[string]Variable{}, nil); err == nil || !strings.Contains(err.Error(), cfg.Name) {
+			t.Fatalf("expected coercion error for %s, got %v", cfg.Name, err)
+		}
+	}
+}
+
 func TestExecuteResolvesTaskPlaceholders(t *testing.T) {
 	payload := Payload{
 		Scope: scopeFlow,
@@ -426,7 +475,7 @@ func TestResolveTaskPlaceholders(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := resolveTaskPlaceholders(tt.input, tasks)
+			got, err := resolveTaskPlaceholders(tt.input, tasks, nil)
 			if err != nil {
 				t.Fatalf("resolveTaskPlaceholders returned error: %v", err)
 			}
@@ -550,7 +599,7 @@ func TestExecuteMasksSecretValues(t *testing.T) {
 	if v := existing["token"].Value; v != "s3cr3t" {
 		t.Fatalf("expected stored secret value, got %v", v)
 	}
-	if res := result["token"]; res != "****" {
+	if res := result["token"]; res != "***" {
 		t.Fatalf("expected masked secret in result, got %v", res)
 	}
 }
@@ -735,6 +784,87 @@ func TestResolveFromTaskPlaceholderSupportsResultSuffix(t *testing.T) {
 	}
 }
 
+func TestResolveTaskPlaceholdersDottedPaths(t *testing.T) {
+	tasks := []flow.Task{
+		{
+			ID:         "fanout",
+			Status:     flow.TaskStatusCompleted,
+			Success:    true,
+			ResultType: flow.ResultTypeJSON,
+			Result: map[string]map[string]any{
+				"listpods": {
+					"pods": []any{
+						map[string]any{"name": "api-0", "ready": true},
+						map[string]any{"name": "api-1", "ready": false},
+					},
+				},
+				"deploy": {"readyReplicas": float64(2)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  any
+	}{
+		{name: "indexed element", input: "${from.task:fanout.result.listpods.pods.1.name}", want: "api-1"},
+		{name: "bracket index", input: "${from.task:fanout.listpods.pods[0].ready}", want: true},
+		{name: "typed number", input: "${from.task:fanout.result.deploy.readyReplicas}", want: float64(2)},
+		{name: "metadata field", input: "${from.task:fanout.success}", want: true},
+		{name: "embedded", input: "pod=${from.task:fanout.result.listpods.pods.0.name}", want: "pod=api-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []string
+			warnf := func(format string, args ...any) { warnings = append(warnings, fmt.Sprintf(format, args...)) }
+
+			got, err := ResolveTaskPlaceholdersWithWarnings(tt.input, tasks, warnf)
+			if err != nil {
+				t.Fatalf("ResolveTaskPlaceholdersWithWarnings() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ResolveTaskPlaceholdersWithWarnings() = %#v, want %#v", got, tt.want)
+			}
+			if len(warnings) != 0 {
+				t.Fatalf("unexpected warnings: %v", warnings)
+			}
+		})
+	}
+}
+
+func TestResolveTaskPlaceholdersMissingPathWarns(t *testing.T) {
+	tasks := []flow.Task{
+		{
+			ID:         "fanout",
+			Status:     flow.TaskStatusCompleted,
+			ResultType: flow.ResultTypeJSON,
+			Result: map[string]map[string]any{
+				"listpods": {"pods": []any{map[string]any{"name": "api-0"}}},
+			},
+		},
+	}
+
+	var warnings []string
+	warnf := func(format string, args ...any) { warnings = append(warnings, fmt.Sprintf(format, args...)) }
+
+	got, err := ResolveTaskPlaceholdersWithWarnings("pod=[${from.task:fanout.result.listpods.pods.5.name}]", tasks, warnf)
+	if err != nil {
+		t.Fatalf("ResolveTaskPlaceholdersWithWarnings() error = %v", err)
+	}
+	if got != "pod=[]" {
+		t.Fatalf("expected empty replacement, got %#v", got)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "index 5 out of range (length 1)") {
+		t.Fatalf("expected out of range warning, got %v", warnings)
+	}
+
+	if _, err := ResolveTaskPlaceholders("${from.task:fanout.result.unknown}", tasks); err == nil {
+		t.Fatal("expected ResolveTaskPlaceholders to fail without a warning sink")
+	}
+}
+
 func TestResolveFromTaskPlaceholderErrors(t *testing.T) {
 	baseTask := flow.Task{
 		ID:         "task.one",
@@ -750,7 +880,8 @@ func TestResolveFromTaskPlaceholderErrors(t *testing.T) {
 		wantErr string
 	}{
 		{name: "empty", expr: "  ", mutate: func(task *flow.Task) []flow.Task { return []flow.Task{*task} }, wantErr: "empty"},
-		{name: "missing path", expr: "task.one", mutate: func(task *flow.Task) []flow.Task { return []flow.Task{*task} }, wantErr: "missing json path"},
+		{name: "missing path", expr: "$.data", mutate: func(task *flow.Task) []flow.Task { return []flow.Task{*task} }, wantErr: "missing json path"},
+		{name: "missing dotted path", expr: "task.one.unknown", mutate: func(task *flow.Task) []flow.Task { return []flow.Task{*task} }, wantErr: `path "unknown" not found`},
 		{name: "missing task", expr: "missing$.data", mutate: func(task *flow.Task) []flow.Task { return []flow.Task{*task} }, wantErr: "not found"},
 		{name: "not completed", expr: "task.one$.data", mutate: func(task *flow.Task) []flow.Task {
 			clone := *task
@@ -903,3 +1034,54 @@ func TestToNumberRejectsEmptyString(t *testing.T) {
 		t.Fatalf("expected error parsing empty string")
 	}
 }
+
+func TestExecuteLoadsValuesFromEnvAndFile(t *testing.T) {
+	t.Setenv("FLOWK_TEST_TOKEN", "env-token")
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("  file-token\n"), 0o600); err != nil {
+		t.Fatalf("writing token file: %v", err)
+	}
+
+	payload := Payload{
+		Scope: scopeFlow,
+		Vars: []VariableConfig{
+			{Name: "env_token", Type: "string", FromEnv: "FLOWK_TEST_TOKEN", Secret: true},
+			{Name: "region", Type: "string", FromEnv: "FLOWK_TEST_UNSET_REGION", Default: "eu-west-1"},
+			{Name: "file_token", Type: "secret", FromFile: tokenFile},
+		},
+	}
+
+	existing := make(map[string]Variable)
+	if _, _, err := Execute(payload, existing, nil); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if got := existing["env_token"]; got.Value != "env-token" || !got.Secret {
+		t.Fatalf("expected masked env token, got %#v", got)
+	}
+	if got := existing["region"].Value; got != "eu-west-1" {
+		t.Fatalf("expected region default, got %v", got)
+	}
+	if got := existing["file_token"].Value; got != "file-token" {
+		t.Fatalf("expected trimmed file contents, got %q", got)
+	}
+}
+
+func TestExecuteExternalSourceErrors(t *testing.T) {
+	tests := []struct {
+		cfg     VariableConfig
+		wantErr string
+	}{
+		{cfg: VariableConfig{Name: "missing", Type: "string", FromEnv: "FLOWK_TEST_UNSET_VARIABLE"}, wantErr: `environment variable "FLOWK_TEST_UNSET_VARIABLE" is not set`},
+		{cfg: VariableConfig{Name: "nofile", Type: "string", FromFile: filepath.Join(t.TempDir(), "absent")}, wantErr: "reading from_file"},
+		{cfg: VariableConfig{Name: "both", Type: "string", Value: "x", FromEnv: "HOME"}, wantErr: "mutually exclusive"},
+		{cfg: VariableConfig{Name: "stray", Type: "string", Value: "x", Default: "y"}, wantErr: "default requires from_env"},
+	}
+
+	for _, tc := range tests {
+		payload := Payload{Scope: scopeFlow, Vars: []VariableConfig{tc.cfg}}
+		if _, _, err := Execute(payload, map[string]Variable{}, nil); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("%s: expected error containing %q, got %v", tc.cfg.Name, tc.wantErr, err)
+		}
+	}
+}
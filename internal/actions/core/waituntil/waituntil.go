@@ -0,0 +1,211 @@
+package waituntil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"flowk/internal/actions/core/evaluate"
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+const (
+	// ActionName identifies the WAIT_UNTIL action in flow definitions.
+	ActionName = "WAIT_UNTIL"
+)
+
+type action struct{}
+
+// Payload describes the configuration supported by the WAIT_UNTIL action.
+type Payload struct {
+	Conditions          []evaluate.Condition `json:"conditions"`
+	Logic               *evaluate.Logic      `json:"logic,omitempty"`
+	MaxWaitSeconds      float64              `json:"max_wait_seconds"`
+	PollIntervalSeconds float64              `json:"poll_interval_seconds"`
+	Check               *flow.Task           `json:"check,omitempty"`
+}
+
+func init() {
+	registry.Register(action{})
+}
+
+func (action) Name() string {
+	return ActionName
+}
+
+// Validate ensures the payload is well defined.
+func (p Payload) Validate() error {
+	if len(p.Conditions) == 0 {
+		return fmt.Errorf("wait_until action: at least one condition is required")
+	}
+	for i, condition := range p.Conditions {
+		if err := condition.Validate(); err != nil {
+			return fmt.Errorf("wait_until action: conditions[%d]: %w", i, err)
+		}
+	}
+	if err := p.Logic.Validate(len(p.Conditions)); err != nil {
+		return fmt.Errorf("wait_until action: %w", err)
+	}
+	if p.MaxWaitSeconds <= 0 {
+		return fmt.Errorf("wait_until action: max_wait_seconds must be greater than zero")
+	}
+	if p.PollIntervalSeconds <= 0 {
+		return fmt.Errorf("wait_until action: poll_interval_seconds must be greater than zero")
+	}
+	if p.Check != nil {
+		if strings.TrimSpace(p.Check.ID) == "" {
+			return fmt.Errorf("wait_until action: check.id is required")
+		}
+		if strings.TrimSpace(p.Check.Action) == "" {
+			return fmt.Errorf("wait_until action: check.action is required")
+		}
+	}
+	return nil
+}
+
+func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	if execCtx == nil {
+		return registry.Result{}, fmt.Errorf("wait_until action: execution context unavailable")
+	}
+
+	var cfg Payload
+	if err := json.Unmarshal(payload, &cfg); err != nil {
+		return registry.Result{}, fmt.Errorf("wait_until action: decoding payload: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return registry.Result{}, err
+	}
+	if cfg.Check != nil && execCtx.ExecuteTask == nil {
+		return registry.Result{}, fmt.Errorf("wait_until action: task executor unavailable")
+	}
+
+	maxWait := time.Duration(cfg.MaxWaitSeconds * float64(time.Second))
+	interval := time.Duration(cfg.PollIntervalSeconds * float64(time.Second))
+	start := time.Now()
+	deadline := start.Add(maxWait)
+	variables := cloneVariables(execCtx.Variables)
+
+	polls := 0
+	for {
+		polls++
+		matches, checkResult, err := poll(ctx, cfg, execCtx, variables)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return registry.Result{}, ctxErr
+		}
+		if err == nil && matches {
+			elapsed := time.Since(start)
+			logf(execCtx, "WAIT_UNTIL: condition met after %d poll(s) in %.2f seconds", polls, elapsed.Seconds())
+			execCtx.Variables = variables
+
+			value := map[string]any{
+				"polls":           polls,
+				"elapsed_seconds": elapsed.Seconds(),
+			}
+			if checkResult != nil {
+				value["check"] = checkResult.Value
+			}
+			return registry.Result{Value: value, Type: flow.ResultTypeJSON}, nil
+		}
+
+		if err != nil {
+			logf(execCtx, "WAIT_UNTIL: poll %d: %v", polls, err)
+		} else {
+			logf(execCtx, "WAIT_UNTIL: poll %d: condition not met", polls)
+		}
+
+		if !time.Now().Before(deadline) {
+			timeoutErr := fmt.Errorf("wait_until action: timeout after %.2f seconds: condition not met after %d poll(s)", cfg.MaxWaitSeconds, polls)
+			if err != nil {
+				timeoutErr = fmt.Errorf("%w: last error: %v", timeoutErr, err)
+			}
+			return registry.Result{}, timeoutErr
+		}
+
+		wait := interval
+		if remaining := time.Until(deadline); remaining < wait {
+			wait = remaining
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return registry.Result{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// poll runs the check task when one is configured and evaluates the
+// conditions. Failed checks and conditions that cannot be resolved yet are
+// reported as errors so the caller keeps polling. variables is updated with
+// the state left by the check task.
+func poll(ctx context.Context, cfg Payload, execCtx *registry.ExecutionContext, variables map[string]registry.Variable) (bool, *registry.Result, error) {
+	target := execCtx.Task
+	tasks := execCtx.Tasks
+	var checkResult *registry.Result
+
+	if cfg.Check != nil {
+		check := *cfg.Check
+		check.ID = strings.TrimSpace(check.ID)
+		if check.FlowID == "" && execCtx.Task != nil {
+			check.FlowID = execCtx.Task.FlowID
+		}
+
+		resp, err := execCtx.ExecuteTask(ctx, registry.TaskExecutionRequest{
+			Task:      &check,
+			Tasks:     execCtx.Tasks,
+			Variables: cloneVariables(variables),
+			LogDir:    execCtx.LogDir,
+		})
+		if err != nil {
+			return false, nil, fmt.Errorf("check task %s: %w", check.ID, err)
+		}
+		for name, variable := range resp.Variables {
+			variables[name] = variable
+		}
+
+		check.Result = resp.Result.Value
+		check.ResultType = resp.Result.Type
+		check.Status = flow.TaskStatusCompleted
+		check.Success = true
+		checkResult = &resp.Result
+
+		target = &check
+		tasks = append(append([]flow.Task(nil), execCtx.Tasks...), check)
+	}
+
+	matches, _, err := evaluate.ExecuteWithLogic(target, tasks, variableValues(variables), cfg.Conditions, cfg.Logic, execCtx.Logger)
+	if err != nil {
+		return false, checkResult, err
+	}
+	return matches, checkResult, nil
+}
+
+func logf(execCtx *registry.ExecutionContext, format string, args ...any) {
+	if execCtx.Logger != nil {
+		execCtx.Logger.Printf(format, args...)
+	}
+}
+
+func cloneVariables(vars map[string]registry.Variable) map[string]registry.Variable {
+	cloned := make(map[string]registry.Variable, len(vars))
+	for key, value := range vars {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+func variableValues(vars map[string]registry.Variable) map[string]any {
+	if len(vars) == 0 {
+		return nil
+	}
+	values := make(map[string]any, len(vars))
+	for name, variable := range vars {
+		values[name] = variable.Value
+	}
+	return values
+}
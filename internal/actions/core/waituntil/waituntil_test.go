@@ -0,0 +1,182 @@
+package waituntil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"flowk/internal/actions/core/evaluate"
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+type stubLogger struct {
+	messages []string
+}
+
+func (l *stubLogger) Printf(format string, args ...any) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *stubLogger) PrintColored(plain, _ string) {
+	l.messages = append(l.messages, plain)
+}
+
+func newExecutionContext() *registry.ExecutionContext {
+	return &registry.ExecutionContext{
+		Task:   &flow.Task{ID: "wait", FlowID: "demo"},
+		Logger: &stubLogger{},
+	}
+}
+
+func TestPayloadValidate(t *testing.T) {
+	valid := Payload{
+		Conditions:          []evaluate.Condition{{Left: "${ready}", Operation: "=", Right: true}},
+		MaxWaitSeconds:      1,
+		PollIntervalSeconds: 0.1,
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cases := map[string]struct {
+		mutate func(*Payload)
+		want   string
+	}{
+		"no conditions":     {func(p *Payload) { p.Conditions = nil }, "at least one condition"},
+		"bad operation":     {func(p *Payload) { p.Conditions[0].Operation = "~" }, "conditions[0]"},
+		"no max wait":       {func(p *Payload) { p.MaxWaitSeconds = 0 }, "max_wait_seconds"},
+		"no poll interval":  {func(p *Payload) { p.PollIntervalSeconds = -1 }, "poll_interval_seconds"},
+		"check without id":  {func(p *Payload) { p.Check = &flow.Task{Action: "HTTP"} }, "check.id"},
+		"check without act": {func(p *Payload) { p.Check = &flow.Task{ID: "probe"} }, "check.action"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			payload := valid
+			payload.Conditions = append([]evaluate.Condition(nil), valid.Conditions...)
+			tc.mutate(&payload)
+			if err := payload.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecutePollsCheckTaskUntilConditionHolds(t *testing.T) {
+	execCtx := newExecutionContext()
+	calls := 0
+	execCtx.ExecuteTask = func(_ context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+		calls++
+		if req.Task.ID != "health" || req.Task.FlowID != "demo" {
+			t.Fatalf("unexpected check task: %+v", req.Task)
+		}
+		if calls == 1 {
+			return registry.TaskExecutionResponse{}, errors.New("connection refused")
+		}
+		status := "starting"
+		if calls >= 3 {
+			status = "ok"
+		}
+		return registry.TaskExecutionResponse{
+			Result: registry.Result{Value: map[string]any{"status": status}, Type: flow.ResultTypeJSON},
+		}, nil
+	}
+
+	payload := json.RawMessage(`{
+		"conditions": [{"left": "${from.task:health.result$.status}", "operation": "=", "right": "ok"}],
+		"max_wait_seconds": 5,
+		"poll_interval_seconds": 0.01,
+		"check": {"id": "health", "action": "HTTP"}
+	}`)
+
+	result, err := action{}.Execute(context.Background(), payload, execCtx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Type != flow.ResultTypeJSON {
+		t.Fatalf("unexpected result type %q", result.Type)
+	}
+	value := result.Value.(map[string]any)
+	if value["polls"] != 3 {
+		t.Fatalf("polls = %v, want 3", value["polls"])
+	}
+	if elapsed, ok := value["elapsed_seconds"].(float64); !ok || elapsed <= 0 {
+		t.Fatalf("unexpected elapsed_seconds %v", value["elapsed_seconds"])
+	}
+	check, ok := value["check"].(map[string]any)
+	if !ok || check["status"] != "ok" {
+		t.Fatalf("unexpected check result %v", value["check"])
+	}
+}
+
+func TestExecuteUsesVariablesSetByCheckTask(t *testing.T) {
+	execCtx := newExecutionContext()
+	execCtx.Variables = map[string]registry.Variable{"attempts": {Name: "attempts", Type: "number", Value: float64(0)}}
+	execCtx.ExecuteTask = func(_ context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+		vars := req.Variables
+		attempts := vars["attempts"].Value.(float64) + 1
+		vars["attempts"] = registry.Variable{Name: "attempts", Type: "number", Value: attempts}
+		return registry.TaskExecutionResponse{Result: registry.Result{Value: attempts, Type: flow.ResultTypeFloat}, Variables: vars}, nil
+	}
+
+	payload := json.RawMessage(`{
+		"conditions": [{"left": "${attempts}", "operation": ">=", "right": 2}],
+		"max_wait_seconds": 5,
+		"poll_interval_seconds": 0.01,
+		"check": {"id": "count", "action": "VARIABLES"}
+	}`)
+
+	result, err := action{}.Execute(context.Background(), payload, execCtx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if polls := result.Value.(map[string]any)["polls"]; polls != 2 {
+		t.Fatalf("polls = %v, want 2", polls)
+	}
+	if got := execCtx.Variables["attempts"].Value; got != float64(2) {
+		t.Fatalf("attempts variable = %v, want 2", got)
+	}
+}
+
+func TestExecuteTimesOut(t *testing.T) {
+	execCtx := newExecutionContext()
+	execCtx.Variables = map[string]registry.Variable{"ready": {Name: "ready", Type: "bool", Value: false}}
+
+	payload := json.RawMessage(`{
+		"conditions": [{"left": "${ready}", "operation": "=", "right": true}],
+		"max_wait_seconds": 0.05,
+		"poll_interval_seconds": 0.01
+	}`)
+
+	_, err := action{}.Execute(context.Background(), payload, execCtx)
+	if err == nil || !strings.Contains(err.Error(), "timeout") || !strings.Contains(err.Error(), "poll(s)") {
+		t.Fatalf("Execute() error = %v, want timeout", err)
+	}
+}
+
+func TestExecuteStopsWhenContextIsCancelled(t *testing.T) {
+	execCtx := newExecutionContext()
+	execCtx.Variables = map[string]registry.Variable{"ready": {Name: "ready", Type: "bool", Value: false}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	payload := json.RawMessage(`{
+		"conditions": [{"left": "${ready}", "operation": "=", "right": true}],
+		"max_wait_seconds": 60,
+		"poll_interval_seconds": 1
+	}`)
+
+	start := time.Now()
+	_, err := action{}.Execute(ctx, payload, execCtx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Execute() error = %v, want context deadline exceeded", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("Execute() did not stop on cancellation")
+	}
+}
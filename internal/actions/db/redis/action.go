@@ -0,0 +1,291 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+const (
+	// ActionName identifies the Redis action in flow definitions.
+	ActionName = "REDIS"
+
+	OperationGet     = "GET"
+	OperationSet     = "SET"
+	OperationDel     = "DEL"
+	OperationIncr    = "INCR"
+	OperationPublish = "PUBLISH"
+	OperationExpire  = "EXPIRE"
+
+	defaultPort = 6379
+)
+
+func init() {
+	registry.Register(action{})
+}
+
+type action struct{}
+
+func (action) Name() string { return ActionName }
+
+type taskConfig struct {
+	Host               string `json:"host"`
+	Port               int    `json:"port"`
+	Username           string `json:"username"`
+	Password           string `json:"password"`
+	DB                 int    `json:"db"`
+	TLS                bool   `json:"tls"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
+
+	Operation    string   `json:"operation"`
+	Key          string   `json:"key"`
+	Keys         []string `json:"keys"`
+	Value        any      `json:"value"`
+	Channel      string   `json:"channel"`
+	TTLSeconds   int      `json:"ttl_seconds"`
+	OnlyIfAbsent bool     `json:"only_if_absent"`
+	Increment    *int64   `json:"increment"`
+}
+
+func (c *taskConfig) Validate() error {
+	c.Host = strings.TrimSpace(c.Host)
+	c.Operation = strings.ToUpper(strings.TrimSpace(c.Operation))
+	c.Channel = strings.TrimSpace(c.Channel)
+
+	if c.Host == "" {
+		return errors.New("redis task: host is required")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("redis task: port %d is out of range", c.Port)
+	}
+	if c.DB < 0 {
+		return fmt.Errorf("redis task: db %d must not be negative", c.DB)
+	}
+	if c.InsecureSkipVerify && !c.TLS {
+		return errors.New("redis task: insecureSkipVerify requires tls")
+	}
+	if c.TTLSeconds < 0 {
+		return fmt.Errorf("redis task: ttl_seconds %d must not be negative", c.TTLSeconds)
+	}
+
+	switch c.Operation {
+	case OperationGet, OperationSet, OperationIncr, OperationExpire, OperationDel:
+	case OperationPublish:
+		if c.Channel == "" {
+			return errors.New("redis task: channel is required for PUBLISH operation")
+		}
+		if c.Value == nil {
+			return errors.New("redis task: value is required for PUBLISH operation")
+		}
+		if c.Key != "" || len(c.Keys) > 0 {
+			return errors.New("redis task: key is not supported for PUBLISH operation")
+		}
+	case "":
+		return errors.New("redis task: operation is required")
+	default:
+		return fmt.Errorf("redis task: unsupported operation %q", c.Operation)
+	}
+
+	if c.Operation != OperationPublish && c.Channel != "" {
+		return errors.New("redis task: channel is only supported for PUBLISH operation")
+	}
+	if c.Operation == OperationDel {
+		if c.Key != "" {
+			c.Keys = append([]string{c.Key}, c.Keys...)
+			c.Key = ""
+		}
+		if len(c.Keys) == 0 {
+			return errors.New("redis task: key or keys is required for DEL operation")
+		}
+	} else if len(c.Keys) > 0 {
+		return errors.New("redis task: keys is only supported for DEL operation")
+	} else if c.Operation != OperationPublish && c.Key == "" {
+		return fmt.Errorf("redis task: key is required for %s operation", c.Operation)
+	}
+
+	if c.Operation == OperationSet && c.Value == nil {
+		return errors.New("redis task: value is required for SET operation")
+	}
+	if c.Value != nil && c.Operation != OperationSet && c.Operation != OperationPublish {
+		return errors.New("redis task: value is only supported for SET and PUBLISH operations")
+	}
+	if _, err := c.valueString(); err != nil {
+		return err
+	}
+	if c.Operation == OperationExpire && c.TTLSeconds == 0 {
+		return errors.New("redis task: ttl_seconds is required for EXPIRE operation")
+	}
+	if c.TTLSeconds != 0 && c.Operation != OperationSet && c.Operation != OperationExpire {
+		return errors.New("redis task: ttl_seconds is only supported for SET and EXPIRE operations")
+	}
+	if c.OnlyIfAbsent && c.Operation != OperationSet {
+		return errors.New("redis task: only_if_absent is only supported for SET operation")
+	}
+	if c.Increment != nil && c.Operation != OperationIncr {
+		return errors.New("redis task: increment is only supported for INCR operation")
+	}
+	return nil
+}
+
+func decodeTask(data json.RawMessage) (taskConfig, error) {
+	var cfg taskConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("decoding redis task payload: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// valueString returns the value stored by SET or sent by PUBLISH. Redis
+// values are strings, so numbers are written without a fractional part when
+// they are whole.
+func (c taskConfig) valueString() (string, error) {
+	switch v := c.Value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("redis task: value must be a string or a number, got %T", c.Value)
+	}
+}
+
+func (c taskConfig) address() string {
+	port := c.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(port))
+}
+
+func (c taskConfig) options() *goredis.Options {
+	opts := &goredis.Options{
+		Addr:            c.address(),
+		Username:        c.Username,
+		Password:        c.Password,
+		DB:              c.DB,
+		DisableIdentity: true,
+	}
+	if c.TLS {
+		opts.TLSConfig = &tls.Config{ServerName: c.Host, InsecureSkipVerify: c.InsecureSkipVerify, MinVersion: tls.VersionTLS12} //nolint:gosec
+	}
+	return opts
+}
+
+func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	cfg, err := decodeTask(payload)
+	if err != nil {
+		return registry.Result{}, err
+	}
+
+	var logger registry.Logger
+	if execCtx != nil {
+		logger = execCtx.Logger
+	}
+
+	result, err := execute(ctx, cfg, logger)
+	if err != nil {
+		return registry.Result{}, err
+	}
+	return registry.Result{Value: result, Type: flow.ResultTypeJSON}, nil
+}
+
+// execute runs the operation on a short-lived client. A missing key is not an
+// error: GET reports exists false and EXPIRE reports updated false.
+func execute(ctx context.Context, cfg taskConfig, logger registry.Logger) (map[string]any, error) {
+	client := goredis.NewClient(cfg.options())
+	defer client.Close()
+
+	target := cfg.Key
+	switch cfg.Operation {
+	case OperationDel:
+		target = strings.Join(cfg.Keys, ", ")
+	case OperationPublish:
+		target = "channel " + cfg.Channel
+	}
+	logf(logger, "REDIS: %s %s on %s", cfg.Operation, target, cfg.address())
+
+	value, _ := cfg.valueString()
+	ttl := time.Duration(cfg.TTLSeconds) * time.Second
+
+	switch cfg.Operation {
+	case OperationGet:
+		stored, err := client.Get(ctx, cfg.Key).Result()
+		if errors.Is(err, goredis.Nil) {
+			return map[string]any{"key": cfg.Key, "value": nil, "exists": false}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("redis task: GET %s: %w", cfg.Key, err)
+		}
+		return map[string]any{"key": cfg.Key, "value": stored, "exists": true}, nil
+	case OperationSet:
+		args := goredis.SetArgs{TTL: ttl}
+		if cfg.OnlyIfAbsent {
+			args.Mode = "NX"
+		}
+		err := client.SetArgs(ctx, cfg.Key, value, args).Err()
+		if errors.Is(err, goredis.Nil) {
+			logf(logger, "REDIS: %s already exists, not set", cfg.Key)
+			return map[string]any{"key": cfg.Key, "set": false}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("redis task: SET %s: %w", cfg.Key, err)
+		}
+		return map[string]any{"key": cfg.Key, "set": true}, nil
+	case OperationDel:
+		deleted, err := client.Del(ctx, cfg.Keys...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redis task: DEL: %w", err)
+		}
+		return map[string]any{"keys": cfg.Keys, "deleted": deleted}, nil
+	case OperationIncr:
+		increment := int64(1)
+		if cfg.Increment != nil {
+			increment = *cfg.Increment
+		}
+		counter, err := client.IncrBy(ctx, cfg.Key, increment).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redis task: INCR %s: %w", cfg.Key, err)
+		}
+		return map[string]any{"key": cfg.Key, "value": counter}, nil
+	case OperationPublish:
+		receivers, err := client.Publish(ctx, cfg.Channel, value).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redis task: PUBLISH %s: %w", cfg.Channel, err)
+		}
+		return map[string]any{"channel": cfg.Channel, "receivers": receivers}, nil
+	case OperationExpire:
+		updated, err := client.Expire(ctx, cfg.Key, ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redis task: EXPIRE %s: %w", cfg.Key, err)
+		}
+		return map[string]any{"key": cfg.Key, "updated": updated}, nil
+	default:
+		return nil, fmt.Errorf("redis task: unsupported operation %q", cfg.Operation)
+	}
+}
+
+func logf(logger registry.Logger, format string, args ...any) {
+	if logger != nil {
+		logger.Printf(format, args...)
+	}
+}
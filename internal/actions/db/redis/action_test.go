@@ -0,0 +1,253 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServer speaks enough of the RESP protocol to serve the commands the
+// action sends. It rejects HELLO so the client falls back to RESP2, and
+// requires AUTH when password is set.
+type fakeServer struct {
+	password string
+
+	mu        sync.Mutex
+	values    map[string]string
+	ttls      map[string]string
+	published []string
+}
+
+func startFakeServer(t *testing.T, password string) (*fakeServer, string, int) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeServer{password: password, values: map[string]string{}, ttls: map[string]string{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return server, addr.IP.String(), addr.Port
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authed := s.password == ""
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		name := strings.ToUpper(args[0])
+		if !authed && name != "AUTH" && name != "HELLO" {
+			io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		if name == "AUTH" {
+			authed = args[len(args)-1] == s.password
+			if !authed {
+				io.WriteString(conn, "-WRONGPASS invalid username-password pair\r\n")
+				continue
+			}
+		}
+		io.WriteString(conn, s.reply(name, args[1:]))
+	}
+}
+
+func (s *fakeServer) reply(name string, args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		value, ok := s.values[args[0]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		key := args[0]
+		options := strings.ToUpper(strings.Join(args[2:], " "))
+		if _, exists := s.values[key]; exists && strings.Contains(options, "NX") {
+			return "$-1\r\n"
+		}
+		s.values[key] = args[1]
+		s.ttls[key] = options
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "INCRBY":
+		current, _ := strconv.ParseInt(s.values[args[0]], 10, 64)
+		increment, _ := strconv.ParseInt(args[1], 10, 64)
+		s.values[args[0]] = strconv.FormatInt(current+increment, 10)
+		return fmt.Sprintf(":%d\r\n", current+increment)
+	case "PUBLISH":
+		s.published = append(s.published, args[0]+"="+args[1])
+		return ":2\r\n"
+	case "EXPIRE":
+		if _, ok := s.values[args[0]]; !ok {
+			return ":0\r\n"
+		}
+		s.ttls[args[0]] = "EX " + args[1]
+		return ":1\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", name)
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		lengthLine, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(lengthLine, "$")))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:length])
+	}
+	return args, nil
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     taskConfig
+		wantErr string
+	}{
+		{name: "missing host", cfg: taskConfig{Operation: "GET", Key: "k"}, wantErr: "host is required"},
+		{name: "unknown operation", cfg: taskConfig{Host: "h", Operation: "HGET", Key: "k"}, wantErr: "unsupported operation"},
+		{name: "get without key", cfg: taskConfig{Host: "h", Operation: "GET"}, wantErr: "key is required for GET"},
+		{name: "set without value", cfg: taskConfig{Host: "h", Operation: "SET", Key: "k"}, wantErr: "value is required for SET"},
+		{name: "object value", cfg: taskConfig{Host: "h", Operation: "SET", Key: "k", Value: map[string]any{}}, wantErr: "string or a number"},
+		{name: "publish without channel", cfg: taskConfig{Host: "h", Operation: "PUBLISH", Value: "hi"}, wantErr: "channel is required"},
+		{name: "expire without ttl", cfg: taskConfig{Host: "h", Operation: "EXPIRE", Key: "k"}, wantErr: "ttl_seconds is required"},
+		{name: "ttl on get", cfg: taskConfig{Host: "h", Operation: "GET", Key: "k", TTLSeconds: 5}, wantErr: "only supported for SET and EXPIRE"},
+		{name: "nx on incr", cfg: taskConfig{Host: "h", Operation: "INCR", Key: "k", OnlyIfAbsent: true}, wantErr: "only_if_absent is only supported"},
+		{name: "keys on get", cfg: taskConfig{Host: "h", Operation: "GET", Keys: []string{"k"}}, wantErr: "keys is only supported for DEL"},
+		{name: "insecure without tls", cfg: taskConfig{Host: "h", Operation: "GET", Key: "k", InsecureSkipVerify: true}, wantErr: "requires tls"},
+		{name: "del without keys", cfg: taskConfig{Host: "h", Operation: "DEL"}, wantErr: "key or keys is required"},
+		{name: "valid set", cfg: taskConfig{Host: "h", Operation: "set", Key: "k", Value: float64(3), TTLSeconds: 60, OnlyIfAbsent: true}},
+		{name: "valid publish", cfg: taskConfig{Host: "h", Operation: "PUBLISH", Channel: "events", Value: "deployed"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func run(t *testing.T, host string, port int, fields string) (map[string]any, error) {
+	t.Helper()
+	payload := json.RawMessage(fmt.Sprintf(`{"host":%q,"port":%d,"password":"s3cret",%s}`, host, port, fields))
+	result, err := action{}.Execute(context.Background(), payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	return result.Value.(map[string]any), nil
+}
+
+func TestExecuteOperations(t *testing.T) {
+	t.Parallel()
+
+	server, host, port := startFakeServer(t, "s3cret")
+	steps := []struct {
+		fields string
+		want   string
+	}{
+		{`"operation":"SET","key":"lock","value":"run-1","ttl_seconds":30,"only_if_absent":true`, `map[key:lock set:true]`},
+		{`"operation":"SET","key":"lock","value":"run-2","only_if_absent":true`, `map[key:lock set:false]`},
+		{`"operation":"GET","key":"lock"`, `map[exists:true key:lock value:run-1]`},
+		{`"operation":"GET","key":"missing"`, `map[exists:false key:missing value:<nil>]`},
+		{`"operation":"SET","key":"count","value":3`, `map[key:count set:true]`},
+		{`"operation":"INCR","key":"count"`, `map[key:count value:4]`},
+		{`"operation":"INCR","key":"count","increment":-10`, `map[key:count value:-6]`},
+		{`"operation":"EXPIRE","key":"count","ttl_seconds":60`, `map[key:count updated:true]`},
+		{`"operation":"EXPIRE","key":"missing","ttl_seconds":60`, `map[key:missing updated:false]`},
+		{`"operation":"PUBLISH","channel":"events","value":"deployed"`, `map[channel:events receivers:2]`},
+		{`"operation":"DEL","key":"lock","keys":["count","missing"]`, `map[deleted:2 keys:[lock count missing]]`},
+	}
+	for _, step := range steps {
+		result, err := run(t, host, port, step.fields)
+		if err != nil {
+			t.Fatalf("%s: Execute() error = %v", step.fields, err)
+		}
+		if got := fmt.Sprint(result); got != step.want {
+			t.Fatalf("%s: result = %s, want %s", step.fields, got, step.want)
+		}
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.values) != 0 {
+		t.Fatalf("values left after DEL: %v", server.values)
+	}
+	if server.ttls["lock"] != "EX 30 NX" || server.ttls["count"] != "EX 60" {
+		t.Fatalf("expirations = %v", server.ttls)
+	}
+	if len(server.published) != 1 || server.published[0] != "events=deployed" {
+		t.Fatalf("published = %v", server.published)
+	}
+}
+
+func TestExecuteRejectsWrongPassword(t *testing.T) {
+	t.Parallel()
+
+	_, host, port := startFakeServer(t, "other")
+	if _, err := run(t, host, port, `"operation":"GET","key":"k"`); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+}
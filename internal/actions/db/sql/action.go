@@ -0,0 +1,345 @@
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"flowk/internal/actions/db/common"
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+const (
+	// ActionName identifies the SQL action in flow definitions.
+	ActionName = "SQL"
+
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// driverNames maps the driver accepted in the payload to the database/sql
+// driver registered for it.
+var driverNames = map[string]string{
+	DriverPostgres: "pgx",
+	DriverMySQL:    "mysql",
+}
+
+var defaultPorts = map[string]int{
+	DriverPostgres: 5432,
+	DriverMySQL:    3306,
+}
+
+// rowStatementPattern matches statements that return rows.
+var rowStatementPattern = regexp.MustCompile(`(?is)^\s*(select|with|show|explain|values|table|describe|desc)\b|\breturning\b`)
+
+func init() {
+	registry.Register(action{})
+}
+
+type action struct{}
+
+func (action) Name() string { return ActionName }
+
+type taskConfig struct {
+	Driver      string     `json:"driver"`
+	DSN         string     `json:"dsn"`
+	Host        string     `json:"host"`
+	Port        int        `json:"port"`
+	Username    string     `json:"username"`
+	Password    string     `json:"password"`
+	Database    string     `json:"database"`
+	SSLMode     string     `json:"sslmode"`
+	Transaction bool       `json:"transaction"`
+	Steps       []stepSpec `json:"steps"`
+}
+
+type stepSpec struct {
+	ID          string `json:"id"`
+	Query       string `json:"query"`
+	Args        []any  `json:"args"`
+	ReturnsRows *bool  `json:"returns_rows"`
+}
+
+func (c *taskConfig) Validate() error {
+	c.Driver = strings.ToLower(strings.TrimSpace(c.Driver))
+	if c.Driver == "" {
+		return errors.New("sql task: driver is required")
+	}
+	if _, ok := driverNames[c.Driver]; !ok {
+		return fmt.Errorf("sql task: unsupported driver %q", c.Driver)
+	}
+
+	if strings.TrimSpace(c.DSN) != "" {
+		if strings.TrimSpace(c.Host) != "" || strings.TrimSpace(c.Database) != "" {
+			return errors.New("sql task: dsn cannot be combined with host or database")
+		}
+	} else {
+		if strings.TrimSpace(c.Host) == "" {
+			return errors.New("sql task: dsn or host is required")
+		}
+		if strings.TrimSpace(c.Database) == "" {
+			return errors.New("sql task: database is required when dsn is not set")
+		}
+		if c.Port < 0 || c.Port > 65535 {
+			return fmt.Errorf("sql task: port %d is out of range", c.Port)
+		}
+	}
+	if c.SSLMode != "" && c.Driver != DriverPostgres {
+		return errors.New("sql task: sslmode is only supported by the postgres driver")
+	}
+
+	if len(c.Steps) == 0 {
+		return errors.New("sql task: at least one step is required")
+	}
+	for i, step := range c.Steps {
+		if strings.TrimSpace(step.Query) == "" {
+			return fmt.Errorf("sql task: steps[%d]: query is required", i)
+		}
+	}
+	return nil
+}
+
+func decodeTask(data json.RawMessage) (taskConfig, error) {
+	var cfg taskConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("decoding sql task payload: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// dataSourceName returns the DSN to connect with, building it from the
+// discrete connection fields when dsn is not set.
+func (c taskConfig) dataSourceName() string {
+	if dsn := strings.TrimSpace(c.DSN); dsn != "" {
+		return dsn
+	}
+
+	port := c.Port
+	if port == 0 {
+		port = defaultPorts[c.Driver]
+	}
+	addr := net.JoinHostPort(strings.TrimSpace(c.Host), strconv.Itoa(port))
+
+	switch c.Driver {
+	case DriverMySQL:
+		driverCfg := mysqlDriver.NewConfig()
+		driverCfg.User = c.Username
+		driverCfg.Passwd = c.Password
+		driverCfg.Net = "tcp"
+		driverCfg.Addr = addr
+		driverCfg.DBName = strings.TrimSpace(c.Database)
+		driverCfg.ParseTime = true
+		return driverCfg.FormatDSN()
+	default:
+		sslMode := strings.TrimSpace(c.SSLMode)
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		u := &url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.Username, c.Password),
+			Host:     addr,
+			Path:     "/" + strings.TrimSpace(c.Database),
+			RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+		}
+		return u.String()
+	}
+}
+
+// describe names the target database without exposing credentials.
+func (c taskConfig) describe() string {
+	if strings.TrimSpace(c.DSN) != "" {
+		return c.Driver + " database"
+	}
+	return fmt.Sprintf("%s database %s on %s", c.Driver, strings.TrimSpace(c.Database), strings.TrimSpace(c.Host))
+}
+
+func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	cfg, err := decodeTask(payload)
+	if err != nil {
+		return registry.Result{}, err
+	}
+
+	var logger registry.Logger
+	if execCtx != nil {
+		logger = execCtx.Logger
+	}
+
+	results, err := execute(ctx, cfg, logger)
+	if err != nil {
+		return registry.Result{}, err
+	}
+	return registry.Result{Value: map[string]any{"steps": results}, Type: flow.ResultTypeJSON}, nil
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*stdsql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (stdsql.Result, error)
+}
+
+// execute runs the configured steps in order. In transaction mode every step
+// runs in a single transaction that is rolled back when any of them fails.
+// Each step yields its rows for queries or rows_affected for other statements.
+func execute(ctx context.Context, cfg taskConfig, logger registry.Logger) ([]map[string]any, error) {
+	db, err := stdsql.Open(driverNames[cfg.Driver], cfg.dataSourceName())
+	if err != nil {
+		return nil, fmt.Errorf("sql task: connecting to %s: %w", cfg.describe(), err)
+	}
+	defer db.Close()
+
+	pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		return nil, fmt.Errorf("sql task: connecting to %s: %w", cfg.describe(), err)
+	}
+
+	logf(logger, "SQL: running %d step(s) against %s", len(cfg.Steps), cfg.describe())
+
+	if !cfg.Transaction {
+		return runSteps(ctx, db, cfg.Steps, logger)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sql task: starting transaction: %w", err)
+	}
+	results, err := runSteps(ctx, tx, cfg.Steps, logger)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		logf(logger, "SQL: transaction rolled back")
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("sql task: committing transaction: %w", err)
+	}
+	logf(logger, "SQL: transaction committed")
+	return results, nil
+}
+
+func runSteps(ctx context.Context, q querier, steps []stepSpec, logger registry.Logger) ([]map[string]any, error) {
+	results := make([]map[string]any, 0, len(steps))
+	for i, step := range steps {
+		result := make(map[string]any)
+		if id := strings.TrimSpace(step.ID); id != "" {
+			result["id"] = id
+		}
+
+		if step.returnsRows() {
+			rows, err := queryRows(ctx, q, step)
+			if err != nil {
+				return nil, fmt.Errorf("sql task: %s: %w", stepLabel(i, step), err)
+			}
+			result["rows"] = rows
+			logf(logger, "SQL: %s returned %d row(s)", stepLabel(i, step), len(rows))
+		} else {
+			affected, err := execStatement(ctx, q, step)
+			if err != nil {
+				return nil, fmt.Errorf("sql task: %s: %w", stepLabel(i, step), err)
+			}
+			result["rows_affected"] = affected
+			logf(logger, "SQL: %s affected %d row(s)", stepLabel(i, step), affected)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func queryRows(ctx context.Context, q querier, step stepSpec) ([]map[string]any, error) {
+	rows, err := q.QueryContext(ctx, step.Query, queryArgs(step.Args)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("reading columns: %w", err)
+	}
+	results := make([]map[string]any, 0)
+	for rows.Next() {
+		row, err := common.ScanRow(columns, rows)
+		if err != nil {
+			return nil, fmt.Errorf("reading row: %w", err)
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func execStatement(ctx context.Context, q querier, step stepSpec) (int64, error) {
+	res, err := q.ExecContext(ctx, step.Query, queryArgs(step.Args)...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("reading affected rows: %w", err)
+	}
+	return affected, nil
+}
+
+// queryArgs converts decoded JSON arguments to values the drivers accept:
+// whole numbers become int64 and objects or arrays are passed as JSON text.
+func queryArgs(args []any) []any {
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				converted[i] = int64(v)
+			} else {
+				converted[i] = v
+			}
+		case map[string]any, []any:
+			data, _ := json.Marshal(v)
+			converted[i] = string(data)
+		default:
+			converted[i] = v
+		}
+	}
+	return converted
+}
+
+// returnsRows reports whether the step is run as a query. Statements are
+// classified by their leading keyword unless returns_rows is set.
+func (s stepSpec) returnsRows() bool {
+	if s.ReturnsRows != nil {
+		return *s.ReturnsRows
+	}
+	return rowStatementPattern.MatchString(s.Query)
+}
+
+func stepLabel(index int, step stepSpec) string {
+	if id := strings.TrimSpace(step.ID); id != "" {
+		return fmt.Sprintf("step %q", id)
+	}
+	return fmt.Sprintf("step %d", index)
+}
+
+func logf(logger registry.Logger, format string, args ...any) {
+	if logger != nil {
+		logger.Printf(format, args...)
+	}
+}
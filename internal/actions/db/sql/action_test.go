@@ -0,0 +1,250 @@
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeDriver records the statements it receives. Queries return two rows,
+// statements containing FAIL return an error and any other statement
+// affects one row.
+type fakeDriver struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (d *fakeDriver) record(entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, entry)
+}
+
+func (d *fakeDriver) entries() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.log...)
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{driver: d}, nil }
+
+type fakeConn struct{ driver *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{driver: c.driver, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.driver.record("BEGIN")
+	return &fakeTx{driver: c.driver}, nil
+}
+
+type fakeTx struct{ driver *fakeDriver }
+
+func (t *fakeTx) Commit() error   { t.driver.record("COMMIT"); return nil }
+func (t *fakeTx) Rollback() error { t.driver.record("ROLLBACK"); return nil }
+
+type fakeStmt struct {
+	driver *fakeDriver
+	query  string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.record(fmt.Sprintf("%s %v", s.query, args))
+	if strings.Contains(s.query, "FAIL") {
+		return nil, errors.New("statement failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.record(fmt.Sprintf("%s %v", s.query, args))
+	return &fakeRows{values: [][]driver.Value{{int64(1), []byte("alice")}, {int64(2), []byte("bob")}}}, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+	next   int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+var fakeDriverCount atomic.Int64
+
+func newFakeDriver(t *testing.T) *fakeDriver {
+	t.Helper()
+	fake := &fakeDriver{}
+	name := fmt.Sprintf("sqltest-%d", fakeDriverCount.Add(1))
+	stdsql.Register(name, fake)
+	driverNames["sqltest"] = name
+	t.Cleanup(func() { delete(driverNames, "sqltest") })
+	return fake
+}
+
+func runAction(t *testing.T, payload map[string]any) (any, error) {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	result, err := action{}.Execute(context.Background(), raw, nil)
+	return result.Value, err
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     taskConfig
+		wantErr string
+	}{
+		{name: "missing driver", cfg: taskConfig{DSN: "x", Steps: []stepSpec{{Query: "SELECT 1"}}}, wantErr: "driver is required"},
+		{name: "unsupported driver", cfg: taskConfig{Driver: "oracle", DSN: "x", Steps: []stepSpec{{Query: "SELECT 1"}}}, wantErr: "unsupported driver"},
+		{name: "missing connection", cfg: taskConfig{Driver: "postgres", Steps: []stepSpec{{Query: "SELECT 1"}}}, wantErr: "dsn or host is required"},
+		{name: "missing database", cfg: taskConfig{Driver: "postgres", Host: "db", Steps: []stepSpec{{Query: "SELECT 1"}}}, wantErr: "database is required"},
+		{name: "dsn and host", cfg: taskConfig{Driver: "postgres", DSN: "x", Host: "db", Steps: []stepSpec{{Query: "SELECT 1"}}}, wantErr: "cannot be combined"},
+		{name: "mysql sslmode", cfg: taskConfig{Driver: "mysql", DSN: "x", SSLMode: "require", Steps: []stepSpec{{Query: "SELECT 1"}}}, wantErr: "sslmode"},
+		{name: "no steps", cfg: taskConfig{Driver: "mysql", DSN: "x"}, wantErr: "at least one step"},
+		{name: "empty query", cfg: taskConfig{Driver: "mysql", DSN: "x", Steps: []stepSpec{{Query: " "}}}, wantErr: "steps[0]: query is required"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.cfg
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDataSourceName(t *testing.T) {
+	postgres := taskConfig{Driver: DriverPostgres, Host: "db", Username: "app", Password: "p@ss", Database: "orders"}
+	if got, want := postgres.dataSourceName(), "postgres://app:p%40ss@db:5432/orders?sslmode=disable"; got != want {
+		t.Fatalf("postgres dsn = %q, want %q", got, want)
+	}
+
+	mysql := taskConfig{Driver: DriverMySQL, Host: "db", Port: 3307, Username: "app", Password: "secret", Database: "orders"}
+	if got, want := mysql.dataSourceName(), "app:secret@tcp(db:3307)/orders?parseTime=true"; got != want {
+		t.Fatalf("mysql dsn = %q, want %q", got, want)
+	}
+
+	if got := mysql.describe(); strings.Contains(got, "secret") {
+		t.Fatalf("describe() exposes the password: %q", got)
+	}
+}
+
+func TestReturnsRows(t *testing.T) {
+	for query, want := range map[string]bool{
+		"SELECT * FROM t":                        true,
+		"  with x as (select 1) select * from x": true,
+		"INSERT INTO t VALUES (1) RETURNING id":  true,
+		"UPDATE t SET a = 1":                     false,
+		"DELETE FROM selections":                 false,
+	} {
+		if got := (stepSpec{Query: query}).returnsRows(); got != want {
+			t.Errorf("returnsRows(%q) = %v, want %v", query, got, want)
+		}
+	}
+	override := false
+	if (stepSpec{Query: "SELECT pg_notify('x', 'y')", ReturnsRows: &override}).returnsRows() {
+		t.Errorf("returns_rows override ignored")
+	}
+}
+
+func TestExecuteReturnsRowsAndAffectedCounts(t *testing.T) {
+	fake := newFakeDriver(t)
+
+	value, err := runAction(t, map[string]any{
+		"driver": "sqltest",
+		"dsn":    "fake",
+		"steps": []map[string]any{
+			{"id": "insert", "query": "INSERT INTO users (name) VALUES ($1)", "args": []any{"carol", 3, map[string]any{"a": 1}}},
+			{"id": "list", "query": "SELECT id, name FROM users"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	steps := value.(map[string]any)["steps"].([]map[string]any)
+	if len(steps) != 2 {
+		t.Fatalf("unexpected steps: %v", steps)
+	}
+	if steps[0]["id"] != "insert" || steps[0]["rows_affected"] != int64(1) {
+		t.Fatalf("unexpected insert result: %v", steps[0])
+	}
+	rows := steps[1]["rows"].([]map[string]any)
+	if len(rows) != 2 || rows[0]["id"] != int64(1) || rows[1]["name"] != "bob" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+
+	log := fake.entries()
+	if log[0] != `INSERT INTO users (name) VALUES ($1) [carol 3 {"a":1}]` {
+		t.Fatalf("unexpected args: %v", log)
+	}
+	for _, entry := range log {
+		if entry == "BEGIN" {
+			t.Fatalf("transaction started without transaction mode: %v", log)
+		}
+	}
+}
+
+func TestExecuteTransactionRollsBackOnError(t *testing.T) {
+	fake := newFakeDriver(t)
+
+	_, err := runAction(t, map[string]any{
+		"driver":      "sqltest",
+		"dsn":         "fake",
+		"transaction": true,
+		"steps": []map[string]any{
+			{"query": "INSERT INTO users (name) VALUES ('dave')"},
+			{"id": "broken", "query": "UPDATE FAIL"},
+			{"query": "DELETE FROM users"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `step "broken": statement failed`) {
+		t.Fatalf("Execute() error = %v, want failure of step broken", err)
+	}
+
+	log := strings.Join(fake.entries(), "\n")
+	if !strings.HasPrefix(log, "BEGIN\n") || !strings.HasSuffix(log, "ROLLBACK") || strings.Contains(log, "COMMIT") || strings.Contains(log, "DELETE") {
+		t.Fatalf("unexpected statement log:\n%s", log)
+	}
+}
+
+func TestExecuteTransactionCommits(t *testing.T) {
+	fake := newFakeDriver(t)
+
+	if _, err := runAction(t, map[string]any{
+		"driver":      "sqltest",
+		"dsn":         "fake",
+		"transaction": true,
+		"steps":       []map[string]any{{"query": "DELETE FROM users"}},
+	}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	log := fake.entries()
+	if len(log) != 3 || log[0] != "BEGIN" || log[2] != "COMMIT" {
+		t.Fatalf("unexpected statement log: %v", log)
+	}
+}
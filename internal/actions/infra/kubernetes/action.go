@@ -7,37 +7,79 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
 	"flowk/internal/actions/registry"
+	"flowk/internal/config"
+	"flowk/internal/shared/tracing"
 )
 
 type taskConfig struct {
-	Context             string   `json:"context"`
-	Namespace           string   `json:"namespace"`
-	Operation           string   `json:"operation"`
-	Deployments         []string `json:"deployments,omitempty"`
-	Replicas            *int32   `json:"replicas,omitempty"`
-	Kubeconfig          string   `json:"kubeconfig,omitempty"`
-	Pods                []string `json:"pod,omitempty"`
-	Container           string   `json:"container,omitempty"`
-	SinceTime           string   `json:"since_time,omitempty"`
-	SincePodStart       bool     `json:"since_pod_start,omitempty"`
-	Service             string   `json:"service,omitempty"`
-	LocalPort           int32    `json:"local_port,omitempty"`
-	ServicePort         int32    `json:"service_port,omitempty"`
-	MaxWaitSeconds      float64  `json:"max_wait_seconds,omitempty"`
-	PollIntervalSeconds float64  `json:"poll_interval_seconds,omitempty"`
+	Context             string            `json:"context"`
+	Namespace           string            `json:"namespace"`
+	Operation           string            `json:"operation"`
+	Deployments         []string          `json:"deployments,omitempty"`
+	Replicas            *int32            `json:"replicas,omitempty"`
+	Kubeconfig          string            `json:"kubeconfig,omitempty"`
+	InCluster           bool              `json:"in_cluster,omitempty"`
+	Pods                []string          `json:"pod,omitempty"`
+	Container           string            `json:"container,omitempty"`
+	SinceTime           string            `json:"since_time,omitempty"`
+	SincePodStart       bool              `json:"since_pod_start,omitempty"`
+	SinceDuration       string            `json:"since_duration,omitempty"`
+	TailLines           *int64            `json:"tail_lines,omitempty"`
+	Follow              bool              `json:"follow,omitempty"`
+	Previous            bool              `json:"previous,omitempty"`
+	Service             string            `json:"service,omitempty"`
+	LocalPort           int32             `json:"local_port,omitempty"`
+	ServicePort         int32             `json:"service_port,omitempty"`
+	PodPort             int32             `json:"pod_port,omitempty"`
+	ForwardID           string            `json:"forward_id,omitempty"`
+	MaxWaitSeconds      float64           `json:"max_wait_seconds,omitempty"`
+	PollIntervalSeconds float64           `json:"poll_interval_seconds,omitempty"`
+	Manifest            string            `json:"manifest,omitempty"`
+	ManifestPath        string            `json:"manifest_path,omitempty"`
+	Kind                string            `json:"kind,omitempty"`
+	Names               []string          `json:"names,omitempty"`
+	LabelSelector       string            `json:"label_selector,omitempty"`
+	FieldSelector       string            `json:"field_selector,omitempty"`
+	Status              []string          `json:"status,omitempty"`
+	GracePeriodSeconds  *int64            `json:"grace_period_seconds,omitempty"`
+	DryRun              bool              `json:"dry_run,omitempty"`
+	Command             []string          `json:"command,omitempty"`
+	Stdin               string            `json:"stdin,omitempty"`
+	TTY                 bool              `json:"tty,omitempty"`
+	CaptureExitCode     bool              `json:"capture_exit_code,omitempty"`
+	Wait                bool              `json:"wait,omitempty"`
+	CollectLogs         bool              `json:"collect_logs,omitempty"`
+	Reveal              bool              `json:"reveal,omitempty"`
+	Variable            string            `json:"variable,omitempty"`
+	Image               string            `json:"image,omitempty"`
+	Images              map[string]string `json:"images,omitempty"`
 }
 
 func (c taskConfig) Validate() error {
 	op := strings.ToUpper(strings.TrimSpace(c.Operation))
-	if strings.TrimSpace(c.Context) == "" && op != OperationStopPortForward {
-		return fmt.Errorf("kubernetes task: context is required")
+	if strings.TrimSpace(c.Context) == "" && op != OperationStopPortForward && !c.InCluster {
+		return fmt.Errorf("kubernetes task: context is required (set it on the task or as defaults.kubernetes.context in config.yaml)")
 	}
 
 	deployments := normalizeStringList(c.Deployments)
 	pods := normalizeStringList(c.Pods)
 	switch op {
 	case OperationGetPods:
+		if trimmed := strings.TrimSpace(c.LabelSelector); trimmed != "" {
+			if _, err := labels.Parse(trimmed); err != nil {
+				return fmt.Errorf("kubernetes task: invalid label_selector: %w", err)
+			}
+		}
+		if trimmed := strings.TrimSpace(c.FieldSelector); trimmed != "" {
+			if _, err := fields.ParseSelector(trimmed); err != nil {
+				return fmt.Errorf("kubernetes task: invalid field_selector: %w", err)
+			}
+		}
 		return nil
 	case OperationGetDeployments:
 		return nil
@@ -53,11 +95,29 @@ func (c taskConfig) Validate() error {
 				return fmt.Errorf("kubernetes task: since_time must be in RFC3339 format: %w", err)
 			}
 		}
+		if trimmed := strings.TrimSpace(c.SinceDuration); trimmed != "" {
+			if strings.TrimSpace(c.SinceTime) != "" {
+				return fmt.Errorf("kubernetes task: specify either since_time or since_duration for GET_LOGS operations, not both")
+			}
+			duration, err := time.ParseDuration(trimmed)
+			if err != nil {
+				return fmt.Errorf("kubernetes task: since_duration must be a duration such as 15m: %w", err)
+			}
+			if duration <= 0 {
+				return fmt.Errorf("kubernetes task: since_duration must be greater than zero")
+			}
+		}
+		if c.TailLines != nil && *c.TailLines < 0 {
+			return fmt.Errorf("kubernetes task: tail_lines must be greater than or equal to zero")
+		}
 		return nil
 	case OperationScale:
 		if strings.TrimSpace(c.Namespace) == "" {
 			return fmt.Errorf("kubernetes task: namespace is required for SCALE operations")
 		}
+		if !containsKind(scalableKinds, workloadKind(c.Kind)) {
+			return fmt.Errorf("kubernetes task: kind must be one of %s for SCALE operations", strings.Join(scalableKinds, ", "))
+		}
 		if len(deployments) == 0 {
 			return fmt.Errorf("kubernetes task: at least one deployment is required for SCALE operations")
 		}
@@ -72,6 +132,9 @@ func (c taskConfig) Validate() error {
 		if strings.TrimSpace(c.Namespace) == "" {
 			return fmt.Errorf("kubernetes task: namespace is required for WAIT_FOR_POD_READINESS operations")
 		}
+		if !containsKind(waitableKinds, workloadKind(c.Kind)) {
+			return fmt.Errorf("kubernetes task: kind must be one of %s for WAIT_FOR_POD_READINESS operations", strings.Join(waitableKinds, ", "))
+		}
 		if len(deployments) == 0 {
 			return fmt.Errorf("kubernetes task: at least one deployment is required for WAIT_FOR_POD_READINESS operations")
 		}
@@ -83,19 +146,155 @@ func (c taskConfig) Validate() error {
 		}
 		return nil
 	case OperationPortForward:
-		if strings.TrimSpace(c.Service) == "" {
-			return fmt.Errorf("kubernetes task: service is required for PORT_FORWARD operations")
+		hasService := strings.TrimSpace(c.Service) != ""
+		if hasService == (len(pods) > 0) {
+			return fmt.Errorf("kubernetes task: specify exactly one of service or pod for PORT_FORWARD operations")
+		}
+		if len(pods) > 1 {
+			return fmt.Errorf("kubernetes task: specify a single pod for PORT_FORWARD operations")
 		}
 		if c.LocalPort <= 0 || c.LocalPort > 65535 {
 			return fmt.Errorf("kubernetes task: local_port must be between 1 and 65535 for PORT_FORWARD operations")
 		}
-		if c.ServicePort <= 0 || c.ServicePort > 65535 {
+		if hasService && (c.ServicePort <= 0 || c.ServicePort > 65535) {
 			return fmt.Errorf("kubernetes task: service_port must be between 1 and 65535 for PORT_FORWARD operations")
 		}
+		if c.PodPort < 0 || c.PodPort > 65535 {
+			return fmt.Errorf("kubernetes task: pod_port must be between 1 and 65535 for PORT_FORWARD operations")
+		}
 		return nil
 	case OperationStopPortForward:
+		if strings.TrimSpace(c.ForwardID) != "" {
+			return nil
+		}
 		if c.LocalPort <= 0 || c.LocalPort > 65535 {
-			return fmt.Errorf("kubernetes task: local_port must be between 1 and 65535 for STOP_PORT_FORWARD operations")
+			return fmt.Errorf("kubernetes task: local_port or forward_id is required for STOP_PORT_FORWARD operations (local_port must be between 1 and 65535)")
+		}
+		return nil
+	case OperationApply:
+		hasManifest := strings.TrimSpace(c.Manifest) != ""
+		hasPath := strings.TrimSpace(c.ManifestPath) != ""
+		if hasManifest == hasPath {
+			return fmt.Errorf("kubernetes task: specify exactly one of manifest or manifest_path for APPLY operations")
+		}
+		if hasManifest {
+			if _, err := parseManifest([]byte(c.Manifest)); err != nil {
+				return fmt.Errorf("kubernetes task: invalid manifest for APPLY operations: %w", err)
+			}
+		}
+		return nil
+	case OperationDelete:
+		if strings.TrimSpace(c.Namespace) == "" {
+			return fmt.Errorf("kubernetes task: namespace is required for DELETE operations")
+		}
+		if !isDeletableKind(normalizeKind(c.Kind)) {
+			return fmt.Errorf("kubernetes task: kind must be one of %s for DELETE operations", strings.Join(deletableKinds, ", "))
+		}
+		hasNames := len(normalizeStringList(c.Names)) > 0
+		hasSelector := strings.TrimSpace(c.LabelSelector) != ""
+		if hasNames == hasSelector {
+			return fmt.Errorf("kubernetes task: specify exactly one of names or label_selector for DELETE operations")
+		}
+		if hasSelector {
+			if _, err := labels.Parse(c.LabelSelector); err != nil {
+				return fmt.Errorf("kubernetes task: invalid label_selector: %w", err)
+			}
+		}
+		if c.GracePeriodSeconds != nil && *c.GracePeriodSeconds < 0 {
+			return fmt.Errorf("kubernetes task: grace_period_seconds must be greater than or equal to zero")
+		}
+		return nil
+	case OperationExec:
+		if len(c.Command) == 0 {
+			return fmt.Errorf("kubernetes task: command is required for EXEC operations")
+		}
+		if len(pods)+len(deployments) != 1 {
+			return fmt.Errorf("kubernetes task: specify exactly one pod or deployment for EXEC operations")
+		}
+		return nil
+	case OperationRolloutRestart:
+		if strings.TrimSpace(c.Namespace) == "" {
+			return fmt.Errorf("kubernetes task: namespace is required for ROLLOUT_RESTART operations")
+		}
+		if !containsKind(restartableKinds, workloadKind(c.Kind)) {
+			return fmt.Errorf("kubernetes task: kind must be one of %s for ROLLOUT_RESTART operations", strings.Join(restartableKinds, ", "))
+		}
+		if len(normalizeStringList(c.Names)) == 0 {
+			return fmt.Errorf("kubernetes task: at least one name is required for ROLLOUT_RESTART operations")
+		}
+		if c.Wait {
+			if c.MaxWaitSeconds <= 0 {
+				return fmt.Errorf("kubernetes task: max_wait_seconds must be greater than zero when ROLLOUT_RESTART waits")
+			}
+			if c.PollIntervalSeconds <= 0 {
+				return fmt.Errorf("kubernetes task: poll_interval_seconds must be greater than zero when ROLLOUT_RESTART waits")
+			}
+		}
+		return nil
+	case OperationWaitForJob:
+		if strings.TrimSpace(c.Namespace) == "" {
+			return fmt.Errorf("kubernetes task: namespace is required for WAIT_FOR_JOB operations")
+		}
+		if len(normalizeStringList(c.Names)) != 1 {
+			return fmt.Errorf("kubernetes task: exactly one job name is required for WAIT_FOR_JOB operations")
+		}
+		if c.MaxWaitSeconds <= 0 {
+			return fmt.Errorf("kubernetes task: max_wait_seconds must be greater than zero for WAIT_FOR_JOB operations")
+		}
+		if c.PollIntervalSeconds <= 0 {
+			return fmt.Errorf("kubernetes task: poll_interval_seconds must be greater than zero for WAIT_FOR_JOB operations")
+		}
+		return nil
+	case OperationGetEvents:
+		if trimmed := strings.TrimSpace(c.LabelSelector); trimmed != "" {
+			if _, err := labels.Parse(trimmed); err != nil {
+				return fmt.Errorf("kubernetes task: invalid label_selector: %w", err)
+			}
+		}
+		return nil
+	case OperationGetConfigMap, OperationGetSecret:
+		if strings.TrimSpace(c.Namespace) == "" {
+			return fmt.Errorf("kubernetes task: namespace is required for %s operations", op)
+		}
+		if len(normalizeStringList(c.Names)) != 1 {
+			return fmt.Errorf("kubernetes task: exactly one name is required for %s operations", op)
+		}
+		hasVariable := strings.TrimSpace(c.Variable) != ""
+		if op == OperationGetConfigMap && (c.Reveal || hasVariable) {
+			return fmt.Errorf("kubernetes task: reveal and variable are only supported for GET_SECRET operations")
+		}
+		if c.Reveal != hasVariable {
+			// Revealed values are masked in the run output through the secret
+			// variable, so one is never set without the other.
+			return fmt.Errorf("kubernetes task: reveal requires variable for GET_SECRET operations, and variable requires reveal")
+		}
+		return nil
+	case OperationSetImage:
+		if strings.TrimSpace(c.Namespace) == "" {
+			return fmt.Errorf("kubernetes task: namespace is required for SET_IMAGE operations")
+		}
+		if len(deployments) != 1 {
+			return fmt.Errorf("kubernetes task: exactly one deployment is required for SET_IMAGE operations")
+		}
+		hasImage := strings.TrimSpace(c.Image) != ""
+		if hasImage == (len(c.Images) > 0) {
+			return fmt.Errorf("kubernetes task: specify either container with image, or images, for SET_IMAGE operations")
+		}
+		if hasImage && strings.TrimSpace(c.Container) == "" {
+			return fmt.Errorf("kubernetes task: container is required with image for SET_IMAGE operations")
+		}
+		for container, image := range c.Images {
+			if strings.TrimSpace(container) == "" || strings.TrimSpace(image) == "" {
+				return fmt.Errorf("kubernetes task: images entries need a container name and an image for SET_IMAGE operations")
+			}
+		}
+		if c.Wait {
+			if c.MaxWaitSeconds <= 0 {
+				return fmt.Errorf("kubernetes task: max_wait_seconds must be greater than zero when SET_IMAGE waits")
+			}
+			if c.PollIntervalSeconds <= 0 {
+				return fmt.Errorf("kubernetes task: poll_interval_seconds must be greater than zero when SET_IMAGE waits")
+			}
 		}
 		return nil
 	default:
@@ -111,6 +310,7 @@ func decodeTask(data json.RawMessage) (Config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, fmt.Errorf("decoding kubernetes task payload: %w", err)
 	}
+	applyConfigDefaults(&cfg, config.ActionDefaults().Kubernetes)
 	if err := cfg.Validate(); err != nil {
 		return Config{}, err
 	}
@@ -124,28 +324,87 @@ func decodeTask(data json.RawMessage) (Config, error) {
 		sinceTime = &parsed
 	}
 
+	var sinceDuration time.Duration
+	if trimmed := strings.TrimSpace(cfg.SinceDuration); trimmed != "" {
+		parsed, err := time.ParseDuration(trimmed)
+		if err != nil {
+			return Config{}, fmt.Errorf("kubernetes task: parsing since_duration: %w", err)
+		}
+		sinceDuration = parsed
+	}
+
 	deployments := normalizeStringList(cfg.Deployments)
 	pods := normalizeStringList(cfg.Pods)
 
+	var images map[string]string
+	if image := strings.TrimSpace(cfg.Image); image != "" {
+		images = map[string]string{strings.TrimSpace(cfg.Container): image}
+	}
+	for container, image := range cfg.Images {
+		if images == nil {
+			images = make(map[string]string, len(cfg.Images))
+		}
+		images[strings.TrimSpace(container)] = strings.TrimSpace(image)
+	}
+
 	return Config{
-		Context:       strings.TrimSpace(cfg.Context),
-		Namespace:     strings.TrimSpace(cfg.Namespace),
-		Operation:     strings.TrimSpace(cfg.Operation),
-		Deployments:   deployments,
-		Replicas:      cfg.Replicas,
-		Kubeconfig:    strings.TrimSpace(cfg.Kubeconfig),
-		Pods:          pods,
-		Container:     strings.TrimSpace(cfg.Container),
-		SinceTime:     sinceTime,
-		SincePodStart: cfg.SincePodStart,
-		Service:       strings.TrimSpace(cfg.Service),
-		LocalPort:     cfg.LocalPort,
-		ServicePort:   cfg.ServicePort,
-		MaxWait:       time.Duration(cfg.MaxWaitSeconds * float64(time.Second)),
-		PollInterval:  time.Duration(cfg.PollIntervalSeconds * float64(time.Second)),
+		Context:            strings.TrimSpace(cfg.Context),
+		Namespace:          strings.TrimSpace(cfg.Namespace),
+		Operation:          strings.TrimSpace(cfg.Operation),
+		Deployments:        deployments,
+		Replicas:           cfg.Replicas,
+		Kubeconfig:         strings.TrimSpace(cfg.Kubeconfig),
+		InCluster:          cfg.InCluster,
+		Pods:               pods,
+		Container:          strings.TrimSpace(cfg.Container),
+		SinceTime:          sinceTime,
+		SincePodStart:      cfg.SincePodStart,
+		SinceDuration:      sinceDuration,
+		TailLines:          cfg.TailLines,
+		Follow:             cfg.Follow,
+		Previous:           cfg.Previous,
+		Service:            strings.TrimSpace(cfg.Service),
+		LocalPort:          cfg.LocalPort,
+		ServicePort:        cfg.ServicePort,
+		PodPort:            cfg.PodPort,
+		ForwardID:          strings.TrimSpace(cfg.ForwardID),
+		MaxWait:            time.Duration(cfg.MaxWaitSeconds * float64(time.Second)),
+		PollInterval:       time.Duration(cfg.PollIntervalSeconds * float64(time.Second)),
+		Manifest:           cfg.Manifest,
+		ManifestPath:       strings.TrimSpace(cfg.ManifestPath),
+		Kind:               normalizeKind(cfg.Kind),
+		Names:              normalizeStringList(cfg.Names),
+		LabelSelector:      strings.TrimSpace(cfg.LabelSelector),
+		FieldSelector:      strings.TrimSpace(cfg.FieldSelector),
+		Statuses:           normalizeStringList(cfg.Status),
+		GracePeriodSeconds: cfg.GracePeriodSeconds,
+		DryRun:             cfg.DryRun,
+		Command:            cfg.Command,
+		Stdin:              cfg.Stdin,
+		TTY:                cfg.TTY,
+		CaptureExitCode:    cfg.CaptureExitCode,
+		Wait:               cfg.Wait,
+		CollectLogs:        cfg.CollectLogs,
+		Reveal:             cfg.Reveal,
+		Variable:           strings.TrimSpace(cfg.Variable),
+		Images:             images,
 	}, nil
 }
 
+// applyConfigDefaults fills context and kubeconfig from config.yaml when the
+// task omits them. In-cluster tasks keep using the service account.
+func applyConfigDefaults(cfg *taskConfig, defaults config.KubernetesDefaultsConfig) {
+	if cfg.InCluster {
+		return
+	}
+	if strings.TrimSpace(cfg.Context) == "" {
+		cfg.Context = defaults.Context
+	}
+	if strings.TrimSpace(cfg.Kubeconfig) == "" {
+		cfg.Kubeconfig = defaults.Kubeconfig
+	}
+}
+
 type action struct{}
 
 func init() {
@@ -164,9 +423,30 @@ func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 
 	cfg.LogDir = execCtx.LogDir
 
+	operation := strings.ToUpper(strings.TrimSpace(cfg.Operation))
+	ctx, span := tracing.Start(ctx, "kubernetes "+operation,
+		attribute.String("flowk.kubernetes.operation", operation),
+		attribute.String("flowk.kubernetes.context", cfg.Context),
+		attribute.String("flowk.kubernetes.namespace", cfg.Namespace),
+	)
 	value, resultType, err := Execute(ctx, cfg, execCtx.Logger)
+	tracing.End(span, err)
 	if err != nil {
-		return registry.Result{}, err
+		if resultType == "" {
+			return registry.Result{}, err
+		}
+		return registry.Result{Value: value, Type: resultType}, err
+	}
+	if secret, ok := value.(SecretDetails); ok && secret.Revealed {
+		// Storing the values as a secret variable masks them in logs and
+		// task artifacts for the rest of the run.
+		if execCtx.Variables == nil {
+			execCtx.Variables = make(map[string]registry.Variable)
+		}
+		execCtx.Variables[cfg.Variable] = registry.Variable{Name: cfg.Variable, Type: "object", Value: secretVariableValue(secret), Secret: true}
+		if execCtx.Logger != nil {
+			execCtx.Logger.Printf("Kubernetes: stored secret %s in variable %s", secret.Name, cfg.Variable)
+		}
 	}
 	return registry.Result{Value: value, Type: resultType}, nil
 }
@@ -0,0 +1,195 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// applyFieldManager identifies flowk as the owner of server-side applied fields.
+const applyFieldManager = "flowk"
+
+// ApplyResult reports the outcome of applying a single manifest object.
+type ApplyResult struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Action     string `json:"action"`
+}
+
+// loadManifest returns the objects declared by the inline manifest or the
+// manifest file.
+func loadManifest(cfg Config) ([]*unstructured.Unstructured, error) {
+	data := []byte(cfg.Manifest)
+	if strings.TrimSpace(cfg.Manifest) == "" {
+		content, err := os.ReadFile(cfg.ManifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes: reading manifest %s: %w", cfg.ManifestPath, err)
+		}
+		data = content
+	}
+	return parseManifest(data)
+}
+
+// parseManifest decodes every YAML or JSON document in data, skipping empty
+// documents and expanding List kinds.
+func parseManifest(data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	var objects []*unstructured.Unstructured
+	for index := 0; ; index++ {
+		var content map[string]any
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("kubernetes: parsing manifest document %d: %w", index, err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("kubernetes: parsing manifest document %d: %w", index, err)
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+
+	for i, obj := range objects {
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("kubernetes: manifest object %d must define apiVersion and kind", i)
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("kubernetes: manifest object %d (%s) must define metadata.name", i, obj.GetKind())
+		}
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("kubernetes: manifest does not contain any objects")
+	}
+	return objects, nil
+}
+
+func buildDynamicClient(client kubernetes.Interface, restCfg *rest.Config) (dynamic.Interface, meta.RESTMapper, error) {
+	dyn, err := dynamic.NewForConfig(restCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("kubernetes: creating dynamic client: %w", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client.Discovery()))
+	return dyn, mapper, nil
+}
+
+// applyManifest server-side applies every object. Namespaced objects without
+// a namespace use the resolved one; an object whose namespace differs from
+// the namespace configured on the task is rejected.
+func applyManifest(ctx context.Context, dyn dynamic.Interface, mapper meta.RESTMapper, namespace, configuredNamespace string, objects []*unstructured.Unstructured, logger Logger) ([]ApplyResult, error) {
+	// Resolve every object before applying any of them so that a bad
+	// document later in the manifest does not leave a partial apply behind.
+	targets := make([]applyTarget, 0, len(objects))
+	for _, obj := range objects {
+		target, err := resolveApplyTarget(dyn, mapper, namespace, configuredNamespace, obj)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	results := make([]ApplyResult, 0, len(targets))
+	for _, target := range targets {
+		obj, resource, objNamespace := target.object, target.resource, target.namespace
+		gvk := obj.GroupVersionKind()
+
+		found := false
+		previousVersion := ""
+		existing, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		switch {
+		case err == nil:
+			found = true
+			previousVersion = existing.GetResourceVersion()
+		case !apierrors.IsNotFound(err):
+			return nil, fmt.Errorf("kubernetes: retrieving %s %s: %w", gvk.Kind, obj.GetName(), err)
+		}
+
+		applied, err := resource.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: applyFieldManager, Force: true})
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes: applying %s %s: %w", gvk.Kind, obj.GetName(), err)
+		}
+
+		action := "created"
+		if found {
+			action = "configured"
+			if applied.GetResourceVersion() == previousVersion {
+				action = "unchanged"
+			}
+		}
+		if logger != nil {
+			logger.Printf("Kubernetes: %s %s %s", strings.ToLower(gvk.Kind), obj.GetName(), action)
+		}
+
+		results = append(results, ApplyResult{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       gvk.Kind,
+			Name:       obj.GetName(),
+			Namespace:  objNamespace,
+			Action:     action,
+		})
+	}
+	return results, nil
+}
+
+// applyTarget is a manifest object paired with the resource client and
+// namespace it will be applied to.
+type applyTarget struct {
+	object    *unstructured.Unstructured
+	resource  dynamic.ResourceInterface
+	namespace string
+}
+
+func resolveApplyTarget(dyn dynamic.Interface, mapper meta.RESTMapper, namespace, configuredNamespace string, obj *unstructured.Unstructured) (applyTarget, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return applyTarget{}, fmt.Errorf("kubernetes: resolving resource for %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return applyTarget{object: obj, resource: dyn.Resource(mapping.Resource)}, nil
+	}
+
+	objNamespace := obj.GetNamespace()
+	switch {
+	case objNamespace == "":
+		objNamespace = namespace
+	case configuredNamespace != "" && objNamespace != configuredNamespace:
+		return applyTarget{}, fmt.Errorf("kubernetes APPLY operation: %s %s declares namespace %s but the task targets namespace %s", gvk.Kind, obj.GetName(), objNamespace, configuredNamespace)
+	}
+	obj.SetNamespace(objNamespace)
+	return applyTarget{
+		object:    obj,
+		resource:  dyn.Resource(mapping.Resource).Namespace(objNamespace),
+		namespace: objNamespace,
+	}, nil
+}
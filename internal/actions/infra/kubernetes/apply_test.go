@@ -0,0 +1,226 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+---
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: second
+  - apiVersion: v1
+    kind: Namespace
+    metadata:
+      name: team
+`
+	objects, err := parseManifest([]byte(manifest))
+	if err != nil {
+		t.Fatalf("parseManifest() error = %v", err)
+	}
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	if want := "ConfigMap/first,ConfigMap/second,Namespace/team"; strings.Join(names, ",") != want {
+		t.Fatalf("objects = %v, want %s", names, want)
+	}
+
+	for name, invalid := range map[string]string{
+		"syntax":  "kind: [",
+		"no name": "apiVersion: v1\nkind: ConfigMap\n",
+		"no kind": `{"apiVersion": "v1", "metadata": {"name": "x"}}`,
+		"empty":   "---\n",
+	} {
+		if _, err := parseManifest([]byte(invalid)); err == nil {
+			t.Fatalf("parseManifest(%s) error = nil, want error", name)
+		}
+	}
+}
+
+func TestTaskConfigValidateApply(t *testing.T) {
+	valid := taskConfig{Context: "example", Operation: OperationApply, Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: demo\n"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	both := valid
+	both.ManifestPath = "manifest.yaml"
+	if err := both.Validate(); err == nil || !strings.Contains(err.Error(), "exactly one of manifest or manifest_path") {
+		t.Fatalf("Validate() error = %v, want manifest/manifest_path error", err)
+	}
+
+	broken := taskConfig{Context: "example", Operation: OperationApply, Manifest: "kind: ["}
+	if err := broken.Validate(); err == nil || !strings.Contains(err.Error(), "invalid manifest") {
+		t.Fatalf("Validate() error = %v, want invalid manifest error", err)
+	}
+}
+
+func TestApplyManifest(t *testing.T) {
+	existing := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "settings", "namespace": "apps", "resourceVersion": "5"},
+		"data":       map[string]any{"mode": "blue"},
+	}}
+	dyn, mapper := newApplyTestClient(existing)
+
+	objects, err := parseManifest([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: blue
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: extra
+  namespace: apps
+data:
+  mode: green
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: team
+`))
+	if err != nil {
+		t.Fatalf("parseManifest() error = %v", err)
+	}
+
+	results, err := applyManifest(context.Background(), dyn, mapper, "apps", "apps", objects, nil)
+	if err != nil {
+		t.Fatalf("applyManifest() error = %v", err)
+	}
+	want := []ApplyResult{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "settings", Namespace: "apps", Action: "unchanged"},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "extra", Namespace: "apps", Action: "created"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "team", Action: "created"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Fatalf("results = %+v, want %+v", results, want)
+	}
+
+	changed, _ := parseManifest([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  mode: red\n"))
+	results, err = applyManifest(context.Background(), dyn, mapper, "apps", "", changed, nil)
+	if err != nil {
+		t.Fatalf("applyManifest() error = %v", err)
+	}
+	if results[0].Action != "configured" {
+		t.Fatalf("Action = %q, want configured", results[0].Action)
+	}
+}
+
+func TestApplyManifestRejectsNamespaceMismatch(t *testing.T) {
+	dyn, mapper := newApplyTestClient()
+	objects, _ := parseManifest([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: other\n"))
+
+	_, err := applyManifest(context.Background(), dyn, mapper, "apps", "apps", objects, nil)
+	if err == nil || !strings.Contains(err.Error(), "declares namespace other but the task targets namespace apps") {
+		t.Fatalf("applyManifest() error = %v, want namespace mismatch", err)
+	}
+
+	results, err := applyManifest(context.Background(), dyn, mapper, "default", "", objects, nil)
+	if err != nil {
+		t.Fatalf("applyManifest() without configured namespace error = %v", err)
+	}
+	if results[0].Namespace != "other" {
+		t.Fatalf("Namespace = %q, want the manifest namespace", results[0].Namespace)
+	}
+}
+
+func TestApplyManifestResolvesAllObjectsBeforeApplying(t *testing.T) {
+	dyn, mapper := newApplyTestClient()
+	objects, _ := parseManifest([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: unknown
+`))
+
+	if _, err := applyManifest(context.Background(), dyn, mapper, "apps", "", objects, nil); err == nil || !strings.Contains(err.Error(), "resolving resource for Widget unknown") {
+		t.Fatalf("applyManifest() error = %v, want unknown kind", err)
+	}
+	for _, action := range dyn.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Fatalf("expected nothing to be applied, got %v", action)
+		}
+	}
+}
+
+// newApplyTestClient returns a fake dynamic client that emulates server-side
+// apply by replacing the stored object and bumping its resourceVersion when
+// the content changes.
+func newApplyTestClient(objects ...runtime.Object) (*dynamicfake.FakeDynamicClient, meta.RESTMapper) {
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMaps: "ConfigMapList",
+		namespaces: "NamespaceList",
+	}, objects...)
+
+	dyn.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		applied := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &applied.Object); err != nil {
+			return true, nil, err
+		}
+
+		tracker := dyn.Tracker()
+		current, err := tracker.Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if apierrors.IsNotFound(err) {
+			applied.SetResourceVersion("1")
+			return true, applied, tracker.Create(patch.GetResource(), applied, patch.GetNamespace())
+		}
+		if err != nil {
+			return true, nil, err
+		}
+
+		stored := current.(*unstructured.Unstructured)
+		if reflect.DeepEqual(stored.Object["data"], applied.Object["data"]) {
+			return true, stored, nil
+		}
+		version, _ := strconv.Atoi(stored.GetResourceVersion())
+		applied.SetResourceVersion(strconv.Itoa(version + 1))
+		return true, applied, tracker.Update(patch.GetResource(), applied, patch.GetNamespace())
+	})
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	return dyn, mapper
+}
@@ -0,0 +1,77 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConfigMapDetails reports the data of a ConfigMap read by GET_CONFIGMAP.
+type ConfigMapDetails struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Data      map[string]string `json:"data"`
+}
+
+// SecretDetails reports the keys of a Secret read by GET_SECRET. Data values
+// are masked unless the task sets reveal.
+type SecretDetails struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Data      map[string]string `json:"data"`
+	Revealed  bool              `json:"revealed"`
+}
+
+// secretMask replaces Secret values that are not revealed.
+const secretMask = "***"
+
+func getConfigMap(ctx context.Context, client kubernetes.Interface, namespace, name string) (ConfigMapDetails, error) {
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return ConfigMapDetails{}, fmt.Errorf("kubernetes: getting configmap %s in namespace %s: %w", name, namespace, err)
+	}
+
+	data := make(map[string]string, len(configMap.Data))
+	for key, value := range configMap.Data {
+		data[key] = value
+	}
+	return ConfigMapDetails{Namespace: configMap.Namespace, Name: configMap.Name, Data: data}, nil
+}
+
+// getSecret reads a Secret. The API already returns the data base64-decoded;
+// each value is returned as text when reveal is set and masked otherwise.
+func getSecret(ctx context.Context, client kubernetes.Interface, namespace, name string, reveal bool) (SecretDetails, error) {
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return SecretDetails{}, fmt.Errorf("kubernetes: getting secret %s in namespace %s: %w", name, namespace, err)
+	}
+
+	data := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		if reveal {
+			data[key] = string(value)
+		} else {
+			data[key] = secretMask
+		}
+	}
+	return SecretDetails{
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+		Type:      string(secret.Type),
+		Data:      data,
+		Revealed:  reveal,
+	}, nil
+}
+
+// secretVariableValue converts revealed Secret data to the object stored in
+// the task's secret variable.
+func secretVariableValue(details SecretDetails) map[string]any {
+	value := make(map[string]any, len(details.Data))
+	for key, data := range details.Data {
+		value[key] = data
+	}
+	return value
+}
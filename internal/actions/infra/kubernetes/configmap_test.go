@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTaskConfigValidateGetConfigMapAndSecret(t *testing.T) {
+	valid := []taskConfig{
+		{Context: "example", Namespace: "apps", Operation: OperationGetConfigMap, Names: []string{"settings"}},
+		{Context: "example", Namespace: "apps", Operation: OperationGetSecret, Names: []string{"db"}},
+		{Context: "example", Namespace: "apps", Operation: OperationGetSecret, Names: []string{"db"}, Reveal: true, Variable: "db"},
+	}
+	for _, cfg := range valid {
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("Validate(%+v) error = %v", cfg, err)
+		}
+	}
+
+	cases := map[string]struct {
+		cfg  taskConfig
+		want string
+	}{
+		"missing namespace":       {taskConfig{Context: "example", Operation: OperationGetSecret, Names: []string{"db"}}, "namespace is required"},
+		"no names":                {taskConfig{Context: "example", Namespace: "apps", Operation: OperationGetConfigMap}, "exactly one name"},
+		"several names":           {taskConfig{Context: "example", Namespace: "apps", Operation: OperationGetSecret, Names: []string{"a", "b"}}, "exactly one name"},
+		"reveal on configmap":     {taskConfig{Context: "example", Namespace: "apps", Operation: OperationGetConfigMap, Names: []string{"settings"}, Reveal: true}, "only supported for GET_SECRET"},
+		"reveal without var":      {taskConfig{Context: "example", Namespace: "apps", Operation: OperationGetSecret, Names: []string{"db"}, Reveal: true}, "reveal requires variable"},
+		"variable without reveal": {taskConfig{Context: "example", Namespace: "apps", Operation: OperationGetSecret, Names: []string{"db"}, Variable: "db"}, "variable requires reveal"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.cfg.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetConfigMap(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "apps"},
+		Data:       map[string]string{"log_level": "debug", "region": "eu-west-1"},
+	})
+
+	result, err := getConfigMap(context.Background(), client, "apps", "settings")
+	if err != nil {
+		t.Fatalf("getConfigMap() error = %v", err)
+	}
+	if result.Name != "settings" || result.Namespace != "apps" || len(result.Data) != 2 || result.Data["log_level"] != "debug" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if _, err := getConfigMap(context.Background(), client, "apps", "missing"); err == nil || !strings.Contains(err.Error(), "getting configmap missing") {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestGetSecretMasksUnlessRevealed(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"url": []byte("postgres://app:s3cret@db:5432/app"), "user": []byte("app")},
+	})
+
+	masked, err := getSecret(context.Background(), client, "apps", "db", false)
+	if err != nil {
+		t.Fatalf("getSecret() error = %v", err)
+	}
+	if masked.Revealed || masked.Type != "Opaque" || len(masked.Data) != 2 || masked.Data["url"] != "***" || masked.Data["user"] != "***" {
+		t.Fatalf("expected masked values, got %+v", masked)
+	}
+
+	revealed, err := getSecret(context.Background(), client, "apps", "db", true)
+	if err != nil {
+		t.Fatalf("getSecret() error = %v", err)
+	}
+	if !revealed.Revealed || revealed.Data["url"] != "postgres://app:s3cret@db:5432/app" {
+		t.Fatalf("expected revealed values, got %+v", revealed)
+	}
+	if value := secretVariableValue(revealed); value["user"] != "app" || len(value) != 2 {
+		t.Fatalf("unexpected variable value: %v", value)
+	}
+}
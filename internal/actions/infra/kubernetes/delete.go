@@ -0,0 +1,135 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DeleteResult reports a single object removed by a DELETE operation.
+type DeleteResult struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	DryRun    bool   `json:"dryRun"`
+}
+
+// deletableKinds lists the kinds accepted by DELETE operations.
+var deletableKinds = []string{"configmap", "deployment", "job", "pod", "service"}
+
+// namespacedResource lists and deletes objects of one kind in a namespace.
+type namespacedResource struct {
+	list   func(ctx context.Context, opts metav1.ListOptions) ([]string, error)
+	delete func(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+func normalizeKind(kind string) string {
+	return strings.ToLower(strings.TrimSpace(kind))
+}
+
+func isDeletableKind(kind string) bool {
+	for _, candidate := range deletableKinds {
+		if candidate == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func resourceForKind(client kubernetes.Interface, namespace, kind string) (namespacedResource, error) {
+	switch kind {
+	case "deployment":
+		api := client.AppsV1().Deployments(namespace)
+		return namespacedResource{list: listNames(api.List), delete: api.Delete}, nil
+	case "pod":
+		api := client.CoreV1().Pods(namespace)
+		return namespacedResource{list: listNames(api.List), delete: api.Delete}, nil
+	case "service":
+		api := client.CoreV1().Services(namespace)
+		return namespacedResource{list: listNames(api.List), delete: api.Delete}, nil
+	case "configmap":
+		api := client.CoreV1().ConfigMaps(namespace)
+		return namespacedResource{list: listNames(api.List), delete: api.Delete}, nil
+	case "job":
+		api := client.BatchV1().Jobs(namespace)
+		return namespacedResource{list: listNames(api.List), delete: api.Delete}, nil
+	default:
+		return namespacedResource{}, fmt.Errorf("kubernetes DELETE operation: unsupported kind %q (expected one of %s)", kind, strings.Join(deletableKinds, ", "))
+	}
+}
+
+// listNames adapts a typed List call into one returning the object names.
+func listNames[L runtime.Object](list func(context.Context, metav1.ListOptions) (L, error)) func(context.Context, metav1.ListOptions) ([]string, error) {
+	return func(ctx context.Context, opts metav1.ListOptions) ([]string, error) {
+		items, err := list(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		objects, err := meta.ExtractList(items)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(objects))
+		for _, object := range objects {
+			accessor, err := meta.Accessor(object)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, accessor.GetName())
+		}
+		return names, nil
+	}
+}
+
+// deleteResources deletes the named objects, or every object matching the
+// label selector, with foreground propagation so dependents go first. With
+// dryRun the API server validates the request without removing anything.
+// When a deletion fails, the objects already removed are returned along with
+// the error.
+func deleteResources(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config, logger Logger) ([]DeleteResult, error) {
+	kind := normalizeKind(cfg.Kind)
+	resource, err := resourceForKind(client, namespace, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	names := cfg.Names
+	if len(names) == 0 {
+		names, err = resource.list(ctx, metav1.ListOptions{LabelSelector: cfg.LabelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes: listing %ss matching %q in namespace %s: %w", kind, cfg.LabelSelector, namespace, err)
+		}
+		sort.Strings(names)
+	}
+
+	propagation := metav1.DeletePropagationForeground
+	opts := metav1.DeleteOptions{
+		GracePeriodSeconds: cfg.GracePeriodSeconds,
+		PropagationPolicy:  &propagation,
+	}
+	if cfg.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	results := make([]DeleteResult, 0, len(names))
+	for _, name := range names {
+		if err := resource.delete(ctx, name, opts); err != nil {
+			return results, fmt.Errorf("kubernetes: deleting %s %s in namespace %s: %w", kind, name, namespace, err)
+		}
+		if logger != nil {
+			suffix := ""
+			if cfg.DryRun {
+				suffix = " (dry run)"
+			}
+			logger.Printf("Kubernetes: deleted %s %s in namespace %s%s", kind, name, namespace, suffix)
+		}
+		results = append(results, DeleteResult{Kind: kind, Name: name, Namespace: namespace, DryRun: cfg.DryRun})
+	}
+	return results, nil
+}
@@ -0,0 +1,111 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+)
+
+func TestTaskConfigValidateDelete(t *testing.T) {
+	valid := taskConfig{Context: "example", Namespace: "apps", Operation: OperationDelete, Kind: "Pod", Names: []string{"web-0"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cases := map[string]struct {
+		mutate func(*taskConfig)
+		want   string
+	}{
+		"missing namespace":  {func(c *taskConfig) { c.Namespace = "" }, "namespace is required"},
+		"unknown kind":       {func(c *taskConfig) { c.Kind = "secret" }, "kind must be one of"},
+		"names and selector": {func(c *taskConfig) { c.LabelSelector = "app=web" }, "exactly one of names or label_selector"},
+		"no target":          {func(c *taskConfig) { c.Names = nil }, "exactly one of names or label_selector"},
+		"bad selector": {func(c *taskConfig) {
+			c.Names = nil
+			c.LabelSelector = "app in ("
+		}, "invalid label_selector"},
+		"negative grace": {func(c *taskConfig) { c.GracePeriodSeconds = pointer.Int64(-1) }, "grace_period_seconds"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			tc.mutate(&cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeleteResources(t *testing.T) {
+	labelled := func(name, app string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", Labels: map[string]string{"app": app}}}
+	}
+	client := fake.NewSimpleClientset(
+		labelled("web-b", "web"),
+		labelled("web-a", "web"),
+		labelled("db", "db"),
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "apps"}},
+	)
+
+	results, err := deleteResources(context.Background(), client, "apps", Config{Kind: "configmap", LabelSelector: "app=web"}, nil)
+	if err != nil {
+		t.Fatalf("deleteResources() error = %v", err)
+	}
+	want := []DeleteResult{
+		{Kind: "configmap", Name: "web-a", Namespace: "apps"},
+		{Kind: "configmap", Name: "web-b", Namespace: "apps"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Fatalf("results = %+v, want %+v", results, want)
+	}
+	remaining, _ := client.CoreV1().ConfigMaps("apps").List(context.Background(), metav1.ListOptions{})
+	if len(remaining.Items) != 1 || remaining.Items[0].Name != "db" {
+		t.Fatalf("remaining configmaps = %+v, want only db", remaining.Items)
+	}
+
+	client.ClearActions()
+	results, err = deleteResources(context.Background(), client, "apps", Config{Kind: "job", Names: []string{"migrate"}, GracePeriodSeconds: pointer.Int64(5), DryRun: true}, nil)
+	if err != nil {
+		t.Fatalf("deleteResources() dry run error = %v", err)
+	}
+	if len(results) != 1 || !results[0].DryRun {
+		t.Fatalf("results = %+v, want a dry-run deletion of migrate", results)
+	}
+
+	var opts metav1.DeleteOptions
+	for _, action := range client.Actions() {
+		if deleteAction, ok := action.(k8stesting.DeleteAction); ok {
+			opts = deleteAction.GetDeleteOptions()
+		}
+	}
+	if !reflect.DeepEqual(opts.DryRun, []string{metav1.DryRunAll}) {
+		t.Fatalf("DryRun = %v, want [All]", opts.DryRun)
+	}
+	if opts.PropagationPolicy == nil || *opts.PropagationPolicy != metav1.DeletePropagationForeground {
+		t.Fatalf("PropagationPolicy = %v, want Foreground", opts.PropagationPolicy)
+	}
+	if opts.GracePeriodSeconds == nil || *opts.GracePeriodSeconds != 5 {
+		t.Fatalf("GracePeriodSeconds = %v, want 5", opts.GracePeriodSeconds)
+	}
+
+	if _, err := deleteResources(context.Background(), client, "apps", Config{Kind: "pod", Names: []string{"missing"}}, nil); err == nil {
+		t.Fatal("deleteResources() error = nil, want not found error")
+	}
+
+	results, err = deleteResources(context.Background(), client, "apps", Config{Kind: "configmap", Names: []string{"db", "missing"}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "deleting configmap missing") {
+		t.Fatalf("deleteResources() error = %v, want not found error for missing", err)
+	}
+	if want := []DeleteResult{{Kind: "configmap", Name: "db", Namespace: "apps"}}; !reflect.DeepEqual(results, want) {
+		t.Fatalf("partial results = %+v, want %+v", results, want)
+	}
+}
@@ -0,0 +1,111 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EventDetails summarizes one event returned by a GET_EVENTS operation.
+type EventDetails struct {
+	Type          string `json:"type"`
+	Reason        string `json:"reason"`
+	Message       string `json:"message"`
+	Count         int32  `json:"count"`
+	Age           string `json:"age"`
+	Kind          string `json:"kind"`
+	Name          string `json:"name"`
+	LastTimestamp string `json:"lastTimestamp,omitempty"`
+}
+
+// listEvents lists the events of a namespace, oldest first like
+// `kubectl get events --sort-by=.lastTimestamp`. Names and Kind keep the
+// events whose involved object matches them; the label selector is passed to
+// the API server and applies to the events' own labels.
+func listEvents(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config) ([]EventDetails, error) {
+	eventList, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: cfg.LabelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: listing events in namespace %s: %w", namespace, err)
+	}
+
+	matched := make([]*corev1.Event, 0, len(eventList.Items))
+	for i := range eventList.Items {
+		event := &eventList.Items[i]
+		if cfg.Kind != "" && !strings.EqualFold(event.InvolvedObject.Kind, cfg.Kind) {
+			continue
+		}
+		if len(cfg.Names) > 0 && !containsName(cfg.Names, event.InvolvedObject.Name) {
+			continue
+		}
+		matched = append(matched, event)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return eventLastSeen(matched[i]).Before(eventLastSeen(matched[j]))
+	})
+
+	events := make([]EventDetails, 0, len(matched))
+	for _, event := range matched {
+		events = append(events, buildEventDetails(event))
+	}
+	return events, nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+// eventLastSeen returns when the event last occurred. Events recorded through
+// the events.k8s.io API only set eventTime and series, so those are used when
+// lastTimestamp is empty.
+func eventLastSeen(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+func buildEventDetails(event *corev1.Event) EventDetails {
+	count := event.Count
+	if event.Series != nil && event.Series.Count > count {
+		count = event.Series.Count
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	details := EventDetails{
+		Type:    event.Type,
+		Reason:  event.Reason,
+		Message: strings.TrimSpace(event.Message),
+		Count:   count,
+		Kind:    event.InvolvedObject.Kind,
+		Name:    event.InvolvedObject.Name,
+	}
+	if lastSeen := eventLastSeen(event); !lastSeen.IsZero() {
+		details.Age = formatRelativeDuration(time.Since(lastSeen))
+		details.LastTimestamp = lastSeen.UTC().Format(time.RFC3339)
+	}
+	return details
+}
@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTaskConfigValidateGetEvents(t *testing.T) {
+	valid := taskConfig{Context: "example", Operation: OperationGetEvents, Kind: "Pod", Names: []string{"web-1"}, LabelSelector: "team=web"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	invalid := valid
+	invalid.LabelSelector = "team in (web"
+	if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), "invalid label_selector") {
+		t.Fatalf("Validate() error = %v, want invalid label_selector", err)
+	}
+}
+
+func TestListEventsFiltersAndSorts(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+	event := func(name, kind, object, reason string, lastSeen time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: object, Namespace: "apps"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        reason + " for " + object,
+			Count:          2,
+			LastTimestamp:  metav1.NewTime(lastSeen),
+		}
+	}
+	// Recorded through events.k8s.io: no lastTimestamp nor count.
+	scheduled := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "web-1.scheduled", Namespace: "apps"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1", Namespace: "apps"},
+		Type:           corev1.EventTypeNormal,
+		Reason:         "Scheduled",
+		EventTime:      metav1.NewMicroTime(base),
+	}
+	client := fake.NewSimpleClientset(
+		event("web-1.backoff", "Pod", "web-1", "BackOff", base.Add(30*time.Minute)),
+		event("web-1.pull", "Pod", "web-1", "Failed", base.Add(10*time.Minute)),
+		event("web.scaled", "Deployment", "web", "ScalingReplicaSet", base.Add(5*time.Minute)),
+		event("db-1.pull", "Pod", "db-1", "Failed", base.Add(20*time.Minute)),
+		scheduled,
+	)
+
+	reasons := func(events []EventDetails) string {
+		var out []string
+		for _, e := range events {
+			out = append(out, e.Kind+"/"+e.Name+":"+e.Reason)
+		}
+		return strings.Join(out, ",")
+	}
+
+	all, err := listEvents(context.Background(), client, "apps", Config{})
+	if err != nil {
+		t.Fatalf("listEvents() error = %v", err)
+	}
+	if got, want := reasons(all), "Pod/web-1:Scheduled,Deployment/web:ScalingReplicaSet,Pod/web-1:Failed,Pod/db-1:Failed,Pod/web-1:BackOff"; got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+
+	web, err := listEvents(context.Background(), client, "apps", Config{Kind: "pod", Names: []string{"web-1"}})
+	if err != nil {
+		t.Fatalf("listEvents() error = %v", err)
+	}
+	if got, want := reasons(web), "Pod/web-1:Scheduled,Pod/web-1:Failed,Pod/web-1:BackOff"; got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+
+	first, last := web[0], web[2]
+	if first.Count != 1 || first.Age != "1h" || first.LastTimestamp != base.UTC().Format(time.RFC3339) {
+		t.Fatalf("unexpected details for event without lastTimestamp: %+v", first)
+	}
+	if last.Type != corev1.EventTypeWarning || last.Count != 2 || last.Age != "30m" || last.Message != "BackOff for web-1" {
+		t.Fatalf("unexpected details: %+v", last)
+	}
+}
@@ -0,0 +1,165 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// defaultContainerAnnotation names the container kubectl execs into when none is given.
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// ExecResult reports the outcome of an EXEC operation.
+type ExecResult struct {
+	Namespace string   `json:"namespace"`
+	Pod       string   `json:"pod"`
+	Container string   `json:"container"`
+	Command   []string `json:"command"`
+	Stdout    string   `json:"stdout"`
+	Stderr    string   `json:"stderr"`
+	ExitCode  int      `json:"exitCode"`
+}
+
+// newExecutor opens the exec stream for the pod; tests replace it to avoid a
+// real API server.
+var newExecutor = func(client kubernetes.Interface, restCfg *rest.Config, namespace, pod string, opts *corev1.PodExecOptions) (remotecommand.Executor, error) {
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(opts, scheme.ParameterCodec)
+	return remotecommand.NewSPDYExecutor(restCfg, "POST", req.URL())
+}
+
+// execInPod runs cfg.Command in the requested pod, or in the first ready pod
+// of the requested deployment, and captures its output and exit status.
+func execInPod(ctx context.Context, client kubernetes.Interface, restCfg *rest.Config, namespace string, cfg Config, logger Logger) (ExecResult, error) {
+	var (
+		pod *corev1.Pod
+		err error
+	)
+	if len(cfg.Pods) > 0 {
+		pod, err = client.CoreV1().Pods(namespace).Get(ctx, cfg.Pods[0], metav1.GetOptions{})
+		if err != nil {
+			return ExecResult{}, fmt.Errorf("kubernetes: retrieving pod %s in namespace %s: %w", cfg.Pods[0], namespace, err)
+		}
+	} else {
+		pod, err = selectDeploymentPod(ctx, client, namespace, cfg.Deployments[0])
+		if err != nil {
+			return ExecResult{}, err
+		}
+	}
+
+	container, err := execContainer(pod, cfg.Container)
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	if logger != nil {
+		logger.Printf("Kubernetes: executing %q in pod %s container %s (namespace %s)", strings.Join(cfg.Command, " "), pod.Name, container, namespace)
+	}
+
+	executor, err := newExecutor(client, restCfg, namespace, pod.Name, &corev1.PodExecOptions{
+		Container: container,
+		Command:   cfg.Command,
+		Stdin:     cfg.Stdin != "",
+		Stdout:    true,
+		Stderr:    !cfg.TTY,
+		TTY:       cfg.TTY,
+	})
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("kubernetes: creating exec stream for pod %s: %w", pod.Name, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	opts := remotecommand.StreamOptions{Stdout: &stdout, Tty: cfg.TTY}
+	if !cfg.TTY {
+		opts.Stderr = &stderr
+	}
+	if cfg.Stdin != "" {
+		opts.Stdin = strings.NewReader(cfg.Stdin)
+	}
+
+	result := ExecResult{
+		Namespace: namespace,
+		Pod:       pod.Name,
+		Container: container,
+		Command:   cfg.Command,
+	}
+
+	err = executor.StreamWithContext(ctx, opts)
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ExecResult{}, fmt.Errorf("kubernetes: exec in pod %s interrupted: %w", pod.Name, ctxErr)
+		}
+		var exitErr utilexec.ExitError
+		if !errors.As(err, &exitErr) {
+			return ExecResult{}, fmt.Errorf("kubernetes: exec in pod %s: %w", pod.Name, err)
+		}
+		result.ExitCode = exitErr.ExitStatus()
+		if !cfg.CaptureExitCode {
+			return ExecResult{}, fmt.Errorf("kubernetes EXEC operation: command in pod %s exited with status %d: %s", pod.Name, result.ExitCode, strings.TrimSpace(result.Stderr))
+		}
+	}
+	return result, nil
+}
+
+// selectDeploymentPod returns the first ready pod, by name, that belongs to
+// the deployment.
+func selectDeploymentPod(ctx context.Context, client kubernetes.Interface, namespace, name string) (*corev1.Pod, error) {
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: retrieving deployment %s in namespace %s: %w", name, namespace, err)
+	}
+	if deployment.Spec.Selector == nil {
+		return nil, fmt.Errorf("kubernetes: deployment %s in namespace %s does not define a selector", name, namespace)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: parsing selector for deployment %s in namespace %s: %w", name, namespace, err)
+	}
+
+	podList, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: listing pods for deployment %s in namespace %s: %w", name, namespace, err)
+	}
+	sort.Slice(podList.Items, func(i, j int) bool {
+		return podList.Items[i].Name < podList.Items[j].Name
+	})
+	for i := range podList.Items {
+		if isPodReady(&podList.Items[i]) {
+			return &podList.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("kubernetes: no ready pods found for deployment %s in namespace %s", name, namespace)
+}
+
+// execContainer resolves the container to exec into, preferring the
+// requested one, then kubectl's default-container annotation, then the
+// first container.
+func execContainer(pod *corev1.Pod, requested string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		if annotated := strings.TrimSpace(pod.Annotations[defaultContainerAnnotation]); annotated != "" && containerExists(pod, annotated) {
+			return annotated, nil
+		}
+	}
+	containers, err := selectContainers(pod, requested)
+	if err != nil {
+		return "", err
+	}
+	return containers[0], nil
+}
@@ -0,0 +1,158 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// fakeExecutor echoes stdin to stdout and finishes with err, or blocks until
+// the context is cancelled when block is set.
+type fakeExecutor struct {
+	stderr string
+	err    error
+	block  bool
+}
+
+func (e *fakeExecutor) Stream(opts remotecommand.StreamOptions) error {
+	return e.StreamWithContext(context.Background(), opts)
+}
+
+func (e *fakeExecutor) StreamWithContext(ctx context.Context, opts remotecommand.StreamOptions) error {
+	if e.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if opts.Stdin != nil {
+		_, _ = io.Copy(opts.Stdout, opts.Stdin)
+	}
+	if opts.Stderr != nil {
+		_, _ = io.WriteString(opts.Stderr, e.stderr)
+	}
+	return e.err
+}
+
+func stubExecutor(t *testing.T, executor *fakeExecutor) *corev1.PodExecOptions {
+	t.Helper()
+	captured := &corev1.PodExecOptions{}
+	original := newExecutor
+	newExecutor = func(_ kubernetes.Interface, _ *rest.Config, _, pod string, opts *corev1.PodExecOptions) (remotecommand.Executor, error) {
+		*captured = *opts
+		return executor, nil
+	}
+	t.Cleanup(func() { newExecutor = original })
+	return captured
+}
+
+func newExecTestClient() *fake.Clientset {
+	pod := func(name string, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "apps",
+				Labels:      map[string]string{"app": "api"},
+				Annotations: map[string]string{defaultContainerAnnotation: "app"},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "sidecar"}, {Name: "app"}}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+	return fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "apps"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			},
+		},
+		pod("api-a", false),
+		pod("api-b", true),
+		pod("api-c", true),
+	)
+}
+
+func TestExecInPodUsesFirstReadyDeploymentPod(t *testing.T) {
+	captured := stubExecutor(t, &fakeExecutor{stderr: "warning"})
+
+	cfg := Config{Deployments: []string{"api"}, Command: []string{"cat"}, Stdin: "migrated"}
+	result, err := execInPod(context.Background(), newExecTestClient(), nil, "apps", cfg, nil)
+	if err != nil {
+		t.Fatalf("execInPod() error = %v", err)
+	}
+	if result.Pod != "api-b" || result.Container != "app" {
+		t.Fatalf("exec target = %s/%s, want api-b/app", result.Pod, result.Container)
+	}
+	if result.Stdout != "migrated" || result.Stderr != "warning" || result.ExitCode != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if !captured.Stdin || !captured.Stderr || captured.TTY {
+		t.Fatalf("unexpected exec options: %+v", captured)
+	}
+}
+
+func TestExecInPodExitStatus(t *testing.T) {
+	stubExecutor(t, &fakeExecutor{stderr: "boom", err: utilexec.CodeExitError{Err: errors.New("command terminated with exit code 3"), Code: 3}})
+	client := newExecTestClient()
+	cfg := Config{Pods: []string{"api-a"}, Container: "sidecar", Command: []string{"false"}}
+
+	_, err := execInPod(context.Background(), client, nil, "apps", cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "exited with status 3: boom") {
+		t.Fatalf("execInPod() error = %v, want exit status 3", err)
+	}
+
+	cfg.CaptureExitCode = true
+	result, err := execInPod(context.Background(), client, nil, "apps", cfg, nil)
+	if err != nil {
+		t.Fatalf("execInPod() with capture_exit_code error = %v", err)
+	}
+	if result.ExitCode != 3 || result.Container != "sidecar" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestExecInPodHonorsCancellation(t *testing.T) {
+	stubExecutor(t, &fakeExecutor{block: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	cfg := Config{Pods: []string{"api-b"}, Command: []string{"sleep", "infinity"}}
+	_, err := execInPod(ctx, newExecTestClient(), nil, "apps", cfg, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("execInPod() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestTaskConfigValidateExec(t *testing.T) {
+	cfg := taskConfig{Context: "example", Operation: OperationExec, Pods: []string{"api-a"}, Command: []string{"ls"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cfg.Deployments = []string{"api"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "exactly one pod or deployment") {
+		t.Fatalf("Validate() error = %v, want target error", err)
+	}
+
+	cfg = taskConfig{Context: "example", Operation: OperationExec, Pods: []string{"api-a"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "command is required") {
+		t.Fatalf("Validate() error = %v, want command error", err)
+	}
+}
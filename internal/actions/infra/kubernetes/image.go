@@ -0,0 +1,131 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ContainerImageChange reports the image update of one container.
+type ContainerImageChange struct {
+	Container     string `json:"container"`
+	PreviousImage string `json:"previousImage"`
+	Image         string `json:"image"`
+	Changed       bool   `json:"changed"`
+}
+
+// SetImageResult reports the outcome of a SET_IMAGE operation.
+type SetImageResult struct {
+	Namespace  string                     `json:"namespace"`
+	Deployment string                     `json:"deployment"`
+	Containers []ContainerImageChange     `json:"containers"`
+	Readiness  *WaitForPodReadinessResult `json:"readiness,omitempty"`
+}
+
+// setImage updates container images of a deployment with a strategic merge
+// patch, like kubectl set image, and optionally waits for the rollout. Every
+// requested container must exist in the pod template, as a container or an
+// init container, before anything is patched.
+func setImage(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config, logger Logger) (SetImageResult, error) {
+	name := cfg.Deployments[0]
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return SetImageResult{}, fmt.Errorf("kubernetes: getting deployment %s in namespace %s: %w", name, namespace, err)
+	}
+
+	podSpec := deployment.Spec.Template.Spec
+	containerNames := make([]string, 0, len(cfg.Images))
+	for container := range cfg.Images {
+		containerNames = append(containerNames, container)
+	}
+	sort.Strings(containerNames)
+
+	result := SetImageResult{
+		Namespace:  namespace,
+		Deployment: name,
+		Containers: make([]ContainerImageChange, 0, len(containerNames)),
+	}
+	var containers, initContainers []map[string]string
+	for _, container := range containerNames {
+		image := cfg.Images[container]
+		previous, isInit, found := containerImage(podSpec, container)
+		if !found {
+			return SetImageResult{}, fmt.Errorf("kubernetes SET_IMAGE operation: container %q not found in deployment %s", container, name)
+		}
+
+		change := ContainerImageChange{Container: container, PreviousImage: previous, Image: image, Changed: previous != image}
+		result.Containers = append(result.Containers, change)
+		if !change.Changed {
+			continue
+		}
+		entry := map[string]string{"name": container, "image": image}
+		if isInit {
+			initContainers = append(initContainers, entry)
+		} else {
+			containers = append(containers, entry)
+		}
+	}
+
+	if len(containers)+len(initContainers) > 0 {
+		podPatch := map[string]any{}
+		if len(containers) > 0 {
+			podPatch["containers"] = containers
+		}
+		if len(initContainers) > 0 {
+			podPatch["initContainers"] = initContainers
+		}
+		patch, err := json.Marshal(map[string]any{
+			"spec": map[string]any{
+				"template": map[string]any{"spec": podPatch},
+			},
+		})
+		if err != nil {
+			return SetImageResult{}, fmt.Errorf("kubernetes: encoding image patch: %w", err)
+		}
+		if _, err := client.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return SetImageResult{}, fmt.Errorf("kubernetes: setting images of deployment %s in namespace %s: %w", name, namespace, err)
+		}
+	}
+	if logger != nil {
+		for _, change := range result.Containers {
+			if change.Changed {
+				logger.Printf("Kubernetes: container %s of deployment %s: %s -> %s", change.Container, name, change.PreviousImage, change.Image)
+			} else {
+				logger.Printf("Kubernetes: container %s of deployment %s already uses %s", change.Container, name, change.Image)
+			}
+		}
+	}
+
+	if !cfg.Wait {
+		return result, nil
+	}
+
+	readiness, err := waitForWorkloads(ctx, client, namespace, OperationSetImage, workloadKindDeployment, []string{name}, true, cfg, logger)
+	if err != nil {
+		return SetImageResult{}, err
+	}
+	result.Readiness = &readiness
+	return result, nil
+}
+
+// containerImage returns the image of the named container in spec and
+// whether it is an init container.
+func containerImage(spec corev1.PodSpec, name string) (string, bool, bool) {
+	for _, container := range spec.Containers {
+		if container.Name == name {
+			return container.Image, false, true
+		}
+	}
+	for _, container := range spec.InitContainers {
+		if container.Name == name {
+			return container.Image, true, true
+		}
+	}
+	return "", false, false
+}
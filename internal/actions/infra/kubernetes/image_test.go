@@ -0,0 +1,151 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestTaskConfigValidateSetImage(t *testing.T) {
+	valid := taskConfig{Context: "example", Namespace: "apps", Operation: OperationSetImage, Deployments: []string{"web"}, Container: "web", Image: "registry.example.com/web:1.4.2"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cases := map[string]struct {
+		mutate func(*taskConfig)
+		want   string
+	}{
+		"missing namespace":       {func(c *taskConfig) { c.Namespace = "" }, "namespace is required"},
+		"no deployment":           {func(c *taskConfig) { c.Deployments = nil }, "exactly one deployment"},
+		"several deployments":     {func(c *taskConfig) { c.Deployments = []string{"web", "api"} }, "exactly one deployment"},
+		"no image":                {func(c *taskConfig) { c.Image = "" }, "specify either container with image, or images"},
+		"image and images":        {func(c *taskConfig) { c.Images = map[string]string{"web": "web:2"} }, "specify either container with image, or images"},
+		"image without container": {func(c *taskConfig) { c.Container = "" }, "container is required with image"},
+		"empty images entry": {func(c *taskConfig) {
+			c.Image = ""
+			c.Images = map[string]string{"web": " "}
+		}, "images entries need a container name and an image"},
+		"wait without max": {func(c *taskConfig) {
+			c.Wait = true
+			c.PollIntervalSeconds = 1
+		}, "max_wait_seconds"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			tc.mutate(&cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTaskSetImageMergesContainerAndImage(t *testing.T) {
+	cfg, err := decodeTask(json.RawMessage(`{"context":"example","namespace":"apps","operation":"SET_IMAGE","deployments":["web"],"container":" web ","image":" web:2 "}`))
+	if err != nil {
+		t.Fatalf("decodeTask() error = %v", err)
+	}
+	if len(cfg.Images) != 1 || cfg.Images["web"] != "web:2" {
+		t.Fatalf("Images = %v, want map[web:web:2]", cfg.Images)
+	}
+}
+
+func newImageTestDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps", Generation: 1},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "migrate", Image: "web-migrate:1"}},
+					Containers: []corev1.Container{
+						{Name: "web", Image: "web:1"},
+						{Name: "proxy", Image: "envoy:1.29"},
+					},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 1, ReadyReplicas: 1, AvailableReplicas: 1},
+	}
+}
+
+func TestSetImageUpdatesContainers(t *testing.T) {
+	client := fake.NewSimpleClientset(newImageTestDeployment())
+
+	cfg := Config{Deployments: []string{"web"}, Images: map[string]string{"web": "web:2", "migrate": "web-migrate:2", "proxy": "envoy:1.29"}}
+	result, err := setImage(context.Background(), client, "apps", cfg, nil)
+	if err != nil {
+		t.Fatalf("setImage() error = %v", err)
+	}
+	want := []ContainerImageChange{
+		{Container: "migrate", PreviousImage: "web-migrate:1", Image: "web-migrate:2", Changed: true},
+		{Container: "proxy", PreviousImage: "envoy:1.29", Image: "envoy:1.29", Changed: false},
+		{Container: "web", PreviousImage: "web:1", Image: "web:2", Changed: true},
+	}
+	if result.Deployment != "web" || result.Readiness != nil || len(result.Containers) != len(want) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for i := range want {
+		if result.Containers[i] != want[i] {
+			t.Fatalf("Containers[%d] = %+v, want %+v", i, result.Containers[i], want[i])
+		}
+	}
+
+	updated, err := client.AppsV1().Deployments("apps").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get deployment error = %v", err)
+	}
+	spec := updated.Spec.Template.Spec
+	if len(spec.Containers) != 2 || spec.Containers[0].Image != "web:2" || spec.Containers[1].Image != "envoy:1.29" {
+		t.Fatalf("containers = %+v", spec.Containers)
+	}
+	if len(spec.InitContainers) != 1 || spec.InitContainers[0].Image != "web-migrate:2" {
+		t.Fatalf("init containers = %+v", spec.InitContainers)
+	}
+}
+
+func TestSetImageRejectsUnknownContainer(t *testing.T) {
+	client := fake.NewSimpleClientset(newImageTestDeployment())
+
+	cfg := Config{Deployments: []string{"web"}, Images: map[string]string{"web": "web:2", "sidecar": "sidecar:1"}}
+	if _, err := setImage(context.Background(), client, "apps", cfg, nil); err == nil || !strings.Contains(err.Error(), `container "sidecar" not found`) {
+		t.Fatalf("setImage() error = %v, want unknown container", err)
+	}
+
+	unchanged, _ := client.AppsV1().Deployments("apps").Get(context.Background(), "web", metav1.GetOptions{})
+	if image := unchanged.Spec.Template.Spec.Containers[0].Image; image != "web:1" {
+		t.Fatalf("web image = %s, want the deployment left untouched", image)
+	}
+}
+
+func TestSetImageWaitsForReadiness(t *testing.T) {
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "apps", Labels: map[string]string{"app": "web"}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	client := fake.NewSimpleClientset(newImageTestDeployment(), readyPod)
+
+	cfg := Config{Deployments: []string{"web"}, Images: map[string]string{"web": "web:2"}, Wait: true, MaxWait: time.Second, PollInterval: 10 * time.Millisecond}
+	result, err := setImage(context.Background(), client, "apps", cfg, nil)
+	if err != nil {
+		t.Fatalf("setImage() error = %v", err)
+	}
+	if result.Readiness == nil || !result.Readiness.Succeeded || len(result.Readiness.Deployments) != 1 {
+		t.Fatalf("unexpected readiness: %+v", result.Readiness)
+	}
+}
@@ -0,0 +1,193 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// JobCondition mirrors one entry of a Job's status conditions.
+type JobCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+// WaitForJobResult reports the outcome of a WAIT_FOR_JOB operation.
+type WaitForJobResult struct {
+	Namespace   string         `json:"namespace"`
+	Job         string         `json:"job"`
+	Completions int32          `json:"completions"`
+	Succeeded   int32          `json:"succeeded"`
+	Failed      int32          `json:"failed"`
+	Active      int32          `json:"active"`
+	Complete    bool           `json:"complete"`
+	Conditions  []JobCondition `json:"conditions"`
+	Logs        []ContainerLog `json:"logs,omitempty"`
+	Checks      int            `json:"checks"`
+	Elapsed     string         `json:"elapsed"`
+}
+
+// waitForJob polls the named Job until it completes or fails. A failed Job is
+// returned together with an error so the result, including the logs of the
+// failing pod when CollectLogs is set, still reaches the flow.
+func waitForJob(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config, logger Logger) (WaitForJobResult, error) {
+	name := cfg.Names[0]
+	start := time.Now()
+	deadline := start.Add(cfg.MaxWait)
+
+	checks := 0
+	for {
+		job, err := client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return WaitForJobResult{}, fmt.Errorf("kubernetes: getting job %s in namespace %s: %w", name, namespace, err)
+		}
+		checks++
+
+		result := buildJobResult(job)
+		result.Checks = checks
+		result.Elapsed = formatRelativeDuration(time.Since(start))
+		if logger != nil {
+			logger.Printf("Kubernetes: checking job %s - %d/%d completions (active: %d, failed: %d)", name, result.Succeeded, result.Completions, result.Active, result.Failed)
+		}
+
+		if result.Complete {
+			return result, nil
+		}
+		if failed, reason := jobFailed(job); failed {
+			if cfg.CollectLogs {
+				result.Logs = collectFailedJobLogs(ctx, client, namespace, job, cfg, logger)
+			}
+			return result, fmt.Errorf("kubernetes WAIT_FOR_JOB operation: job %s failed: %s", name, reason)
+		}
+
+		if time.Now().After(deadline) {
+			return WaitForJobResult{}, fmt.Errorf("kubernetes WAIT_FOR_JOB operation: timeout after %s waiting for job %s: %d/%d completions", formatRelativeDuration(cfg.MaxWait), name, result.Succeeded, result.Completions)
+		}
+
+		remaining := time.Until(deadline)
+		wait := cfg.PollInterval
+		if remaining < wait {
+			wait = remaining
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			if !timer.Stop() {
+				<-timer.C
+			}
+			return WaitForJobResult{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func buildJobResult(job *batchv1.Job) WaitForJobResult {
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+
+	result := WaitForJobResult{
+		Namespace:   job.Namespace,
+		Job:         job.Name,
+		Completions: completions,
+		Succeeded:   job.Status.Succeeded,
+		Failed:      job.Status.Failed,
+		Active:      job.Status.Active,
+		Conditions:  make([]JobCondition, 0, len(job.Status.Conditions)),
+	}
+	for _, condition := range job.Status.Conditions {
+		entry := JobCondition{
+			Type:    string(condition.Type),
+			Status:  string(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		}
+		if !condition.LastTransitionTime.IsZero() {
+			entry.LastTransitionTime = condition.LastTransitionTime.UTC().Format(time.RFC3339)
+		}
+		result.Conditions = append(result.Conditions, entry)
+		if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
+			result.Complete = true
+		}
+	}
+	if result.Succeeded >= completions {
+		result.Complete = true
+	}
+	return result
+}
+
+// jobFailed reports whether the Job carries a true Failed condition and why.
+func jobFailed(job *batchv1.Job) (bool, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type != batchv1.JobFailed || condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		reason := condition.Reason
+		if condition.Message != "" {
+			reason = fmt.Sprintf("%s: %s", reason, condition.Message)
+		}
+		if reason == "" {
+			reason = "Failed"
+		}
+		return true, reason
+	}
+	return false, ""
+}
+
+// collectFailedJobLogs stores the logs of the most recently started failed pod
+// of the Job. Errors are only logged so they do not hide the Job failure.
+func collectFailedJobLogs(ctx context.Context, client kubernetes.Interface, namespace string, job *batchv1.Job, cfg Config, logger Logger) []ContainerLog {
+	pod, err := failedJobPod(ctx, client, namespace, job)
+	if err == nil && pod != nil {
+		var logs []ContainerLog
+		logs, err = collectPodLogs(ctx, client, namespace, pod, cfg)
+		if err == nil {
+			return logs
+		}
+	}
+	if err != nil && logger != nil {
+		logger.Printf("Kubernetes: collecting logs of failed job %s: %v", job.Name, err)
+	}
+	return nil
+}
+
+func failedJobPod(ctx context.Context, client kubernetes.Interface, namespace string, job *batchv1.Job) (*corev1.Pod, error) {
+	if job.Spec.Selector == nil {
+		return nil, fmt.Errorf("job %s has no selector", job.Name)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("converting selector for job %s: %w", job.Name, err)
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("listing pods for job %s: %w", job.Name, err)
+	}
+
+	failed := make([]*corev1.Pod, 0, len(pods.Items))
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodFailed {
+			failed = append(failed, &pods.Items[i])
+		}
+	}
+	if len(failed) == 0 {
+		return nil, fmt.Errorf("no failed pods found for job %s", job.Name)
+	}
+
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[j].CreationTimestamp.Before(&failed[i].CreationTimestamp)
+	})
+	return failed[0], nil
+}
@@ -0,0 +1,121 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestTaskConfigValidateWaitForJob(t *testing.T) {
+	valid := taskConfig{Context: "example", Namespace: "jobs", Operation: OperationWaitForJob, Names: []string{"migrate"}, MaxWaitSeconds: 60, PollIntervalSeconds: 1}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cases := map[string]struct {
+		mutate func(*taskConfig)
+		want   string
+	}{
+		"missing namespace": {func(c *taskConfig) { c.Namespace = "" }, "namespace is required"},
+		"no names":          {func(c *taskConfig) { c.Names = nil }, "exactly one job name"},
+		"several names":     {func(c *taskConfig) { c.Names = []string{"a", "b"} }, "exactly one job name"},
+		"no max wait":       {func(c *taskConfig) { c.MaxWaitSeconds = 0 }, "max_wait_seconds"},
+		"no poll interval":  {func(c *taskConfig) { c.PollIntervalSeconds = 0 }, "poll_interval_seconds"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			tc.mutate(&cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func newTestJob(status batchv1.JobStatus) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "jobs"},
+		Spec: batchv1.JobSpec{
+			Completions: pointer.Int32(2),
+			Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": "migrate"}},
+		},
+		Status: status,
+	}
+}
+
+func TestWaitForJobSucceeds(t *testing.T) {
+	client := fake.NewSimpleClientset(newTestJob(batchv1.JobStatus{
+		Succeeded: 2,
+		Conditions: []batchv1.JobCondition{{
+			Type:               batchv1.JobComplete,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)),
+		}},
+	}))
+
+	cfg := Config{Names: []string{"migrate"}, MaxWait: time.Second, PollInterval: 10 * time.Millisecond}
+	result, err := waitForJob(context.Background(), client, "jobs", cfg, nil)
+	if err != nil {
+		t.Fatalf("waitForJob() error = %v", err)
+	}
+	if !result.Complete || result.Succeeded != 2 || result.Completions != 2 || result.Checks != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.Conditions) != 1 || result.Conditions[0].Type != "Complete" || result.Conditions[0].LastTransitionTime != "2024-05-01T10:00:00Z" {
+		t.Fatalf("unexpected conditions: %+v", result.Conditions)
+	}
+}
+
+func TestWaitForJobFailureCollectsLogs(t *testing.T) {
+	job := newTestJob(batchv1.JobStatus{
+		Failed: 1,
+		Conditions: []batchv1.JobCondition{{
+			Type:    batchv1.JobFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  "BackoffLimitExceeded",
+			Message: "Job has reached the specified backoff limit",
+		}},
+	})
+	failedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate-abc", Namespace: "jobs", Labels: map[string]string{"job-name": "migrate"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "migrate"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+	client := fake.NewSimpleClientset(job, failedPod)
+
+	logDir := t.TempDir()
+	cfg := Config{Names: []string{"migrate"}, MaxWait: time.Second, PollInterval: 10 * time.Millisecond, CollectLogs: true, LogDir: logDir}
+	result, err := waitForJob(context.Background(), client, "jobs", cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "job migrate failed: BackoffLimitExceeded") {
+		t.Fatalf("waitForJob() error = %v, want job failure", err)
+	}
+	if result.Job != "migrate" || result.Failed != 1 || result.Complete {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.Logs) != 1 || result.Logs[0].Pod != "migrate-abc" || result.Logs[0].Container != "migrate" {
+		t.Fatalf("unexpected logs: %+v", result.Logs)
+	}
+	if _, err := os.Stat(filepath.Join(logDir, result.Logs[0].File)); err != nil {
+		t.Fatalf("log file not written: %v", err)
+	}
+}
+
+func TestWaitForJobTimeout(t *testing.T) {
+	client := fake.NewSimpleClientset(newTestJob(batchv1.JobStatus{Active: 1, Succeeded: 1}))
+
+	cfg := Config{Names: []string{"migrate"}, MaxWait: 30 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	_, err := waitForJob(context.Background(), client, "jobs", cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "timeout") || !strings.Contains(err.Error(), "1/2 completions") {
+		t.Fatalf("waitForJob() error = %v, want timeout", err)
+	}
+}
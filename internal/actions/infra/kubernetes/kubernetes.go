@@ -1,7 +1,9 @@
 package kubernetes
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,10 +24,9 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
-	"k8s.io/client-go/util/retry"
-	"k8s.io/utils/pointer"
 
 	"flowk/internal/flow"
+	"flowk/internal/shared/runcontext"
 )
 
 const (
@@ -38,14 +39,32 @@ const (
 	OperationGetDeployments = "GET_DEPLOYMENTS"
 	// OperationGetLogs retrieves logs for pods or deployments.
 	OperationGetLogs = "GET_LOGS"
-	// OperationScale updates the replica count of the requested deployment.
+	// OperationScale updates the replica count of the requested deployments or statefulsets.
 	OperationScale = "SCALE"
-	// OperationPortForward establishes a port-forward tunnel to a service-selected pod.
+	// OperationPortForward establishes a port-forward tunnel to a named pod or a service-selected pod.
 	OperationPortForward = "PORT_FORWARD"
 	// OperationStopPortForward terminates an active port-forward tunnel.
 	OperationStopPortForward = "STOP_PORT_FORWARD"
-	// OperationWaitForPodReadiness waits until every pod belonging to the requested deployments is ready.
+	// OperationWaitForPodReadiness waits until every pod belonging to the requested workloads is ready.
 	OperationWaitForPodReadiness = "WAIT_FOR_POD_READINESS"
+	// OperationApply server-side applies the objects declared in a manifest.
+	OperationApply = "APPLY"
+	// OperationDelete deletes named objects of a kind or every object matching a label selector.
+	OperationDelete = "DELETE"
+	// OperationExec runs a command inside a pod container.
+	OperationExec = "EXEC"
+	// OperationRolloutRestart restarts the pods of deployments or statefulsets without changing their spec.
+	OperationRolloutRestart = "ROLLOUT_RESTART"
+	// OperationWaitForJob waits until a Job completes or fails.
+	OperationWaitForJob = "WAIT_FOR_JOB"
+	// OperationGetEvents lists the events of a namespace, oldest first.
+	OperationGetEvents = "GET_EVENTS"
+	// OperationGetConfigMap returns the data of a ConfigMap.
+	OperationGetConfigMap = "GET_CONFIGMAP"
+	// OperationGetSecret returns the keys of a Secret, with values masked unless revealed.
+	OperationGetSecret = "GET_SECRET"
+	// OperationSetImage updates container images of a deployment.
+	OperationSetImage = "SET_IMAGE"
 )
 
 // Logger defines the minimal interface expected from loggers used by the action.
@@ -55,22 +74,47 @@ type Logger interface {
 
 // Config contains the information required to execute a Kubernetes operation.
 type Config struct {
-	Context       string
-	Namespace     string
-	Operation     string
-	Deployments   []string
-	Replicas      *int32
-	Kubeconfig    string
-	Pods          []string
-	Container     string
-	SinceTime     *time.Time
-	SincePodStart bool
-	Service       string
-	LocalPort     int32
-	ServicePort   int32
-	MaxWait       time.Duration
-	PollInterval  time.Duration
-	LogDir        string `json:"-"`
+	Context            string
+	Namespace          string
+	Operation          string
+	Deployments        []string
+	Replicas           *int32
+	Kubeconfig         string
+	InCluster          bool
+	Pods               []string
+	Container          string
+	SinceTime          *time.Time
+	SincePodStart      bool
+	SinceDuration      time.Duration
+	TailLines          *int64
+	Follow             bool
+	Previous           bool
+	Service            string
+	LocalPort          int32
+	ServicePort        int32
+	PodPort            int32
+	ForwardID          string
+	MaxWait            time.Duration
+	PollInterval       time.Duration
+	Manifest           string
+	ManifestPath       string
+	Kind               string
+	Names              []string
+	LabelSelector      string
+	FieldSelector      string
+	Statuses           []string
+	GracePeriodSeconds *int64
+	DryRun             bool
+	Command            []string
+	Stdin              string
+	TTY                bool
+	CaptureExitCode    bool
+	Wait               bool
+	CollectLogs        bool
+	Reveal             bool
+	Variable           string
+	Images             map[string]string
+	LogDir             string `json:"-"`
 }
 
 // DeploymentDetails captures high-level readiness details for a deployment.
@@ -100,17 +144,21 @@ type PodDetails struct {
 	ContainerImages []string `json:"containerImages,omitempty"`
 }
 
-// ScaleResult reports the outcome of a deployment scaling operation.
+// ScaleResult reports the outcome of a scaling operation. Deployment holds the
+// name of the scaled object whatever its kind.
 type ScaleResult struct {
 	Namespace        string `json:"namespace"`
+	Kind             string `json:"kind"`
 	Deployment       string `json:"deployment"`
 	PreviousReplicas int32  `json:"previousReplicas"`
 	DesiredReplicas  int32  `json:"desiredReplicas"`
 	Changed          bool   `json:"changed"`
 }
 
-// DeploymentReadinessStatus captures the readiness information for a deployment.
+// DeploymentReadinessStatus captures the readiness information for a
+// deployment, statefulset or daemonset. Deployment holds the object name.
 type DeploymentReadinessStatus struct {
+	Kind              string `json:"kind"`
 	Deployment        string `json:"deployment"`
 	DesiredReplicas   int32  `json:"desiredReplicas"`
 	ReadyReplicas     int32  `json:"readyReplicas"`
@@ -131,7 +179,7 @@ type WaitForPodReadinessResult struct {
 
 // Execute performs the requested Kubernetes operation and returns the outcome.
 func Execute(ctx context.Context, cfg Config, logger Logger) (any, flow.ResultType, error) {
-	client, restCfg, defaultNamespace, err := buildClient(cfg.Context, cfg.Kubeconfig)
+	client, restCfg, defaultNamespace, err := runClient(ctx, cfg.Context, cfg.Kubeconfig, cfg.InCluster)
 	if err != nil {
 		return nil, "", err
 	}
@@ -150,7 +198,7 @@ func Execute(ctx context.Context, cfg Config, logger Logger) (any, flow.ResultTy
 		if logger != nil {
 			logger.Printf("Kubernetes: listing pods in namespace %s (context %s)", namespace, cfg.Context)
 		}
-		pods, err := listPods(ctx, client, namespace)
+		pods, err := listPods(ctx, client, namespace, cfg)
 		if err != nil {
 			return nil, "", err
 		}
@@ -184,7 +232,7 @@ func Execute(ctx context.Context, cfg Config, logger Logger) (any, flow.ResultTy
 			}
 			logger.Printf("Kubernetes: fetching logs for %s %s in namespace %s (context %s)", targetType, targetList, namespace, cfg.Context)
 		}
-		logs, err := getLogs(ctx, client, namespace, cfg)
+		logs, err := getLogs(ctx, client, namespace, cfg, logger)
 		if err != nil {
 			return nil, "", err
 		}
@@ -196,12 +244,13 @@ func Execute(ctx context.Context, cfg Config, logger Logger) (any, flow.ResultTy
 		if len(cfg.Deployments) == 0 {
 			return nil, "", fmt.Errorf("kubernetes SCALE operation: at least one deployment is required")
 		}
+		kind := workloadKind(cfg.Kind)
 		if logger != nil {
-			logger.Printf("Kubernetes: scaling deployments %s in namespace %s to %d replicas (context %s)", strings.Join(cfg.Deployments, ", "), namespace, *cfg.Replicas, cfg.Context)
+			logger.Printf("Kubernetes: scaling %ss %s in namespace %s to %d replicas (context %s)", kind, strings.Join(cfg.Deployments, ", "), namespace, *cfg.Replicas, cfg.Context)
 		}
 		results := make([]ScaleResult, 0, len(cfg.Deployments))
 		for _, deployment := range cfg.Deployments {
-			result, err := scaleDeployment(ctx, client, namespace, deployment, *cfg.Replicas)
+			result, err := scaleWorkload(ctx, client, namespace, kind, deployment, *cfg.Replicas)
 			if err != nil {
 				return nil, "", err
 			}
@@ -209,31 +258,39 @@ func Execute(ctx context.Context, cfg Config, logger Logger) (any, flow.ResultTy
 		}
 		return results, flow.ResultTypeJSON, nil
 	case OperationPortForward:
-		if strings.TrimSpace(cfg.Service) == "" {
-			return nil, "", fmt.Errorf("kubernetes PORT_FORWARD operation: service is required")
+		if strings.TrimSpace(cfg.Service) == "" && len(cfg.Pods) == 0 {
+			return nil, "", fmt.Errorf("kubernetes PORT_FORWARD operation: service or pod is required")
 		}
 		if cfg.LocalPort <= 0 || cfg.LocalPort > 65535 {
 			return nil, "", fmt.Errorf("kubernetes PORT_FORWARD operation: local_port must be between 1 and 65535")
 		}
-		if cfg.ServicePort <= 0 || cfg.ServicePort > 65535 {
+		if len(cfg.Pods) == 0 && (cfg.ServicePort <= 0 || cfg.ServicePort > 65535) {
 			return nil, "", fmt.Errorf("kubernetes PORT_FORWARD operation: service_port must be between 1 and 65535")
 		}
 		if logger != nil {
-			logger.Printf("Kubernetes: port-forwarding service %s in namespace %s on localhost:%d (context %s)", cfg.Service, namespace, cfg.LocalPort, cfg.Context)
+			target := "service " + cfg.Service
+			if len(cfg.Pods) > 0 {
+				target = "pod " + cfg.Pods[0]
+			}
+			logger.Printf("Kubernetes: port-forwarding %s in namespace %s on localhost:%d (context %s)", target, namespace, cfg.LocalPort, cfg.Context)
 		}
-		result, err := portForwardService(ctx, client, restCfg, namespace, cfg, logger)
+		result, err := portForward(ctx, client, restCfg, namespace, cfg, logger)
 		if err != nil {
 			return nil, "", err
 		}
 		return result, flow.ResultTypeJSON, nil
 	case OperationStopPortForward:
-		if cfg.LocalPort <= 0 || cfg.LocalPort > 65535 {
+		if cfg.ForwardID == "" && (cfg.LocalPort <= 0 || cfg.LocalPort > 65535) {
 			return nil, "", fmt.Errorf("kubernetes STOP_PORT_FORWARD operation: local_port must be between 1 and 65535")
 		}
 		if logger != nil {
-			logger.Printf("Kubernetes: stopping port-forward on localhost:%d", cfg.LocalPort)
+			if cfg.ForwardID != "" {
+				logger.Printf("Kubernetes: stopping port-forward %s", cfg.ForwardID)
+			} else {
+				logger.Printf("Kubernetes: stopping port-forward on localhost:%d", cfg.LocalPort)
+			}
 		}
-		result, err := stopPortForward(ctx, cfg.LocalPort, logger)
+		result, err := stopPortForward(ctx, cfg.ForwardID, cfg.LocalPort, logger)
 		if err != nil {
 			return nil, "", err
 		}
@@ -249,35 +306,171 @@ func Execute(ctx context.Context, cfg Config, logger Logger) (any, flow.ResultTy
 			return nil, "", fmt.Errorf("kubernetes WAIT_FOR_POD_READINESS operation: poll_interval_seconds must be greater than zero")
 		}
 		if logger != nil {
-			logger.Printf("Kubernetes: waiting for deployments %s to become ready in namespace %s (context %s)", strings.Join(cfg.Deployments, ", "), namespace, cfg.Context)
+			logger.Printf("Kubernetes: waiting for %ss %s to become ready in namespace %s (context %s)", workloadKind(cfg.Kind), strings.Join(cfg.Deployments, ", "), namespace, cfg.Context)
 		}
 		result, err := waitForPodReadiness(ctx, client, namespace, cfg, logger)
 		if err != nil {
 			return nil, "", err
 		}
 		return result, flow.ResultTypeJSON, nil
+	case OperationApply:
+		objects, err := loadManifest(cfg)
+		if err != nil {
+			return nil, "", err
+		}
+		if logger != nil {
+			logger.Printf("Kubernetes: applying %d manifest objects in namespace %s (context %s)", len(objects), namespace, cfg.Context)
+		}
+		dyn, mapper, err := buildDynamicClient(client, restCfg)
+		if err != nil {
+			return nil, "", err
+		}
+		results, err := applyManifest(ctx, dyn, mapper, namespace, strings.TrimSpace(cfg.Namespace), objects, logger)
+		if err != nil {
+			return nil, "", err
+		}
+		return results, flow.ResultTypeJSON, nil
+	case OperationDelete:
+		if len(cfg.Names) == 0 && strings.TrimSpace(cfg.LabelSelector) == "" {
+			return nil, "", fmt.Errorf("kubernetes DELETE operation: names or label_selector is required")
+		}
+		if logger != nil {
+			target := strings.Join(cfg.Names, ", ")
+			if target == "" {
+				target = fmt.Sprintf("matching %q", cfg.LabelSelector)
+			}
+			logger.Printf("Kubernetes: deleting %s %s in namespace %s (context %s, dry run %t)", normalizeKind(cfg.Kind), target, namespace, cfg.Context, cfg.DryRun)
+		}
+		results, err := deleteResources(ctx, client, namespace, cfg, logger)
+		if err != nil {
+			// Keep the objects removed before the failure in the task result.
+			return results, flow.ResultTypeJSON, err
+		}
+		return results, flow.ResultTypeJSON, nil
+	case OperationExec:
+		if len(cfg.Command) == 0 {
+			return nil, "", fmt.Errorf("kubernetes EXEC operation: command is required")
+		}
+		if len(cfg.Pods) == 0 && len(cfg.Deployments) == 0 {
+			return nil, "", fmt.Errorf("kubernetes EXEC operation: pod or deployments is required")
+		}
+		result, err := execInPod(ctx, client, restCfg, namespace, cfg, logger)
+		if err != nil {
+			return nil, "", err
+		}
+		return result, flow.ResultTypeJSON, nil
+	case OperationRolloutRestart:
+		if len(cfg.Names) == 0 {
+			return nil, "", fmt.Errorf("kubernetes ROLLOUT_RESTART operation: at least one name is required")
+		}
+		if logger != nil {
+			logger.Printf("Kubernetes: restarting %ss %s in namespace %s (context %s)", workloadKind(cfg.Kind), strings.Join(cfg.Names, ", "), namespace, cfg.Context)
+		}
+		result, err := rolloutRestart(ctx, client, namespace, cfg, logger)
+		if err != nil {
+			return nil, "", err
+		}
+		return result, flow.ResultTypeJSON, nil
+	case OperationWaitForJob:
+		if len(cfg.Names) != 1 {
+			return nil, "", fmt.Errorf("kubernetes WAIT_FOR_JOB operation: exactly one job name is required")
+		}
+		if cfg.MaxWait <= 0 {
+			return nil, "", fmt.Errorf("kubernetes WAIT_FOR_JOB operation: max_wait_seconds must be greater than zero")
+		}
+		if cfg.PollInterval <= 0 {
+			return nil, "", fmt.Errorf("kubernetes WAIT_FOR_JOB operation: poll_interval_seconds must be greater than zero")
+		}
+		if logger != nil {
+			logger.Printf("Kubernetes: waiting for job %s to complete in namespace %s (context %s)", cfg.Names[0], namespace, cfg.Context)
+		}
+		result, err := waitForJob(ctx, client, namespace, cfg, logger)
+		if err != nil {
+			if result.Job == "" {
+				return nil, "", err
+			}
+			// Keep the failed job status and logs in the task result.
+			return result, flow.ResultTypeJSON, err
+		}
+		return result, flow.ResultTypeJSON, nil
+	case OperationGetEvents:
+		if logger != nil {
+			logger.Printf("Kubernetes: listing events in namespace %s (context %s)", namespace, cfg.Context)
+		}
+		events, err := listEvents(ctx, client, namespace, cfg)
+		if err != nil {
+			return nil, "", err
+		}
+		return events, flow.ResultTypeJSON, nil
+	case OperationGetConfigMap:
+		if len(cfg.Names) != 1 {
+			return nil, "", fmt.Errorf("kubernetes GET_CONFIGMAP operation: exactly one configmap name is required")
+		}
+		if logger != nil {
+			logger.Printf("Kubernetes: reading configmap %s in namespace %s (context %s)", cfg.Names[0], namespace, cfg.Context)
+		}
+		configMap, err := getConfigMap(ctx, client, namespace, cfg.Names[0])
+		if err != nil {
+			return nil, "", err
+		}
+		return configMap, flow.ResultTypeJSON, nil
+	case OperationGetSecret:
+		if len(cfg.Names) != 1 {
+			return nil, "", fmt.Errorf("kubernetes GET_SECRET operation: exactly one secret name is required")
+		}
+		if logger != nil {
+			logger.Printf("Kubernetes: reading secret %s in namespace %s (context %s)", cfg.Names[0], namespace, cfg.Context)
+		}
+		secret, err := getSecret(ctx, client, namespace, cfg.Names[0], cfg.Reveal)
+		if err != nil {
+			return nil, "", err
+		}
+		return secret, flow.ResultTypeJSON, nil
+	case OperationSetImage:
+		if len(cfg.Deployments) != 1 {
+			return nil, "", fmt.Errorf("kubernetes SET_IMAGE operation: exactly one deployment is required")
+		}
+		if len(cfg.Images) == 0 {
+			return nil, "", fmt.Errorf("kubernetes SET_IMAGE operation: at least one container image is required")
+		}
+		if logger != nil {
+			logger.Printf("Kubernetes: setting images of deployment %s in namespace %s (context %s)", cfg.Deployments[0], namespace, cfg.Context)
+		}
+		result, err := setImage(ctx, client, namespace, cfg, logger)
+		if err != nil {
+			return nil, "", err
+		}
+		return result, flow.ResultTypeJSON, nil
 	default:
 		return nil, "", fmt.Errorf("unsupported Kubernetes operation %q", cfg.Operation)
 	}
 }
 
 func waitForPodReadiness(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config, logger Logger) (WaitForPodReadinessResult, error) {
+	return waitForWorkloads(ctx, client, namespace, OperationWaitForPodReadiness, workloadKind(cfg.Kind), cfg.Deployments, false, cfg, logger)
+}
+
+// waitForWorkloads polls the named workloads of one kind until all of their
+// pods are ready. With requireUpdated, a workload is only ready once its
+// controller has observed the latest spec and replaced every pod, which is
+// what a rollout needs.
+func waitForWorkloads(ctx context.Context, client kubernetes.Interface, namespace, operation, kind string, names []string, requireUpdated bool, cfg Config, logger Logger) (WaitForPodReadinessResult, error) {
 	start := time.Now()
 	deadline := start.Add(cfg.MaxWait)
 
 	checks := 0
 	for {
-		statuses := make([]DeploymentReadinessStatus, 0, len(cfg.Deployments))
+		statuses := make([]DeploymentReadinessStatus, 0, len(names))
 		allReady := true
 
-		for _, name := range cfg.Deployments {
-			status, err := collectDeploymentReadiness(ctx, client, namespace, name)
+		for _, name := range names {
+			status, err := collectWorkloadReadiness(ctx, client, namespace, kind, name, requireUpdated)
 			if err != nil {
 				return WaitForPodReadinessResult{}, err
 			}
 			statuses = append(statuses, status)
 			if logger != nil {
-				logger.Printf("Kubernetes: checking deployment %s - %d/%d pods ready (desired replicas: %d)", status.Deployment, status.ReadyPods, status.TotalPods, status.DesiredReplicas)
+				logger.Printf("Kubernetes: checking %s %s - %d/%d pods ready (desired replicas: %d)", kind, status.Deployment, status.ReadyPods, status.TotalPods, status.DesiredReplicas)
 			}
 			if !status.Ready {
 				allReady = false
@@ -305,9 +498,9 @@ func waitForPodReadiness(ctx context.Context, client kubernetes.Interface, names
 			}
 			timeout := formatRelativeDuration(cfg.MaxWait)
 			if len(notReady) == 0 {
-				notReady = append(notReady, "no deployments reported readiness details")
+				notReady = append(notReady, fmt.Sprintf("no %ss reported readiness details", kind))
 			}
-			return WaitForPodReadinessResult{}, fmt.Errorf("kubernetes WAIT_FOR_POD_READINESS operation: timeout after %s waiting for deployments: %s", timeout, strings.Join(notReady, ", "))
+			return WaitForPodReadinessResult{}, fmt.Errorf("kubernetes %s operation: timeout after %s waiting for %ss: %s", operation, timeout, kind, strings.Join(notReady, ", "))
 		}
 
 		remaining := time.Until(deadline)
@@ -328,24 +521,102 @@ func waitForPodReadiness(ctx context.Context, client kubernetes.Interface, names
 	}
 }
 
-func collectDeploymentReadiness(ctx context.Context, client kubernetes.Interface, namespace, deploymentName string) (DeploymentReadinessStatus, error) {
-	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+// workloadState holds the controller fields readiness is computed from.
+type workloadState struct {
+	selector           *metav1.LabelSelector
+	desiredReplicas    int32
+	readyReplicas      int32
+	availableReplicas  int32
+	updatedReplicas    int32
+	generation         int64
+	observedGeneration int64
+	// fromStatus marks kinds whose desired count comes from the controller
+	// status, so readiness also requires the controller to report it.
+	fromStatus bool
+}
+
+func getWorkloadState(ctx context.Context, client kubernetes.Interface, namespace, kind, name string) (workloadState, error) {
+	var state workloadState
+	var replicas *int32
+	switch kind {
+	case workloadKindDeployment:
+		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return workloadState{}, fmt.Errorf("kubernetes: retrieving deployment %s in namespace %s: %w", name, namespace, err)
+		}
+		replicas = deployment.Spec.Replicas
+		state = workloadState{
+			selector:           deployment.Spec.Selector,
+			readyReplicas:      deployment.Status.ReadyReplicas,
+			availableReplicas:  deployment.Status.AvailableReplicas,
+			updatedReplicas:    deployment.Status.UpdatedReplicas,
+			generation:         deployment.Generation,
+			observedGeneration: deployment.Status.ObservedGeneration,
+		}
+	case workloadKindStatefulSet:
+		statefulSet, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return workloadState{}, fmt.Errorf("kubernetes: retrieving statefulset %s in namespace %s: %w", name, namespace, err)
+		}
+		replicas = statefulSet.Spec.Replicas
+		state = workloadState{
+			selector:           statefulSet.Spec.Selector,
+			readyReplicas:      statefulSet.Status.ReadyReplicas,
+			availableReplicas:  statefulSet.Status.AvailableReplicas,
+			updatedReplicas:    statefulSet.Status.UpdatedReplicas,
+			generation:         statefulSet.Generation,
+			observedGeneration: statefulSet.Status.ObservedGeneration,
+		}
+	case workloadKindDaemonSet:
+		daemonSet, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return workloadState{}, fmt.Errorf("kubernetes: retrieving daemonset %s in namespace %s: %w", name, namespace, err)
+		}
+		// DaemonSets have no replica count: one pod is desired per eligible
+		// node, as reported by the controller.
+		desired := daemonSet.Status.DesiredNumberScheduled
+		replicas = &desired
+		state = workloadState{
+			selector:           daemonSet.Spec.Selector,
+			readyReplicas:      daemonSet.Status.NumberReady,
+			availableReplicas:  daemonSet.Status.NumberAvailable,
+			updatedReplicas:    daemonSet.Status.UpdatedNumberScheduled,
+			generation:         daemonSet.Generation,
+			observedGeneration: daemonSet.Status.ObservedGeneration,
+			fromStatus:         true,
+		}
+	default:
+		return workloadState{}, fmt.Errorf("kubernetes: unsupported workload kind %q", kind)
+	}
+
+	state.desiredReplicas = 1
+	if replicas != nil {
+		state.desiredReplicas = *replicas
+		if state.desiredReplicas < 0 {
+			state.desiredReplicas = 0
+		}
+	}
+	return state, nil
+}
+
+func collectWorkloadReadiness(ctx context.Context, client kubernetes.Interface, namespace, kind, name string, requireUpdated bool) (DeploymentReadinessStatus, error) {
+	state, err := getWorkloadState(ctx, client, namespace, kind, name)
 	if err != nil {
-		return DeploymentReadinessStatus{}, fmt.Errorf("kubernetes: retrieving deployment %s in namespace %s: %w", deploymentName, namespace, err)
+		return DeploymentReadinessStatus{}, err
 	}
 
-	if deployment.Spec.Selector == nil {
-		return DeploymentReadinessStatus{}, fmt.Errorf("kubernetes: deployment %s in namespace %s does not define a selector", deploymentName, namespace)
+	if state.selector == nil {
+		return DeploymentReadinessStatus{}, fmt.Errorf("kubernetes: %s %s in namespace %s does not define a selector", kind, name, namespace)
 	}
 
-	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	selector, err := metav1.LabelSelectorAsSelector(state.selector)
 	if err != nil {
-		return DeploymentReadinessStatus{}, fmt.Errorf("kubernetes: building selector for deployment %s in namespace %s: %w", deploymentName, namespace, err)
+		return DeploymentReadinessStatus{}, fmt.Errorf("kubernetes: building selector for %s %s in namespace %s: %w", kind, name, namespace, err)
 	}
 
 	podList, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
 	if err != nil {
-		return DeploymentReadinessStatus{}, fmt.Errorf("kubernetes: listing pods for deployment %s in namespace %s: %w", deploymentName, namespace, err)
+		return DeploymentReadinessStatus{}, fmt.Errorf("kubernetes: listing pods for %s %s in namespace %s: %w", kind, name, namespace, err)
 	}
 
 	readyPods := 0
@@ -364,33 +635,96 @@ func collectDeploymentReadiness(ctx context.Context, client kubernetes.Interface
 		}
 	}
 
-	desiredReplicas := int32(1)
-	if deployment.Spec.Replicas != nil {
-		desiredReplicas = *deployment.Spec.Replicas
-		if desiredReplicas < 0 {
-			desiredReplicas = 0
-		}
-	}
-
+	desiredReplicas := state.desiredReplicas
 	ready := false
 	if desiredReplicas == 0 {
 		ready = totalPods == 0
 	} else {
 		ready = readyPods >= int(desiredReplicas) && readyPods == totalPods && totalPods >= int(desiredReplicas)
 	}
+	if state.fromStatus && state.readyReplicas < desiredReplicas {
+		ready = false
+	}
+	if requireUpdated && (state.observedGeneration < state.generation || state.updatedReplicas < desiredReplicas) {
+		ready = false
+	}
 
 	return DeploymentReadinessStatus{
-		Deployment:        deploymentName,
+		Kind:              kind,
+		Deployment:        name,
 		DesiredReplicas:   desiredReplicas,
-		ReadyReplicas:     deployment.Status.ReadyReplicas,
-		AvailableReplicas: deployment.Status.AvailableReplicas,
+		ReadyReplicas:     state.readyReplicas,
+		AvailableReplicas: state.availableReplicas,
 		ReadyPods:         readyPods,
 		TotalPods:         totalPods,
 		Ready:             ready,
 	}, nil
 }
 
-func buildClient(contextName, kubeconfigPath string) (kubernetes.Interface, *rest.Config, string, error) {
+// serviceAccountNamespaceFile holds the namespace of the pod flowk runs in
+// when it uses the in-cluster configuration.
+var serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// inClusterConfig is replaced in tests, where no service account is mounted.
+var inClusterConfig = rest.InClusterConfig
+
+// useInClusterConfig reports whether the client should authenticate with the
+// pod's service account: when requested explicitly, or when running inside a
+// cluster without any kubeconfig to load.
+func useInClusterConfig(inCluster bool, kubeconfigPath string) bool {
+	if inCluster {
+		return true
+	}
+	if strings.TrimSpace(os.Getenv("KUBERNETES_SERVICE_HOST")) == "" {
+		return false
+	}
+	if strings.TrimSpace(kubeconfigPath) != "" || strings.TrimSpace(os.Getenv("KUBECONFIG")) != "" {
+		return false
+	}
+	_, err := os.Stat(clientcmd.RecommendedHomeFile)
+	return err != nil
+}
+
+// clientKey identifies a cached client within a run.
+type clientKey struct {
+	context    string
+	kubeconfig string
+	inCluster  bool
+}
+
+type cachedClient struct {
+	client    kubernetes.Interface
+	restCfg   *rest.Config
+	namespace string
+}
+
+// runClient returns the client for the context and kubeconfig, building it
+// once per run so every task of the run shares its connections.
+func runClient(ctx context.Context, contextName, kubeconfigPath string, inCluster bool) (kubernetes.Interface, *rest.Config, string, error) {
+	key := clientKey{
+		context:    strings.TrimSpace(contextName),
+		kubeconfig: strings.TrimSpace(kubeconfigPath),
+		inCluster:  inCluster,
+	}
+	value, err := runcontext.RunScopeFromContext(ctx).Load(key, func() (any, error) {
+		client, restCfg, namespace, err := buildClient(contextName, kubeconfigPath, inCluster)
+		if err != nil {
+			return nil, err
+		}
+		return &cachedClient{client: client, restCfg: restCfg, namespace: namespace}, nil
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	cached := value.(*cachedClient)
+	return cached.client, cached.restCfg, cached.namespace, nil
+}
+
+func buildClient(contextName, kubeconfigPath string, inCluster bool) (kubernetes.Interface, *rest.Config, string, error) {
+	if useInClusterConfig(inCluster, kubeconfigPath) {
+		return buildInClusterClient()
+	}
+
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 	if trimmed := strings.TrimSpace(kubeconfigPath); trimmed != "" {
 		loadingRules.ExplicitPath = trimmed
@@ -423,52 +757,155 @@ func buildClient(contextName, kubeconfigPath string) (kubernetes.Interface, *res
 	return clientset, restCfg, namespace, nil
 }
 
+func buildInClusterClient() (kubernetes.Interface, *rest.Config, string, error) {
+	restCfg, err := inClusterConfig()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("kubernetes: building in-cluster configuration: %w", err)
+	}
+
+	namespace := ""
+	if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("kubernetes: creating clientset: %w", err)
+	}
+
+	return clientset, restCfg, namespace, nil
+}
+
 // PortForwardResult captures the details of an established port-forward tunnel.
 type PortForwardResult struct {
+	ForwardID   string `json:"forwardId,omitempty"`
 	Namespace   string `json:"namespace"`
-	Service     string `json:"service"`
+	Service     string `json:"service,omitempty"`
 	Pod         string `json:"pod"`
 	LocalPort   int32  `json:"localPort"`
-	ServicePort int32  `json:"servicePort"`
+	ServicePort int32  `json:"servicePort,omitempty"`
 	TargetPort  string `json:"targetPort"`
 }
 
 // StopPortForwardResult reports the outcome of a STOP_PORT_FORWARD operation.
 type StopPortForwardResult struct {
+	ForwardID string `json:"forwardId,omitempty"`
 	LocalPort int32  `json:"localPort"`
 	Stopped   bool   `json:"stopped"`
 	Message   string `json:"message,omitempty"`
 }
 
 type portForwardSession struct {
+	id   string
 	stop func()
 	done chan struct{}
 	err  error
 }
 
-var (
-	portForwardSessionsMu sync.Mutex
-	portForwardSessions   = make(map[int32]*portForwardSession)
-)
+type portForwardSessionsKey struct{}
 
-func portForwardService(ctx context.Context, client kubernetes.Interface, restCfg *rest.Config, namespace string, cfg Config, logger Logger) (PortForwardResult, error) {
-	serviceName := strings.TrimSpace(cfg.Service)
-	svc, err := client.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
-	if err != nil {
-		return PortForwardResult{}, fmt.Errorf("kubernetes: retrieving service %s in namespace %s: %w", serviceName, namespace, err)
+// portForwardSessions holds the port-forwards opened by one run, keyed by
+// local port. It lives in the run scope, so concurrent runs never see each
+// other's tunnels, and it stops the tunnels still open when the run ends.
+type portForwardSessions struct {
+	mu       sync.Mutex
+	sessions map[int32]*portForwardSession
+	closed   bool
+}
+
+// runPortForwards returns the port-forward sessions of the run in ctx.
+// Outside a run every call returns a new, empty set.
+func runPortForwards(ctx context.Context) *portForwardSessions {
+	scope := runcontext.RunScopeFromContext(ctx)
+	value, _ := scope.Load(portForwardSessionsKey{}, func() (any, error) {
+		sessions := &portForwardSessions{sessions: make(map[int32]*portForwardSession)}
+		if scope != nil {
+			go sessions.stopWhenDone(scope.Context())
+		}
+		return sessions, nil
+	})
+	return value.(*portForwardSessions)
+}
+
+// stopWhenDone stops every registered session once the run context ends.
+func (s *portForwardSessions) stopWhenDone(ctx context.Context) {
+	<-ctx.Done()
+
+	s.mu.Lock()
+	s.closed = true
+	stops := make([]func(), 0, len(s.sessions))
+	for _, session := range s.sessions {
+		stops = append(stops, session.stop)
 	}
+	s.mu.Unlock()
 
-	svcPort, err := selectServicePort(svc, cfg.ServicePort)
-	if err != nil {
-		return PortForwardResult{}, err
+	for _, stop := range stops {
+		stop()
 	}
+}
 
-	pod, err := selectServicePod(ctx, client, namespace, svc)
-	if err != nil {
-		return PortForwardResult{}, err
+// add registers session on localPort. It reports false once the run ended,
+// in which case the caller must stop the session itself.
+func (s *portForwardSessions) add(localPort int32, session *portForwardSession) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
 	}
+	s.sessions[localPort] = session
+	return true
+}
 
-	targetPort, err := resolveTargetPort(pod, svcPort)
+// remove drops session unless another one replaced it on localPort.
+func (s *portForwardSessions) remove(localPort int32, session *portForwardSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.sessions[localPort]; ok && current == session {
+		delete(s.sessions, localPort)
+	}
+}
+
+// find returns the active session registered under the forward id, or under
+// the local port when id is empty.
+func (s *portForwardSessions) find(id string, localPort int32) (int32, *portForwardSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id == "" {
+		session, ok := s.sessions[localPort]
+		return localPort, session, ok
+	}
+	for port, session := range s.sessions {
+		if session.id == id {
+			return port, session, true
+		}
+	}
+	return 0, nil, false
+}
+
+// portForward opens a tunnel from cfg.LocalPort to a pod, either the single
+// pod named in cfg.Pods or a pod selected by cfg.Service.
+func portForward(ctx context.Context, client kubernetes.Interface, restCfg *rest.Config, namespace string, cfg Config, logger Logger) (PortForwardResult, error) {
+	sessions := runPortForwards(ctx)
+	forwardID := strings.TrimSpace(cfg.ForwardID)
+	if forwardID != "" {
+		if _, _, ok := sessions.find(forwardID, 0); ok {
+			return PortForwardResult{}, fmt.Errorf("kubernetes PORT_FORWARD operation: forward_id %q is already in use", forwardID)
+		}
+	}
+
+	var (
+		pod        *corev1.Pod
+		targetPort string
+		target     string
+		err        error
+	)
+	if len(cfg.Pods) > 0 {
+		pod, targetPort, err = resolvePodForwardTarget(ctx, client, namespace, cfg.Pods[0], cfg.PodPort)
+		target = "pod " + cfg.Pods[0]
+	} else {
+		pod, targetPort, err = resolveServiceForwardTarget(ctx, client, namespace, strings.TrimSpace(cfg.Service), cfg.ServicePort)
+		target = "service " + strings.TrimSpace(cfg.Service)
+	}
 	if err != nil {
 		return PortForwardResult{}, err
 	}
@@ -496,14 +933,6 @@ func portForwardService(ctx context.Context, client kubernetes.Interface, restCf
 		})
 	}
 
-	go func() {
-		select {
-		case <-ctx.Done():
-			stopFn()
-		case <-stopCh:
-		}
-	}()
-
 	ports := []string{fmt.Sprintf("%d:%s", cfg.LocalPort, targetPort)}
 
 	pf, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, ports, stopCh, readyCh, io.Discard, io.Discard)
@@ -526,40 +955,99 @@ func portForwardService(ctx context.Context, client kubernetes.Interface, restCf
 		}
 		return PortForwardResult{}, fmt.Errorf("kubernetes: running port-forward: %w", err)
 	case <-ctx.Done():
+		stopFn()
 		return PortForwardResult{}, ctx.Err()
 	}
 
 	session := &portForwardSession{
+		id:   forwardID,
 		stop: stopFn,
 		done: make(chan struct{}),
 	}
 
-	portForwardSessionsMu.Lock()
-	portForwardSessions[cfg.LocalPort] = session
-	portForwardSessionsMu.Unlock()
+	// The forward outlives the task that opened it: it stops with
+	// STOP_PORT_FORWARD or when the run ends, not with the task context.
+	if !sessions.add(cfg.LocalPort, session) {
+		stopFn()
+		return PortForwardResult{}, fmt.Errorf("kubernetes PORT_FORWARD operation: the run ended before the port-forward was registered")
+	}
 
 	go func() {
 		err := <-errCh
 		session.err = err
 		if err != nil && logger != nil {
-			logger.Printf("Kubernetes: port-forward for service %s/%s terminated: %v", namespace, serviceName, err)
-		}
-		portForwardSessionsMu.Lock()
-		if current, ok := portForwardSessions[cfg.LocalPort]; ok && current == session {
-			delete(portForwardSessions, cfg.LocalPort)
+			logger.Printf("Kubernetes: port-forward for %s in namespace %s terminated: %v", target, namespace, err)
 		}
-		portForwardSessionsMu.Unlock()
+		sessions.remove(cfg.LocalPort, session)
 		close(session.done)
 	}()
 
-	return PortForwardResult{
-		Namespace:   namespace,
-		Service:     serviceName,
-		Pod:         pod.Name,
-		LocalPort:   cfg.LocalPort,
-		ServicePort: cfg.ServicePort,
-		TargetPort:  targetPort,
-	}, nil
+	result := PortForwardResult{
+		ForwardID:  forwardID,
+		Namespace:  namespace,
+		Pod:        pod.Name,
+		LocalPort:  cfg.LocalPort,
+		TargetPort: targetPort,
+	}
+	if len(cfg.Pods) == 0 {
+		result.Service = strings.TrimSpace(cfg.Service)
+		result.ServicePort = cfg.ServicePort
+	}
+	return result, nil
+}
+
+// resolveServiceForwardTarget picks a pod behind the service and the pod port
+// its servicePort maps to.
+func resolveServiceForwardTarget(ctx context.Context, client kubernetes.Interface, namespace, serviceName string, servicePort int32) (*corev1.Pod, string, error) {
+	svc, err := client.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("kubernetes: retrieving service %s in namespace %s: %w", serviceName, namespace, err)
+	}
+
+	svcPort, err := selectServicePort(svc, servicePort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pod, err := selectServicePod(ctx, client, namespace, svc)
+	if err != nil {
+		return nil, "", err
+	}
+
+	targetPort, err := resolveTargetPort(pod, svcPort)
+	if err != nil {
+		return nil, "", err
+	}
+	return pod, targetPort, nil
+}
+
+// resolvePodForwardTarget fetches the named pod and resolves the port to
+// forward to: podPort when set, otherwise the pod's only container port.
+func resolvePodForwardTarget(ctx context.Context, client kubernetes.Interface, namespace, podName string, podPort int32) (*corev1.Pod, string, error) {
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("kubernetes: retrieving pod %s in namespace %s: %w", podName, namespace, err)
+	}
+	if podPort > 0 {
+		return pod, strconv.Itoa(int(podPort)), nil
+	}
+
+	var ports []int32
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Protocol == "" || port.Protocol == corev1.ProtocolTCP {
+				ports = append(ports, port.ContainerPort)
+			}
+		}
+	}
+	switch len(ports) {
+	case 1:
+		return pod, strconv.Itoa(int(ports[0])), nil
+	case 0:
+		return nil, "", fmt.Errorf("kubernetes: pod %s in namespace %s does not declare any TCP container port; set pod_port", podName, namespace)
+	default:
+		return nil, "", fmt.Errorf("kubernetes: pod %s in namespace %s declares %d TCP container ports; set pod_port to choose one", podName, namespace, len(ports))
+	}
 }
 
 func selectServicePort(svc *corev1.Service, requested int32) (corev1.ServicePort, error) {
@@ -649,11 +1137,12 @@ func resolveTargetPort(pod *corev1.Pod, port corev1.ServicePort) (string, error)
 	return "", fmt.Errorf("kubernetes: could not resolve target port for service port %s", port.Name)
 }
 
-func stopPortForward(ctx context.Context, localPort int32, logger Logger) (StopPortForwardResult, error) {
-	portForwardSessionsMu.Lock()
-	session, ok := portForwardSessions[localPort]
-	portForwardSessionsMu.Unlock()
+func stopPortForward(ctx context.Context, forwardID string, localPort int32, logger Logger) (StopPortForwardResult, error) {
+	port, session, ok := runPortForwards(ctx).find(forwardID, localPort)
 	if !ok {
+		if forwardID != "" {
+			return StopPortForwardResult{}, fmt.Errorf("kubernetes STOP_PORT_FORWARD operation: no active port-forward with forward_id %q", forwardID)
+		}
 		return StopPortForwardResult{}, fmt.Errorf("kubernetes STOP_PORT_FORWARD operation: no active port-forward on local port %d", localPort)
 	}
 
@@ -665,7 +1154,7 @@ func stopPortForward(ctx context.Context, localPort int32, logger Logger) (StopP
 		if session.err != nil {
 			message = session.err.Error()
 		}
-		return StopPortForwardResult{LocalPort: localPort, Stopped: true, Message: message}, nil
+		return StopPortForwardResult{ForwardID: session.id, LocalPort: port, Stopped: true, Message: message}, nil
 	case <-ctx.Done():
 		return StopPortForwardResult{}, ctx.Err()
 	}
@@ -707,15 +1196,25 @@ func isPodReady(pod *corev1.Pod) bool {
 	return false
 }
 
-func listPods(ctx context.Context, client kubernetes.Interface, namespace string) ([]PodDetails, error) {
-	podList, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+// listPods returns the pods matching the configured label and field
+// selectors, keeping only those whose displayed status is listed in
+// cfg.Statuses when any are given.
+func listPods(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config) ([]PodDetails, error) {
+	podList, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: cfg.LabelSelector,
+		FieldSelector: cfg.FieldSelector,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("kubernetes: listing pods in namespace %s: %w", namespace, err)
 	}
 
 	pods := make([]PodDetails, 0, len(podList.Items))
 	for i := range podList.Items {
-		pods = append(pods, buildPodDetails(&podList.Items[i]))
+		details := buildPodDetails(&podList.Items[i])
+		if !matchesStatus(details.Status, cfg.Statuses) {
+			continue
+		}
+		pods = append(pods, details)
 	}
 
 	sort.Slice(pods, func(i, j int) bool {
@@ -725,6 +1224,18 @@ func listPods(ctx context.Context, client kubernetes.Interface, namespace string
 	return pods, nil
 }
 
+func matchesStatus(status string, statuses []string) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, candidate := range statuses {
+		if strings.EqualFold(candidate, status) {
+			return true
+		}
+	}
+	return false
+}
+
 func buildPodDetails(pod *corev1.Pod) PodDetails {
 	if pod == nil {
 		return PodDetails{}
@@ -857,51 +1368,6 @@ func listDeployments(ctx context.Context, client kubernetes.Interface, namespace
 	return deployments, nil
 }
 
-func scaleDeployment(ctx context.Context, client kubernetes.Interface, namespace, name string, replicas int32) (ScaleResult, error) {
-	deployments := client.AppsV1().Deployments(namespace)
-
-	var (
-		result  ScaleResult
-		changed bool
-	)
-
-	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			return err
-		}
-
-		current := int32(0)
-		if deployment.Spec.Replicas != nil {
-			current = *deployment.Spec.Replicas
-		}
-
-		result.Namespace = namespace
-		result.Deployment = name
-		result.PreviousReplicas = current
-		result.DesiredReplicas = replicas
-
-		if current == replicas {
-			changed = false
-			return nil
-		}
-
-		deployment.Spec.Replicas = pointer.Int32(replicas)
-		if _, err := deployments.Update(ctx, deployment, metav1.UpdateOptions{}); err != nil {
-			return err
-		}
-
-		changed = true
-		return nil
-	})
-	if err != nil {
-		return ScaleResult{}, fmt.Errorf("kubernetes: scaling deployment %s in namespace %s: %w", name, namespace, err)
-	}
-
-	result.Changed = changed
-	return result, nil
-}
-
 // ContainerLog represents the stored log output of a specific container within a pod.
 type ContainerLog struct {
 	Namespace string `json:"namespace"`
@@ -910,7 +1376,7 @@ type ContainerLog struct {
 	File      string `json:"file"`
 }
 
-func getLogs(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config) ([]ContainerLog, error) {
+func getLogs(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config, logger Logger) ([]ContainerLog, error) {
 	if len(cfg.Pods) == 0 && len(cfg.Deployments) == 0 {
 		return nil, fmt.Errorf("kubernetes GET_LOGS operation: either pod or deployments must be specified")
 	}
@@ -918,23 +1384,40 @@ func getLogs(ctx context.Context, client kubernetes.Interface, namespace string,
 		return nil, fmt.Errorf("kubernetes GET_LOGS operation: pod and deployments cannot be specified together")
 	}
 
+	pods, err := resolveLogPods(ctx, client, namespace, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Follow {
+		return followPodLogs(ctx, client, namespace, pods, cfg, logger)
+	}
+
+	var logs []ContainerLog
+	for _, pod := range pods {
+		podLogs, err := collectPodLogs(ctx, client, namespace, pod, cfg)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, podLogs...)
+	}
+	return logs, nil
+}
+
+func resolveLogPods(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config) ([]*corev1.Pod, error) {
 	if len(cfg.Pods) > 0 {
-		var logs []ContainerLog
+		pods := make([]*corev1.Pod, 0, len(cfg.Pods))
 		for _, podName := range cfg.Pods {
 			pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
 			if err != nil {
 				return nil, fmt.Errorf("kubernetes: retrieving pod %s in namespace %s: %w", podName, namespace, err)
 			}
-			podLogs, err := collectPodLogs(ctx, client, namespace, pod, cfg)
-			if err != nil {
-				return nil, err
-			}
-			logs = append(logs, podLogs...)
+			pods = append(pods, pod)
 		}
-		return logs, nil
+		return pods, nil
 	}
 
-	var logs []ContainerLog
+	var pods []*corev1.Pod
 	for _, deploymentName := range cfg.Deployments {
 		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
 		if err != nil {
@@ -964,23 +1447,16 @@ func getLogs(ctx context.Context, client kubernetes.Interface, namespace string,
 		})
 
 		for i := range podList.Items {
-			podLogs, err := collectPodLogs(ctx, client, namespace, &podList.Items[i], cfg)
-			if err != nil {
-				return nil, err
-			}
-			logs = append(logs, podLogs...)
+			pods = append(pods, &podList.Items[i])
 		}
 	}
 
-	return logs, nil
+	return pods, nil
 }
 
-func collectPodLogs(ctx context.Context, client kubernetes.Interface, namespace string, pod *corev1.Pod, cfg Config) ([]ContainerLog, error) {
-	containers, err := selectContainers(pod, cfg.Container)
-	if err != nil {
-		return nil, err
-	}
-
+// podLogDir creates the directory holding the log files of a pod and returns
+// the log root together with the directory path relative to it.
+func podLogDir(cfg Config, pod *corev1.Pod) (string, string, error) {
 	logRoot := strings.TrimSpace(cfg.LogDir)
 	if logRoot == "" {
 		logRoot = "."
@@ -989,7 +1465,21 @@ func collectPodLogs(ctx context.Context, client kubernetes.Interface, namespace
 	baseRelativeDir := filepath.Join("kubernetes_logs", sanitizePathSegment(pod.Namespace), sanitizePathSegment(pod.Name))
 	baseDir := filepath.Join(logRoot, baseRelativeDir)
 	if err := os.MkdirAll(baseDir, 0o755); err != nil {
-		return nil, fmt.Errorf("kubernetes: ensuring log directory %s: %w", baseDir, err)
+		return "", "", fmt.Errorf("kubernetes: ensuring log directory %s: %w", baseDir, err)
+	}
+
+	return logRoot, baseRelativeDir, nil
+}
+
+func collectPodLogs(ctx context.Context, client kubernetes.Interface, namespace string, pod *corev1.Pod, cfg Config) ([]ContainerLog, error) {
+	containers, err := selectContainers(pod, cfg.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	logRoot, baseRelativeDir, err := podLogDir(cfg, pod)
+	if err != nil {
+		return nil, err
 	}
 
 	podLogs := make([]ContainerLog, 0, len(containers))
@@ -1025,6 +1515,91 @@ func collectPodLogs(ctx context.Context, client kubernetes.Interface, namespace
 	return podLogs, nil
 }
 
+// followPodLogs streams the logs of every selected container concurrently,
+// appending to the per-container files and echoing each line to the logger
+// until the streams end or ctx is cancelled.
+func followPodLogs(ctx context.Context, client kubernetes.Interface, namespace string, pods []*corev1.Pod, cfg Config, logger Logger) ([]ContainerLog, error) {
+	var (
+		logs     []ContainerLog
+		logPods  []*corev1.Pod
+		logFiles []string
+	)
+	for _, pod := range pods {
+		containers, err := selectContainers(pod, cfg.Container)
+		if err != nil {
+			return nil, err
+		}
+		logRoot, baseRelativeDir, err := podLogDir(cfg, pod)
+		if err != nil {
+			return nil, err
+		}
+		for _, container := range containers {
+			relativePath := filepath.Join(baseRelativeDir, fmt.Sprintf("%s.log", sanitizePathSegment(container)))
+			logs = append(logs, ContainerLog{
+				Namespace: pod.Namespace,
+				Pod:       pod.Name,
+				Container: container,
+				File:      relativePath,
+			})
+			logPods = append(logPods, pod)
+			logFiles = append(logFiles, filepath.Join(logRoot, relativePath))
+		}
+	}
+
+	errs := make([]error, len(logs))
+	var wg sync.WaitGroup
+	for i := range logs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = followContainerLog(ctx, client, namespace, logPods[i], logs[i].Container, logFiles[i], cfg, logger)
+		}(i)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func followContainerLog(ctx context.Context, client kubernetes.Interface, namespace string, pod *corev1.Pod, container, filePath string, cfg Config, logger Logger) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("kubernetes: creating log file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	options := buildPodLogOptions(pod, container, cfg)
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(pod.Name, options).Stream(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("kubernetes: retrieving logs for container %s in pod %s: %w", container, pod.Name, err)
+	}
+	defer stream.Close()
+
+	reader := bufio.NewReader(stream)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if line != "" {
+			if _, err := file.WriteString(line); err != nil {
+				return fmt.Errorf("kubernetes: writing log file %s: %w", filePath, err)
+			}
+			if logger != nil {
+				logger.Printf("Kubernetes: [%s/%s] %s", pod.Name, container, strings.TrimRight(line, "\r\n"))
+			}
+		}
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("kubernetes: reading logs for container %s in pod %s: %w", container, pod.Name, readErr)
+		}
+	}
+}
+
 func sanitizePathSegment(value string) string {
 	replacer := strings.NewReplacer(
 		"/", "_",
@@ -1101,7 +1676,15 @@ func containerExists(pod *corev1.Pod, name string) bool {
 }
 
 func buildPodLogOptions(pod *corev1.Pod, container string, cfg Config) *corev1.PodLogOptions {
-	opts := &corev1.PodLogOptions{Container: container}
+	opts := &corev1.PodLogOptions{
+		Container: container,
+		Follow:    cfg.Follow,
+		Previous:  cfg.Previous,
+	}
+	if cfg.TailLines != nil {
+		tail := *cfg.TailLines
+		opts.TailLines = &tail
+	}
 
 	if cfg.SinceTime != nil {
 		since := metav1.NewTime(*cfg.SinceTime)
@@ -1109,6 +1692,12 @@ func buildPodLogOptions(pod *corev1.Pod, container string, cfg Config) *corev1.P
 		return opts
 	}
 
+	if cfg.SinceDuration > 0 {
+		since := metav1.NewTime(time.Now().Add(-cfg.SinceDuration))
+		opts.SinceTime = &since
+		return opts
+	}
+
 	if cfg.SincePodStart {
 		if pod != nil && pod.Status.StartTime != nil {
 			opts.SinceTime = pod.Status.StartTime
@@ -2,7 +2,11 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -13,13 +17,22 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
+
+	"flowk/internal/config"
+	"flowk/internal/shared/runcontext"
 )
 
-func resetPortForwardSessions() {
-	portForwardSessionsMu.Lock()
-	portForwardSessions = make(map[int32]*portForwardSession)
-	portForwardSessionsMu.Unlock()
+// newPortForwardRun returns a context carrying a fresh run scope, and the
+// port-forward sessions of that run. The scope is closed when the test ends.
+func newPortForwardRun(t *testing.T) (context.Context, *portForwardSessions) {
+	t.Helper()
+	scope := runcontext.NewRunScope(context.Background())
+	t.Cleanup(scope.Close)
+	ctx := runcontext.WithRunScope(context.Background(), scope)
+	return ctx, runPortForwards(ctx)
 }
 
 func TestTaskConfigValidateStopPortForward(t *testing.T) {
@@ -46,6 +59,258 @@ func TestTaskConfigValidateStopPortForward(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 	})
+
+	t.Run("accepts forward id", func(t *testing.T) {
+		cfg := taskConfig{
+			Operation: OperationStopPortForward,
+			ForwardID: "db-tunnel",
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+	})
+}
+
+func TestTaskConfigValidateInCluster(t *testing.T) {
+	cfg := taskConfig{Operation: OperationGetPods, InCluster: true}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cfg.InCluster = false
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "context is required") {
+		t.Fatalf("Validate() error = %v, want context is required", err)
+	}
+}
+
+func TestDecodeTaskUsesConfigDefaults(t *testing.T) {
+	config.SetActionDefaults(config.DefaultsConfig{Kubernetes: config.KubernetesDefaultsConfig{Context: "prod", Kubeconfig: "/etc/kube/prod"}})
+	t.Cleanup(func() { config.SetActionDefaults(config.DefaultsConfig{}) })
+
+	cfg, err := decodeTask(json.RawMessage(`{"operation":"GET_PODS"}`))
+	if err != nil {
+		t.Fatalf("decodeTask() error = %v", err)
+	}
+	if cfg.Context != "prod" || cfg.Kubeconfig != "/etc/kube/prod" {
+		t.Fatalf("context, kubeconfig = %q, %q, want the config defaults", cfg.Context, cfg.Kubeconfig)
+	}
+
+	cfg, err = decodeTask(json.RawMessage(`{"operation":"GET_PODS","context":"staging","kubeconfig":"/tmp/kube"}`))
+	if err != nil {
+		t.Fatalf("decodeTask() error = %v", err)
+	}
+	if cfg.Context != "staging" || cfg.Kubeconfig != "/tmp/kube" {
+		t.Fatalf("context, kubeconfig = %q, %q, want the task values", cfg.Context, cfg.Kubeconfig)
+	}
+
+	cfg, err = decodeTask(json.RawMessage(`{"operation":"GET_PODS","in_cluster":true}`))
+	if err != nil {
+		t.Fatalf("decodeTask() error = %v", err)
+	}
+	if cfg.Kubeconfig != "" {
+		t.Fatalf("kubeconfig = %q, want in-cluster tasks to ignore the default", cfg.Kubeconfig)
+	}
+}
+
+func TestUseInClusterConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("KUBECONFIG", "")
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	if useInClusterConfig(false, "") {
+		t.Fatal("useInClusterConfig() = true outside a cluster")
+	}
+	if !useInClusterConfig(true, "/tmp/config") {
+		t.Fatal("useInClusterConfig() = false with in_cluster set")
+	}
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	if !useInClusterConfig(false, "") {
+		t.Fatal("useInClusterConfig() = false inside a cluster without kubeconfig")
+	}
+	if useInClusterConfig(false, "/tmp/config") {
+		t.Fatal("useInClusterConfig() = true with an explicit kubeconfig")
+	}
+
+	t.Setenv("KUBECONFIG", "/tmp/config")
+	if useInClusterConfig(false, "") {
+		t.Fatal("useInClusterConfig() = true with KUBECONFIG set")
+	}
+}
+
+func TestBuildClientInCluster(t *testing.T) {
+	namespaceFile := filepath.Join(t.TempDir(), "namespace")
+	if err := os.WriteFile(namespaceFile, []byte("jobs\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	originalFile, originalConfig := serviceAccountNamespaceFile, inClusterConfig
+	t.Cleanup(func() {
+		serviceAccountNamespaceFile, inClusterConfig = originalFile, originalConfig
+	})
+	serviceAccountNamespaceFile = namespaceFile
+	inClusterConfig = func() (*rest.Config, error) {
+		return &rest.Config{Host: "https://10.0.0.1:443"}, nil
+	}
+
+	client, restCfg, namespace, err := buildClient("", "", true)
+	if err != nil {
+		t.Fatalf("buildClient() error = %v", err)
+	}
+	if client == nil || restCfg.Host != "https://10.0.0.1:443" || namespace != "jobs" {
+		t.Fatalf("buildClient() = %v, %+v, %q", client, restCfg, namespace)
+	}
+
+	inClusterConfig = func() (*rest.Config, error) {
+		return nil, rest.ErrNotInCluster
+	}
+	if _, _, _, err := buildClient("", "", true); !errors.Is(err, rest.ErrNotInCluster) {
+		t.Fatalf("buildClient() error = %v, want ErrNotInCluster", err)
+	}
+}
+
+func TestRunClientSharedWithinRun(t *testing.T) {
+	originalFile, originalConfig := serviceAccountNamespaceFile, inClusterConfig
+	t.Cleanup(func() {
+		serviceAccountNamespaceFile, inClusterConfig = originalFile, originalConfig
+	})
+	serviceAccountNamespaceFile = filepath.Join(t.TempDir(), "missing")
+	builds := 0
+	inClusterConfig = func() (*rest.Config, error) {
+		builds++
+		return &rest.Config{Host: "https://10.0.0.1:443"}, nil
+	}
+
+	scope := runcontext.NewRunScope(context.Background())
+	ctx := runcontext.WithRunScope(context.Background(), scope)
+	first, _, _, err := runClient(ctx, "", "", true)
+	if err != nil {
+		t.Fatalf("runClient() error = %v", err)
+	}
+	second, _, _, err := runClient(ctx, " ", "", true)
+	if err != nil {
+		t.Fatalf("runClient() error = %v", err)
+	}
+	if first != second || builds != 1 {
+		t.Fatalf("runClient() built %d clients, want one shared client", builds)
+	}
+
+	scope.Close()
+	if _, _, _, err := runClient(ctx, "", "", true); err != nil {
+		t.Fatalf("runClient() error = %v", err)
+	}
+	if builds != 2 {
+		t.Fatalf("runClient() built %d clients, want a new client after the run ends", builds)
+	}
+}
+
+func TestTaskConfigValidatePortForwardTargets(t *testing.T) {
+	valid := taskConfig{Context: "example", Operation: OperationPortForward, Pods: []string{"db-0"}, LocalPort: 15432}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cases := map[string]struct {
+		mutate func(*taskConfig)
+		want   string
+	}{
+		"service and pod": {func(c *taskConfig) {
+			c.Service = "db"
+			c.ServicePort = 5432
+		}, "exactly one of service or pod"},
+		"no target":     {func(c *taskConfig) { c.Pods = nil }, "exactly one of service or pod"},
+		"several pods":  {func(c *taskConfig) { c.Pods = []string{"db-0", "db-1"} }, "single pod"},
+		"invalid port":  {func(c *taskConfig) { c.PodPort = 70000 }, "pod_port must be between 1 and 65535"},
+		"no local port": {func(c *taskConfig) { c.LocalPort = 0 }, "local_port must be between 1 and 65535"},
+		"service w/o port": {func(c *taskConfig) {
+			c.Pods = nil
+			c.Service = "db"
+		}, "service_port must be between 1 and 65535"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			tc.mutate(&cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolvePodForwardTarget(t *testing.T) {
+	podWithPorts := func(name string, ports ...corev1.ContainerPort) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Ports: ports}}},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		podWithPorts("single", corev1.ContainerPort{ContainerPort: 5432}),
+		podWithPorts("multi", corev1.ContainerPort{ContainerPort: 8080}, corev1.ContainerPort{ContainerPort: 9090}, corev1.ContainerPort{ContainerPort: 53, Protocol: corev1.ProtocolUDP}),
+		podWithPorts("none"),
+	)
+
+	tests := []struct {
+		pod     string
+		podPort int32
+		want    string
+		wantErr string
+	}{
+		{pod: "single", want: "5432"},
+		{pod: "multi", podPort: 9090, want: "9090"},
+		{pod: "multi", wantErr: "declares 2 TCP container ports"},
+		{pod: "none", wantErr: "does not declare any TCP container port"},
+		{pod: "none", podPort: 7000, want: "7000"},
+		{pod: "missing", wantErr: "retrieving pod missing"},
+	}
+	for _, tc := range tests {
+		pod, port, err := resolvePodForwardTarget(context.Background(), client, "apps", tc.pod, tc.podPort)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("resolvePodForwardTarget(%s, %d) error = %v, want %q", tc.pod, tc.podPort, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("resolvePodForwardTarget(%s, %d) error = %v", tc.pod, tc.podPort, err)
+		}
+		if pod.Name != tc.pod || port != tc.want {
+			t.Fatalf("resolvePodForwardTarget(%s, %d) = %s:%s, want %s:%s", tc.pod, tc.podPort, pod.Name, port, tc.pod, tc.want)
+		}
+	}
+}
+
+func TestStopPortForwardByID(t *testing.T) {
+	ctx, sessions := newPortForwardRun(t)
+
+	session := &portForwardSession{id: "db-tunnel", done: make(chan struct{})}
+	var stopOnce sync.Once
+	session.stop = func() {
+		stopOnce.Do(func() { close(session.done) })
+	}
+	other := &portForwardSession{id: "cache-tunnel", stop: func() {}, done: make(chan struct{})}
+
+	sessions.add(15432, session)
+	sessions.add(16379, other)
+
+	if _, err := stopPortForward(ctx, "unknown", 0, nil); err == nil || !strings.Contains(err.Error(), `forward_id "unknown"`) {
+		t.Fatalf("stopPortForward() error = %v, want unknown forward_id error", err)
+	}
+
+	result, err := stopPortForward(ctx, "db-tunnel", 0, nil)
+	if err != nil {
+		t.Fatalf("stopPortForward() error = %v", err)
+	}
+	if !result.Stopped || result.ForwardID != "db-tunnel" || result.LocalPort != 15432 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	select {
+	case <-other.done:
+		t.Fatal("expected the other session to keep running")
+	default:
+	}
 }
 
 func TestTaskConfigValidateWaitForPodReadiness(t *testing.T) {
@@ -132,6 +397,34 @@ func TestTaskConfigValidateWaitForPodReadiness(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 	})
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		cfg := taskConfig{
+			Context:             "example",
+			Namespace:           "apps",
+			Operation:           OperationWaitForPodReadiness,
+			Kind:                "job",
+			Deployments:         []string{"demo"},
+			MaxWaitSeconds:      30,
+			PollIntervalSeconds: 5,
+		}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "kind must be one of deployment, statefulset, daemonset") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestTaskConfigValidateScaleKind(t *testing.T) {
+	cfg := taskConfig{Context: "example", Namespace: "apps", Operation: OperationScale, Kind: "StatefulSet", Deployments: []string{"db"}, Replicas: pointer.Int32(3)}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cfg.Kind = "daemonset"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "kind must be one of deployment, statefulset") {
+		t.Fatalf("Validate() error = %v, want unsupported kind", err)
+	}
 }
 
 type recordingLogger struct {
@@ -146,15 +439,15 @@ func (l *recordingLogger) Printf(format string, v ...interface{}) {
 }
 
 func TestStopPortForward_NoActiveSession(t *testing.T) {
-	resetPortForwardSessions()
+	ctx, _ := newPortForwardRun(t)
 
-	if _, err := stopPortForward(context.Background(), 9000, nil); err == nil {
+	if _, err := stopPortForward(ctx, "", 9000, nil); err == nil {
 		t.Fatal("stopPortForward() error = nil, want error for missing session")
 	}
 }
 
 func TestStopPortForward_SessionLifecycle(t *testing.T) {
-	resetPortForwardSessions()
+	ctx, sessions := newPortForwardRun(t)
 
 	localPort := int32(8081)
 	stopCalled := make(chan struct{})
@@ -168,19 +461,15 @@ func TestStopPortForward_SessionLifecycle(t *testing.T) {
 		})
 	}
 
-	portForwardSessionsMu.Lock()
-	portForwardSessions[localPort] = session
-	portForwardSessionsMu.Unlock()
+	sessions.add(localPort, session)
 
 	go func() {
 		<-stopCalled
-		portForwardSessionsMu.Lock()
-		delete(portForwardSessions, localPort)
-		portForwardSessionsMu.Unlock()
+		sessions.remove(localPort, session)
 		close(session.done)
 	}()
 
-	result, err := stopPortForward(context.Background(), localPort, nil)
+	result, err := stopPortForward(ctx, "", localPort, nil)
 	if err != nil {
 		t.Fatalf("stopPortForward() error = %v", err)
 	}
@@ -194,14 +483,56 @@ func TestStopPortForward_SessionLifecycle(t *testing.T) {
 		t.Fatalf("Message = %q, want port-forward stopped", result.Message)
 	}
 
-	portForwardSessionsMu.Lock()
-	_, exists := portForwardSessions[localPort]
-	portForwardSessionsMu.Unlock()
-	if exists {
+	if _, _, exists := sessions.find("", localPort); exists {
 		t.Fatalf("session for port %d still registered, want removed", localPort)
 	}
 }
 
+func TestPortForwardSessionsArePerRun(t *testing.T) {
+	newSession := func(id string) *portForwardSession {
+		session := &portForwardSession{id: id, done: make(chan struct{})}
+		var stopOnce sync.Once
+		session.stop = func() {
+			stopOnce.Do(func() { close(session.done) })
+		}
+		return session
+	}
+
+	firstScope := runcontext.NewRunScope(context.Background())
+	firstCtx := runcontext.WithRunScope(context.Background(), firstScope)
+	firstSessions := runPortForwards(firstCtx)
+	first := newSession("tunnel")
+	firstSessions.add(15432, first)
+
+	secondCtx, secondSessions := newPortForwardRun(t)
+	second := newSession("tunnel")
+	secondSessions.add(15432, second)
+
+	if firstSessions == secondSessions {
+		t.Fatal("expected each run to get its own port-forward sessions")
+	}
+
+	result, err := stopPortForward(secondCtx, "tunnel", 0, nil)
+	if err != nil || !result.Stopped {
+		t.Fatalf("stopPortForward() = %+v, %v", result, err)
+	}
+	select {
+	case <-first.done:
+		t.Fatal("expected stopping a tunnel in one run to leave the other run's tunnel open")
+	default:
+	}
+
+	firstScope.Close()
+	select {
+	case <-first.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the tunnel to stop when its run ended")
+	}
+	if firstSessions.add(15433, newSession("late")) {
+		t.Fatal("expected sessions added after the run ended to be rejected")
+	}
+}
+
 func TestFormatRelativeDuration(t *testing.T) {
 	tests := []struct {
 		duration time.Duration
@@ -283,6 +614,53 @@ func TestBuildPodDetails(t *testing.T) {
 	}
 }
 
+func TestListPodsFilters(t *testing.T) {
+	pod := func(name, app string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", Labels: map[string]string{"app": app}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		pod("web-2", "web", corev1.PodPending),
+		pod("web-1", "web", corev1.PodRunning),
+		pod("db-1", "db", corev1.PodRunning),
+	)
+
+	names := func(pods []PodDetails) string {
+		var out []string
+		for _, p := range pods {
+			out = append(out, p.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	all, err := listPods(context.Background(), client, "apps", Config{})
+	if err != nil {
+		t.Fatalf("listPods() error = %v", err)
+	}
+	if got := names(all); got != "db-1,web-1,web-2" {
+		t.Fatalf("pods = %s, want every pod sorted by name", got)
+	}
+
+	web, err := listPods(context.Background(), client, "apps", Config{LabelSelector: "app=web", Statuses: []string{"pending"}})
+	if err != nil {
+		t.Fatalf("listPods() error = %v", err)
+	}
+	if got := names(web); got != "web-2" {
+		t.Fatalf("pods = %s, want web-2", got)
+	}
+
+	client.ClearActions()
+	if _, err := listPods(context.Background(), client, "apps", Config{FieldSelector: "spec.nodeName=worker-1"}); err != nil {
+		t.Fatalf("listPods() error = %v", err)
+	}
+	list := client.Actions()[0].(k8stesting.ListAction)
+	if got := list.GetListRestrictions().Fields.String(); got != "spec.nodeName=worker-1" {
+		t.Fatalf("field selector = %q, want spec.nodeName=worker-1", got)
+	}
+}
+
 func TestScaleDeployment(t *testing.T) {
 	client := fake.NewSimpleClientset(&appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -299,9 +677,9 @@ func TestScaleDeployment(t *testing.T) {
 		},
 	})
 
-	result, err := scaleDeployment(context.Background(), client, "default", "example", 5)
+	result, err := scaleWorkload(context.Background(), client, "default", "deployment", "example", 5)
 	if err != nil {
-		t.Fatalf("scaleDeployment() error = %v", err)
+		t.Fatalf("scaleWorkload() error = %v", err)
 	}
 	if !result.Changed {
 		t.Fatalf("Changed = false, want true")
@@ -321,15 +699,83 @@ func TestScaleDeployment(t *testing.T) {
 		t.Fatalf("deployment replicas = %v, want 5", dep.Spec.Replicas)
 	}
 
-	unchanged, err := scaleDeployment(context.Background(), client, "default", "example", 5)
+	unchanged, err := scaleWorkload(context.Background(), client, "default", "deployment", "example", 5)
 	if err != nil {
-		t.Fatalf("scaleDeployment() second call error = %v", err)
+		t.Fatalf("scaleWorkload() second call error = %v", err)
 	}
 	if unchanged.Changed {
 		t.Fatalf("Changed = true, want false for unchanged scale")
 	}
 }
 
+func TestScaleStatefulSet(t *testing.T) {
+	client := fake.NewSimpleClientset(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32(1)},
+	})
+
+	result, err := scaleWorkload(context.Background(), client, "apps", "statefulset", "db", 3)
+	if err != nil {
+		t.Fatalf("scaleWorkload() error = %v", err)
+	}
+	if result.Kind != "statefulset" || result.PreviousReplicas != 1 || result.DesiredReplicas != 3 || !result.Changed {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	sts, err := client.AppsV1().StatefulSets("apps").Get(context.Background(), "db", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get statefulset error = %v", err)
+	}
+	if sts.Spec.Replicas == nil || *sts.Spec.Replicas != 3 {
+		t.Fatalf("statefulset replicas = %v, want 3", sts.Spec.Replicas)
+	}
+}
+
+func TestWaitForPodReadiness_DaemonSet(t *testing.T) {
+	pod := func(name string, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", Labels: map[string]string{"app": "agent"}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+		Spec:       appsv1.DaemonSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}}},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 1},
+	}
+	client := fake.NewSimpleClientset(daemonSet, pod("agent-a", true), pod("agent-b", false))
+
+	cfg := Config{Kind: "daemonset", Deployments: []string{"agent"}, MaxWait: 30 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	_, err := waitForPodReadiness(context.Background(), client, "kube-system", cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "waiting for daemonsets: agent 1/2 ready") {
+		t.Fatalf("waitForPodReadiness() error = %v, want daemonset timeout", err)
+	}
+
+	if _, err := client.CoreV1().Pods("kube-system").Update(context.Background(), pod("agent-b", true), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update pod error = %v", err)
+	}
+	daemonSet.Status.NumberReady = 2
+	if _, err := client.AppsV1().DaemonSets("kube-system").UpdateStatus(context.Background(), daemonSet, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+
+	result, err := waitForPodReadiness(context.Background(), client, "kube-system", cfg, nil)
+	if err != nil {
+		t.Fatalf("waitForPodReadiness() error = %v", err)
+	}
+	status := result.Deployments[0]
+	if status.Kind != "daemonset" || status.DesiredReplicas != 2 || status.ReadyReplicas != 2 || !status.Ready {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
 func TestWaitForPodReadiness_Success(t *testing.T) {
 	client := fake.NewSimpleClientset(
 		&appsv1.Deployment{
@@ -468,6 +914,36 @@ func TestWaitForPodReadiness_Timeout(t *testing.T) {
 	}
 }
 
+func TestWaitForPodReadiness_AbortsOnContextDeadline(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "apps"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.Int32(1),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+			},
+		},
+	)
+
+	cfg := Config{
+		Deployments:  []string{"demo"},
+		MaxWait:      time.Minute,
+		PollInterval: 10 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := waitForPodReadiness(ctx, client, "apps", cfg, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("waitForPodReadiness() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("waitForPodReadiness() returned after %s, want prompt abort", elapsed)
+	}
+}
+
 func TestSelectContainers(t *testing.T) {
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
@@ -671,3 +1147,93 @@ func TestBuildPodLogOptions(t *testing.T) {
 		t.Fatalf("buildPodLogOptions() default sinceTime = %v, want nil", opts.SinceTime)
 	}
 }
+
+func TestBuildPodLogOptionsTailFollowPrevious(t *testing.T) {
+	tail := int64(50)
+	cfg := Config{TailLines: &tail, Follow: true, Previous: true, SinceDuration: 15 * time.Minute}
+
+	before := time.Now().Add(-15 * time.Minute)
+	opts := buildPodLogOptions(&corev1.Pod{}, "app", cfg)
+	after := time.Now().Add(-15 * time.Minute)
+
+	if opts.TailLines == nil || *opts.TailLines != 50 {
+		t.Fatalf("buildPodLogOptions() tailLines = %v, want 50", opts.TailLines)
+	}
+	if !opts.Follow || !opts.Previous {
+		t.Fatalf("buildPodLogOptions() follow = %t, previous = %t, want both true", opts.Follow, opts.Previous)
+	}
+	if opts.SinceTime == nil || opts.SinceTime.Time.Before(before.Truncate(time.Second)) || opts.SinceTime.Time.After(after) {
+		t.Fatalf("buildPodLogOptions() since duration = %v, want between %v and %v", opts.SinceTime, before, after)
+	}
+}
+
+func TestTaskConfigValidateGetLogsOptions(t *testing.T) {
+	tail := int64(-1)
+	cases := map[string]struct {
+		cfg     taskConfig
+		wantErr string
+	}{
+		"valid": {
+			cfg: taskConfig{Context: "dev", Operation: OperationGetLogs, Pods: []string{"web"}, SinceDuration: "15m", Follow: true},
+		},
+		"invalid duration": {
+			cfg:     taskConfig{Context: "dev", Operation: OperationGetLogs, Pods: []string{"web"}, SinceDuration: "soon"},
+			wantErr: "since_duration",
+		},
+		"both since": {
+			cfg:     taskConfig{Context: "dev", Operation: OperationGetLogs, Pods: []string{"web"}, SinceDuration: "1h", SinceTime: "2024-01-01T00:00:00Z"},
+			wantErr: "since_time or since_duration",
+		},
+		"negative tail": {
+			cfg:     taskConfig{Context: "dev", Operation: OperationGetLogs, Pods: []string{"web"}, TailLines: &tail},
+			wantErr: "tail_lines",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() error = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetLogsFollowStreamsToFileAndLogger(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}
+	client := fake.NewSimpleClientset(pod)
+	logger := &recordingLogger{}
+	dir := t.TempDir()
+
+	logs, err := getLogs(context.Background(), client, "default", Config{Pods: []string{"web-0"}, Follow: true, LogDir: dir}, logger)
+	if err != nil {
+		t.Fatalf("getLogs() error = %v", err)
+	}
+	if len(logs) != 1 || logs[0].Container != "app" {
+		t.Fatalf("getLogs() = %+v, want one entry for container app", logs)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, logs[0].File))
+	if err != nil {
+		t.Fatalf("reading log file: %v", err)
+	}
+	if string(data) != "fake logs" {
+		t.Fatalf("log file = %q, want %q", data, "fake logs")
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.messages) != 1 || logger.messages[0] != "Kubernetes: [web-0/app] fake logs" {
+		t.Fatalf("logger messages = %v", logger.messages)
+	}
+}
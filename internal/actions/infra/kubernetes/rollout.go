@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// restartedAtAnnotation is the pod template annotation kubectl rollout
+// restart bumps to roll every pod without changing the spec.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// restartableKinds lists the kinds accepted by ROLLOUT_RESTART operations.
+var restartableKinds = []string{workloadKindDeployment, workloadKindStatefulSet}
+
+// RolloutRestartResult reports the outcome of a ROLLOUT_RESTART operation.
+type RolloutRestartResult struct {
+	Namespace   string                     `json:"namespace"`
+	Kind        string                     `json:"kind"`
+	Names       []string                   `json:"names"`
+	RestartedAt string                     `json:"restartedAt"`
+	Readiness   *WaitForPodReadinessResult `json:"readiness,omitempty"`
+}
+
+// rolloutRestart stamps the restartedAt annotation on the pod template of each
+// named workload, like kubectl rollout restart, and optionally waits for the
+// new pods to become ready.
+func rolloutRestart(ctx context.Context, client kubernetes.Interface, namespace string, cfg Config, logger Logger) (RolloutRestartResult, error) {
+	kind := workloadKind(cfg.Kind)
+	restartedAt := time.Now().Format(time.RFC3339)
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{restartedAtAnnotation: restartedAt},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return RolloutRestartResult{}, fmt.Errorf("kubernetes: encoding restart patch: %w", err)
+	}
+
+	result := RolloutRestartResult{
+		Namespace:   namespace,
+		Kind:        kind,
+		Names:       make([]string, 0, len(cfg.Names)),
+		RestartedAt: restartedAt,
+	}
+	for _, name := range cfg.Names {
+		switch kind {
+		case workloadKindDeployment:
+			_, err = client.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		case workloadKindStatefulSet:
+			_, err = client.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		default:
+			return RolloutRestartResult{}, fmt.Errorf("kubernetes ROLLOUT_RESTART operation: unsupported kind %q", kind)
+		}
+		if err != nil {
+			return RolloutRestartResult{}, fmt.Errorf("kubernetes: restarting %s %s in namespace %s: %w", kind, name, namespace, err)
+		}
+		if logger != nil {
+			logger.Printf("Kubernetes: restarted %s %s in namespace %s", kind, name, namespace)
+		}
+		result.Names = append(result.Names, name)
+	}
+
+	if !cfg.Wait {
+		return result, nil
+	}
+
+	readiness, err := waitForWorkloads(ctx, client, namespace, OperationRolloutRestart, kind, cfg.Names, true, cfg, logger)
+	if err != nil {
+		return RolloutRestartResult{}, err
+	}
+	result.Readiness = &readiness
+	return result, nil
+}
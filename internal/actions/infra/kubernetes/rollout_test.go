@@ -0,0 +1,131 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestTaskConfigValidateRolloutRestart(t *testing.T) {
+	valid := taskConfig{Context: "example", Namespace: "apps", Operation: OperationRolloutRestart, Names: []string{"web"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cases := map[string]struct {
+		mutate func(*taskConfig)
+		want   string
+	}{
+		"missing namespace": {func(c *taskConfig) { c.Namespace = "" }, "namespace is required"},
+		"unknown kind":      {func(c *taskConfig) { c.Kind = "daemonset" }, "kind must be one of"},
+		"no names":          {func(c *taskConfig) { c.Names = nil }, "at least one name"},
+		"wait without max": {func(c *taskConfig) {
+			c.Wait = true
+			c.PollIntervalSeconds = 1
+		}, "max_wait_seconds"},
+		"wait without poll": {func(c *taskConfig) {
+			c.Wait = true
+			c.MaxWaitSeconds = 30
+		}, "poll_interval_seconds"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			tc.mutate(&cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRolloutRestartDeployments(t *testing.T) {
+	deployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}}},
+			},
+		}
+	}
+	client := fake.NewSimpleClientset(deployment("web"), deployment("api"))
+
+	result, err := rolloutRestart(context.Background(), client, "apps", Config{Names: []string{"web", "api"}}, nil)
+	if err != nil {
+		t.Fatalf("rolloutRestart() error = %v", err)
+	}
+	if result.Kind != "deployment" || strings.Join(result.Names, ",") != "web,api" || result.Readiness != nil {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if _, err := time.Parse(time.RFC3339, result.RestartedAt); err != nil {
+		t.Fatalf("RestartedAt = %q, want RFC3339: %v", result.RestartedAt, err)
+	}
+
+	for _, name := range []string{"web", "api"} {
+		dep, err := client.AppsV1().Deployments("apps").Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Get deployment %s error = %v", name, err)
+		}
+		if got := dep.Spec.Template.Annotations[restartedAtAnnotation]; got != result.RestartedAt {
+			t.Fatalf("deployment %s restartedAt = %q, want %q", name, got, result.RestartedAt)
+		}
+		if dep.Spec.Template.Labels["app"] != name {
+			t.Fatalf("deployment %s template labels changed: %v", name, dep.Spec.Template.Labels)
+		}
+	}
+
+	if _, err := rolloutRestart(context.Background(), client, "apps", Config{Names: []string{"missing"}}, nil); err == nil {
+		t.Fatal("rolloutRestart() error = nil, want not found error")
+	}
+}
+
+func TestRolloutRestartStatefulSetWaitsForUpdatedPods(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps", Generation: 2},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: pointer.Int32(1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		},
+		Status: appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 1},
+	}
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "apps", Labels: map[string]string{"app": "db"}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	client := fake.NewSimpleClientset(statefulSet, readyPod)
+
+	cfg := Config{Kind: "statefulset", Names: []string{"db"}, Wait: true, MaxWait: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	_, err := rolloutRestart(context.Background(), client, "apps", cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "ROLLOUT_RESTART operation: timeout") || !strings.Contains(err.Error(), "statefulsets") {
+		t.Fatalf("rolloutRestart() error = %v, want timeout while the old pod is still current", err)
+	}
+
+	updated, _ := client.AppsV1().StatefulSets("apps").Get(context.Background(), "db", metav1.GetOptions{})
+	updated.Status.UpdatedReplicas = 1
+	if _, err := client.AppsV1().StatefulSets("apps").UpdateStatus(context.Background(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+
+	result, err := rolloutRestart(context.Background(), client, "apps", cfg, nil)
+	if err != nil {
+		t.Fatalf("rolloutRestart() error = %v", err)
+	}
+	if result.Kind != "statefulset" || result.Readiness == nil || !result.Readiness.Succeeded {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	restarted, _ := client.AppsV1().StatefulSets("apps").Get(context.Background(), "db", metav1.GetOptions{})
+	if restarted.Spec.Template.Annotations[restartedAtAnnotation] != result.RestartedAt {
+		t.Fatalf("statefulset annotations = %v, want restartedAt %s", restarted.Spec.Template.Annotations, result.RestartedAt)
+	}
+}
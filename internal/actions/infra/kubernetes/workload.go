@@ -0,0 +1,113 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	workloadKindDeployment  = "deployment"
+	workloadKindStatefulSet = "statefulset"
+	workloadKindDaemonSet   = "daemonset"
+)
+
+var (
+	// scalableKinds lists the kinds accepted by SCALE operations.
+	scalableKinds = []string{workloadKindDeployment, workloadKindStatefulSet}
+	// waitableKinds lists the kinds accepted by WAIT_FOR_POD_READINESS operations.
+	waitableKinds = []string{workloadKindDeployment, workloadKindStatefulSet, workloadKindDaemonSet}
+)
+
+// workloadKind returns the controller kind targeted by an operation,
+// defaulting to deployments.
+func workloadKind(kind string) string {
+	if kind = normalizeKind(kind); kind == "" {
+		return workloadKindDeployment
+	}
+	return kind
+}
+
+func containsKind(kinds []string, kind string) bool {
+	for _, candidate := range kinds {
+		if candidate == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// scaleWorkload sets the replica count of a deployment or statefulset,
+// retrying on update conflicts.
+func scaleWorkload(ctx context.Context, client kubernetes.Interface, namespace, kind, name string, replicas int32) (ScaleResult, error) {
+	var (
+		result  ScaleResult
+		changed bool
+	)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, update, err := scaleTarget(ctx, client, namespace, kind, name)
+		if err != nil {
+			return err
+		}
+
+		result.Namespace = namespace
+		result.Kind = kind
+		result.Deployment = name
+		result.PreviousReplicas = current
+		result.DesiredReplicas = replicas
+
+		if current == replicas {
+			changed = false
+			return nil
+		}
+
+		if err := update(replicas); err != nil {
+			return err
+		}
+
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return ScaleResult{}, fmt.Errorf("kubernetes: scaling %s %s in namespace %s: %w", kind, name, namespace, err)
+	}
+
+	result.Changed = changed
+	return result, nil
+}
+
+// scaleTarget fetches a scalable workload and returns its current replica
+// count together with a function that stores a new one.
+func scaleTarget(ctx context.Context, client kubernetes.Interface, namespace, kind, name string) (int32, func(int32) error, error) {
+	switch kind {
+	case workloadKindDeployment:
+		api := client.AppsV1().Deployments(namespace)
+		deployment, err := api.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, nil, err
+		}
+		return pointer.Int32Deref(deployment.Spec.Replicas, 0), func(replicas int32) error {
+			deployment.Spec.Replicas = pointer.Int32(replicas)
+			_, err := api.Update(ctx, deployment, metav1.UpdateOptions{})
+			return err
+		}, nil
+	case workloadKindStatefulSet:
+		api := client.AppsV1().StatefulSets(namespace)
+		statefulSet, err := api.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, nil, err
+		}
+		return pointer.Int32Deref(statefulSet.Spec.Replicas, 0), func(replicas int32) error {
+			statefulSet.Spec.Replicas = pointer.Int32(replicas)
+			_, err := api.Update(ctx, statefulSet, metav1.UpdateOptions{})
+			return err
+		}, nil
+	default:
+		return 0, nil, fmt.Errorf("unsupported kind %q (expected one of %v)", kind, scalableKinds)
+	}
+}
@@ -0,0 +1,352 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+const (
+	// ActionName identifies the HTTP action in the flow definition.
+	ActionName = "HTTP"
+
+	defaultTimeoutSeconds = 30
+)
+
+// maxResponseBytes bounds the response body kept for each step, so a large
+// download cannot exhaust memory or bloat the task log.
+var maxResponseBytes int64 = 10 << 20
+
+func init() {
+	registry.Register(&Action{})
+}
+
+// Action performs a sequence of REST calls.
+type Action struct{}
+
+// Name returns the registry identifier for the HTTP action.
+func (Action) Name() string {
+	return ActionName
+}
+
+// Execute runs the declared requests in order and stops at the first failure.
+func (Action) Execute(ctx context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	var spec payloadSpec
+	if err := json.Unmarshal(payload, &spec); err != nil {
+		return registry.Result{}, fmt.Errorf("http: decode payload: %w", err)
+	}
+	if err := spec.validate(); err != nil {
+		return registry.Result{}, err
+	}
+
+	results := make([]stepResult, 0, len(spec.Steps))
+	for idx, step := range spec.Steps {
+		select {
+		case <-ctx.Done():
+			return registry.Result{}, ctx.Err()
+		default:
+		}
+
+		result, err := executeStep(ctx, idx, step, execCtx)
+		if err != nil {
+			return registry.Result{}, err
+		}
+		results = append(results, result)
+	}
+
+	return registry.Result{Value: map[string]any{
+		"steps": results,
+	}, Type: flow.ResultTypeJSON}, nil
+}
+
+type payloadSpec struct {
+	Steps []requestStep `json:"steps"`
+}
+
+type requestStep struct {
+	ID             string            `json:"id"`
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers"`
+	Query          map[string]string `json:"query"`
+	Body           json.RawMessage   `json:"body"`
+	BodyFromVar    string            `json:"bodyFromVar"`
+	TimeoutSeconds *float64          `json:"timeoutSeconds"`
+	ExpectedStatus []int             `json:"expectedStatus"`
+	BasicAuth      *basicAuth        `json:"basicAuth"`
+	BearerToken    string            `json:"bearerToken"`
+}
+
+type basicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type stepResult struct {
+	ID      string            `json:"id,omitempty"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    any               `json:"body"`
+}
+
+func (p *payloadSpec) validate() error {
+	if len(p.Steps) == 0 {
+		return errors.New("http: at least one step must be declared")
+	}
+	for idx, step := range p.Steps {
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("http: step %d: %w", idx, err)
+		}
+	}
+	return nil
+}
+
+func (s *requestStep) validate() error {
+	if strings.TrimSpace(s.URL) == "" {
+		return errors.New("url is required")
+	}
+	switch s.method() {
+	case nethttp.MethodGet, nethttp.MethodHead, nethttp.MethodPost, nethttp.MethodPut, nethttp.MethodPatch, nethttp.MethodDelete, nethttp.MethodOptions:
+	default:
+		return fmt.Errorf("unsupported method %q", s.Method)
+	}
+	if hasBody(s.Body) && strings.TrimSpace(s.BodyFromVar) != "" {
+		return errors.New("body and bodyFromVar cannot be used together")
+	}
+	if s.BasicAuth != nil && strings.TrimSpace(s.BearerToken) != "" {
+		return errors.New("basicAuth and bearerToken cannot be used together")
+	}
+	if s.BasicAuth != nil && strings.TrimSpace(s.BasicAuth.Username) == "" {
+		return errors.New("basicAuth.username is required")
+	}
+	if s.TimeoutSeconds != nil && *s.TimeoutSeconds < 0 {
+		return errors.New("timeoutSeconds must be non-negative")
+	}
+	for _, status := range s.ExpectedStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("expectedStatus %d is not a valid HTTP status", status)
+		}
+	}
+	return nil
+}
+
+func (s *requestStep) method() string {
+	method := strings.ToUpper(strings.TrimSpace(s.Method))
+	if method == "" {
+		return nethttp.MethodGet
+	}
+	return method
+}
+
+func executeStep(ctx context.Context, idx int, step requestStep, execCtx *registry.ExecutionContext) (stepResult, error) {
+	method := step.method()
+	target, err := buildURL(step.URL, step.Query)
+	if err != nil {
+		return stepResult{}, fmt.Errorf("http: step %d: %w", idx, err)
+	}
+
+	body, contentType, err := requestBody(step, execCtx)
+	if err != nil {
+		return stepResult{}, fmt.Errorf("http: step %d: %w", idx, err)
+	}
+
+	timeout := defaultTimeoutSeconds * time.Second
+	if step.TimeoutSeconds != nil && *step.TimeoutSeconds > 0 {
+		timeout = time.Duration(*step.TimeoutSeconds * float64(time.Second))
+	}
+	req, err := nethttp.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return stepResult{}, fmt.Errorf("http: step %d: creating request: %w", idx, err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range step.Headers {
+		req.Header.Set(name, value)
+	}
+	switch {
+	case step.BasicAuth != nil:
+		req.SetBasicAuth(step.BasicAuth.Username, step.BasicAuth.Password)
+	case strings.TrimSpace(step.BearerToken) != "":
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(step.BearerToken))
+	}
+
+	if execCtx != nil && execCtx.Logger != nil {
+		execCtx.Logger.Printf("HTTP: %s %s", method, target)
+	}
+
+	resp, err := newClient(timeout).Do(req)
+	if err != nil {
+		return stepResult{}, fmt.Errorf("http: step %d: %s %s: %w", idx, method, target, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return stepResult{}, fmt.Errorf("http: step %d: reading response body: %w", idx, err)
+	}
+	if int64(len(data)) > maxResponseBytes {
+		return stepResult{}, fmt.Errorf("http: step %d: %s %s: response body exceeds %d bytes", idx, method, target, maxResponseBytes)
+	}
+
+	if execCtx != nil && execCtx.Logger != nil {
+		execCtx.Logger.Printf("HTTP: %s %s returned %d", method, target, resp.StatusCode)
+	}
+
+	if !statusExpected(resp.StatusCode, step.ExpectedStatus) {
+		return stepResult{}, fmt.Errorf("http: step %d: %s %s returned unexpected status %d: %s", idx, method, target, resp.StatusCode, excerpt(data))
+	}
+
+	return stepResult{
+		ID:      step.ID,
+		Method:  method,
+		URL:     target,
+		Status:  resp.StatusCode,
+		Headers: flattenHeaders(resp.Header),
+		Body:    decodeBody(resp.Header.Get("Content-Type"), data),
+	}, nil
+}
+
+// newClient builds the client for one step the way HTTP_REQUEST does: the
+// proxy comes from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables and the timeout covers the whole exchange, body included.
+func newClient(timeout time.Duration) *nethttp.Client {
+	return &nethttp.Client{
+		Transport: &nethttp.Transport{Proxy: nethttp.ProxyFromEnvironment},
+		Timeout:   timeout,
+	}
+}
+
+// excerpt shortens a response body for error messages.
+func excerpt(data []byte) string {
+	const limit = 256
+	text := strings.TrimSpace(string(data))
+	if len(text) > limit {
+		return text[:limit] + "..."
+	}
+	return text
+}
+
+func buildURL(raw string, query map[string]string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("parsing url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("url %q must use the http or https scheme", raw)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("url %q must include a host", raw)
+	}
+	if len(query) > 0 {
+		values := parsed.Query()
+		for name, value := range query {
+			values.Set(name, value)
+		}
+		parsed.RawQuery = values.Encode()
+	}
+	return parsed.String(), nil
+}
+
+// requestBody returns the payload to send together with the content type
+// implied by it. Strings are sent as they are while any other JSON value is
+// sent encoded as application/json.
+func requestBody(step requestStep, execCtx *registry.ExecutionContext) ([]byte, string, error) {
+	if name := strings.TrimSpace(step.BodyFromVar); name != "" {
+		if execCtx == nil {
+			return nil, "", fmt.Errorf("bodyFromVar %q: variable not found", name)
+		}
+		variable, ok := execCtx.Variables[name]
+		if !ok {
+			return nil, "", fmt.Errorf("bodyFromVar %q: variable not found", name)
+		}
+		return encodeBody(variable.Value)
+	}
+
+	if !hasBody(step.Body) {
+		return nil, "", nil
+	}
+	var value any
+	if err := json.Unmarshal(step.Body, &value); err != nil {
+		return nil, "", fmt.Errorf("decoding body: %w", err)
+	}
+	return encodeBody(value)
+}
+
+func encodeBody(value any) ([]byte, string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		return []byte(v), "", nil
+	case []byte:
+		return v, "", nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("encoding body: %w", err)
+	}
+	return data, "application/json", nil
+}
+
+func hasBody(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null"))
+}
+
+// decodeBody parses JSON responses and returns any other body as text.
+func decodeBody(contentType string, data []byte) any {
+	if len(data) == 0 {
+		return ""
+	}
+	if isJSONContentType(contentType) {
+		var value any
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value
+		}
+	}
+	return string(data)
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func flattenHeaders(header nethttp.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// statusExpected reports whether status is accepted, defaulting to any 2xx
+// status when none are listed.
+func statusExpected(status int, expected []int) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, candidate := range expected {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"flowk/internal/actions/registry"
+)
+
+func runAction(t *testing.T, payload string, execCtx *registry.ExecutionContext) ([]stepResult, error) {
+	t.Helper()
+
+	result, err := Action{}.Execute(context.Background(), json.RawMessage(payload), execCtx)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := result.Value.(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected result value %T", result.Value)
+	}
+	steps, ok := value["steps"].([]stepResult)
+	if !ok {
+		t.Fatalf("unexpected steps value %T", value["steps"])
+	}
+	return steps, nil
+}
+
+func TestActionExecutesStepsAndParsesJSON(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		switch r.URL.Path {
+		case "/deployments":
+			if got := r.Header.Get("Authorization"); got != "Bearer secret-token" {
+				t.Errorf("Authorization = %q", got)
+			}
+			if got := r.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"replicas":3,"service":"api"}` {
+				t.Errorf("body = %s", body)
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(nethttp.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"d-1","state":"pending"}`))
+		case "/health":
+			if got := r.URL.Query().Get("verbose"); got != "true" {
+				t.Errorf("verbose query = %q", got)
+			}
+			if user, pass, ok := r.BasicAuth(); !ok || user != "ops" || pass != "pw" {
+				t.Errorf("basic auth = %q %q %v", user, pass, ok)
+			}
+			w.Header().Set("X-Check", "ok")
+			_, _ = w.Write([]byte("healthy"))
+		default:
+			nethttp.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	payload := `{"steps": [
+		{"id": "deploy", "method": "post", "url": "` + server.URL + `/deployments", "bearerToken": "secret-token", "body": {"service": "api", "replicas": 3}, "expectedStatus": [201]},
+		{"url": "` + server.URL + `/health", "query": {"verbose": "true"}, "basicAuth": {"username": "ops", "password": "pw"}}
+	]}`
+	steps, err := runAction(t, payload, &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(steps))
+	}
+
+	deploy := steps[0]
+	if deploy.ID != "deploy" || deploy.Method != "POST" || deploy.Status != nethttp.StatusCreated {
+		t.Fatalf("unexpected deploy step: %+v", deploy)
+	}
+	body, ok := deploy.Body.(map[string]any)
+	if !ok || body["id"] != "d-1" {
+		t.Fatalf("expected parsed JSON body, got %#v", deploy.Body)
+	}
+
+	health := steps[1]
+	if health.Method != "GET" || health.Status != nethttp.StatusOK || health.Body != "healthy" || health.Headers["X-Check"] != "ok" {
+		t.Fatalf("unexpected health step: %+v", health)
+	}
+	if !strings.HasSuffix(health.URL, "/health?verbose=true") {
+		t.Fatalf("unexpected health url %q", health.URL)
+	}
+}
+
+func TestActionBodyFromVar(t *testing.T) {
+	var received string
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = r.Header.Get("Content-Type") + " " + string(body)
+		w.WriteHeader(nethttp.StatusNoContent)
+	}))
+	defer server.Close()
+
+	execCtx := &registry.ExecutionContext{Variables: map[string]registry.Variable{
+		"event": {Name: "event", Type: "object", Value: map[string]any{"status": "done"}},
+	}}
+	payload := `{"steps": [{"method": "PUT", "url": "` + server.URL + `/hook", "bodyFromVar": "event"}]}`
+	if _, err := runAction(t, payload, execCtx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if received != `application/json {"status":"done"}` {
+		t.Fatalf("unexpected request: %q", received)
+	}
+
+	payload = `{"steps": [{"method": "PUT", "url": "` + server.URL + `/hook", "bodyFromVar": "missing"}]}`
+	if _, err := runAction(t, payload, execCtx); err == nil || !strings.Contains(err.Error(), "variable not found") {
+		t.Fatalf("expected missing variable error, got %v", err)
+	}
+}
+
+func TestActionFailsOnUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		nethttp.Error(w, "boom", nethttp.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	payload := `{"steps": [{"url": "` + server.URL + `/"}]}`
+	if _, err := runAction(t, payload, &registry.ExecutionContext{}); err == nil || !strings.Contains(err.Error(), "unexpected status 502: boom") {
+		t.Fatalf("expected unexpected status error, got %v", err)
+	}
+}
+
+func TestActionLimitsResponseBody(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		io.WriteString(w, strings.Repeat("x", 64))
+	}))
+	defer server.Close()
+
+	previous := maxResponseBytes
+	maxResponseBytes = 32
+	defer func() { maxResponseBytes = previous }()
+
+	payload := `{"steps": [{"url": "` + server.URL + `/large"}]}`
+	if _, err := runAction(t, payload, &registry.ExecutionContext{}); err == nil || !strings.Contains(err.Error(), "response body exceeds 32 bytes") {
+		t.Fatalf("expected body limit error, got %v", err)
+	}
+}
+
+func TestActionTimesOutSlowResponses(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	payload := `{"steps": [{"url": "` + server.URL + `/slow", "timeoutSeconds": 0.05}]}`
+	if _, err := runAction(t, payload, &registry.ExecutionContext{}); err == nil || !strings.Contains(err.Error(), "Client.Timeout exceeded") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestActionValidation(t *testing.T) {
+	cases := map[string]struct {
+		payload string
+		want    string
+	}{
+		"no steps":         {`{"steps": []}`, "at least one step"},
+		"missing url":      {`{"steps": [{"method": "GET"}]}`, "url is required"},
+		"bad method":       {`{"steps": [{"method": "TRACE", "url": "http://example.com"}]}`, "unsupported method"},
+		"body and var":     {`{"steps": [{"url": "http://example.com", "body": "x", "bodyFromVar": "v"}]}`, "cannot be used together"},
+		"two auth methods": {`{"steps": [{"url": "http://example.com", "basicAuth": {"username": "u"}, "bearerToken": "t"}]}`, "cannot be used together"},
+		"bad scheme":       {`{"steps": [{"url": "ftp://example.com"}]}`, "http or https scheme"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := runAction(t, tc.payload, &registry.ExecutionContext{}); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Execute() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	netsmtp "net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+)
+
+const (
+	// ActionName identifies the EMAIL action in flow definitions.
+	ActionName = "EMAIL"
+
+	SecuritySTARTTLS = "STARTTLS"
+	SecurityTLS      = "TLS"
+	SecurityNone     = "NONE"
+
+	defaultTimeoutSeconds = 30
+)
+
+func init() {
+	registry.Register(action{})
+}
+
+type action struct{}
+
+func (action) Name() string { return ActionName }
+
+// Execute sends the configured message and returns the recipients accepted by
+// the server. Recipients rejected individually are reported without failing
+// the task as long as at least one was accepted.
+func (action) Execute(ctx context.Context, payload json.RawMessage, execCtx *registry.ExecutionContext) (registry.Result, error) {
+	var task taskConfig
+	if err := json.Unmarshal(payload, &task); err != nil {
+		return registry.Result{}, fmt.Errorf("email: decode payload: %w", err)
+	}
+	if err := task.validate(); err != nil {
+		return registry.Result{}, err
+	}
+
+	message, messageID, err := buildMessage(task, time.Now())
+	if err != nil {
+		return registry.Result{}, err
+	}
+
+	if execCtx != nil && execCtx.Logger != nil {
+		execCtx.Logger.Printf("EMAIL: sending %q to %d recipient(s) through %s", task.Subject, len(task.recipients()), task.address())
+	}
+
+	result, err := send(ctx, task, message)
+	if err != nil {
+		return registry.Result{}, err
+	}
+	result.MessageID = messageID
+
+	if execCtx != nil && execCtx.Logger != nil {
+		execCtx.Logger.Printf("EMAIL: accepted %d recipient(s), rejected %d", len(result.Accepted), len(result.Rejected))
+	}
+	return registry.Result{Value: result, Type: flow.ResultTypeJSON}, nil
+}
+
+type taskConfig struct {
+	Host               string       `json:"host"`
+	Port               int          `json:"port"`
+	Security           string       `json:"security"`
+	InsecureSkipVerify bool         `json:"insecureSkipVerify"`
+	Username           string       `json:"username"`
+	Password           string       `json:"password"`
+	From               string       `json:"from"`
+	To                 []string     `json:"to"`
+	Cc                 []string     `json:"cc"`
+	Bcc                []string     `json:"bcc"`
+	Subject            string       `json:"subject"`
+	Body               string       `json:"body"`
+	HTML               bool         `json:"html"`
+	Attachments        []attachment `json:"attachments"`
+	TimeoutSeconds     float64      `json:"timeoutSeconds"`
+}
+
+type attachment struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+}
+
+// sendResult is the value returned by the action.
+type sendResult struct {
+	MessageID string            `json:"message_id"`
+	Accepted  []string          `json:"accepted"`
+	Rejected  map[string]string `json:"rejected,omitempty"`
+}
+
+func (t *taskConfig) validate() error {
+	t.Host = strings.TrimSpace(t.Host)
+	if t.Host == "" {
+		return errors.New("email: host is required")
+	}
+	t.Security = strings.ToUpper(strings.TrimSpace(t.Security))
+	if t.Security == "" {
+		t.Security = SecuritySTARTTLS
+	}
+	switch t.Security {
+	case SecuritySTARTTLS, SecurityTLS, SecurityNone:
+	default:
+		return fmt.Errorf("email: unsupported security %q", t.Security)
+	}
+	if t.Port == 0 {
+		t.Port = defaultPort(t.Security)
+	}
+	if t.Port < 1 || t.Port > 65535 {
+		return fmt.Errorf("email: port %d is out of range", t.Port)
+	}
+	if strings.TrimSpace(t.From) == "" {
+		return errors.New("email: from is required")
+	}
+	if len(t.To) == 0 {
+		return errors.New("email: at least one to recipient is required")
+	}
+	for _, addr := range append([]string{t.From}, t.recipients()...) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("email: invalid address %q: %w", addr, err)
+		}
+	}
+	if strings.ContainsAny(t.Subject, "\r\n") {
+		return errors.New("email: subject cannot contain line breaks")
+	}
+	if t.Password != "" && strings.TrimSpace(t.Username) == "" {
+		return errors.New("email: username is required when password is set")
+	}
+	for i, att := range t.Attachments {
+		if strings.TrimSpace(att.Path) == "" {
+			return fmt.Errorf("email: attachments[%d]: path is required", i)
+		}
+	}
+	if t.TimeoutSeconds < 0 {
+		return errors.New("email: timeoutSeconds cannot be negative")
+	}
+	return nil
+}
+
+func defaultPort(security string) int {
+	switch security {
+	case SecurityTLS:
+		return 465
+	case SecurityNone:
+		return 25
+	default:
+		return 587
+	}
+}
+
+func (t taskConfig) address() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// recipients returns every envelope recipient, including Bcc.
+func (t taskConfig) recipients() []string {
+	all := make([]string, 0, len(t.To)+len(t.Cc)+len(t.Bcc))
+	all = append(all, t.To...)
+	all = append(all, t.Cc...)
+	return append(all, t.Bcc...)
+}
+
+func (t taskConfig) timeout() time.Duration {
+	if t.TimeoutSeconds > 0 {
+		return time.Duration(t.TimeoutSeconds * float64(time.Second))
+	}
+	return defaultTimeoutSeconds * time.Second
+}
+
+func send(ctx context.Context, task taskConfig, message []byte) (sendResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, task.timeout())
+	defer cancel()
+
+	tlsConfig := &tls.Config{ServerName: task.Host, InsecureSkipVerify: task.InsecureSkipVerify}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", task.address())
+	if err != nil {
+		return sendResult{}, fmt.Errorf("email: connecting to %s: %w", task.address(), err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	// Closing the connection interrupts any pending exchange once ctx ends.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if task.Security == SecurityTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := netsmtp.NewClient(conn, task.Host)
+	if err != nil {
+		conn.Close()
+		return sendResult{}, fmt.Errorf("email: %s: %w", task.address(), contextError(ctx, err))
+	}
+	defer client.Close()
+
+	if task.Security == SecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return sendResult{}, fmt.Errorf("email: %s does not support STARTTLS; set security to TLS or NONE", task.address())
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return sendResult{}, fmt.Errorf("email: starting TLS: %w", contextError(ctx, err))
+		}
+	}
+
+	if username := strings.TrimSpace(task.Username); username != "" {
+		if err := client.Auth(netsmtp.PlainAuth("", username, task.Password, task.Host)); err != nil {
+			return sendResult{}, fmt.Errorf("email: authenticating as %s: %w", username, contextError(ctx, err))
+		}
+	}
+
+	if err := client.Mail(addressOnly(task.From)); err != nil {
+		return sendResult{}, fmt.Errorf("email: sender %s rejected: %w", task.From, contextError(ctx, err))
+	}
+
+	result := sendResult{Accepted: []string{}}
+	for _, rcpt := range task.recipients() {
+		addr := addressOnly(rcpt)
+		if err := client.Rcpt(addr); err != nil {
+			if ctx.Err() != nil {
+				return sendResult{}, fmt.Errorf("email: %w", ctx.Err())
+			}
+			if result.Rejected == nil {
+				result.Rejected = make(map[string]string)
+			}
+			result.Rejected[addr] = err.Error()
+			continue
+		}
+		result.Accepted = append(result.Accepted, addr)
+	}
+	if len(result.Accepted) == 0 {
+		return sendResult{}, fmt.Errorf("email: every recipient was rejected: %v", result.Rejected)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return sendResult{}, fmt.Errorf("email: starting message data: %w", contextError(ctx, err))
+	}
+	if _, err := writer.Write(message); err != nil {
+		return sendResult{}, fmt.Errorf("email: writing message: %w", contextError(ctx, err))
+	}
+	if err := writer.Close(); err != nil {
+		return sendResult{}, fmt.Errorf("email: message rejected: %w", contextError(ctx, err))
+	}
+	_ = client.Quit()
+
+	return result, nil
+}
+
+// contextError prefers the context error so timeouts and cancellations are
+// not reported as the network errors they cause.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
@@ -0,0 +1,273 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload map[string]any
+		wantErr string
+	}{
+		{name: "missing host", payload: map[string]any{"from": "a@example.com", "to": []string{"b@example.com"}}, wantErr: "host is required"},
+		{name: "bad security", payload: map[string]any{"host": "mail", "security": "SSL", "from": "a@example.com", "to": []string{"b@example.com"}}, wantErr: "unsupported security"},
+		{name: "missing from", payload: map[string]any{"host": "mail", "to": []string{"b@example.com"}}, wantErr: "from is required"},
+		{name: "missing to", payload: map[string]any{"host": "mail", "from": "a@example.com"}, wantErr: "at least one to recipient"},
+		{name: "invalid address", payload: map[string]any{"host": "mail", "from": "a@example.com", "to": []string{"not an address"}}, wantErr: "invalid address"},
+		{name: "header injection", payload: map[string]any{"host": "mail", "from": "a@example.com", "to": []string{"b@example.com"}, "subject": "hi\r\nBcc: x@example.com"}, wantErr: "line breaks"},
+		{name: "password without username", payload: map[string]any{"host": "mail", "from": "a@example.com", "to": []string{"b@example.com"}, "password": "secret"}, wantErr: "username is required"},
+		{name: "attachment without path", payload: map[string]any{"host": "mail", "from": "a@example.com", "to": []string{"b@example.com"}, "attachments": []map[string]any{{"name": "x"}}}, wantErr: "attachments[0]: path is required"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, _ := json.Marshal(tc.payload)
+			_, err := (action{}).Execute(context.Background(), raw, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDefaultsPort(t *testing.T) {
+	for security, want := range map[string]int{"": 587, "starttls": 587, "TLS": 465, "NONE": 25} {
+		task := taskConfig{Host: "mail", Security: security, From: "a@example.com", To: []string{"b@example.com"}}
+		if err := task.validate(); err != nil {
+			t.Fatalf("validate(%q) error = %v", security, err)
+		}
+		if task.Port != want {
+			t.Fatalf("port for %q = %d, want %d", security, task.Port, want)
+		}
+	}
+}
+
+// fakeServer is a minimal SMTP server that records the envelope and message
+// of a single session and rejects recipients listed in reject.
+type fakeServer struct {
+	listener net.Listener
+	reject   map[string]bool
+
+	mu      sync.Mutex
+	auth    string
+	from    string
+	rcpts   []string
+	message string
+}
+
+func newFakeServer(t *testing.T, reject ...string) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeServer{listener: listener, reject: map[string]bool{}}
+	for _, addr := range reject {
+		server.reject[addr] = true
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *fakeServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 fake ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			s.mu.Lock()
+			s.auth = line
+			s.mu.Unlock()
+			reply("235 ok")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = line
+			s.mu.Unlock()
+			reply("250 ok")
+		case "RCPT":
+			addr := strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")
+			if s.reject[addr] {
+				reply("550 no such user")
+				continue
+			}
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, addr)
+			s.mu.Unlock()
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.message = data.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestExecuteSendsMessageWithAttachment(t *testing.T) {
+	server := newFakeServer(t, "missing@example.com")
+
+	report := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(report, []byte("all tasks passed"), 0o600); err != nil {
+		t.Fatalf("write attachment: %v", err)
+	}
+
+	payload := map[string]any{
+		"host":        "127.0.0.1",
+		"port":        server.port(),
+		"security":    "NONE",
+		"username":    "flowk",
+		"password":    "secret",
+		"from":        "FlowK <flowk@example.com>",
+		"to":          []string{"ops@example.com", "missing@example.com"},
+		"bcc":         []string{"audit@example.com"},
+		"subject":     "Deploy finished",
+		"body":        "The deploy finished.\nSee the attached report.",
+		"attachments": []map[string]any{{"path": report}},
+	}
+	raw, _ := json.Marshal(payload)
+
+	res, err := (action{}).Execute(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	result := res.Value.(sendResult)
+	if strings.Join(result.Accepted, ",") != "ops@example.com,audit@example.com" {
+		t.Fatalf("accepted = %v", result.Accepted)
+	}
+	if _, ok := result.Rejected["missing@example.com"]; !ok || len(result.Rejected) != 1 {
+		t.Fatalf("rejected = %v", result.Rejected)
+	}
+	if !strings.HasSuffix(result.MessageID, "@example.com>") {
+		t.Fatalf("message id = %q", result.MessageID)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	wantAuth := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00flowk\x00secret"))
+	if server.auth != wantAuth {
+		t.Fatalf("auth = %q, want %q", server.auth, wantAuth)
+	}
+	if server.from != "MAIL FROM:<flowk@example.com>" {
+		t.Fatalf("from = %q", server.from)
+	}
+	for _, want := range []string{
+		"From: \"FlowK\" <flowk@example.com>\r\n",
+		"To: <ops@example.com>, <missing@example.com>\r\n",
+		"Subject: Deploy finished\r\n",
+		"Message-ID: " + result.MessageID + "\r\n",
+		"Content-Type: multipart/mixed; boundary=",
+		"The deploy finished.\r\nSee the attached report.",
+		"Content-Disposition: attachment; filename=report.txt",
+		base64.StdEncoding.EncodeToString([]byte("all tasks passed")),
+	} {
+		if !strings.Contains(server.message, want) {
+			t.Fatalf("message missing %q:\n%s", want, server.message)
+		}
+	}
+	if strings.Contains(server.message, "audit@example.com") {
+		t.Fatalf("bcc recipient leaked into headers:\n%s", server.message)
+	}
+}
+
+func TestExecuteFailsWhenEveryRecipientIsRejected(t *testing.T) {
+	server := newFakeServer(t, "missing@example.com")
+
+	raw, _ := json.Marshal(map[string]any{
+		"host":     "127.0.0.1",
+		"port":     server.port(),
+		"security": "NONE",
+		"from":     "flowk@example.com",
+		"to":       []string{"missing@example.com"},
+		"subject":  "hi",
+	})
+
+	_, err := (action{}).Execute(context.Background(), raw, nil)
+	if err == nil || !strings.Contains(err.Error(), "every recipient was rejected") {
+		t.Fatalf("Execute() error = %v, want rejection", err)
+	}
+}
+
+func TestExecuteRequiresSTARTTLSSupport(t *testing.T) {
+	server := newFakeServer(t)
+
+	raw, _ := json.Marshal(map[string]any{
+		"host":    "127.0.0.1",
+		"port":    server.port(),
+		"from":    "flowk@example.com",
+		"to":      []string{"ops@example.com"},
+		"subject": "hi",
+	})
+
+	_, err := (action{}).Execute(context.Background(), raw, nil)
+	if err == nil || !strings.Contains(err.Error(), "does not support STARTTLS") {
+		t.Fatalf("Execute() error = %v, want STARTTLS error", err)
+	}
+}
+
+func TestBuildMessageEncodesNonASCIISubject(t *testing.T) {
+	task := taskConfig{From: "a@example.com", To: []string{"b@example.com"}, Subject: "Despliegue terminado ✓", Body: "hola", HTML: true}
+	message, _, err := buildMessage(task, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("buildMessage() error = %v", err)
+	}
+	text := string(message)
+	if !strings.Contains(text, "Subject: =?utf-8?q?") {
+		t.Fatalf("subject not encoded:\n%s", text)
+	}
+	if !strings.Contains(text, "Content-Type: text/html; charset=utf-8\r\n") {
+		t.Fatalf("html content type missing:\n%s", text)
+	}
+	if !strings.Contains(text, "Date: Wed, 01 May 2024 10:00:00 +0000\r\n") {
+		t.Fatalf("date header missing:\n%s", text)
+	}
+}
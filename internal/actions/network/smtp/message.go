@@ -0,0 +1,173 @@
+package smtp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// buildMessage renders the RFC 5322 message sent to the server and returns it
+// together with its Message-ID. Bcc recipients are left out of the headers.
+func buildMessage(task taskConfig, now time.Time) ([]byte, string, error) {
+	messageID, err := newMessageID(task.From)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", formatAddressList([]string{task.From}))
+	writeHeader(&buf, "To", formatAddressList(task.To))
+	if len(task.Cc) > 0 {
+		writeHeader(&buf, "Cc", formatAddressList(task.Cc))
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", task.Subject))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID)
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	bodyType := "text/plain; charset=utf-8"
+	if task.HTML {
+		bodyType = "text/html; charset=utf-8"
+	}
+
+	if len(task.Attachments) == 0 {
+		writeHeader(&buf, "Content-Type", bodyType)
+		writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, task.Body); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), messageID, nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()}))
+	buf.WriteString("\r\n")
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {bodyType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("email: writing body: %w", err)
+	}
+	if err := writeQuotedPrintable(part, task.Body); err != nil {
+		return nil, "", err
+	}
+
+	for i, att := range task.Attachments {
+		if err := writeAttachment(mw, att); err != nil {
+			return nil, "", fmt.Errorf("email: attachments[%d]: %w", i, err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", fmt.Errorf("email: closing message: %w", err)
+	}
+	return buf.Bytes(), messageID, nil
+}
+
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	fmt.Fprintf(buf, "%s: %s\r\n", name, value)
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(normalizeNewlines(body))); err != nil {
+		return fmt.Errorf("email: writing body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("email: writing body: %w", err)
+	}
+	return nil
+}
+
+func writeAttachment(mw *multipart.Writer, att attachment) error {
+	path := strings.TrimSpace(att.Path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	name := strings.TrimSpace(att.Name)
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	contentType := strings.TrimSpace(att.ContentType)
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": name})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Base64 lines are wrapped at 76 characters as required by RFC 2045.
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(part, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = fmt.Fprintf(part, "%s\r\n", encoded)
+	return err
+}
+
+// normalizeNewlines converts bare line feeds to CRLF as SMTP expects.
+func normalizeNewlines(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
+
+func formatAddressList(addrs []string) string {
+	formatted := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			formatted = append(formatted, strings.TrimSpace(addr))
+			continue
+		}
+		formatted = append(formatted, parsed.String())
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// addressOnly strips the display name from addr for the SMTP envelope.
+func addressOnly(addr string) string {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil {
+		return strings.TrimSpace(addr)
+	}
+	return parsed.Address
+}
+
+func newMessageID(from string) (string, error) {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", fmt.Errorf("email: generating message id: %w", err)
+	}
+	domain := "flowk.local"
+	if address := addressOnly(from); strings.Contains(address, "@") {
+		domain = address[strings.LastIndex(address, "@")+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(raw[:]), domain), nil
+}
@@ -7,19 +7,25 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	sshclient "github.com/helloyi/go-sshclient"
 	"github.com/kr/fs"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 	"golang.org/x/crypto/ssh/knownhosts"
 
 	"flowk/internal/actions/registry"
+	"flowk/internal/config"
 	"flowk/internal/flow"
+	"flowk/internal/shared/tracing"
 )
 
 func init() {
@@ -62,18 +68,34 @@ func (Action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 	if err := json.Unmarshal(payload, &spec); err != nil {
 		return registry.Result{}, fmt.Errorf("ssh: decode payload: %w", err)
 	}
+	spec.Connection.applyConfigDefaults(config.ActionDefaults().SSH)
 
 	if err := spec.validate(); err != nil {
 		return registry.Result{}, err
 	}
 
-	client, err := spec.Connection.dial()
+	_, connectSpan := tracing.Start(ctx, "ssh connect",
+		attribute.String("flowk.ssh.address", spec.Connection.Address),
+		attribute.Int("flowk.ssh.jump_hosts", len(spec.Connection.JumpHosts)),
+	)
+	client, closeHops, err := spec.Connection.dial()
+	tracing.End(connectSpan, err)
 	if err != nil {
 		return registry.Result{}, err
 	}
+	defer closeHops()
 	defer client.Close()
 
-	state := newActionState(client, spec)
+	// Closing the connection is the only way to interrupt a remote command
+	// that is already running, so tie it to the task context.
+	stopAbort := context.AfterFunc(ctx, func() { _ = client.Close() })
+	defer stopAbort()
+
+	var logger registry.Logger
+	if execCtx != nil {
+		logger = execCtx.Logger
+	}
+	state := newActionState(client, spec, logger)
 	defer state.Close()
 
 	results := make([]stepResult, 0, len(spec.Steps))
@@ -85,8 +107,16 @@ func (Action) Execute(ctx context.Context, payload json.RawMessage, execCtx *reg
 		default:
 		}
 
-		outcome, err := state.executeStep(ctx, idx, raw)
+		stepCtx, stepSpan := tracing.Start(ctx, fmt.Sprintf("ssh step %d", idx), attribute.Int("flowk.ssh.step", idx))
+		outcome, err := state.executeStep(stepCtx, idx, raw)
+		if outcome.Operation != "" {
+			stepSpan.SetAttributes(attribute.String("flowk.ssh.operation", outcome.Operation))
+		}
+		tracing.End(stepSpan, err)
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return registry.Result{}, fmt.Errorf("ssh: step %d interrupted: %w", idx, ctxErr)
+			}
 			return registry.Result{}, err
 		}
 		results = append(results, outcome)
@@ -117,15 +147,19 @@ func (p *payloadSpec) validate() error {
 
 // connectionSpec declares how the SSH client should be established.
 type connectionSpec struct {
-	Network          string      `json:"network"`
-	Address          string      `json:"address"`
-	Username         string      `json:"username"`
-	Auth             authSpec    `json:"auth"`
-	TimeoutSeconds   float64     `json:"timeoutSeconds"`
-	HostKey          hostKeySpec `json:"hostKey"`
-	ClientVersion    string      `json:"clientVersion"`
-	PreferredCiphers []string    `json:"preferredCiphers"`
-	KeepAliveSeconds float64     `json:"keepAliveSeconds"`
+	Network          string         `json:"network"`
+	Address          string         `json:"address"`
+	Username         string         `json:"username"`
+	Auth             authSpec       `json:"auth"`
+	TimeoutSeconds   float64        `json:"timeoutSeconds"`
+	HostKey          hostKeySpec    `json:"hostKey"`
+	ClientVersion    string         `json:"clientVersion"`
+	PreferredCiphers []string       `json:"preferredCiphers"`
+	KeepAliveSeconds float64        `json:"keepAliveSeconds"`
+	JumpHosts        []jumpHostSpec `json:"jumpHosts"`
+
+	// authMethod records the authentication method that succeeded.
+	authMethod string
 }
 
 func (c *connectionSpec) validate() error {
@@ -135,65 +169,176 @@ func (c *connectionSpec) validate() error {
 	if strings.TrimSpace(c.Username) == "" {
 		return errors.New("ssh: connection.username must be provided")
 	}
+	for idx, hop := range c.JumpHosts {
+		if strings.TrimSpace(hop.Address) == "" {
+			return fmt.Errorf("ssh: connection.jumpHosts[%d].address must be provided", idx)
+		}
+		if strings.TrimSpace(hop.Username) == "" {
+			return fmt.Errorf("ssh: connection.jumpHosts[%d].username must be provided", idx)
+		}
+	}
 	return nil
 }
 
-func (c *connectionSpec) dial() (*sshclient.Client, error) {
+// applyConfigDefaults fills timeoutSeconds and the host key settings from
+// config.yaml when the task omits them.
+func (c *connectionSpec) applyConfigDefaults(defaults config.SSHDefaultsConfig) {
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaults.TimeoutSeconds
+	}
+	if strings.TrimSpace(c.HostKey.Mode) != "" {
+		return
+	}
+	c.HostKey.Mode = defaults.HostKeyMode
+	if len(c.HostKey.KnownHostsFiles) == 0 && len(c.HostKey.InlineEntries) == 0 {
+		c.HostKey.KnownHostsFiles = append([]string(nil), defaults.KnownHostsFiles...)
+	}
+}
+
+// jumpHostSpec declares a bastion hop. Host keys are verified with the
+// connection's hostKey settings.
+type jumpHostSpec struct {
+	Network        string   `json:"network"`
+	Address        string   `json:"address"`
+	Username       string   `json:"username"`
+	Auth           authSpec `json:"auth"`
+	TimeoutSeconds float64  `json:"timeoutSeconds"`
+}
+
+// dial connects to the target, through every jump host in order when any
+// are configured. The returned function closes the jump host connections
+// and must be called after the client is closed.
+func (c *connectionSpec) dial() (*sshclient.Client, func(), error) {
 	network := strings.TrimSpace(c.Network)
 	if network == "" {
 		network = "tcp"
 	}
 
-	timeout := time.Duration(0)
-	if c.TimeoutSeconds > 0 {
-		timeout = time.Duration(c.TimeoutSeconds * float64(time.Second))
+	callback, cleanup, err := c.HostKey.build()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cleanup != nil {
+		defer cleanup()
 	}
 
+	var hops []*sshclient.Client
+	closeHops := func() {
+		for i := len(hops) - 1; i >= 0; i-- {
+			_ = hops[i].Close()
+		}
+	}
+
+	var via *sshclient.Client
+	for idx := range c.JumpHosts {
+		hop := &c.JumpHosts[idx]
+		hopNetwork := chooseNonEmpty(strings.TrimSpace(hop.Network), network)
+		timeout := chooseTimeout(hop.TimeoutSeconds, c.TimeoutSeconds)
+		client, _, err := dialWithAuth(via, hopNetwork, hop.Address, &hop.Auth, c.clientConfig(hop.Username, timeout, callback))
+		if err != nil {
+			closeHops()
+			return nil, nil, fmt.Errorf("ssh: jump host %d: %w", idx, err)
+		}
+		hops = append(hops, client)
+		via = client
+	}
+
+	config := c.clientConfig(c.Username, chooseTimeout(c.TimeoutSeconds, 0), callback)
+	client, method, err := dialWithAuth(via, network, c.Address, &c.Auth, config)
+	if err != nil {
+		closeHops()
+		return nil, nil, err
+	}
+
+	c.authMethod = method
+	if c.KeepAliveSeconds > 0 {
+		go keepAliveLoop(client.UnderlyingClient(), time.Duration(c.KeepAliveSeconds*float64(time.Second)))
+	}
+
+	return client, closeHops, nil
+}
+
+func (c *connectionSpec) clientConfig(username string, timeout time.Duration, callback ssh.HostKeyCallback) *ssh.ClientConfig {
 	config := &ssh.ClientConfig{
-		User:            c.Username,
+		User:            username,
 		Timeout:         timeout,
 		ClientVersion:   strings.TrimSpace(c.ClientVersion),
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 	}
-
 	if len(c.PreferredCiphers) > 0 {
 		config.Config = ssh.Config{Ciphers: c.PreferredCiphers}
 	}
-
-	if err := c.Auth.apply(config); err != nil {
-		return nil, err
-	}
-
-	callback, cleanup, err := c.HostKey.build()
-	if err != nil {
-		return nil, err
-	}
 	if callback != nil {
 		config.HostKeyCallback = callback
 	}
+	return config
+}
 
-	client, err := sshclient.Dial(network, c.Address, config)
+// dialWithAuth connects to address, directly or through via, trying each
+// authentication attempt in order. It returns the accepted method.
+func dialWithAuth(via *sshclient.Client, network, address string, auth *authSpec, config *ssh.ClientConfig) (*sshclient.Client, string, error) {
+	attempts, err := auth.attempts()
 	if err != nil {
-		return nil, fmt.Errorf("ssh: dial %s %s: %w", network, c.Address, err)
+		return nil, "", err
 	}
+	defer func() {
+		for _, attempt := range attempts {
+			if attempt.cleanup != nil {
+				attempt.cleanup()
+			}
+		}
+	}()
 
-	if c.KeepAliveSeconds > 0 {
-		go keepAliveLoop(client.UnderlyingClient(), time.Duration(c.KeepAliveSeconds*float64(time.Second)))
-	}
+	// Each attempt dials separately so that "auto" can report which
+	// method was accepted by the server.
+	var dialErrs []error
+	for _, attempt := range attempts {
+		attemptConfig := *config
+		attemptConfig.Auth = attempt.methods
 
-	if cleanup != nil {
-		cleanup()
+		var client *sshclient.Client
+		if via == nil {
+			client, err = sshclient.Dial(network, address, &attemptConfig)
+		} else {
+			client, err = via.Dial(network, address, &attemptConfig)
+		}
+		if err != nil {
+			dialErrs = append(dialErrs, fmt.Errorf("%s: %w", attempt.method, err))
+			continue
+		}
+		return client, attempt.method, nil
 	}
 
-	return client, nil
+	return nil, "", fmt.Errorf("ssh: dial %s %s: %w", network, address, errors.Join(dialErrs...))
+}
+
+func chooseTimeout(seconds, fallback float64) time.Duration {
+	if seconds <= 0 {
+		seconds = fallback
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
 }
 
 func (c *connectionSpec) summary() map[string]any {
-	return map[string]any{
+	summary := map[string]any{
 		"address":  c.Address,
 		"network":  chooseNonEmpty(c.Network, "tcp"),
 		"username": c.Username,
 	}
+	if c.authMethod != "" {
+		summary["authMethod"] = c.authMethod
+	}
+	if len(c.JumpHosts) > 0 {
+		hops := make([]string, len(c.JumpHosts))
+		for i, hop := range c.JumpHosts {
+			hops[i] = hop.Address
+		}
+		summary["jumpHosts"] = hops
+	}
+	return summary
 }
 
 // hostKeySpec configures host key verification.
@@ -208,7 +353,7 @@ func (h *hostKeySpec) build() (ssh.HostKeyCallback, func(), error) {
 	switch mode {
 	case "", "insecure":
 		return ssh.InsecureIgnoreHostKey(), nil, nil
-	case "known_hosts":
+	case "known_hosts", "tofu":
 		var sources []string
 		var cleanupPaths []string
 		for _, file := range h.KnownHostsFiles {
@@ -222,6 +367,9 @@ func (h *hostKeySpec) build() (ssh.HostKeyCallback, func(), error) {
 			}
 			sources = append(sources, expanded)
 		}
+		if mode == "tofu" && len(sources) == 0 {
+			return nil, nil, errors.New("ssh: hostKey.knownHostsFiles[0] must name a writable file when mode is tofu")
+		}
 		if len(h.InlineEntries) > 0 {
 			tempFile, err := writeTempKnownHosts(h.InlineEntries)
 			if err != nil {
@@ -230,24 +378,130 @@ func (h *hostKeySpec) build() (ssh.HostKeyCallback, func(), error) {
 			sources = append(sources, tempFile)
 			cleanupPaths = append(cleanupPaths, tempFile)
 		}
+		cleanup := func() {
+			for _, path := range cleanupPaths {
+				_ = os.Remove(path)
+			}
+		}
 		if len(sources) == 0 {
 			return nil, nil, errors.New("ssh: hostKey.knownHostsFiles or inlineEntries required when mode is known_hosts")
 		}
+		if mode == "tofu" {
+			return tofuCallback(sources), cleanup, nil
+		}
 		callback, err := knownhosts.New(sources...)
 		if err != nil {
+			cleanup()
 			return nil, nil, fmt.Errorf("ssh: known_hosts callback: %w", err)
 		}
-		cleanup := func() {
-			for _, path := range cleanupPaths {
-				_ = os.Remove(path)
-			}
-		}
 		return callback, cleanup, nil
 	default:
 		return nil, nil, fmt.Errorf("ssh: unsupported hostKey.mode %q", h.Mode)
 	}
 }
 
+// tofuCallback trusts a host on first use: an unknown host key is appended
+// to sources[0], while a known host must keep presenting the same key. The
+// files are re-read under a lock on every connection so that concurrent
+// tasks see each other's entries.
+func tofuCallback(sources []string) ssh.HostKeyCallback {
+	trustFile := sources[0]
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		unlock, err := lockKnownHosts(trustFile)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if err := ensureKnownHostsFile(trustFile); err != nil {
+			return err
+		}
+		callback, err := knownhosts.New(sources...)
+		if err != nil {
+			return fmt.Errorf("ssh: known_hosts callback: %w", err)
+		}
+
+		err = callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if err == nil || !errors.As(err, &keyErr) {
+			return err
+		}
+		if len(keyErr.Want) > 0 {
+			return fmt.Errorf("ssh: host key for %s changed, possible man-in-the-middle attack: %w", hostname, err)
+		}
+
+		line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+		file, err := os.OpenFile(trustFile, os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("ssh: open known_hosts %q: %w", trustFile, err)
+		}
+		defer file.Close()
+		if _, err := fmt.Fprintln(file, line); err != nil {
+			return fmt.Errorf("ssh: record host key in %q: %w", trustFile, err)
+		}
+		return nil
+	}
+}
+
+func ensureKnownHostsFile(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("ssh: create directories for %s: %w", path, err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("ssh: create known_hosts %q: %w", path, err)
+	}
+	return file.Close()
+}
+
+var (
+	knownHostsMu          sync.Mutex
+	knownHostsLockTimeout = 10 * time.Second
+)
+
+// lockKnownHosts serializes updates of a known_hosts file, within the process
+// through a mutex and across processes through an exclusive "<path>.lock"
+// file. A lock file older than knownHostsLockTimeout was left behind by a run
+// that died while holding it, so it is removed and the lock taken over.
+func lockKnownHosts(path string) (func(), error) {
+	knownHostsMu.Lock()
+
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(knownHostsLockTimeout)
+	for {
+		if dir := filepath.Dir(lockPath); dir != "." {
+			if err := os.MkdirAll(dir, 0o700); err != nil {
+				knownHostsMu.Unlock()
+				return nil, fmt.Errorf("ssh: create directories for %s: %w", lockPath, err)
+			}
+		}
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			file.Close()
+			return func() {
+				_ = os.Remove(lockPath)
+				knownHostsMu.Unlock()
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			knownHostsMu.Unlock()
+			return nil, fmt.Errorf("ssh: lock known_hosts %q: %w", path, err)
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > knownHostsLockTimeout {
+			if removeErr := os.Remove(lockPath); removeErr == nil || errors.Is(removeErr, os.ErrNotExist) {
+				continue
+			}
+		}
+		if time.Now().After(deadline) {
+			knownHostsMu.Unlock()
+			return nil, fmt.Errorf("ssh: lock known_hosts %q: %w", path, err)
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+}
+
 // authSpec configures authentication.
 type authSpec struct {
 	Method         string `json:"method"`
@@ -258,40 +512,106 @@ type authSpec struct {
 	Passphrase     string `json:"passphrase"`
 }
 
-func (a *authSpec) apply(config *ssh.ClientConfig) error {
+// authAttempt is one set of credentials offered to the server. cleanup
+// releases resources such as the agent connection once dialing is done.
+type authAttempt struct {
+	method  string
+	methods []ssh.AuthMethod
+	cleanup func()
+}
+
+// attempts returns the credentials to try in order. Every method yields a
+// single attempt except "auto", which tries the agent, then the configured
+// private key, then the password.
+func (a *authSpec) attempts() ([]authAttempt, error) {
 	method := strings.ToLower(strings.TrimSpace(a.Method))
 	switch method {
 	case "password":
 		if a.Password == "" {
-			return errors.New("ssh: auth.password must be provided when method is password")
+			return nil, errors.New("ssh: auth.password must be provided when method is password")
 		}
-		config.Auth = []ssh.AuthMethod{ssh.Password(a.Password)}
-	case "private_key", "private-key", "keyfile":
-		pem, err := a.privateKeyPEM()
+		return []authAttempt{a.passwordAttempt()}, nil
+	case "private_key", "private-key", "keyfile", "private_key_with_passphrase":
+		attempt, err := a.privateKeyAttempt(method)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		signer, err := signerFromPEM(pem, a.Passphrase)
+		return []authAttempt{attempt}, nil
+	case "agent":
+		attempt, err := agentAttempt()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		config.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
-	case "private_key_with_passphrase":
-		pem, err := a.privateKeyPEM()
-		if err != nil {
-			return err
+		return []authAttempt{attempt}, nil
+	case "auto":
+		var attempts []authAttempt
+		if attempt, err := agentAttempt(); err == nil {
+			attempts = append(attempts, attempt)
 		}
-		signer, err := signerFromPEM(pem, a.Passphrase)
-		if err != nil {
-			return err
+		if a.hasPrivateKey() {
+			attempt, err := a.privateKeyAttempt("private_key")
+			if err != nil {
+				for _, previous := range attempts {
+					if previous.cleanup != nil {
+						previous.cleanup()
+					}
+				}
+				return nil, err
+			}
+			attempts = append(attempts, attempt)
+		}
+		if a.Password != "" {
+			attempts = append(attempts, a.passwordAttempt())
+		}
+		if len(attempts) == 0 {
+			return nil, errors.New("ssh: auth.method auto found no ssh agent, private key or password to try")
 		}
-		config.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+		return attempts, nil
 	case "":
-		return errors.New("ssh: auth.method must be supplied")
+		return nil, errors.New("ssh: auth.method must be supplied")
 	default:
-		return fmt.Errorf("ssh: unsupported auth.method %q", a.Method)
+		return nil, fmt.Errorf("ssh: unsupported auth.method %q", a.Method)
 	}
-	return nil
+}
+
+func (a *authSpec) passwordAttempt() authAttempt {
+	return authAttempt{method: "password", methods: []ssh.AuthMethod{ssh.Password(a.Password)}}
+}
+
+func (a *authSpec) privateKeyAttempt(method string) (authAttempt, error) {
+	pem, err := a.privateKeyPEM()
+	if err != nil {
+		return authAttempt{}, err
+	}
+	signer, err := signerFromPEM(pem, a.Passphrase)
+	if err != nil {
+		return authAttempt{}, err
+	}
+	return authAttempt{method: method, methods: []ssh.AuthMethod{ssh.PublicKeys(signer)}}, nil
+}
+
+func (a *authSpec) hasPrivateKey() bool {
+	return strings.TrimSpace(a.PrivateKey) != "" ||
+		strings.TrimSpace(a.PrivateKeyPEM) != "" ||
+		strings.TrimSpace(a.PrivateKeyPath) != ""
+}
+
+// agentAttempt connects to the ssh agent listening on $SSH_AUTH_SOCK.
+func agentAttempt() (authAttempt, error) {
+	socket := strings.TrimSpace(os.Getenv("SSH_AUTH_SOCK"))
+	if socket == "" {
+		return authAttempt{}, errors.New("ssh: auth.method agent requires SSH_AUTH_SOCK to be set")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return authAttempt{}, fmt.Errorf("ssh: connect to ssh agent at %q: %w", socket, err)
+	}
+	client := agent.NewClient(conn)
+	return authAttempt{
+		method:  "agent",
+		methods: []ssh.AuthMethod{ssh.PublicKeysCallback(client.Signers)},
+		cleanup: func() { _ = conn.Close() },
+	}, nil
 }
 
 func (a *authSpec) privateKeyPEM() (string, error) {
@@ -373,15 +693,20 @@ func keepAliveLoop(client *ssh.Client, interval time.Duration) {
 type actionState struct {
 	client    *sshclient.Client
 	spec      payloadSpec
+	logger    registry.Logger
 	sftp      *sshclient.RemoteFileSystem
 	tempFiles []string
+	forwards  map[string]*forward
 }
 
-func newActionState(client *sshclient.Client, spec payloadSpec) *actionState {
-	return &actionState{client: client, spec: spec}
+func newActionState(client *sshclient.Client, spec payloadSpec, logger registry.Logger) *actionState {
+	return &actionState{client: client, spec: spec, logger: logger}
 }
 
 func (s *actionState) Close() {
+	for id := range s.forwards {
+		s.stopForward(id)
+	}
 	if s.sftp != nil {
 		_ = s.sftp.Close()
 	}
@@ -396,10 +721,12 @@ type stepEnvelope struct {
 }
 
 type stepResult struct {
-	ID        string `json:"id"`
-	Operation string `json:"operation"`
-	Success   bool   `json:"success"`
-	Output    any    `json:"output,omitempty"`
+	ID         string `json:"id"`
+	Operation  string `json:"operation"`
+	Success    bool   `json:"success"`
+	ExitCode   *int   `json:"exitCode,omitempty"`
+	Output     any    `json:"output,omitempty"`
+	ParseError string `json:"parseError,omitempty"`
 }
 
 func (s *actionState) executeStep(ctx context.Context, idx int, raw json.RawMessage) (stepResult, error) {
@@ -443,6 +770,12 @@ func (s *actionState) executeStep(ctx context.Context, idx int, raw json.RawMess
 			return stepResult{}, err
 		}
 		return res, nil
+	case "LOCAL_FORWARD", "REMOTE_FORWARD", "STOP_FORWARD":
+		res, err := s.handleForwardStep(ctx, env, raw, op)
+		if err != nil {
+			return stepResult{}, err
+		}
+		return res, nil
 	default:
 		return stepResult{}, fmt.Errorf("ssh: unsupported operation %q", env.Operation)
 	}
@@ -454,7 +787,10 @@ type commandStep struct {
 	Append           []string `json:"append"`
 	Stdout           string   `json:"stdout"`
 	Stderr           string   `json:"stderr"`
+	Stream           bool     `json:"stream"`
+	Parse            string   `json:"parse"`
 	AllowedExitCodes []int    `json:"allowedExitCodes"`
+	CaptureExitCode  bool     `json:"captureExitCode"`
 }
 
 func (s commandStep) allowsExit(err error) bool {
@@ -462,12 +798,11 @@ func (s commandStep) allowsExit(err error) bool {
 		return false
 	}
 
-	var exitErr interface{ ExitStatus() int }
-	if !errors.As(err, &exitErr) {
+	status, ok := exitStatus(err)
+	if !ok {
 		return false
 	}
 
-	status := exitErr.ExitStatus()
 	for _, allowed := range s.AllowedExitCodes {
 		if allowed == status {
 			return true
@@ -484,6 +819,9 @@ func (s *actionState) handleCommandStep(ctx context.Context, env stepEnvelope, r
 	if len(step.Commands) == 0 {
 		return stepResult{}, fmt.Errorf("ssh: step %q commands cannot be empty", env.ID)
 	}
+	if err := validateParse(step.Parse); err != nil {
+		return stepResult{}, fmt.Errorf("ssh: step %q: %w", env.ID, err)
+	}
 
 	rs := s.client.Cmd(step.Commands[0])
 	for _, cmd := range step.Append {
@@ -493,54 +831,46 @@ func (s *actionState) handleCommandStep(ctx context.Context, env stepEnvelope, r
 		rs = rs.Cmd(cmd)
 	}
 
-	captureStdout := strings.EqualFold(step.Stdout, "capture")
-	captureStderr := strings.EqualFold(step.Stderr, "capture")
-	var stdoutBuf, stderrBuf bytes.Buffer
-	if captureStdout || captureStderr {
-		var stdout io.Writer
-		var stderr io.Writer
-		if captureStdout {
-			stdout = &stdoutBuf
-		}
-		if captureStderr {
-			stderr = &stderrBuf
-		}
-		rs = rs.SetStdio(stdout, stderr)
-	}
-
 	result := stepResult{ID: env.ID, Operation: env.Operation, Success: true}
 
-	switch op {
-	case "RUN_COMMAND":
-		err := rs.Run()
-		if err != nil && !step.allowsExit(err) {
-			return stepResult{}, fmt.Errorf("ssh: command run %q failed: %w", env.ID, err)
-		}
-		if captureStdout || captureStderr {
-			result.Output = map[string]any{"stdout": stdoutBuf.String(), "stderr": stderrBuf.String()}
-		}
-	case "RUN_COMMAND_OUTPUT":
-		output, err := rs.Output()
-		if err != nil && !step.allowsExit(err) {
-			return stepResult{}, fmt.Errorf("ssh: command output %q failed: %w", env.ID, err)
-		}
-		result.Output = string(output)
-	case "RUN_COMMAND_SMART_OUTPUT":
-		output, err := rs.SmartOutput()
-		if err != nil && !step.allowsExit(err) {
-			return stepResult{}, fmt.Errorf("ssh: command smart output %q failed: %w", env.ID, err)
-		}
-		result.Output = string(output)
+	mode := outputModeOf(op)
+	output, err := s.runRemote(rs, env.ID, mode, outputOptions{
+		captureStdout: strings.EqualFold(step.Stdout, "capture"),
+		captureStderr: strings.EqualFold(step.Stderr, "capture"),
+		stream:        step.Stream,
+	})
+	status, isExit := exitStatus(err)
+	if err != nil && !(isExit && step.CaptureExitCode) && !step.allowsExit(err) {
+		return stepResult{}, fmt.Errorf("ssh: command %s %q failed: %w", mode, env.ID, err)
+	}
+	if isExit {
+		result.ExitCode = &status
 	}
+	result.Output, result.ParseError = parseOutput(output, step.Parse)
 
 	return result, nil
 }
 
+// exitStatus reports the remote exit status carried by err, which is zero
+// when err is nil. ok is false when err did not come from the remote exit.
+func exitStatus(err error) (status int, ok bool) {
+	if err == nil {
+		return 0, true
+	}
+	var exitErr interface{ ExitStatus() int }
+	if !errors.As(err, &exitErr) {
+		return 0, false
+	}
+	return exitErr.ExitStatus(), true
+}
+
 type scriptStep struct {
 	ID     string `json:"id"`
 	Script string `json:"script"`
 	Stdout string `json:"stdout"`
 	Stderr string `json:"stderr"`
+	Stream bool   `json:"stream"`
+	Parse  string `json:"parse"`
 }
 
 func (s *actionState) handleScriptStep(ctx context.Context, env stepEnvelope, raw json.RawMessage, op string) (stepResult, error) {
@@ -551,51 +881,31 @@ func (s *actionState) handleScriptStep(ctx context.Context, env stepEnvelope, ra
 	if strings.TrimSpace(step.Script) == "" {
 		return stepResult{}, fmt.Errorf("ssh: script step %q requires script content", env.ID)
 	}
-
-	rs := s.client.Script(step.Script)
-	captureStdout := strings.EqualFold(step.Stdout, "capture")
-	captureStderr := strings.EqualFold(step.Stderr, "capture")
-	var stdoutBuf, stderrBuf bytes.Buffer
-	if captureStdout || captureStderr {
-		var stdout io.Writer
-		var stderr io.Writer
-		if captureStdout {
-			stdout = &stdoutBuf
-		}
-		if captureStderr {
-			stderr = &stderrBuf
-		}
-		rs = rs.SetStdio(stdout, stderr)
+	if err := validateParse(step.Parse); err != nil {
+		return stepResult{}, fmt.Errorf("ssh: script step %q: %w", env.ID, err)
 	}
 
+	rs := s.client.Script(step.Script)
 	result := stepResult{ID: env.ID, Operation: env.Operation, Success: true}
-	switch op {
-	case "RUN_SCRIPT":
-		if err := rs.Run(); err != nil {
-			return stepResult{}, fmt.Errorf("ssh: script run %q failed: %w", env.ID, err)
-		}
-		if captureStdout || captureStderr {
-			result.Output = map[string]any{"stdout": stdoutBuf.String(), "stderr": stderrBuf.String()}
-		}
-	case "RUN_SCRIPT_OUTPUT":
-		output, err := rs.Output()
-		if err != nil {
-			return stepResult{}, fmt.Errorf("ssh: script output %q failed: %w", env.ID, err)
-		}
-		result.Output = string(output)
-	case "RUN_SCRIPT_SMART_OUTPUT":
-		output, err := rs.SmartOutput()
-		if err != nil {
-			return stepResult{}, fmt.Errorf("ssh: script smart output %q failed: %w", env.ID, err)
-		}
-		result.Output = string(output)
+
+	mode := outputModeOf(op)
+	output, err := s.runRemote(rs, env.ID, mode, outputOptions{
+		captureStdout: strings.EqualFold(step.Stdout, "capture"),
+		captureStderr: strings.EqualFold(step.Stderr, "capture"),
+		stream:        step.Stream,
+	})
+	if err != nil {
+		return stepResult{}, fmt.Errorf("ssh: script %s %q failed: %w", mode, env.ID, err)
 	}
+	result.Output, result.ParseError = parseOutput(output, step.Parse)
 	return result, nil
 }
 
 type scriptFileStep struct {
-	ID   string `json:"id"`
-	Path string `json:"path"`
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	Stream bool   `json:"stream"`
+	Parse  string `json:"parse"`
 }
 
 func (s *actionState) handleScriptFileStep(ctx context.Context, env stepEnvelope, raw json.RawMessage, op string) (stepResult, error) {
@@ -606,6 +916,9 @@ func (s *actionState) handleScriptFileStep(ctx context.Context, env stepEnvelope
 	if strings.TrimSpace(step.Path) == "" {
 		return stepResult{}, fmt.Errorf("ssh: script file step %q requires path", env.ID)
 	}
+	if err := validateParse(step.Parse); err != nil {
+		return stepResult{}, fmt.Errorf("ssh: script file step %q: %w", env.ID, err)
+	}
 
 	abs, err := filepath.Abs(step.Path)
 	if err != nil {
@@ -614,25 +927,212 @@ func (s *actionState) handleScriptFileStep(ctx context.Context, env stepEnvelope
 
 	rs := s.client.ScriptFile(abs)
 	result := stepResult{ID: env.ID, Operation: env.Operation, Success: true}
-	switch op {
-	case "RUN_SCRIPT_FILE":
-		if err := rs.Run(); err != nil {
-			return stepResult{}, fmt.Errorf("ssh: script file run %q failed: %w", env.ID, err)
+
+	mode := outputModeOf(op)
+	output, err := s.runRemote(rs, env.ID, mode, outputOptions{stream: step.Stream})
+	if err != nil {
+		return stepResult{}, fmt.Errorf("ssh: script file %s %q failed: %w", mode, env.ID, err)
+	}
+	result.Output, result.ParseError = parseOutput(output, step.Parse)
+	return result, nil
+}
+
+// outputMode tells how a RUN_* operation reports output: "run" returns the
+// captured streams when requested, "output" returns stdout, and
+// "smart output" returns stdout on success or stderr on failure.
+type outputMode string
+
+const (
+	outputRun   outputMode = "run"
+	outputPlain outputMode = "output"
+	outputSmart outputMode = "smart output"
+)
+
+func outputModeOf(op string) outputMode {
+	switch {
+	case strings.HasSuffix(op, "_SMART_OUTPUT"):
+		return outputSmart
+	case strings.HasSuffix(op, "_OUTPUT"):
+		return outputPlain
+	default:
+		return outputRun
+	}
+}
+
+func validateParse(parse string) error {
+	switch parse {
+	case "", "json", "lines":
+		return nil
+	default:
+		return fmt.Errorf("unsupported parse %q (expected json or lines)", parse)
+	}
+}
+
+// parseOutput converts the captured stdout according to parse: "json"
+// decodes it into structured data and "lines" splits it into a string array.
+// Stdout is the whole output for the *_OUTPUT modes and the stdout field of
+// captured streams. When JSON decoding fails the raw string is kept and the
+// error is returned for the step's parseError.
+func parseOutput(output any, parse string) (any, string) {
+	if parse == "" {
+		return output, ""
+	}
+
+	convert := func(stdout string) (any, string) {
+		if parse == "lines" {
+			return splitLines(stdout), ""
 		}
-	case "RUN_SCRIPT_FILE_OUTPUT":
-		output, err := rs.Output()
-		if err != nil {
-			return stepResult{}, fmt.Errorf("ssh: script file output %q failed: %w", env.ID, err)
+		var decoded any
+		if err := json.Unmarshal([]byte(stdout), &decoded); err != nil {
+			return stdout, err.Error()
+		}
+		return decoded, ""
+	}
+
+	switch v := output.(type) {
+	case string:
+		return convert(v)
+	case map[string]any:
+		stdout, ok := v["stdout"].(string)
+		if !ok {
+			return output, ""
+		}
+		parsed := make(map[string]any, len(v))
+		for key, value := range v {
+			parsed[key] = value
+		}
+		var parseErr string
+		parsed["stdout"], parseErr = convert(stdout)
+		return parsed, parseErr
+	default:
+		return output, ""
+	}
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimRight(text, "\r\n")
+	if text == "" {
+		return []string{}
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+type outputOptions struct {
+	captureStdout bool
+	captureStderr bool
+	stream        bool
+}
+
+// runRemote runs the script and builds the step output for mode. The output
+// is returned alongside the error so that allowed exit codes keep it.
+func (s *actionState) runRemote(rs *sshclient.RemoteScript, id string, mode outputMode, opts outputOptions) (any, error) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+
+	if !opts.stream {
+		switch mode {
+		case outputPlain:
+			output, err := rs.Output()
+			return string(output), err
+		case outputSmart:
+			output, err := rs.SmartOutput()
+			return string(output), err
+		}
+
+		if !opts.captureStdout && !opts.captureStderr {
+			return nil, rs.Run()
+		}
+		var stdout, stderr io.Writer
+		if opts.captureStdout {
+			stdout = &stdoutBuf
+		}
+		if opts.captureStderr {
+			stderr = &stderrBuf
 		}
-		result.Output = string(output)
-	case "RUN_SCRIPT_FILE_SMART_OUTPUT":
-		output, err := rs.SmartOutput()
+		err := rs.SetStdio(stdout, stderr).Run()
+		return map[string]any{"stdout": stdoutBuf.String(), "stderr": stderrBuf.String()}, err
+	}
+
+	// Streaming tees every line to the logger as it arrives, so the
+	// output modes are rebuilt from the same buffers instead of the
+	// library's Output helpers.
+	stdoutLines := newLineLogger(s.logger, fmt.Sprintf("SSH %s stdout: ", id))
+	stderrLines := newLineLogger(s.logger, fmt.Sprintf("SSH %s stderr: ", id))
+	stdout := io.MultiWriter(&stdoutBuf, stdoutLines)
+	stderr := io.MultiWriter(&stderrBuf, stderrLines)
+
+	err := rs.SetStdio(stdout, stderr).Run()
+	stdoutLines.Flush()
+	stderrLines.Flush()
+
+	switch mode {
+	case outputPlain:
+		return stdoutBuf.String(), err
+	case outputSmart:
 		if err != nil {
-			return stepResult{}, fmt.Errorf("ssh: script file smart output %q failed: %w", env.ID, err)
+			return stderrBuf.String(), err
 		}
-		result.Output = string(output)
+		return stdoutBuf.String(), nil
+	}
+	if !opts.captureStdout && !opts.captureStderr {
+		return nil, err
+	}
+	output := map[string]any{"stdout": "", "stderr": ""}
+	if opts.captureStdout {
+		output["stdout"] = stdoutBuf.String()
+	}
+	if opts.captureStderr {
+		output["stderr"] = stderrBuf.String()
+	}
+	return output, err
+}
+
+// lineLogger forwards complete lines written to it to the logger. Flush
+// emits a trailing line without a newline.
+type lineLogger struct {
+	logger  registry.Logger
+	prefix  string
+	mu      sync.Mutex
+	pending []byte
+}
+
+func newLineLogger(logger registry.Logger, prefix string) *lineLogger {
+	return &lineLogger{logger: logger, prefix: prefix}
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pending = append(l.pending, p...)
+	for {
+		idx := bytes.IndexByte(l.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		l.emit(strings.TrimRight(string(l.pending[:idx]), "\r"))
+		l.pending = l.pending[idx+1:]
+	}
+	return len(p), nil
+}
+
+func (l *lineLogger) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) > 0 {
+		l.emit(string(l.pending))
+		l.pending = nil
+	}
+}
+
+func (l *lineLogger) emit(line string) {
+	if l.logger != nil {
+		l.logger.Printf("%s%s", l.prefix, line)
 	}
-	return result, nil
 }
 
 type shellStep struct {
@@ -813,6 +1313,27 @@ func (s *actionState) handleSFTPStep(ctx context.Context, env stepEnvelope, raw
 		if err := rfs.Download(remote, local); err != nil {
 			return stepResult{}, fmt.Errorf("ssh: sftp download %q failed: %w", env.ID, err)
 		}
+		verified, err := s.verifyTransfer(step.Params, local, remote)
+		if err != nil {
+			return stepResult{}, fmt.Errorf("ssh: sftp download %q verify: %w", env.ID, err)
+		}
+		if verified != nil {
+			result.Output = map[string]any{"verify": verified}
+		}
+	case "DOWNLOAD_DIR":
+		remote := stringValue(step.Params, "remotePath")
+		local := stringValue(step.Params, "localPath")
+		if remote == "" || local == "" {
+			return stepResult{}, fmt.Errorf("ssh: sftp download_dir %q requires remotePath and localPath", env.ID)
+		}
+		transfer, err := newDirTransfer(rfs, step.Params)
+		if err != nil {
+			return stepResult{}, fmt.Errorf("ssh: sftp download_dir %q: %w", env.ID, err)
+		}
+		if err := transfer.download(remote, local, ""); err != nil {
+			return stepResult{}, fmt.Errorf("ssh: sftp download_dir %q failed: %w", env.ID, err)
+		}
+		result.Output = transfer.output()
 	case "GETWD":
 		wd, err := rfs.Getwd()
 		if err != nil {
@@ -1034,6 +1555,27 @@ func (s *actionState) handleSFTPStep(ctx context.Context, env stepEnvelope, raw
 		if err := rfs.Upload(local, remote); err != nil {
 			return stepResult{}, fmt.Errorf("ssh: sftp upload %q failed: %w", env.ID, err)
 		}
+		verified, err := s.verifyTransfer(step.Params, local, remote)
+		if err != nil {
+			return stepResult{}, fmt.Errorf("ssh: sftp upload %q verify: %w", env.ID, err)
+		}
+		if verified != nil {
+			result.Output = map[string]any{"verify": verified}
+		}
+	case "UPLOAD_DIR":
+		local := stringValue(step.Params, "localPath")
+		remote := stringValue(step.Params, "remotePath")
+		if remote == "" || local == "" {
+			return stepResult{}, fmt.Errorf("ssh: sftp upload_dir %q requires remotePath and localPath", env.ID)
+		}
+		transfer, err := newDirTransfer(rfs, step.Params)
+		if err != nil {
+			return stepResult{}, fmt.Errorf("ssh: sftp upload_dir %q: %w", env.ID, err)
+		}
+		if err := transfer.upload(local, remote, ""); err != nil {
+			return stepResult{}, fmt.Errorf("ssh: sftp upload_dir %q failed: %w", env.ID, err)
+		}
+		result.Output = transfer.output()
 	case "WAIT":
 		if err := rfs.Wait(); err != nil {
 			return stepResult{}, fmt.Errorf("ssh: sftp wait %q failed: %w", env.ID, err)
@@ -1119,6 +1661,43 @@ func stringValue(params map[string]any, key string) string {
 	return ""
 }
 
+func boolValue(params map[string]any, key string) bool {
+	if params == nil {
+		return false
+	}
+	switch v := params[key].(type) {
+	case bool:
+		return v
+	case string:
+		parsed, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return parsed
+	}
+	return false
+}
+
+func stringSliceValue(params map[string]any, key string) []string {
+	if params == nil {
+		return nil
+	}
+	switch v := params[key].(type) {
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok && strings.TrimSpace(str) != "" {
+				values = append(values, str)
+			}
+		}
+		return values
+	case []string:
+		return v
+	case string:
+		if strings.TrimSpace(v) != "" {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
 func intValue(params map[string]any, key string) int {
 	if params == nil {
 		return 0
@@ -1,8 +1,32 @@
 package ssh
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"flowk/internal/actions/registry"
+	"flowk/internal/config"
 )
 
 type fakeExitError int
@@ -43,3 +67,857 @@ func TestCommandStepAllowsExit(t *testing.T) {
 		}
 	})
 }
+
+func TestDialAuthenticatesWithAgent(t *testing.T) {
+	signer, keyring := newTestKeyring(t)
+	t.Setenv("SSH_AUTH_SOCK", serveTestAgent(t, keyring))
+	config, _ := testServerConfig(t, signer.PublicKey(), "")
+	addr := startTestSSHServer(t, config)
+
+	conn := connectionSpec{Address: addr, Username: "flowk", Auth: authSpec{Method: "agent"}}
+	client, closeHops, err := conn.dial()
+	if err != nil {
+		t.Fatalf("dial() error = %v", err)
+	}
+	defer closeHops()
+	defer client.Close()
+
+	if got := conn.summary()["authMethod"]; got != "agent" {
+		t.Fatalf("expected authMethod agent, got %v", got)
+	}
+}
+
+func TestDialAutoFallsBackToPassword(t *testing.T) {
+	_, keyring := newTestKeyring(t)
+	t.Setenv("SSH_AUTH_SOCK", serveTestAgent(t, keyring))
+	other := newTestSigner(t)
+	config, _ := testServerConfig(t, other.PublicKey(), "s3cret")
+	addr := startTestSSHServer(t, config)
+
+	conn := connectionSpec{Address: addr, Username: "flowk", Auth: authSpec{Method: "auto", Password: "s3cret"}}
+	client, closeHops, err := conn.dial()
+	if err != nil {
+		t.Fatalf("dial() error = %v", err)
+	}
+	defer closeHops()
+	defer client.Close()
+
+	if got := conn.summary()["authMethod"]; got != "password" {
+		t.Fatalf("expected authMethod password, got %v", got)
+	}
+}
+
+func TestAgentAuthRequiresSocket(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	_, err := (&authSpec{Method: "agent"}).attempts()
+	if err == nil || !strings.Contains(err.Error(), "SSH_AUTH_SOCK") {
+		t.Fatalf("expected SSH_AUTH_SOCK error, got %v", err)
+	}
+
+	_, err = (&authSpec{Method: "auto"}).attempts()
+	if err == nil || !strings.Contains(err.Error(), "no ssh agent") {
+		t.Fatalf("expected auto to report missing credentials, got %v", err)
+	}
+}
+
+func TestDialThroughJumpHosts(t *testing.T) {
+	targetKey, targetPEM := newTestKeyPEM(t)
+	targetConfig, targetHostKey := testServerConfig(t, targetKey.PublicKey(), "")
+	target := startTestSSHServer(t, targetConfig)
+	bastionConfig, bastionHostKey := testServerConfig(t, nil, "bastion-pass")
+	bastion := startTestSSHServer(t, bastionConfig)
+
+	conn := connectionSpec{
+		Address:  target,
+		Username: "deploy",
+		Auth:     authSpec{Method: "private_key", PrivateKeyPEM: targetPEM},
+		HostKey: hostKeySpec{
+			Mode: "known_hosts",
+			InlineEntries: []string{
+				knownhosts.Line([]string{knownhosts.Normalize(bastion)}, bastionHostKey),
+				knownhosts.Line([]string{knownhosts.Normalize(target)}, targetHostKey),
+			},
+		},
+		JumpHosts: []jumpHostSpec{
+			{Address: bastion, Username: "jump", Auth: authSpec{Method: "password", Password: "bastion-pass"}},
+		},
+	}
+	if err := conn.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	client, closeHops, err := conn.dial()
+	if err != nil {
+		t.Fatalf("dial() error = %v", err)
+	}
+	if _, _, err := client.UnderlyingClient().SendRequest("ping@flowk", true, nil); err != nil {
+		t.Fatalf("request through jump host failed: %v", err)
+	}
+	client.Close()
+	closeHops()
+
+	if hops, ok := conn.summary()["jumpHosts"].([]string); !ok || len(hops) != 1 || hops[0] != bastion {
+		t.Fatalf("unexpected jumpHosts summary: %v", conn.summary()["jumpHosts"])
+	}
+
+	// The bastion's host key must be verified as well.
+	conn.HostKey.InlineEntries = conn.HostKey.InlineEntries[1:]
+	if _, _, err := conn.dial(); err == nil || !strings.Contains(err.Error(), "jump host 0") {
+		t.Fatalf("expected jump host key verification error, got %v", err)
+	}
+}
+
+func TestConnectionAppliesConfigDefaults(t *testing.T) {
+	defaults := config.SSHDefaultsConfig{TimeoutSeconds: 20, HostKeyMode: "known_hosts", KnownHostsFiles: []string{"~/.ssh/known_hosts"}}
+
+	spec := connectionSpec{Address: "host:22", Username: "ops"}
+	spec.applyConfigDefaults(defaults)
+	if spec.TimeoutSeconds != 20 || spec.HostKey.Mode != "known_hosts" || len(spec.HostKey.KnownHostsFiles) != 1 {
+		t.Fatalf("connection = %+v, want the config defaults", spec)
+	}
+
+	spec = connectionSpec{Address: "host:22", Username: "ops", TimeoutSeconds: 5, HostKey: hostKeySpec{Mode: "insecure"}}
+	spec.applyConfigDefaults(defaults)
+	if spec.TimeoutSeconds != 5 || spec.HostKey.Mode != "insecure" || len(spec.HostKey.KnownHostsFiles) != 0 {
+		t.Fatalf("connection = %+v, want the task values to win", spec)
+	}
+}
+
+func TestTOFUCallbackRecordsAndEnforcesHostKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ssh", "known_hosts")
+	callback, _, err := (&hostKeySpec{Mode: "tofu", KnownHostsFiles: []string{path}}).build()
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+
+	remote := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+	first := newTestSigner(t).PublicKey()
+	if err := callback("db.example.com:22", remote, first); err != nil {
+		t.Fatalf("first connection should be trusted: %v", err)
+	}
+	if err := callback("db.example.com:22", remote, first); err != nil {
+		t.Fatalf("recorded key should be accepted: %v", err)
+	}
+
+	err = callback("db.example.com:22", remote, newTestSigner(t).PublicKey())
+	if err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Fatalf("expected changed host key error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			host := fmt.Sprintf("node%d.example.com:22", i)
+			if err := callback(host, remote, newTestSigner(t).PublicKey()); err != nil {
+				t.Errorf("callback(%s) error = %v", host, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading known_hosts: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 9 {
+		t.Fatalf("expected 9 known_hosts entries, got %d:\n%s", lines, data)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file to be removed, got %v", err)
+	}
+}
+
+func TestTOFUReplacesStaleLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	lockPath := path + ".lock"
+	if err := os.WriteFile(lockPath, nil, 0o600); err != nil {
+		t.Fatalf("writing lock file: %v", err)
+	}
+	stale := time.Now().Add(-2 * knownHostsLockTimeout)
+	if err := os.Chtimes(lockPath, stale, stale); err != nil {
+		t.Fatalf("aging lock file: %v", err)
+	}
+
+	callback, _, err := (&hostKeySpec{Mode: "tofu", KnownHostsFiles: []string{path}}).build()
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+	remote := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+	if err := callback("db.example.com:22", remote, newTestSigner(t).PublicKey()); err != nil {
+		t.Fatalf("expected stale lock to be replaced, got %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file to be removed, got %v", err)
+	}
+}
+
+func TestTOFURequiresKnownHostsFile(t *testing.T) {
+	if _, _, err := (&hostKeySpec{Mode: "tofu"}).build(); err == nil {
+		t.Fatal("expected error when tofu has no known_hosts file")
+	}
+}
+
+func TestExecuteStreamsCommandOutput(t *testing.T) {
+	logger := &recordingLogger{}
+	result := executeTestSteps(t, logger, `[
+		{"id": "deploy", "operation": "RUN_COMMAND", "commands": ["echo one; echo two >&2; printf three"], "stream": true, "stdout": "capture"},
+		{"id": "version", "operation": "RUN_COMMAND_OUTPUT", "commands": ["echo 1.2.3"], "stream": true},
+		{"id": "script", "operation": "RUN_SCRIPT_SMART_OUTPUT", "script": "echo ok", "stream": true}
+	]`)
+
+	steps := result["steps"].([]stepResult)
+	captured := steps[0].Output.(map[string]any)
+	if captured["stdout"] != "one\nthree" || captured["stderr"] != "" {
+		t.Fatalf("unexpected captured output: %#v", captured)
+	}
+	if steps[1].Output != "1.2.3\n" {
+		t.Fatalf("unexpected RUN_COMMAND_OUTPUT output: %#v", steps[1].Output)
+	}
+	if steps[2].Output != "ok\n" {
+		t.Fatalf("unexpected RUN_SCRIPT_SMART_OUTPUT output: %#v", steps[2].Output)
+	}
+
+	for _, want := range []string{
+		"SSH deploy stdout: one",
+		"SSH deploy stderr: two",
+		"SSH deploy stdout: three",
+		"SSH version stdout: 1.2.3",
+		"SSH script stdout: ok",
+	} {
+		if !logger.contains(want) {
+			t.Fatalf("expected streamed line %q, got %v", want, logger.lines())
+		}
+	}
+}
+
+func TestExecuteStopsStreamingWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := executeTestStepsContext(ctx, t, &recordingLogger{}, `[
+		{"id": "hang", "operation": "RUN_COMMAND", "commands": ["echo started; sleep 10"], "stream": true}
+	]`)
+	if err == nil {
+		t.Fatal("expected cancellation error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("streaming step did not stop on cancellation (took %s)", elapsed)
+	}
+}
+
+func TestExecuteRecordsCommandExitCode(t *testing.T) {
+	result := executeTestSteps(t, nil, `[
+		{"id": "ok", "operation": "RUN_COMMAND", "commands": ["true"]},
+		{"id": "probe", "operation": "RUN_COMMAND_OUTPUT", "commands": ["echo missing; exit 3"], "captureExitCode": true}
+	]`)
+
+	steps := result["steps"].([]stepResult)
+	if steps[0].ExitCode == nil || *steps[0].ExitCode != 0 {
+		t.Fatalf("expected exit code 0 for successful command, got %v", steps[0].ExitCode)
+	}
+	if steps[1].ExitCode == nil || *steps[1].ExitCode != 3 || !steps[1].Success {
+		t.Fatalf("expected recorded exit code 3, got %+v", steps[1])
+	}
+	if steps[1].Output != "missing\n" {
+		t.Fatalf("expected output to be kept, got %#v", steps[1].Output)
+	}
+
+	_, err := executeTestStepsContext(context.Background(), t, nil, `[
+		{"id": "probe", "operation": "RUN_COMMAND", "commands": ["exit 3"]}
+	]`)
+	if err == nil || !strings.Contains(err.Error(), "probe") {
+		t.Fatalf("expected non-zero exit to fail by default, got %v", err)
+	}
+}
+
+func TestExecuteTransfersDirectories(t *testing.T) {
+	source := t.TempDir()
+	writeTestFile(t, filepath.Join(source, "x.txt"), "x")
+	writeTestFile(t, filepath.Join(source, "b", "y.txt"), "y")
+	writeTestFile(t, filepath.Join(source, "b", "debug.log"), "log")
+	if err := os.Symlink(filepath.Join(source, "x.txt"), filepath.Join(source, "link.txt")); err != nil {
+		t.Fatalf("creating symlink: %v", err)
+	}
+	remote := filepath.Join(t.TempDir(), "remote", "tree")
+	local := t.TempDir()
+
+	result := executeTestSteps(t, nil, fmt.Sprintf(`[
+		{"id": "up", "operation": "SFTP", "method": "UPLOAD_DIR", "params": {"localPath": %q, "remotePath": %q, "exclude": ["*.log"]}},
+		{"id": "down", "operation": "SFTP", "method": "DOWNLOAD_DIR", "params": {"remotePath": %q, "localPath": %q}}
+	]`, source, remote, remote, local))
+
+	steps := result["steps"].([]stepResult)
+	want := []string{"b/y.txt", "x.txt"}
+	for i, step := range steps {
+		transferred := step.Output.(map[string]any)["transferred"].([]string)
+		if strings.Join(transferred, ",") != strings.Join(want, ",") {
+			t.Fatalf("step %d transferred %v, want %v", i, transferred, want)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(local, "b", "y.txt")); err != nil || string(data) != "y" {
+		t.Fatalf("expected downloaded b/y.txt, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(remote, "b", "debug.log")); !os.IsNotExist(err) {
+		t.Fatalf("expected debug.log to be excluded, got %v", err)
+	}
+
+	followed := executeTestSteps(t, nil, fmt.Sprintf(`[
+		{"id": "up", "operation": "SFTP", "method": "UPLOAD_DIR", "params": {"localPath": %q, "remotePath": %q, "exclude": ["b"], "followSymlinks": true}}
+	]`, source, filepath.Join(t.TempDir(), "followed")))
+	transferred := followed["steps"].([]stepResult)[0].Output.(map[string]any)["transferred"].([]string)
+	if strings.Join(transferred, ",") != "link.txt,x.txt" {
+		t.Fatalf("expected followed symlink to be uploaded, got %v", transferred)
+	}
+}
+
+func TestExecuteDirectoryTransferContinuesOnError(t *testing.T) {
+	remote := t.TempDir()
+	writeTestFile(t, filepath.Join(remote, "a.txt"), "a")
+	writeTestFile(t, filepath.Join(remote, "b.txt"), "b")
+	local := t.TempDir()
+	// A directory in place of a.txt makes that download fail.
+	if err := os.MkdirAll(filepath.Join(local, "a.txt"), 0o755); err != nil {
+		t.Fatalf("creating blocker: %v", err)
+	}
+
+	steps := fmt.Sprintf(`[
+		{"id": "down", "operation": "SFTP", "method": "DOWNLOAD_DIR", "params": {"remotePath": %q, "localPath": %q, "continueOnError": %%t}}
+	]`, remote, local)
+
+	if _, err := executeTestStepsContext(context.Background(), t, nil, fmt.Sprintf(steps, false)); err == nil || !strings.Contains(err.Error(), "a.txt") {
+		t.Fatalf("expected a.txt failure, got %v", err)
+	}
+
+	result := executeTestSteps(t, nil, fmt.Sprintf(steps, true))
+	output := result["steps"].([]stepResult)[0].Output.(map[string]any)
+	if transferred := output["transferred"].([]string); len(transferred) != 1 || transferred[0] != "b.txt" {
+		t.Fatalf("expected b.txt to be transferred, got %v", transferred)
+	}
+	if failures := output["errors"].([]map[string]any); len(failures) != 1 || failures[0]["path"] != "a.txt" {
+		t.Fatalf("expected a.txt failure to be reported, got %v", output["errors"])
+	}
+}
+
+func TestExecuteVerifiesTransferChecksums(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "artifact.bin")
+	writeTestFile(t, source, "payload")
+	remote := filepath.Join(dir, "remote artifact.bin")
+	local := filepath.Join(dir, "copy.bin")
+
+	result := executeTestSteps(t, nil, fmt.Sprintf(`[
+		{"id": "up", "operation": "SFTP", "method": "UPLOAD", "params": {"localPath": %q, "remotePath": %q, "verify": "sha256"}},
+		{"id": "down", "operation": "SFTP", "method": "DOWNLOAD", "params": {"remotePath": %q, "localPath": %q, "verify": "md5", "verifyCommand": "md5sum < {path}"}}
+	]`, source, remote, remote, local))
+
+	steps := result["steps"].([]stepResult)
+	want := map[string]string{
+		"sha256": "239f59ed55e737c77147cf55ad0c1b030b6d7ee748a7426952f9b852d5a935e5",
+		"md5":    "321c3cf486ed509164edec1e1981fec8",
+	}
+	for _, step := range steps {
+		verified := step.Output.(map[string]any)["verify"].(map[string]any)
+		algorithm := verified["algorithm"].(string)
+		if verified["local"] != want[algorithm] || verified["remote"] != want[algorithm] {
+			t.Fatalf("unexpected %s digests: %v", algorithm, verified)
+		}
+	}
+
+	_, err := executeTestStepsContext(context.Background(), t, nil, fmt.Sprintf(`[
+		{"id": "up", "operation": "SFTP", "method": "UPLOAD", "params": {"localPath": %q, "remotePath": %q, "verify": "sha256", "verifyCommand": "echo deadbeef"}}
+	]`, source, remote))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") || !strings.Contains(err.Error(), want["sha256"]) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestForwardStepsTunnelConnections(t *testing.T) {
+	echo := startTestEchoServer(t)
+	config, _ := testServerConfig(t, nil, "pass")
+	addr := startTestSSHServer(t, config)
+
+	conn := connectionSpec{Address: addr, Username: "flowk", Auth: authSpec{Method: "password", Password: "pass"}}
+	client, closeHops, err := conn.dial()
+	if err != nil {
+		t.Fatalf("dial() error = %v", err)
+	}
+	defer closeHops()
+	defer client.Close()
+
+	state := newActionState(client, payloadSpec{}, nil)
+	defer state.Close()
+	run := func(ctx context.Context, step string) map[string]any {
+		t.Helper()
+		res, err := state.executeStep(ctx, 0, json.RawMessage(step))
+		if err != nil {
+			t.Fatalf("executeStep(%s) error = %v", step, err)
+		}
+		return res.Output.(map[string]any)
+	}
+
+	local := run(context.Background(), fmt.Sprintf(`{"id": "db", "operation": "LOCAL_FORWARD", "remoteAddr": %q}`, echo))
+	localAddr := local["localAddr"].(string)
+	assertTestEcho(t, localAddr)
+
+	if _, err := state.executeStep(context.Background(), 0, json.RawMessage(`{"id": "db", "operation": "LOCAL_FORWARD", "remoteAddr": "127.0.0.1:1"}`)); err == nil {
+		t.Fatal("expected duplicate forward id to fail")
+	}
+
+	stopped := run(context.Background(), `{"id": "stop", "operation": "STOP_FORWARD", "forwardId": "db"}`)
+	if stopped["stopped"] != true {
+		t.Fatalf("expected forward to be stopped, got %v", stopped)
+	}
+	if c, err := net.Dial("tcp", localAddr); err == nil {
+		c.Close()
+		t.Fatal("expected stopped forward to refuse connections")
+	}
+
+	remote := run(context.Background(), fmt.Sprintf(`{"id": "callback", "operation": "REMOTE_FORWARD", "localAddr": %q}`, echo))
+	assertTestEcho(t, remote["remoteAddr"].(string))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := run(ctx, fmt.Sprintf(`{"id": "short", "operation": "LOCAL_FORWARD", "remoteAddr": %q}`, echo))
+	cancel()
+	<-state.forwards["short"].done
+	if c, err := net.Dial("tcp", cancelled["localAddr"].(string)); err == nil {
+		c.Close()
+		t.Fatal("expected cancelled forward to refuse connections")
+	}
+}
+
+func startTestEchoServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func assertTestEcho(t *testing.T, addr string) {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("dial %s: %v", addr, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+		t.Fatalf("expected echo through %s, got %q (%v)", addr, reply, err)
+	}
+}
+
+func TestExecuteParsesCommandOutput(t *testing.T) {
+	result := executeTestSteps(t, nil, `[
+		{"id": "json", "operation": "RUN_COMMAND_OUTPUT", "commands": ["printf '{\"items\": [{\"name\": \"api\"}]}'"], "parse": "json"},
+		{"id": "lines", "operation": "RUN_SCRIPT_OUTPUT", "script": "echo one; echo two", "parse": "lines"},
+		{"id": "broken", "operation": "RUN_COMMAND_OUTPUT", "commands": ["echo not-json"], "parse": "json"},
+		{"id": "captured", "operation": "RUN_COMMAND", "commands": ["echo '[1, 2]'"], "stdout": "capture", "parse": "json"}
+	]`)
+	steps := result["steps"].([]stepResult)
+
+	items := steps[0].Output.(map[string]any)["items"].([]any)
+	if name := items[0].(map[string]any)["name"]; name != "api" {
+		t.Fatalf("expected parsed JSON output, got %#v", steps[0].Output)
+	}
+	if lines, ok := steps[1].Output.([]string); !ok || strings.Join(lines, ",") != "one,two" {
+		t.Fatalf("expected output lines, got %#v", steps[1].Output)
+	}
+	if steps[2].Output != "not-json\n" || steps[2].ParseError == "" {
+		t.Fatalf("expected raw output with parseError, got %#v (%q)", steps[2].Output, steps[2].ParseError)
+	}
+	if stdout, ok := steps[3].Output.(map[string]any)["stdout"].([]any); !ok || len(stdout) != 2 {
+		t.Fatalf("expected parsed captured stdout, got %#v", steps[3].Output)
+	}
+
+	if _, err := executeTestStepsContext(context.Background(), t, nil, `[
+		{"id": "bad", "operation": "RUN_COMMAND_OUTPUT", "commands": ["true"], "parse": "yaml"}
+	]`); err == nil || !strings.Contains(err.Error(), "unsupported parse") {
+		t.Fatalf("expected unsupported parse error, got %v", err)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("creating directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) PrintColored(plain, _ string) {
+	l.Printf("%s", plain)
+}
+
+func (l *recordingLogger) lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.messages...)
+}
+
+func (l *recordingLogger) contains(line string) bool {
+	for _, message := range l.lines() {
+		if message == line {
+			return true
+		}
+	}
+	return false
+}
+
+func executeTestSteps(t *testing.T, logger registry.Logger, steps string) map[string]any {
+	t.Helper()
+	result, err := executeTestStepsContext(context.Background(), t, logger, steps)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	return result
+}
+
+// executeTestStepsContext runs the steps through Action.Execute against an
+// in-process server that executes commands with the local shell.
+func executeTestStepsContext(ctx context.Context, t *testing.T, logger registry.Logger, steps string) (map[string]any, error) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test SSH server runs commands with /bin/sh")
+	}
+
+	config, _ := testServerConfig(t, nil, "pass")
+	addr := startTestSSHServer(t, config)
+	payload := fmt.Sprintf(`{
+		"connection": {"address": %q, "username": "flowk", "auth": {"method": "password", "password": "pass"}},
+		"steps": %s
+	}`, addr, steps)
+
+	result, err := (Action{}).Execute(ctx, json.RawMessage(payload), &registry.ExecutionContext{Logger: logger})
+	if err != nil {
+		return nil, err
+	}
+	return result.Value.(map[string]any), nil
+}
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("creating signer: %v", err)
+	}
+	return signer
+}
+
+func newTestKeyPEM(t *testing.T) (ssh.Signer, string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("creating signer: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("marshalling key: %v", err)
+	}
+	return signer, string(pem.EncodeToMemory(block))
+}
+
+func newTestKeyring(t *testing.T) (ssh.Signer, agent.Agent) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("creating signer: %v", err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+		t.Fatalf("adding key to agent: %v", err)
+	}
+	return signer, keyring
+}
+
+func serveTestAgent(t *testing.T, keyring agent.Agent) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listening on agent socket: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	return socket
+}
+
+// testServerConfig accepts the given public key and, when set, the password.
+// It also returns the server host key.
+func testServerConfig(t *testing.T, authorized ssh.PublicKey, password string) (*ssh.ServerConfig, ssh.PublicKey) {
+	t.Helper()
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorized != nil && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown public key")
+		},
+	}
+	if password != "" {
+		config.PasswordCallback = func(_ ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if string(pass) == password {
+				return nil, nil
+			}
+			return nil, errors.New("wrong password")
+		}
+	}
+	hostKey := newTestSigner(t)
+	config.AddHostKey(hostKey)
+	return config, hostKey.PublicKey()
+}
+
+// startTestSSHServer runs an in-process SSH server on a loopback port and
+// returns its address.
+func startTestSSHServer(t *testing.T, config *ssh.ServerConfig) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHConn(conn, config)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveTestSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	serverConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+	go serveTestGlobalRequests(serverConn, reqs)
+
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "direct-tcpip":
+			go forwardTestChannel(newChannel)
+		case "session":
+			go serveTestSession(newChannel)
+		default:
+			_ = newChannel.Reject(ssh.UnknownChannelType, "not supported")
+		}
+	}
+}
+
+// serveTestSession runs exec and shell requests with the local /bin/sh.
+func serveTestSession(newChannel ssh.NewChannel) {
+	channel, reqs, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	for req := range reqs {
+		switch req.Type {
+		case "exec":
+			var payload struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			go runTestCommand(channel, exec.Command("/bin/sh", "-c", payload.Command), false)
+		case "shell":
+			_ = req.Reply(true, nil)
+			go runTestCommand(channel, exec.Command("/bin/sh"), true)
+		case "subsystem":
+			var payload struct{ Name string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil || payload.Name != "sftp" {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			go func() {
+				defer channel.Close()
+				server, err := sftp.NewServer(channel)
+				if err != nil {
+					return
+				}
+				_ = server.Serve()
+			}()
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+func runTestCommand(channel ssh.Channel, cmd *exec.Cmd, withStdin bool) {
+	defer channel.Close()
+	if withStdin {
+		cmd.Stdin = channel
+	}
+	cmd.Stdout = channel
+	cmd.Stderr = channel.Stderr()
+
+	status := uint32(0)
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			status = 255
+		} else {
+			status = uint32(exitErr.ExitCode())
+		}
+	}
+	_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+}
+
+// forwardTestChannel lets the test server act as a jump host.
+// serveTestGlobalRequests handles tcpip-forward by listening locally and
+// opening a forwarded-tcpip channel back to the client for every connection.
+func serveTestGlobalRequests(serverConn *ssh.ServerConn, reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		if req.Type != "tcpip-forward" {
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+			continue
+		}
+		var bind struct {
+			Addr string
+			Port uint32
+		}
+		if err := ssh.Unmarshal(req.Payload, &bind); err != nil {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		listener, err := net.Listen("tcp", net.JoinHostPort(bind.Addr, strconv.Itoa(int(bind.Port))))
+		if err != nil {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		port := uint32(listener.Addr().(*net.TCPAddr).Port)
+		_ = req.Reply(true, ssh.Marshal(struct{ Port uint32 }{port}))
+
+		go func() {
+			_ = serverConn.Wait()
+			listener.Close()
+		}()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				origin := conn.RemoteAddr().(*net.TCPAddr)
+				payload := ssh.Marshal(struct {
+					Addr       string
+					Port       uint32
+					OriginAddr string
+					OriginPort uint32
+				}{bind.Addr, port, origin.IP.String(), uint32(origin.Port)})
+				channel, channelReqs, err := serverConn.OpenChannel("forwarded-tcpip", payload)
+				if err != nil {
+					conn.Close()
+					continue
+				}
+				go ssh.DiscardRequests(channelReqs)
+				go func() {
+					done := make(chan struct{}, 2)
+					go func() { _, _ = io.Copy(channel, conn); done <- struct{}{} }()
+					go func() { _, _ = io.Copy(conn, channel); done <- struct{}{} }()
+					<-done
+					channel.Close()
+					conn.Close()
+				}()
+			}
+		}()
+	}
+}
+
+func forwardTestChannel(newChannel ssh.NewChannel) {
+	var target struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, reqs, err := newChannel.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	done := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(channel, conn); done <- struct{}{} }()
+	go func() { _, _ = io.Copy(conn, channel); done <- struct{}{} }()
+	<-done
+	channel.Close()
+	conn.Close()
+}
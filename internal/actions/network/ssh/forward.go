@@ -0,0 +1,209 @@
+package ssh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+const defaultForwardAddr = "127.0.0.1:0"
+
+// forwardStep opens or stops a port forward. LOCAL_FORWARD listens on
+// localAddr and connects to remoteAddr through the SSH host; REMOTE_FORWARD
+// listens on remoteAddr on the SSH host and connects back to localAddr.
+type forwardStep struct {
+	ID         string `json:"id"`
+	LocalAddr  string `json:"localAddr"`
+	RemoteAddr string `json:"remoteAddr"`
+	ForwardID  string `json:"forwardId"`
+}
+
+// forward is a running tunnel. Its accept loop and open connections are torn
+// down when the tunnel is stopped, the task context is done or the action
+// closes.
+type forward struct {
+	listener net.Listener
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func (s *actionState) handleForwardStep(ctx context.Context, env stepEnvelope, raw json.RawMessage, op string) (stepResult, error) {
+	var step forwardStep
+	if err := json.Unmarshal(raw, &step); err != nil {
+		return stepResult{}, fmt.Errorf("ssh: decode forward step %q: %w", env.ID, err)
+	}
+	result := stepResult{ID: env.ID, Operation: env.Operation, Success: true}
+
+	if op == "STOP_FORWARD" {
+		id := strings.TrimSpace(step.ForwardID)
+		if id == "" {
+			return stepResult{}, fmt.Errorf("ssh: stop_forward %q requires forwardId", env.ID)
+		}
+		stopped := s.stopForward(id)
+		result.Output = map[string]any{"forwardId": id, "stopped": stopped}
+		return result, nil
+	}
+
+	id := strings.TrimSpace(env.ID)
+	if id == "" {
+		return stepResult{}, fmt.Errorf("ssh: %s step requires id", strings.ToLower(op))
+	}
+	if _, exists := s.forwards[id]; exists {
+		return stepResult{}, fmt.Errorf("ssh: forward %q is already running", id)
+	}
+
+	localAddr := strings.TrimSpace(step.LocalAddr)
+	remoteAddr := strings.TrimSpace(step.RemoteAddr)
+	client := s.client.UnderlyingClient()
+
+	var (
+		listener net.Listener
+		dial     func() (net.Conn, error)
+		err      error
+	)
+	if op == "LOCAL_FORWARD" {
+		if remoteAddr == "" {
+			return stepResult{}, fmt.Errorf("ssh: local_forward %q requires remoteAddr", env.ID)
+		}
+		if localAddr == "" {
+			localAddr = defaultForwardAddr
+		}
+		listener, err = net.Listen("tcp", localAddr)
+		dial = func() (net.Conn, error) { return client.Dial("tcp", remoteAddr) }
+	} else {
+		if localAddr == "" {
+			return stepResult{}, fmt.Errorf("ssh: remote_forward %q requires localAddr", env.ID)
+		}
+		if remoteAddr == "" {
+			remoteAddr = defaultForwardAddr
+		}
+		listener, err = client.Listen("tcp", remoteAddr)
+		dial = func() (net.Conn, error) { return net.Dial("tcp", localAddr) }
+	}
+	if err != nil {
+		return stepResult{}, fmt.Errorf("ssh: %s %q listen failed: %w", strings.ToLower(op), env.ID, err)
+	}
+
+	fwdCtx, cancel := context.WithCancel(ctx)
+	fwd := &forward{
+		listener: listener,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	if s.forwards == nil {
+		s.forwards = make(map[string]*forward)
+	}
+	s.forwards[id] = fwd
+	go fwd.serve(fwdCtx, dial)
+
+	output := map[string]any{"id": id}
+	if op == "LOCAL_FORWARD" {
+		output["localAddr"] = listener.Addr().String()
+		output["remoteAddr"] = remoteAddr
+	} else {
+		output["localAddr"] = localAddr
+		output["remoteAddr"] = listener.Addr().String()
+	}
+	if s.logger != nil {
+		s.logger.Printf("SSH %s: %s %s -> %s", id, strings.ToLower(op), output["localAddr"], output["remoteAddr"])
+	}
+	result.Output = output
+	return result, nil
+}
+
+// stopForward tears down the tunnel registered under id and reports whether
+// one was running.
+func (s *actionState) stopForward(id string) bool {
+	fwd, ok := s.forwards[id]
+	if !ok {
+		return false
+	}
+	delete(s.forwards, id)
+	fwd.stop()
+	return true
+}
+
+func (f *forward) serve(ctx context.Context, dial func() (net.Conn, error)) {
+	defer close(f.done)
+	go func() {
+		<-ctx.Done()
+		_ = f.listener.Close()
+		f.closeConns()
+	}()
+
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			f.cancel()
+			return
+		}
+		go f.pipe(conn, dial)
+	}
+}
+
+func (f *forward) pipe(conn net.Conn, dial func() (net.Conn, error)) {
+	if !f.track(conn) {
+		_ = conn.Close()
+		return
+	}
+	defer f.untrack(conn)
+
+	target, err := dial()
+	if err != nil {
+		return
+	}
+	if !f.track(target) {
+		_ = target.Close()
+		return
+	}
+	defer f.untrack(target)
+
+	done := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(target, conn); done <- struct{}{} }()
+	go func() { _, _ = io.Copy(conn, target); done <- struct{}{} }()
+	<-done
+}
+
+// track registers conn so stop can close it. It returns false once the
+// tunnel is shutting down.
+func (f *forward) track(conn net.Conn) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conns == nil {
+		return false
+	}
+	f.conns[conn] = struct{}{}
+	return true
+}
+
+func (f *forward) untrack(conn net.Conn) {
+	f.mu.Lock()
+	if f.conns != nil {
+		delete(f.conns, conn)
+	}
+	f.mu.Unlock()
+	_ = conn.Close()
+}
+
+func (f *forward) closeConns() {
+	f.mu.Lock()
+	conns := f.conns
+	f.conns = nil
+	f.mu.Unlock()
+	for conn := range conns {
+		_ = conn.Close()
+	}
+}
+
+func (f *forward) stop() {
+	f.cancel()
+	<-f.done
+}
@@ -0,0 +1,303 @@
+package ssh
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	sshclient "github.com/helloyi/go-sshclient"
+)
+
+// dirTransfer copies a directory tree between the local host and the remote
+// file system for the UPLOAD_DIR and DOWNLOAD_DIR methods.
+type dirTransfer struct {
+	rfs             *sshclient.RemoteFileSystem
+	exclude         []string
+	followSymlinks  bool
+	continueOnError bool
+
+	transferred []string
+	failures    []map[string]any
+	visited     map[string]bool
+}
+
+func newDirTransfer(rfs *sshclient.RemoteFileSystem, params map[string]any) (*dirTransfer, error) {
+	exclude := stringSliceValue(params, "exclude")
+	for _, pattern := range exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return &dirTransfer{
+		rfs:             rfs,
+		exclude:         exclude,
+		followSymlinks:  boolValue(params, "followSymlinks"),
+		continueOnError: boolValue(params, "continueOnError"),
+		visited:         make(map[string]bool),
+	}, nil
+}
+
+// output lists the transferred files, relative to the source root, and the
+// files skipped because of errors when continueOnError is set.
+func (t *dirTransfer) output() map[string]any {
+	transferred := t.transferred
+	if transferred == nil {
+		transferred = []string{}
+	}
+	output := map[string]any{"transferred": transferred}
+	if len(t.failures) > 0 {
+		output["errors"] = t.failures
+	}
+	return output
+}
+
+// excluded matches the patterns against the relative path and its base name.
+func (t *dirTransfer) excluded(rel string) bool {
+	for _, pattern := range t.exclude {
+		if matched, _ := path.Match(pattern, rel); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// fail records the error and lets the transfer go on when continueOnError is
+// set; otherwise it returns the error to abort the transfer.
+func (t *dirTransfer) fail(rel string, err error) error {
+	if t.continueOnError {
+		t.failures = append(t.failures, map[string]any{"path": rel, "error": err.Error()})
+		return nil
+	}
+	return fmt.Errorf("%s: %w", rel, err)
+}
+
+func (t *dirTransfer) upload(localDir, remoteDir, rel string) error {
+	if resolved, err := filepath.EvalSymlinks(localDir); err == nil {
+		if t.visited[resolved] {
+			return nil
+		}
+		t.visited[resolved] = true
+	}
+
+	if err := t.rfs.MkdirAll(remoteDir); err != nil {
+		return t.fail(displayPath(rel), err)
+	}
+	entries, err := os.ReadDir(localDir)
+	if err != nil {
+		return t.fail(displayPath(rel), err)
+	}
+
+	for _, entry := range entries {
+		entryRel := path.Join(rel, entry.Name())
+		if t.excluded(entryRel) {
+			continue
+		}
+		local := filepath.Join(localDir, entry.Name())
+		remote := path.Join(remoteDir, entry.Name())
+
+		isDir := entry.IsDir()
+		switch {
+		case entry.Type()&os.ModeSymlink != 0:
+			if !t.followSymlinks {
+				continue
+			}
+			info, err := os.Stat(local)
+			if err != nil {
+				if err := t.fail(entryRel, err); err != nil {
+					return err
+				}
+				continue
+			}
+			isDir = info.IsDir()
+		case !isDir && !entry.Type().IsRegular():
+			continue
+		}
+
+		if isDir {
+			if err := t.upload(local, remote, entryRel); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := t.rfs.Upload(local, remote); err != nil {
+			if err := t.fail(entryRel, err); err != nil {
+				return err
+			}
+			continue
+		}
+		t.transferred = append(t.transferred, entryRel)
+	}
+	return nil
+}
+
+func (t *dirTransfer) download(remoteDir, localDir, rel string) error {
+	if resolved, err := t.rfs.RealPath(remoteDir); err == nil {
+		if t.visited[resolved] {
+			return nil
+		}
+		t.visited[resolved] = true
+	}
+
+	if err := os.MkdirAll(localDir, 0o755); err != nil {
+		return t.fail(displayPath(rel), err)
+	}
+	entries, err := t.rfs.ReadDir(remoteDir)
+	if err != nil {
+		return t.fail(displayPath(rel), err)
+	}
+
+	for _, entry := range entries {
+		entryRel := path.Join(rel, entry.Name())
+		if t.excluded(entryRel) {
+			continue
+		}
+		remote := path.Join(remoteDir, entry.Name())
+		local := filepath.Join(localDir, entry.Name())
+
+		isDir := entry.IsDir()
+		switch {
+		case entry.Mode()&os.ModeSymlink != 0:
+			if !t.followSymlinks {
+				continue
+			}
+			info, err := t.rfs.Stat(remote)
+			if err != nil {
+				if err := t.fail(entryRel, err); err != nil {
+					return err
+				}
+				continue
+			}
+			isDir = info.IsDir()
+		case !isDir && !entry.Mode().IsRegular():
+			continue
+		}
+
+		if isDir {
+			if err := t.download(remote, local, entryRel); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := t.rfs.Download(remote, local); err != nil {
+			if err := t.fail(entryRel, err); err != nil {
+				return err
+			}
+			continue
+		}
+		t.transferred = append(t.transferred, entryRel)
+	}
+	return nil
+}
+
+func displayPath(rel string) string {
+	if rel == "" {
+		return "."
+	}
+	return rel
+}
+
+// checksumCommands are the remote commands used to hash a transferred file
+// when the step does not override them with verifyCommand.
+var checksumCommands = map[string]string{
+	"sha256": "sha256sum",
+	"md5":    "md5sum",
+}
+
+func newChecksum(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), nil
+	case "md5":
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported verify algorithm %q", algorithm)
+	}
+}
+
+// localChecksum returns the hex digest of the local file.
+func localChecksum(algorithm, name string) (string, error) {
+	h, err := newChecksum(algorithm)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// remoteChecksum runs the checksum command over the connection and returns
+// the first field of its output, which is where sha256sum and md5sum print
+// the digest. The quoted path replaces {path} in the command or is appended.
+func (s *actionState) remoteChecksum(algorithm, command, name string) (string, error) {
+	if command == "" {
+		command = checksumCommands[algorithm]
+	}
+	quoted := shellQuote(name)
+	if strings.Contains(command, "{path}") {
+		command = strings.ReplaceAll(command, "{path}", quoted)
+	} else {
+		command += " " + quoted
+	}
+
+	output, err := s.client.Cmd(command).Output()
+	if err != nil {
+		return "", fmt.Errorf("remote checksum command %q failed: %w", command, err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("remote checksum command %q returned no digest", command)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// verifyTransfer compares the digests of both ends of an UPLOAD or DOWNLOAD
+// when the step sets verify. It returns nil output when verification is off.
+func (s *actionState) verifyTransfer(params map[string]any, local, remote string) (map[string]any, error) {
+	algorithm := strings.ToLower(strings.TrimSpace(stringValue(params, "verify")))
+	if algorithm == "" {
+		return nil, nil
+	}
+	if _, err := newChecksum(algorithm); err != nil {
+		return nil, err
+	}
+
+	localSum, err := localChecksum(algorithm, local)
+	if err != nil {
+		return nil, fmt.Errorf("local checksum: %w", err)
+	}
+	remoteSum, err := s.remoteChecksum(algorithm, stringValue(params, "verifyCommand"), remote)
+	if err != nil {
+		return nil, err
+	}
+
+	output := map[string]any{
+		"algorithm": algorithm,
+		"local":     localSum,
+		"remote":    remoteSum,
+	}
+	if localSum != remoteSum {
+		return output, fmt.Errorf("%s checksum mismatch: local %s, remote %s", algorithm, localSum, remoteSum)
+	}
+	return output, nil
+}
+
+// shellQuote wraps value in single quotes for a POSIX shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -33,6 +33,9 @@ type ExecutionContext struct {
 	Logger      Logger
 	LogDir      string
 	ExecuteTask TaskExecutor
+	// Mask hides secret values and mask_patterns matches in text an action
+	// writes outside the logger. It is nil when nothing needs masking.
+	Mask func(string) string
 }
 
 // TaskExecutionRequest describes a task that should be executed on behalf of an action.
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -12,7 +14,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/openpgp"
 	"golang.org/x/crypto/openpgp/armor"
@@ -158,11 +162,26 @@ type signResult struct {
 	SignerKeyID       string      `json:"signerKeyId"`
 }
 
+type multiSignResult struct {
+	Signatures []signResult `json:"signatures"`
+}
+
 type verifyResult struct {
+	Verified          bool             `json:"verified"`
+	SignerAlias       string           `json:"signerAlias,omitempty"`
+	SignerFingerprint string           `json:"signerFingerprint,omitempty"`
+	SignerKeyID       string           `json:"signerKeyId,omitempty"`
+	Require           string           `json:"require,omitempty"`
+	Signatures        []signatureCheck `json:"signatures,omitempty"`
+}
+
+type signatureCheck struct {
+	Index             int    `json:"index"`
 	Verified          bool   `json:"verified"`
 	SignerAlias       string `json:"signerAlias,omitempty"`
 	SignerFingerprint string `json:"signerFingerprint,omitempty"`
 	SignerKeyID       string `json:"signerKeyId,omitempty"`
+	Error             string `json:"error,omitempty"`
 }
 
 type importKeyStep struct {
@@ -205,41 +224,85 @@ type generateKeyStep struct {
 	Name           string `json:"name"`
 	Email          string `json:"email"`
 	Comment        string `json:"comment"`
+	Algorithm      string `json:"algorithm"`
 	RSABits        int    `json:"rsaBits"`
+	ExpiresInDays  int    `json:"expiresInDays"`
+	Passphrase     string `json:"passphrase"`
 	PrivateKeyPath string `json:"privateKeyPath"`
 	PublicKeyPath  string `json:"publicKeyPath"`
 }
 
 type generateKeyResult struct {
 	Alias          string            `json:"alias"`
+	Algorithm      string            `json:"algorithm"`
 	Armored        bool              `json:"armored"`
 	Fingerprint    string            `json:"fingerprint"`
 	KeyID          string            `json:"keyId"`
 	PrivateKey     encodedData       `json:"privateKey"`
 	PublicKey      encodedData       `json:"publicKey"`
+	Protected      bool              `json:"passphraseProtected"`
+	ExpiresAt      string            `json:"expiresAt,omitempty"`
 	PrivateKeyPath string            `json:"privateKeyPath,omitempty"`
 	PublicKeyPath  string            `json:"publicKeyPath,omitempty"`
 	Identities     map[string]string `json:"identities,omitempty"`
 }
 
-type signDetachedStep struct {
+type exportKeyStep struct {
 	baseStep
-	SignWith       string `json:"signWith"`
-	Message        string `json:"message"`
-	MessagePath    string `json:"messagePath"`
+	Alias          string `json:"alias"`
+	IncludePrivate bool   `json:"includePrivate"`
 	Armor          *bool  `json:"armor"`
-	OutputPath     string `json:"outputPath"`
-	TextMode       *bool  `json:"textMode"`
-	ResultEncoding string `json:"resultEncoding"`
+	PrivateKeyPath string `json:"privateKeyPath"`
+	PublicKeyPath  string `json:"publicKeyPath"`
+}
+
+type exportKeyResult struct {
+	Alias          string       `json:"alias"`
+	Armored        bool         `json:"armored"`
+	Fingerprints   []string     `json:"fingerprints"`
+	KeyIDs         []string     `json:"keyIds"`
+	PublicKey      encodedData  `json:"publicKey"`
+	PrivateKey     *encodedData `json:"privateKey,omitempty"`
+	PrivateKeyPath string       `json:"privateKeyPath,omitempty"`
+	PublicKeyPath  string       `json:"publicKeyPath,omitempty"`
+}
+
+type listKeysResult struct {
+	Aliases []aliasSummary `json:"aliases"`
+}
+
+type aliasSummary struct {
+	Alias           string            `json:"alias"`
+	Source          string            `json:"source"`
+	ContainsPrivate bool              `json:"containsPrivate"`
+	Fingerprints    []string          `json:"fingerprints"`
+	KeyIDs          []string          `json:"keyIds"`
+	Identities      map[string]string `json:"identities,omitempty"`
+}
+
+type signDetachedStep struct {
+	baseStep
+	SignWith       string   `json:"signWith"`
+	SignWithAll    []string `json:"signWithAll"`
+	KeyIDs         []string `json:"keyIds"`
+	Message        string   `json:"message"`
+	MessagePath    string   `json:"messagePath"`
+	Armor          *bool    `json:"armor"`
+	OutputPath     string   `json:"outputPath"`
+	TextMode       *bool    `json:"textMode"`
+	ResultEncoding string   `json:"resultEncoding"`
 }
 
 type verifyDetachedStep struct {
 	baseStep
-	Message       string   `json:"message"`
-	MessagePath   string   `json:"messagePath"`
-	Signature     string   `json:"signature"`
-	SignaturePath string   `json:"signaturePath"`
-	KeyAliases    []string `json:"keyAliases"`
+	Message        string   `json:"message"`
+	MessagePath    string   `json:"messagePath"`
+	Signature      string   `json:"signature"`
+	SignaturePath  string   `json:"signaturePath"`
+	Signatures     []string `json:"signatures"`
+	SignaturePaths []string `json:"signaturePaths"`
+	Require        string   `json:"require"`
+	KeyAliases     []string `json:"keyAliases"`
 }
 
 func newActionState(execCtx *registry.ExecutionContext) *actionState {
@@ -252,6 +315,13 @@ func newActionState(execCtx *registry.ExecutionContext) *actionState {
 
 var defaultConfig = &packet.Config{DefaultHash: crypto.SHA256}
 
+// Key algorithms accepted by GENERATE_KEY.
+const (
+	keyAlgorithmRSA     = "rsa"
+	keyAlgorithmECDSA   = "ecdsa"
+	keyAlgorithmEd25519 = "ed25519"
+)
+
 func (s *actionState) executeStep(ctx context.Context, idx int, raw json.RawMessage) (stepOutcome, error) {
 	var base baseStep
 	if err := json.Unmarshal(raw, &base); err != nil {
@@ -281,6 +351,14 @@ func (s *actionState) executeStep(ctx context.Context, idx int, raw json.RawMess
 			return stepOutcome{}, fmt.Errorf("pgp: steps[%d]: decode import_key: %w", idx, err)
 		}
 		result, err = s.executeImportKey(step)
+	case "EXPORT_KEY":
+		var step exportKeyStep
+		if err := json.Unmarshal(raw, &step); err != nil {
+			return stepOutcome{}, fmt.Errorf("pgp: steps[%d]: decode export_key: %w", idx, err)
+		}
+		result, err = s.executeExportKey(step)
+	case "LIST_KEYS":
+		result = s.executeListKeys()
 	case "ENCRYPT":
 		var step encryptStep
 		if err := json.Unmarshal(raw, &step); err != nil {
@@ -298,7 +376,11 @@ func (s *actionState) executeStep(ctx context.Context, idx int, raw json.RawMess
 		if err := json.Unmarshal(raw, &step); err != nil {
 			return stepOutcome{}, fmt.Errorf("pgp: steps[%d]: decode sign_detached: %w", idx, err)
 		}
-		result, err = s.executeSignDetached(step)
+		if len(step.SignWithAll) > 0 {
+			result, err = s.executeSignDetachedAll(step)
+		} else {
+			result, err = s.executeSignDetached(step)
+		}
 	case "VERIFY_DETACHED":
 		var step verifyDetachedStep
 		if err := json.Unmarshal(raw, &step); err != nil {
@@ -331,7 +413,15 @@ func (s *actionState) executeGenerateKey(step generateKeyStep) (generateKeyResul
 		return generateKeyResult{}, errors.New("pgp: generate_key requires at least a name or email")
 	}
 
+	algorithm := strings.ToLower(strings.TrimSpace(step.Algorithm))
+	if algorithm == "" {
+		algorithm = keyAlgorithmRSA
+	}
+
 	rsaBits := step.RSABits
+	if rsaBits != 0 && algorithm != keyAlgorithmRSA {
+		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: rsaBits only applies to the %s algorithm", alias, keyAlgorithmRSA)
+	}
 	if rsaBits < 0 {
 		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: rsaBits cannot be negative", alias)
 	}
@@ -339,21 +429,54 @@ func (s *actionState) executeGenerateKey(step generateKeyStep) (generateKeyResul
 		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: rsaBits must be at least 2048", alias)
 	}
 
+	if step.ExpiresInDays < 0 {
+		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: expiresInDays cannot be negative", alias)
+	}
+
 	cfg := *defaultConfig
 	if rsaBits > 0 {
 		cfg.RSABits = rsaBits
 	}
 
-	entity, err := openpgp.NewEntity(name, comment, email, &cfg)
+	var (
+		entity *openpgp.Entity
+		err    error
+	)
+	switch algorithm {
+	case keyAlgorithmRSA:
+		entity, err = openpgp.NewEntity(name, comment, email, &cfg)
+	case keyAlgorithmECDSA:
+		entity, err = newECDSAEntity(name, comment, email, &cfg)
+	case keyAlgorithmEd25519:
+		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: %s keys are not supported by the OpenPGP implementation in use (no EdDSA support)", alias, algorithm)
+	default:
+		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: unsupported algorithm %q (expected %s or %s)", alias, step.Algorithm, keyAlgorithmRSA, keyAlgorithmECDSA)
+	}
 	if err != nil {
 		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: create entity: %w", alias, err)
 	}
 
-	privData, err := exportPrivateKey(entity)
+	var expiresAt string
+	if step.ExpiresInDays > 0 {
+		lifetime := time.Duration(step.ExpiresInDays) * 24 * time.Hour
+		if err := setKeyLifetime(entity, lifetime, &cfg); err != nil {
+			return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: set expiry: %w", alias, err)
+		}
+		expiresAt = entity.PrimaryKey.CreationTime.Add(lifetime).UTC().Format(time.RFC3339)
+	}
+
+	// The in-memory entity stays decrypted for later steps; only the exported
+	// private key is protected by the passphrase.
+	var privData []byte
+	if step.Passphrase != "" {
+		privData, err = exportProtectedPrivateKey(openpgp.EntityList{entity}, true, []byte(step.Passphrase))
+	} else {
+		privData, err = exportPrivateKey(openpgp.EntityList{entity}, true)
+	}
 	if err != nil {
 		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: %w", alias, err)
 	}
-	pubData, err := exportPublicKey(entity)
+	pubData, err := exportPublicKey(openpgp.EntityList{entity}, true)
 	if err != nil {
 		return generateKeyResult{}, fmt.Errorf("pgp: generate_key[%s]: %w", alias, err)
 	}
@@ -377,7 +500,7 @@ func (s *actionState) executeGenerateKey(step generateKeyStep) (generateKeyResul
 
 	s.aliases[strings.ToUpper(alias)] = entry
 	s.entityAlias[entity] = alias
-	s.logf("PGP: generated key for alias %s", alias)
+	s.logf("PGP: generated %s key for alias %s", algorithm, alias)
 
 	privPath := strings.TrimSpace(step.PrivateKeyPath)
 	if privPath != "" {
@@ -395,6 +518,7 @@ func (s *actionState) executeGenerateKey(step generateKeyStep) (generateKeyResul
 
 	return generateKeyResult{
 		Alias:       alias,
+		Algorithm:   algorithm,
 		Armored:     true,
 		Fingerprint: fingerprintHex(entity.PrimaryKey),
 		KeyID:       formatKeyID(entity.PrimaryKey.KeyId),
@@ -406,12 +530,81 @@ func (s *actionState) executeGenerateKey(step generateKeyStep) (generateKeyResul
 			Value:    string(pubData),
 			Encoding: "utf8",
 		},
+		Protected:      step.Passphrase != "",
+		ExpiresAt:      expiresAt,
 		PrivateKeyPath: privPath,
 		PublicKeyPath:  pubPath,
 		Identities:     identities,
 	}, nil
 }
 
+// setKeyLifetime makes the entity and its subkeys expire lifetime after their
+// creation by setting the key and signature lifetimes of the self-signatures,
+// then re-signing them.
+func setKeyLifetime(entity *openpgp.Entity, lifetime time.Duration, cfg *packet.Config) error {
+	secs := uint32(lifetime / time.Second)
+	for _, ident := range entity.Identities {
+		ident.SelfSignature.KeyLifetimeSecs = &secs
+		ident.SelfSignature.SigLifetimeSecs = &secs
+		if err := ident.SelfSignature.SignUserId(ident.UserId.Id, entity.PrimaryKey, entity.PrivateKey, cfg); err != nil {
+			return err
+		}
+	}
+	for _, subkey := range entity.Subkeys {
+		subkey.Sig.KeyLifetimeSecs = &secs
+		subkey.Sig.SigLifetimeSecs = &secs
+		if err := subkey.Sig.SignKey(subkey.PublicKey, entity.PrivateKey, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newECDSAEntity builds a NIST P-256 ECDSA key with a self-signed identity.
+// The key can sign and certify but not receive encrypted messages, since the
+// OpenPGP implementation does not support ECDH encryption subkeys.
+func newECDSAEntity(name, comment, email string, cfg *packet.Config) (*openpgp.Entity, error) {
+	uid := packet.NewUserId(name, comment, email)
+	if uid == nil {
+		return nil, errors.New("user id field contained invalid characters")
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), cfg.Random())
+	if err != nil {
+		return nil, err
+	}
+
+	creationTime := cfg.Now()
+	entity := &openpgp.Entity{
+		PrimaryKey: packet.NewECDSAPublicKey(creationTime, &priv.PublicKey),
+		PrivateKey: packet.NewECDSAPrivateKey(creationTime, priv),
+		Identities: make(map[string]*openpgp.Identity),
+	}
+
+	isPrimaryID := true
+	identity := &openpgp.Identity{
+		Name:   uid.Id,
+		UserId: uid,
+		SelfSignature: &packet.Signature{
+			CreationTime: creationTime,
+			SigType:      packet.SigTypePositiveCert,
+			PubKeyAlgo:   packet.PubKeyAlgoECDSA,
+			Hash:         cfg.Hash(),
+			IsPrimaryId:  &isPrimaryID,
+			FlagsValid:   true,
+			FlagSign:     true,
+			FlagCertify:  true,
+			IssuerKeyId:  &entity.PrimaryKey.KeyId,
+		},
+	}
+	if err := identity.SelfSignature.SignUserId(uid.Id, entity.PrimaryKey, entity.PrivateKey, cfg); err != nil {
+		return nil, err
+	}
+	entity.Identities[uid.Id] = identity
+
+	return entity, nil
+}
+
 func (s *actionState) executeImportKey(step importKeyStep) (importKeyResult, error) {
 	alias := strings.TrimSpace(step.Alias)
 	if alias == "" {
@@ -500,6 +693,132 @@ func (s *actionState) executeImportKey(step importKeyStep) (importKeyResult, err
 	}, nil
 }
 
+func (s *actionState) executeExportKey(step exportKeyStep) (exportKeyResult, error) {
+	alias := strings.TrimSpace(step.Alias)
+	if alias == "" {
+		return exportKeyResult{}, errors.New("pgp: export_key.alias must be provided")
+	}
+	entry, err := s.lookupAlias(alias)
+	if err != nil {
+		return exportKeyResult{}, fmt.Errorf("pgp: export_key: %w", err)
+	}
+
+	armorEnabled := true
+	if step.Armor != nil {
+		armorEnabled = *step.Armor
+	}
+
+	privPath := strings.TrimSpace(step.PrivateKeyPath)
+	if privPath != "" && !step.IncludePrivate {
+		return exportKeyResult{}, fmt.Errorf("pgp: export_key[%s]: privateKeyPath requires includePrivate", alias)
+	}
+
+	res := exportKeyResult{
+		Alias:        entry.Alias,
+		Armored:      armorEnabled,
+		Fingerprints: make([]string, 0, len(entry.Entities)),
+		KeyIDs:       make([]string, 0, len(entry.Entities)),
+	}
+	for _, entity := range entry.Entities {
+		res.Fingerprints = append(res.Fingerprints, fingerprintHex(entity.PrimaryKey))
+		res.KeyIDs = append(res.KeyIDs, formatKeyID(entity.PrimaryKey.KeyId))
+	}
+
+	pubData, err := exportPublicKey(entry.Entities, armorEnabled)
+	if err != nil {
+		return exportKeyResult{}, fmt.Errorf("pgp: export_key[%s]: %w", alias, err)
+	}
+	res.PublicKey = encodeKeyData(pubData, armorEnabled)
+
+	if pubPath := strings.TrimSpace(step.PublicKeyPath); pubPath != "" {
+		if err := writeFile(pubPath, pubData); err != nil {
+			return exportKeyResult{}, fmt.Errorf("pgp: export_key[%s]: %w", alias, err)
+		}
+		res.PublicKeyPath = pubPath
+	}
+
+	if step.IncludePrivate {
+		if !hasFullPrivateKey(entry) {
+			return exportKeyResult{}, fmt.Errorf("pgp: export_key[%s]: alias does not contain a private key", alias)
+		}
+		privData, err := exportPrivateKey(entry.Entities, armorEnabled)
+		if err != nil {
+			return exportKeyResult{}, fmt.Errorf("pgp: export_key[%s]: %w", alias, err)
+		}
+		privKey := encodeKeyData(privData, armorEnabled)
+		res.PrivateKey = &privKey
+
+		if privPath != "" {
+			if err := writeFile(privPath, privData); err != nil {
+				return exportKeyResult{}, fmt.Errorf("pgp: export_key[%s]: %w", alias, err)
+			}
+			res.PrivateKeyPath = privPath
+		}
+	}
+
+	s.logf("PGP: exported keys of alias %s (private: %t)", alias, step.IncludePrivate)
+	return res, nil
+}
+
+// hasFullPrivateKey reports whether every key of the alias, subkeys included,
+// carries private material, which serializing the private keyring requires.
+func hasFullPrivateKey(entry *keyEntry) bool {
+	if !entry.ContainsPrivate {
+		return false
+	}
+	for _, entity := range entry.Entities {
+		if entity.PrivateKey == nil {
+			return false
+		}
+		for _, sub := range entity.Subkeys {
+			if sub.PrivateKey == nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func encodeKeyData(data []byte, armored bool) encodedData {
+	if armored {
+		return encodedData{Value: string(data), Encoding: "utf8"}
+	}
+	return encodedData{Value: base64.StdEncoding.EncodeToString(data), Encoding: "base64"}
+}
+
+func (s *actionState) executeListKeys() listKeysResult {
+	keys := make([]string, 0, len(s.aliases))
+	for key := range s.aliases {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	res := listKeysResult{Aliases: make([]aliasSummary, 0, len(keys))}
+	for _, key := range keys {
+		entry := s.aliases[key]
+		summary := aliasSummary{
+			Alias:           entry.Alias,
+			Source:          entry.Source,
+			ContainsPrivate: entry.ContainsPrivate,
+			Fingerprints:    make([]string, 0, len(entry.Entities)),
+			KeyIDs:          make([]string, 0, len(entry.Entities)),
+			Identities:      make(map[string]string),
+		}
+		for _, entity := range entry.Entities {
+			summary.Fingerprints = append(summary.Fingerprints, fingerprintHex(entity.PrimaryKey))
+			summary.KeyIDs = append(summary.KeyIDs, formatKeyID(entity.PrimaryKey.KeyId))
+			for name, ident := range entity.Identities {
+				if name == "" {
+					continue
+				}
+				summary.Identities[name] = ident.UserId.Email
+			}
+		}
+		res.Aliases = append(res.Aliases, summary)
+	}
+	return res
+}
+
 func (s *actionState) executeEncrypt(step encryptStep) (encryptResult, error) {
 	message, _, err := loadBytes(step.Message, step.MessagePath)
 	if err != nil {
@@ -772,18 +1091,69 @@ func (s *actionState) executeSignDetached(step signDetachedStep) (signResult, er
 	if signerAlias == "" {
 		return signResult{}, errors.New("pgp: sign_detached.signWith must be provided")
 	}
-	entry, err := s.lookupAlias(signerAlias)
+
+	message, _, err := loadBytes(step.Message, step.MessagePath)
 	if err != nil {
 		return signResult{}, fmt.Errorf("pgp: sign_detached: %w", err)
 	}
-	signer, err := selectSigningEntity(entry)
+
+	res, signature, err := s.signDetached(signerAlias, message, step)
 	if err != nil {
-		return signResult{}, fmt.Errorf("pgp: sign_detached: %w", err)
+		return signResult{}, err
+	}
+
+	if step.OutputPath != "" {
+		if err := writeFile(step.OutputPath, signature); err != nil {
+			return signResult{}, fmt.Errorf("pgp: sign_detached: %w", err)
+		}
+	}
+	res.OutputPath = strings.TrimSpace(step.OutputPath)
+	return res, nil
+}
+
+// executeSignDetachedAll produces one detached signature per alias listed in
+// signWithAll, for artifacts that must be co-signed.
+func (s *actionState) executeSignDetachedAll(step signDetachedStep) (multiSignResult, error) {
+	if strings.TrimSpace(step.SignWith) != "" {
+		return multiSignResult{}, errors.New("pgp: sign_detached: signWith and signWithAll are mutually exclusive")
+	}
+	if strings.TrimSpace(step.OutputPath) != "" {
+		return multiSignResult{}, errors.New("pgp: sign_detached: outputPath is not supported with signWithAll")
 	}
 
 	message, _, err := loadBytes(step.Message, step.MessagePath)
 	if err != nil {
-		return signResult{}, fmt.Errorf("pgp: sign_detached: %w", err)
+		return multiSignResult{}, fmt.Errorf("pgp: sign_detached: %w", err)
+	}
+
+	res := multiSignResult{Signatures: make([]signResult, 0, len(step.SignWithAll))}
+	for _, alias := range step.SignWithAll {
+		signed, _, err := s.signDetached(strings.TrimSpace(alias), message, step)
+		if err != nil {
+			return multiSignResult{}, err
+		}
+		res.Signatures = append(res.Signatures, signed)
+	}
+	return res, nil
+}
+
+// signDetached signs message with the key of signerAlias selected by
+// step.KeyIDs and returns the result along with the raw signature.
+func (s *actionState) signDetached(signerAlias string, message []byte, step signDetachedStep) (signResult, []byte, error) {
+	entry, err := s.lookupAlias(signerAlias)
+	if err != nil {
+		return signResult{}, nil, fmt.Errorf("pgp: sign_detached: %w", err)
+	}
+	signer, signingKey, err := selectSigningKey(entry, step.KeyIDs)
+	if err != nil {
+		return signResult{}, nil, fmt.Errorf("pgp: sign_detached: %w", err)
+	}
+
+	// The openpgp signing helpers always sign with the entity's PrivateKey,
+	// so a selected subkey is passed through a view of the entity.
+	signWith := signer
+	if signingKey != signer.PrivateKey {
+		signWith = &openpgp.Entity{PrimaryKey: signer.PrimaryKey, PrivateKey: signingKey}
 	}
 
 	armorEnabled := true
@@ -800,19 +1170,19 @@ func (s *actionState) executeSignDetached(step signDetachedStep) (signResult, er
 	var signErr error
 	if armorEnabled {
 		if textMode {
-			signErr = openpgp.ArmoredDetachSignText(&buf, signer, bytes.NewReader(message), defaultConfig)
+			signErr = openpgp.ArmoredDetachSignText(&buf, signWith, bytes.NewReader(message), defaultConfig)
 		} else {
-			signErr = openpgp.ArmoredDetachSign(&buf, signer, bytes.NewReader(message), defaultConfig)
+			signErr = openpgp.ArmoredDetachSign(&buf, signWith, bytes.NewReader(message), defaultConfig)
 		}
 	} else {
 		if textMode {
-			signErr = openpgp.DetachSignText(&buf, signer, bytes.NewReader(message), defaultConfig)
+			signErr = openpgp.DetachSignText(&buf, signWith, bytes.NewReader(message), defaultConfig)
 		} else {
-			signErr = openpgp.DetachSign(&buf, signer, bytes.NewReader(message), defaultConfig)
+			signErr = openpgp.DetachSign(&buf, signWith, bytes.NewReader(message), defaultConfig)
 		}
 	}
 	if signErr != nil {
-		return signResult{}, fmt.Errorf("pgp: sign_detached: %w", signErr)
+		return signResult{}, nil, fmt.Errorf("pgp: sign_detached: %w", signErr)
 	}
 
 	signature := buf.Bytes()
@@ -830,25 +1200,18 @@ func (s *actionState) executeSignDetached(step signDetachedStep) (signResult, er
 			data.Value = string(signature)
 			data.Encoding = "binary"
 		default:
-			return signResult{}, fmt.Errorf("pgp: sign_detached: unsupported resultEncoding %q", step.ResultEncoding)
-		}
-	}
-
-	if step.OutputPath != "" {
-		if err := writeFile(step.OutputPath, signature); err != nil {
-			return signResult{}, fmt.Errorf("pgp: sign_detached: %w", err)
+			return signResult{}, nil, fmt.Errorf("pgp: sign_detached: unsupported resultEncoding %q", step.ResultEncoding)
 		}
 	}
 
 	alias, _ := s.aliasForEntity(signer)
 	return signResult{
 		Signature:         data,
-		OutputPath:        strings.TrimSpace(step.OutputPath),
 		Armored:           armorEnabled,
 		SignerAlias:       alias,
 		SignerFingerprint: fingerprintHex(signer.PrimaryKey),
-		SignerKeyID:       formatKeyID(signer.PrimaryKey.KeyId),
-	}, nil
+		SignerKeyID:       formatKeyID(signingKey.KeyId),
+	}, signature, nil
 }
 
 func (s *actionState) executeVerifyDetached(step verifyDetachedStep) (verifyResult, error) {
@@ -859,10 +1222,6 @@ func (s *actionState) executeVerifyDetached(step verifyDetachedStep) (verifyResu
 	if err != nil {
 		return verifyResult{}, fmt.Errorf("pgp: verify_detached: %w", err)
 	}
-	signature, _, err := loadBytes(step.Signature, step.SignaturePath)
-	if err != nil {
-		return verifyResult{}, fmt.Errorf("pgp: verify_detached: %w", err)
-	}
 
 	keyRing, _, err := s.entitiesForAliases(step.KeyAliases)
 	if err != nil {
@@ -872,24 +1231,18 @@ func (s *actionState) executeVerifyDetached(step verifyDetachedStep) (verifyResu
 		return verifyResult{}, errors.New("pgp: verify_detached: no keys available")
 	}
 
-	var entity *openpgp.Entity
-	entity, err = openpgp.CheckDetachedSignature(keyRing, bytes.NewReader(message), bytes.NewReader(signature))
+	if len(step.Signatures) > 0 || len(step.SignaturePaths) > 0 {
+		return s.verifyDetachedAll(step, keyRing, message)
+	}
+
+	signature, _, err := loadBytes(step.Signature, step.SignaturePath)
 	if err != nil {
-		block, blockErr := armor.Decode(bytes.NewReader(signature))
-		if blockErr != nil {
-			return verifyResult{}, fmt.Errorf("pgp: verify_detached: %w", err)
-		}
-		if block.Type != openpgp.SignatureType {
-			return verifyResult{}, fmt.Errorf("pgp: verify_detached: expected %s block, got %s", openpgp.SignatureType, block.Type)
-		}
-		body, readErr := io.ReadAll(block.Body)
-		if readErr != nil {
-			return verifyResult{}, fmt.Errorf("pgp: verify_detached: read armored signature: %w", readErr)
-		}
-		entity, err = openpgp.CheckDetachedSignature(keyRing, bytes.NewReader(message), bytes.NewReader(body))
-		if err != nil {
-			return verifyResult{}, fmt.Errorf("pgp: verify_detached: %w", err)
-		}
+		return verifyResult{}, fmt.Errorf("pgp: verify_detached: %w", err)
+	}
+
+	entity, err := checkDetachedSignature(keyRing, message, signature)
+	if err != nil {
+		return verifyResult{}, fmt.Errorf("pgp: verify_detached: %w", err)
 	}
 
 	alias, _ := s.aliasForEntity(entity)
@@ -901,6 +1254,85 @@ func (s *actionState) executeVerifyDetached(step verifyDetachedStep) (verifyResu
 	}, nil
 }
 
+// verifyDetachedAll checks every signature listed in signatures and
+// signaturePaths and requires all of them, or at least one with require: any,
+// to verify.
+func (s *actionState) verifyDetachedAll(step verifyDetachedStep, keyRing openpgp.EntityList, message []byte) (verifyResult, error) {
+	if step.Signature != "" || strings.TrimSpace(step.SignaturePath) != "" {
+		return verifyResult{}, errors.New("pgp: verify_detached: signature/signaturePath cannot be combined with signatures/signaturePaths")
+	}
+
+	require := strings.ToLower(strings.TrimSpace(step.Require))
+	switch require {
+	case "":
+		require = "all"
+	case "all", "any":
+	default:
+		return verifyResult{}, fmt.Errorf("pgp: verify_detached: unsupported require %q (expected all or any)", step.Require)
+	}
+
+	signatures := make([][]byte, 0, len(step.Signatures)+len(step.SignaturePaths))
+	for _, inline := range step.Signatures {
+		signatures = append(signatures, []byte(inline))
+	}
+	for _, path := range step.SignaturePaths {
+		data, _, err := loadBytes("", path)
+		if err != nil {
+			return verifyResult{}, fmt.Errorf("pgp: verify_detached: %w", err)
+		}
+		signatures = append(signatures, data)
+	}
+
+	res := verifyResult{Require: require, Signatures: make([]signatureCheck, 0, len(signatures))}
+	verified := 0
+	var firstErr error
+	for idx, signature := range signatures {
+		check := signatureCheck{Index: idx}
+		entity, err := checkDetachedSignature(keyRing, message, signature)
+		if err != nil {
+			check.Error = err.Error()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("signature %d: %w", idx, err)
+			}
+		} else {
+			verified++
+			check.Verified = true
+			check.SignerAlias, _ = s.aliasForEntity(entity)
+			check.SignerFingerprint = fingerprintHex(entity.PrimaryKey)
+			check.SignerKeyID = formatKeyID(entity.PrimaryKey.KeyId)
+		}
+		res.Signatures = append(res.Signatures, check)
+	}
+
+	if (require == "all" && verified != len(signatures)) || verified == 0 {
+		return verifyResult{}, fmt.Errorf("pgp: verify_detached: %d of %d signatures verified (require %s): %w", verified, len(signatures), require, firstErr)
+	}
+	res.Verified = true
+	return res, nil
+}
+
+// checkDetachedSignature verifies a binary or armored detached signature and
+// returns the signing entity.
+func checkDetachedSignature(keyRing openpgp.EntityList, message, signature []byte) (*openpgp.Entity, error) {
+	entity, err := openpgp.CheckDetachedSignature(keyRing, bytes.NewReader(message), bytes.NewReader(signature))
+	if err == nil {
+		return entity, nil
+	}
+
+	block, blockErr := armor.Decode(bytes.NewReader(signature))
+	if blockErr != nil {
+		return nil, err
+	}
+	if block.Type != openpgp.SignatureType {
+		return nil, fmt.Errorf("expected %s block, got %s", openpgp.SignatureType, block.Type)
+	}
+	body, readErr := io.ReadAll(block.Body)
+	if readErr != nil {
+		return nil, fmt.Errorf("read armored signature: %w", readErr)
+	}
+	return openpgp.CheckDetachedSignature(keyRing, bytes.NewReader(message), bytes.NewReader(body))
+}
+
 func (s *actionState) lookupAlias(alias string) (*keyEntry, error) {
 	key := strings.ToUpper(strings.TrimSpace(alias))
 	if key == "" {
@@ -1001,6 +1433,55 @@ func selectSigningEntity(entry *keyEntry) (*openpgp.Entity, error) {
 	return nil, fmt.Errorf("pgp: alias %q does not contain a signing-capable private key", entry.Alias)
 }
 
+// selectSigningKey picks the signing key of an alias. Without keyIDs it is the
+// primary key chosen by selectSigningEntity; otherwise the first primary key
+// or signing-capable subkey whose key ID matches one of keyIDs.
+func selectSigningKey(entry *keyEntry, keyIDs []string) (*openpgp.Entity, *packet.PrivateKey, error) {
+	if len(keyIDs) == 0 {
+		entity, err := selectSigningEntity(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		return entity, entity.PrivateKey, nil
+	}
+
+	for _, entity := range entry.Entities {
+		candidates := make([]*packet.PrivateKey, 0, len(entity.Subkeys)+1)
+		if entity.PrivateKey != nil && entity.PrimaryKey.PubKeyAlgo.CanSign() {
+			candidates = append(candidates, entity.PrivateKey)
+		}
+		for _, sub := range entity.Subkeys {
+			if sub.PrivateKey != nil && sub.Sig.FlagsValid && sub.Sig.FlagSign && sub.PublicKey.PubKeyAlgo.CanSign() {
+				candidates = append(candidates, sub.PrivateKey)
+			}
+		}
+		for _, key := range candidates {
+			if !matchesKeyID(key.KeyId, keyIDs) {
+				continue
+			}
+			if key.Encrypted {
+				return nil, nil, fmt.Errorf("pgp: alias %q contains encrypted private key; provide passphrase during import", entry.Alias)
+			}
+			return entity, key, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("pgp: alias %q has no signing key matching keyIds %v", entry.Alias, keyIDs)
+}
+
+// matchesKeyID reports whether id matches one of the wanted key IDs, given as
+// 16 hex digit long IDs or 8 hex digit short IDs, optionally prefixed by 0x.
+func matchesKeyID(id uint64, wanted []string) bool {
+	full := formatKeyID(id)
+	for _, candidate := range wanted {
+		candidate = strings.ToUpper(strings.TrimSpace(candidate))
+		candidate = strings.TrimPrefix(candidate, "0X")
+		if candidate == full || (len(candidate) == 8 && strings.HasSuffix(full, candidate)) {
+			return true
+		}
+	}
+	return false
+}
+
 func loadBytes(inline, path string) ([]byte, string, error) {
 	trimmedInline := inline
 	trimmedPath := strings.TrimSpace(path)
@@ -1044,32 +1525,46 @@ func parseKeyRing(data []byte) (openpgp.EntityList, bool, error) {
 	return entities, false, nil
 }
 
-func exportPrivateKey(entity *openpgp.Entity) ([]byte, error) {
-	var buf bytes.Buffer
-	writer, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
-	if err != nil {
-		return nil, fmt.Errorf("armor private key: %w", err)
-	}
-	if err := entity.SerializePrivate(writer, defaultConfig); err != nil {
-		return nil, fmt.Errorf("serialize private key: %w", err)
-	}
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("close private armor: %w", err)
-	}
-	return buf.Bytes(), nil
+func exportPrivateKey(entities openpgp.EntityList, armored bool) ([]byte, error) {
+	return serializeKeys(openpgp.PrivateKeyType, armored, func(w io.Writer) error {
+		for _, entity := range entities {
+			if err := entity.SerializePrivate(w, defaultConfig); err != nil {
+				return fmt.Errorf("serialize private key: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
-func exportPublicKey(entity *openpgp.Entity) ([]byte, error) {
+func exportPublicKey(entities openpgp.EntityList, armored bool) ([]byte, error) {
+	return serializeKeys(openpgp.PublicKeyType, armored, func(w io.Writer) error {
+		for _, entity := range entities {
+			if err := entity.Serialize(w); err != nil {
+				return fmt.Errorf("serialize public key: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func serializeKeys(blockType string, armored bool, serialize func(io.Writer) error) ([]byte, error) {
 	var buf bytes.Buffer
-	writer, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if !armored {
+		if err := serialize(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	writer, err := armor.Encode(&buf, blockType, nil)
 	if err != nil {
-		return nil, fmt.Errorf("armor public key: %w", err)
+		return nil, fmt.Errorf("armor %s: %w", strings.ToLower(blockType), err)
 	}
-	if err := entity.Serialize(writer); err != nil {
-		return nil, fmt.Errorf("serialize public key: %w", err)
+	if err := serialize(writer); err != nil {
+		return nil, err
 	}
 	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("close public armor: %w", err)
+		return nil, fmt.Errorf("close %s armor: %w", strings.ToLower(blockType), err)
 	}
 	return buf.Bytes(), nil
 }
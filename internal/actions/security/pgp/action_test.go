@@ -3,13 +3,21 @@ package pgp
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/crypto/openpgp"
 	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
 
 	"flowk/internal/actions/registry"
 	"flowk/internal/flow"
@@ -409,6 +417,527 @@ func TestActionGenerateKeyAndSymmetricEncrypt(t *testing.T) {
 	}
 }
 
+func TestActionExportKey(t *testing.T) {
+	t.Parallel()
+
+	entity, err := openpgp.NewEntity("Bob Example", "", "bob@example.com", nil)
+	if err != nil {
+		t.Fatalf("NewEntity: %v", err)
+	}
+	privKey, pubKey := exportEntity(t, entity)
+	pubPath := filepath.Join(t.TempDir(), "bob_public.gpg")
+
+	payload := map[string]any{
+		"action": "PGP",
+		"steps": []any{
+			map[string]any{"operation": "IMPORT_KEY", "alias": "bob", "key": privKey},
+			map[string]any{"operation": "IMPORT_KEY", "alias": "bob_pub", "key": pubKey},
+			map[string]any{"id": "armored", "operation": "EXPORT_KEY", "alias": "bob", "includePrivate": true},
+			map[string]any{"id": "binary", "operation": "EXPORT_KEY", "alias": "bob_pub", "armor": false, "publicKeyPath": pubPath},
+		},
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal payload: %v", err)
+	}
+
+	result, err := Action{}.Execute(context.Background(), raw, &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	data, err := json.Marshal(result.Value)
+	if err != nil {
+		t.Fatalf("Marshal result: %v", err)
+	}
+	var decoded struct {
+		Steps []struct {
+			Result json.RawMessage `json:"result"`
+		} `json:"steps"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal decoded result: %v", err)
+	}
+
+	fingerprint := fingerprintHex(entity.PrimaryKey)
+	keyID := formatKeyID(entity.PrimaryKey.KeyId)
+
+	var armored exportKeyResult
+	if err := json.Unmarshal(decoded.Steps[2].Result, &armored); err != nil {
+		t.Fatalf("Unmarshal armored export: %v", err)
+	}
+	if !armored.Armored || armored.PrivateKey == nil || armored.Fingerprints[0] != fingerprint || armored.KeyIDs[0] != keyID {
+		t.Fatalf("unexpected armored export: %+v", armored)
+	}
+	reimported, err := openpgp.ReadArmoredKeyRing(bytes.NewBufferString(armored.PrivateKey.Value))
+	if err != nil {
+		t.Fatalf("ReadArmoredKeyRing private: %v", err)
+	}
+	if len(reimported) != 1 || reimported[0].PrivateKey == nil || fingerprintHex(reimported[0].PrimaryKey) != fingerprint {
+		t.Fatalf("exported private key does not round-trip")
+	}
+
+	var binary exportKeyResult
+	if err := json.Unmarshal(decoded.Steps[3].Result, &binary); err != nil {
+		t.Fatalf("Unmarshal binary export: %v", err)
+	}
+	if binary.Armored || binary.PublicKey.Encoding != "base64" || binary.PrivateKey != nil {
+		t.Fatalf("unexpected binary export: %+v", binary)
+	}
+	written, err := os.ReadFile(pubPath)
+	if err != nil {
+		t.Fatalf("Read public key file: %v", err)
+	}
+	if base64.StdEncoding.EncodeToString(written) != binary.PublicKey.Value {
+		t.Fatalf("public key file does not match the result")
+	}
+	if _, err := openpgp.ReadKeyRing(bytes.NewReader(written)); err != nil {
+		t.Fatalf("ReadKeyRing public: %v", err)
+	}
+
+	payload["steps"] = []any{
+		map[string]any{"operation": "IMPORT_KEY", "alias": "bob_pub", "key": pubKey},
+		map[string]any{"operation": "EXPORT_KEY", "alias": "bob_pub", "includePrivate": true},
+	}
+	raw, err = json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal payload: %v", err)
+	}
+	if _, err := (Action{}).Execute(context.Background(), raw, &registry.ExecutionContext{}); err == nil || !strings.Contains(err.Error(), "does not contain a private key") {
+		t.Fatalf("Execute error = %v, want missing private key", err)
+	}
+}
+
+func TestActionGenerateECDSAKeySignVerify(t *testing.T) {
+	t.Parallel()
+
+	execute := func(steps ...any) []json.RawMessage {
+		t.Helper()
+		raw, err := json.Marshal(map[string]any{"action": "PGP", "steps": steps})
+		if err != nil {
+			t.Fatalf("Marshal payload: %v", err)
+		}
+		result, err := Action{}.Execute(context.Background(), raw, &registry.ExecutionContext{})
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		data, err := json.Marshal(result.Value)
+		if err != nil {
+			t.Fatalf("Marshal result: %v", err)
+		}
+		var decoded struct {
+			Steps []struct {
+				Result json.RawMessage `json:"result"`
+			} `json:"steps"`
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal decoded result: %v", err)
+		}
+		results := make([]json.RawMessage, 0, len(decoded.Steps))
+		for _, step := range decoded.Steps {
+			results = append(results, step.Result)
+		}
+		return results
+	}
+
+	results := execute(
+		map[string]any{"operation": "GENERATE_KEY", "alias": "ecc", "name": "ECC User", "algorithm": "ecdsa"},
+		map[string]any{"operation": "SIGN_DETACHED", "signWith": "ecc", "message": "release 1.0"},
+	)
+
+	var genRes generateKeyResult
+	if err := json.Unmarshal(results[0], &genRes); err != nil {
+		t.Fatalf("Unmarshal generate result: %v", err)
+	}
+	if genRes.Algorithm != "ecdsa" {
+		t.Fatalf("unexpected algorithm: %q", genRes.Algorithm)
+	}
+	var signRes signResult
+	if err := json.Unmarshal(results[1], &signRes); err != nil {
+		t.Fatalf("Unmarshal sign result: %v", err)
+	}
+
+	results = execute(
+		map[string]any{"operation": "IMPORT_KEY", "alias": "ecc_pub", "key": genRes.PublicKey.Value},
+		map[string]any{"operation": "VERIFY_DETACHED", "message": "release 1.0", "signature": signRes.Signature.Value, "keyAliases": []string{"ecc_pub"}},
+	)
+	var verifyRes verifyResult
+	if err := json.Unmarshal(results[1], &verifyRes); err != nil {
+		t.Fatalf("Unmarshal verify result: %v", err)
+	}
+	if !verifyRes.Verified || verifyRes.SignerFingerprint != genRes.Fingerprint {
+		t.Fatalf("unexpected verify result: %+v", verifyRes)
+	}
+
+	cases := map[string]struct {
+		step map[string]any
+		want string
+	}{
+		"rsaBits with ecdsa": {map[string]any{"algorithm": "ecdsa", "rsaBits": 4096}, "rsaBits only applies to the rsa algorithm"},
+		"ed25519":            {map[string]any{"algorithm": "ed25519"}, "not supported"},
+		"unknown algorithm":  {map[string]any{"algorithm": "dsa"}, "unsupported algorithm"},
+	}
+	for name, tc := range cases {
+		step := map[string]any{"operation": "GENERATE_KEY", "alias": "bad", "name": "Bad"}
+		for key, value := range tc.step {
+			step[key] = value
+		}
+		raw, err := json.Marshal(map[string]any{"action": "PGP", "steps": []any{step}})
+		if err != nil {
+			t.Fatalf("%s: Marshal payload: %v", name, err)
+		}
+		if _, err := (Action{}).Execute(context.Background(), raw, &registry.ExecutionContext{}); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: Execute error = %v, want %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestActionGenerateProtectedExpiringKey(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]any{
+		"action": "PGP",
+		"steps": []any{
+			map[string]any{
+				"operation":     "GENERATE_KEY",
+				"alias":         "release",
+				"email":         "release@example.com",
+				"expiresInDays": 30,
+				"passphrase":    "s3cret",
+			},
+			map[string]any{"operation": "SIGN_DETACHED", "signWith": "release", "message": "artifact"},
+		},
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal payload: %v", err)
+	}
+	result, err := Action{}.Execute(context.Background(), raw, &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	data, err := json.Marshal(result.Value)
+	if err != nil {
+		t.Fatalf("Marshal result: %v", err)
+	}
+	var decoded struct {
+		Steps []struct {
+			Result json.RawMessage `json:"result"`
+		} `json:"steps"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal decoded result: %v", err)
+	}
+
+	var genRes generateKeyResult
+	if err := json.Unmarshal(decoded.Steps[0].Result, &genRes); err != nil {
+		t.Fatalf("Unmarshal generate result: %v", err)
+	}
+	if !genRes.Protected {
+		t.Fatalf("expected passphrase protected key")
+	}
+	expiresAt, err := time.Parse(time.RFC3339, genRes.ExpiresAt)
+	if err != nil {
+		t.Fatalf("parse expiresAt %q: %v", genRes.ExpiresAt, err)
+	}
+	if until := time.Until(expiresAt); until < 29*24*time.Hour || until > 31*24*time.Hour {
+		t.Fatalf("unexpected expiresAt: %s", genRes.ExpiresAt)
+	}
+
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewBufferString(genRes.PrivateKey.Value))
+	if err != nil {
+		t.Fatalf("ReadArmoredKeyRing: %v", err)
+	}
+	entity := entities[0]
+	if !entity.PrivateKey.Encrypted || !entity.Subkeys[0].PrivateKey.Encrypted {
+		t.Fatalf("expected encrypted private keys")
+	}
+	if err := entity.PrivateKey.Decrypt([]byte("wrong")); err == nil {
+		t.Fatalf("expected decrypt failure with wrong passphrase")
+	}
+	if err := entity.PrivateKey.Decrypt([]byte("s3cret")); err != nil {
+		t.Fatalf("Decrypt primary key: %v", err)
+	}
+	if err := entity.Subkeys[0].PrivateKey.Decrypt([]byte("s3cret")); err != nil {
+		t.Fatalf("Decrypt subkey: %v", err)
+	}
+	for _, ident := range entity.Identities {
+		if ident.SelfSignature.KeyLifetimeSecs == nil || *ident.SelfSignature.KeyLifetimeSecs != 30*24*60*60 {
+			t.Fatalf("unexpected key lifetime: %v", ident.SelfSignature.KeyLifetimeSecs)
+		}
+	}
+
+	var signRes signResult
+	if err := json.Unmarshal(decoded.Steps[1].Result, &signRes); err != nil {
+		t.Fatalf("Unmarshal sign result: %v", err)
+	}
+	payload["steps"] = []any{
+		map[string]any{"operation": "IMPORT_KEY", "alias": "release", "key": genRes.PrivateKey.Value, "passphrase": "s3cret"},
+		map[string]any{"operation": "VERIFY_DETACHED", "message": "artifact", "signature": signRes.Signature.Value, "keyAliases": []string{"release"}},
+	}
+	raw, err = json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal payload: %v", err)
+	}
+	if _, err := (Action{}).Execute(context.Background(), raw, &registry.ExecutionContext{}); err != nil {
+		t.Fatalf("Execute re-import: %v", err)
+	}
+}
+
+func TestActionListKeys(t *testing.T) {
+	t.Parallel()
+
+	entity, err := openpgp.NewEntity("Carol Example", "", "carol@example.com", nil)
+	if err != nil {
+		t.Fatalf("NewEntity: %v", err)
+	}
+	_, pubKey := exportEntity(t, entity)
+
+	payload := map[string]any{
+		"action": "PGP",
+		"steps": []any{
+			map[string]any{"operation": "LIST_KEYS"},
+			map[string]any{"operation": "IMPORT_KEY", "alias": "carol", "key": pubKey},
+			map[string]any{"operation": "GENERATE_KEY", "alias": "build", "name": "Build Bot"},
+			map[string]any{"operation": "LIST_KEYS", "id": "keys"},
+		},
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal payload: %v", err)
+	}
+	result, err := Action{}.Execute(context.Background(), raw, &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	data, err := json.Marshal(result.Value)
+	if err != nil {
+		t.Fatalf("Marshal result: %v", err)
+	}
+	var decoded struct {
+		Steps []struct {
+			Result json.RawMessage `json:"result"`
+		} `json:"steps"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal decoded result: %v", err)
+	}
+
+	var empty listKeysResult
+	if err := json.Unmarshal(decoded.Steps[0].Result, &empty); err != nil {
+		t.Fatalf("Unmarshal first list: %v", err)
+	}
+	if len(empty.Aliases) != 0 {
+		t.Fatalf("expected no aliases before imports, got %+v", empty.Aliases)
+	}
+
+	var listed listKeysResult
+	if err := json.Unmarshal(decoded.Steps[3].Result, &listed); err != nil {
+		t.Fatalf("Unmarshal list: %v", err)
+	}
+	if len(listed.Aliases) != 2 {
+		t.Fatalf("unexpected aliases: %+v", listed.Aliases)
+	}
+	build, carol := listed.Aliases[0], listed.Aliases[1]
+	if build.Alias != "build" || build.Source != "generated" || !build.ContainsPrivate || len(build.KeyIDs) != 1 {
+		t.Fatalf("unexpected generated alias: %+v", build)
+	}
+	if carol.Alias != "carol" || carol.Source != "inline" || carol.ContainsPrivate {
+		t.Fatalf("unexpected imported alias: %+v", carol)
+	}
+	if carol.Fingerprints[0] != fingerprintHex(entity.PrimaryKey) || carol.KeyIDs[0] != formatKeyID(entity.PrimaryKey.KeyId) {
+		t.Fatalf("unexpected imported key ids: %+v", carol)
+	}
+	if carol.Identities["Carol Example <carol@example.com>"] != "carol@example.com" {
+		t.Fatalf("unexpected identities: %v", carol.Identities)
+	}
+}
+
+func TestActionCoSignAndVerifyAll(t *testing.T) {
+	t.Parallel()
+
+	alice, err := openpgp.NewEntity("Alice", "", "alice@example.com", nil)
+	if err != nil {
+		t.Fatalf("NewEntity alice: %v", err)
+	}
+	bob, err := openpgp.NewEntity("Bob", "", "bob@example.com", nil)
+	if err != nil {
+		t.Fatalf("NewEntity bob: %v", err)
+	}
+	alicePriv, alicePub := exportEntity(t, alice)
+	bobPriv, bobPub := exportEntity(t, bob)
+	imports := []any{
+		map[string]any{"operation": "IMPORT_KEY", "alias": "alice", "key": alicePriv},
+		map[string]any{"operation": "IMPORT_KEY", "alias": "bob", "key": bobPriv},
+		map[string]any{"operation": "IMPORT_KEY", "alias": "alice_pub", "key": alicePub},
+		map[string]any{"operation": "IMPORT_KEY", "alias": "bob_pub", "key": bobPub},
+	}
+
+	execute := func(steps ...any) ([]json.RawMessage, error) {
+		raw, err := json.Marshal(map[string]any{"action": "PGP", "steps": append(append([]any{}, imports...), steps...)})
+		if err != nil {
+			t.Fatalf("Marshal payload: %v", err)
+		}
+		result, err := Action{}.Execute(context.Background(), raw, &registry.ExecutionContext{})
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(result.Value)
+		if err != nil {
+			t.Fatalf("Marshal result: %v", err)
+		}
+		var decoded struct {
+			Steps []struct {
+				Result json.RawMessage `json:"result"`
+			} `json:"steps"`
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal decoded result: %v", err)
+		}
+		results := make([]json.RawMessage, 0, len(decoded.Steps))
+		for _, step := range decoded.Steps[len(imports):] {
+			results = append(results, step.Result)
+		}
+		return results, nil
+	}
+
+	results, err := execute(map[string]any{
+		"operation":   "SIGN_DETACHED",
+		"signWithAll": []string{"alice", "bob"},
+		"message":     "package.tgz",
+		"keyIds":      []string{formatKeyID(alice.PrimaryKey.KeyId), formatKeyID(bob.PrimaryKey.KeyId)[8:]},
+	})
+	if err != nil {
+		t.Fatalf("Execute sign: %v", err)
+	}
+	var signed multiSignResult
+	if err := json.Unmarshal(results[0], &signed); err != nil {
+		t.Fatalf("Unmarshal sign result: %v", err)
+	}
+	if len(signed.Signatures) != 2 || signed.Signatures[0].SignerAlias != "alice" || signed.Signatures[1].SignerKeyID != formatKeyID(bob.PrimaryKey.KeyId) {
+		t.Fatalf("unexpected signatures: %+v", signed.Signatures)
+	}
+	aliceSig, bobSig := signed.Signatures[0].Signature.Value, signed.Signatures[1].Signature.Value
+
+	results, err = execute(map[string]any{
+		"operation":  "VERIFY_DETACHED",
+		"message":    "package.tgz",
+		"signatures": []string{aliceSig, bobSig},
+		"keyAliases": []string{"alice_pub", "bob_pub"},
+	})
+	if err != nil {
+		t.Fatalf("Execute verify all: %v", err)
+	}
+	var verified verifyResult
+	if err := json.Unmarshal(results[0], &verified); err != nil {
+		t.Fatalf("Unmarshal verify result: %v", err)
+	}
+	if !verified.Verified || verified.Require != "all" || len(verified.Signatures) != 2 || verified.Signatures[1].SignerAlias != "bob_pub" {
+		t.Fatalf("unexpected verify result: %+v", verified)
+	}
+
+	// Only alice's key is trusted: "all" fails while "any" still accepts.
+	verifyStep := map[string]any{
+		"operation":  "VERIFY_DETACHED",
+		"message":    "package.tgz",
+		"signatures": []string{aliceSig, bobSig},
+		"keyAliases": []string{"alice_pub"},
+	}
+	if _, err := execute(verifyStep); err == nil || !strings.Contains(err.Error(), "1 of 2 signatures verified") {
+		t.Fatalf("Execute verify all error = %v, want partial verification failure", err)
+	}
+	verifyStep["require"] = "any"
+	results, err = execute(verifyStep)
+	if err != nil {
+		t.Fatalf("Execute verify any: %v", err)
+	}
+	verified = verifyResult{}
+	if err := json.Unmarshal(results[0], &verified); err != nil {
+		t.Fatalf("Unmarshal verify any result: %v", err)
+	}
+	if !verified.Verified || !verified.Signatures[0].Verified || verified.Signatures[1].Verified || verified.Signatures[1].Error == "" {
+		t.Fatalf("unexpected verify any result: %+v", verified)
+	}
+
+	if _, err := execute(map[string]any{
+		"operation": "SIGN_DETACHED",
+		"signWith":  "alice",
+		"message":   "package.tgz",
+		"keyIds":    []string{"DEADBEEF"},
+	}); err == nil || !strings.Contains(err.Error(), "no signing key matching keyIds") {
+		t.Fatalf("Execute sign error = %v, want unmatched key id", err)
+	}
+}
+
+func TestActionSignWithSubkeySelectedByKeyID(t *testing.T) {
+	t.Parallel()
+
+	entity, err := openpgp.NewEntity("Subkey Signer", "", "subkey@example.com", nil)
+	if err != nil {
+		t.Fatalf("NewEntity: %v", err)
+	}
+	subPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	now := time.Now()
+	subkey := openpgp.Subkey{
+		PublicKey:  packet.NewECDSAPublicKey(now, &subPriv.PublicKey),
+		PrivateKey: packet.NewECDSAPrivateKey(now, subPriv),
+		Sig: &packet.Signature{
+			CreationTime: now,
+			SigType:      packet.SigTypeSubkeyBinding,
+			PubKeyAlgo:   entity.PrimaryKey.PubKeyAlgo,
+			Hash:         crypto.SHA256,
+			FlagsValid:   true,
+			FlagSign:     true,
+			IssuerKeyId:  &entity.PrimaryKey.KeyId,
+		},
+	}
+	subkey.PublicKey.IsSubkey = true
+	subkey.PrivateKey.IsSubkey = true
+	if err := subkey.Sig.SignKey(subkey.PublicKey, entity.PrivateKey, nil); err != nil {
+		t.Fatalf("SignKey: %v", err)
+	}
+	entity.Subkeys = append(entity.Subkeys, subkey)
+	subkeyID := formatKeyID(subkey.PublicKey.KeyId)
+
+	// x/crypto cannot serialize the cross-signature a signing subkey needs to
+	// be imported again, so the entity is registered directly.
+	state := newActionState(&registry.ExecutionContext{})
+	state.aliases["SIGNER"] = &keyEntry{Alias: "signer", Entities: openpgp.EntityList{entity}, ContainsPrivate: true}
+	state.entityAlias[entity] = "signer"
+
+	signed, err := state.executeSignDetached(signDetachedStep{SignWith: "signer", Message: "payload", KeyIDs: []string{"0x" + subkeyID}})
+	if err != nil {
+		t.Fatalf("executeSignDetached: %v", err)
+	}
+	if signed.SignerKeyID != subkeyID || signed.SignerAlias != "signer" || signed.SignerFingerprint != fingerprintHex(entity.PrimaryKey) {
+		t.Fatalf("unexpected sign result: %+v", signed)
+	}
+
+	signer, err := openpgp.CheckDetachedSignature(openpgp.EntityList{entity}, bytes.NewBufferString("payload"), mustDearmor(t, signed.Signature.Value))
+	if err != nil {
+		t.Fatalf("CheckDetachedSignature: %v", err)
+	}
+	if signer.PrimaryKey.KeyId != entity.PrimaryKey.KeyId {
+		t.Fatalf("unexpected signer: %s", formatKeyID(signer.PrimaryKey.KeyId))
+	}
+}
+
+func mustDearmor(t *testing.T, value string) *bytes.Reader {
+	t.Helper()
+
+	block, err := armor.Decode(bytes.NewBufferString(value))
+	if err != nil {
+		t.Fatalf("armor.Decode: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(block.Body); err != nil {
+		t.Fatalf("read armored body: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
 func exportEntity(t *testing.T, entity *openpgp.Entity) (string, string) {
 	t.Helper()
 
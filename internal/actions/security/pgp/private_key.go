@@ -0,0 +1,163 @@
+package pgp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+	"golang.org/x/crypto/openpgp/s2k"
+)
+
+// s2kUsageSHA1 marks a secret key packet whose key material is followed by a
+// SHA-1 hash and encrypted with a passphrase-derived key (RFC 4880, 5.5.3).
+const s2kUsageSHA1 = 254
+
+// exportProtectedPrivateKey serializes the private keys of the entities with
+// every secret key packet encrypted under passphrase. The entities themselves
+// are left decrypted so they stay usable for later steps.
+func exportProtectedPrivateKey(entities openpgp.EntityList, armored bool, passphrase []byte) ([]byte, error) {
+	return serializeKeys(openpgp.PrivateKeyType, armored, func(w io.Writer) error {
+		for _, entity := range entities {
+			if err := serializeProtectedEntity(w, entity, passphrase); err != nil {
+				return fmt.Errorf("serialize private key: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// serializeProtectedEntity mirrors Entity.SerializePrivate, which cannot write
+// encrypted keys. Signatures are written as they are, so they must already be
+// signed.
+func serializeProtectedEntity(w io.Writer, entity *openpgp.Entity, passphrase []byte) error {
+	if err := serializeProtectedKey(w, entity.PrivateKey, passphrase); err != nil {
+		return err
+	}
+	for _, ident := range entity.Identities {
+		if err := ident.UserId.Serialize(w); err != nil {
+			return err
+		}
+		if err := ident.SelfSignature.Serialize(w); err != nil {
+			return err
+		}
+	}
+	for _, subkey := range entity.Subkeys {
+		if err := serializeProtectedKey(w, subkey.PrivateKey, passphrase); err != nil {
+			return err
+		}
+		if err := subkey.Sig.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serializeProtectedKey writes a secret key packet whose key material is
+// encrypted with AES-256 in CFB mode under an iterated and salted S2K key.
+func serializeProtectedKey(w io.Writer, key *packet.PrivateKey, passphrase []byte) error {
+	if key == nil {
+		return errors.New("missing private key")
+	}
+
+	var plain bytes.Buffer
+	if err := key.Serialize(&plain); err != nil {
+		return err
+	}
+	tag, privateBody, err := splitPacket(plain.Bytes())
+	if err != nil {
+		return err
+	}
+
+	var public bytes.Buffer
+	if err := key.PublicKey.Serialize(&public); err != nil {
+		return err
+	}
+	_, publicBody, err := splitPacket(public.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// The unencrypted body is the public key, a zero S2K usage octet, the key
+	// material and a two octet checksum.
+	if len(privateBody) < len(publicBody)+3 {
+		return errors.New("truncated private key packet")
+	}
+	material := privateBody[len(publicBody)+1 : len(privateBody)-2]
+
+	var body bytes.Buffer
+	body.Write(publicBody)
+	body.WriteByte(s2kUsageSHA1)
+	body.WriteByte(byte(packet.CipherAES256))
+
+	symKey := make([]byte, 32)
+	if err := s2k.Serialize(&body, symKey, defaultConfig.Random(), passphrase, &s2k.Config{Hash: crypto.SHA256}); err != nil {
+		return err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(defaultConfig.Random(), iv); err != nil {
+		return err
+	}
+	body.Write(iv)
+
+	block, err := aes.NewCipher(symKey)
+	if err != nil {
+		return err
+	}
+	checksum := sha1.Sum(material)
+	data := append(append([]byte{}, material...), checksum[:]...)
+	cipher.NewCFBEncrypter(block, iv).XORKeyStream(data, data)
+	body.Write(data)
+
+	return writePacket(w, tag, body.Bytes())
+}
+
+// splitPacket returns the tag and body of a single new-format packet, the
+// format used by the packet serializers.
+func splitPacket(data []byte) (byte, []byte, error) {
+	if len(data) < 2 || data[0]&0xc0 != 0xc0 {
+		return 0, nil, errors.New("unexpected packet format")
+	}
+	tag := data[0] & 0x3f
+
+	var length, offset int
+	switch first := int(data[1]); {
+	case first < 192:
+		length, offset = first, 2
+	case first < 224:
+		if len(data) < 3 {
+			return 0, nil, errors.New("truncated packet header")
+		}
+		length, offset = (first-192)<<8+int(data[2])+192, 3
+	case first == 255:
+		if len(data) < 6 {
+			return 0, nil, errors.New("truncated packet header")
+		}
+		length = int(data[2])<<24 | int(data[3])<<16 | int(data[4])<<8 | int(data[5])
+		offset = 6
+	default:
+		return 0, nil, errors.New("partial packet lengths are not supported")
+	}
+	if len(data) != offset+length {
+		return 0, nil, errors.New("unexpected packet length")
+	}
+	return tag, data[offset:], nil
+}
+
+// writePacket writes a new-format packet using a five octet length.
+func writePacket(w io.Writer, tag byte, body []byte) error {
+	length := len(body)
+	header := []byte{0xc0 | tag, 255, byte(length >> 24), byte(length >> 16), byte(length >> 8), byte(length)}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
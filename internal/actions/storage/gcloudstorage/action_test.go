@@ -5,21 +5,34 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/mitchellh/mapstructure"
+	"google.golang.org/api/googleapi"
 
 	"flowk/internal/actions/registry"
 	"flowk/internal/flow"
 )
 
 type fakeService struct {
+	// mu guards objects and transient, which recursive operations reach from
+	// several workers.
+	mu      sync.Mutex
 	objects map[string]map[string]*fakeObject
-	auth    AuthInfo
-	closed  bool
+	// transient holds the number of 503 errors returned for a bucket/object
+	// before CopyObject or DeleteObject succeed.
+	transient map[string]int
+	auth      AuthInfo
+	signErr   error
+	closed    bool
 }
 
 type fakeObject struct {
@@ -28,11 +41,13 @@ type fakeObject struct {
 	data         []byte
 	updated      time.Time
 	contentType  string
+	cacheControl string
 	storageClass string
+	metadata     map[string]string
 }
 
 func newFakeService() *fakeService {
-	return &fakeService{objects: make(map[string]map[string]*fakeObject)}
+	return &fakeService{objects: make(map[string]map[string]*fakeObject), transient: make(map[string]int)}
 }
 
 func (f *fakeService) Close() error {
@@ -59,7 +74,23 @@ func (f *fakeService) ObjectExists(_ context.Context, path StoragePath) (bool, e
 	return ok, nil
 }
 
-func (f *fakeService) CopyObject(_ context.Context, src, dst StoragePath) error {
+// failTransiently consumes one pending transient failure for path. The
+// caller must hold f.mu.
+func (f *fakeService) failTransiently(path StoragePath) error {
+	key := path.Bucket + "/" + path.Object
+	if f.transient[key] == 0 {
+		return nil
+	}
+	f.transient[key]--
+	return &googleapi.Error{Code: http.StatusServiceUnavailable, Message: "backend unavailable"}
+}
+
+func (f *fakeService) CopyObject(_ context.Context, src, dst StoragePath, meta ObjectMetadata) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failTransiently(src); err != nil {
+		return err
+	}
 	bucket, ok := f.objects[src.Bucket]
 	if !ok {
 		return errors.New("source bucket not found")
@@ -71,11 +102,49 @@ func (f *fakeService) CopyObject(_ context.Context, src, dst StoragePath) error
 	f.ensureBucket(dst.Bucket)
 	copied := *object
 	copied.name = dst.Object
+	copied.metadata = make(map[string]string, len(object.metadata)+len(meta.Metadata))
+	for key, value := range object.metadata {
+		copied.metadata[key] = value
+	}
+	for key, value := range meta.Metadata {
+		copied.metadata[key] = value
+	}
+	if meta.ContentType != "" {
+		copied.contentType = meta.ContentType
+	}
+	if meta.CacheControl != "" {
+		copied.cacheControl = meta.CacheControl
+	}
 	f.objects[dst.Bucket][dst.Object] = &copied
 	return nil
 }
 
+func (f *fakeService) UploadObject(_ context.Context, dst StoragePath, r io.Reader, meta ObjectMetadata) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ensureBucket(dst.Bucket)
+	f.objects[dst.Bucket][dst.Object] = &fakeObject{
+		name:         dst.Object,
+		bucket:       dst.Bucket,
+		data:         data,
+		updated:      time.Now(),
+		contentType:  meta.ContentType,
+		cacheControl: meta.CacheControl,
+		metadata:     meta.Metadata,
+	}
+	return nil
+}
+
 func (f *fakeService) DeleteObject(_ context.Context, path StoragePath) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failTransiently(path); err != nil {
+		return err
+	}
 	bucket, ok := f.objects[path.Bucket]
 	if !ok {
 		return nil
@@ -122,6 +191,27 @@ func (f *fakeService) List(_ context.Context, path StoragePath, recursive bool)
 	return result, nil
 }
 
+func (f *fakeService) StatObject(_ context.Context, path StoragePath) (ObjectAttrs, bool, error) {
+	obj, ok := f.objects[path.Bucket][path.Object]
+	if !ok {
+		return ObjectAttrs{}, false, nil
+	}
+	return ObjectAttrs{
+		Name:         obj.name,
+		Size:         int64(len(obj.data)),
+		Updated:      obj.updated,
+		ContentType:  obj.contentType,
+		StorageClass: obj.storageClass,
+	}, true, nil
+}
+
+func (f *fakeService) SignURL(_ context.Context, path StoragePath, method string, expires time.Time) (string, error) {
+	if f.signErr != nil {
+		return "", f.signErr
+	}
+	return fmt.Sprintf("https://storage.googleapis.com/%s/%s?method=%s&expires=%d", path.Bucket, path.Object, method, expires.Unix()), nil
+}
+
 func (f *fakeService) FetchAuthInfo(context.Context) (AuthInfo, error) {
 	return f.auth, nil
 }
@@ -147,7 +237,7 @@ func TestExecuteCopySingleObject(t *testing.T) {
 	logger := &testLogger{}
 	execCtx := &registry.ExecutionContext{Logger: logger}
 
-	act := action{factory: func(context.Context) (Service, error) { return service, nil }}
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
 	payload := Payload{Operation: OperationCopy, Copy: &CopyPayload{Source: "gs://source/file.txt", Destination: "gs://target/file.txt"}}
 	raw, err := json.Marshal(payload)
 	if err != nil {
@@ -181,11 +271,249 @@ func TestExecuteCopySingleObject(t *testing.T) {
 	}
 }
 
+func TestExecuteCopyUploadsFile(t *testing.T) {
+	ctx := context.Background()
+	service := newFakeService()
+
+	localPath := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(localPath, []byte(`{"ok":true}`), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
+	payload := Payload{Operation: OperationCopy, Copy: &CopyPayload{
+		Source:      localPath,
+		Destination: "gs://reports/daily/",
+		ObjectMetadata: ObjectMetadata{
+			ContentType: "application/json",
+			Metadata:    map[string]string{"origin": "flowk"},
+		},
+	}}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	result, err := act.Execute(ctx, raw, &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var copyResult CopyResult
+	if err := mapstructure.Decode(result.Value, &copyResult); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+
+	want := []CopyEntry{{
+		Source:      localPath,
+		Destination: "gs://reports/daily/report.json",
+		Copied:      true,
+		Metadata:    &ObjectMetadata{ContentType: "application/json", Metadata: map[string]string{"origin": "flowk"}},
+	}}
+	if diff := cmp.Diff(want, copyResult.Entries); diff != "" {
+		t.Fatalf("unexpected entries (-want +got):\n%s", diff)
+	}
+	object, ok := service.objects["reports"]["daily/report.json"]
+	if !ok {
+		t.Fatalf("uploaded object missing")
+	}
+	if object.contentType != "application/json" || object.metadata["origin"] != "flowk" || string(object.data) != `{"ok":true}` {
+		t.Fatalf("unexpected uploaded object: %+v", object)
+	}
+}
+
+func TestExecuteCopyUploadsDirectoryWithGlob(t *testing.T) {
+	ctx := context.Background()
+	service := newFakeService()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.txt", filepath.Join("nested", "c.log")} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
+
+	run := func(cfg *CopyPayload) (CopyResult, error) {
+		raw, err := json.Marshal(Payload{Operation: OperationCopy, Copy: cfg})
+		if err != nil {
+			t.Fatalf("marshal payload: %v", err)
+		}
+		result, err := act.Execute(ctx, raw, &registry.ExecutionContext{})
+		if err != nil {
+			return CopyResult{}, err
+		}
+		var copyResult CopyResult
+		if err := mapstructure.Decode(result.Value, &copyResult); err != nil {
+			t.Fatalf("decode result: %v", err)
+		}
+		return copyResult, nil
+	}
+
+	if _, err := run(&CopyPayload{Source: dir, Destination: "gs://logs/all"}); err == nil || !strings.Contains(err.Error(), "set recursive") {
+		t.Fatalf("expected directory without recursive to fail, got %v", err)
+	}
+
+	result, err := run(&CopyPayload{Source: filepath.Join(dir, "*.log"), Destination: "gs://logs/top"})
+	if err != nil {
+		t.Fatalf("execute glob upload: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].Destination != "gs://logs/top/a.log" || !result.Entries[0].Copied {
+		t.Fatalf("unexpected glob entries: %+v", result.Entries)
+	}
+
+	result, err = run(&CopyPayload{Source: dir, Destination: "gs://logs/all", Recursive: true})
+	if err != nil {
+		t.Fatalf("execute recursive upload: %v", err)
+	}
+	if len(result.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", result.Entries)
+	}
+	for _, name := range []string{"all/a.log", "all/b.txt", "all/nested/c.log"} {
+		if _, ok := service.objects["logs"][name]; !ok {
+			t.Fatalf("object %s missing after recursive upload", name)
+		}
+	}
+}
+
+func TestExecuteCopyAppliesMetadataOverrides(t *testing.T) {
+	ctx := context.Background()
+	service := newFakeService()
+	service.ensureBucket("artifacts")
+	service.objects["artifacts"]["app.js"] = &fakeObject{
+		name:        "app.js",
+		bucket:      "artifacts",
+		data:        []byte("console.log(1)"),
+		contentType: "text/javascript",
+		metadata:    map[string]string{"build": "42", "stage": "ci"},
+	}
+
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
+	payload := Payload{Operation: OperationCopy, Copy: &CopyPayload{
+		Source:      "gs://artifacts/app.js",
+		Destination: "gs://site/app.js",
+		ObjectMetadata: ObjectMetadata{
+			CacheControl: "public, max-age=60",
+			Metadata:     map[string]string{"stage": "release"},
+		},
+	}}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	result, err := act.Execute(ctx, raw, &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	copyResult, ok := result.Value.(CopyResult)
+	if !ok {
+		t.Fatalf("unexpected result value %T", result.Value)
+	}
+	wantApplied := &ObjectMetadata{CacheControl: "public, max-age=60", Metadata: map[string]string{"stage": "release"}}
+	if len(copyResult.Entries) != 1 || !copyResult.Entries[0].Copied {
+		t.Fatalf("unexpected entries: %+v", copyResult.Entries)
+	}
+	if diff := cmp.Diff(wantApplied, copyResult.Entries[0].Metadata); diff != "" {
+		t.Fatalf("unexpected applied metadata (-want +got):\n%s", diff)
+	}
+
+	copied := service.objects["site"]["app.js"]
+	if copied.contentType != "text/javascript" || copied.cacheControl != "public, max-age=60" {
+		t.Fatalf("unexpected copied attributes: %+v", copied)
+	}
+	if diff := cmp.Diff(map[string]string{"build": "42", "stage": "release"}, copied.metadata); diff != "" {
+		t.Fatalf("unexpected copied metadata (-want +got):\n%s", diff)
+	}
+}
+
+func TestCopyPayloadRejectsMetadataForLocalDestination(t *testing.T) {
+	cfg := CopyPayload{Source: "gs://bucket/file.txt", Destination: "./file.txt", ObjectMetadata: ObjectMetadata{ContentType: "text/plain"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gs:// destination") {
+		t.Fatalf("Validate() error = %v, want gs:// destination error", err)
+	}
+}
+
+func TestExecuteCopyRecursiveConcurrentWithRetry(t *testing.T) {
+	defer func(delay time.Duration) { retryBaseDelay = delay }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	ctx := context.Background()
+	service := newFakeService()
+	service.ensureBucket("src")
+	var want []string
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("data/part-%02d.csv", i)
+		service.objects["src"][name] = &fakeObject{name: name, bucket: "src", data: []byte(name)}
+		want = append(want, "gs://src/"+name)
+	}
+	service.transient["src/data/part-03.csv"] = 2
+	service.transient["src/data/part-07.csv"] = maxAttempts
+
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
+	payload := Payload{Operation: OperationCopy, Copy: &CopyPayload{Source: "gs://src/data/", Destination: "gs://dst/copy", Concurrency: 3}}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	result, err := act.Execute(ctx, raw, &registry.ExecutionContext{Logger: &testLogger{}})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	copyResult, ok := result.Value.(CopyResult)
+	if !ok {
+		t.Fatalf("unexpected result value %T", result.Value)
+	}
+
+	var got []string
+	for _, entry := range copyResult.Entries {
+		got = append(got, entry.Source)
+		wantCopied := entry.Source != "gs://src/data/part-07.csv"
+		if entry.Copied != wantCopied {
+			t.Fatalf("unexpected entry %+v", entry)
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("entries not sorted by source (-want +got):\n%s", diff)
+	}
+	if _, ok := service.objects["dst"]["copy/part-03.csv"]; !ok {
+		t.Fatalf("object with transient failures was not retried")
+	}
+}
+
+func TestRunConcurrentlyStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	calls := 0
+	err := runConcurrently(ctx, 2, 100, func(context.Context, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 5 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("runConcurrently() error = %v, want context.Canceled", err)
+	}
+	if calls >= 100 {
+		t.Fatalf("expected pending items to be skipped after cancel, got %d calls", calls)
+	}
+}
+
 func TestExecuteMoveMissingSource(t *testing.T) {
 	ctx := context.Background()
 	service := newFakeService()
 
-	act := action{factory: func(context.Context) (Service, error) { return service, nil }}
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
 	payload := Payload{Operation: OperationMove, Move: &MovePayload{Source: "gs://bucket/missing.txt", Destination: "gs://bucket/other.txt"}}
 	raw, err := json.Marshal(payload)
 	if err != nil {
@@ -216,7 +544,7 @@ func TestExecuteRemoveHandlesMissingTargets(t *testing.T) {
 	service.ensureBucket("data")
 	service.objects["data"]["existing.txt"] = &fakeObject{name: "existing.txt", bucket: "data", data: []byte("value"), updated: time.Now()}
 
-	act := action{factory: func(context.Context) (Service, error) { return service, nil }}
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
 	payload := Payload{Operation: OperationRemove, Remove: &RemovePayload{Targets: []string{"gs://data/existing.txt", "gs://data/missing.txt"}}}
 	raw, err := json.Marshal(payload)
 	if err != nil {
@@ -242,7 +570,7 @@ func TestExecuteListMissingObjectIsNotError(t *testing.T) {
 	ctx := context.Background()
 	service := newFakeService()
 
-	act := action{factory: func(context.Context) (Service, error) { return service, nil }}
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
 	payload := Payload{Operation: OperationList, List: &ListPayload{Target: "gs://bucket/missing.txt"}}
 	raw, err := json.Marshal(payload)
 	if err != nil {
@@ -264,12 +592,112 @@ func TestExecuteListMissingObjectIsNotError(t *testing.T) {
 	}
 }
 
+func TestExecuteStat(t *testing.T) {
+	ctx := context.Background()
+	service := newFakeService()
+	service.ensureBucket("data")
+	updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.objects["data"]["dump.sql"] = &fakeObject{name: "dump.sql", bucket: "data", data: []byte("select 1;"), updated: updated, contentType: "application/sql", storageClass: "STANDARD"}
+
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
+	stat := func(target string) StatResult {
+		raw, err := json.Marshal(Payload{Operation: OperationStat, Stat: &StatPayload{Target: target}})
+		if err != nil {
+			t.Fatalf("marshal payload: %v", err)
+		}
+		result, err := act.Execute(ctx, raw, &registry.ExecutionContext{})
+		if err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+		response, ok := result.Value.(StatResponse)
+		if !ok {
+			t.Fatalf("unexpected result value %T", result.Value)
+		}
+		return response.Stat
+	}
+
+	want := StatResult{Target: "gs://data/dump.sql", Exists: true, Size: 9, Updated: &updated, ContentType: "application/sql", StorageClass: "STANDARD"}
+	if diff := cmp.Diff(want, stat("gs://data/dump.sql")); diff != "" {
+		t.Fatalf("unexpected stat result (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(StatResult{Target: "gs://data/missing.sql"}, stat("data/missing.sql")); diff != "" {
+		t.Fatalf("unexpected stat result for missing object (-want +got):\n%s", diff)
+	}
+}
+
+func TestExecuteSignURL(t *testing.T) {
+	ctx := context.Background()
+	service := newFakeService()
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
+
+	run := func(cfg *SignURLPayload) (registry.Result, error) {
+		raw, err := json.Marshal(Payload{Operation: OperationSignURL, SignURL: cfg})
+		if err != nil {
+			t.Fatalf("marshal payload: %v", err)
+		}
+		return act.Execute(ctx, raw, &registry.ExecutionContext{})
+	}
+
+	before := time.Now()
+	result, err := run(&SignURLPayload{Target: "gs://releases/app.tar.gz", Method: "put", ExpiresSeconds: 600})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	signed, ok := result.Value.(SignURLResult)
+	if !ok {
+		t.Fatalf("unexpected result value %T", result.Value)
+	}
+	if signed.Method != "PUT" || signed.Target != "gs://releases/app.tar.gz" || !strings.Contains(signed.URL, "/releases/app.tar.gz?method=PUT") {
+		t.Fatalf("unexpected signed URL result: %+v", signed)
+	}
+	if lifetime := signed.ExpiresAt.Sub(before); lifetime < 599*time.Second || lifetime > 601*time.Second {
+		t.Fatalf("unexpected expiry %s", signed.ExpiresAt)
+	}
+
+	if _, err := run(&SignURLPayload{Target: "gs://releases/app.tar.gz", Method: "DELETE", ExpiresSeconds: 600}); err == nil || !strings.Contains(err.Error(), "expected GET or PUT") {
+		t.Fatalf("expected method validation error, got %v", err)
+	}
+	if _, err := run(&SignURLPayload{Target: "gs://releases/app.tar.gz", ExpiresSeconds: 8 * 24 * 3600}); err == nil || !strings.Contains(err.Error(), "cannot exceed") {
+		t.Fatalf("expected expiry validation error, got %v", err)
+	}
+
+	service.signErr = errors.New("unable to detect default GoogleAccessID")
+	if _, err := run(&SignURLPayload{Target: "gs://releases/app.tar.gz", ExpiresSeconds: 60}); err == nil || !strings.Contains(err.Error(), "GoogleAccessID") {
+		t.Fatalf("expected signing error, got %v", err)
+	}
+}
+
+func TestExecutePassesExplicitCredentials(t *testing.T) {
+	ctx := context.Background()
+	service := newFakeService()
+
+	var got Credentials
+	act := action{factory: func(_ context.Context, creds Credentials) (Service, error) {
+		got = creds
+		return service, nil
+	}}
+
+	raw := []byte(`{"operation":"AUTH_INFO","credentialsFile":"/secrets/sa.json"}`)
+	if _, err := act.Execute(ctx, raw, &registry.ExecutionContext{}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got != (Credentials{File: "/secrets/sa.json"}) {
+		t.Fatalf("unexpected credentials passed to factory: %+v", got)
+	}
+
+	raw = []byte(`{"operation":"AUTH_INFO","credentialsFile":"/secrets/sa.json","credentialsJSON":"{}"}`)
+	if _, err := act.Execute(ctx, raw, &registry.ExecutionContext{}); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("expected mutually exclusive error, got %v", err)
+	}
+}
+
 func TestExecuteAuthInfo(t *testing.T) {
 	ctx := context.Background()
 	service := newFakeService()
 	service.auth = AuthInfo{ProjectID: "project", Account: "account@example.com", Scopes: []string{"scope"}, Source: "test"}
 
-	act := action{factory: func(context.Context) (Service, error) { return service, nil }}
+	act := action{factory: func(context.Context, Credentials) (Service, error) { return service, nil }}
 	payload := Payload{Operation: OperationAuthInfo}
 	raw, err := json.Marshal(payload)
 	if err != nil {
@@ -9,7 +9,9 @@ import (
     "os"
     fp "path/filepath"
     pth "path"
+    "sort"
     "strings"
+    "sync"
     "time"
 
 	"flowk/internal/actions/registry"
@@ -23,32 +25,57 @@ const (
 	OperationMove     Operation = "MV"
 	OperationRemove   Operation = "RM"
 	OperationList     Operation = "LS"
+	OperationStat     Operation = "STAT"
+	OperationSignURL  Operation = "SIGN_URL"
 	OperationAuthInfo Operation = "AUTH_INFO"
 )
 
 type Payload struct {
-	Operation Operation      `json:"operation"`
-	Copy      *CopyPayload   `json:"copy,omitempty"`
-	Move      *MovePayload   `json:"move,omitempty"`
-	Remove    *RemovePayload `json:"remove,omitempty"`
-	List      *ListPayload   `json:"list,omitempty"`
+	Operation Operation       `json:"operation"`
+	Copy      *CopyPayload    `json:"copy,omitempty"`
+	Move      *MovePayload    `json:"move,omitempty"`
+	Remove    *RemovePayload  `json:"remove,omitempty"`
+	List      *ListPayload    `json:"list,omitempty"`
+	Stat      *StatPayload    `json:"stat,omitempty"`
+	SignURL   *SignURLPayload `json:"sign_url,omitempty"`
+	Credentials
+}
+
+// Credentials selects an explicit service account key instead of the
+// application default credentials.
+type Credentials struct {
+	File string `json:"credentialsFile,omitempty"`
+	JSON string `json:"credentialsJSON,omitempty"`
 }
 
 type CopyPayload struct {
 	Source      string `json:"source"`
 	Destination string `json:"destination"`
 	Recursive   bool   `json:"recursive,omitempty"`
+	Concurrency int    `json:"concurrency,omitempty"`
+	ObjectMetadata
 }
 
 type MovePayload struct {
 	Source      string `json:"source"`
 	Destination string `json:"destination"`
 	Recursive   bool   `json:"recursive,omitempty"`
+	Concurrency int    `json:"concurrency,omitempty"`
+	ObjectMetadata
+}
+
+// ObjectMetadata holds the attributes applied to objects written by CP and MV.
+// Empty fields keep the attributes of the source object.
+type ObjectMetadata struct {
+	ContentType  string            `json:"contentType,omitempty"`
+	CacheControl string            `json:"cacheControl,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
 }
 
 type RemovePayload struct {
-	Targets   []string `json:"targets"`
-	Recursive bool     `json:"recursive,omitempty"`
+	Targets     []string `json:"targets"`
+	Recursive   bool     `json:"recursive,omitempty"`
+	Concurrency int      `json:"concurrency,omitempty"`
 }
 
 type ListPayload struct {
@@ -56,15 +83,29 @@ type ListPayload struct {
 	Recursive bool   `json:"recursive,omitempty"`
 }
 
+type StatPayload struct {
+	Target string `json:"target"`
+}
+
+// maxSignedURLExpiry is the longest lifetime allowed for V4 signed URLs.
+const maxSignedURLExpiry = 7 * 24 * time.Hour
+
+type SignURLPayload struct {
+	Target         string `json:"target"`
+	Method         string `json:"method,omitempty"`
+	ExpiresSeconds int    `json:"expires_seconds"`
+}
+
 type CopyResult struct {
 	Entries []CopyEntry `json:"entries"`
 }
 
 type CopyEntry struct {
-	Source      string `json:"source"`
-	Destination string `json:"destination"`
-	Copied      bool   `json:"copied"`
-	Skipped     string `json:"skipped,omitempty"`
+	Source      string          `json:"source"`
+	Destination string          `json:"destination"`
+	Copied      bool            `json:"copied"`
+	Skipped     string          `json:"skipped,omitempty"`
+	Metadata    *ObjectMetadata `json:"metadata,omitempty"`
 }
 
 type MoveResult struct {
@@ -72,10 +113,11 @@ type MoveResult struct {
 }
 
 type MoveEntry struct {
-	Source      string `json:"source"`
-	Destination string `json:"destination"`
-	Moved       bool   `json:"moved"`
-	Skipped     string `json:"skipped,omitempty"`
+	Source      string          `json:"source"`
+	Destination string          `json:"destination"`
+	Moved       bool            `json:"moved"`
+	Skipped     string          `json:"skipped,omitempty"`
+	Metadata    *ObjectMetadata `json:"metadata,omitempty"`
 }
 
 type RemoveResult struct {
@@ -104,6 +146,26 @@ type ListedObject struct {
 	StorageClass string    `json:"storageClass,omitempty"`
 }
 
+// StatResult describes a single object. Size is zero and the remaining
+// attributes are omitted when the object is missing.
+type StatResult struct {
+	Target       string     `json:"target"`
+	Exists       bool       `json:"exists"`
+	Size         int64      `json:"size"`
+	Updated      *time.Time `json:"updated,omitempty"`
+	ContentType  string     `json:"contentType,omitempty"`
+	StorageClass string     `json:"storageClass,omitempty"`
+	MD5          string     `json:"md5,omitempty"`
+	CRC32C       uint32     `json:"crc32c,omitempty"`
+}
+
+type SignURLResult struct {
+	Target    string    `json:"target"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 type AuthInfo struct {
 	ProjectID string   `json:"projectId,omitempty"`
 	Account   string   `json:"account,omitempty"`
@@ -115,18 +177,25 @@ type ListResponse struct {
 	List ListResult `json:"list"`
 }
 
+type StatResponse struct {
+	Stat StatResult `json:"stat"`
+}
+
 type AuthInfoResult struct {
 	Info AuthInfo `json:"info"`
 }
 
-type serviceFactory func(ctx context.Context) (Service, error)
+type serviceFactory func(ctx context.Context, creds Credentials) (Service, error)
 
 type Service interface {
 	Close() error
 	ObjectExists(ctx context.Context, path StoragePath) (bool, error)
-	CopyObject(ctx context.Context, src, dst StoragePath) error
+	CopyObject(ctx context.Context, src, dst StoragePath, meta ObjectMetadata) error
+	UploadObject(ctx context.Context, dst StoragePath, r io.Reader, meta ObjectMetadata) error
 	DeleteObject(ctx context.Context, path StoragePath) error
 	List(ctx context.Context, path StoragePath, recursive bool) (ServiceListResult, error)
+	StatObject(ctx context.Context, path StoragePath) (ObjectAttrs, bool, error)
+	SignURL(ctx context.Context, path StoragePath, method string, expires time.Time) (string, error)
 	FetchAuthInfo(ctx context.Context) (AuthInfo, error)
 }
 
@@ -142,6 +211,9 @@ type ObjectAttrs struct {
 	Updated      time.Time
 	ContentType  string
 	StorageClass string
+	// MD5 is hex encoded and empty for composite objects.
+	MD5    string
+	CRC32C uint32
 }
 
 type StoragePath struct {
@@ -150,6 +222,9 @@ type StoragePath struct {
 }
 
 func (p *Payload) Validate() error {
+	if strings.TrimSpace(p.Credentials.File) != "" && strings.TrimSpace(p.Credentials.JSON) != "" {
+		return errors.New("credentialsFile and credentialsJSON are mutually exclusive")
+	}
 	switch strings.ToUpper(string(p.Operation)) {
 	case string(OperationCopy):
 		if p.Copy == nil {
@@ -171,6 +246,16 @@ func (p *Payload) Validate() error {
 			return errors.New("list payload is required for LS operation")
 		}
 		return p.List.Validate()
+	case string(OperationStat):
+		if p.Stat == nil {
+			return errors.New("stat payload is required for STAT operation")
+		}
+		return p.Stat.Validate()
+	case string(OperationSignURL):
+		if p.SignURL == nil {
+			return errors.New("sign_url payload is required for SIGN_URL operation")
+		}
+		return p.SignURL.Validate()
 	case string(OperationAuthInfo):
 		return nil
 	default:
@@ -188,7 +273,13 @@ func (c *CopyPayload) Validate() error {
 	if strings.TrimSpace(c.Destination) == "" {
 		return errors.New("destination is required")
 	}
-	return nil
+	if !isGCSURI(c.Source) && !isGCSURI(c.Destination) {
+		return errors.New("source or destination must be a gs:// URI")
+	}
+	if !isGCSURI(c.Destination) && c.applied() != nil {
+		return errors.New("contentType, cacheControl and metadata require a gs:// destination")
+	}
+	return validateConcurrency(c.Concurrency)
 }
 
 func (m *MovePayload) Validate() error {
@@ -198,7 +289,21 @@ func (m *MovePayload) Validate() error {
 	if strings.TrimSpace(m.Destination) == "" {
 		return errors.New("destination is required")
 	}
-	return nil
+	return validateConcurrency(m.Concurrency)
+}
+
+// applied returns the trimmed attributes to set on written objects, or nil
+// when none are requested.
+func (m ObjectMetadata) applied() *ObjectMetadata {
+	applied := ObjectMetadata{
+		ContentType:  strings.TrimSpace(m.ContentType),
+		CacheControl: strings.TrimSpace(m.CacheControl),
+		Metadata:     m.Metadata,
+	}
+	if applied.ContentType == "" && applied.CacheControl == "" && len(applied.Metadata) == 0 {
+		return nil
+	}
+	return &applied
 }
 
 func (r *RemovePayload) Validate() error {
@@ -210,6 +315,13 @@ func (r *RemovePayload) Validate() error {
 			return fmt.Errorf("targets[%d] cannot be empty", i)
 		}
 	}
+	return validateConcurrency(r.Concurrency)
+}
+
+func validateConcurrency(value int) error {
+	if value < 0 || value > maxConcurrency {
+		return fmt.Errorf("concurrency must be between 1 and %d", maxConcurrency)
+	}
 	return nil
 }
 
@@ -220,6 +332,46 @@ func (l *ListPayload) Validate() error {
 	return nil
 }
 
+func (s *StatPayload) Validate() error {
+	if strings.TrimSpace(s.Target) == "" {
+		return errors.New("target is required")
+	}
+	if strings.ContainsAny(s.Target, "*?[") {
+		return errors.New("target cannot contain wildcards")
+	}
+	return nil
+}
+
+func (s *SignURLPayload) Validate() error {
+	if strings.TrimSpace(s.Target) == "" {
+		return errors.New("target is required")
+	}
+	if strings.ContainsAny(s.Target, "*?[") {
+		return errors.New("target cannot contain wildcards")
+	}
+	switch s.method() {
+	case "GET", "PUT":
+	default:
+		return fmt.Errorf("unsupported method %q: expected GET or PUT", s.Method)
+	}
+	if s.ExpiresSeconds <= 0 {
+		return errors.New("expires_seconds must be greater than zero")
+	}
+	if time.Duration(s.ExpiresSeconds)*time.Second > maxSignedURLExpiry {
+		return fmt.Errorf("expires_seconds cannot exceed %d", int(maxSignedURLExpiry/time.Second))
+	}
+	return nil
+}
+
+// method returns the upper-cased HTTP method, defaulting to GET.
+func (s *SignURLPayload) method() string {
+	method := strings.ToUpper(strings.TrimSpace(s.Method))
+	if method == "" {
+		return "GET"
+	}
+	return method
+}
+
 type action struct {
 	factory serviceFactory
 }
@@ -245,7 +397,7 @@ func (a action) Execute(ctx context.Context, payload json.RawMessage, execCtx *r
 		return registry.Result{}, err
 	}
 
-	service, err := a.factory(ctx)
+	service, err := a.factory(ctx, cfg.Credentials)
 	if err != nil {
 		return registry.Result{}, fmt.Errorf("initializing storage client: %w", err)
 	}
@@ -278,6 +430,18 @@ func (a action) Execute(ctx context.Context, payload json.RawMessage, execCtx *r
 			return registry.Result{}, err
 		}
 		return registry.Result{Value: result, Type: flow.ResultTypeJSON}, nil
+	case OperationStat:
+		result, err := executeStat(ctx, service, cfg.Stat, execCtx)
+		if err != nil {
+			return registry.Result{}, err
+		}
+		return registry.Result{Value: result, Type: flow.ResultTypeJSON}, nil
+	case OperationSignURL:
+		result, err := executeSignURL(ctx, service, cfg.SignURL, execCtx)
+		if err != nil {
+			return registry.Result{}, err
+		}
+		return registry.Result{Value: result, Type: flow.ResultTypeJSON}, nil
 	case OperationAuthInfo:
 		result, err := executeAuthInfo(ctx, service, execCtx)
 		if err != nil {
@@ -290,6 +454,10 @@ func (a action) Execute(ctx context.Context, payload json.RawMessage, execCtx *r
 }
 
 func executeCopy(ctx context.Context, service Service, cfg *CopyPayload, execCtx *registry.ExecutionContext) (CopyResult, error) {
+    if !isGCSURI(cfg.Source) {
+        return executeUpload(ctx, service, cfg, execCtx)
+    }
+
     // Determine if destination is GCS or local path
     dstIsGCS := strings.HasPrefix(strings.TrimSpace(cfg.Destination), "gs://")
 
@@ -319,6 +487,13 @@ func executeCopy(ctx context.Context, service Service, cfg *CopyPayload, execCtx
         recursive = true
     }
 
+    // Attributes applied to GCS destinations; source attributes are kept otherwise
+    applied := cfg.applied()
+    var meta ObjectMetadata
+    if applied != nil {
+        meta = *applied
+    }
+
     // If destination is GCS, parse it
     var dstPath StoragePath
     if dstIsGCS {
@@ -330,8 +505,12 @@ func executeCopy(ctx context.Context, service Service, cfg *CopyPayload, execCtx
 
     entries := make([]CopyEntry, 0)
 
-    // Helper to append entry with logging
+    // Helper to append entry with logging; recursive copies call it from
+    // several workers
+    var mu sync.Mutex
     addEntry := func(entry CopyEntry) {
+        mu.Lock()
+        defer mu.Unlock()
         if execCtx != nil && execCtx.Logger != nil && entry.Copied {
             execCtx.Logger.Printf("Copied %s to %s", entry.Source, entry.Destination)
         }
@@ -352,15 +531,9 @@ func executeCopy(ctx context.Context, service Service, cfg *CopyPayload, execCtx
         if dstIsGCS {
             destPrefix = ensureTrailingSlash(dstPath.Object)
         }
-        for _, obj := range list.Objects {
-            // Filter by glob if needed
-            if hasGlob {
-                rel := strings.TrimPrefix(obj.Name, prefix)
-                match, _ := pth.Match(pattern, rel)
-                if !match {
-                    continue
-                }
-            }
+        objects := filterObjects(list.Objects, prefix, pattern)
+        err = runConcurrently(ctx, concurrencyOrDefault(cfg.Concurrency), len(objects), func(ctx context.Context, i int) {
+            obj := objects[i]
             relative := strings.TrimPrefix(obj.Name, prefix)
             srcObj := StoragePath{Bucket: srcPath.Bucket, Object: obj.Name}
 
@@ -368,11 +541,12 @@ func executeCopy(ctx context.Context, service Service, cfg *CopyPayload, execCtx
                 destination := dstPath
                 destination.Object = destPrefix + relative
                 entry := CopyEntry{Source: buildGCSURI(srcPath.Bucket, obj.Name), Destination: buildGCSURI(destination.Bucket, destination.Object)}
-                if err := service.CopyObject(ctx, srcObj, destination); err != nil {
+                if err := withRetry(ctx, func() error { return service.CopyObject(ctx, srcObj, destination, meta) }); err != nil {
                     entry.Copied = false
                     entry.Skipped = err.Error()
                 } else {
                     entry.Copied = true
+                    entry.Metadata = applied
                 }
                 addEntry(entry)
             } else {
@@ -388,16 +562,20 @@ func executeCopy(ctx context.Context, service Service, cfg *CopyPayload, execCtx
                         destPathLocal = fp.Join(destPathLocal, fp.FromSlash(relative))
                     }
                 }
-                if err := downloadObjectToFile(ctx, service, srcObj, destPathLocal); err != nil {
+                if err := withRetry(ctx, func() error { return downloadObjectToFile(ctx, service, srcObj, destPathLocal) }); err != nil {
                     addEntry(CopyEntry{Source: buildGCSURI(srcObj.Bucket, srcObj.Object), Destination: destPathLocal, Copied: false, Skipped: err.Error()})
                 } else {
                     addEntry(CopyEntry{Source: buildGCSURI(srcObj.Bucket, srcObj.Object), Destination: destPathLocal, Copied: true})
                 }
             }
+        })
+        if err != nil {
+            return CopyResult{}, err
         }
         if len(entries) == 0 {
             return CopyResult{}, fmt.Errorf("no objects found under %s", cfg.Source)
         }
+        sortCopyEntries(entries)
         return CopyResult{Entries: entries}, nil
     }
 
@@ -414,11 +592,12 @@ func executeCopy(ctx context.Context, service Service, cfg *CopyPayload, execCtx
         return CopyResult{Entries: entries}, nil
     }
     if dstIsGCS {
-        if err := service.CopyObject(ctx, srcPath, dstPath); err != nil {
+        if err := withRetry(ctx, func() error { return service.CopyObject(ctx, srcPath, dstPath, meta) }); err != nil {
             entry.Copied = false
             entry.Skipped = err.Error()
         } else {
             entry.Copied = true
+            entry.Metadata = applied
         }
         addEntry(entry)
         return CopyResult{Entries: entries}, nil
@@ -433,7 +612,7 @@ func executeCopy(ctx context.Context, service Service, cfg *CopyPayload, execCtx
             destLocal = fp.Join(destLocal, fp.Base(srcPath.Object))
         }
     }
-    if err := downloadObjectToFile(ctx, service, srcPath, destLocal); err != nil {
+    if err := withRetry(ctx, func() error { return downloadObjectToFile(ctx, service, srcPath, destLocal) }); err != nil {
         entry.Copied = false
         entry.Skipped = err.Error()
         entry.Destination = destLocal
@@ -471,6 +650,129 @@ func downloadObjectToFile(ctx context.Context, svc Service, src StoragePath, des
     return nil
 }
 
+// executeUpload copies a local file, or the files below a local directory when
+// recursive is set or the source contains a glob, to GCS.
+func executeUpload(ctx context.Context, service Service, cfg *CopyPayload, execCtx *registry.ExecutionContext) (CopyResult, error) {
+	dstPath, err := parseGCSPath(cfg.Destination)
+	if err != nil {
+		return CopyResult{}, err
+	}
+	applied := cfg.applied()
+	var meta ObjectMetadata
+	if applied != nil {
+		meta = *applied
+	}
+
+	entries := make([]CopyEntry, 0)
+	var mu sync.Mutex
+	upload := func(ctx context.Context, localPath string, destination StoragePath) {
+		entry := CopyEntry{Source: localPath, Destination: buildGCSURI(destination.Bucket, destination.Object)}
+		err := withRetry(ctx, func() error { return uploadFileToObject(ctx, service, localPath, destination, meta) })
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			entry.Skipped = err.Error()
+		} else {
+			entry.Copied = true
+			entry.Metadata = applied
+			if execCtx != nil && execCtx.Logger != nil {
+				execCtx.Logger.Printf("Uploaded %s to %s", entry.Source, entry.Destination)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	// Split a glob into the directory to walk and the pattern matched against
+	// slash separated paths relative to it, as done for GCS sources.
+	source := strings.TrimSpace(cfg.Source)
+	baseDir := source
+	pattern := ""
+	if slashed := fp.ToSlash(source); strings.ContainsAny(slashed, "*?[") {
+		wc := strings.IndexAny(slashed, "*?[")
+		slash := strings.LastIndex(slashed[:wc], "/")
+		baseDir = "."
+		if slash >= 0 {
+			baseDir = fp.FromSlash(slashed[:slash+1])
+		}
+		pattern = slashed[slash+1:]
+	}
+
+	info, err := os.Stat(baseDir)
+	if err != nil {
+		if pattern == "" && errors.Is(err, os.ErrNotExist) {
+			entries = append(entries, CopyEntry{Source: cfg.Source, Destination: cfg.Destination, Skipped: "source not found"})
+			return CopyResult{Entries: entries}, nil
+		}
+		return CopyResult{}, fmt.Errorf("source %s: %w", cfg.Source, err)
+	}
+
+	if !info.IsDir() {
+		destination := dstPath
+		if destination.Object == "" || strings.HasSuffix(destination.Object, "/") {
+			destination.Object += fp.Base(baseDir)
+		}
+		upload(ctx, baseDir, destination)
+		return CopyResult{Entries: entries}, nil
+	}
+	if pattern == "" && !cfg.Recursive {
+		return CopyResult{}, fmt.Errorf("source %s is a directory: set recursive to upload it", cfg.Source)
+	}
+
+	type uploadJob struct {
+		localPath   string
+		destination StoragePath
+	}
+	var jobs []uploadJob
+	destPrefix := ensureTrailingSlash(dstPath.Object)
+	err = fp.WalkDir(baseDir, func(localPath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := fp.Rel(baseDir, localPath)
+		if err != nil {
+			return err
+		}
+		relative := fp.ToSlash(rel)
+		if pattern != "" {
+			if match, _ := pth.Match(pattern, relative); !match {
+				return nil
+			}
+		}
+		destination := dstPath
+		destination.Object = destPrefix + relative
+		jobs = append(jobs, uploadJob{localPath: localPath, destination: destination})
+		return nil
+	})
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("walking %s: %w", baseDir, err)
+	}
+	if len(jobs) == 0 {
+		return CopyResult{}, fmt.Errorf("no files found under %s", cfg.Source)
+	}
+
+	err = runConcurrently(ctx, concurrencyOrDefault(cfg.Concurrency), len(jobs), func(ctx context.Context, i int) {
+		upload(ctx, jobs[i].localPath, jobs[i].destination)
+	})
+	if err != nil {
+		return CopyResult{}, err
+	}
+	sortCopyEntries(entries)
+	return CopyResult{Entries: entries}, nil
+}
+
+func uploadFileToObject(ctx context.Context, svc Service, localPath string, dst StoragePath, meta ObjectMetadata) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return svc.UploadObject(ctx, dst, f, meta)
+}
+
 func executeMove(ctx context.Context, service Service, cfg *MovePayload, execCtx *registry.ExecutionContext) (MoveResult, error) {
     srcPath, err := parseGCSPath(cfg.Source)
     if err != nil {
@@ -481,6 +783,12 @@ func executeMove(ctx context.Context, service Service, cfg *MovePayload, execCtx
         return MoveResult{}, err
     }
 
+    applied := cfg.applied()
+    var meta ObjectMetadata
+    if applied != nil {
+        meta = *applied
+    }
+
     entries := make([]MoveEntry, 0)
 
     // Support glob in source
@@ -512,34 +820,39 @@ func executeMove(ctx context.Context, service Service, cfg *MovePayload, execCtx
         }
         prefix := ensureTrailingSlash(basePrefix)
         destPrefix := ensureTrailingSlash(dstPath.Object)
-        for _, obj := range list.Objects {
-            rel := strings.TrimPrefix(obj.Name, prefix)
-            if hasGlob {
-                match, _ := pth.Match(pattern, rel)
-                if !match {
-                    continue
-                }
-            }
+        objects := filterObjects(list.Objects, prefix, pattern)
+        var mu sync.Mutex
+        err = runConcurrently(ctx, concurrencyOrDefault(cfg.Concurrency), len(objects), func(ctx context.Context, i int) {
+            obj := objects[i]
+            source := StoragePath{Bucket: srcPath.Bucket, Object: obj.Name}
             destination := dstPath
-            destination.Object = destPrefix + rel
+            destination.Object = destPrefix + strings.TrimPrefix(obj.Name, prefix)
             entry := MoveEntry{Source: buildGCSURI(srcPath.Bucket, obj.Name), Destination: buildGCSURI(destination.Bucket, destination.Object)}
-            if err := service.CopyObject(ctx, StoragePath{Bucket: srcPath.Bucket, Object: obj.Name}, destination); err != nil {
+            if err := withRetry(ctx, func() error { return service.CopyObject(ctx, source, destination, meta) }); err != nil {
                 entry.Moved = false
                 entry.Skipped = err.Error()
-            } else if err := service.DeleteObject(ctx, StoragePath{Bucket: srcPath.Bucket, Object: obj.Name}); err != nil {
+            } else if err := withRetry(ctx, func() error { return service.DeleteObject(ctx, source) }); err != nil {
                 entry.Moved = false
                 entry.Skipped = fmt.Sprintf("copied but failed to delete source: %v", err)
             } else {
                 entry.Moved = true
-                if execCtx != nil && execCtx.Logger != nil {
-                    execCtx.Logger.Printf("Moved %s to %s", entry.Source, entry.Destination)
-                }
+                entry.Metadata = applied
+            }
+
+            mu.Lock()
+            defer mu.Unlock()
+            if entry.Moved && execCtx != nil && execCtx.Logger != nil {
+                execCtx.Logger.Printf("Moved %s to %s", entry.Source, entry.Destination)
             }
             entries = append(entries, entry)
+        })
+        if err != nil {
+            return MoveResult{}, err
         }
         if len(entries) == 0 {
             return MoveResult{}, fmt.Errorf("no objects found under %s", cfg.Source)
         }
+        sort.Slice(entries, func(i, j int) bool { return entries[i].Source < entries[j].Source })
     } else {
         exists, err := service.ObjectExists(ctx, srcPath)
         if err != nil {
@@ -550,14 +863,15 @@ func executeMove(ctx context.Context, service Service, cfg *MovePayload, execCtx
             entry.Moved = false
             entry.Skipped = "source not found"
         } else {
-            if err := service.CopyObject(ctx, srcPath, dstPath); err != nil {
+            if err := withRetry(ctx, func() error { return service.CopyObject(ctx, srcPath, dstPath, meta) }); err != nil {
                 entry.Moved = false
                 entry.Skipped = err.Error()
-            } else if err := service.DeleteObject(ctx, srcPath); err != nil {
+            } else if err := withRetry(ctx, func() error { return service.DeleteObject(ctx, srcPath) }); err != nil {
                 entry.Moved = false
                 entry.Skipped = fmt.Sprintf("copied but failed to delete source: %v", err)
             } else {
                 entry.Moved = true
+                entry.Metadata = applied
                 if execCtx != nil && execCtx.Logger != nil {
                     execCtx.Logger.Printf("Moved %s to %s", cfg.Source, cfg.Destination)
                 }
@@ -608,24 +922,33 @@ func executeRemove(ctx context.Context, service Service, cfg *RemovePayload, exe
             }
             deletedAny := false
             prefix := ensureTrailingSlash(basePrefix)
-            for _, obj := range list.Objects {
-                rel := strings.TrimPrefix(obj.Name, prefix)
-                if hasGlob {
-                    match, _ := pth.Match(pattern, rel)
-                    if !match {
-                        continue
-                    }
-                }
-                if err := service.DeleteObject(ctx, StoragePath{Bucket: path.Bucket, Object: obj.Name}); err != nil {
-                    entries = append(entries, RemoveEntry{Target: buildGCSURI(path.Bucket, obj.Name), Deleted: false, Message: err.Error()})
-                    continue
+            objects := filterObjects(list.Objects, prefix, pattern)
+            removed := make([]RemoveEntry, 0, len(objects))
+            var mu sync.Mutex
+            err = runConcurrently(ctx, concurrencyOrDefault(cfg.Concurrency), len(objects), func(ctx context.Context, i int) {
+                object := StoragePath{Bucket: path.Bucket, Object: objects[i].Name}
+                entry := RemoveEntry{Target: buildGCSURI(object.Bucket, object.Object)}
+                if err := withRetry(ctx, func() error { return service.DeleteObject(ctx, object) }); err != nil {
+                    entry.Message = err.Error()
+                } else {
+                    entry.Deleted = true
                 }
-                deletedAny = true
-                if execCtx != nil && execCtx.Logger != nil {
-                    execCtx.Logger.Printf("Deleted %s", buildGCSURI(path.Bucket, obj.Name))
+
+                mu.Lock()
+                defer mu.Unlock()
+                if entry.Deleted {
+                    deletedAny = true
+                    if execCtx != nil && execCtx.Logger != nil {
+                        execCtx.Logger.Printf("Deleted %s", entry.Target)
+                    }
                 }
-                entries = append(entries, RemoveEntry{Target: buildGCSURI(path.Bucket, obj.Name), Deleted: true})
+                removed = append(removed, entry)
+            })
+            if err != nil {
+                return RemoveResult{}, err
             }
+            sort.Slice(removed, func(i, j int) bool { return removed[i].Target < removed[j].Target })
+            entries = append(entries, removed...)
             if !deletedAny {
                 entries = append(entries, RemoveEntry{Target: target, Deleted: false, Message: "no objects matched"})
             }
@@ -640,7 +963,7 @@ func executeRemove(ctx context.Context, service Service, cfg *RemovePayload, exe
         if !exists {
             entry.Deleted = false
             entry.Message = "not found"
-        } else if err := service.DeleteObject(ctx, path); err != nil {
+        } else if err := withRetry(ctx, func() error { return service.DeleteObject(ctx, path) }); err != nil {
             entry.Deleted = false
             entry.Message = err.Error()
         } else {
@@ -737,6 +1060,63 @@ func executeList(ctx context.Context, service Service, cfg *ListPayload, execCtx
     return ListResponse{List: result}, nil
 }
 
+func executeStat(ctx context.Context, service Service, cfg *StatPayload, execCtx *registry.ExecutionContext) (StatResponse, error) {
+	target := ensureGCSURI(cfg.Target)
+	path, err := parseGCSPath(target)
+	if err != nil {
+		return StatResponse{}, err
+	}
+	if path.Object == "" || strings.HasSuffix(path.Object, "/") {
+		return StatResponse{}, fmt.Errorf("stat target %s must name an object", target)
+	}
+
+	attrs, exists, err := service.StatObject(ctx, path)
+	if err != nil {
+		return StatResponse{}, fmt.Errorf("stat %s: %w", target, err)
+	}
+
+	result := StatResult{Target: target, Exists: exists}
+	if !exists {
+		if execCtx != nil && execCtx.Logger != nil {
+			execCtx.Logger.Printf("Object %s not found", target)
+		}
+		return StatResponse{Stat: result}, nil
+	}
+
+	result.Size = attrs.Size
+	result.Updated = &attrs.Updated
+	result.ContentType = attrs.ContentType
+	result.StorageClass = attrs.StorageClass
+	result.MD5 = attrs.MD5
+	result.CRC32C = attrs.CRC32C
+	if execCtx != nil && execCtx.Logger != nil {
+		execCtx.Logger.Printf("Object %s: %d byte(s), updated %s", target, attrs.Size, attrs.Updated.Format(time.RFC3339))
+	}
+	return StatResponse{Stat: result}, nil
+}
+
+func executeSignURL(ctx context.Context, service Service, cfg *SignURLPayload, execCtx *registry.ExecutionContext) (SignURLResult, error) {
+	target := ensureGCSURI(cfg.Target)
+	path, err := parseGCSPath(target)
+	if err != nil {
+		return SignURLResult{}, err
+	}
+	if path.Object == "" || strings.HasSuffix(path.Object, "/") {
+		return SignURLResult{}, fmt.Errorf("sign_url target %s must name an object", target)
+	}
+
+	method := cfg.method()
+	expiresAt := time.Now().Add(time.Duration(cfg.ExpiresSeconds) * time.Second).UTC().Truncate(time.Second)
+	url, err := service.SignURL(ctx, path, method, expiresAt)
+	if err != nil {
+		return SignURLResult{}, fmt.Errorf("signing %s URL for %s: %w", method, target, err)
+	}
+	if execCtx != nil && execCtx.Logger != nil {
+		execCtx.Logger.Printf("Signed %s URL for %s valid until %s", method, target, expiresAt.Format(time.RFC3339))
+	}
+	return SignURLResult{Target: target, Method: method, URL: url, ExpiresAt: expiresAt}, nil
+}
+
 // ensureGCSURI ensures the provided path starts with gs://. If empty, returns empty.
 func ensureGCSURI(uri string) string {
     trimmed := strings.TrimSpace(uri)
@@ -761,6 +1141,29 @@ func executeAuthInfo(ctx context.Context, service Service, execCtx *registry.Exe
 	return AuthInfoResult{Info: info}, nil
 }
 
+// filterObjects returns the objects whose name relative to prefix matches
+// pattern. An empty pattern matches every object.
+func filterObjects(objects []ObjectAttrs, prefix, pattern string) []ObjectAttrs {
+	if pattern == "" {
+		return objects
+	}
+	matched := make([]ObjectAttrs, 0, len(objects))
+	for _, obj := range objects {
+		if match, _ := pth.Match(pattern, strings.TrimPrefix(obj.Name, prefix)); match {
+			matched = append(matched, obj)
+		}
+	}
+	return matched
+}
+
+func sortCopyEntries(entries []CopyEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Source < entries[j].Source })
+}
+
+func isGCSURI(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "gs://")
+}
+
 func ensureTrailingSlash(value string) string {
 	if value == "" {
 		return ""
@@ -0,0 +1,93 @@
+package gcloudstorage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// defaultConcurrency is the number of objects processed at once by
+	// recursive CP, MV and RM operations.
+	defaultConcurrency = 4
+	maxConcurrency     = 64
+	maxAttempts        = 5
+)
+
+// retryBaseDelay is the first backoff delay; it doubles on every retry.
+var retryBaseDelay = 200 * time.Millisecond
+
+func concurrencyOrDefault(value int) int {
+	if value <= 0 {
+		return defaultConcurrency
+	}
+	return value
+}
+
+// runConcurrently calls fn for each index in [0, n) using up to workers
+// goroutines. Pending items are skipped once ctx is cancelled, and the
+// context error is returned after the running calls finish.
+func runConcurrently(ctx context.Context, workers, n int, fn func(ctx context.Context, i int)) error {
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(ctx, i)
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// withRetry runs op, retrying transient storage errors with exponential
+// backoff.
+func withRetry(ctx context.Context, op func() error) error {
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt == maxAttempts || !isTransientError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// isTransientError reports whether err is a rate limit or server error
+// returned by the storage API.
+func isTransientError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+}
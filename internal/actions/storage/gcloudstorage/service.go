@@ -2,36 +2,72 @@ package gcloudstorage
 
 import (
     "context"
+    "encoding/hex"
     "encoding/json"
     "errors"
     "fmt"
+    "io"
     "os"
     "os/exec"
     "strings"
+    "time"
 
     "cloud.google.com/go/compute/metadata"
     "cloud.google.com/go/storage"
     "golang.org/x/oauth2/google"
     "google.golang.org/api/iterator"
+    "google.golang.org/api/option"
 )
 
 type gcsService struct {
 	client *storage.Client
 	creds  *google.Credentials
+	// source reports how explicit credentials were provided; it is empty
+	// for application default credentials.
+	source string
 }
 
-func newGCSService(ctx context.Context) (Service, error) {
-	creds, err := google.FindDefaultCredentials(ctx, storage.ScopeReadWrite)
+func newGCSService(ctx context.Context, explicit Credentials) (Service, error) {
+	var (
+		data   []byte
+		opts   []option.ClientOption
+		source string
+	)
+	switch {
+	case strings.TrimSpace(explicit.File) != "":
+		file := strings.TrimSpace(explicit.File)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading credentials file: %w", err)
+		}
+		data = content
+		opts = append(opts, option.WithCredentialsFile(file))
+		source = "credentials_file"
+	case strings.TrimSpace(explicit.JSON) != "":
+		data = []byte(explicit.JSON)
+		opts = append(opts, option.WithCredentialsJSON(data))
+		source = "credentials_inline"
+	}
+
+	var (
+		creds *google.Credentials
+		err   error
+	)
+	if data != nil {
+		creds, err = google.CredentialsFromJSON(ctx, data, storage.ScopeReadWrite)
+	} else {
+		creds, err = google.FindDefaultCredentials(ctx, storage.ScopeReadWrite)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	client, err := storage.NewClient(ctx)
+	client, err := storage.NewClient(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &gcsService{client: client, creds: creds}, nil
+	return &gcsService{client: client, creds: creds, source: source}, nil
 }
 
 func (s *gcsService) Close() error {
@@ -53,10 +89,46 @@ func (s *gcsService) ObjectExists(ctx context.Context, path StoragePath) (bool,
 	return err == nil, err
 }
 
-func (s *gcsService) CopyObject(ctx context.Context, src, dst StoragePath) error {
-	copier := s.client.Bucket(dst.Bucket).Object(dst.Object).CopierFrom(s.client.Bucket(src.Bucket).Object(src.Object))
-	_, err := copier.Run(ctx)
-	return err
+func (s *gcsService) CopyObject(ctx context.Context, src, dst StoragePath, meta ObjectMetadata) error {
+	object := s.client.Bucket(dst.Bucket).Object(dst.Object)
+	copier := object.CopierFrom(s.client.Bucket(src.Bucket).Object(src.Object))
+	if _, err := copier.Run(ctx); err != nil {
+		return err
+	}
+	// Setting attributes on the copier would replace all of the source
+	// metadata, so overrides are patched onto the copy instead.
+	if meta.applied() == nil {
+		return nil
+	}
+	update := storage.ObjectAttrsToUpdate{Metadata: meta.Metadata}
+	if meta.ContentType != "" {
+		update.ContentType = meta.ContentType
+	}
+	if meta.CacheControl != "" {
+		update.CacheControl = meta.CacheControl
+	}
+	if _, err := object.Update(ctx, update); err != nil {
+		return fmt.Errorf("copied but failed to update metadata: %w", err)
+	}
+	return nil
+}
+
+func (s *gcsService) UploadObject(ctx context.Context, dst StoragePath, r io.Reader, meta ObjectMetadata) error {
+	// Cancelling the context aborts the upload so a failed read does not
+	// leave a truncated object behind.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := s.client.Bucket(dst.Bucket).Object(dst.Object).NewWriter(ctx)
+	w.ContentType = meta.ContentType
+	w.CacheControl = meta.CacheControl
+	w.Metadata = meta.Metadata
+	if _, err := io.Copy(w, r); err != nil {
+		cancel()
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
 }
 
 func (s *gcsService) DeleteObject(ctx context.Context, path StoragePath) error {
@@ -114,6 +186,39 @@ func (s *gcsService) List(ctx context.Context, path StoragePath, recursive bool)
     return result, nil
 }
 
+func (s *gcsService) StatObject(ctx context.Context, path StoragePath) (ObjectAttrs, bool, error) {
+	attrs, err := s.client.Bucket(path.Bucket).Object(path.Object).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return ObjectAttrs{}, false, nil
+	}
+	if err != nil {
+		return ObjectAttrs{}, false, err
+	}
+	return ObjectAttrs{
+		Name:         attrs.Name,
+		Size:         attrs.Size,
+		Updated:      attrs.Updated,
+		ContentType:  attrs.ContentType,
+		StorageClass: attrs.StorageClass,
+		MD5:          hex.EncodeToString(attrs.MD5),
+		CRC32C:       attrs.CRC32C,
+	}, true, nil
+}
+
+func (s *gcsService) SignURL(_ context.Context, path StoragePath, method string, expires time.Time) (string, error) {
+	// The client detects the signing identity from the default credentials
+	// and falls back to the IAM signBlob API when no private key is present.
+	url, err := s.client.Bucket(path.Bucket).SignedURL(path.Object, &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  method,
+		Expires: expires,
+	})
+	if err != nil {
+		return "", fmt.Errorf("the active credentials cannot sign URLs (provide a service account key file): %w", err)
+	}
+	return url, nil
+}
+
 func (s *gcsService) FetchAuthInfo(ctx context.Context) (AuthInfo, error) {
     info := AuthInfo{}
     if s.creds != nil {
@@ -123,6 +228,9 @@ func (s *gcsService) FetchAuthInfo(ctx context.Context) (AuthInfo, error) {
             if err == nil {
                 info.Account = account
                 info.Source = "credentials_json"
+                if s.source != "" {
+                    info.Source = s.source
+                }
             }
         }
     }
@@ -0,0 +1,158 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"flowk/internal/actions/registry"
+)
+
+const (
+	ActionName = "ARCHIVE"
+
+	OperationCreate  = "CREATE"
+	OperationExtract = "EXTRACT"
+
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+type Payload struct {
+	Operation   string   `json:"operation"`
+	Format      string   `json:"format"`
+	Archive     string   `json:"archive"`
+	Inputs      []string `json:"inputs"`
+	BaseDir     string   `json:"baseDir"`
+	Destination string   `json:"destination"`
+	Overwrite   *bool    `json:"overwrite"`
+}
+
+type ExecutionResult struct {
+	Operation   string   `json:"operation"`
+	Format      string   `json:"format"`
+	Archive     string   `json:"archive"`
+	Size        int64    `json:"size"`
+	Destination string   `json:"destination,omitempty"`
+	Entries     []string `json:"entries"`
+}
+
+func (p *Payload) Validate() error {
+	p.Operation = strings.ToUpper(strings.TrimSpace(p.Operation))
+	p.Archive = strings.TrimSpace(p.Archive)
+	p.BaseDir = strings.TrimSpace(p.BaseDir)
+	p.Destination = strings.TrimSpace(p.Destination)
+
+	switch p.Operation {
+	case OperationCreate:
+		if len(p.Inputs) == 0 {
+			return fmt.Errorf("archive task: inputs are required for CREATE operation")
+		}
+		for i, input := range p.Inputs {
+			if strings.TrimSpace(input) == "" {
+				return fmt.Errorf("archive task: inputs[%d] cannot be empty", i)
+			}
+		}
+		if p.Destination != "" {
+			return fmt.Errorf("archive task: destination is only supported for EXTRACT operation")
+		}
+	case OperationExtract:
+		if p.Destination == "" {
+			return fmt.Errorf("archive task: destination is required for EXTRACT operation")
+		}
+		if len(p.Inputs) > 0 || p.BaseDir != "" {
+			return fmt.Errorf("archive task: inputs and baseDir are only supported for CREATE operation")
+		}
+	default:
+		return fmt.Errorf("archive task: unsupported operation %q", p.Operation)
+	}
+
+	if p.Archive == "" {
+		return fmt.Errorf("archive task: archive is required")
+	}
+
+	format, err := resolveFormat(p.Format, p.Archive)
+	if err != nil {
+		return err
+	}
+	p.Format = format
+	return nil
+}
+
+// resolveFormat normalises the requested format or infers it from the
+// archive extension when none is given.
+func resolveFormat(format, archivePath string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatZip:
+		return FormatZip, nil
+	case FormatTarGz, "tgz":
+		return FormatTarGz, nil
+	case "":
+		lower := strings.ToLower(archivePath)
+		switch {
+		case strings.HasSuffix(lower, ".zip"):
+			return FormatZip, nil
+		case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+			return FormatTarGz, nil
+		}
+		return "", fmt.Errorf("archive task: format is required when it cannot be inferred from %q", archivePath)
+	default:
+		return "", fmt.Errorf("archive task: unsupported format %q", format)
+	}
+}
+
+func (p Payload) overwrite() bool {
+	return p.Overwrite == nil || *p.Overwrite
+}
+
+func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionContext) (ExecutionResult, error) {
+	result := ExecutionResult{
+		Operation: spec.Operation,
+		Format:    spec.Format,
+		Archive:   spec.Archive,
+		Entries:   []string{},
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return result, fmt.Errorf("archive: operation interrupted: %w", ctxErr)
+	}
+
+	var (
+		entries []string
+		err     error
+	)
+	switch spec.Operation {
+	case OperationCreate:
+		entries, err = createArchive(ctx, spec)
+	case OperationExtract:
+		result.Destination = spec.Destination
+		entries, err = extractArchive(ctx, spec)
+	default:
+		err = fmt.Errorf("archive: unsupported operation %q", spec.Operation)
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Entries = entries
+
+	info, err := os.Stat(spec.Archive)
+	if err != nil {
+		return result, fmt.Errorf("archive: %w", err)
+	}
+	result.Size = info.Size()
+
+	if execCtx != nil && execCtx.Logger != nil {
+		execCtx.Logger.Printf("ARCHIVE: %s %s with %d entries (%d bytes)", spec.Operation, spec.Archive, len(entries), result.Size)
+	}
+	return result, nil
+}
+
+// isWithin reports whether path is root or one of its descendants.
+func isWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
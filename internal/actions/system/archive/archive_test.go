@@ -0,0 +1,186 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"flowk/internal/actions/registry"
+)
+
+func TestPayloadValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		payload    Payload
+		wantErr    string
+		wantFormat string
+	}{
+		{name: "unknown operation", payload: Payload{Operation: "LIST", Archive: "a.zip"}, wantErr: "unsupported operation"},
+		{name: "create without inputs", payload: Payload{Operation: OperationCreate, Archive: "a.zip"}, wantErr: "inputs are required"},
+		{name: "create with destination", payload: Payload{Operation: OperationCreate, Archive: "a.zip", Inputs: []string{"x"}, Destination: "out"}, wantErr: "destination is only supported"},
+		{name: "extract without destination", payload: Payload{Operation: OperationExtract, Archive: "a.zip"}, wantErr: "destination is required"},
+		{name: "extract with inputs", payload: Payload{Operation: OperationExtract, Archive: "a.zip", Destination: "out", Inputs: []string{"x"}}, wantErr: "only supported for CREATE"},
+		{name: "missing archive", payload: Payload{Operation: OperationCreate, Inputs: []string{"x"}}, wantErr: "archive is required"},
+		{name: "unknown extension", payload: Payload{Operation: OperationCreate, Archive: "a.rar", Inputs: []string{"x"}}, wantErr: "format is required"},
+		{name: "unknown format", payload: Payload{Operation: OperationCreate, Archive: "a.rar", Format: "rar", Inputs: []string{"x"}}, wantErr: "unsupported format"},
+		{name: "inferred zip", payload: Payload{Operation: OperationCreate, Archive: "out/A.ZIP", Inputs: []string{"x"}}, wantFormat: FormatZip},
+		{name: "inferred tgz", payload: Payload{Operation: "extract", Archive: "a.tgz", Destination: "out"}, wantFormat: FormatTarGz},
+		{name: "explicit format", payload: Payload{Operation: OperationCreate, Archive: "bundle", Format: "TGZ", Inputs: []string{"x"}}, wantFormat: FormatTarGz},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.payload.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if tt.wantFormat != "" && tt.payload.Format != tt.wantFormat {
+				t.Fatalf("format = %q, want %q", tt.payload.Format, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func execute(t *testing.T, payload map[string]any) (ExecutionResult, error) {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal payload: %v", err)
+	}
+	res, err := Action{}.Execute(context.Background(), raw, &registry.ExecutionContext{})
+	if err != nil {
+		return ExecutionResult{}, err
+	}
+	return res.Value.(ExecutionResult), nil
+}
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		target := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+}
+
+func TestCreateAndExtractRoundTrip(t *testing.T) {
+	for _, archiveName := range []string{"bundle.zip", "bundle.tar.gz"} {
+		archiveName := archiveName
+		t.Run(archiveName, func(t *testing.T) {
+			dir := t.TempDir()
+			dist := filepath.Join(dir, "dist")
+			writeTree(t, dist, map[string]string{
+				"app.js":             "console.log(1)",
+				"app.js.map":         "{}",
+				"assets/logo.svg":    "<svg/>",
+				"assets/css/app.css": "body{}",
+			})
+			archivePath := filepath.Join(dir, "out", archiveName)
+
+			created, err := execute(t, map[string]any{
+				"operation": OperationCreate,
+				"archive":   archivePath,
+				"baseDir":   dist,
+				"inputs":    []string{"*.js", "assets"},
+			})
+			if err != nil {
+				t.Fatalf("CREATE error = %v", err)
+			}
+			wantEntries := []string{"app.js", "assets/", "assets/css/", "assets/css/app.css", "assets/logo.svg"}
+			if !reflect.DeepEqual(created.Entries, wantEntries) {
+				t.Fatalf("entries = %v, want %v", created.Entries, wantEntries)
+			}
+			if created.Size == 0 {
+				t.Fatalf("archive size not reported: %+v", created)
+			}
+
+			dest := filepath.Join(dir, "extracted")
+			extracted, err := execute(t, map[string]any{
+				"operation":   OperationExtract,
+				"archive":     archivePath,
+				"destination": dest,
+			})
+			if err != nil {
+				t.Fatalf("EXTRACT error = %v", err)
+			}
+			if !reflect.DeepEqual(extracted.Entries, wantEntries) {
+				t.Fatalf("extracted entries = %v, want %v", extracted.Entries, wantEntries)
+			}
+			if data, err := os.ReadFile(filepath.Join(dest, "assets", "css", "app.css")); err != nil || string(data) != "body{}" {
+				t.Fatalf("extracted file = %q, %v", data, err)
+			}
+			if _, err := os.Stat(filepath.Join(dest, "app.js.map")); !os.IsNotExist(err) {
+				t.Fatalf("unmatched file was archived: %v", err)
+			}
+
+			if _, err := execute(t, map[string]any{"operation": OperationExtract, "archive": archivePath, "destination": dest, "overwrite": false}); err == nil || !strings.Contains(err.Error(), "already exists") {
+				t.Fatalf("expected overwrite error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateFailsWhenInputMatchesNothing(t *testing.T) {
+	dir := t.TempDir()
+	_, err := execute(t, map[string]any{
+		"operation": OperationCreate,
+		"archive":   filepath.Join(dir, "a.zip"),
+		"inputs":    []string{filepath.Join(dir, "*.bin")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "matched no files") {
+		t.Fatalf("expected no match error, got %v", err)
+	}
+}
+
+func TestExtractRejectsPathTraversal(t *testing.T) {
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	w, _ := zw.Create("../evil.txt")
+	w.Write([]byte("owned"))
+	zw.Close()
+
+	var tarBuf bytes.Buffer
+	gz := gzip.NewWriter(&tarBuf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc", Mode: 0o777})
+	tw.Close()
+	gz.Close()
+
+	for name, data := range map[string][]byte{"evil.zip": zipBuf.Bytes(), "evil.tar.gz": tarBuf.Bytes()} {
+		dir := t.TempDir()
+		archivePath := filepath.Join(dir, name)
+		if err := os.WriteFile(archivePath, data, 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		_, err := execute(t, map[string]any{
+			"operation":   OperationExtract,
+			"archive":     archivePath,
+			"destination": filepath.Join(dir, "out"),
+		})
+		if err == nil || !strings.Contains(err.Error(), "escapes the destination directory") {
+			t.Fatalf("%s: expected traversal error, got %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(err) {
+			t.Fatalf("%s: file written outside destination", name)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sourceEntry is a file system object to add to the archive under name.
+type sourceEntry struct {
+	name string
+	path string
+	info fs.FileInfo
+}
+
+// entryWriter adds entries to an archive of a given format.
+type entryWriter interface {
+	add(entry sourceEntry) error
+	Close() error
+}
+
+func createArchive(ctx context.Context, spec Payload) ([]string, error) {
+	if !spec.overwrite() {
+		if _, err := os.Lstat(spec.Archive); err == nil {
+			return nil, fmt.Errorf("archive: %s already exists and overwrite is false", spec.Archive)
+		}
+	}
+
+	sources, err := collectSources(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(spec.Archive), 0o755); err != nil {
+		return nil, fmt.Errorf("archive: creating parent directory of %s: %w", spec.Archive, err)
+	}
+	out, err := os.Create(spec.Archive)
+	if err != nil {
+		return nil, fmt.Errorf("archive: creating %s: %w", spec.Archive, err)
+	}
+
+	names, err := writeEntries(ctx, out, spec.Format, sources)
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("archive: writing %s: %w", spec.Archive, closeErr)
+	}
+	if err != nil {
+		_ = os.Remove(spec.Archive)
+		return nil, err
+	}
+	return names, nil
+}
+
+func writeEntries(ctx context.Context, out io.Writer, format string, sources []sourceEntry) ([]string, error) {
+	var writer entryWriter
+	if format == FormatZip {
+		writer = &zipEntryWriter{zw: zip.NewWriter(out)}
+	} else {
+		gz := gzip.NewWriter(out)
+		writer = &tarEntryWriter{gz: gz, tw: tar.NewWriter(gz)}
+	}
+
+	names := make([]string, 0, len(sources))
+	for _, entry := range sources {
+		if err := ctx.Err(); err != nil {
+			writer.Close()
+			return nil, fmt.Errorf("archive: operation interrupted: %w", err)
+		}
+		if err := writer.add(entry); err != nil {
+			writer.Close()
+			return nil, fmt.Errorf("archive: adding %s: %w", entry.path, err)
+		}
+		names = append(names, entry.name)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("archive: finishing archive: %w", err)
+	}
+	return names, nil
+}
+
+// collectSources expands the input patterns and walks matched directories.
+// Entry names are relative to baseDir when set and otherwise start at the
+// base name of each match.
+func collectSources(ctx context.Context, spec Payload) ([]sourceEntry, error) {
+	archiveAbs, err := filepath.Abs(spec.Archive)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	baseAbs := ""
+	if spec.BaseDir != "" {
+		if baseAbs, err = filepath.Abs(spec.BaseDir); err != nil {
+			return nil, fmt.Errorf("archive: %w", err)
+		}
+	}
+
+	var sources []sourceEntry
+	seen := make(map[string]string)
+	for _, input := range spec.Inputs {
+		pattern := strings.TrimSpace(input)
+		if baseAbs != "" && !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseAbs, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("archive: invalid input pattern %q: %w", input, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("archive: input %q matched no files", input)
+		}
+
+		for _, match := range matches {
+			root := filepath.Base(match)
+			if baseAbs != "" {
+				matchAbs, err := filepath.Abs(match)
+				if err != nil {
+					return nil, fmt.Errorf("archive: %w", err)
+				}
+				if !isWithin(matchAbs, baseAbs) {
+					return nil, fmt.Errorf("archive: input %s is outside baseDir %s", match, spec.BaseDir)
+				}
+				root, _ = filepath.Rel(baseAbs, matchAbs)
+			}
+
+			err := filepath.WalkDir(match, func(current string, d fs.DirEntry, walkErr error) error {
+				if walkErr != nil {
+					return walkErr
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if abs, err := filepath.Abs(current); err == nil && abs == archiveAbs {
+					return nil
+				}
+				rel, err := filepath.Rel(match, current)
+				if err != nil {
+					return err
+				}
+				name := filepath.ToSlash(filepath.Join(root, rel))
+				if name == "." {
+					return nil
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				if info.IsDir() {
+					name += "/"
+				}
+				if previous, ok := seen[name]; ok {
+					if previous == current {
+						return nil
+					}
+					return fmt.Errorf("%s and %s both map to entry %q", previous, current, name)
+				}
+				seen[name] = current
+				sources = append(sources, sourceEntry{name: name, path: current, info: info})
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("archive: reading input %s: %w", match, err)
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("archive: inputs did not contain any files")
+	}
+	return sources, nil
+}
+
+type zipEntryWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipEntryWriter) add(entry sourceEntry) error {
+	header, err := zip.FileInfoHeader(entry.info)
+	if err != nil {
+		return err
+	}
+	header.Name = entry.name
+	if !entry.info.IsDir() && entry.info.Mode().IsRegular() {
+		header.Method = zip.Deflate
+	}
+	dst, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case entry.info.IsDir():
+		return nil
+	case entry.info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(entry.path)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(dst, target)
+		return err
+	case entry.info.Mode().IsRegular():
+		return copyFrom(dst, entry.path)
+	default:
+		return fmt.Errorf("unsupported file type %s", entry.info.Mode().Type())
+	}
+}
+
+func (w *zipEntryWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarEntryWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (w *tarEntryWriter) add(entry sourceEntry) error {
+	link := ""
+	if entry.info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(entry.path)
+		if err != nil {
+			return err
+		}
+		link = target
+	} else if !entry.info.IsDir() && !entry.info.Mode().IsRegular() {
+		return fmt.Errorf("unsupported file type %s", entry.info.Mode().Type())
+	}
+
+	header, err := tar.FileInfoHeader(entry.info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = entry.name
+	// Owner names depend on the machine creating the archive.
+	header.Uname, header.Gname = "", ""
+	if err := w.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if entry.info.Mode().IsRegular() {
+		return copyFrom(w.tw, entry.path)
+	}
+	return nil
+}
+
+func (w *tarEntryWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		w.gz.Close()
+		return err
+	}
+	return w.gz.Close()
+}
+
+func copyFrom(dst io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(dst, f)
+	return err
+}
@@ -0,0 +1,232 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// extractor writes archive entries below the destination directory and
+// rejects any entry or link that would resolve outside of it (zip-slip).
+type extractor struct {
+	root      string
+	overwrite bool
+}
+
+func extractArchive(ctx context.Context, spec Payload) ([]string, error) {
+	root, err := filepath.Abs(spec.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("archive: creating destination %s: %w", spec.Destination, err)
+	}
+	x := extractor{root: root, overwrite: spec.overwrite()}
+
+	var entries []string
+	if spec.Format == FormatZip {
+		entries, err = x.extractZip(ctx, spec.Archive)
+	} else {
+		entries, err = x.extractTarGz(ctx, spec.Archive)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("archive: extracting %s: %w", spec.Archive, err)
+	}
+	return entries, nil
+}
+
+func (x extractor) extractZip(ctx context.Context, archivePath string) ([]string, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	entries := make([]string, 0, len(reader.File))
+	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name, target, err := x.target(file.Name)
+		if err != nil {
+			return nil, err
+		}
+		mode := file.Mode()
+
+		switch {
+		case mode.IsDir():
+			err = os.MkdirAll(target, dirPerm(mode))
+		case mode&fs.ModeSymlink != 0:
+			var link []byte
+			if link, err = readZipFile(file); err == nil {
+				err = x.symlink(name, target, string(link))
+			}
+		case mode.IsRegular():
+			var rc io.ReadCloser
+			if rc, err = file.Open(); err == nil {
+				err = x.writeFile(target, rc, mode.Perm())
+				rc.Close()
+			}
+		default:
+			err = fmt.Errorf("unsupported file type %s", mode.Type())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", file.Name, err)
+		}
+		entries = append(entries, displayName(name, mode.IsDir()))
+	}
+	return entries, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (x extractor) extractTarGz(ctx context.Context, archivePath string) ([]string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var entries []string
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name, target, err := x.target(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		mode := header.FileInfo().Mode()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, dirPerm(mode))
+		case tar.TypeReg:
+			err = x.writeFile(target, tr, mode.Perm())
+		case tar.TypeSymlink:
+			err = x.symlink(name, target, header.Linkname)
+		case tar.TypeLink:
+			var linkTarget string
+			if _, linkTarget, err = x.target(header.Linkname); err == nil {
+				err = x.replace(target)
+			}
+			if err == nil {
+				err = os.Link(linkTarget, target)
+			}
+		default:
+			err = fmt.Errorf("unsupported entry type %q", string(header.Typeflag))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", header.Name, err)
+		}
+		entries = append(entries, displayName(name, header.Typeflag == tar.TypeDir))
+	}
+	if entries == nil {
+		entries = []string{}
+	}
+	return entries, nil
+}
+
+// target resolves an entry name to its path below the destination.
+func (x extractor) target(entryName string) (string, string, error) {
+	name := path.Clean(strings.ReplaceAll(entryName, "\\", "/"))
+	if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") ||
+		filepath.VolumeName(filepath.FromSlash(name)) != "" {
+		return "", "", fmt.Errorf("entry %q escapes the destination directory", entryName)
+	}
+	target := filepath.Join(x.root, filepath.FromSlash(name))
+	if !isWithin(target, x.root) {
+		return "", "", fmt.Errorf("entry %q escapes the destination directory", entryName)
+	}
+	return name, target, nil
+}
+
+// symlink creates a link whose target must stay inside the destination.
+func (x extractor) symlink(name, target, link string) error {
+	cleaned := strings.ReplaceAll(link, "\\", "/")
+	if path.IsAbs(cleaned) || filepath.VolumeName(filepath.FromSlash(cleaned)) != "" {
+		return fmt.Errorf("link target %q escapes the destination directory", link)
+	}
+	resolved := filepath.Join(x.root, filepath.FromSlash(path.Join(path.Dir(name), cleaned)))
+	if !isWithin(resolved, x.root) {
+		return fmt.Errorf("link target %q escapes the destination directory", link)
+	}
+	if err := x.replace(target); err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
+
+// replace prepares target for a new entry, removing an existing file when
+// overwriting is allowed.
+func (x extractor) replace(target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(target); err == nil {
+		if !x.overwrite {
+			return fmt.Errorf("%s already exists and overwrite is false", target)
+		}
+		return os.Remove(target)
+	}
+	return nil
+}
+
+func (x extractor) writeFile(target string, src io.Reader, perm fs.FileMode) error {
+	if err := x.replace(target); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func dirPerm(mode fs.FileMode) fs.FileMode {
+	if perm := mode.Perm(); perm != 0 {
+		return perm
+	}
+	return 0o755
+}
+
+func displayName(name string, dir bool) string {
+	if dir {
+		return name + "/"
+	}
+	return name
+}
@@ -0,0 +1,408 @@
+package checksum
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"flowk/internal/actions/registry"
+)
+
+const (
+	ActionName = "CHECKSUM"
+
+	OperationCompute = "COMPUTE"
+	OperationVerify  = "VERIFY"
+
+	AlgorithmMD5    = "md5"
+	AlgorithmSHA1   = "sha1"
+	AlgorithmSHA256 = "sha256"
+	AlgorithmSHA512 = "sha512"
+)
+
+// digestLengths maps each supported algorithm to the length of its hex digest.
+var digestLengths = map[string]int{
+	AlgorithmMD5:    md5.Size * 2,
+	AlgorithmSHA1:   sha1.Size * 2,
+	AlgorithmSHA256: sha256.Size * 2,
+	AlgorithmSHA512: sha512.Size * 2,
+}
+
+type Payload struct {
+	Operation  string   `json:"operation"`
+	Algorithms []string `json:"algorithms"`
+	Paths      []string `json:"paths"`
+	Path       string   `json:"path"`
+	Expected   Expected `json:"expected"`
+	FailFast   bool     `json:"fail_fast"`
+}
+
+// Expected holds the digests VERIFY compares a file against, keyed by
+// algorithm. In a flow it is either a single digest string, optionally
+// prefixed with its algorithm ("sha256:<hex>"), or an object of algorithm to
+// digest. A bare digest is keyed by "" until Validate resolves its algorithm.
+type Expected map[string]string
+
+func (e *Expected) UnmarshalJSON(data []byte) error {
+	var digest string
+	if err := json.Unmarshal(data, &digest); err == nil {
+		*e = Expected{"": digest}
+		return nil
+	}
+	var digests map[string]string
+	if err := json.Unmarshal(data, &digests); err != nil {
+		return errors.New("expected must be a digest string or an object of algorithm to digest")
+	}
+	*e = digests
+	return nil
+}
+
+type ExecutionResult struct {
+	Operation  string       `json:"operation"`
+	Algorithms []string     `json:"algorithms"`
+	Files      []FileResult `json:"files"`
+	Errors     int          `json:"errors"`
+}
+
+// FileResult reports one file. Error is set instead of the digests when the
+// file could not be read; Match is only set by VERIFY.
+type FileResult struct {
+	Path    string            `json:"path"`
+	Size    int64             `json:"size"`
+	Digests map[string]string `json:"digests,omitempty"`
+	Match   *bool             `json:"match,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+func (p *Payload) Validate() error {
+	p.Operation = strings.ToUpper(strings.TrimSpace(p.Operation))
+	p.Path = strings.TrimSpace(p.Path)
+
+	algorithms, err := normalizeAlgorithms(p.Algorithms)
+	if err != nil {
+		return err
+	}
+	p.Algorithms = algorithms
+
+	switch p.Operation {
+	case OperationCompute:
+		if len(p.Paths) == 0 {
+			return fmt.Errorf("checksum task: paths are required for COMPUTE operation")
+		}
+		for i := range p.Paths {
+			p.Paths[i] = strings.TrimSpace(p.Paths[i])
+			if p.Paths[i] == "" {
+				return fmt.Errorf("checksum task: paths[%d] cannot be empty", i)
+			}
+		}
+		if p.Path != "" || len(p.Expected) > 0 {
+			return fmt.Errorf("checksum task: path and expected are only supported for VERIFY operation")
+		}
+		if len(p.Algorithms) == 0 {
+			p.Algorithms = []string{AlgorithmSHA256}
+		}
+	case OperationVerify:
+		if p.Path == "" {
+			return fmt.Errorf("checksum task: path is required for VERIFY operation")
+		}
+		if len(p.Paths) > 0 {
+			return fmt.Errorf("checksum task: paths are only supported for COMPUTE operation")
+		}
+		expected, err := normalizeExpected(p.Expected, p.Algorithms)
+		if err != nil {
+			return err
+		}
+		p.Expected = expected
+		p.Algorithms = expected.algorithms()
+	default:
+		return fmt.Errorf("checksum task: unsupported operation %q", p.Operation)
+	}
+
+	return nil
+}
+
+func normalizeAlgorithms(values []string) ([]string, error) {
+	var algorithms []string
+	seen := make(map[string]struct{}, len(values))
+	for i, value := range values {
+		algorithm := strings.ToLower(strings.TrimSpace(value))
+		if _, ok := digestLengths[algorithm]; !ok {
+			return nil, fmt.Errorf("checksum task: algorithms[%d]: unsupported algorithm %q", i, value)
+		}
+		if _, dup := seen[algorithm]; dup {
+			continue
+		}
+		seen[algorithm] = struct{}{}
+		algorithms = append(algorithms, algorithm)
+	}
+	return algorithms, nil
+}
+
+// normalizeExpected lower-cases the expected digests and resolves the
+// algorithm of a bare digest: from an "algorithm:" prefix, from algorithms
+// when it names exactly one, or from the length of the digest. Only the
+// first field of a digest is kept, so sha256sum output can be used as is.
+func normalizeExpected(expected Expected, algorithms []string) (Expected, error) {
+	if len(expected) == 0 {
+		return nil, fmt.Errorf("checksum task: expected is required for VERIFY operation")
+	}
+
+	normalized := make(Expected, len(expected))
+	for key, value := range expected {
+		digest := strings.ToLower(strings.TrimSpace(value))
+		if fields := strings.Fields(digest); len(fields) > 0 {
+			digest = fields[0]
+		}
+		algorithm := strings.ToLower(strings.TrimSpace(key))
+
+		if algorithm == "" {
+			if prefix, rest, found := strings.Cut(digest, ":"); found {
+				algorithm, digest = prefix, rest
+			} else if len(algorithms) == 1 {
+				algorithm = algorithms[0]
+			} else {
+				algorithm = algorithmForLength(len(digest))
+				if algorithm == "" {
+					return nil, fmt.Errorf("checksum task: cannot tell the algorithm of expected digest %q; prefix it, e.g. sha256:<digest>", value)
+				}
+			}
+		}
+
+		length, ok := digestLengths[algorithm]
+		if !ok {
+			return nil, fmt.Errorf("checksum task: expected: unsupported algorithm %q", algorithm)
+		}
+		if _, err := hex.DecodeString(digest); err != nil || len(digest) != length {
+			return nil, fmt.Errorf("checksum task: expected %s digest must be %d hexadecimal characters, got %q", algorithm, length, value)
+		}
+		if _, dup := normalized[algorithm]; dup {
+			return nil, fmt.Errorf("checksum task: expected lists %s more than once", algorithm)
+		}
+		normalized[algorithm] = digest
+	}
+	return normalized, nil
+}
+
+// algorithmForLength returns the algorithm whose digests have length hex
+// characters, or "" when none does.
+func algorithmForLength(length int) string {
+	for algorithm, digestLength := range digestLengths {
+		if digestLength == length {
+			return algorithm
+		}
+	}
+	return ""
+}
+
+func (e Expected) algorithms() []string {
+	algorithms := make([]string, 0, len(e))
+	for algorithm := range e {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+	return algorithms
+}
+
+func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionContext) (ExecutionResult, error) {
+	result := ExecutionResult{
+		Operation:  spec.Operation,
+		Algorithms: spec.Algorithms,
+		Files:      []FileResult{},
+	}
+
+	var logger registry.Logger
+	if execCtx != nil {
+		logger = execCtx.Logger
+	}
+
+	switch spec.Operation {
+	case OperationCompute:
+		for _, target := range expandPaths(spec.Paths) {
+			file := FileResult{Path: target.path}
+			err := target.err
+			if err == nil {
+				file.Size, file.Digests, err = hashFile(ctx, target.path, spec.Algorithms)
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return result, fmt.Errorf("checksum: operation interrupted: %w", ctxErr)
+			}
+			if err != nil {
+				file.Error = err.Error()
+				result.Errors++
+				if spec.FailFast {
+					return result, fmt.Errorf("checksum: %w", err)
+				}
+			}
+			logFile(logger, file, spec.Algorithms)
+			result.Files = append(result.Files, file)
+		}
+	case OperationVerify:
+		file := FileResult{Path: spec.Path}
+		size, digests, err := hashFile(ctx, spec.Path, spec.Algorithms)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return result, fmt.Errorf("checksum: operation interrupted: %w", ctxErr)
+		}
+		if err != nil {
+			return result, fmt.Errorf("checksum: %w", err)
+		}
+		file.Size, file.Digests = size, digests
+
+		var mismatched []string
+		for _, algorithm := range spec.Algorithms {
+			if digests[algorithm] != spec.Expected[algorithm] {
+				mismatched = append(mismatched, fmt.Sprintf("%s is %s, expected %s", algorithm, digests[algorithm], spec.Expected[algorithm]))
+			}
+		}
+		match := len(mismatched) == 0
+		file.Match = &match
+		logFile(logger, file, spec.Algorithms)
+		result.Files = append(result.Files, file)
+		if !match {
+			return result, fmt.Errorf("checksum: %s does not match: %s", spec.Path, strings.Join(mismatched, "; "))
+		}
+	default:
+		return result, fmt.Errorf("checksum: unsupported operation %q", spec.Operation)
+	}
+
+	return result, nil
+}
+
+type target struct {
+	path string
+	err  error
+}
+
+// expandPaths resolves paths and glob patterns into the files to hash, in
+// order and without repeats. A path that does not exist, a pattern that
+// matches nothing and a directory named directly become entries carrying the
+// error; directories matched by a pattern are skipped.
+func expandPaths(paths []string) []target {
+	var targets []target
+	seen := make(map[string]struct{})
+	add := func(entry target) {
+		if _, dup := seen[entry.path]; dup {
+			return
+		}
+		seen[entry.path] = struct{}{}
+		targets = append(targets, entry)
+	}
+
+	for _, pattern := range paths {
+		if !strings.ContainsAny(pattern, "*?[") {
+			info, err := os.Stat(pattern)
+			switch {
+			case err != nil:
+				add(target{path: pattern, err: err})
+			case info.IsDir():
+				add(target{path: pattern, err: fmt.Errorf("%s is a directory", pattern)})
+			default:
+				add(target{path: pattern})
+			}
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			add(target{path: pattern, err: fmt.Errorf("invalid pattern %q: %w", pattern, err)})
+			continue
+		}
+		if len(matches) == 0 {
+			add(target{path: pattern, err: fmt.Errorf("pattern %q matched no files", pattern)})
+			continue
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				continue
+			}
+			add(target{path: match})
+		}
+	}
+	return targets
+}
+
+// hashFile reads path once and returns its size and its hex digest for each
+// algorithm.
+func hashFile(ctx context.Context, path string, algorithms []string) (int64, map[string]string, error) {
+	hashes := make(map[string]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		h := newHash(algorithm)
+		hashes[algorithm] = h
+		writers = append(writers, h)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	size, err := io.Copy(io.MultiWriter(writers...), contextReader{ctx: ctx, reader: file})
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	digests := make(map[string]string, len(hashes))
+	for algorithm, h := range hashes {
+		digests[algorithm] = hex.EncodeToString(h.Sum(nil))
+	}
+	return size, digests, nil
+}
+
+func newHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case AlgorithmMD5:
+		return md5.New()
+	case AlgorithmSHA1:
+		return sha1.New()
+	case AlgorithmSHA512:
+		return sha512.New()
+	default:
+		return sha256.New()
+	}
+}
+
+// contextReader stops a long read once the context is done.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+func logFile(logger registry.Logger, file FileResult, algorithms []string) {
+	if logger == nil {
+		return
+	}
+	if file.Error != "" {
+		logger.Printf("CHECKSUM: %s: %s", file.Path, file.Error)
+		return
+	}
+	for _, algorithm := range algorithms {
+		logger.Printf("CHECKSUM: %s %s  %s", algorithm, file.Digests[algorithm], file.Path)
+	}
+	if file.Match != nil {
+		outcome := "matches"
+		if !*file.Match {
+			outcome = "does not match"
+		}
+		logger.Printf("CHECKSUM: %s %s the expected digest", file.Path, outcome)
+	}
+}
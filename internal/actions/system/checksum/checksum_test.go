@@ -0,0 +1,200 @@
+package checksum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"flowk/internal/actions/registry"
+)
+
+const (
+	helloMD5    = "5d41402abc4b2a76b9719d911017c592"
+	helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+)
+
+func TestPayloadValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		payload        string
+		wantErr        string
+		wantAlgorithms []string
+		wantExpected   Expected
+	}{
+		{name: "unknown operation", payload: `{"operation":"HASH"}`, wantErr: "unsupported operation"},
+		{name: "compute without paths", payload: `{"operation":"COMPUTE"}`, wantErr: "paths are required"},
+		{name: "compute with expected", payload: `{"operation":"COMPUTE","paths":["a"],"expected":"` + helloMD5 + `"}`, wantErr: "only supported for VERIFY"},
+		{name: "unknown algorithm", payload: `{"operation":"COMPUTE","paths":["a"],"algorithms":["crc32"]}`, wantErr: "unsupported algorithm"},
+		{name: "compute defaults to sha256", payload: `{"operation":"compute","paths":["a"]}`, wantAlgorithms: []string{AlgorithmSHA256}},
+		{name: "compute several algorithms", payload: `{"operation":"COMPUTE","paths":["a"],"algorithms":["SHA256","md5","sha256"]}`, wantAlgorithms: []string{AlgorithmSHA256, AlgorithmMD5}},
+		{name: "verify without expected", payload: `{"operation":"VERIFY","path":"a"}`, wantErr: "expected is required"},
+		{name: "verify without path", payload: `{"operation":"VERIFY","expected":"` + helloMD5 + `"}`, wantErr: "path is required"},
+		{name: "verify infers algorithm", payload: `{"operation":"VERIFY","path":"a","expected":"` + strings.ToUpper(helloSHA256) + `"}`, wantAlgorithms: []string{AlgorithmSHA256}, wantExpected: Expected{AlgorithmSHA256: helloSHA256}},
+		{name: "verify prefixed digest", payload: `{"operation":"VERIFY","path":"a","expected":"md5:` + helloMD5 + `"}`, wantAlgorithms: []string{AlgorithmMD5}, wantExpected: Expected{AlgorithmMD5: helloMD5}},
+		{name: "verify sha256sum output", payload: `{"operation":"VERIFY","path":"a","expected":"` + helloSHA256 + `  a\n"}`, wantExpected: Expected{AlgorithmSHA256: helloSHA256}},
+		{name: "verify digest object", payload: `{"operation":"VERIFY","path":"a","expected":{"sha256":"` + helloSHA256 + `","md5":"` + helloMD5 + `"}}`, wantAlgorithms: []string{AlgorithmMD5, AlgorithmSHA256}},
+		{name: "verify wrong length", payload: `{"operation":"VERIFY","path":"a","algorithms":["sha256"],"expected":"` + helloMD5 + `"}`, wantErr: "must be 64 hexadecimal characters"},
+		{name: "verify unknown length", payload: `{"operation":"VERIFY","path":"a","expected":"abc"}`, wantErr: "cannot tell the algorithm"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var payload Payload
+			err := json.Unmarshal([]byte(tt.payload), &payload)
+			if err == nil {
+				err = payload.Validate()
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if tt.wantAlgorithms != nil && !reflect.DeepEqual(payload.Algorithms, tt.wantAlgorithms) {
+				t.Fatalf("algorithms = %v, want %v", payload.Algorithms, tt.wantAlgorithms)
+			}
+			if tt.wantExpected != nil && !reflect.DeepEqual(payload.Expected, tt.wantExpected) {
+				t.Fatalf("expected = %v, want %v", payload.Expected, tt.wantExpected)
+			}
+		})
+	}
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	return dir
+}
+
+func execute(t *testing.T, payload map[string]any) (ExecutionResult, error) {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var spec Payload
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	return Execute(context.Background(), spec, &registry.ExecutionContext{})
+}
+
+func TestComputeReportsEachFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"dist/app.tar.gz": "hello",
+		"dist/app.zip":    "hello",
+		"dist/nested/x":   "ignored",
+	})
+
+	result, err := execute(t, map[string]any{
+		"operation":  OperationCompute,
+		"algorithms": []string{"sha256", "md5"},
+		"paths": []string{
+			filepath.Join(dir, "dist", "*"),
+			filepath.Join(dir, "dist", "app.zip"),
+			filepath.Join(dir, "missing.bin"),
+			filepath.Join(dir, "*.none"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(result.Files) != 4 || result.Errors != 2 {
+		t.Fatalf("files = %+v, errors = %d; want 4 files and 2 errors", result.Files, result.Errors)
+	}
+	for _, file := range result.Files[:2] {
+		want := map[string]string{AlgorithmSHA256: helloSHA256, AlgorithmMD5: helloMD5}
+		if file.Error != "" || file.Size != 5 || !reflect.DeepEqual(file.Digests, want) {
+			t.Fatalf("file = %+v, want digests %v", file, want)
+		}
+	}
+	if !strings.HasSuffix(result.Files[1].Path, "app.zip") {
+		t.Fatalf("files are not in order or repeated: %+v", result.Files)
+	}
+	if result.Files[2].Error == "" || result.Files[2].Digests != nil {
+		t.Fatalf("missing file = %+v, want an error entry", result.Files[2])
+	}
+	if !strings.Contains(result.Files[3].Error, "matched no files") {
+		t.Fatalf("empty pattern = %+v, want an error entry", result.Files[3])
+	}
+}
+
+func TestComputeFailFast(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.txt": "hello"})
+
+	result, err := execute(t, map[string]any{
+		"operation": OperationCompute,
+		"paths":     []string{filepath.Join(dir, "missing.txt"), filepath.Join(dir, "a.txt")},
+		"fail_fast": true,
+	})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Execute() error = %v, want os.ErrNotExist", err)
+	}
+	if len(result.Files) != 0 {
+		t.Fatalf("files = %+v, want none after failing fast", result.Files)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.txt": "hello"})
+	path := filepath.Join(dir, "a.txt")
+
+	result, err := execute(t, map[string]any{
+		"operation": OperationVerify,
+		"path":      path,
+		"expected":  map[string]string{"sha256": helloSHA256, "md5": strings.ToUpper(helloMD5)},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Match == nil || !*result.Files[0].Match {
+		t.Fatalf("files = %+v, want one match", result.Files)
+	}
+
+	wrong := strings.Repeat("0", 64)
+	_, err = execute(t, map[string]any{"operation": OperationVerify, "path": path, "expected": wrong})
+	if err == nil || !strings.Contains(err.Error(), "sha256 is "+helloSHA256+", expected "+wrong) {
+		t.Fatalf("Execute() error = %v, want a sha256 mismatch", err)
+	}
+
+	_, err = execute(t, map[string]any{"operation": OperationVerify, "path": filepath.Join(dir, "missing"), "expected": helloMD5})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Execute() error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestExecuteStopsWhenCancelled(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.txt": "hello"})
+	spec := Payload{Operation: OperationCompute, Paths: []string{filepath.Join(dir, "a.txt")}}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Execute(ctx, spec, &registry.ExecutionContext{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Execute() error = %v, want context.Canceled", err)
+	}
+}
@@ -3,10 +3,19 @@ package docker
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"flowk/internal/actions/registry"
@@ -14,9 +23,19 @@ import (
 
 const ActionName = "DOCKER"
 
+// dockerWaitDelay is how long a cancelled docker command gets to stop the
+// build or container it drives before it is killed.
+const dockerWaitDelay = 10 * time.Second
+
+// dockerHubServer is the registry credentials are stored under for images
+// that do not name a registry.
+const dockerHubServer = "https://index.docker.io/v1/"
+
 const (
 	OperationImagesList       = "IMAGES_LIST"
+	OperationImageBuild       = "IMAGE_BUILD"
 	OperationImagePull        = "IMAGE_PULL"
+	OperationImagePush        = "IMAGE_PUSH"
 	OperationImageRemove      = "IMAGE_REMOVE"
 	OperationImagePrune       = "IMAGE_PRUNE"
 	OperationContainersList   = "CONTAINERS_LIST"
@@ -41,19 +60,33 @@ const (
 )
 
 type Payload struct {
-	Operation   string   `json:"operation"`
-	Image       string   `json:"image"`
-	Container   string   `json:"container"`
-	Name        string   `json:"name"`
-	Volume      string   `json:"volume"`
-	Network     string   `json:"network"`
-	Command     []string `json:"command"`
-	Env         []string `json:"env"`
-	Ports       []string `json:"ports"`
-	Interactive bool     `json:"interactive"`
-	TTY         bool     `json:"tty"`
-	Detach      bool     `json:"detach"`
-	RemoveExisting bool  `json:"remove_existing"`
+	Operation      string        `json:"operation"`
+	Image          string        `json:"image"`
+	Container      string        `json:"container"`
+	Name           string        `json:"name"`
+	Volume         string        `json:"volume"`
+	Network        string        `json:"network"`
+	Command        []string      `json:"command"`
+	Env            []string      `json:"env"`
+	Ports          []string      `json:"ports"`
+	Mounts         []string      `json:"mounts"`
+	Interactive    bool          `json:"interactive"`
+	TTY            bool          `json:"tty"`
+	Detach         bool          `json:"detach"`
+	RemoveExisting bool          `json:"remove_existing"`
+	Context        string        `json:"context"`
+	Dockerfile     string        `json:"dockerfile"`
+	Tags           []string      `json:"tags"`
+	BuildArgs      []string      `json:"build_args"`
+	RegistryAuth   *RegistryAuth `json:"registry_auth"`
+}
+
+// RegistryAuth holds the credentials docker uses to pull and push images.
+// Server defaults to the registry named by the image, or Docker Hub.
+type RegistryAuth struct {
+	Server   string `json:"server"`
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 type ExecutionResult struct {
@@ -62,6 +95,8 @@ type ExecutionResult struct {
 	Stdout          string   `json:"stdout"`
 	Stderr          string   `json:"stderr"`
 	DurationSeconds float64  `json:"durationSeconds"`
+	ImageID         string   `json:"imageId,omitempty"`
+	Digest          string   `json:"digest,omitempty"`
 }
 
 func (p *Payload) Validate() error {
@@ -72,24 +107,30 @@ func (p *Payload) Validate() error {
 	p.Volume = strings.TrimSpace(p.Volume)
 	p.Network = strings.TrimSpace(p.Network)
 
-	for i := range p.Command {
-		p.Command[i] = strings.TrimSpace(p.Command[i])
-		if p.Command[i] == "" {
-			return fmt.Errorf("docker task: command[%d] is required", i)
-		}
-	}
-
-	for i := range p.Env {
-		p.Env[i] = strings.TrimSpace(p.Env[i])
-		if p.Env[i] == "" {
-			return fmt.Errorf("docker task: env[%d] is required", i)
+	p.Context = strings.TrimSpace(p.Context)
+	p.Dockerfile = strings.TrimSpace(p.Dockerfile)
+
+	for field, values := range map[string][]string{
+		"command":    p.Command,
+		"env":        p.Env,
+		"ports":      p.Ports,
+		"mounts":     p.Mounts,
+		"tags":       p.Tags,
+		"build_args": p.BuildArgs,
+	} {
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+			if values[i] == "" {
+				return fmt.Errorf("docker task: %s[%d] is required", field, i)
+			}
 		}
 	}
 
-	for i := range p.Ports {
-		p.Ports[i] = strings.TrimSpace(p.Ports[i])
-		if p.Ports[i] == "" {
-			return fmt.Errorf("docker task: ports[%d] is required", i)
+	if auth := p.RegistryAuth; auth != nil {
+		auth.Server = strings.TrimSpace(auth.Server)
+		auth.Username = strings.TrimSpace(auth.Username)
+		if auth.Username == "" || auth.Password == "" {
+			return errors.New("docker task: registry_auth requires username and password")
 		}
 	}
 
@@ -103,7 +144,11 @@ func (p *Payload) Validate() error {
 		OperationVolumePrune,
 		OperationNetworkList:
 		return nil
-	case OperationImagePull, OperationImageRemove:
+	case OperationImageBuild:
+		if p.Context == "" {
+			p.Context = "."
+		}
+	case OperationImagePull, OperationImagePush, OperationImageRemove:
 		if p.Image == "" {
 			return fmt.Errorf("docker task: image is required for %s", p.Operation)
 		}
@@ -138,8 +183,18 @@ func (p *Payload) Validate() error {
 }
 
 func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionContext) (ExecutionResult, error) {
+	var env []string
+	if spec.RegistryAuth != nil {
+		configDir, err := writeRegistryConfig(*spec.RegistryAuth, registryServer(spec))
+		if err != nil {
+			return ExecutionResult{}, err
+		}
+		defer os.RemoveAll(configDir)
+		env = append(os.Environ(), "DOCKER_CONFIG="+configDir)
+	}
+
 	if spec.Operation == OperationContainerRun && spec.RemoveExisting && strings.TrimSpace(spec.Name) != "" {
-		if err := removeContainerIfExists(ctx, spec.Name, execCtx); err != nil {
+		if err := removeContainerIfExists(ctx, spec.Name, env, execCtx); err != nil {
 			return ExecutionResult{}, err
 		}
 	}
@@ -149,83 +204,179 @@ func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionConte
 		return ExecutionResult{}, err
 	}
 
+	var imageIDFile string
+	if spec.Operation == OperationImageBuild {
+		dir, err := os.MkdirTemp("", "flowk-docker-build-")
+		if err != nil {
+			return ExecutionResult{}, fmt.Errorf("docker: creating image ID file: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		imageIDFile = filepath.Join(dir, "image-id")
+		// The build context stays the last argument.
+		args = slices.Insert(args, len(args)-1, "--iidfile", imageIDFile)
+	}
+
+	result, err := runDocker(ctx, args, env, execCtx.Logger)
+	if err != nil {
+		return ExecutionResult{}, err
+	}
+	if result.ExitCode != 0 {
+		return result, fmt.Errorf("docker: command exited with code %d", result.ExitCode)
+	}
+
+	switch spec.Operation {
+	case OperationImageBuild:
+		content, err := os.ReadFile(imageIDFile)
+		if err != nil {
+			return result, fmt.Errorf("docker: reading built image ID: %w", err)
+		}
+		result.ImageID = strings.TrimSpace(string(content))
+	case OperationImagePull, OperationImagePush:
+		imageID, err := inspectImageID(ctx, spec.Image, env)
+		if err != nil && execCtx.Logger != nil {
+			execCtx.Logger.Printf("DOCKER: WARNING: reading the image ID of %s: %v", spec.Image, err)
+		}
+		result.ImageID = imageID
+		if spec.Operation == OperationImagePush {
+			result.Digest = pushedDigest(result.Stdout)
+		}
+	}
+
+	return result, nil
+}
+
+// runDocker runs docker with args and streams its output to the logger line
+// by line while it runs. A non-zero exit code is reported in the result; the
+// error is reserved for commands that could not run or were interrupted.
+func runDocker(ctx context.Context, args, env []string, logger registry.Logger) (ExecutionResult, error) {
 	command := exec.CommandContext(ctx, "docker", args...)
+	command.Env = env
+	// An interrupt lets docker cancel the build or stop the attached
+	// container, which killing the client would leave running.
+	command.Cancel = func() error {
+		if runtime.GOOS == "windows" {
+			return command.Process.Kill()
+		}
+		return command.Process.Signal(os.Interrupt)
+	}
+	command.WaitDelay = dockerWaitDelay
 
 	var stdoutBuf, stderrBuf bytes.Buffer
-	command.Stdout = &stdoutBuf
-	command.Stderr = &stderrBuf
+	stdoutLog := newLineLogger(logger, "DOCKER stdout: ")
+	stderrLog := newLineLogger(logger, "DOCKER stderr: ")
+	command.Stdout = io.MultiWriter(&stdoutBuf, stdoutLog)
+	command.Stderr = io.MultiWriter(&stderrBuf, stderrLog)
 
-	logCommand(execCtx.Logger, args)
+	logCommand(logger, args)
 
 	start := time.Now()
 	runErr := command.Run()
 	duration := time.Since(start)
+	stdoutLog.Flush()
+	stderrLog.Flush()
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ExecutionResult{}, fmt.Errorf("docker: command interrupted: %w", ctxErr)
+	}
 
 	exitCode := 0
 	if runErr != nil {
 		var exitErr *exec.ExitError
-		if errors.As(runErr, &exitErr) {
-			exitCode = exitErr.ExitCode()
-		} else {
-			if ctxErr := ctx.Err(); ctxErr != nil {
-				return ExecutionResult{}, fmt.Errorf("docker: command interrupted: %w", ctxErr)
-			}
+		if !errors.As(runErr, &exitErr) {
 			return ExecutionResult{}, fmt.Errorf("docker: executing command: %w", runErr)
 		}
+		exitCode = exitErr.ExitCode()
 	}
 
-	stdout := stdoutBuf.String()
-	stderr := stderrBuf.String()
-
-	logCommandOutcome(execCtx.Logger, exitCode, stdout, stderr, duration)
+	logCommandOutcome(logger, exitCode, duration)
 
-	result := ExecutionResult{
+	return ExecutionResult{
 		Command:         append([]string{"docker"}, args...),
 		ExitCode:        exitCode,
-		Stdout:          stdout,
-		Stderr:          stderr,
+		Stdout:          stdoutBuf.String(),
+		Stderr:          stderrBuf.String(),
 		DurationSeconds: duration.Seconds(),
-	}
+	}, nil
+}
 
-	if runErr != nil && exitCode != 0 {
-		return result, fmt.Errorf("docker: command exited with code %d", exitCode)
+func removeContainerIfExists(ctx context.Context, name string, env []string, execCtx *registry.ExecutionContext) error {
+	result, err := runDocker(ctx, []string{"rm", "-f", name}, env, execCtx.Logger)
+	if err != nil {
+		return err
 	}
-
-	return result, nil
+	if result.ExitCode != 0 {
+		if strings.Contains(result.Stderr, "No such container") || strings.Contains(result.Stdout, "No such container") {
+			return nil
+		}
+		return fmt.Errorf("docker: remove existing container %q failed with code %d", name, result.ExitCode)
+	}
+	return nil
 }
 
-func removeContainerIfExists(ctx context.Context, name string, execCtx *registry.ExecutionContext) error {
-	args := []string{"rm", "-f", name}
-	command := exec.CommandContext(ctx, "docker", args...)
+func inspectImageID(ctx context.Context, image string, env []string) (string, error) {
+	command := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Id}}", image)
+	command.Env = env
+	output, err := command.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	var stdoutBuf, stderrBuf bytes.Buffer
-	command.Stdout = &stdoutBuf
-	command.Stderr = &stderrBuf
+var pushedDigestPattern = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
 
-	logCommand(execCtx.Logger, args)
-	start := time.Now()
-	runErr := command.Run()
-	duration := time.Since(start)
+// pushedDigest returns the registry digest docker push reports for the last
+// tag it pushed.
+func pushedDigest(stdout string) string {
+	matches := pushedDigestPattern.FindAllStringSubmatch(stdout, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1][1]
+}
 
-	exitCode := 0
-	if runErr != nil {
-		var exitErr *exec.ExitError
-		if errors.As(runErr, &exitErr) {
-			exitCode = exitErr.ExitCode()
-		}
+// registryServer returns the registry the credentials are for: the configured
+// server, or the registry named by the image (or first tag for builds).
+func registryServer(spec Payload) string {
+	if spec.RegistryAuth != nil && spec.RegistryAuth.Server != "" {
+		return spec.RegistryAuth.Server
 	}
+	reference := spec.Image
+	if reference == "" && len(spec.Tags) > 0 {
+		reference = spec.Tags[0]
+	}
+	host, _, found := strings.Cut(reference, "/")
+	if found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		return host
+	}
+	return dockerHubServer
+}
 
-	stdout := stdoutBuf.String()
-	stderr := stderrBuf.String()
-	logCommandOutcome(execCtx.Logger, exitCode, stdout, stderr, duration)
+// writeRegistryConfig writes a docker config directory holding only the
+// credentials for server, so they never reach the command line or the
+// user's own docker config. The caller removes the directory.
+func writeRegistryConfig(auth RegistryAuth, server string) (string, error) {
+	config := map[string]any{
+		"auths": map[string]any{
+			server: map[string]string{
+				"auth": base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password)),
+			},
+		},
+	}
+	content, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("docker: encoding registry credentials: %w", err)
+	}
 
-	if runErr != nil && exitCode != 0 {
-		if strings.Contains(stderr, "No such container") || strings.Contains(stdout, "No such container") {
-			return nil
-		}
-		return fmt.Errorf("docker: remove existing container %q failed with code %d", name, exitCode)
+	dir, err := os.MkdirTemp("", "flowk-docker-config-")
+	if err != nil {
+		return "", fmt.Errorf("docker: creating registry config: %w", err)
 	}
-	return nil
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), content, 0o600); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("docker: writing registry config: %w", err)
+	}
+	return dir, nil
 }
 
 func buildDockerArgs(spec Payload) ([]string, error) {
@@ -235,8 +386,22 @@ func buildDockerArgs(spec Payload) ([]string, error) {
 	switch spec.Operation {
 	case OperationImagesList:
 		args = append(args, "images")
+	case OperationImageBuild:
+		args = append(args, "build")
+		if spec.Dockerfile != "" {
+			args = append(args, "--file", spec.Dockerfile)
+		}
+		for _, tag := range spec.Tags {
+			args = append(args, "--tag", tag)
+		}
+		for _, buildArg := range spec.BuildArgs {
+			args = append(args, "--build-arg", buildArg)
+		}
+		args = append(args, spec.Context)
 	case OperationImagePull:
 		args = append(args, "pull", spec.Image)
+	case OperationImagePush:
+		args = append(args, "push", spec.Image)
 	case OperationImageRemove:
 		args = append(args, "rmi", spec.Image)
 	case OperationImagePrune:
@@ -260,6 +425,9 @@ func buildDockerArgs(spec Payload) ([]string, error) {
 		for _, port := range spec.Ports {
 			args = append(args, "-p", port)
 		}
+		for _, mount := range spec.Mounts {
+			args = append(args, "-v", mount)
+		}
 		args = append(args, spec.Image)
 		args = append(args, spec.Command...)
 	case OperationContainerStart:
@@ -322,16 +490,54 @@ func logCommand(logger registry.Logger, args []string) {
 	logger.Printf("DOCKER: executing docker %s", strings.Join(args, " "))
 }
 
-func logCommandOutcome(logger registry.Logger, exitCode int, stdout, stderr string, duration time.Duration) {
+func logCommandOutcome(logger registry.Logger, exitCode int, duration time.Duration) {
 	if logger == nil {
 		return
 	}
-
 	logger.Printf("DOCKER: exit code %d (duration %s)", exitCode, duration.Round(time.Millisecond))
-	if strings.TrimSpace(stdout) != "" {
-		logger.Printf("DOCKER stdout:\n%s", stdout)
+}
+
+// lineLogger forwards complete lines written to it to the logger. Flush
+// emits a trailing line without a newline.
+type lineLogger struct {
+	logger  registry.Logger
+	prefix  string
+	mu      sync.Mutex
+	pending []byte
+}
+
+func newLineLogger(logger registry.Logger, prefix string) *lineLogger {
+	return &lineLogger{logger: logger, prefix: prefix}
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pending = append(l.pending, p...)
+	for {
+		idx := bytes.IndexByte(l.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		l.emit(strings.TrimRight(string(l.pending[:idx]), "\r"))
+		l.pending = l.pending[idx+1:]
 	}
-	if strings.TrimSpace(stderr) != "" {
-		logger.Printf("DOCKER stderr:\n%s", stderr)
+	return len(p), nil
+}
+
+func (l *lineLogger) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) > 0 {
+		l.emit(string(l.pending))
+		l.pending = nil
+	}
+}
+
+func (l *lineLogger) emit(line string) {
+	if l.logger != nil {
+		l.logger.Printf("%s%s", l.prefix, line)
 	}
 }
@@ -1,8 +1,19 @@
 package docker
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"flowk/internal/actions/registry"
 )
 
 func TestPayloadValidate(t *testing.T) {
@@ -18,6 +29,10 @@ func TestPayloadValidate(t *testing.T) {
 		{name: "exec requires container", payload: Payload{Operation: OperationContainerExec, Command: []string{"ls"}}, wantErr: "container is required"},
 		{name: "exec requires command", payload: Payload{Operation: OperationContainerExec, Container: "c1"}, wantErr: "command is required"},
 		{name: "valid run", payload: Payload{Operation: OperationContainerRun, Image: "alpine"}},
+		{name: "push requires image", payload: Payload{Operation: OperationImagePush}, wantErr: "image is required"},
+		{name: "empty tag", payload: Payload{Operation: OperationImageBuild, Tags: []string{" "}}, wantErr: "tags[0] is required"},
+		{name: "auth requires password", payload: Payload{Operation: OperationImagePull, Image: "alpine", RegistryAuth: &RegistryAuth{Username: "ci"}}, wantErr: "registry_auth requires username and password"},
+		{name: "valid build", payload: Payload{Operation: OperationImageBuild}},
 	}
 
 	for _, tt := range tests {
@@ -61,7 +76,183 @@ func TestBuildDockerArgsAndFlags(t *testing.T) {
 		t.Fatal("expected unsupported operation error")
 	}
 
+	buildArgs, err := buildDockerArgs(Payload{Operation: OperationImageBuild, Context: "app", Dockerfile: "app/Dockerfile.prod", Tags: []string{"demo:1", "demo:latest"}, BuildArgs: []string{"VERSION=1"}})
+	if err != nil {
+		t.Fatalf("buildDockerArgs(build) returned error: %v", err)
+	}
+	if got := strings.Join(buildArgs, " "); got != "build --file app/Dockerfile.prod --tag demo:1 --tag demo:latest --build-arg VERSION=1 app" {
+		t.Fatalf("unexpected build args: %q", got)
+	}
+
+	mountArgs, err := buildDockerArgs(Payload{Operation: OperationContainerRun, Image: "alpine", Mounts: []string{"/data:/data:ro"}})
+	if err != nil {
+		t.Fatalf("buildDockerArgs(run) returned error: %v", err)
+	}
+	if got := strings.Join(mountArgs, " "); got != "run -v /data:/data:ro alpine" {
+		t.Fatalf("unexpected run args: %q", got)
+	}
+
 	if got := dockerFlags(true, true); strings.Join(got, " ") != "-i -t" {
 		t.Fatalf("unexpected dockerFlags output: %v", got)
 	}
 }
+
+func TestRegistryServer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec Payload
+		want string
+	}{
+		{spec: Payload{Image: "alpine"}, want: dockerHubServer},
+		{spec: Payload{Image: "library/alpine:3"}, want: dockerHubServer},
+		{spec: Payload{Image: "ghcr.io/acme/app:1"}, want: "ghcr.io"},
+		{spec: Payload{Image: "localhost:5000/app"}, want: "localhost:5000"},
+		{spec: Payload{Tags: []string{"registry.example.com/app:1"}}, want: "registry.example.com"},
+		{spec: Payload{Image: "ghcr.io/acme/app", RegistryAuth: &RegistryAuth{Server: "mirror.example.com"}}, want: "mirror.example.com"},
+	}
+	for _, tt := range tests {
+		if got := registryServer(tt.spec); got != tt.want {
+			t.Fatalf("registryServer(%+v) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) PrintColored(plain, _ string) {
+	l.Printf("%s", plain)
+}
+
+func (l *recordingLogger) contains(line string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, logged := range l.lines {
+		if logged == line {
+			return true
+		}
+	}
+	return false
+}
+
+const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// installFakeDocker puts a docker script first on PATH that answers the
+// commands the action runs.
+func installFakeDocker(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker is a POSIX shell script")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+build)
+	while [ "$#" -gt 0 ]; do
+		if [ "$1" = "--iidfile" ]; then printf 'sha256:built' > "$2"; fi
+		shift
+	done
+	echo "step 1/2"
+	echo "step 2/2" >&2
+	;;
+push)
+	cat "$DOCKER_CONFIG/config.json"; echo
+	echo "latest: digest: ` + digest + ` size: 528"
+	;;
+image)
+	echo "sha256:local"
+	;;
+pull)
+	trap 'echo interrupted > "$FAKE_DOCKER_TRAP"; exit 130' INT
+	sleep 5 >/dev/null 2>&1 &
+	wait
+	;;
+run)
+	echo "partial output"
+	exit 3
+	;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatalf("writing fake docker: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestExecuteWithFakeDocker(t *testing.T) {
+	installFakeDocker(t)
+
+	t.Run("build", func(t *testing.T) {
+		logger := &recordingLogger{}
+		spec := Payload{Operation: OperationImageBuild, Tags: []string{"demo:1"}}
+		if err := spec.Validate(); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		result, err := Execute(context.Background(), spec, &registry.ExecutionContext{Logger: logger})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if result.ImageID != "sha256:built" {
+			t.Fatalf("ImageID = %q, want sha256:built", result.ImageID)
+		}
+		for _, line := range []string{"DOCKER stdout: step 1/2", "DOCKER stderr: step 2/2"} {
+			if !logger.contains(line) {
+				t.Fatalf("log missing %q: %v", line, logger.lines)
+			}
+		}
+	})
+
+	t.Run("push with credentials", func(t *testing.T) {
+		spec := Payload{Operation: OperationImagePush, Image: "ghcr.io/acme/app:1", RegistryAuth: &RegistryAuth{Username: "ci", Password: "s3cret"}}
+		if err := spec.Validate(); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		result, err := Execute(context.Background(), spec, &registry.ExecutionContext{})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if result.Digest != digest || result.ImageID != "sha256:local" {
+			t.Fatalf("Digest = %q, ImageID = %q", result.Digest, result.ImageID)
+		}
+		auth := base64.StdEncoding.EncodeToString([]byte("ci:s3cret"))
+		if want := `{"auths":{"ghcr.io":{"auth":"` + auth + `"}}}`; !strings.Contains(result.Stdout, want) {
+			t.Fatalf("docker config = %q, want %q", result.Stdout, want)
+		}
+		if strings.Contains(strings.Join(result.Command, " "), "s3cret") {
+			t.Fatalf("password on the command line: %v", result.Command)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		trap := filepath.Join(t.TempDir(), "trap")
+		t.Setenv("FAKE_DOCKER_TRAP", trap)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err := Execute(ctx, Payload{Operation: OperationImagePull, Image: "alpine"}, &registry.ExecutionContext{})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Execute() error = %v, want context.DeadlineExceeded", err)
+		}
+		if _, err := os.Stat(trap); err != nil {
+			t.Fatalf("docker was not interrupted: %v", err)
+		}
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		result, err := Execute(context.Background(), Payload{Operation: OperationContainerRun, Image: "alpine"}, &registry.ExecutionContext{})
+		if err == nil || !strings.Contains(err.Error(), "exited with code 3") {
+			t.Fatalf("Execute() error = %v, want exit code 3", err)
+		}
+		if result.ExitCode != 3 || result.Stdout != "partial output\n" {
+			t.Fatalf("result = %+v", result)
+		}
+	})
+}
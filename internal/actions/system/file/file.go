@@ -0,0 +1,500 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"flowk/internal/actions/registry"
+)
+
+const (
+	ActionName = "FILE"
+
+	OperationCopy     = "COPY"
+	OperationMove     = "MOVE"
+	OperationMkdir    = "MKDIR"
+	OperationRemove   = "REMOVE"
+	OperationWrite    = "WRITE"
+	OperationRead     = "READ"
+	OperationTemplate = "TEMPLATE"
+
+	EncodingUTF8   = "utf8"
+	EncodingBase64 = "base64"
+
+	defaultDirPermissions  fs.FileMode = 0o755
+	defaultFilePermissions fs.FileMode = 0o644
+)
+
+// operationFields lists the optional fields each operation accepts besides
+// operation itself.
+var operationFields = map[string][]string{
+	OperationCopy:     {"source", "destination", "overwrite", "recursive"},
+	OperationMove:     {"source", "destination", "overwrite"},
+	OperationMkdir:    {"path", "permissions"},
+	OperationRemove:   {"path", "recursive"},
+	OperationWrite:    {"path", "content", "encoding", "permissions", "overwrite"},
+	OperationRead:     {"path", "encoding"},
+	OperationTemplate: {"source", "template", "destination", "permissions", "overwrite"},
+}
+
+type Payload struct {
+	Operation   string `json:"operation"`
+	Path        string `json:"path"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Content     string `json:"content"`
+	Template    string `json:"template"`
+	Encoding    string `json:"encoding"`
+	Permissions string `json:"permissions"`
+	Overwrite   *bool  `json:"overwrite"`
+	Recursive   bool   `json:"recursive"`
+}
+
+// EncodedData carries file content together with the encoding used to
+// represent it as a string.
+type EncodedData struct {
+	Value    string `json:"value"`
+	Encoding string `json:"encoding"`
+}
+
+type ExecutionResult struct {
+	Operation string       `json:"operation"`
+	Paths     []string     `json:"paths"`
+	Bytes     int64        `json:"bytes,omitempty"`
+	Content   *EncodedData `json:"content,omitempty"`
+}
+
+func (p *Payload) Validate() error {
+	p.Operation = strings.ToUpper(strings.TrimSpace(p.Operation))
+	p.Path = strings.TrimSpace(p.Path)
+	p.Source = strings.TrimSpace(p.Source)
+	p.Destination = strings.TrimSpace(p.Destination)
+	p.Encoding = strings.ToLower(strings.TrimSpace(p.Encoding))
+	p.Permissions = strings.TrimSpace(p.Permissions)
+
+	allowed, ok := operationFields[p.Operation]
+	if !ok {
+		return fmt.Errorf("file task: unsupported operation %q", p.Operation)
+	}
+	for _, field := range p.setFields() {
+		if !containsField(allowed, field) {
+			return fmt.Errorf("file task: %s is not supported by %s operation", field, p.Operation)
+		}
+	}
+
+	switch p.Operation {
+	case OperationCopy, OperationMove:
+		if p.Source == "" || p.Destination == "" {
+			return fmt.Errorf("file task: source and destination are required for %s operation", p.Operation)
+		}
+	case OperationTemplate:
+		if (p.Source == "") == (p.Template == "") {
+			return fmt.Errorf("file task: exactly one of source or template is required for TEMPLATE operation")
+		}
+		if p.Destination == "" {
+			return fmt.Errorf("file task: destination is required for TEMPLATE operation")
+		}
+	default:
+		if p.Path == "" {
+			return fmt.Errorf("file task: path is required for %s operation", p.Operation)
+		}
+	}
+
+	if p.Operation == OperationRemove && isRootPath(p.Path) {
+		return fmt.Errorf("file task: refusing to remove %q", p.Path)
+	}
+
+	switch p.Encoding {
+	case "", EncodingUTF8, EncodingBase64:
+	default:
+		return fmt.Errorf("file task: unsupported encoding %q", p.Encoding)
+	}
+
+	if p.Permissions != "" {
+		if mode, err := strconv.ParseUint(p.Permissions, 8, 32); err != nil || mode > 0o777 {
+			return fmt.Errorf("file task: permissions must be an octal mode such as 0644, got %q", p.Permissions)
+		}
+	}
+
+	return nil
+}
+
+// setFields returns the JSON names of the optional fields present in the payload.
+func (p *Payload) setFields() []string {
+	var fields []string
+	add := func(name string, set bool) {
+		if set {
+			fields = append(fields, name)
+		}
+	}
+	add("path", p.Path != "")
+	add("source", p.Source != "")
+	add("destination", p.Destination != "")
+	add("content", p.Content != "")
+	add("template", p.Template != "")
+	add("encoding", p.Encoding != "")
+	add("permissions", p.Permissions != "")
+	add("overwrite", p.Overwrite != nil)
+	add("recursive", p.Recursive)
+	return fields
+}
+
+func containsField(fields []string, name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isRootPath(path string) bool {
+	cleaned := filepath.Clean(path)
+	return cleaned == "." || cleaned == filepath.VolumeName(cleaned)+string(filepath.Separator)
+}
+
+// fileMode returns the permissions for created files or, for MKDIR,
+// directories.
+func (p Payload) fileMode() fs.FileMode {
+	if mode, err := strconv.ParseUint(p.Permissions, 8, 32); err == nil && mode <= 0o777 {
+		return fs.FileMode(mode)
+	}
+	if p.Operation == OperationMkdir {
+		return defaultDirPermissions
+	}
+	return defaultFilePermissions
+}
+
+func (p Payload) overwrite() bool {
+	return p.Overwrite == nil || *p.Overwrite
+}
+
+func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionContext) (ExecutionResult, error) {
+	result := ExecutionResult{Operation: spec.Operation, Paths: []string{}}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return result, fmt.Errorf("file: operation interrupted: %w", ctxErr)
+	}
+
+	var err error
+	switch spec.Operation {
+	case OperationCopy:
+		result.Paths, err = copyPath(ctx, spec.Source, spec.Destination, spec.overwrite(), spec.Recursive)
+	case OperationMove:
+		result.Paths, err = movePath(ctx, spec.Source, spec.Destination, spec.overwrite())
+	case OperationMkdir:
+		if err = os.MkdirAll(spec.Path, spec.fileMode()); err != nil {
+			err = fmt.Errorf("file: creating directory %s: %w", spec.Path, err)
+		} else {
+			result.Paths = []string{spec.Path}
+		}
+	case OperationRemove:
+		result.Paths, err = removePath(spec.Path, spec.Recursive)
+	case OperationWrite:
+		var data []byte
+		if data, err = decodeContent(spec.Content, spec.Encoding); err == nil {
+			err = writeFile(spec.Path, data, spec.fileMode(), spec.overwrite())
+		}
+		if err == nil {
+			result.Paths = []string{spec.Path}
+			result.Bytes = int64(len(data))
+		}
+	case OperationRead:
+		var data []byte
+		if data, err = os.ReadFile(spec.Path); err != nil {
+			err = fmt.Errorf("file: reading %s: %w", spec.Path, err)
+		} else {
+			result.Paths = []string{spec.Path}
+			result.Bytes = int64(len(data))
+			result.Content = encodeContent(data, spec.Encoding)
+		}
+	case OperationTemplate:
+		var data []byte
+		if data, err = renderTemplate(spec, execCtx); err == nil {
+			err = writeFile(spec.Destination, data, spec.fileMode(), spec.overwrite())
+		}
+		if err == nil {
+			result.Paths = []string{spec.Destination}
+			result.Bytes = int64(len(data))
+		}
+	default:
+		err = fmt.Errorf("file: unsupported operation %q", spec.Operation)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if execCtx != nil && execCtx.Logger != nil {
+		execCtx.Logger.Printf("FILE: %s affected %d path(s)", spec.Operation, len(result.Paths))
+	}
+	return result, nil
+}
+
+func decodeContent(content, encoding string) ([]byte, error) {
+	if encoding != EncodingBase64 {
+		return []byte(content), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+	if err != nil {
+		return nil, fmt.Errorf("file: decoding base64 content: %w", err)
+	}
+	return data, nil
+}
+
+func encodeContent(data []byte, encoding string) *EncodedData {
+	if encoding == EncodingBase64 {
+		return &EncodedData{Value: base64.StdEncoding.EncodeToString(data), Encoding: EncodingBase64}
+	}
+	return &EncodedData{Value: string(data), Encoding: EncodingUTF8}
+}
+
+// writeFile writes data to path, creating missing parent directories.
+func writeFile(path string, data []byte, mode fs.FileMode, overwrite bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), defaultDirPermissions); err != nil {
+		return fmt.Errorf("file: creating parent directory of %s: %w", path, err)
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, mode)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("file: %s already exists and overwrite is false", path)
+		}
+		return fmt.Errorf("file: writing %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("file: writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("file: writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// renderTemplate executes the Go text/template with the current flow
+// variables as data, so {{ .name }} renders the value of variable name.
+func renderTemplate(spec Payload, execCtx *registry.ExecutionContext) ([]byte, error) {
+	name, text := "template", spec.Template
+	if spec.Source != "" {
+		data, err := os.ReadFile(spec.Source)
+		if err != nil {
+			return nil, fmt.Errorf("file: reading template %s: %w", spec.Source, err)
+		}
+		name, text = filepath.Base(spec.Source), string(data)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("file: parsing template: %w", err)
+	}
+
+	vars := make(map[string]any)
+	if execCtx != nil {
+		for name, variable := range execCtx.Variables {
+			vars[name] = variable.Value
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return nil, fmt.Errorf("file: rendering template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// removePath deletes path. A missing path is not an error and yields no
+// affected paths; directories with content require recursive.
+func removePath(path string, recursive bool) ([]string, error) {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file: removing %s: %w", path, err)
+	}
+	if info.IsDir() && recursive {
+		err = os.RemoveAll(path)
+	} else {
+		err = os.Remove(path)
+	}
+	if err != nil {
+		if info.IsDir() && !recursive {
+			return nil, fmt.Errorf("file: removing %s: %w (set recursive to remove a directory with content)", path, err)
+		}
+		return nil, fmt.Errorf("file: removing %s: %w", path, err)
+	}
+	return []string{path}, nil
+}
+
+// copyPath copies a file, or a directory tree when recursive is set, to
+// destination and returns the files written. Directories are merged into an
+// existing destination directory.
+func copyPath(ctx context.Context, source, destination string, overwrite, recursive bool) ([]string, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("file: copying %s: %w", source, err)
+	}
+	if within, err := isWithin(destination, source); err != nil {
+		return nil, fmt.Errorf("file: copying %s: %w", source, err)
+	} else if within {
+		return nil, fmt.Errorf("file: cannot copy %s onto or into itself", source)
+	}
+	if !overwrite {
+		if _, err := os.Lstat(destination); err == nil {
+			return nil, fmt.Errorf("file: %s already exists and overwrite is false", destination)
+		}
+	}
+
+	if !info.IsDir() {
+		if err := copyFile(source, destination, info.Mode().Perm()); err != nil {
+			return nil, err
+		}
+		return []string{destination}, nil
+	}
+
+	if !recursive {
+		return nil, fmt.Errorf("file: %s is a directory; set recursive to copy it", source)
+	}
+
+	var written []string
+	err = filepath.WalkDir(source, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destination, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case entry.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+		default:
+			if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		written = append(written, target)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("file: copying %s: %w", source, err)
+	}
+	sort.Strings(written)
+	if written == nil {
+		written = []string{}
+	}
+	return written, nil
+}
+
+func copyFile(source, destination string, mode fs.FileMode) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("file: copying %s: %w", source, err)
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(destination), defaultDirPermissions); err != nil {
+		return fmt.Errorf("file: creating parent directory of %s: %w", destination, err)
+	}
+	out, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("file: copying to %s: %w", destination, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("file: copying to %s: %w", destination, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("file: copying to %s: %w", destination, err)
+	}
+	return nil
+}
+
+// movePath renames source to destination, replacing an existing destination
+// when overwrite is set. Renames that fail, typically because source and
+// destination are on different file systems, fall back to copy and remove.
+func movePath(ctx context.Context, source, destination string, overwrite bool) ([]string, error) {
+	if _, err := os.Lstat(source); err != nil {
+		return nil, fmt.Errorf("file: moving %s: %w", source, err)
+	}
+	if within, err := isWithin(destination, source); err != nil {
+		return nil, fmt.Errorf("file: moving %s: %w", source, err)
+	} else if within {
+		return nil, fmt.Errorf("file: cannot move %s onto or into itself", source)
+	}
+	if _, err := os.Lstat(destination); err == nil {
+		if !overwrite {
+			return nil, fmt.Errorf("file: %s already exists and overwrite is false", destination)
+		}
+		if err := os.RemoveAll(destination); err != nil {
+			return nil, fmt.Errorf("file: replacing %s: %w", destination, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(destination), defaultDirPermissions); err != nil {
+		return nil, fmt.Errorf("file: creating parent directory of %s: %w", destination, err)
+	}
+
+	renameErr := os.Rename(source, destination)
+	if renameErr == nil {
+		return []string{destination}, nil
+	}
+
+	if _, err := copyPath(ctx, source, destination, true, true); err != nil {
+		_ = os.RemoveAll(destination)
+		return nil, fmt.Errorf("file: moving %s: %w", source, renameErr)
+	}
+	if err := os.RemoveAll(source); err != nil {
+		return nil, fmt.Errorf("file: removing %s after copying it: %w", source, err)
+	}
+	return []string{destination}, nil
+}
+
+// isWithin reports whether path is parent or one of its descendants.
+func isWithin(path, parent string) (bool, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	absParent, err := filepath.Abs(parent)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(absParent, absPath)
+	if err != nil {
+		return false, nil
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))), nil
+}
@@ -0,0 +1,176 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"flowk/internal/actions/registry"
+)
+
+func TestPayloadValidate(t *testing.T) {
+	t.Parallel()
+
+	overwrite := false
+	tests := []struct {
+		name    string
+		payload Payload
+		wantErr string
+	}{
+		{name: "unknown operation", payload: Payload{Operation: "TOUCH", Path: "a"}, wantErr: "unsupported operation"},
+		{name: "copy without destination", payload: Payload{Operation: OperationCopy, Source: "a"}, wantErr: "source and destination are required"},
+		{name: "write without path", payload: Payload{Operation: OperationWrite, Content: "x"}, wantErr: "path is required"},
+		{name: "template without input", payload: Payload{Operation: OperationTemplate, Destination: "out"}, wantErr: "exactly one of source or template"},
+		{name: "template with both inputs", payload: Payload{Operation: OperationTemplate, Source: "in", Template: "x", Destination: "out"}, wantErr: "exactly one of source or template"},
+		{name: "field of another operation", payload: Payload{Operation: OperationRead, Path: "a", Overwrite: &overwrite}, wantErr: "overwrite is not supported by READ"},
+		{name: "bad encoding", payload: Payload{Operation: OperationRead, Path: "a", Encoding: "hex"}, wantErr: "unsupported encoding"},
+		{name: "bad permissions", payload: Payload{Operation: OperationMkdir, Path: "a", Permissions: "rwx"}, wantErr: "octal mode"},
+		{name: "remove root", payload: Payload{Operation: OperationRemove, Path: "/"}, wantErr: "refusing to remove"},
+		{name: "valid move", payload: Payload{Operation: "move", Source: "a", Destination: "b", Overwrite: &overwrite}},
+		{name: "valid template", payload: Payload{Operation: OperationTemplate, Template: "{{ .name }}", Destination: "out", Permissions: "0600"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.payload.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func execute(t *testing.T, payload map[string]any, execCtx *registry.ExecutionContext) (ExecutionResult, error) {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal payload: %v", err)
+	}
+	if execCtx == nil {
+		execCtx = &registry.ExecutionContext{}
+	}
+	res, err := Action{}.Execute(context.Background(), raw, execCtx)
+	if err != nil {
+		return ExecutionResult{}, err
+	}
+	return res.Value.(ExecutionResult), nil
+}
+
+func TestWriteAndReadBase64(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "data.bin")
+
+	written, err := execute(t, map[string]any{"operation": OperationWrite, "path": path, "content": "AAEC/w==", "encoding": "base64", "permissions": "0600"}, nil)
+	if err != nil {
+		t.Fatalf("WRITE error = %v", err)
+	}
+	if written.Bytes != 4 || written.Paths[0] != path {
+		t.Fatalf("unexpected WRITE result: %+v", written)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0o600 && os.PathSeparator == '/' {
+		t.Fatalf("permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	read, err := execute(t, map[string]any{"operation": OperationRead, "path": path, "encoding": "base64"}, nil)
+	if err != nil {
+		t.Fatalf("READ error = %v", err)
+	}
+	if read.Content == nil || read.Content.Value != "AAEC/w==" || read.Content.Encoding != EncodingBase64 {
+		t.Fatalf("unexpected READ content: %+v", read.Content)
+	}
+
+	if _, err := execute(t, map[string]any{"operation": OperationWrite, "path": path, "content": "x", "overwrite": false}, nil); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected overwrite error, got %v", err)
+	}
+}
+
+func TestCopyMoveAndRemoveDirectory(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	for name, content := range map[string]string{"a.txt": "a", filepath.Join("sub", "b.txt"): "b"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	copyDst := filepath.Join(dir, "copy")
+	if _, err := execute(t, map[string]any{"operation": OperationCopy, "source": src, "destination": copyDst}, nil); err == nil || !strings.Contains(err.Error(), "set recursive") {
+		t.Fatalf("expected recursive error, got %v", err)
+	}
+	copied, err := execute(t, map[string]any{"operation": OperationCopy, "source": src, "destination": copyDst, "recursive": true}, nil)
+	if err != nil {
+		t.Fatalf("COPY error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(copyDst, "sub", "b.txt")); err != nil || string(data) != "b" {
+		t.Fatalf("copied file = %q, %v", data, err)
+	}
+	if len(copied.Paths) != 2 {
+		t.Fatalf("unexpected COPY paths: %v", copied.Paths)
+	}
+	if _, err := execute(t, map[string]any{"operation": OperationCopy, "source": src, "destination": filepath.Join(src, "sub", "inner"), "recursive": true}, nil); err == nil || !strings.Contains(err.Error(), "into itself") {
+		t.Fatalf("expected self copy error, got %v", err)
+	}
+
+	moveDst := filepath.Join(dir, "moved", "tree")
+	if _, err := execute(t, map[string]any{"operation": OperationMove, "source": copyDst, "destination": moveDst}, nil); err != nil {
+		t.Fatalf("MOVE error = %v", err)
+	}
+	if _, err := os.Stat(copyDst); !os.IsNotExist(err) {
+		t.Fatalf("source still exists after MOVE: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(moveDst, "a.txt")); err != nil || string(data) != "a" {
+		t.Fatalf("moved file = %q, %v", data, err)
+	}
+
+	if _, err := execute(t, map[string]any{"operation": OperationRemove, "path": moveDst}, nil); err == nil || !strings.Contains(err.Error(), "set recursive") {
+		t.Fatalf("expected recursive error, got %v", err)
+	}
+	removed, err := execute(t, map[string]any{"operation": OperationRemove, "path": moveDst, "recursive": true}, nil)
+	if err != nil || len(removed.Paths) != 1 {
+		t.Fatalf("REMOVE = %+v, %v", removed, err)
+	}
+	removed, err = execute(t, map[string]any{"operation": OperationRemove, "path": moveDst}, nil)
+	if err != nil || len(removed.Paths) != 0 {
+		t.Fatalf("REMOVE of a missing path = %+v, %v", removed, err)
+	}
+}
+
+func TestTemplateRendersVariables(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "app.conf")
+	execCtx := &registry.ExecutionContext{Variables: map[string]registry.Variable{
+		"host":  {Name: "host", Type: "string", Value: "db.internal"},
+		"ports": {Name: "ports", Type: "array", Value: []any{5432.0, 5433.0}},
+	}}
+
+	tmpl := "host={{ .host }}\n{{ range .ports }}port={{ . }}\n{{ end }}"
+	if _, err := execute(t, map[string]any{"operation": OperationTemplate, "template": tmpl, "destination": dst}, execCtx); err != nil {
+		t.Fatalf("TEMPLATE error = %v", err)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "host=db.internal\nport=5432\nport=5433\n"; string(data) != want {
+		t.Fatalf("rendered = %q, want %q", data, want)
+	}
+
+	if _, err := execute(t, map[string]any{"operation": OperationTemplate, "template": "{{ .missing }}", "destination": dst}, execCtx); err == nil || !strings.Contains(err.Error(), "rendering template") {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+}
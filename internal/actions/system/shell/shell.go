@@ -18,22 +18,65 @@ import (
 
 const ActionName = "SHELL"
 
+// shellWaitDelay bounds how long a cancelled command may keep its output
+// open.
+const shellWaitDelay = time.Second
+
 type Payload struct {
 	// Command holds the normalized command text to pass to the shell.
 	Command string `json:"-"`
 	// RawCommand keeps the original JSON form (array of lines) for decoding.
-	RawCommand       json.RawMessage       `json:"command"`
+	RawCommand json.RawMessage `json:"command"`
+	// Inline is a script passed as a single argument to the shell, an
+	// alternative to command.
+	Inline           string                `json:"inline"`
 	Shell            *ShellOptions         `json:"shell"`
 	Environment      []EnvironmentVariable `json:"environment"`
+	Env              map[string]any        `json:"env"`
 	ProxyVariables   []string              `json:"proxyVariables"`
 	WorkingDirectory string                `json:"workingDirectory"`
+	Cwd              string                `json:"cwd"`
+	Stdin            string                `json:"stdin"`
 	TimeoutSeconds   float64               `json:"timeoutSeconds"`
 	ContinueOnError  bool                  `json:"continueOnError"`
+	AllowedExitCodes []int                 `json:"allowedExitCodes"`
+	Capture          *CaptureOptions       `json:"capture"`
+}
+
+// CaptureOptions names the flow variables that receive the command output.
+type CaptureOptions struct {
+	StdoutVar   string `json:"stdout_var"`
+	StderrVar   string `json:"stderr_var"`
+	ExitCodeVar string `json:"exit_code_var"`
+	Trim        bool   `json:"trim"`
 }
 
+// ShellOptions selects the interpreter that runs the command. In JSON it is
+// either an object with program and args or the name of a known shell.
 type ShellOptions struct {
 	Program string   `json:"program"`
 	Args    []string `json:"args"`
+	// Name is the known shell given as a string; Validate resolves it to
+	// Program and Args.
+	Name string `json:"-"`
+}
+
+// Known shell names accepted as a string in the shell field.
+const (
+	ShellSh         = "sh"
+	ShellBash       = "bash"
+	ShellPowerShell = "powershell"
+	ShellCmd        = "cmd"
+)
+
+func (o *ShellOptions) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*o = ShellOptions{Name: name}
+		return nil
+	}
+	type plain ShellOptions
+	return json.Unmarshal(data, (*plain)(o))
 }
 
 type EnvironmentVariable struct {
@@ -61,23 +104,45 @@ type ExecutionResult struct {
 }
 
 func (p *Payload) Validate() error {
-	// Normalize command from raw JSON (array only)
-	if err := p.normalizeCommand(); err != nil {
-		return err
-	}
-	trimmedCommand := strings.TrimSpace(p.Command)
-	if trimmedCommand == "" {
-		return fmt.Errorf("shell task: command is required")
-	}
-	p.Command = trimmedCommand
-
 	if p.Shell != nil {
+		if name := strings.TrimSpace(p.Shell.Name); name != "" {
+			program, args, err := namedShell(name)
+			if err != nil {
+				return err
+			}
+			p.Shell.Program, p.Shell.Args = program, args
+		}
 		p.Shell.Program = strings.TrimSpace(p.Shell.Program)
 		if p.Shell.Program == "" {
 			return fmt.Errorf("shell task: shell.program is required when shell is provided")
 		}
 	}
 
+	// cmd.exe only runs the first line of its /C argument, so command lines
+	// are chained with & for it, which runs them in sequence like a script.
+	cmdShell := isCmdShell(p.program())
+	separator := "\n"
+	if cmdShell {
+		separator = " & "
+	}
+
+	if inline := strings.TrimSpace(p.Inline); inline != "" {
+		if len(p.RawCommand) > 0 {
+			return fmt.Errorf("shell task: command and inline cannot both be set")
+		}
+		if cmdShell && strings.ContainsAny(inline, "\r\n") {
+			return fmt.Errorf("shell task: inline must be a single line for cmd; chain commands with & or &&")
+		}
+		p.Command = inline
+	} else if err := p.normalizeCommand(separator); err != nil {
+		return err
+	}
+	trimmedCommand := strings.TrimSpace(p.Command)
+	if trimmedCommand == "" {
+		return fmt.Errorf("shell task: command or inline is required")
+	}
+	p.Command = trimmedCommand
+
 	for idx := range p.Environment {
 		if err := p.Environment[idx].validate(idx); err != nil {
 			return err
@@ -92,23 +157,49 @@ func (p *Payload) Validate() error {
 		p.ProxyVariables[idx] = trimmed
 	}
 
+	for name := range p.Env {
+		trimmed := strings.TrimSpace(name)
+		if trimmed == "" {
+			return fmt.Errorf("shell task: env: name is required")
+		}
+		if strings.Contains(trimmed, "=") {
+			return fmt.Errorf("shell task: env: name %q cannot contain '='", trimmed)
+		}
+	}
+
 	p.WorkingDirectory = strings.TrimSpace(p.WorkingDirectory)
+	p.Cwd = strings.TrimSpace(p.Cwd)
+	if p.Cwd != "" {
+		if p.WorkingDirectory != "" && p.WorkingDirectory != p.Cwd {
+			return fmt.Errorf("shell task: cwd and workingDirectory cannot both be set")
+		}
+		p.WorkingDirectory = p.Cwd
+	}
 
 	if p.TimeoutSeconds < 0 {
 		return fmt.Errorf("shell task: timeoutSeconds cannot be negative")
 	}
 
+	if p.Capture != nil {
+		p.Capture.StdoutVar = strings.TrimSpace(p.Capture.StdoutVar)
+		p.Capture.StderrVar = strings.TrimSpace(p.Capture.StderrVar)
+		p.Capture.ExitCodeVar = strings.TrimSpace(p.Capture.ExitCodeVar)
+		if p.Capture.StdoutVar == "" && p.Capture.StderrVar == "" && p.Capture.ExitCodeVar == "" {
+			return fmt.Errorf("shell task: capture requires stdout_var, stderr_var or exit_code_var")
+		}
+	}
+
 	return nil
 }
 
 // normalizeCommand parses rawCommand which must be an array of strings
-// and sets Command to the newline-joined script.
-func (p *Payload) normalizeCommand() error {
+// and sets Command to the lines joined with separator.
+func (p *Payload) normalizeCommand(separator string) error {
 	if len(p.RawCommand) == 0 {
 		// Allow tests or programmatic callers to provide Command directly.
 		p.Command = strings.TrimSpace(p.Command)
 		if p.Command == "" {
-			return fmt.Errorf("shell task: command is required")
+			return fmt.Errorf("shell task: command or inline is required")
 		}
 		return nil
 	}
@@ -118,10 +209,19 @@ func (p *Payload) normalizeCommand() error {
 		return fmt.Errorf("shell task: command must be an array of strings")
 	}
 	// Join with newlines so shells like bash -lc execute sequentially
-	p.Command = strings.Join(asArray, "\n")
+	p.Command = strings.Join(asArray, separator)
 	return nil
 }
 
+// program returns the interpreter the command runs with.
+func (p *Payload) program() string {
+	if p.Shell != nil && p.Shell.Program != "" {
+		return p.Shell.Program
+	}
+	program, _ := defaultShell()
+	return program
+}
+
 func (e *EnvironmentVariable) validate(idx int) error {
 	trimmed := strings.TrimSpace(e.Name)
 	if trimmed == "" {
@@ -141,6 +241,21 @@ func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionConte
 
 	builder := newEnvironmentBuilder(os.Environ())
 
+	envNames := make([]string, 0, len(spec.Env))
+	for name := range spec.Env {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		value, err := stringify(spec.Env[name])
+		if err != nil {
+			return ExecutionResult{}, fmt.Errorf("shell task: env %q: %w", name, err)
+		}
+		if err := builder.apply(name, value, false, false); err != nil {
+			return ExecutionResult{}, fmt.Errorf("shell task: env: %w", err)
+		}
+	}
+
 	for idx := range spec.Environment {
 		entry := spec.Environment[idx]
 		value, err := stringify(entry.Value)
@@ -184,6 +299,9 @@ func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionConte
 
 	program, args := resolveShell(spec)
 	command := exec.CommandContext(ctx, program, args...)
+	// Cancelling kills the shell only. Processes it started may keep the
+	// output pipes open, so stop waiting for them shortly after.
+	command.WaitDelay = shellWaitDelay
 	command.Env = envSlice
 	if spec.WorkingDirectory != "" {
 		command.Dir = spec.WorkingDirectory
@@ -192,6 +310,9 @@ func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionConte
 	var stdoutBuf, stderrBuf bytes.Buffer
 	command.Stdout = &stdoutBuf
 	command.Stderr = &stderrBuf
+	if spec.Stdin != "" {
+		command.Stdin = strings.NewReader(spec.Stdin)
+	}
 
 	logCommand(execCtx.Logger, spec, program, args, envSettings)
 
@@ -202,12 +323,12 @@ func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionConte
 	exitCode := 0
 	if runErr != nil {
 		var exitErr *exec.ExitError
-		if errors.As(runErr, &exitErr) {
+		switch {
+		case ctx.Err() != nil:
+			return ExecutionResult{}, fmt.Errorf("shell: command interrupted: %w", ctx.Err())
+		case errors.As(runErr, &exitErr):
 			exitCode = exitErr.ExitCode()
-		} else {
-			if ctxErr := ctx.Err(); ctxErr != nil {
-				return ExecutionResult{}, fmt.Errorf("shell: command interrupted: %w", ctxErr)
-			}
+		default:
 			return ExecutionResult{}, fmt.Errorf("shell: executing command: %w", runErr)
 		}
 	}
@@ -227,13 +348,53 @@ func Execute(ctx context.Context, spec Payload, execCtx *registry.ExecutionConte
 		Environment:      envSettings,
 	}
 
-	if runErr != nil && exitCode != 0 && !spec.ContinueOnError {
+	if runErr != nil && exitCode != 0 && !spec.ContinueOnError && !spec.allowsExit(exitCode) {
 		return result, fmt.Errorf("shell: command exited with code %d", exitCode)
 	}
 
+	if spec.Capture != nil {
+		captureVariables(execCtx, *spec.Capture, result)
+	}
+
 	return result, nil
 }
 
+// captureVariables stores the configured streams and exit code as flow
+// variables so later tasks can reference them with ${name}.
+func captureVariables(execCtx *registry.ExecutionContext, capture CaptureOptions, result ExecutionResult) {
+	if execCtx.Variables == nil {
+		execCtx.Variables = make(map[string]registry.Variable)
+	}
+
+	stdout, stderr := result.Stdout, result.Stderr
+	if capture.Trim {
+		stdout = strings.TrimRight(stdout, "\r\n")
+		stderr = strings.TrimRight(stderr, "\r\n")
+	}
+
+	set := func(name, varType string, value any) {
+		if name == "" {
+			return
+		}
+		execCtx.Variables[name] = registry.Variable{Name: name, Type: varType, Value: value}
+		if execCtx.Logger != nil {
+			execCtx.Logger.Printf("SHELL: captured %s", name)
+		}
+	}
+	set(capture.StdoutVar, "string", stdout)
+	set(capture.StderrVar, "string", stderr)
+	set(capture.ExitCodeVar, "number", float64(result.ExitCode))
+}
+
+func (p Payload) allowsExit(code int) bool {
+	for _, allowed := range p.AllowedExitCodes {
+		if allowed == code {
+			return true
+		}
+	}
+	return false
+}
+
 func resolveShell(spec Payload) (string, []string) {
 	program := ""
 	args := []string{}
@@ -254,16 +415,52 @@ func resolveShell(spec Payload) (string, []string) {
 }
 
 func defaultShell() (string, []string) {
-	switch runtime.GOOS {
-	case "windows":
+	name := ShellSh
+	if runtime.GOOS == "windows" {
+		name = ShellCmd
+	}
+	program, args, _ := namedShell(name)
+	return program, args
+}
+
+// namedShell returns the program and the arguments that make it run the
+// script passed as the next argument, for the current platform.
+func namedShell(name string) (string, []string, error) {
+	windows := runtime.GOOS == "windows"
+	switch strings.ToLower(name) {
+	case ShellSh:
+		if windows {
+			return "sh", []string{"-c"}, nil
+		}
+		return "/bin/sh", []string{"-c"}, nil
+	case ShellBash:
+		return "bash", []string{"-c"}, nil
+	case ShellPowerShell:
+		// Windows PowerShell ships with Windows; elsewhere only PowerShell 7
+		// (pwsh) exists.
+		program := "pwsh"
+		if windows {
+			program = "powershell.exe"
+		}
+		return program, []string{"-NoProfile", "-NonInteractive", "-Command"}, nil
+	case ShellCmd:
 		program := os.Getenv("COMSPEC")
 		if strings.TrimSpace(program) == "" {
 			program = "cmd.exe"
 		}
-		return program, []string{"/C"}
+		return program, []string{"/C"}, nil
 	default:
-		return "/bin/sh", []string{"-c"}
+		return "", nil, fmt.Errorf("shell task: unknown shell %q (use %s, %s, %s or %s, or an object with program and args)", name, ShellSh, ShellBash, ShellPowerShell, ShellCmd)
+	}
+}
+
+// isCmdShell reports whether program is cmd.exe, whichever path it has.
+func isCmdShell(program string) bool {
+	base := strings.ToLower(program)
+	if idx := strings.LastIndexAny(base, `/\`); idx >= 0 {
+		base = base[idx+1:]
 	}
+	return strings.TrimSuffix(base, ".exe") == "cmd"
 }
 
 type environmentBuilder struct {
@@ -2,10 +2,15 @@ package shell
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"flowk/internal/actions/registry"
 )
@@ -115,6 +120,106 @@ func TestExecuteHonorsContinueOnError(t *testing.T) {
 	}
 }
 
+func TestExecuteAppliesEnvMapStdinAndCwd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stdin piping relies on a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	payload := Payload{
+		Command: "printf '%s|%s|' \"$GREETING\" \"$(basename \"$PWD\")\"; cat",
+		Env:     map[string]any{"GREETING": "hola", "COUNT": 3},
+		Cwd:     dir,
+		Stdin:   "from stdin",
+	}
+	if err := payload.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	result, err := Execute(context.Background(), payload, &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "hola|" + filepath.Base(dir) + "|from stdin"
+	if result.Stdout != want {
+		t.Fatalf("unexpected stdout: got %q want %q", result.Stdout, want)
+	}
+	if result.WorkingDirectory != dir {
+		t.Fatalf("expected working directory %q, got %q", dir, result.WorkingDirectory)
+	}
+}
+
+func TestExecuteAllowsConfiguredExitCodes(t *testing.T) {
+	payload := Payload{Command: failingCommand(3), AllowedExitCodes: []int{1, 3}}
+
+	result, err := Execute(context.Background(), payload, &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.ExitCode != 3 {
+		t.Fatalf("expected exit code 3, got %d", result.ExitCode)
+	}
+
+	payload.AllowedExitCodes = []int{1}
+	if _, err := Execute(context.Background(), payload, &registry.ExecutionContext{}); err == nil {
+		t.Fatalf("expected error when exit code is not allowed")
+	}
+}
+
+func TestValidateRejectsConflictingWorkingDirectories(t *testing.T) {
+	payload := Payload{Command: echoCommand("noop"), Cwd: "/tmp/a", WorkingDirectory: "/tmp/b"}
+	if err := payload.Validate(); err == nil {
+		t.Fatalf("expected error when cwd and workingDirectory differ")
+	}
+
+	payload = Payload{Command: echoCommand("noop"), Env: map[string]any{"A=B": "x"}}
+	if err := payload.Validate(); err == nil {
+		t.Fatalf("expected error for env name containing '='")
+	}
+}
+
+func TestExecuteCapturesOutputIntoVariables(t *testing.T) {
+	payload := Payload{
+		Command:          echoCommand("captured") + "\n" + failingCommand(4),
+		AllowedExitCodes: []int{4},
+		Capture:          &CaptureOptions{StdoutVar: " out ", StderrVar: "errs", ExitCodeVar: "code", Trim: true},
+	}
+	if err := payload.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	execCtx := &registry.ExecutionContext{}
+	if _, err := Execute(context.Background(), payload, execCtx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got := execCtx.Variables["out"]; got.Type != "string" || got.Value != "captured" {
+		t.Fatalf("unexpected stdout variable: %+v", got)
+	}
+	if got := execCtx.Variables["errs"]; got.Value != "" {
+		t.Fatalf("unexpected stderr variable: %+v", got)
+	}
+	if got := execCtx.Variables["code"]; got.Type != "number" || got.Value != float64(4) {
+		t.Fatalf("unexpected exit code variable: %+v", got)
+	}
+
+	payload.Capture.Trim = false
+	if _, err := Execute(context.Background(), payload, execCtx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := normalizeNewlines(execCtx.Variables["out"].Value.(string)); got != "captured\n" {
+		t.Fatalf("expected untrimmed stdout, got %q", got)
+	}
+}
+
+func TestValidateRequiresCaptureVariable(t *testing.T) {
+	payload := Payload{Command: echoCommand("noop"), Capture: &CaptureOptions{Trim: true}}
+	if err := payload.Validate(); err == nil {
+		t.Fatalf("expected error when capture names no variable")
+	}
+}
+
 func TestExecuteFailsWithUnknownProxyVariable(t *testing.T) {
 	payload := Payload{Command: echoCommand("noop"), ProxyVariables: []string{"missing"}}
 
@@ -123,6 +228,92 @@ func TestExecuteFailsWithUnknownProxyVariable(t *testing.T) {
 	}
 }
 
+func TestValidateResolvesNamedShellAndInline(t *testing.T) {
+	var payload Payload
+	if err := json.Unmarshal([]byte(`{"shell":"bash","inline":"echo a | tr a b && echo done"}`), &payload); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if err := payload.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if payload.Shell.Program != "bash" || strings.Join(payload.Shell.Args, " ") != "-c" {
+		t.Fatalf("shell = %s %v, want bash -c", payload.Shell.Program, payload.Shell.Args)
+	}
+	if payload.Command != "echo a | tr a b && echo done" {
+		t.Fatalf("Command = %q, want the inline script", payload.Command)
+	}
+
+	cases := map[string]struct {
+		payload string
+		want    string
+	}{
+		"unknown shell":       {`{"shell":"zsh","inline":"true"}`, `unknown shell "zsh"`},
+		"command and inline":  {`{"command":["true"],"inline":"true"}`, "command and inline cannot both be set"},
+		"no command":          {`{"shell":"sh"}`, "command or inline is required"},
+		"multi-line cmd":      {`{"shell":"cmd","inline":"echo a\necho b"}`, "single line for cmd"},
+		"program object kept": {`{"shell":{"args":["-c"]},"inline":"true"}`, "shell.program is required"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var payload Payload
+			if err := json.Unmarshal([]byte(tc.payload), &payload); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if err := payload.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateChainsCommandLinesForCmd(t *testing.T) {
+	payload := Payload{
+		RawCommand: json.RawMessage(`["echo a", "echo b"]`),
+		Shell:      &ShellOptions{Program: `C:\Windows\System32\CMD.EXE`, Args: []string{"/C"}},
+	}
+	if err := payload.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if payload.Command != "echo a & echo b" {
+		t.Fatalf("Command = %q, want the lines chained with &", payload.Command)
+	}
+}
+
+func TestActionRunsInlineScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("inline script uses POSIX shell syntax")
+	}
+	dir := t.TempDir()
+	payload := fmt.Sprintf(`{"shell":"sh","inline":"echo hello | tr h j && pwd && echo \"$GREETING\"","cwd":%q,"env":{"GREETING":"hi"}}`, dir)
+
+	result, err := Action{}.Execute(context.Background(), json.RawMessage(payload), &registry.ExecutionContext{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	execution := result.Value.(ExecutionResult)
+	realDir, _ := filepath.EvalSymlinks(dir)
+	if want := "jello\n" + realDir + "\nhi\n"; execution.Stdout != want {
+		t.Fatalf("stdout = %q, want %q", execution.Stdout, want)
+	}
+	if got := execution.Command; len(got) != 3 || got[0] != "/bin/sh" || got[1] != "-c" {
+		t.Fatalf("command = %v, want /bin/sh -c <script>", got)
+	}
+}
+
+func TestActionInlineScriptHonorsTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("inline script uses POSIX shell syntax")
+	}
+	start := time.Now()
+	_, err := Action{}.Execute(context.Background(), json.RawMessage(`{"inline":"sleep 5","timeoutSeconds":0.2}`), &registry.ExecutionContext{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Execute() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("Execute() took %s, want the timeout to stop the script", elapsed)
+	}
+}
+
 func echoCommand(message string) string {
 	if runtime.GOOS == "windows" {
 		return "echo " + message
@@ -2,41 +2,63 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	_ "flowk/internal/actions/auth/gmail"
 	_ "flowk/internal/actions/auth/oauth2"
+	_ "flowk/internal/actions/core/datetime"
 	"flowk/internal/actions/core/evaluate"
 	_ "flowk/internal/actions/core/forloop"
 	_ "flowk/internal/actions/core/parallel"
+	_ "flowk/internal/actions/core/random"
 	_ "flowk/internal/actions/core/sleep"
+	_ "flowk/internal/actions/core/templaterender"
+	_ "flowk/internal/actions/core/transform"
 	"flowk/internal/actions/core/variables"
 	"flowk/internal/actions/db/cassandra"
 	_ "flowk/internal/actions/db/mysql"
 	_ "flowk/internal/actions/db/postgres"
+	_ "flowk/internal/actions/db/redis"
+	_ "flowk/internal/actions/db/sql"
 	_ "flowk/internal/actions/infra/helm"
 	_ "flowk/internal/actions/infra/kubernetes"
+	_ "flowk/internal/actions/network/http"
 	_ "flowk/internal/actions/network/httpclient"
+	_ "flowk/internal/actions/network/smtp"
 	_ "flowk/internal/actions/network/ssh"
 	_ "flowk/internal/actions/network/telnet"
 	_ "flowk/internal/actions/security/pgp"
 	_ "flowk/internal/actions/storage/gcloudstorage"
+	_ "flowk/internal/actions/system/archive"
 	_ "flowk/internal/actions/system/base64"
+	_ "flowk/internal/actions/system/checksum"
 	_ "flowk/internal/actions/system/docker"
+	_ "flowk/internal/actions/system/file"
 	_ "flowk/internal/actions/system/secretprovidervault"
 	_ "flowk/internal/actions/system/shell"
 	"flowk/internal/flow"
 	"flowk/internal/shared/runcontext"
+	"flowk/internal/shared/tracing"
 )
 
 // Run loads the flow definition and executes the requested actions.
-func Run(ctx context.Context, flowPath string, logger cassandra.Logger, startTaskID, singleTaskID, runFlowID, runSubtaskID string) error {
+func Run(ctx context.Context, flowPath string, logger cassandra.Logger, startTaskID, singleTaskID, runFlowID, runSubtaskID string) (err error) {
 	observer := observerFromContext(ctx)
 
+	ctx, span := tracing.Start(ctx, "flow", attribute.String(spanAttrFlowPath, flowPath))
+	defer func() { tracing.End(span, err) }()
+
 	definition, err := flow.LoadDefinition(flowPath)
+	if err == nil {
+		err = checkVariableReferences(ctx, definition, logger.Printf)
+	}
 	if err != nil {
 		publishEvent(observer, FlowEvent{
 			Type:   FlowEventFlowFinished,
@@ -45,13 +67,35 @@ func Run(ctx context.Context, flowPath string, logger cassandra.Logger, startTas
 		})
 		return err
 	}
+	span.SetName("flow " + definition.ID)
+	span.SetAttributes(attribute.String(spanAttrFlowID, definition.ID))
 
 	publishEvent(observer, FlowEvent{
 		Type:   FlowEventFlowLoaded,
 		FlowID: definition.ID,
 	})
 
+	scope := runcontext.NewRunScope(ctx)
+	defer scope.Close()
+	ctx = runcontext.WithRunScope(ctx, scope)
+
+	var report *junitReport
+	reportPath := junitPathFromContext(ctx)
+	if reportPath != "" {
+		report = newJUnitReport()
+		ctx = withJUnitReport(ctx, report)
+	}
+
 	err = runDefinition(ctx, definition, flowPath, logger, startTaskID, singleTaskID, runFlowID, runSubtaskID, observer)
+	if report != nil {
+		if writeErr := report.write(reportPath, definition); writeErr != nil {
+			if err == nil {
+				err = writeErr
+			} else {
+				logger.Printf("WARNING: %v", writeErr)
+			}
+		}
+	}
 	publishEvent(observer, FlowEvent{
 		Type:   FlowEventFlowFinished,
 		FlowID: definition.ID,
@@ -61,10 +105,15 @@ func Run(ctx context.Context, flowPath string, logger cassandra.Logger, startTas
 	return err
 }
 
-// ValidateFlow loads the flow definition to ensure it is structurally valid.
-func ValidateFlow(flowPath string) error {
-	_, err := flow.LoadDefinition(flowPath)
-	return err
+// ValidateFlow loads the flow definition to ensure it is structurally valid
+// and logs a warning for each variable reference no task declares. With
+// WithStrictVariables those warnings become an error.
+func ValidateFlow(ctx context.Context, flowPath string, logger cassandra.Logger) error {
+	definition, err := flow.LoadDefinition(flowPath)
+	if err != nil {
+		return err
+	}
+	return checkVariableReferences(ctx, definition, logger.Printf)
 }
 
 func errorMessage(err error) string {
@@ -79,11 +128,36 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		return fmt.Errorf("definition is required")
 	}
 
+	if timeout := definition.Timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cancelCleanup := func() {}
+	defer func() { cancelCleanup() }()
+
 	var (
 		allowedFlows     map[string]struct{}
 		firstAllowedTask int = -1
 		err              error
 	)
+	runTaskIDs := runTasksFromContext(ctx)
+	if len(runTaskIDs) > 0 {
+		if strings.TrimSpace(startTaskID) != "" || strings.TrimSpace(singleTaskID) != "" || strings.TrimSpace(runFlowID) != "" || strings.TrimSpace(runSubtaskID) != "" {
+			return fmt.Errorf("run-tasks cannot be combined with begin-from-task, run-task, run-subtask, or run-flow")
+		}
+	}
+	tags, tagsRequested := tagFilterFromContext(ctx)
+	if tagsRequested {
+		if strings.TrimSpace(startTaskID) != "" || strings.TrimSpace(singleTaskID) != "" || len(runTaskIDs) > 0 || strings.TrimSpace(runFlowID) != "" || strings.TrimSpace(runSubtaskID) != "" {
+			return fmt.Errorf("tag cannot be combined with begin-from-task, run-task, run-tasks, run-subtask, or run-flow")
+		}
+	}
+	if definition.Execution == flow.ExecutionDAG {
+		if strings.TrimSpace(startTaskID) != "" || strings.TrimSpace(singleTaskID) != "" || len(runTaskIDs) > 0 || tagsRequested || strings.TrimSpace(runFlowID) != "" {
+			return fmt.Errorf("dag execution cannot be combined with begin-from-task, run-task, run-tasks, tag, or run-flow")
+		}
+	}
 	if trimmed := strings.TrimSpace(runFlowID); trimmed != "" {
 		if strings.TrimSpace(startTaskID) != "" || strings.TrimSpace(singleTaskID) != "" || strings.TrimSpace(runSubtaskID) != "" {
 			return fmt.Errorf("run-flow cannot be combined with begin-from-task, run-task, or run-subtask")
@@ -105,11 +179,26 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 	runCtx := RunContext{
 		Vars: make(map[string]Variable),
 	}
+
+	masker, err := newLogMasker(definition.MaskPatterns)
+	if err != nil {
+		return err
+	}
+	ctx = withLogMasker(ctx, masker)
+	var jsonLog *jsonLogSink
+	if logFormatFromContext(ctx) == LogFormatJSON {
+		jsonLog = &jsonLogSink{}
+		defer jsonLog.Close()
+		logger = &structuredLogger{base: logger, sink: jsonLog, flowID: definition.ID}
+	}
+	logger = &maskingLogger{base: logger, masker: masker, vars: runCtx.Snapshot}
 	runState := RunStateFromContext(ctx)
 	resumeRequested := strings.TrimSpace(startTaskID) != "" ||
 		strings.TrimSpace(singleTaskID) != "" ||
 		strings.TrimSpace(runFlowID) != "" ||
-		strings.TrimSpace(runSubtaskID) != ""
+		strings.TrimSpace(runSubtaskID) != "" ||
+		len(runTaskIDs) > 0 ||
+		tagsRequested
 	isResume := runState != nil && runState.HasData() && resumeRequested
 	if isResume {
 		ctx = runcontext.WithResume(ctx)
@@ -130,10 +219,15 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		}
 	}
 
-	flowLogsDir, err := prepareFlowLogsDir(flowPath, isResume)
+	flowLogsDir, err := prepareFlowLogsDir(logsDirFromContext(ctx), flowPath, isResume)
 	if err != nil {
 		return err
 	}
+	if jsonLog != nil {
+		if err := jsonLog.open(filepath.Join(flowLogsDir, JSONLogFileName), isResume); err != nil {
+			return err
+		}
+	}
 
 	flowDirectories := map[string]string{
 		definition.ID: flowLogsDir,
@@ -190,6 +284,15 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		}
 	}
 
+	successFlowID := strings.TrimSpace(definition.OnSuccessFlow)
+	successStartIdx, successEndIdx := -1, -1
+	if successFlowID != "" {
+		successStartIdx, successEndIdx = findFlowTaskRange(definition.Tasks, successFlowID)
+		if successStartIdx < 0 {
+			return fmt.Errorf("on_success_flow %q not found in flow definition", successFlowID)
+		}
+	}
+
 	finallyFlowID := strings.TrimSpace(definition.FinallyFlow)
 	finallyFlowStartIdx, finallyFlowEndIdx := -1, -1
 	if finallyFlowID != "" {
@@ -212,9 +315,37 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		originalErr               error
 		cleanupScheduled          bool
 		cleanupFlowExplicitlyUsed bool = strings.TrimSpace(runFlowID) == cleanupFlowID
+		successFlowExplicitlyUsed bool = strings.TrimSpace(runFlowID) == successFlowID
 	)
 
-	if trimmed := strings.TrimSpace(singleTaskID); trimmed != "" {
+	var selectedTasks map[int]struct{}
+	if len(runTaskIDs) > 0 {
+		var firstIdx, lastIdx int
+		selectedTasks, firstIdx, lastIdx, err = selectRunTasks(definition.Tasks, runTaskIDs)
+		if err != nil {
+			return err
+		}
+		startIdx = firstIdx
+		endIdx = lastIdx + 1
+		requestedStartIdx = firstIdx
+	} else if tagsRequested {
+		var firstIdx, lastIdx int
+		selectedTasks, firstIdx, lastIdx, err = selectTaggedTasks(definition.Tasks, tags)
+		if err != nil {
+			return err
+		}
+		startIdx = firstIdx
+		endIdx = lastIdx + 1
+		requestedStartIdx = firstIdx
+		logger.Printf("Tag filter %s selected %d of %d tasks", tags, len(selectedTasks), len(definition.Tasks))
+		for idx := range definition.Tasks {
+			task := &definition.Tasks[idx]
+			if _, run := selectedTasks[idx]; run || (idx < lastIdx && strings.EqualFold(task.Action, variables.ActionName)) {
+				continue
+			}
+			logger.Printf("Task %s filtered out by tag filter - Status: %s", task.ID, task.Status)
+		}
+	} else if trimmed := strings.TrimSpace(singleTaskID); trimmed != "" {
 		targetIdx := findTaskIndexByID(definition.Tasks, trimmed)
 		if targetIdx < 0 {
 			return fmt.Errorf("run-task: task id %q not found in flow definition", trimmed)
@@ -295,8 +426,27 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		return nil
 	}
 
+	// Once the flow deadline passes, the remaining on_error_flow and finally
+	// hooks run under a fresh context bounded by flowTimeoutCleanupWindow.
+	timedOut := false
+	startTimeoutCleanup := func(cause error) error {
+		if timedOut {
+			return nil
+		}
+		timeoutErr := flowTimeoutError(ctx, definition, cause)
+		if timeoutErr == nil {
+			return nil
+		}
+		timedOut = true
+		ctx, cancelCleanup = context.WithTimeout(context.WithoutCancel(ctx), flowTimeoutCleanupWindow)
+		return timeoutErr
+	}
+
 	finallyExecuted := false
 	runFinally := func(prevErr error) error {
+		if timeoutErr := startTimeoutCleanup(prevErr); timeoutErr != nil {
+			prevErr = timeoutErr
+		}
 		if finallyExecuted || (finallyFlowStartIdx < 0 && finallyTaskIdx < 0) {
 			return prevErr
 		}
@@ -337,6 +487,29 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		return prevErr
 	}
 
+	runSuccessFlow := func() error {
+		if successStartIdx < 0 || successFlowExplicitlyUsed {
+			return nil
+		}
+		for i := successStartIdx; i <= successEndIdx; i++ {
+			if runcontext.IsStopRequested(ctx) {
+				return nil
+			}
+			if timeoutErr := startTimeoutCleanup(nil); timeoutErr != nil {
+				return timeoutErr
+			}
+			task := &definition.Tasks[i]
+			taskFlowDir, err := resolveFlowDir(task.FlowID)
+			if err != nil {
+				return fmt.Errorf("tasks[%d]: resolving flow directory: %w", i, err)
+			}
+			if _, _, execErr := executeTask(ctx, &runCtx, task, definition.Tasks, logger, taskFlowDir, allocator, observer); execErr != nil {
+				return fmt.Errorf("on_success_flow %q failed: tasks[%d]: %w", successFlowID, i, execErr)
+			}
+		}
+		return nil
+	}
+
 	publishEvent(observer, FlowEvent{
 		Type:   FlowEventFlowStarted,
 		FlowID: definition.ID,
@@ -387,16 +560,54 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 	skipStopAtOnce := stopAtTaskID != "" && stopAtTaskID == strings.TrimSpace(startTaskID)
 
 	stopRequested := false
+	if definition.Execution == flow.ExecutionDAG {
+		var dagErr error
+		stopRequested, dagErr = runDAG(ctx, &runCtx, definition, func(idx int) bool {
+			flowID := definition.Tasks[idx].FlowID
+			return (cleanupFlowID == "" || flowID != cleanupFlowID) &&
+				(successFlowID == "" || flowID != successFlowID) &&
+				(finallyFlowID == "" || flowID != finallyFlowID) &&
+				idx != finallyTaskIdx
+		}, resolveFlowDir, logger, allocator, observer)
+
+		// The linear loop below only runs on_error_flow after a failure.
+		loopStartIdx = endIdx
+		if dagErr != nil {
+			if timeoutErr := startTimeoutCleanup(dagErr); timeoutErr != nil {
+				dagErr = timeoutErr
+			}
+			originalErr = dagErr
+			if cleanupStartIdx >= 0 {
+				cleanupScheduled = true
+				loopStartIdx, endIdx = cleanupStartIdx, cleanupEndIdx+1
+			}
+		}
+	}
 	for idx := loopStartIdx; idx < endIdx; idx++ {
 		if runcontext.IsStopRequested(ctx) {
 			stopRequested = true
 			break
 		}
+		if timeoutErr := startTimeoutCleanup(originalErr); timeoutErr != nil {
+			originalErr = timeoutErr
+			if !cleanupScheduled {
+				if cleanupStartIdx < 0 || cleanupFlowExplicitlyUsed {
+					break
+				}
+				cleanupScheduled = true
+				endIdx = cleanupEndIdx + 1
+				idx = cleanupStartIdx - 1
+				continue
+			}
+		}
 		task := &definition.Tasks[idx]
 
 		if cleanupFlowID != "" && !cleanupScheduled && !cleanupFlowExplicitlyUsed && task.FlowID == cleanupFlowID {
 			continue
 		}
+		if successFlowID != "" && !successFlowExplicitlyUsed && task.FlowID == successFlowID {
+			continue
+		}
 
 		if len(allowedFlows) > 0 {
 			if _, run := allowedFlows[task.FlowID]; !run {
@@ -412,6 +623,11 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		if idx < requestedStartIdx && !strings.EqualFold(task.Action, variables.ActionName) {
 			continue
 		}
+		if selectedTasks != nil && !strings.EqualFold(task.Action, variables.ActionName) {
+			if _, run := selectedTasks[idx]; !run && !(cleanupScheduled && idx >= cleanupStartIdx && idx <= cleanupEndIdx) {
+				continue
+			}
+		}
 
 		taskFlowDir, err := resolveFlowDir(task.FlowID)
 		if err != nil {
@@ -421,6 +637,9 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		actionResult, _, err := executeTask(ctx, &runCtx, task, definition.Tasks, logger, taskFlowDir, allocator, observer)
 		if err != nil {
 			wrappedErr := fmt.Errorf("tasks[%d]: %w", idx, err)
+			if timeoutErr := startTimeoutCleanup(wrappedErr); timeoutErr != nil {
+				wrappedErr = timeoutErr
+			}
 			if originalErr == nil {
 				originalErr = wrappedErr
 			}
@@ -435,7 +654,10 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 			}
 
 			if cleanupScheduled && idx >= cleanupStartIdx && idx <= cleanupEndIdx {
-				return runFinally(fmt.Errorf("on_error_flow %q failed: %v (original error: %w)", cleanupFlowID, err, originalErr))
+				originalErr = fmt.Errorf("on_error_flow %q failed: %v (original error: %w)", cleanupFlowID, err, originalErr)
+			}
+			if timedOut {
+				break
 			}
 
 			return runFinally(originalErr)
@@ -474,6 +696,14 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 		}
 	}
 
+	if !stopRequested && originalErr == nil {
+		if err := runSuccessFlow(); err != nil {
+			logFlowSummary(logger, definition.Tasks)
+			return runFinally(err)
+		}
+		stopRequested = runcontext.IsStopRequested(ctx)
+	}
+
 	logFlowSummary(logger, definition.Tasks)
 
 	if stopRequested {
@@ -487,6 +717,23 @@ func runDefinition(ctx context.Context, definition *flow.Definition, flowPath st
 	return runFinally(nil)
 }
 
+// flowTimeoutCleanupWindow bounds the on_error_flow and finally hooks that
+// still run after the flow-level deadline has expired.
+var flowTimeoutCleanupWindow = time.Minute
+
+// flowTimeoutError reports an expired flow-level deadline. Once the deadline
+// passes no further regular tasks are started.
+func flowTimeoutError(ctx context.Context, definition *flow.Definition, cause error) error {
+	timeout := definition.Timeout()
+	if timeout <= 0 || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil
+	}
+	if cause == nil {
+		cause = ctx.Err()
+	}
+	return fmt.Errorf("flow timed out after %s: %w", timeout, cause)
+}
+
 func findTaskByID(tasks []flow.Task, id string) *flow.Task {
 	trimmedID := strings.TrimSpace(id)
 	if trimmedID == "" {
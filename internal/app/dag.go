@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"flowk/internal/actions/db/cassandra"
+	"flowk/internal/actions/registry"
+	"flowk/internal/flow"
+	"flowk/internal/shared/runcontext"
+)
+
+// dagTaskOutcome is what a task started by runDAG reports back to the
+// scheduler once it finishes.
+type dagTaskOutcome struct {
+	idx    int
+	task   flow.Task
+	base   map[string]Variable
+	vars   map[string]Variable
+	result registry.Result
+	err    error
+}
+
+// runDAG runs the top-level tasks accepted by include for a definition using
+// the dag execution mode. A task starts once every task in its depends_on list
+// completed; ready tasks start in definition order, at most
+// definition.MaxConcurrency at a time.
+//
+// Each task runs against its own copy of the variables and of the task list,
+// so tasks running concurrently never share state. When a task completes, the
+// variables it created or changed are applied to runCtx; concurrent writes to
+// the same variable resolve in completion order.
+//
+// After the first failure no further task starts, and the tasks already
+// running are waited for. The returned flag reports a stop request.
+func runDAG(
+	ctx context.Context,
+	runCtx *RunContext,
+	definition *flow.Definition,
+	include func(idx int) bool,
+	resolveFlowDir func(flowID string) (string, error),
+	logger cassandra.Logger,
+	allocator *taskDirectoryAllocator,
+	observer FlowObserver,
+) (bool, error) {
+	tasks := definition.Tasks
+	indexes := make(map[string]int, len(tasks))
+	for idx := range tasks {
+		indexes[strings.TrimSpace(tasks[idx].ID)] = idx
+	}
+
+	waiting := make(map[int]int)
+	dependents := make(map[int][]int)
+	var ready []int
+	for idx := range tasks {
+		if !include(idx) {
+			continue
+		}
+		for _, dependency := range tasks[idx].DependsOn {
+			dependencyIdx, found := indexes[strings.TrimSpace(dependency)]
+			if !found || !include(dependencyIdx) {
+				return false, fmt.Errorf("tasks[%d]: depends_on %q does not name a task of the main flow", idx, dependency)
+			}
+			waiting[idx]++
+			dependents[dependencyIdx] = append(dependents[dependencyIdx], idx)
+		}
+		if waiting[idx] == 0 {
+			ready = append(ready, idx)
+		}
+	}
+
+	outcomes := make(chan dagTaskOutcome)
+	running := 0
+	halted, stopped := false, false
+	var failure error
+
+	for {
+		for !halted && failure == nil && len(ready) > 0 && ctx.Err() == nil &&
+			(definition.MaxConcurrency <= 0 || running < definition.MaxConcurrency) {
+			idx := ready[0]
+			ready = ready[1:]
+
+			taskFlowDir, err := resolveFlowDir(tasks[idx].FlowID)
+			if err != nil {
+				failure = fmt.Errorf("tasks[%d]: resolving flow directory: %w", idx, err)
+				break
+			}
+
+			base := runCtx.Snapshot()
+			taskRunCtx := &RunContext{}
+			taskRunCtx.Replace(base)
+			taskList := append([]flow.Task(nil), tasks...)
+			task := tasks[idx]
+
+			running++
+			go func() {
+				result, _, err := executeTask(ctx, taskRunCtx, &task, taskList, logger, taskFlowDir, allocator, observer)
+				outcomes <- dagTaskOutcome{idx: idx, task: task, base: base, vars: taskRunCtx.Snapshot(), result: result, err: err}
+			}()
+		}
+
+		if running == 0 {
+			break
+		}
+
+		outcome := <-outcomes
+		running--
+		tasks[outcome.idx] = outcome.task
+		if outcome.err != nil {
+			if failure == nil {
+				failure = fmt.Errorf("tasks[%d]: %w", outcome.idx, outcome.err)
+			}
+			continue
+		}
+
+		mergeDAGVariables(runCtx, outcome.base, outcome.vars)
+
+		if control := outcome.result.Control; control != nil {
+			if target := strings.TrimSpace(control.JumpToTaskID); target != "" && failure == nil {
+				failure = fmt.Errorf("tasks[%d]: jumping to task %q is not supported with dag execution", outcome.idx, target)
+			}
+			if control.Exit {
+				halted = true
+			}
+		}
+		if runcontext.IsStopRequested(ctx) {
+			halted, stopped = true, true
+		}
+
+		for _, dependent := range dependents[outcome.idx] {
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				pos, _ := slices.BinarySearch(ready, dependent)
+				ready = slices.Insert(ready, pos, dependent)
+			}
+		}
+	}
+
+	if failure == nil && !halted && len(ready) > 0 {
+		if err := ctx.Err(); err != nil {
+			failure = fmt.Errorf("tasks[%d]: %w", ready[0], err)
+		}
+	}
+	return stopped, failure
+}
+
+// mergeDAGVariables applies the variables a task created or changed, compared
+// to the base it started from, on top of the current run variables.
+func mergeDAGVariables(runCtx *RunContext, base, vars map[string]Variable) {
+	merged := runCtx.Snapshot()
+	for name, variable := range vars {
+		if previous, exists := base[name]; exists && reflect.DeepEqual(previous, variable) {
+			continue
+		}
+		merged[name] = variable
+	}
+	runCtx.Replace(merged)
+}
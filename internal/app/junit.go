@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"flowk/internal/flow"
+)
+
+type junitPathContextKey struct{}
+
+type junitReportContextKey struct{}
+
+// WithJUnitReport makes Run write a JUnit XML report of the task results to
+// path once the flow finishes, whether it succeeded or not.
+func WithJUnitReport(ctx context.Context, path string) context.Context {
+	if ctx == nil || path == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, junitPathContextKey{}, path)
+}
+
+func junitPathFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	path, _ := ctx.Value(junitPathContextKey{}).(string)
+	return path
+}
+
+func withJUnitReport(ctx context.Context, report *junitReport) context.Context {
+	return context.WithValue(ctx, junitReportContextKey{}, report)
+}
+
+func junitReportFromContext(ctx context.Context) *junitReport {
+	if ctx == nil {
+		return nil
+	}
+	report, _ := ctx.Value(junitReportContextKey{}).(*junitReport)
+	return report
+}
+
+// junitReport collects one test case per executed task, nested PARALLEL and
+// FOR tasks included. Tasks run concurrently under PARALLEL, so it is locked.
+type junitReport struct {
+	mu       sync.Mutex
+	started  time.Time
+	cases    []junitTestCase
+	recorded map[*flow.Task]bool
+}
+
+type junitTestCase struct {
+	flowID  string
+	name    string
+	seconds float64
+	failure string
+	skipped string
+	task    *flow.Task
+}
+
+func newJUnitReport() *junitReport {
+	return &junitReport{started: time.Now(), recorded: make(map[*flow.Task]bool)}
+}
+
+// recordTaskResult adds the outcome of a task that ran. message is the
+// masked error, empty when the task succeeded.
+func (r *junitReport) recordTaskResult(task *flow.Task, message string) {
+	r.add(task, junitTestCase{seconds: task.DurationSeconds, failure: message})
+}
+
+func (r *junitReport) recordTaskSkipped(task *flow.Task, reason string) {
+	r.add(task, junitTestCase{skipped: reason})
+}
+
+func (r *junitReport) add(task *flow.Task, testCase junitTestCase) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	testCase.flowID = task.FlowID
+	testCase.name = task.ID
+	testCase.task = task
+	r.cases = append(r.cases, testCase)
+	r.recorded[task] = true
+}
+
+// junit XML document types.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string         `xml:"name,attr"`
+	Tests     int            `xml:"tests,attr"`
+	Failures  int            `xml:"failures,attr"`
+	Skipped   int            `xml:"skipped,attr"`
+	Time      string         `xml:"time,attr"`
+	Timestamp string         `xml:"timestamp,attr"`
+	Cases     []junitXMLCase `xml:"testcase"`
+	seconds   float64
+}
+
+type junitXMLCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// write saves the report to path. Top-level tasks of definition that never
+// ran, because the flow stopped or started later, are reported as skipped.
+func (r *junitReport) write(path string, definition *flow.Definition) error {
+	r.mu.Lock()
+	cases := append([]junitTestCase(nil), r.cases...)
+	topLevel := make(map[*flow.Task]bool, len(definition.Tasks))
+	for idx := range definition.Tasks {
+		task := &definition.Tasks[idx]
+		topLevel[task] = true
+		if !r.recorded[task] {
+			cases = append(cases, junitTestCase{flowID: task.FlowID, name: task.ID, skipped: "not run", task: task})
+		}
+	}
+	r.mu.Unlock()
+
+	document := junitTestSuites{
+		Name: definition.ID,
+		Time: junitSeconds(time.Since(r.started).Seconds()),
+	}
+	suiteIndex := make(map[string]int)
+	for _, testCase := range cases {
+		flowID := testCase.flowID
+		if flowID == "" {
+			flowID = definition.ID
+		}
+		idx, exists := suiteIndex[flowID]
+		if !exists {
+			idx = len(document.Suites)
+			suiteIndex[flowID] = idx
+			document.Suites = append(document.Suites, junitTestSuite{Name: flowID, Timestamp: r.started.Format(time.RFC3339)})
+		}
+		suite := &document.Suites[idx]
+
+		xmlCase := junitXMLCase{Name: testCase.name, ClassName: flowID, Time: junitSeconds(testCase.seconds)}
+		switch {
+		case testCase.failure != "":
+			xmlCase.Failure = &junitMessage{Message: testCase.failure, Text: testCase.failure}
+			suite.Failures++
+			document.Failures++
+		case testCase.skipped != "":
+			xmlCase.Skipped = &junitMessage{Message: testCase.skipped}
+			suite.Skipped++
+			document.Skipped++
+		}
+		// Nested tasks run inside their PARALLEL or FOR task, whose time
+		// already covers them.
+		if topLevel[testCase.task] {
+			suite.seconds += testCase.seconds
+		}
+		suite.Cases = append(suite.Cases, xmlCase)
+		suite.Tests++
+		document.Tests++
+	}
+	for idx := range document.Suites {
+		document.Suites[idx].Time = junitSeconds(document.Suites[idx].seconds)
+	}
+
+	data, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding JUnit report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating JUnit report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0o644); err != nil {
+		return fmt.Errorf("writing JUnit report: %w", err)
+	}
+	return nil
+}
+
+func junitSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
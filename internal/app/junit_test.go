@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const junitFlow = `{
+  "description": "junit",
+  "id": "junit.flow",
+  "name": "junit.flow",
+  "tasks": [
+    {"action": "FOR", "description": "loop", "id": "loop", "name": "loop", "variable": "item", "values": ["a", "b"],
+     "tasks": [
+       {"action": "PRINT", "description": "print", "id": "loop.print", "name": "loop.print", "entries": [{"message": "${item}"}]}
+     ]},
+    {"action": "PRINT", "description": "skipped", "id": "skipped", "name": "skipped", "entries": [{"message": "never"}],
+     "skip_if": [{"left": "1", "operation": "=", "right": "1"}]},
+    {"action": "SHELL", "description": "fails", "id": "fails", "name": "fails", "command": ["sh", "-c", "exit 3"]},
+    {"action": "PRINT", "description": "after", "id": "after", "name": "after", "entries": [{"message": "after"}]}
+  ]
+}`
+
+func TestRunWritesJUnitReport(t *testing.T) {
+	dir := t.TempDir()
+	flowPath := filepath.Join(dir, "flow.json")
+	if err := os.WriteFile(flowPath, []byte(junitFlow), 0o600); err != nil {
+		t.Fatalf("writing flow: %v", err)
+	}
+	reportPath := filepath.Join(dir, "reports", "junit.xml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = WithJUnitReport(WithLogsDir(ctx, t.TempDir()), reportPath)
+
+	if err := Run(ctx, flowPath, &bufferLogger{}, "", "", "", ""); err == nil {
+		t.Fatal("expected the failing SHELL task to fail the run")
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("reading JUnit report: %v", err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("decoding JUnit report: %v\n%s", err, data)
+	}
+
+	if report.Tests != 6 || report.Failures != 1 || report.Skipped != 2 {
+		t.Fatalf("tests/failures/skipped = %d/%d/%d, want 6/1/2:\n%s", report.Tests, report.Failures, report.Skipped, data)
+	}
+	if len(report.Suites) != 1 || report.Suites[0].Name != "junit.flow" {
+		t.Fatalf("unexpected suites:\n%s", data)
+	}
+
+	var names []string
+	for _, testCase := range report.Suites[0].Cases {
+		if testCase.ClassName != "junit.flow" {
+			t.Fatalf("classname = %q, want the flow ID", testCase.ClassName)
+		}
+		state := "passed"
+		switch {
+		case testCase.Failure != nil:
+			state = "failed"
+		case testCase.Skipped != nil:
+			state = "skipped:" + testCase.Skipped.Message
+		}
+		names = append(names, testCase.Name+"="+state)
+	}
+	want := "loop.print=passed,loop.print=passed,loop=passed,skipped=skipped:skip_if conditions matched,fails=failed,after=skipped:not run"
+	if got := strings.Join(names, ","); got != want {
+		t.Fatalf("test cases = %s, want %s", got, want)
+	}
+}
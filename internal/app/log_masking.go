@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"flowk/internal/actions/db/cassandra"
+	"flowk/internal/flow"
+)
+
+// maskedValue replaces secret values and mask_patterns matches in log output,
+// matching the marker PRINT uses for secret variables.
+const maskedValue = "***"
+
+type logMaskerKey struct{}
+
+// logMasker hides secret variable values and flow-level mask_patterns matches
+// in log lines. Masking only affects logging; actions still receive the real
+// values.
+type logMasker struct {
+	patterns []*regexp.Regexp
+}
+
+func newLogMasker(patterns []string) (*logMasker, error) {
+	masker := &logMasker{}
+	for i, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("mask_patterns[%d]: %w", i, err)
+		}
+		masker.patterns = append(masker.patterns, compiled)
+	}
+	return masker, nil
+}
+
+func withLogMasker(ctx context.Context, masker *logMasker) context.Context {
+	if masker == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, logMaskerKey{}, masker)
+}
+
+func logMaskerFromContext(ctx context.Context) *logMasker {
+	if ctx == nil {
+		return nil
+	}
+	masker, _ := ctx.Value(logMaskerKey{}).(*logMasker)
+	return masker
+}
+
+// mask replaces the values of secret variables and every mask pattern match
+// in message.
+func (m *logMasker) mask(message string, vars map[string]Variable) string {
+	if m == nil || message == "" {
+		return message
+	}
+
+	for _, secret := range secretValues(vars) {
+		message = strings.ReplaceAll(message, secret, maskedValue)
+	}
+	for _, pattern := range m.patterns {
+		message = pattern.ReplaceAllString(message, maskedValue)
+	}
+	return message
+}
+
+// secretValues returns the rendered values of secret variables, longest first
+// so that overlapping secrets are masked completely.
+func secretValues(vars map[string]Variable) []string {
+	var values []string
+	for _, variable := range vars {
+		if !variable.Secret || variable.Value == nil {
+			continue
+		}
+
+		var rendered string
+		switch v := variable.Value.(type) {
+		case string:
+			rendered = v
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				rendered = fmt.Sprintf("%v", v)
+			} else {
+				rendered = string(data)
+			}
+		}
+		if rendered != "" {
+			values = append(values, rendered)
+		}
+		if _, ok := variable.Value.(string); !ok {
+			values = appendSecretLeaves(values, variable.Value)
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return values
+}
+
+// appendSecretLeaves adds the strings nested in an object or array secret, so
+// a field that reaches the output on its own is masked too.
+func appendSecretLeaves(values []string, value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			values = append(values, v)
+		}
+	case map[string]any:
+		for _, item := range v {
+			values = appendSecretLeaves(values, item)
+		}
+	case []any:
+		for _, item := range v {
+			values = appendSecretLeaves(values, item)
+		}
+	}
+	return values
+}
+
+// maskResult returns a copy of result with mask applied to every string, so
+// task artifacts never persist secrets. The in-memory result is left intact for
+// later from.task references.
+func maskResult(result any, mask func(string) string) any {
+	if result == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return result
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return result
+	}
+	return maskStrings(decoded, mask)
+}
+
+func maskStrings(value any, mask func(string) string) any {
+	switch v := value.(type) {
+	case string:
+		return mask(v)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = maskStrings(item, mask)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = maskStrings(item, mask)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// maskingLogger applies a logMasker to every line written to the base logger.
+type maskingLogger struct {
+	base   cassandra.Logger
+	masker *logMasker
+	vars   func() map[string]Variable
+}
+
+func (l *maskingLogger) Printf(format string, args ...interface{}) {
+	l.base.Printf("%s", l.masker.mask(fmt.Sprintf(format, args...), l.vars()))
+}
+
+func (l *maskingLogger) printTask(task *flow.Task, plain, colored string) {
+	vars := l.vars()
+	printTaskLine(l.base, task, l.masker.mask(plain, vars), l.masker.mask(colored, vars))
+}
@@ -20,6 +20,8 @@ const (
 )
 
 type FlowEvent struct {
+	// ID is assigned by the UI event hub when the event is published.
+	ID        uint64        `json:"id,omitempty"`
 	Type      FlowEventType `json:"type"`
 	Timestamp time.Time     `json:"timestamp"`
 	FlowID    string        `json:"flowId"`
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"flowk/internal/flow"
+)
+
+type runTasksContextKey struct{}
+
+// WithRunTasks makes Run execute only the tasks with the given identifiers,
+// in the order they appear in the flow definition. VARIABLES tasks that
+// precede a selected task still run so the selected tasks see their values.
+func WithRunTasks(ctx context.Context, taskIDs []string) context.Context {
+	if ctx == nil || len(taskIDs) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, runTasksContextKey{}, append([]string(nil), taskIDs...))
+}
+
+func runTasksFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	taskIDs, _ := ctx.Value(runTasksContextKey{}).([]string)
+	return taskIDs
+}
+
+// selectRunTasks resolves the requested task identifiers to their indexes in
+// tasks and returns them as a set along with the lowest and highest index.
+func selectRunTasks(tasks []flow.Task, taskIDs []string) (map[int]struct{}, int, int, error) {
+	selected := make(map[int]struct{}, len(taskIDs))
+	first, last := -1, -1
+	for _, id := range taskIDs {
+		trimmed := strings.TrimSpace(id)
+		idx := findTaskIndexByID(tasks, trimmed)
+		if idx < 0 {
+			return nil, -1, -1, fmt.Errorf("run-tasks: task id %q not found in flow definition", trimmed)
+		}
+		selected[idx] = struct{}{}
+		if first < 0 || idx < first {
+			first = idx
+		}
+		if idx > last {
+			last = idx
+		}
+	}
+	return selected, first, last, nil
+}
+
+// Tag match modes accepted by WithTagFilter. Any runs the tasks carrying at
+// least one of the requested tags; all runs the tasks carrying every one.
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+type tagFilterContextKey struct{}
+
+type tagFilter struct {
+	tags  []string
+	match string
+}
+
+// WithTagFilter makes Run execute only the tasks whose tags match the filter.
+// Like WithRunTasks, VARIABLES tasks that precede a selected task still run.
+func WithTagFilter(ctx context.Context, tags []string, match string) context.Context {
+	if ctx == nil || len(tags) == 0 {
+		return ctx
+	}
+	if match == "" {
+		match = TagMatchAny
+	}
+	return context.WithValue(ctx, tagFilterContextKey{}, tagFilter{tags: append([]string(nil), tags...), match: match})
+}
+
+func tagFilterFromContext(ctx context.Context) (tagFilter, bool) {
+	if ctx == nil {
+		return tagFilter{}, false
+	}
+	filter, ok := ctx.Value(tagFilterContextKey{}).(tagFilter)
+	return filter, ok
+}
+
+// ParseTagMatch normalises a -tag-match value, treating "" as any.
+func ParseTagMatch(value string) (string, error) {
+	switch match := strings.ToLower(strings.TrimSpace(value)); match {
+	case "", TagMatchAny:
+		return TagMatchAny, nil
+	case TagMatchAll:
+		return TagMatchAll, nil
+	default:
+		return "", fmt.Errorf("unsupported tag match %q (expected %s or %s)", value, TagMatchAny, TagMatchAll)
+	}
+}
+
+func (f tagFilter) String() string {
+	return fmt.Sprintf("%s (match %s)", strings.Join(f.tags, ", "), f.match)
+}
+
+func (f tagFilter) matches(task *flow.Task) bool {
+	for _, tag := range f.tags {
+		carried := slices.Contains(task.Labels, tag)
+		if carried && f.match == TagMatchAny {
+			return true
+		}
+		if !carried && f.match == TagMatchAll {
+			return false
+		}
+	}
+	return f.match == TagMatchAll
+}
+
+// selectTaggedTasks returns the indexes of the tasks matching filter along
+// with the lowest and highest one. A filter that matches nothing is an error.
+func selectTaggedTasks(tasks []flow.Task, filter tagFilter) (map[int]struct{}, int, int, error) {
+	selected := make(map[int]struct{})
+	first, last := -1, -1
+	for idx := range tasks {
+		if !filter.matches(&tasks[idx]) {
+			continue
+		}
+		selected[idx] = struct{}{}
+		if first < 0 {
+			first = idx
+		}
+		last = idx
+	}
+	if len(selected) == 0 {
+		return nil, -1, -1, fmt.Errorf("tag filter %s matched no tasks in flow definition", filter)
+	}
+	return selected, first, last, nil
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"flowk/internal/actions/db/cassandra"
+	"flowk/internal/flow"
+)
+
+// Log formats accepted by WithLogFormat. Text keeps the console output only;
+// JSON also writes every log line to JSONLogFileName in the flow logs dir.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// JSONLogFileName is the file, inside logs/<flow>, that receives one JSON
+// object per log line when the JSON log format is enabled.
+const JSONLogFileName = "flow_log.jsonl"
+
+type logFormatContextKey struct{}
+
+// WithLogFormat selects the log format of Run. Unknown formats are rejected
+// by ParseLogFormat before they reach the context.
+func WithLogFormat(ctx context.Context, format string) context.Context {
+	if ctx == nil || format == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, logFormatContextKey{}, format)
+}
+
+func logFormatFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if format, ok := ctx.Value(logFormatContextKey{}).(string); ok {
+			return format
+		}
+	}
+	return LogFormatText
+}
+
+// ParseLogFormat normalises a -log-format value, treating "" as text.
+func ParseLogFormat(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case "", LogFormatText:
+		return LogFormatText, nil
+	case LogFormatJSON:
+		return LogFormatJSON, nil
+	default:
+		return "", fmt.Errorf("log format %q is not supported (use %q or %q)", value, LogFormatText, LogFormatJSON)
+	}
+}
+
+// LogEntry is one line of the JSON log.
+type LogEntry struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	FlowID    string `json:"flowId,omitempty"`
+	TaskID    string `json:"taskId,omitempty"`
+	Message   string `json:"message"`
+}
+
+// taskLineLogger is implemented by the loggers that keep track of the task a
+// line belongs to. Task loggers hand them the plain and colored forms of each
+// line so the console keeps its colors and structured sinks stay readable.
+type taskLineLogger interface {
+	printTask(task *flow.Task, plain, colored string)
+}
+
+// printTaskLine writes a line on behalf of task, which may be nil for lines
+// that belong to the flow as a whole.
+func printTaskLine(logger cassandra.Logger, task *flow.Task, plain, colored string) {
+	if logger == nil {
+		return
+	}
+	if attributed, ok := logger.(taskLineLogger); ok {
+		attributed.printTask(task, plain, colored)
+		return
+	}
+	logger.Printf("%s", colored)
+}
+
+// structuredLogger forwards every line to the console logger and records it,
+// with its flow and task, in a jsonLogSink.
+type structuredLogger struct {
+	base   cassandra.Logger
+	sink   *jsonLogSink
+	flowID string
+}
+
+func (l *structuredLogger) Printf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.printTask(nil, message, message)
+}
+
+func (l *structuredLogger) printTask(task *flow.Task, plain, colored string) {
+	if l.base != nil {
+		l.base.Printf("%s", colored)
+	}
+
+	entry := LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		FlowID:    l.flowID,
+		Message:   ansiEscapePattern.ReplaceAllString(plain, ""),
+	}
+	if task != nil {
+		entry.FlowID = task.FlowID
+		entry.TaskID = task.ID
+	}
+	entry.Level = logLevel(entry.Message)
+	l.sink.write(entry)
+}
+
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// logLevel derives a level from the conventions used by the engine: lines
+// starting with WARNING are warnings and failed tasks report "with ERRORS".
+func logLevel(message string) string {
+	switch {
+	case strings.HasPrefix(message, "WARNING"):
+		return "warn"
+	case strings.HasPrefix(message, "ERROR"), strings.Contains(message, "executed with ERRORS"):
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// jsonLogSink appends LogEntry lines to a file. Entries written before open
+// are kept in memory and flushed once the file exists.
+type jsonLogSink struct {
+	mu      sync.Mutex
+	file    *os.File
+	pending []LogEntry
+	closed  bool
+	err     error
+}
+
+func (s *jsonLogSink) open(path string, resume bool) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening JSON log: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.file = file
+	for _, entry := range s.pending {
+		s.writeLocked(entry)
+	}
+	s.pending = nil
+	return s.err
+}
+
+func (s *jsonLogSink) write(entry LogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.file == nil {
+		s.pending = append(s.pending, entry)
+		return
+	}
+	s.writeLocked(entry)
+}
+
+func (s *jsonLogSink) writeLocked(entry LogEntry) {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = s.file.Write(append(data, '\n'))
+	}
+	if err != nil && s.err == nil {
+		s.err = fmt.Errorf("writing JSON log: %w", err)
+	}
+}
+
+// Close closes the file and reports the first write error.
+func (s *jsonLogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.file == nil {
+		return s.err
+	}
+	if err := s.file.Close(); err != nil && s.err == nil {
+		s.err = fmt.Errorf("closing JSON log: %w", err)
+	}
+	s.file = nil
+	return s.err
+}
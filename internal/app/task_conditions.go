@@ -0,0 +1,81 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"flowk/internal/actions/core/evaluate"
+	"flowk/internal/flow"
+	expansion "flowk/internal/shared/expansion"
+)
+
+// taskSkipReason evaluates the task's skip_if and run_if conditions against the
+// current variables and prior task results. It returns a non-empty reason when
+// the task must not run. skip_if takes precedence: a matching skip_if skips the
+// task even when run_if also matches.
+func taskSkipReason(runCtx *RunContext, task *flow.Task, tasks []flow.Task, warnf expansion.WarnFunc) (string, error) {
+	skipIf, err := decodeTaskConditions(task.SkipIf)
+	if err != nil {
+		return "", fmt.Errorf("decoding skip_if: %w", err)
+	}
+	runIf, err := decodeTaskConditions(task.RunIf)
+	if err != nil {
+		return "", fmt.Errorf("decoding run_if: %w", err)
+	}
+	if len(skipIf) == 0 && len(runIf) == 0 {
+		return "", nil
+	}
+
+	vars := runCtx.Snapshot()
+	resolve := func(value any) (any, error) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding operand: %w", err)
+		}
+		expanded, err := expansion.ExpandTaskPayload(data, vars, tasks, warnf)
+		if err != nil {
+			return nil, err
+		}
+		var resolved any
+		if err := json.Unmarshal(expanded, &resolved); err != nil {
+			return nil, fmt.Errorf("decoding operand: %w", err)
+		}
+		return resolved, nil
+	}
+
+	if len(skipIf) > 0 {
+		matches, err := evaluate.Matches(skipIf, resolve)
+		if err != nil {
+			return "", fmt.Errorf("skip_if: %w", err)
+		}
+		if matches {
+			return "skip_if conditions matched", nil
+		}
+	}
+
+	if len(runIf) > 0 {
+		matches, err := evaluate.Matches(runIf, resolve)
+		if err != nil {
+			return "", fmt.Errorf("run_if: %w", err)
+		}
+		if !matches {
+			return "run_if conditions not met", nil
+		}
+	}
+
+	return "", nil
+}
+
+func decodeTaskConditions(raw json.RawMessage) ([]evaluate.Condition, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+
+	var conditions []evaluate.Condition
+	if err := json.Unmarshal(trimmed, &conditions); err != nil {
+		return nil, err
+	}
+	return conditions, nil
+}
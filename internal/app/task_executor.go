@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 	"flowk/internal/actions/core/parallel"
 	"flowk/internal/actions/core/print"
 	"flowk/internal/actions/core/variables"
+	"flowk/internal/actions/core/waituntil"
 	"flowk/internal/actions/db/cassandra"
 	"flowk/internal/actions/registry"
 	"flowk/internal/flow"
@@ -70,10 +72,46 @@ func executeTask(
 	if task == nil {
 		return registry.Result{}, "", fmt.Errorf("executeTask: task is required")
 	}
+
+	ctx, span := startTaskSpan(ctx, task)
+	result, taskDir, err := executeTracedTask(ctx, runCtx, task, tasks, logger, parentDir, allocator, observer)
+	endTaskSpan(span, task, err)
+	if report := junitReportFromContext(ctx); report != nil && (err != nil || task.Status != flow.TaskStatusNotStarted) {
+		message := errorMessage(err)
+		if masker := logMaskerFromContext(ctx); masker != nil && message != "" {
+			message = masker.mask(message, runCtx.Snapshot())
+		}
+		report.recordTaskResult(task, message)
+	}
+	return result, taskDir, err
+}
+
+// executeTracedTask runs task inside the span opened by executeTask.
+func executeTracedTask(
+	ctx context.Context,
+	runCtx *RunContext,
+	task *flow.Task,
+	tasks []flow.Task,
+	logger cassandra.Logger,
+	parentDir string,
+	allocator *taskDirectoryAllocator,
+	observer FlowObserver,
+) (registry.Result, string, error) {
 	if allocator == nil {
 		return registry.Result{}, "", fmt.Errorf("executeTask: directory allocator is required")
 	}
 
+	skipReason, conditionErr := taskSkipReason(runCtx, task, tasks, logger.Printf)
+	if conditionErr == nil && skipReason != "" {
+		task.Status = flow.TaskStatusNotStarted
+		message := fmt.Sprintf("[[ Skipping flow: %s task: %s ]] %s", task.FlowID, task.ID, skipReason)
+		printTaskLine(logger, task, message, message)
+		if report := junitReportFromContext(ctx); report != nil {
+			report.recordTaskSkipped(task, skipReason)
+		}
+		return registry.Result{}, "", nil
+	}
+
 	taskDir, err := allocator.allocate(parentDir, task.ID)
 	if err != nil {
 		return registry.Result{}, "", err
@@ -89,6 +127,11 @@ func executeTask(
 	})
 
 	taskLogger := newTaskLogger(logger, observer, task)
+	if masker := logMaskerFromContext(ctx); masker != nil {
+		taskLogger.mask = func(message string) string {
+			return masker.mask(message, runCtx.Snapshot())
+		}
+	}
 	taskLogPrefix := fmt.Sprintf("flow: %s task: %s", task.FlowID, task.ID)
 
 	expandedDescription := task.Description
@@ -106,6 +149,10 @@ func executeTask(
 	startColored := fmt.Sprintf("%s[[ Executing %s ]]%s %s", colors.BrightWhite, taskLogPrefix, colors.Reset, expandedDescription)
 	taskLogger.PrintColored(startPlain, startColored)
 
+	if conditionErr != nil {
+		return finalizeTask(ctx, task, taskLogger, taskLogPrefix, taskDir, runCtx.Snapshot(), fmt.Errorf("evaluating task conditions: %w", conditionErr), observer)
+	}
+
 	var (
 		expandedPayload json.RawMessage = task.Payload
 		actionResult    registry.Result
@@ -115,7 +162,7 @@ func executeTask(
 
 	switch {
 	case strings.EqualFold(task.Action, evaluate.ActionName):
-		expandedPayload, execErr = expansion.ExpandEvaluateTaskPayload(task.Payload, runCtx.Snapshot(), tasks)
+		expandedPayload, execErr = expansion.ExpandEvaluateTaskPayload(task.Payload, runCtx.Snapshot(), tasks, taskLogger.Printf)
 	case strings.EqualFold(task.Action, print.ActionName):
 	// PRINT tasks handle interpolation at execution time.
 	case strings.EqualFold(task.Action, variables.ActionName):
@@ -123,9 +170,11 @@ func executeTask(
 	case strings.EqualFold(task.Action, forloop.ActionName):
 	// FOR tasks manage variable evaluation within nested executions.
 	case strings.EqualFold(task.Action, parallel.ActionName):
-		expandedPayload, execErr = expansion.ExpandParallelTaskPayload(task.Payload, runCtx.Snapshot(), tasks)
+		expandedPayload, execErr = expansion.ExpandParallelTaskPayload(task.Payload, runCtx.Snapshot(), tasks, taskLogger.Printf)
+	case strings.EqualFold(task.Action, waituntil.ActionName):
+		expandedPayload, execErr = expansion.ExpandWaitUntilTaskPayload(task.Payload, runCtx.Snapshot(), tasks, taskLogger.Printf)
 	default:
-		expandedPayload, execErr = expansion.ExpandTaskPayload(task.Payload, runCtx.Snapshot(), tasks)
+		expandedPayload, execErr = expansion.ExpandTaskPayload(task.Payload, runCtx.Snapshot(), tasks, taskLogger.Printf)
 	}
 
 	if execErr != nil {
@@ -139,43 +188,75 @@ func executeTask(
 		return finalizeTask(ctx, task, taskLogger, taskLogPrefix, taskDir, runCtx.Snapshot(), execErr, observer)
 	}
 
-	execCtx := runCtx.ExecutionContext(task, tasks, taskLogger)
-	execCtx.LogDir = taskDir
-	execCtx.ExecuteTask = func(childCtx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
-		if req.Task == nil {
-			return registry.TaskExecutionResponse{}, fmt.Errorf("executeTask: nested task is required")
+	runAttempt := func() (registry.Result, *registry.ExecutionContext, error) {
+		actionCtx, cancelAction := withTaskTimeout(ctx, task)
+		defer cancelAction()
+
+		execCtx := runCtx.ExecutionContext(task, tasks, taskLogger)
+		execCtx.Mask = taskLogger.mask
+		execCtx.LogDir = taskDir
+		execCtx.ExecuteTask = func(childCtx context.Context, req registry.TaskExecutionRequest) (registry.TaskExecutionResponse, error) {
+			if req.Task == nil {
+				return registry.TaskExecutionResponse{}, fmt.Errorf("executeTask: nested task is required")
+			}
+
+			childRunCtx := &RunContext{}
+			if len(req.Variables) == 0 {
+				childRunCtx.Replace(runCtx.Snapshot())
+			} else {
+				childRunCtx.Replace(registryVariablesToRun(req.Variables))
+			}
+
+			nestedTasks := req.Tasks
+			if len(nestedTasks) == 0 {
+				nestedTasks = tasks
+			}
+
+			nestedParent := strings.TrimSpace(req.LogDir)
+			if nestedParent == "" {
+				nestedParent = taskDir
+			}
+
+			nestedResult, _, nestedErr := executeTask(childCtx, childRunCtx, req.Task, nestedTasks, logger, nestedParent, allocator, observer)
+			if nestedErr != nil {
+				return registry.TaskExecutionResponse{}, nestedErr
+			}
+
+			return registry.TaskExecutionResponse{
+				Result:    nestedResult,
+				Variables: runVariablesToRegistry(childRunCtx.Snapshot()),
+			}, nil
 		}
 
-		childRunCtx := &RunContext{}
-		if len(req.Variables) == 0 {
-			childRunCtx.Replace(runCtx.Snapshot())
-		} else {
-			childRunCtx.Replace(registryVariablesToRun(req.Variables))
-		}
+		result, err := actionImpl.Execute(actionCtx, expandedPayload, execCtx)
+		return result, execCtx, taskTimeoutError(ctx, actionCtx, task, err)
+	}
 
-		nestedTasks := req.Tasks
-		if len(nestedTasks) == 0 {
-			nestedTasks = tasks
+	var execCtx *registry.ExecutionContext
+	attempts := task.Retry.Attempts()
+	for attempt := 1; ; attempt++ {
+		actionResult, execCtx, execErr = runAttempt()
+		if execErr == nil {
+			break
 		}
-
-		nestedParent := strings.TrimSpace(req.LogDir)
-		if nestedParent == "" {
-			nestedParent = taskDir
+		if attempts > 1 {
+			taskLogger.Printf("attempt %d/%d failed: %v", attempt, attempts, execErr)
 		}
-
-		nestedResult, _, nestedErr := executeTask(childCtx, childRunCtx, req.Task, nestedTasks, logger, nestedParent, allocator, observer)
-		if nestedErr != nil {
-			return registry.TaskExecutionResponse{}, nestedErr
+		if attempt >= attempts || ctx.Err() != nil {
+			break
+		}
+		if err := waitForRetry(ctx, task.Retry.Delay(attempt)); err != nil {
+			break
 		}
-
-		return registry.TaskExecutionResponse{
-			Result:    nestedResult,
-			Variables: runVariablesToRegistry(childRunCtx.Snapshot()),
-		}, nil
 	}
-
-	actionResult, execErr = actionImpl.Execute(ctx, expandedPayload, execCtx)
 	if execErr != nil {
+		if execCtx != nil && strings.EqualFold(task.Action, parallel.ActionName) {
+			// PARALLEL reports failed subtasks alongside the merged state of
+			// the ones that completed, so keep both for the task artifacts.
+			runCtx.UpdateFromExecutionContext(execCtx)
+			task.Result = actionResult.Value
+			task.ResultType = actionResult.Type
+		}
 		return finalizeTask(ctx, task, taskLogger, taskLogPrefix, taskDir, runCtx.Snapshot(), execErr, observer)
 	}
 
@@ -190,7 +271,7 @@ func executeTask(
 
 	resultType = actionResult.Type
 
-	if err := writeTaskArtifacts(taskDir, task, taskLogger.Logs(), runCtx.Snapshot(), ""); err != nil {
+	if err := writeTaskArtifacts(taskDir, task, taskLogger.Logs(), runCtx.Snapshot(), "", taskLogger.mask); err != nil {
 		execErr = fmt.Errorf("writing task artifacts: %w", err)
 		return finalizeTask(ctx, task, taskLogger, taskLogPrefix, taskDir, runCtx.Snapshot(), execErr, observer)
 	}
@@ -215,6 +296,43 @@ func executeTask(
 	return actionResult, taskDir, nil
 }
 
+// waitForRetry pauses before the next attempt, returning early when the
+// context is cancelled.
+func waitForRetry(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withTaskTimeout derives the context handed to the action, bounded by the
+// task timeout when one is configured.
+func withTaskTimeout(ctx context.Context, task *flow.Task) (context.Context, context.CancelFunc) {
+	timeout := task.Timeout()
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// taskTimeoutError reports a task timeout when the task deadline, rather than
+// the parent context, interrupted the action.
+func taskTimeoutError(parent, actionCtx context.Context, task *flow.Task, err error) error {
+	if err == nil || parent.Err() != nil || !errors.Is(actionCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("task timed out after %s: %w", task.Timeout(), err)
+}
+
 func finalizeTask(ctx context.Context, task *flow.Task, taskLogger *taskLogger, prefix, taskDir string, vars map[string]Variable, err error, observer FlowObserver) (registry.Result, string, error) {
 	task.EndTimestamp = time.Now()
 	task.DurationSeconds = task.EndTimestamp.Sub(task.StartTimestamp).Seconds()
@@ -227,7 +345,7 @@ func finalizeTask(ctx context.Context, task *flow.Task, taskLogger *taskLogger,
 	failurePlain := fmt.Sprintf("[[ %s executed with ERRORS ]]", prefix)
 	failureColored := fmt.Sprintf("%s[[ %s executed with ERRORS ]]%s", colors.Red, prefix, colors.Reset)
 
-	if writeErr := writeTaskArtifacts(taskDir, task, taskLogger.Logs(), vars, errorMessage(err)); writeErr != nil {
+	if writeErr := writeTaskArtifacts(taskDir, task, taskLogger.Logs(), vars, errorMessage(err), taskLogger.mask); writeErr != nil {
 		taskLogger.PrintColored(failurePlain, failureColored)
 		return registry.Result{}, taskDir, fmt.Errorf("writing task artifacts: %v (original error: %w)", writeErr, err)
 	}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -20,6 +21,8 @@ type taskLogger struct {
 	logs     []string
 	observer FlowObserver
 	task     *flow.Task
+	// mask hides secrets in each line before it is logged, stored or published.
+	mask func(string) string
 }
 
 func newTaskLogger(base cassandra.Logger, observer FlowObserver, task *flow.Task) *taskLogger {
@@ -44,15 +47,44 @@ func (l *taskLogger) Logs() []string {
 	return copied
 }
 
-func prepareFlowLogsDir(flowPath string, resume bool) (string, error) {
+// LogsDir is the directory, relative to the working directory, that holds
+// one logs/<flow>/task-NNNN-<id> tree per flow.
+const LogsDir = "logs"
+
+// FlowLogsDirName returns the name of the directory below LogsDir that
+// holds the logs of the flow stored at flowPath.
+func FlowLogsDirName(flowPath string) string {
 	flowFile := filepath.Base(flowPath)
 	flowName := strings.TrimSuffix(flowFile, filepath.Ext(flowFile))
 	flowName = sanitizeForDirectory(flowName)
 	if flowName == "" {
 		flowName = "flow"
 	}
+	return flowName
+}
+
+type logsDirContextKey struct{}
+
+// WithLogsDir makes Run write task logs below dir instead of LogsDir.
+func WithLogsDir(ctx context.Context, dir string) context.Context {
+	if ctx == nil || strings.TrimSpace(dir) == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, logsDirContextKey{}, strings.TrimSpace(dir))
+}
+
+func logsDirFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if dir, ok := ctx.Value(logsDirContextKey{}).(string); ok && dir != "" {
+			return dir
+		}
+	}
+	return LogsDir
+}
+
+func prepareFlowLogsDir(root, flowPath string, resume bool) (string, error) {
+	flowName := FlowLogsDirName(flowPath)
 
-	root := "logs"
 	if err := os.MkdirAll(root, 0o755); err != nil {
 		return "", fmt.Errorf("creating logs root directory: %w", err)
 	}
@@ -98,11 +130,13 @@ func (l *taskLogger) logMessage(plain, colored string) {
 	if colored == "" {
 		colored = plain
 	}
-
-	if l.base != nil {
-		l.base.Printf("%s", colored)
+	if l.mask != nil {
+		plain = l.mask(plain)
+		colored = l.mask(colored)
 	}
 
+	printTaskLine(l.base, l.task, plain, colored)
+
 	l.mu.Lock()
 	l.logs = append(l.logs, plain)
 	l.mu.Unlock()
@@ -117,7 +151,7 @@ func (l *taskLogger) logMessage(plain, colored string) {
 	}
 }
 
-func writeTaskArtifacts(dir string, task *flow.Task, logs []string, vars map[string]Variable, errMessage string) error {
+func writeTaskArtifacts(dir string, task *flow.Task, logs []string, vars map[string]Variable, errMessage string, mask func(string) string) error {
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("ensuring task directory %q: %w", dir, err)
 	}
@@ -141,6 +175,14 @@ func writeTaskArtifacts(dir string, task *flow.Task, logs []string, vars map[str
 		payload.Variables = resMap
 	}
 
+	if mask != nil {
+		payload.Result = maskResult(payload.Result, mask)
+		payload.Error = mask(payload.Error)
+		if resMap, ok := payload.Result.(map[string]any); ok && payload.Variables != nil {
+			payload.Variables = resMap
+		}
+	}
+
 	data, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshalling task log: %w", err)
@@ -151,6 +193,12 @@ func writeTaskArtifacts(dir string, task *flow.Task, logs []string, vars map[str
 	}
 
 	snapshot := snapshotVariables(vars)
+	if mask != nil {
+		for name, entry := range snapshot {
+			entry.Value = maskResult(entry.Value, mask)
+			snapshot[name] = entry
+		}
+	}
 	snapshotData, err := json.MarshalIndent(snapshot, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshalling variables snapshot: %w", err)
@@ -194,14 +242,14 @@ func snapshotVariables(vars map[string]Variable) map[string]variableSnapshot {
 	for name, variable := range vars {
 		maskedVar := variable
 		if maskedVar.Secret {
-			maskedVar.Value = "<secret>"
+			maskedVar.Value = maskedValue
 		}
 		masked[name] = maskedVar
 	}
 
 	snapshot := make(map[string]variableSnapshot, len(vars))
 	for name, variable := range vars {
-		value := any("<secret>")
+		value := any(maskedValue)
 		if !variable.Secret {
 			expanded, err := expansion.ExpandValue(variable.Value, masked)
 			if err != nil {
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"flowk/internal/flow"
+	"flowk/internal/shared/tracing"
+)
+
+// Span attribute keys recorded for flows and tasks.
+const (
+	spanAttrFlowID       = "flowk.flow.id"
+	spanAttrFlowPath     = "flowk.flow.path"
+	spanAttrTaskID       = "flowk.task.id"
+	spanAttrTaskAction   = "flowk.task.action"
+	spanAttrTaskStatus   = "flowk.task.status"
+	spanAttrTaskSuccess  = "flowk.task.success"
+	spanAttrTaskDuration = "flowk.task.duration_seconds"
+)
+
+// startTaskSpan opens the span of a task below the flow span, or below the
+// PARALLEL/FOR task span for nested tasks.
+func startTaskSpan(ctx context.Context, task *flow.Task) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "task "+task.ID,
+		attribute.String(spanAttrFlowID, task.FlowID),
+		attribute.String(spanAttrTaskID, task.ID),
+		attribute.String(spanAttrTaskAction, task.Action),
+	)
+}
+
+// endTaskSpan records how the task finished and ends its span.
+func endTaskSpan(span trace.Span, task *flow.Task, err error) {
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String(spanAttrTaskStatus, string(task.Status)),
+			attribute.Bool(spanAttrTaskSuccess, task.Success),
+			attribute.Float64(spanAttrTaskDuration, task.DurationSeconds),
+		)
+	}
+	tracing.End(span, err)
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"flowk/internal/actions/core/datetime"
+	"flowk/internal/actions/core/forloop"
+	"flowk/internal/actions/core/parallel"
+	"flowk/internal/actions/core/random"
+	"flowk/internal/actions/core/variables"
+	"flowk/internal/actions/system/shell"
+	"flowk/internal/flow"
+	"flowk/internal/shared/expansion"
+)
+
+type strictVariablesContextKey struct{}
+
+// WithStrictVariables makes Run and ValidateFlow fail when the flow references
+// variables that no task declares, instead of only logging warnings.
+func WithStrictVariables(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, strictVariablesContextKey{}, true)
+}
+
+func strictVariablesFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	strict, _ := ctx.Value(strictVariablesContextKey{}).(bool)
+	return strict
+}
+
+// UndeclaredVariable is a ${name} reference that no task in the flow declares.
+type UndeclaredVariable struct {
+	Name   string
+	FlowID string
+	TaskID string
+}
+
+func (u UndeclaredVariable) String() string {
+	return fmt.Sprintf("flow %q task %q references undeclared variable ${%s}", u.FlowID, u.TaskID, u.Name)
+}
+
+// LintVariables lists the ${name} references in task descriptions and
+// payloads, nested tasks included, that can never be satisfied. A name counts
+// as declared when any task defines it: VARIABLES entries, FOR loop variables
+// (plus key and value for values_from loops), SHELL capture variables and
+// DATETIME and RANDOM variables.
+// Execution order is ignored, so a warning always points at a name that is
+// missing everywhere. from.task and secret references are skipped, as are
+// ${name:-default} placeholders, which fall back to their default.
+func LintVariables(definition *flow.Definition) []UndeclaredVariable {
+	if definition == nil {
+		return nil
+	}
+
+	var tasks []lintTask
+	for _, task := range definition.Tasks {
+		var payload map[string]any
+		if err := json.Unmarshal(task.Payload, &payload); err != nil {
+			continue
+		}
+		tasks = collectLintTasks(tasks, task.FlowID, payload, "")
+	}
+
+	declared := make(map[string]struct{})
+	for _, task := range tasks {
+		for _, name := range declaredVariables(task.payload) {
+			declared[name] = struct{}{}
+			declared[task.namespace+name] = struct{}{}
+		}
+	}
+
+	var warnings []UndeclaredVariable
+	for _, task := range tasks {
+		seen := make(map[string]struct{})
+		for _, name := range referencedVariables(task.payload) {
+			if _, ok := declared[name]; ok {
+				continue
+			}
+			if _, dup := seen[name]; dup {
+				continue
+			}
+			seen[name] = struct{}{}
+			warnings = append(warnings, UndeclaredVariable{Name: name, FlowID: task.flowID, TaskID: task.id})
+		}
+	}
+	return warnings
+}
+
+// checkVariableReferences logs a warning for each undeclared variable and, in
+// strict mode, turns them into an error.
+func checkVariableReferences(ctx context.Context, definition *flow.Definition, logf func(format string, args ...any)) error {
+	warnings := LintVariables(definition)
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	if strictVariablesFromContext(ctx) {
+		messages := make([]string, len(warnings))
+		for i, warning := range warnings {
+			messages[i] = warning.String()
+		}
+		return fmt.Errorf("undeclared variables: %s", strings.Join(messages, "; "))
+	}
+
+	if logf != nil {
+		for _, warning := range warnings {
+			logf("WARNING: %s", warning)
+		}
+	}
+	return nil
+}
+
+type lintTask struct {
+	id      string
+	flowID  string
+	payload map[string]any
+	// namespace prefixes the variables the task declares once a PARALLEL
+	// with namespace_by_subtask merges them, e.g. "build.".
+	namespace string
+}
+
+// collectLintTasks appends the task and, depth first, the tasks nested in its
+// "tasks" array. The nested tasks are removed from the parent payload so each
+// reference is attributed to the innermost task.
+func collectLintTasks(dst []lintTask, flowID string, payload map[string]any, namespace string) []lintTask {
+	id, _ := payload["id"].(string)
+	own := make(map[string]any, len(payload))
+	for key, value := range payload {
+		own[key] = value
+	}
+
+	var nested []map[string]any
+	if items, ok := payload["tasks"].([]any); ok {
+		for _, item := range items {
+			if child, ok := item.(map[string]any); ok {
+				nested = append(nested, child)
+			}
+		}
+		if len(nested) == len(items) {
+			delete(own, "tasks")
+		} else {
+			nested = nil
+		}
+	}
+
+	dst = append(dst, lintTask{id: strings.TrimSpace(id), flowID: flowID, payload: own, namespace: namespace})
+
+	action, _ := payload["action"].(string)
+	strategy, _ := payload["merge_strategy"].(string)
+	namespaced := strings.EqualFold(strings.TrimSpace(action), parallel.ActionName) &&
+		strings.EqualFold(strings.TrimSpace(strategy), parallel.MergeStrategyNamespaceBySubtask)
+	for _, child := range nested {
+		childNamespace := namespace
+		if childID, _ := child["id"].(string); namespaced && strings.TrimSpace(childID) != "" {
+			childNamespace += strings.TrimSpace(childID) + "."
+		}
+		dst = collectLintTasks(dst, flowID, child, childNamespace)
+	}
+	return dst
+}
+
+func declaredVariables(payload map[string]any) []string {
+	action, _ := payload["action"].(string)
+	var names []string
+	add := func(value any) {
+		if name, ok := value.(string); ok && strings.TrimSpace(name) != "" {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(action)) {
+	case variables.ActionName:
+		entries, _ := payload["vars"].([]any)
+		for _, entry := range entries {
+			if variable, ok := entry.(map[string]any); ok {
+				add(variable["name"])
+			}
+		}
+	case forloop.ActionName:
+		add(payload["variable"])
+		if _, ok := payload["values_from"]; ok {
+			names = append(names, "key", "value")
+		}
+	case shell.ActionName:
+		if capture, ok := payload["capture"].(map[string]any); ok {
+			add(capture["stdout_var"])
+			add(capture["stderr_var"])
+			add(capture["exit_code_var"])
+		}
+	case datetime.ActionName, random.ActionName:
+		add(payload["variable"])
+	}
+	return names
+}
+
+// referencedVariables returns the variable names referenced by every string
+// in the payload, sorted so warnings are stable.
+func referencedVariables(payload map[string]any) []string {
+	var names []string
+	var walk func(value any)
+	walk = func(value any) {
+		switch typed := value.(type) {
+		case map[string]any:
+			for _, item := range typed {
+				walk(item)
+			}
+		case []any:
+			for _, item := range typed {
+				walk(item)
+			}
+		case string:
+			names = append(names, expansion.VariableReferences(typed)...)
+		}
+	}
+	walk(payload)
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"flowk/internal/flow"
+)
+
+const undeclaredVariablesFlow = `{
+  "description": "lint",
+  "id": "lint.flow",
+  "name": "lint.flow",
+  "tasks": [
+    {"action": "VARIABLES", "description": "seed", "id": "seed", "name": "seed", "scope": "flow", "overwrite": true,
+     "vars": [{"name": "k8s_namespace", "type": "string", "value": "default"}]},
+    {"action": "SHELL", "description": "list in ${k8s_namespace}", "id": "list", "name": "list",
+     "command": ["echo", "${k8_namspace}"], "capture": {"stdout_var": "listing"}},
+    {"action": "DATETIME", "description": "stamp", "id": "stamp", "name": "stamp", "operation": "NOW", "variable": "stamp"},
+    {"action": "RANDOM", "description": "suffix", "id": "suffix", "name": "suffix", "operation": "HEX", "bytes": 3, "variable": "suffix"},
+    {"action": "PARALLEL", "description": "fan out", "id": "fan", "name": "fan", "merge_strategy": "namespace_by_subtask",
+     "tasks": [
+       {"action": "RANDOM", "description": "build token", "id": "build", "name": "build", "operation": "HEX", "variable": "token"}
+     ]},
+    {"action": "FOR", "description": "loop", "id": "loop", "name": "loop", "variable": "item", "values": ["a", "b"],
+     "tasks": [
+       {"action": "PRINT", "description": "print", "id": "loop.print", "name": "loop.print",
+        "entries": [{"message": "${item} ${listing} ${stamp} ${suffix} ${region:-eu} ${from.task:list.result} ${build.token} ${deploy.token} ${typo_in_loop}"}]}
+     ]}
+  ]
+}`
+
+func TestLintVariablesReportsUndeclaredReferences(t *testing.T) {
+	dir := t.TempDir()
+	flowPath := filepath.Join(dir, "flow.json")
+	if err := os.WriteFile(flowPath, []byte(undeclaredVariablesFlow), 0o600); err != nil {
+		t.Fatalf("writing flow: %v", err)
+	}
+
+	definition, err := flow.LoadDefinition(flowPath)
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+
+	warnings := LintVariables(definition)
+	want := []UndeclaredVariable{
+		{Name: "k8_namspace", FlowID: "lint.flow", TaskID: "list"},
+		{Name: "deploy.token", FlowID: "lint.flow", TaskID: "loop.print"},
+		{Name: "typo_in_loop", FlowID: "lint.flow", TaskID: "loop.print"},
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("LintVariables() = %v, want %v", warnings, want)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Fatalf("LintVariables()[%d] = %v, want %v", i, warnings[i], want[i])
+		}
+	}
+
+	logger := &bufferLogger{}
+	if err := ValidateFlow(context.Background(), flowPath, logger); err != nil {
+		t.Fatalf("ValidateFlow() error = %v", err)
+	}
+	if !strings.Contains(logger.String(), `WARNING: flow "lint.flow" task "list" references undeclared variable ${k8_namspace}`) {
+		t.Fatalf("expected warning in log, got %q", logger.String())
+	}
+
+	err = ValidateFlow(WithStrictVariables(context.Background()), flowPath, &bufferLogger{})
+	if err == nil || !strings.Contains(err.Error(), "${typo_in_loop}") {
+		t.Fatalf("ValidateFlow() strict error = %v, want undeclared variables error", err)
+	}
+}
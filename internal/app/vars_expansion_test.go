@@ -23,7 +23,7 @@ func TestExpandEvaluateTaskPayloadPreservesConditions(t *testing.T) {
 		"other":   {Name: "other", Value: "mundo"},
 	}
 
-	expanded, err := expansion.ExpandEvaluateTaskPayload(raw, vars, nil)
+	expanded, err := expansion.ExpandEvaluateTaskPayload(raw, vars, nil, nil)
 	if err != nil {
 		t.Fatalf("expandEvaluateTaskPayload() error = %v", err)
 	}
@@ -85,7 +85,7 @@ func TestExpandTaskPayloadExpandsSkipTables(t *testing.T) {
 		},
 	}
 
-	expanded, err := expansion.ExpandTaskPayload(raw, vars, nil)
+	expanded, err := expansion.ExpandTaskPayload(raw, vars, nil, nil)
 	if err != nil {
 		t.Fatalf("expandTaskPayload() error = %v", err)
 	}
@@ -64,6 +64,24 @@ func (e LookupError) Error() string {
 	return fmt.Sprintf("unknown action %q", e.name)
 }
 
+// OperationError reports a missing or unknown operation when building an
+// example for an action.
+type OperationError struct {
+	Action     string
+	Operation  string
+	Operations []string
+}
+
+func (e OperationError) Error() string {
+	if len(e.Operations) == 0 {
+		return fmt.Sprintf("action %s does not declare operations", e.Action)
+	}
+	if e.Operation == "" {
+		return fmt.Sprintf("action %s requires an operation (one of: %s)", e.Action, strings.Join(e.Operations, ", "))
+	}
+	return fmt.Sprintf("unknown operation %q for action %s (one of: %s)", e.Operation, e.Action, strings.Join(e.Operations, ", "))
+}
+
 type schemaDocument struct {
 	Definitions map[string]schemaDefinition `json:"definitions"`
 }
@@ -72,6 +90,7 @@ type schemaDefinition struct {
 	Properties map[string]json.RawMessage `json:"properties"`
 	Required   []string                   `json:"required"`
 	AllOf      []schemaConditional        `json:"allOf"`
+	OneOf      []schemaDefinition         `json:"oneOf"`
 }
 
 type schemaConditional struct {
@@ -94,6 +113,7 @@ type fieldSummary struct {
 
 type conditionalRequirementGroup struct {
 	Title            string
+	Operation        string
 	Required         []fieldSummary
 	Note             string
 	ExampleOverrides map[string]any
@@ -225,6 +245,50 @@ func Build(actionName string) (string, error) {
 	return formatActionHelp(summary), nil
 }
 
+// BuildExample returns the example task for the action as standalone,
+// pretty-printed JSON. Actions with operations need the operation whose
+// variant should be emitted; other actions must be called without one.
+func BuildExample(actionName, operation string) (string, error) {
+	summary, err := loadActionSchemaSummary(actionName)
+	if err != nil {
+		return "", err
+	}
+
+	operation = strings.TrimSpace(operation)
+	var example string
+	if len(summary.ConditionalGroups) == 0 {
+		if operation != "" {
+			return "", OperationError{Action: summary.ActionName, Operation: operation}
+		}
+		example = buildActionExample(summary)
+	} else {
+		group, err := findOperationGroup(summary, operation)
+		if err != nil {
+			return "", err
+		}
+		example = buildConditionalExample(summary, group)
+	}
+
+	if example == "" || !json.Valid([]byte(example)) {
+		return "", fmt.Errorf("action %s: could not build a valid JSON example", summary.ActionName)
+	}
+	return example, nil
+}
+
+func findOperationGroup(summary actionSchemaSummary, operation string) (conditionalRequirementGroup, error) {
+	operations := make([]string, 0, len(summary.ConditionalGroups))
+	for _, group := range summary.ConditionalGroups {
+		if group.Operation == "" {
+			continue
+		}
+		if operation != "" && strings.EqualFold(group.Operation, operation) {
+			return group, nil
+		}
+		operations = append(operations, group.Operation)
+	}
+	return conditionalRequirementGroup{}, OperationError{Action: summary.ActionName, Operation: operation, Operations: operations}
+}
+
 func loadActionSchemaSummary(actionName string) (actionSchemaSummary, error) {
 	trimmed := strings.TrimSpace(actionName)
 	if trimmed == "" {
@@ -833,6 +897,7 @@ func buildConditionalRequirementGroups(actionName string, def *schemaDefinition,
 
 		group := conditionalRequirementGroup{
 			Title:            fmt.Sprintf("operation = %q", op),
+			Operation:        op,
 			Required:         buildFieldSummaries(requiredNames, properties),
 			Note:             note,
 			ExampleOverrides: overrides,
@@ -927,6 +992,14 @@ func (a *conditionalAccumulator) collect(def *schemaDefinition, values map[strin
 		}
 	}
 
+	// Only the first alternative of a oneOf is documented so the help keeps
+	// showing a single, complete example for the operation.
+	if len(def.OneOf) > 0 {
+		if err := a.collect(&def.OneOf[0], values); err != nil {
+			return err
+		}
+	}
+
 	for _, cond := range def.AllOf {
 		if cond.Then == nil {
 			continue
@@ -1165,6 +1238,61 @@ func kubernetesExampleOverrides(actionName, operation string) map[string]any {
 			"max_wait_seconds":      "<max-wait-seconds>",
 			"poll_interval_seconds": "<poll-interval-seconds>",
 		}
+	case "APPLY":
+		return map[string]any{
+			"id":          "apply-manifest-task",
+			"description": "Apply a manifest",
+		}
+	case "EXEC":
+		return map[string]any{
+			"id":          "exec-in-pod-task",
+			"description": "Run a command in a pod",
+			"command":     []any{"<command>", "<arg>"},
+		}
+	case "DELETE":
+		return map[string]any{
+			"id":          "delete-resources-task",
+			"description": "Delete resources",
+			"namespace":   "<namespace>",
+			"kind":        "<kind>",
+		}
+	case "ROLLOUT_RESTART":
+		return map[string]any{
+			"id":          "rollout-restart-task",
+			"description": "Restart a deployment rollout",
+			"namespace":   "<namespace>",
+			"names":       "<deployment-names>",
+		}
+	case "WAIT_FOR_JOB":
+		return map[string]any{
+			"id":                    "wait-for-job-task",
+			"description":           "Wait for a job to complete",
+			"namespace":             "<namespace>",
+			"names":                 "<job-name>",
+			"max_wait_seconds":      "<max-wait-seconds>",
+			"poll_interval_seconds": "<poll-interval-seconds>",
+		}
+	case "GET_CONFIGMAP":
+		return map[string]any{
+			"id":          "get-configmap-task",
+			"description": "Read a configmap",
+			"namespace":   "<namespace>",
+			"names":       "<configmap-name>",
+		}
+	case "GET_SECRET":
+		return map[string]any{
+			"id":          "get-secret-task",
+			"description": "Read a secret",
+			"namespace":   "<namespace>",
+			"names":       "<secret-name>",
+		}
+	case "SET_IMAGE":
+		return map[string]any{
+			"id":          "set-image-task",
+			"description": "Update a deployment image",
+			"namespace":   "<namespace>",
+			"deployments": "<deployment-name>",
+		}
 	case "":
 		return map[string]any{
 			"id":          "generic-k8s-task",
@@ -1194,7 +1322,7 @@ func mergeExampleOverrides(base map[string]any, overrides map[string]any) map[st
 }
 
 func Usage(program string) string {
-	return fmt.Sprintf("Usage:\n  %s help action [action_name]\n\nLists every available action or displays the fields required to configure the specified action.", program)
+	return fmt.Sprintf("Usage:\n  %[1]s help action [action_name] [-json]\n  %[1]s help action <action_name> -example [-operation <OP>]\n  %[1]s help action -all [-format markdown|json]\n\nLists every available action or displays the fields required to configure the specified action.\nWith -json, prints the action's fields, operations and allowed values as JSON.\nWith -example, prints only the example task as JSON; -operation selects the variant for actions with operations.\nWith -all (or %[1]s help actions), prints the complete guide: the primer plus every registered action.", program)
 }
 
 func Index(program string) string {
@@ -1,26 +1,40 @@
 package actionhelp
 
 import (
+	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 
 	_ "flowk/internal/actions/auth/oauth2"
+	_ "flowk/internal/actions/core/datetime"
 	_ "flowk/internal/actions/core/evaluate"
 	_ "flowk/internal/actions/core/forloop"
 	_ "flowk/internal/actions/core/parallel"
 	_ "flowk/internal/actions/core/print"
+	_ "flowk/internal/actions/core/random"
 	_ "flowk/internal/actions/core/sleep"
+	_ "flowk/internal/actions/core/templaterender"
+	_ "flowk/internal/actions/core/transform"
 	_ "flowk/internal/actions/core/variables"
+	_ "flowk/internal/actions/core/waituntil"
 	_ "flowk/internal/actions/db/cassandra"
 	_ "flowk/internal/actions/db/postgres"
+	_ "flowk/internal/actions/db/redis"
+	_ "flowk/internal/actions/db/sql"
 	_ "flowk/internal/actions/infra/helm"
 	_ "flowk/internal/actions/infra/kubernetes"
+	_ "flowk/internal/actions/network/http"
 	_ "flowk/internal/actions/network/httpclient"
+	_ "flowk/internal/actions/network/smtp"
 	_ "flowk/internal/actions/network/ssh"
 	_ "flowk/internal/actions/network/telnet"
 	"flowk/internal/actions/registry"
 	_ "flowk/internal/actions/storage/gcloudstorage"
+	_ "flowk/internal/actions/system/archive"
 	_ "flowk/internal/actions/system/base64"
+	_ "flowk/internal/actions/system/checksum"
+	_ "flowk/internal/actions/system/file"
 	_ "flowk/internal/actions/system/shell"
 )
 
@@ -96,7 +110,16 @@ func TestBuildForKubernetesIncludesConditionalSections(t *testing.T) {
 		"5) operation = \"GET_DEPLOYMENTS\"",
 		"6) operation = \"GET_LOGS\"",
 		"7) operation = \"WAIT_FOR_POD_READINESS\"",
-		"8) operation = any other value (default case)",
+		"8) operation = \"APPLY\"",
+		"9) operation = \"DELETE\"",
+		"10) operation = \"EXEC\"",
+		"11) operation = \"ROLLOUT_RESTART\"",
+		"12) operation = \"WAIT_FOR_JOB\"",
+		"13) operation = \"GET_EVENTS\"",
+		"14) operation = \"GET_CONFIGMAP\"",
+		"15) operation = \"GET_SECRET\"",
+		"16) operation = \"SET_IMAGE\"",
+		"17) operation = any other value (default case)",
 		"Examples:\n\n1) operation = \"PORT_FORWARD\"",
 		"2) operation = \"STOP_PORT_FORWARD\"",
 		"3) operation = \"SCALE\"",
@@ -104,7 +127,16 @@ func TestBuildForKubernetesIncludesConditionalSections(t *testing.T) {
 		"5) operation = \"GET_DEPLOYMENTS\"",
 		"6) operation = \"GET_LOGS\"",
 		"7) operation = \"WAIT_FOR_POD_READINESS\"",
-		"8) operation = any other value (default case)",
+		"8) operation = \"APPLY\"",
+		"9) operation = \"DELETE\"",
+		"10) operation = \"EXEC\"",
+		"11) operation = \"ROLLOUT_RESTART\"",
+		"12) operation = \"WAIT_FOR_JOB\"",
+		"13) operation = \"GET_EVENTS\"",
+		"14) operation = \"GET_CONFIGMAP\"",
+		"15) operation = \"GET_SECRET\"",
+		"16) operation = \"SET_IMAGE\"",
+		"17) operation = any other value (default case)",
 	}
 
 	for _, section := range requiredSections {
@@ -113,8 +145,8 @@ func TestBuildForKubernetesIncludesConditionalSections(t *testing.T) {
 		}
 	}
 
-	if !strings.Contains(help, "(note: \"context\" is NOT required)") {
-		t.Fatalf("expected help output to mention context is not required in STOP_PORT_FORWARD case: %s", help)
+	if !strings.Contains(help, "defaults.kubernetes.context") {
+		t.Fatalf("expected help output to mention the config.yaml context default: %s", help)
 	}
 
 	expectedSnippets := []string{
@@ -212,3 +244,75 @@ func TestBuildForHelmIncludesKeyOperations(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildJSONMatchesDocumentation(t *testing.T) {
+	text, err := BuildJSON("kubernetes")
+	if err != nil {
+		t.Fatalf("BuildJSON() error = %v", err)
+	}
+
+	var doc ActionDocumentation
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		t.Fatalf("BuildJSON() output is not JSON: %v", err)
+	}
+	want, err := BuildDocumentation("kubernetes")
+	if err != nil {
+		t.Fatalf("BuildDocumentation() error = %v", err)
+	}
+	if doc.Name != want.Name || len(doc.Operations) != len(want.Operations) || len(doc.AllowedValues) != len(want.AllowedValues) {
+		t.Fatalf("BuildJSON() = %+v, want %+v", doc, want)
+	}
+
+	if _, err := BuildJSON("does-not-exist"); !errors.As(err, new(LookupError)) {
+		t.Fatalf("BuildJSON(unknown) error = %v, want LookupError", err)
+	}
+}
+
+func TestBuildExampleProducesValidJSONForEveryAction(t *testing.T) {
+	for _, name := range registry.Names() {
+		doc, err := BuildDocumentation(name)
+		if err != nil {
+			t.Fatalf("BuildDocumentation(%s) error = %v", name, err)
+		}
+
+		operations := []string{""}
+		if len(doc.Operations) > 0 {
+			operations = operations[:0]
+			// The last group documents the default case rather than an operation.
+			for _, op := range doc.Operations[:len(doc.Operations)-1] {
+				operations = append(operations, op.Name)
+			}
+		}
+
+		for _, op := range operations {
+			example, err := BuildExample(name, op)
+			if err != nil {
+				t.Fatalf("BuildExample(%s, %q) error = %v", name, op, err)
+			}
+			var task map[string]any
+			if err := json.Unmarshal([]byte(example), &task); err != nil {
+				t.Fatalf("BuildExample(%s, %q) is not valid JSON: %v\n%s", name, op, err, example)
+			}
+			if op != "" && task["operation"] != op {
+				t.Fatalf("BuildExample(%s, %q) operation = %v", name, op, task["operation"])
+			}
+		}
+	}
+}
+
+func TestBuildExampleOperationErrors(t *testing.T) {
+	var opErr OperationError
+	if _, err := BuildExample("kubernetes", ""); !errors.As(err, &opErr) || len(opErr.Operations) == 0 {
+		t.Fatalf("BuildExample(kubernetes, \"\") error = %v, want OperationError listing operations", err)
+	}
+	if _, err := BuildExample("kubernetes", "NOPE"); !errors.As(err, &opErr) || opErr.Operation != "NOPE" {
+		t.Fatalf("BuildExample(kubernetes, NOPE) error = %v, want OperationError", err)
+	}
+	if _, err := BuildExample("print", "SCALE"); !errors.As(err, &opErr) {
+		t.Fatalf("BuildExample(print, SCALE) error = %v, want OperationError", err)
+	}
+	example, err := BuildExample("kubernetes", "scale")
+	if err != nil || !strings.Contains(example, `"operation": "SCALE"`) {
+		t.Fatalf("BuildExample(kubernetes, scale) = %q, %v", example, err)
+	}
+}
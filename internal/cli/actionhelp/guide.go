@@ -54,6 +54,21 @@ func BuildDocumentation(actionName string) (ActionDocumentation, error) {
 	return actionDocumentationFromSummary(summary), nil
 }
 
+// BuildJSON renders BuildDocumentation as indented JSON for editors and
+// generators.
+func BuildJSON(actionName string) (string, error) {
+	doc, err := BuildDocumentation(actionName)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal %s documentation: %w", doc.Name, err)
+	}
+	return string(data), nil
+}
+
 // BuildAllDocumentation gathers documentation for every registered action.
 func BuildAllDocumentation() ([]ActionDocumentation, error) {
 	names := registry.Names()
@@ -85,6 +100,16 @@ func BuildGuide() (GuideDocument, error) {
 	}, nil
 }
 
+// FormatGuideJSON renders the guide as indented JSON, the same structure served
+// by the UI's /api/actions/guide endpoint without the Markdown rendering.
+func FormatGuideJSON(guide GuideDocument) (string, error) {
+	data, err := json.MarshalIndent(guide, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal action guide: %w", err)
+	}
+	return string(data), nil
+}
+
 // FormatGuideMarkdown renders the guide as Markdown so it can be downloaded or
 // consumed directly by language models.
 func FormatGuideMarkdown(guide GuideDocument) string {
@@ -367,16 +392,27 @@ Root fields:
 - "id": required unique flow identifier.
 - "description": required plain-text summary.
 - "imports": optional list of additional JSON flow files expanded before execution (resolved relative to the main flow file). Imported flows must each define their own "id". Imported tasks are prepended in import order and keep their flow id for routing and logging.
-- "tasks": required ordered array of tasks.
+- "tasks": required ordered array of tasks. An entry {"$include": "fragments.json#taskId", "id": "new-id", "params": {...}} is replaced at load time by that task from another file (or every task of the file when "#taskId" is omitted), with ${name} replaced by the params.
 - "on_error_flow": optional flow id to run immediately after a task failure.
+- "on_success_flow": optional flow id to run after all tasks complete without error.
 - "finally_flow": optional flow id to run after the main flow finishes (success or failure).
 - "finally_task": optional task id to run after the main flow finishes (success or failure).
+- "timeout_seconds": optional limit in seconds for the whole run; on_error_flow and finally hooks still run afterwards with a one-minute budget.
+- "execution": optional "linear" (default, array order) or "dag", which runs each task once the tasks in its "depends_on" list complete and runs independent tasks concurrently, up to "max_concurrency" (0 = unlimited).
 
 Task basics:
 
 - Every task includes "id" and "action". "description" is optional but strongly recommended.
+- "task_timeout_seconds" is optional on any task; the task fails with a timeout error when it runs longer.
+- "retry" is optional on any task: {"max_attempts": 3, "delay_seconds": 2, "backoff_multiplier": 2} retries failed attempts before the task fails.
+- "run_if" and "skip_if" are optional on any task: arrays of {"left", "operation", "right"} conditions (same shape as EVALUATE "if_conditions"). "skip_if" wins when both match; skipped tasks stay not started.
+- "depends_on" is optional on any top-level task: task ids that must complete first when the flow uses "execution": "dag".
+- "labels" is optional on any top-level task: an array of labels such as ["deploy", "smoke"] that "flowk run -tag deploy" uses to run only the matching tasks.
+- VARIABLES entries can load values at runtime with "from_env": "NAME" (optional "default") or "from_file": "path" instead of "value".
+- Mark sensitive VARIABLES entries with type "secret" or "secret": true so their values are masked as *** in logs; the flow-level "mask_patterns" (regular expressions) masks other sensitive output.
 - Use "operation" only for actions that declare multiple operations. Omit it for actions without operations.
 - Some control actions (e.g., "PARALLEL", "FOR") include nested "tasks" arrays; nested tasks follow the same shape.
+- FOR can iterate over a prior result with "values_from": "${from.task:<id>.result.items}" instead of "values"; for objects, ${key} and ${value} hold each entry.
 
 Subflows:
 
@@ -394,6 +430,11 @@ Best practices for generating flows automatically:
 - Keep "id" values in lowercase or snake_case and make them unique across the flow.
 - Always include "description" so human operators understand each step's purpose.
 - Reuse variables with the "VARIABLES" action and reference them with the ${variable} syntax.
+- Use ${variable:-default} for optional inputs and ${variable:?message} to fail the task when a required input is missing.
+- Read earlier task results with ${from.task:TASK_ID.result.items.0.name}; missing paths resolve to an empty string with a warning.
 - Choose the correct action and review its required and optional fields in the list below. Use the available operations to build the right payload.
+- EVALUATE combines "if_conditions" with AND by default; set "logic": "or", or a tree such as {"any": [0, {"not": [1]}]} whose items are condition indexes.
+- SHELL can store its output with "capture": {"stdout_var": "name", "stderr_var": "...", "exit_code_var": "...", "trim": true}; later tasks read it as ${name}.
+- PRINT accepts "level" ("info", "warn", "error") to prefix each line and "file" to append the rendered lines to a report file.
 - Preserve task order; FlowK executes tasks sequentially unless a control action (e.g., "EVALUATE" with "gototask" or "exit") dictates otherwise.
 - Validate flows against the JSON schema (values.schema.json) before execution to catch type errors or missing fields.`
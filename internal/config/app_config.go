@@ -3,9 +3,13 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/adrg/xdg"
 	"gopkg.in/yaml.v3"
@@ -23,11 +27,52 @@ const (
 	DefaultFlowsDir = "./flows"
 )
 
+// Environment variables that override config.yaml values when set. They are
+// meant for containers, where mounting a config file is inconvenient.
+const (
+	UITokenEnv = "FLOWK_UI_TOKEN"
+	UIHostEnv  = "FLOWK_UI_HOST"
+	UIPortEnv  = "FLOWK_UI_PORT"
+	UIDirEnv   = "FLOWK_UI_DIR"
+	LogDirEnv  = "FLOWK_LOG_DIR"
+	// ProfileEnv selects a profile when -profile is not given.
+	ProfileEnv = "FLOWK_PROFILE"
+)
+
+// ValueSource tells where a configuration value came from.
+type ValueSource string
+
+const (
+	SourceDefault ValueSource = "default"
+	SourceFile    ValueSource = "file"
+	SourceProfile ValueSource = "profile"
+	SourceEnv     ValueSource = "env"
+)
+
 // UIConfig controls how the embedded UI server is exposed.
 type UIConfig struct {
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
 	Dir  string `yaml:"dir"`
+	// Token, when set, is required as a bearer token on every /api request.
+	Token string `yaml:"token,omitempty"`
+	// AllowRemote must be set to listen on a host other than loopback.
+	AllowRemote bool `yaml:"allow_remote,omitempty"`
+	// TLSCertFile and TLSKeyFile serve the UI over HTTPS. Both must be set.
+	TLSCertFile string `yaml:"tls_cert_file,omitempty"`
+	TLSKeyFile  string `yaml:"tls_key_file,omitempty"`
+	// TLSSelfSigned serves HTTPS with a certificate generated at startup.
+	// It is meant for local testing only.
+	TLSSelfSigned bool `yaml:"tls_self_signed,omitempty"`
+	// SaveDir receives flows saved from the UI. It defaults to flows_dir.
+	SaveDir string `yaml:"save_dir,omitempty"`
+	// Lang is the default language of API error messages, "en" or "es".
+	Lang string `yaml:"lang,omitempty"`
+	// MaxEventSubscribers bounds concurrent event stream clients. Zero
+	// keeps the server default.
+	MaxEventSubscribers int `yaml:"max_event_subscribers,omitempty"`
+	// Metrics serves Prometheus metrics on /metrics.
+	Metrics bool `yaml:"metrics,omitempty"`
 }
 
 // Config captures the user-facing configuration stored in config.yaml.
@@ -35,6 +80,54 @@ type Config struct {
 	UI       UIConfig      `yaml:"ui"`
 	FlowsDir string        `yaml:"flows_dir"`
 	Secrets  SecretsConfig `yaml:"secrets"`
+	// Defaults are used by actions when a task omits the field. A value in
+	// the task payload always wins.
+	Defaults DefaultsConfig `yaml:"defaults,omitempty"`
+	// Tracing exports OpenTelemetry spans for flows and tasks.
+	Tracing TracingConfig `yaml:"tracing,omitempty"`
+}
+
+// DefaultsConfig holds the optional defaults consulted by actions.
+type DefaultsConfig struct {
+	// LogDir replaces the logs directory below the working directory.
+	// LogFormat is "text" (console only) or "json", which also writes
+	// flow_log.jsonl next to the other flow logs.
+	LogDir     string                   `yaml:"log_dir,omitempty"`
+	LogFormat  string                   `yaml:"log_format,omitempty"`
+	Kubernetes KubernetesDefaultsConfig `yaml:"kubernetes,omitempty"`
+	SSH        SSHDefaultsConfig        `yaml:"ssh,omitempty"`
+}
+
+// KubernetesDefaultsConfig supplies the KUBERNETES connection fields.
+type KubernetesDefaultsConfig struct {
+	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+	Context    string `yaml:"context,omitempty"`
+}
+
+// SSHDefaultsConfig supplies the SSH connection fields.
+type SSHDefaultsConfig struct {
+	TimeoutSeconds  float64  `yaml:"timeout_seconds,omitempty"`
+	HostKeyMode     string   `yaml:"host_key_mode,omitempty"`
+	KnownHostsFiles []string `yaml:"known_hosts_files,omitempty"`
+}
+
+var (
+	actionDefaultsMu sync.RWMutex
+	actionDefaults   DefaultsConfig
+)
+
+// SetActionDefaults configures the defaults returned by ActionDefaults.
+func SetActionDefaults(defaults DefaultsConfig) {
+	actionDefaultsMu.Lock()
+	defer actionDefaultsMu.Unlock()
+	actionDefaults = defaults
+}
+
+// ActionDefaults returns the defaults configured with SetActionDefaults.
+func ActionDefaults() DefaultsConfig {
+	actionDefaultsMu.RLock()
+	defer actionDefaultsMu.RUnlock()
+	return actionDefaults
 }
 
 // SecretsConfig controls native secret provider integration.
@@ -51,11 +144,33 @@ type VaultSecretsConfig struct {
 	KVPrefix string `yaml:"kv_prefix"`
 }
 
+// TracingConfig controls OpenTelemetry tracing. Spans are sent over OTLP/HTTP
+// to OTEL_EXPORTER_OTLP_ENDPOINT, then Endpoint, then localhost:4318.
+type TracingConfig struct {
+	Enabled     bool   `yaml:"enabled,omitempty"`
+	Endpoint    string `yaml:"endpoint,omitempty"`
+	ServiceName string `yaml:"service_name,omitempty"`
+}
+
 // LoadResult reports the resolved configuration data and location.
 type LoadResult struct {
 	Config Config
 	Path   string
 	Loaded bool
+	// Profile is the name of the profile merged over the base values, or
+	// empty when none was selected.
+	Profile string
+	// Sources records where the values that environment variables can
+	// override came from, keyed by their config.yaml path ("ui.port").
+	Sources map[string]ValueSource
+}
+
+// Source returns where the value at key came from.
+func (r LoadResult) Source(key string) ValueSource {
+	if source, ok := r.Sources[key]; ok {
+		return source
+	}
+	return SourceDefault
 }
 
 // DefaultConfig returns the configuration values used when config.yaml is missing.
@@ -88,39 +203,183 @@ func Load() (LoadResult, error) {
 }
 
 // LoadFrom reads config.yaml (if present) from the provided path.
-// When path is empty, the XDG configuration location is used.
+// When path is empty, the XDG configuration location is used. The profile
+// named by FLOWK_PROFILE, if any, is merged over the base configuration.
 func LoadFrom(path string) (LoadResult, error) {
+	return LoadProfile(path, "")
+}
+
+// LoadProfile reads config.yaml like LoadFrom and merges the named entry of
+// its "profiles" map over the base configuration. An empty profile falls
+// back to FLOWK_PROFILE; naming a profile the file does not define is an
+// error.
+func LoadProfile(path, profile string) (LoadResult, error) {
 	resolvedPath, err := resolveConfigPath(path)
 	if err != nil {
 		return LoadResult{}, err
 	}
 
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		profile = strings.TrimSpace(os.Getenv(ProfileEnv))
+	}
+
 	cfg := DefaultConfig()
+	sources := make(map[string]ValueSource)
 	data, err := os.ReadFile(resolvedPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			if writeErr := writeDefaultConfig(resolvedPath, cfg); writeErr != nil {
 				return LoadResult{}, writeErr
 			}
-			return LoadResult{Config: cfg, Path: resolvedPath, Loaded: true}, nil
+			data = nil
+		} else {
+			return LoadResult{}, fmt.Errorf("read config %s: %w", resolvedPath, err)
 		}
-		return LoadResult{}, fmt.Errorf("read config %s: %w", resolvedPath, err)
 	}
 
 	if len(strings.TrimSpace(string(data))) == 0 {
-		return LoadResult{Config: cfg, Path: resolvedPath, Loaded: true}, nil
+		if profile != "" {
+			return LoadResult{}, fmt.Errorf("config %s: profile %q is not defined (the file has no profiles)", resolvedPath, profile)
+		}
+		return finishLoad(cfg, resolvedPath, profile, sources)
+	}
+
+	var profileData []byte
+	if profile != "" {
+		data, profileData, err = mergeProfile(data, profile)
+		if err != nil {
+			return LoadResult{}, fmt.Errorf("config %s: %w", resolvedPath, err)
+		}
 	}
 
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return LoadResult{}, fmt.Errorf("parse config %s: %w", resolvedPath, err)
 	}
+	var fromFile Config
+	if err := yaml.Unmarshal(data, &fromFile); err == nil {
+		recordFileSources(fromFile, SourceFile, sources)
+	}
+	var fromProfile Config
+	if err := yaml.Unmarshal(profileData, &fromProfile); err == nil {
+		recordFileSources(fromProfile, SourceProfile, sources)
+	}
 
 	cfg = applyDefaults(cfg)
 	if err := validateConfig(cfg); err != nil {
 		return LoadResult{}, fmt.Errorf("invalid config %s: %w", resolvedPath, err)
 	}
 
-	return LoadResult{Config: cfg, Path: resolvedPath, Loaded: true}, nil
+	return finishLoad(cfg, resolvedPath, profile, sources)
+}
+
+// mergeProfile returns the config document with profiles[name] merged over
+// the base values, along with the profile document alone.
+func mergeProfile(data []byte, name string) ([]byte, []byte, error) {
+	var document map[string]any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	profiles, _ := document["profiles"].(map[string]any)
+	selected, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for candidate := range profiles {
+			names = append(names, candidate)
+		}
+		if len(names) == 0 {
+			return nil, nil, fmt.Errorf("profile %q is not defined (the file has no profiles)", name)
+		}
+		sort.Strings(names)
+		return nil, nil, fmt.Errorf("profile %q is not defined (available: %s)", name, strings.Join(names, ", "))
+	}
+	overlay, ok := selected.(map[string]any)
+	if !ok && selected != nil {
+		return nil, nil, fmt.Errorf("profiles.%s must be a mapping", name)
+	}
+	delete(overlay, "profiles")
+	delete(document, "profiles")
+	mergeYAMLMaps(document, overlay)
+
+	merged, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, nil, fmt.Errorf("merge profile %q: %w", name, err)
+	}
+	profileData, err := yaml.Marshal(overlay)
+	if err != nil {
+		return nil, nil, fmt.Errorf("merge profile %q: %w", name, err)
+	}
+	return merged, profileData, nil
+}
+
+// mergeYAMLMaps copies overlay into base, merging nested mappings so a
+// profile only needs the keys it changes.
+func mergeYAMLMaps(base, overlay map[string]any) {
+	for key, value := range overlay {
+		nested, isMap := value.(map[string]any)
+		existing, existingIsMap := base[key].(map[string]any)
+		if isMap && existingIsMap {
+			mergeYAMLMaps(existing, nested)
+			continue
+		}
+		base[key] = value
+	}
+}
+
+func finishLoad(cfg Config, path, profile string, sources map[string]ValueSource) (LoadResult, error) {
+	cfg, err := applyEnvironment(cfg, sources)
+	if err != nil {
+		return LoadResult{}, err
+	}
+	return LoadResult{Config: cfg, Path: path, Loaded: true, Profile: profile, Sources: sources}, nil
+}
+
+// recordFileSources marks the overridable values set in config.yaml or in
+// the selected profile.
+func recordFileSources(fromFile Config, source ValueSource, sources map[string]ValueSource) {
+	set := map[string]bool{
+		"ui.host":          strings.TrimSpace(fromFile.UI.Host) != "",
+		"ui.port":          fromFile.UI.Port != 0,
+		"ui.dir":           strings.TrimSpace(fromFile.UI.Dir) != "",
+		"ui.token":         strings.TrimSpace(fromFile.UI.Token) != "",
+		"defaults.log_dir": strings.TrimSpace(fromFile.Defaults.LogDir) != "",
+	}
+	for key, isSet := range set {
+		if isSet {
+			sources[key] = source
+		}
+	}
+}
+
+// applyEnvironment applies the environment overrides. They are not written
+// back to config.yaml.
+func applyEnvironment(cfg Config, sources map[string]ValueSource) (Config, error) {
+	if token := strings.TrimSpace(os.Getenv(UITokenEnv)); token != "" {
+		cfg.UI.Token = token
+		sources["ui.token"] = SourceEnv
+	}
+	if host := strings.TrimSpace(os.Getenv(UIHostEnv)); host != "" {
+		cfg.UI.Host = host
+		sources["ui.host"] = SourceEnv
+	}
+	if raw := strings.TrimSpace(os.Getenv(UIPortEnv)); raw != "" {
+		port, err := strconv.Atoi(raw)
+		if err != nil || port <= 0 || port > 65535 {
+			return Config{}, fmt.Errorf("%s must be a port number between 1 and 65535, got %q", UIPortEnv, raw)
+		}
+		cfg.UI.Port = port
+		sources["ui.port"] = SourceEnv
+	}
+	if dir := strings.TrimSpace(os.Getenv(UIDirEnv)); dir != "" {
+		cfg.UI.Dir = dir
+		sources["ui.dir"] = SourceEnv
+	}
+	if dir := strings.TrimSpace(os.Getenv(LogDirEnv)); dir != "" {
+		cfg.Defaults.LogDir = dir
+		sources["defaults.log_dir"] = SourceEnv
+	}
+	return cfg, nil
 }
 
 func resolveConfigPath(path string) (string, error) {
@@ -140,6 +399,7 @@ func applyDefaults(cfg Config) Config {
 		cfg.UI.Port = DefaultUIPort
 	}
 
+	cfg.UI.Token = strings.TrimSpace(cfg.UI.Token)
 	cfg.UI.Dir = strings.TrimSpace(cfg.UI.Dir)
 	if cfg.UI.Dir == "" {
 		cfg.UI.Dir = DefaultUIDir
@@ -156,6 +416,15 @@ func applyDefaults(cfg Config) Config {
 	cfg.Secrets.Vault.KVMount = strings.TrimSpace(cfg.Secrets.Vault.KVMount)
 	cfg.Secrets.Vault.KVPrefix = strings.TrimSpace(cfg.Secrets.Vault.KVPrefix)
 
+	cfg.Tracing.Endpoint = strings.TrimSpace(cfg.Tracing.Endpoint)
+	cfg.Tracing.ServiceName = strings.TrimSpace(cfg.Tracing.ServiceName)
+
+	cfg.Defaults.LogDir = strings.TrimSpace(cfg.Defaults.LogDir)
+	cfg.Defaults.LogFormat = strings.ToLower(strings.TrimSpace(cfg.Defaults.LogFormat))
+	cfg.Defaults.Kubernetes.Kubeconfig = strings.TrimSpace(cfg.Defaults.Kubernetes.Kubeconfig)
+	cfg.Defaults.Kubernetes.Context = strings.TrimSpace(cfg.Defaults.Kubernetes.Context)
+	cfg.Defaults.SSH.HostKeyMode = strings.ToLower(strings.TrimSpace(cfg.Defaults.SSH.HostKeyMode))
+
 	return cfg
 }
 
@@ -163,6 +432,37 @@ func validateConfig(cfg Config) error {
 	if cfg.UI.Port <= 0 || cfg.UI.Port > 65535 {
 		return fmt.Errorf("ui.port must be between 1 and 65535")
 	}
+	hasCert := strings.TrimSpace(cfg.UI.TLSCertFile) != ""
+	hasKey := strings.TrimSpace(cfg.UI.TLSKeyFile) != ""
+	if hasCert != hasKey {
+		return fmt.Errorf("ui.tls_cert_file and ui.tls_key_file must be set together")
+	}
+	if hasCert && cfg.UI.TLSSelfSigned {
+		return fmt.Errorf("ui.tls_self_signed cannot be combined with ui.tls_cert_file")
+	}
+	if cfg.UI.MaxEventSubscribers < 0 {
+		return fmt.Errorf("ui.max_event_subscribers cannot be negative")
+	}
+	switch cfg.Defaults.LogFormat {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("defaults.log_format %q is not supported (use text or json)", cfg.Defaults.LogFormat)
+	}
+	if cfg.Defaults.SSH.TimeoutSeconds < 0 {
+		return fmt.Errorf("defaults.ssh.timeout_seconds cannot be negative")
+	}
+	switch cfg.Defaults.SSH.HostKeyMode {
+	case "", "insecure", "known_hosts", "tofu":
+	default:
+		return fmt.Errorf("defaults.ssh.host_key_mode %q is not supported", cfg.Defaults.SSH.HostKeyMode)
+	}
+
+	if cfg.Tracing.Endpoint != "" {
+		parsed, err := url.Parse(cfg.Tracing.Endpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("tracing.endpoint %q must be an http or https URL", cfg.Tracing.Endpoint)
+		}
+	}
 
 	provider := strings.ToLower(strings.TrimSpace(cfg.Secrets.Provider))
 	switch provider {
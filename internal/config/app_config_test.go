@@ -90,6 +90,32 @@ func TestLoadFromParsesFlowsDir(t *testing.T) {
 	}
 }
 
+func TestLoadFromReadsUIToken(t *testing.T) {
+	customDir := t.TempDir()
+	customPath := filepath.Join(customDir, "token.yaml")
+	content := "ui:\n  host: 0.0.0.0\n  port: 8080\n  token: from-file\n"
+	if err := os.WriteFile(customPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing custom config: %v", err)
+	}
+
+	t.Setenv(UITokenEnv, "")
+	result, err := LoadFrom(customPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if result.Config.UI.Token != "from-file" {
+		t.Fatalf("ui.token = %q, want from-file", result.Config.UI.Token)
+	}
+
+	t.Setenv(UITokenEnv, "from-env")
+	result, err = LoadFrom(customPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if result.Config.UI.Token != "from-env" {
+		t.Fatalf("ui.token = %q, want from-env", result.Config.UI.Token)
+	}
+}
 
 func TestLoadFromWithVaultSecrets(t *testing.T) {
 	customDir := t.TempDir()
@@ -125,3 +151,220 @@ func TestLoadFromVaultRequiresAddressAndToken(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestLoadFromValidatesUITLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "cert and key", content: "ui:\n  tls_cert_file: cert.pem\n  tls_key_file: key.pem\n"},
+		{name: "self signed", content: "ui:\n  tls_self_signed: true\n"},
+		{name: "cert without key", content: "ui:\n  tls_cert_file: cert.pem\n", wantErr: true},
+		{name: "self signed with cert", content: "ui:\n  tls_cert_file: cert.pem\n  tls_key_file: key.pem\n  tls_self_signed: true\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customPath := filepath.Join(t.TempDir(), "tls.yaml")
+			if err := os.WriteFile(customPath, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("writing custom config: %v", err)
+			}
+
+			_, err := LoadFrom(customPath)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("LoadFrom error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadFromParsesActionDefaults(t *testing.T) {
+	customPath := filepath.Join(t.TempDir(), "defaults.yaml")
+	content := "defaults:\n  log_dir: /var/log/flowk\n  log_format: JSON\n  kubernetes:\n    kubeconfig: ~/.kube/prod\n    context: prod\n  ssh:\n    timeout_seconds: 15\n    host_key_mode: Known_Hosts\n    known_hosts_files: [~/.ssh/known_hosts]\n"
+	if err := os.WriteFile(customPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing custom config: %v", err)
+	}
+
+	result, err := LoadFrom(customPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	defaults := result.Config.Defaults
+	if defaults.LogDir != "/var/log/flowk" {
+		t.Fatalf("defaults.log_dir = %q, want /var/log/flowk", defaults.LogDir)
+	}
+	if defaults.LogFormat != "json" {
+		t.Fatalf("defaults.log_format = %q, want json", defaults.LogFormat)
+	}
+	if defaults.Kubernetes.Kubeconfig != "~/.kube/prod" || defaults.Kubernetes.Context != "prod" {
+		t.Fatalf("defaults.kubernetes = %+v", defaults.Kubernetes)
+	}
+	if defaults.SSH.TimeoutSeconds != 15 || defaults.SSH.HostKeyMode != "known_hosts" || len(defaults.SSH.KnownHostsFiles) != 1 {
+		t.Fatalf("defaults.ssh = %+v", defaults.SSH)
+	}
+}
+
+func TestLoadFromValidatesActionDefaults(t *testing.T) {
+	for _, content := range []string{
+		"defaults:\n  ssh:\n    timeout_seconds: -1\n",
+		"defaults:\n  ssh:\n    host_key_mode: strict\n",
+		"defaults:\n  log_format: xml\n",
+	} {
+		customPath := filepath.Join(t.TempDir(), "defaults.yaml")
+		if err := os.WriteFile(customPath, []byte(content), 0o600); err != nil {
+			t.Fatalf("writing custom config: %v", err)
+		}
+		if _, err := LoadFrom(customPath); err == nil || !strings.Contains(err.Error(), "defaults.") {
+			t.Fatalf("LoadFrom(%q) error = %v, want defaults error", content, err)
+		}
+	}
+}
+
+func TestLoadFromParsesTracing(t *testing.T) {
+	customPath := filepath.Join(t.TempDir(), "tracing.yaml")
+	if err := os.WriteFile(customPath, []byte("tracing:\n  enabled: true\n  endpoint: \" http://collector:4318 \"\n"), 0o600); err != nil {
+		t.Fatalf("writing custom config: %v", err)
+	}
+
+	result, err := LoadFrom(customPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if !result.Config.Tracing.Enabled || result.Config.Tracing.Endpoint != "http://collector:4318" {
+		t.Fatalf("tracing = %+v", result.Config.Tracing)
+	}
+
+	if err := os.WriteFile(customPath, []byte("tracing:\n  enabled: true\n  endpoint: collector:4318\n"), 0o600); err != nil {
+		t.Fatalf("writing custom config: %v", err)
+	}
+	if _, err := LoadFrom(customPath); err == nil || !strings.Contains(err.Error(), "tracing.endpoint") {
+		t.Fatalf("LoadFrom() error = %v, want tracing.endpoint error", err)
+	}
+}
+
+func TestDefaultConfigFileOmitsActionDefaults(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	result, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	data, err := os.ReadFile(result.Path)
+	if err != nil {
+		t.Fatalf("reading config file: %v", err)
+	}
+	if strings.Contains(string(data), "defaults:") {
+		t.Fatalf("expected no defaults section in a fresh config, got: %s", data)
+	}
+}
+
+func TestLoadFromAppliesEnvironmentOverrides(t *testing.T) {
+	customPath := filepath.Join(t.TempDir(), "env.yaml")
+	content := "ui:\n  host: 127.0.0.1\n  port: 8080\n  dir: ui/file\ndefaults:\n  log_dir: ./file-logs\n"
+	if err := os.WriteFile(customPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing custom config: %v", err)
+	}
+
+	t.Setenv(UIHostEnv, "0.0.0.0")
+	t.Setenv(UIPortEnv, "9090")
+	t.Setenv(UIDirEnv, "")
+	t.Setenv(LogDirEnv, "/var/log/flowk")
+	t.Setenv(UITokenEnv, "")
+
+	result, err := LoadFrom(customPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if result.Config.UI.Host != "0.0.0.0" || result.Config.UI.Port != 9090 || result.Config.UI.Dir != "ui/file" {
+		t.Fatalf("ui = %+v, want host and port from the environment and dir from the file", result.Config.UI)
+	}
+	if result.Config.Defaults.LogDir != "/var/log/flowk" {
+		t.Fatalf("defaults.log_dir = %q, want /var/log/flowk", result.Config.Defaults.LogDir)
+	}
+
+	wantSources := map[string]ValueSource{
+		"ui.host":          SourceEnv,
+		"ui.port":          SourceEnv,
+		"ui.dir":           SourceFile,
+		"ui.token":         SourceDefault,
+		"defaults.log_dir": SourceEnv,
+	}
+	for key, want := range wantSources {
+		if got := result.Source(key); got != want {
+			t.Fatalf("Source(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadFromRejectsInvalidPortEnvironment(t *testing.T) {
+	customPath := filepath.Join(t.TempDir(), "env.yaml")
+	if err := os.WriteFile(customPath, []byte("ui:\n  port: 8080\n"), 0o600); err != nil {
+		t.Fatalf("writing custom config: %v", err)
+	}
+
+	for _, value := range []string{"http", "0", "70000"} {
+		t.Setenv(UIPortEnv, value)
+		_, err := LoadFrom(customPath)
+		if err == nil || !strings.Contains(err.Error(), UIPortEnv+" must be a port number between 1 and 65535") {
+			t.Fatalf("LoadFrom() with %s=%q error = %v, want port error", UIPortEnv, value, err)
+		}
+	}
+}
+
+func TestLoadProfileMergesOverBaseConfig(t *testing.T) {
+	customPath := filepath.Join(t.TempDir(), "profiles.yaml")
+	content := "ui:\n  host: 127.0.0.1\n  port: 8080\n  dir: ui/dist\nflows_dir: ./flows\nprofiles:\n  prod:\n    ui:\n      port: 9443\n    flows_dir: ./prod-flows\n    defaults:\n      kubernetes:\n        context: prod-cluster\n  dev: {}\n"
+	if err := os.WriteFile(customPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing custom config: %v", err)
+	}
+	t.Setenv(ProfileEnv, "")
+	t.Setenv(UIPortEnv, "")
+
+	result, err := LoadProfile(customPath, "prod")
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if result.Profile != "prod" {
+		t.Fatalf("profile = %q, want prod", result.Profile)
+	}
+	if result.Config.UI.Host != "127.0.0.1" || result.Config.UI.Port != 9443 {
+		t.Fatalf("ui = %+v, want the base host and the profile port", result.Config.UI)
+	}
+	if result.Config.FlowsDir != "./prod-flows" || result.Config.Defaults.Kubernetes.Context != "prod-cluster" {
+		t.Fatalf("config = %+v, want the profile values", result.Config)
+	}
+	if result.Source("ui.port") != SourceProfile || result.Source("ui.host") != SourceFile {
+		t.Fatalf("sources = %v, want ui.port from the profile and ui.host from the file", result.Sources)
+	}
+
+	t.Setenv(ProfileEnv, "dev")
+	result, err = LoadFrom(customPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if result.Profile != "dev" || result.Config.UI.Port != 8080 {
+		t.Fatalf("profile, port = %q, %d, want dev and the base port", result.Profile, result.Config.UI.Port)
+	}
+}
+
+func TestLoadProfileRejectsUnknownProfile(t *testing.T) {
+	customPath := filepath.Join(t.TempDir(), "profiles.yaml")
+	if err := os.WriteFile(customPath, []byte("profiles:\n  prod: {}\n  dev: {}\n"), 0o600); err != nil {
+		t.Fatalf("writing custom config: %v", err)
+	}
+
+	_, err := LoadProfile(customPath, "staging")
+	if err == nil || !strings.Contains(err.Error(), `profile "staging" is not defined (available: dev, prod)`) {
+		t.Fatalf("LoadProfile() error = %v, want unknown profile error", err)
+	}
+
+	_, err = LoadProfile(filepath.Join(t.TempDir(), "missing.yaml"), "prod")
+	if err == nil || !strings.Contains(err.Error(), `profile "prod" is not defined`) {
+		t.Fatalf("LoadProfile() on a new config error = %v, want unknown profile error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package flow
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// definitionCache keeps the validated content of every flow file read by
+// LoadDefinition, so a sub-flow imported several times, or loaded again by a
+// later run, is read, include-expanded and schema-validated once. Entries are
+// keyed by absolute path and reused while the file and the fragments it
+// includes keep their modification time and size. Every load still decodes
+// the content, so callers never share tasks.
+//
+// The UI validates saved flows under temporary names that are never loaded
+// again, so the cache starts over once it holds maxCachedDefinitions files.
+var definitionCache = struct {
+	sync.Mutex
+	entries map[string]*cachedDefinition
+}{entries: make(map[string]*cachedDefinition)}
+
+const maxCachedDefinitions = 512
+
+type cachedDefinition struct {
+	content       []byte
+	schemaVersion uint64
+	files         []fileStamp
+}
+
+// fileStamp records the state of a file when it was read.
+type fileStamp struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// statFile is taken before the file is read: a change made while reading
+// leaves an older stamp behind, which only costs a reload next time.
+func statFile(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{path: path, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+func (s fileStamp) current() bool {
+	stamp, err := statFile(s.path)
+	return err == nil && stamp.modTime.Equal(s.modTime) && stamp.size == s.size
+}
+
+// loadDefinitionContent returns the content of the flow at path with its
+// includes resolved and validated against the schema, and the files it was
+// built from: path followed by the fragments it includes.
+func loadDefinitionContent(path, baseDir string) ([]byte, []string, error) {
+	_, version := schemaFragments()
+	version = schemaCacheVersion(version)
+
+	definitionCache.Lock()
+	entry := definitionCache.entries[path]
+	definitionCache.Unlock()
+	if entry != nil && entry.schemaVersion == version && entry.fresh() {
+		return entry.content, entry.paths(), nil
+	}
+
+	stamp, err := statFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading action flow %s: %w", path, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading action flow %s: %w", path, err)
+	}
+
+	content, fragments, err := resolveIncludes(path, baseDir, content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving includes in %s: %w", path, err)
+	}
+
+	if err := validateDefinitionAgainstSchema(path, content); err != nil {
+		return nil, nil, err
+	}
+
+	definitionCache.Lock()
+	if len(definitionCache.entries) >= maxCachedDefinitions {
+		definitionCache.entries = make(map[string]*cachedDefinition)
+	}
+	entry = &cachedDefinition{
+		content:       content,
+		schemaVersion: version,
+		files:         append([]fileStamp{stamp}, fragments...),
+	}
+	definitionCache.entries[path] = entry
+	definitionCache.Unlock()
+	return content, entry.paths(), nil
+}
+
+func (c *cachedDefinition) paths() []string {
+	paths := make([]string, len(c.files))
+	for idx, file := range c.files {
+		paths[idx] = file.path
+	}
+	return appendUnique(nil, paths)
+}
+
+func (c *cachedDefinition) fresh() bool {
+	for _, file := range c.files {
+		if !file.current() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package flow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// replaceKeepingStamp overwrites path with content of the same size and
+// restores its modification time, a change the cache cannot see.
+func replaceKeepingStamp(t *testing.T, path string, content []byte) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if int64(len(content)) != info.Size() {
+		t.Fatalf("replacement is %d bytes, want %d", len(content), info.Size())
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+}
+
+func TestLoadDefinitionReusesCachedFiles(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := writeIncludeFiles(t, map[string]string{
+		"flow.json":     `{"description":"root","id":"cache.root","name":"cache.root","imports":["sub.json"],"tasks":[{"$include":"fragment.json#wait"}]}`,
+		"sub.json":      `{"description":"sub","id":"cache.sub","name":"cache.sub","tasks":[{"action":"SLEEP","description":"sub","id":"sub","name":"sub","seconds":1}]}`,
+		"fragment.json": `[{"action":"SLEEP","description":"wait","id":"wait","name":"wait","seconds":1}]`,
+	})
+	rootPath := filepath.Join(dir, "flow.json")
+
+	first, err := LoadDefinition(rootPath)
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+
+	// Unreadable content behind unchanged stamps proves nothing is read again.
+	for _, name := range []string{"flow.json", "sub.json", "fragment.json"} {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		replaceKeepingStamp(t, path, bytes.Repeat([]byte(" "), int(info.Size())))
+	}
+
+	first.Tasks[0].Status = TaskStatusCompleted
+	second, err := LoadDefinition(rootPath)
+	if err != nil {
+		t.Fatalf("LoadDefinition() from cache error = %v", err)
+	}
+	if len(second.Tasks) != 2 || second.Tasks[0].ID != "sub" || second.Tasks[1].ID != "wait" {
+		t.Fatalf("cached tasks = %+v, want sub and wait", second.Tasks)
+	}
+	if second.Tasks[0].Status != TaskStatusNotStarted {
+		t.Fatalf("cached task status = %q, want tasks not shared between loads", second.Tasks[0].Status)
+	}
+}
+
+func TestLoadDefinitionReloadsChangedFiles(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := writeIncludeFiles(t, map[string]string{
+		"flow.json":     `{"description":"root","id":"reload.root","name":"reload.root","imports":["sub.json"],"tasks":[{"$include":"fragment.json#wait"}]}`,
+		"sub.json":      `{"description":"sub","id":"reload.sub","name":"reload.sub","tasks":[{"action":"SLEEP","description":"sub","id":"sub","name":"sub","seconds":1}]}`,
+		"fragment.json": `[{"action":"SLEEP","description":"wait","id":"wait","name":"wait","seconds":1}]`,
+	})
+	rootPath := filepath.Join(dir, "flow.json")
+	if _, err := LoadDefinition(rootPath); err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	rewrite := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		later = later.Add(time.Second)
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+
+	rewrite("sub.json", `{"description":"sub","id":"reload.sub","name":"reload.sub","tasks":[{"action":"SLEEP","description":"sub","id":"sub2","name":"sub","seconds":1}]}`)
+	rewrite("fragment.json", `[{"action":"SLEEP","description":"wait","id":"wait","name":"changed","seconds":1}]`)
+
+	def, err := LoadDefinition(rootPath)
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+	if def.Tasks[0].ID != "sub2" || def.Tasks[1].Name != "changed" {
+		t.Fatalf("tasks = %+v, want the changed import and fragment", def.Tasks)
+	}
+
+	rewrite("flow.json", `not json`)
+	if _, err := LoadDefinition(rootPath); err == nil {
+		t.Fatal("LoadDefinition() error = nil after the flow became invalid")
+	}
+}
+
+func TestLoadDefinitionConcurrentLoads(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := writeIncludeFiles(t, map[string]string{
+		"flow.json": `{"description":"root","id":"concurrent.root","name":"concurrent.root","imports":["sub.json"],"tasks":[{"action":"SLEEP","description":"root","id":"root","name":"root","seconds":1}]}`,
+		"sub.json":  `{"description":"sub","id":"concurrent.sub","name":"concurrent.sub","tasks":[{"action":"SLEEP","description":"sub","id":"sub","name":"sub","seconds":1}]}`,
+	})
+	rootPath := filepath.Join(dir, "flow.json")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			def, err := LoadDefinition(rootPath)
+			if err == nil && len(def.Tasks) != 2 {
+				err = fmt.Errorf("got %d tasks, want 2", len(def.Tasks))
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("LoadDefinition() error = %v", err)
+		}
+	}
+}
+
+// writeImportTree writes a flow whose imports form a chain depth files deep,
+// each file with width tasks, and returns the path of the root flow.
+func writeImportTree(tb testing.TB, depth, width int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	for level := depth; level >= 0; level-- {
+		var tasks []string
+		for idx := 0; idx < width; idx++ {
+			id := fmt.Sprintf("level%d.task%d", level, idx)
+			tasks = append(tasks, fmt.Sprintf(`{"action":"SLEEP","description":"%s","id":"%s","name":"%s","seconds":1}`, id, id, id))
+		}
+		imports := ""
+		if level < depth {
+			imports = fmt.Sprintf(`"imports":["level%d.json"],`, level+1)
+		}
+		content := fmt.Sprintf(`{"description":"level %d","id":"tree.level%d","name":"tree.level%d",%s"tasks":[%s]}`,
+			level, level, level, imports, strings.Join(tasks, ","))
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("level%d.json", level)), []byte(content), 0o600); err != nil {
+			tb.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	return filepath.Join(dir, "level0.json")
+}
+
+func BenchmarkLoadDefinitionDeepImports(b *testing.B) {
+	SetupSchemaProviderForTesting(b)
+	rootPath := writeImportTree(b, 20, 10)
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			resetDefinitionCache()
+			if _, err := LoadDefinition(rootPath); err != nil {
+				b.Fatalf("LoadDefinition() error = %v", err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := LoadDefinition(rootPath); err != nil {
+				b.Fatalf("LoadDefinition() error = %v", err)
+			}
+		}
+	})
+}
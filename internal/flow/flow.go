@@ -3,8 +3,11 @@ package flow
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -40,10 +43,23 @@ type Definition struct {
 	// execution jumps directly to the referenced flow after the first
 	// failure instead of stopping immediately.
 	OnErrorFlow string `json:"on_error_flow,omitempty"`
+	// OnSuccessFlow is executed after all tasks complete without error. It is
+	// skipped whenever a task fails, in which case OnErrorFlow applies instead.
+	OnSuccessFlow string `json:"on_success_flow,omitempty"`
 	// FinallyFlow is executed once after the flow finishes, regardless of success or failure.
 	FinallyFlow string `json:"finally_flow,omitempty"`
 	// FinallyTask is executed once after the flow finishes, regardless of success or failure.
 	FinallyTask string `json:"finally_task,omitempty"`
+	// TimeoutSeconds bounds the whole run, including cleanup hooks. Zero disables the limit.
+	TimeoutSeconds float64 `json:"timeout_seconds,omitempty"`
+	// MaskPatterns lists regular expressions whose matches are hidden in every log line.
+	MaskPatterns []string `json:"mask_patterns,omitempty"`
+	// Execution selects the scheduler: ExecutionLinear (the default) runs the
+	// tasks in array order, ExecutionDAG runs each task once the tasks in its
+	// DependsOn list completed, running independent tasks concurrently.
+	Execution string `json:"execution,omitempty"`
+	// MaxConcurrency bounds how many tasks ExecutionDAG runs at once. Zero means unlimited.
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
 
 	// FlowImports maps a flow identifier to the list of flow identifiers it imports.
 	// The map is populated when loading a definition and is not part of the JSON payload.
@@ -51,8 +67,17 @@ type Definition struct {
 	// FlowNames maps a flow identifier to its human-friendly name.
 	// The map is populated when loading a definition and is not part of the JSON payload.
 	FlowNames map[string]string `json:"-"`
+	// Files lists the absolute paths of the flow file, its imports and the
+	// $include fragments they use. It is populated when loading a definition.
+	Files []string `json:"-"`
 }
 
+// Schedulers accepted by Definition.Execution.
+const (
+	ExecutionLinear = "linear"
+	ExecutionDAG    = "dag"
+)
+
 // TaskStatus identifies the lifecycle state of a task within a flow definition.
 type TaskStatus string
 
@@ -69,6 +94,12 @@ type Task struct {
 	Name            string          `json:"name,omitempty"`
 	Description     string          `json:"description"`
 	Action          string          `json:"action"`
+	TimeoutSeconds  float64         `json:"task_timeout_seconds,omitempty"`
+	Retry           *RetryPolicy    `json:"retry,omitempty"`
+	RunIf           json.RawMessage `json:"run_if,omitempty"`
+	SkipIf          json.RawMessage `json:"skip_if,omitempty"`
+	Labels          []string        `json:"labels,omitempty"`
+	DependsOn       []string        `json:"depends_on,omitempty"`
 	FlowID          string          `json:"-"`
 	Status          TaskStatus      `json:"status,omitempty"`
 	StartTimestamp  time.Time       `json:"-"`
@@ -80,6 +111,54 @@ type Task struct {
 	Payload         json.RawMessage `json:"-"`
 }
 
+// RetryPolicy describes how a failing task is re-executed before it is marked as failed.
+type RetryPolicy struct {
+	MaxAttempts       int     `json:"max_attempts"`
+	DelaySeconds      float64 `json:"delay_seconds,omitempty"`
+	BackoffMultiplier float64 `json:"backoff_multiplier,omitempty"`
+}
+
+// Attempts returns the total number of executions allowed by the policy.
+func (p *RetryPolicy) Attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// Delay returns the pause before the given retry. The first retry waits
+// delay_seconds and every following retry multiplies the previous delay by
+// backoff_multiplier.
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	if p == nil || p.DelaySeconds <= 0 || retry < 1 {
+		return 0
+	}
+
+	multiplier := p.BackoffMultiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+
+	seconds := p.DelaySeconds * math.Pow(multiplier, float64(retry-1))
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func (p *RetryPolicy) validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("retry.max_attempts must be greater than or equal to one")
+	}
+	if p.DelaySeconds < 0 {
+		return fmt.Errorf("retry.delay_seconds must be greater than or equal to zero")
+	}
+	if p.BackoffMultiplier != 0 && p.BackoffMultiplier < 1 {
+		return fmt.Errorf("retry.backoff_multiplier must be greater than or equal to one")
+	}
+	return nil
+}
+
 // UnmarshalJSON extracts the metadata fields of a task and retains the original payload.
 func (t *Task) UnmarshalJSON(data []byte) error {
 	type alias struct {
@@ -87,6 +166,20 @@ func (t *Task) UnmarshalJSON(data []byte) error {
 		Name        string `json:"name"`
 		Description string `json:"description"`
 		Action      string `json:"action"`
+		// TimeoutSeconds bounds the whole task. It has its own key so it
+		// never collides with the timeout_seconds that HTTP_REQUEST and HELM
+		// read as their own request timeout.
+		TimeoutSeconds float64      `json:"task_timeout_seconds"`
+		Retry          *RetryPolicy `json:"retry"`
+		// RunIf and SkipIf hold EVALUATE-style conditions that gate the task.
+		RunIf  json.RawMessage `json:"run_if"`
+		SkipIf json.RawMessage `json:"skip_if"`
+		// Labels select the task with the -tag run flag. The key is not
+		// "tags" because DOCKER already reads that as IMAGE_BUILD image tags.
+		Labels []string `json:"labels"`
+		// DependsOn lists the tasks that must complete before this one
+		// starts when the flow uses the dag execution mode.
+		DependsOn []string `json:"depends_on"`
 	}
 
 	var a alias
@@ -98,6 +191,12 @@ func (t *Task) UnmarshalJSON(data []byte) error {
 	t.Name = a.Name
 	t.Description = a.Description
 	t.Action = a.Action
+	t.TimeoutSeconds = a.TimeoutSeconds
+	t.Retry = a.Retry
+	t.RunIf = a.RunIf
+	t.SkipIf = a.SkipIf
+	t.Labels = a.Labels
+	t.DependsOn = a.DependsOn
 	t.Payload = append(t.Payload[:0], data...)
 
 	return nil
@@ -111,7 +210,7 @@ func LoadDefinition(path string) (*Definition, error) {
 	}
 
 	baseDir := filepath.Dir(absPath)
-	def, err := loadDefinitionRecursive(absPath, baseDir, map[string]struct{}{}, map[string]string{})
+	def, err := loadDefinitionRecursive(absPath, baseDir, nil, map[string]string{})
 	if err != nil {
 		return nil, err
 	}
@@ -123,20 +222,20 @@ func LoadDefinition(path string) (*Definition, error) {
 	return def, nil
 }
 
-func loadDefinitionRecursive(path, baseDir string, visiting map[string]struct{}, flowIDs map[string]string) (*Definition, error) {
-	if _, seen := visiting[path]; seen {
-		return nil, fmt.Errorf("flow import cycle detected: %s", path)
+// loadDefinitionRecursive loads the flow at path and its imports. stack holds
+// the chain of files currently being imported so a file that imports itself,
+// directly or transitively, is reported with the full cycle.
+func loadDefinitionRecursive(path, baseDir string, stack []string, flowIDs map[string]string) (*Definition, error) {
+	for idx, importing := range stack {
+		if importing == path {
+			cycle := append(append([]string{}, stack[idx:]...), path)
+			return nil, fmt.Errorf("flow import cycle detected: %s", formatImportCycle(cycle, baseDir))
+		}
 	}
+	stack = append(stack, path)
 
-	visiting[path] = struct{}{}
-	defer delete(visiting, path)
-
-	content, err := os.ReadFile(path)
+	content, files, err := loadDefinitionContent(path, baseDir)
 	if err != nil {
-		return nil, fmt.Errorf("reading action flow %s: %w", path, err)
-	}
-
-	if err := validateDefinitionAgainstSchema(path, content); err != nil {
 		return nil, err
 	}
 
@@ -175,6 +274,7 @@ func loadDefinitionRecursive(path, baseDir string, visiting map[string]struct{},
 	for i := range def.Tasks {
 		def.Tasks[i].FlowID = def.ID
 	}
+	def.Files = files
 
 	var combined []Task
 	for idx, importPath := range def.Imports {
@@ -205,7 +305,7 @@ func loadDefinitionRecursive(path, baseDir string, visiting map[string]struct{},
 			return nil, fmt.Errorf("imports[%d]: resolving path %q: %w", idx, importPath, err)
 		}
 
-		importedDef, err := loadDefinitionRecursive(absImport, baseDir, visiting, flowIDs)
+		importedDef, err := loadDefinitionRecursive(absImport, baseDir, stack, flowIDs)
 		if err != nil {
 			return nil, fmt.Errorf("imports[%d]: loading %q: %w", idx, importPath, err)
 		}
@@ -218,6 +318,8 @@ func loadDefinitionRecursive(path, baseDir string, visiting map[string]struct{},
 		def.FlowImports[def.ID] = append(def.FlowImports[def.ID], importedDef.ID)
 		mergeFlowImports(def.FlowImports, importedDef.FlowImports)
 		mergeFlowNames(def.FlowNames, importedDef.FlowNames)
+		def.Files = appendUnique(def.Files, importedDef.Files)
+		def.MaskPatterns = append(def.MaskPatterns, importedDef.MaskPatterns...)
 	}
 
 	combined = append(combined, def.Tasks...)
@@ -226,6 +328,19 @@ func loadDefinitionRecursive(path, baseDir string, visiting map[string]struct{},
 	return &def, nil
 }
 
+// formatImportCycle renders an import chain as "a.json -> b.json -> a.json",
+// using paths relative to the main flow directory when possible.
+func formatImportCycle(cycle []string, baseDir string) string {
+	names := make([]string, len(cycle))
+	for i, path := range cycle {
+		names[i] = path
+		if rel, err := filepath.Rel(baseDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			names[i] = rel
+		}
+	}
+	return strings.Join(names, " -> ")
+}
+
 func mergeFlowImports(dst map[string][]string, src map[string][]string) {
 	if len(src) == 0 {
 		return
@@ -322,26 +437,60 @@ func (d *Definition) FlowsForExecution(flowID string) (map[string]struct{}, erro
 }
 
 func validateTasks(def *Definition) error {
-	ids := make(map[string]int)
 	for i := range def.Tasks {
 		task := &def.Tasks[i]
 
 		if strings.TrimSpace(task.ID) == "" {
 			return fmt.Errorf("tasks[%d]: id is required", i)
 		}
-		if prevIdx, exists := ids[task.ID]; exists {
-			return fmt.Errorf("tasks[%d]: id %q is duplicated (previously defined at tasks[%d])", i, task.ID, prevIdx)
-		}
-		ids[task.ID] = i
 
 		action := strings.TrimSpace(task.Action)
 		if action == "" {
 			return fmt.Errorf("tasks[%d]: action is required", i)
 		}
 
+		if task.TimeoutSeconds < 0 {
+			return fmt.Errorf("tasks[%d]: task_timeout_seconds must be greater than or equal to zero", i)
+		}
+		if err := task.Retry.validate(); err != nil {
+			return fmt.Errorf("tasks[%d]: %w", i, err)
+		}
+
 		task.Status = TaskStatusNotStarted
 	}
 
+	if err := checkDuplicateTaskIDs(def.Tasks); err != nil {
+		return err
+	}
+	if err := checkGoToTargets(def.Tasks); err != nil {
+		return err
+	}
+	if err := checkDependencies(def.Tasks); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(def.Execution)) {
+	case "", ExecutionLinear:
+		def.Execution = ExecutionLinear
+	case ExecutionDAG:
+		def.Execution = ExecutionDAG
+	default:
+		return fmt.Errorf("unsupported execution %q (expected %s or %s)", def.Execution, ExecutionLinear, ExecutionDAG)
+	}
+	if def.MaxConcurrency < 0 {
+		return fmt.Errorf("max_concurrency must be greater than or equal to zero")
+	}
+
+	if def.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must be greater than or equal to zero")
+	}
+
+	for i, pattern := range def.MaskPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("mask_patterns[%d]: %w", i, err)
+		}
+	}
+
 	trimmedOnError := strings.TrimSpace(def.OnErrorFlow)
 	if trimmedOnError != "" {
 		if len(def.FlowImports) == 0 {
@@ -352,6 +501,16 @@ func validateTasks(def *Definition) error {
 		}
 	}
 
+	trimmedOnSuccess := strings.TrimSpace(def.OnSuccessFlow)
+	if trimmedOnSuccess != "" {
+		if len(def.FlowImports) == 0 {
+			return fmt.Errorf("on_success_flow %q not found in flow metadata", trimmedOnSuccess)
+		}
+		if _, exists := def.FlowImports[trimmedOnSuccess]; !exists {
+			return fmt.Errorf("on_success_flow %q not found in flow definition", trimmedOnSuccess)
+		}
+	}
+
 	trimmedFinallyFlow := strings.TrimSpace(def.FinallyFlow)
 	if trimmedFinallyFlow != "" {
 		if len(def.FlowImports) == 0 {
@@ -378,3 +537,243 @@ func validateTasks(def *Definition) error {
 
 	return nil
 }
+
+// taskLocation identifies a task, top-level or nested, for validation errors.
+type taskLocation struct {
+	Task   Task
+	FlowID string
+	// Path is the task position within its own flow file, such as
+	// "tasks[2]" or "tasks[2].tasks[0]" for a nested task.
+	Path string
+	// Parent is the top-level task that contains a nested task; it is nil for
+	// top-level tasks.
+	Parent *Task
+}
+
+func (l taskLocation) String() string {
+	return fmt.Sprintf("flow %q %s", l.FlowID, l.Path)
+}
+
+// walkTasks calls fn for every merged task and, depth first, for the nested
+// tasks of control actions such as PARALLEL and FOR.
+func walkTasks(tasks []Task, fn func(taskLocation)) {
+	var walk func(task Task, flowID, path string, parent *Task)
+	walk = func(task Task, flowID, path string, parent *Task) {
+		fn(taskLocation{Task: task, FlowID: flowID, Path: path, Parent: parent})
+		if parent == nil {
+			parent = &task
+		}
+		for i, nested := range nestedTasks(task) {
+			walk(nested, flowID, fmt.Sprintf("%s.tasks[%d]", path, i), parent)
+		}
+	}
+
+	// Imported tasks are merged flow by flow, so counting per flow recovers
+	// each task's index within its own file.
+	flowIndexes := make(map[string]int)
+	for _, task := range tasks {
+		idx := flowIndexes[task.FlowID]
+		flowIndexes[task.FlowID] = idx + 1
+		walk(task, task.FlowID, fmt.Sprintf("tasks[%d]", idx), nil)
+	}
+}
+
+// checkDuplicateTaskIDs reports every task ID used more than once across the
+// merged tasks of a definition, including the nested tasks of control actions
+// such as PARALLEL and FOR. Each occurrence is listed with the flow that
+// defines it so duplicates coming from imports are easy to locate.
+func checkDuplicateTaskIDs(tasks []Task) error {
+	locations := make(map[string][]string)
+	var order []string
+	walkTasks(tasks, func(loc taskLocation) {
+		id := strings.TrimSpace(loc.Task.ID)
+		if id == "" {
+			return
+		}
+		if _, seen := locations[id]; !seen {
+			order = append(order, id)
+		}
+		locations[id] = append(locations[id], loc.String())
+	})
+
+	var duplicates []string
+	for _, id := range order {
+		if len(locations[id]) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%q (%s)", id, strings.Join(locations[id], ", ")))
+		}
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("duplicate task ids: %s", strings.Join(duplicates, "; "))
+	}
+	return nil
+}
+
+// checkGoToTargets reports EVALUATE branches whose gototask does not name a
+// top-level task. Jumps are resolved against the top-level task list, so a
+// target nested in PARALLEL or FOR can never be reached. Targets built from
+// placeholders are only known at run time and are skipped.
+func checkGoToTargets(tasks []Task) error {
+	topLevel := make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		topLevel[strings.TrimSpace(task.ID)] = struct{}{}
+	}
+	nestedIn := make(map[string]*Task)
+	walkTasks(tasks, func(loc taskLocation) {
+		if loc.Parent != nil {
+			nestedIn[strings.TrimSpace(loc.Task.ID)] = loc.Parent
+		}
+	})
+
+	var problems []string
+	walkTasks(tasks, func(loc taskLocation) {
+		if !strings.EqualFold(strings.TrimSpace(loc.Task.Action), evaluateActionName) {
+			return
+		}
+		var payload struct {
+			Then goToBranch `json:"then"`
+			Else goToBranch `json:"else"`
+		}
+		if err := json.Unmarshal(loc.Task.Payload, &payload); err != nil {
+			return
+		}
+
+		for _, branch := range []struct {
+			name   string
+			config goToBranch
+		}{{"then", payload.Then}, {"else", payload.Else}} {
+			field, target := branch.config.target()
+			if target == "" || strings.Contains(target, "${") {
+				continue
+			}
+			if _, ok := topLevel[target]; ok {
+				continue
+			}
+			where := fmt.Sprintf("%s (task %q) %s.%s %q", loc, loc.Task.ID, branch.name, field, target)
+			if parent, ok := nestedIn[target]; ok {
+				problems = append(problems, fmt.Sprintf("%s points inside %s task %q; only top-level tasks can be jump targets", where, strings.ToUpper(parent.Action), parent.ID))
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s does not match any task", where))
+		}
+	})
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid gototask: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkDependencies reports depends_on entries that do not name another
+// top-level task, depends_on declared on nested tasks, and dependency cycles.
+func checkDependencies(tasks []Task) error {
+	indexes := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		indexes[strings.TrimSpace(task.ID)] = i
+	}
+
+	var problems []string
+	walkTasks(tasks, func(loc taskLocation) {
+		if loc.Parent != nil && len(loc.Task.DependsOn) > 0 {
+			problems = append(problems, fmt.Sprintf("%s (task %q) is nested in %s task %q; only top-level tasks can declare depends_on", loc, loc.Task.ID, strings.ToUpper(loc.Parent.Action), loc.Parent.ID))
+		}
+	})
+
+	for _, task := range tasks {
+		for _, dependency := range task.DependsOn {
+			target := strings.TrimSpace(dependency)
+			if _, ok := indexes[target]; !ok {
+				problems = append(problems, fmt.Sprintf("task %q depends on %q, which does not match any top-level task", task.ID, target))
+			} else if target == strings.TrimSpace(task.ID) {
+				problems = append(problems, fmt.Sprintf("task %q depends on itself", task.ID))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid depends_on: %s", strings.Join(problems, "; "))
+	}
+
+	// Depth-first search; a dependency still on the stack closes a cycle.
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(tasks))
+	var stack []string
+	var visit func(idx int) error
+	visit = func(idx int) error {
+		switch state[idx] {
+		case done:
+			return nil
+		case visiting:
+			id := strings.TrimSpace(tasks[idx].ID)
+			start := slices.Index(stack, id)
+			return fmt.Errorf("depends_on cycle: %s", strings.Join(append(stack[start:], id), " -> "))
+		}
+		state[idx] = visiting
+		stack = append(stack, strings.TrimSpace(tasks[idx].ID))
+		for _, dependency := range tasks[idx].DependsOn {
+			if err := visit(indexes[strings.TrimSpace(dependency)]); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[idx] = done
+		return nil
+	}
+	for idx := range tasks {
+		if err := visit(idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluateActionName is the action whose branches may jump to another task.
+const evaluateActionName = "EVALUATE"
+
+// goToBranch holds the jump fields of an EVALUATE then/else branch.
+type goToBranch struct {
+	GoToTask   string `json:"gototask"`
+	GoToTaskID string `json:"gototaskid"`
+}
+
+// target returns the field used and the trimmed task ID; gototask wins over
+// gototaskid, matching the EVALUATE action.
+func (b goToBranch) target() (string, string) {
+	if target := strings.TrimSpace(b.GoToTask); target != "" {
+		return "gototask", target
+	}
+	return "gototaskid", strings.TrimSpace(b.GoToTaskID)
+}
+
+// nestedTasks returns the tasks declared in the "tasks" array of a control
+// action payload. Payloads without such an array yield nil.
+func nestedTasks(task Task) []Task {
+	if len(task.Payload) == 0 {
+		return nil
+	}
+	var payload struct {
+		Tasks []Task `json:"tasks"`
+	}
+	if err := json.Unmarshal(task.Payload, &payload); err != nil {
+		return nil
+	}
+	return payload.Tasks
+}
+
+// Timeout returns the flow-level execution limit, or zero when unbounded.
+func (d *Definition) Timeout() time.Duration {
+	if d == nil || d.TimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(d.TimeoutSeconds * float64(time.Second))
+}
+
+// Timeout returns the per-task execution limit, or zero when unbounded.
+func (t *Task) Timeout() time.Duration {
+	if t == nil || t.TimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(t.TimeoutSeconds * float64(time.Second))
+}
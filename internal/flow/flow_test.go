@@ -2,6 +2,7 @@ package flow
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -82,6 +83,7 @@ func RegisterSchemaFragmentForTesting(fragment []byte) {
 	testSchemaVersion++
 	testSchemaMu.Unlock()
 	schemaCache = sync.Map{}
+	resetDefinitionCache()
 }
 
 // ResetSchemaProviderForTesting clears the cached schema fragments so they can be reloaded.
@@ -93,9 +95,16 @@ func ResetSchemaProviderForTesting() {
 	testSchemaMu.Unlock()
 	testSchemaProviderOnce = sync.Once{}
 	schemaCache = sync.Map{}
+	resetDefinitionCache()
 	RegisterSchemaProvider(nil)
 }
 
+func resetDefinitionCache() {
+	definitionCache.Lock()
+	definitionCache.entries = make(map[string]*cachedDefinition)
+	definitionCache.Unlock()
+}
+
 // SchemaFragmentsForTesting returns the schema fragments currently configured for test validation.
 func SchemaFragmentsForTesting() [][]byte {
 	testSchemaMu.RLock()
@@ -129,6 +138,32 @@ func TestTaskUnmarshalStoresPayload(t *testing.T) {
 	}
 }
 
+func TestTaskUnmarshalKeepsLabelsApartFromActionTags(t *testing.T) {
+	setupSchemaProvider(t)
+	data := []byte(`{"id":"build","description":"build image","action":"DOCKER","operation":"IMAGE_BUILD","tags":["app:1"],"labels":["deploy"]}`)
+	var task Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		t.Fatalf("Task.UnmarshalJSON() error = %v", err)
+	}
+
+	if len(task.Labels) != 1 || task.Labels[0] != "deploy" {
+		t.Fatalf("task labels = %v, want [deploy]", task.Labels)
+	}
+}
+
+func TestTaskUnmarshalKeepsTaskTimeoutApartFromActionTimeout(t *testing.T) {
+	setupSchemaProvider(t)
+	data := []byte(`{"id":"call","description":"call api","action":"HTTP_REQUEST","timeout_seconds":5,"task_timeout_seconds":30}`)
+	var task Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		t.Fatalf("Task.UnmarshalJSON() error = %v", err)
+	}
+
+	if task.TimeoutSeconds != 30 {
+		t.Fatalf("task timeout = %v, want 30", task.TimeoutSeconds)
+	}
+}
+
 func TestLoadDefinitionInitializesTaskStatus(t *testing.T) {
 	setupSchemaProvider(t)
 	dir := t.TempDir()
@@ -269,6 +304,53 @@ func TestLoadDefinitionRejectsDuplicateIDs(t *testing.T) {
 	}
 }
 
+func TestLoadDefinitionRejectsInvalidRetryPolicy(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "flow.json")
+	content := []byte(`{"description":"test","id":"invalid.retry","name":"invalid.retry","tasks":[{"action":"SLEEP","description":"retry","id":"sleep","name":"sleep","seconds":1,"retry":{"max_attempts":0}}]}`)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write flow definition: %v", err)
+	}
+
+	if _, err := LoadDefinition(path); err == nil {
+		t.Fatal("LoadDefinition() error = nil, want error")
+	}
+}
+
+func TestLoadDefinitionRejectsInvalidMaskPattern(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "flow.json")
+	content := []byte(`{"description":"test","id":"invalid.mask","name":"invalid.mask","mask_patterns":["(unclosed"],"tasks":[{"action":"SLEEP","description":"sleep","id":"sleep","name":"sleep","seconds":1}]}`)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write flow definition: %v", err)
+	}
+
+	_, err := LoadDefinition(path)
+	if err == nil || !strings.Contains(err.Error(), "mask_patterns[0]") {
+		t.Fatalf("LoadDefinition() error = %v, want mask_patterns error", err)
+	}
+}
+
+func TestRetryPolicyDelayAppliesBackoff(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 4, DelaySeconds: 1, BackoffMultiplier: 2}
+
+	if got := policy.Attempts(); got != 4 {
+		t.Fatalf("Attempts() = %d, want 4", got)
+	}
+	for retry, want := range map[int]float64{1: 1, 2: 2, 3: 4} {
+		if got := policy.Delay(retry).Seconds(); got != want {
+			t.Fatalf("Delay(%d) = %vs, want %vs", retry, got, want)
+		}
+	}
+
+	var none *RetryPolicy
+	if got := none.Attempts(); got != 1 {
+		t.Fatalf("nil Attempts() = %d, want 1", got)
+	}
+}
+
 func TestLoadDefinitionUsesEmbeddedSchema(t *testing.T) {
 	setupSchemaProvider(t)
 	dir := t.TempDir()
@@ -400,9 +482,168 @@ func TestLoadDefinitionDetectsDuplicateIDsAcrossImports(t *testing.T) {
 		t.Fatalf("failed to write root flow: %v", err)
 	}
 
-	if _, err := LoadDefinition(rootPath); err == nil {
+	_, err := LoadDefinition(rootPath)
+	if err == nil {
 		t.Fatal("LoadDefinition() error = nil, want error")
 	}
+	for _, want := range []string{`"dup"`, `flow "dup.flow.imported" tasks[0]`, `flow "dup.flow.root" tasks[0]`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("LoadDefinition() error = %v, want it to mention %s", err, want)
+		}
+	}
+}
+
+func TestLoadDefinitionDetectsDuplicateNestedTaskIDs(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := t.TempDir()
+
+	importedPath := filepath.Join(dir, "imported.json")
+	importedContent := []byte(`{"description":"imported","id":"nested.imported","name":"nested.imported","tasks":[{"action":"SLEEP","description":"shared","id":"wait","name":"wait","seconds":1}]}`)
+	if err := os.WriteFile(importedPath, importedContent, 0o600); err != nil {
+		t.Fatalf("failed to write imported flow: %v", err)
+	}
+
+	rootPath := filepath.Join(dir, "flow.json")
+	rootContent := []byte(`{"description":"root","id":"nested.root","name":"nested.root","imports":["imported.json"],"tasks":[
+		{"action":"SLEEP","description":"first","id":"first","name":"first","seconds":1},
+		{"action":"PARALLEL","description":"fan out","id":"fan","name":"fan","tasks":[
+			{"action":"SLEEP","description":"dup of top level","id":"first","name":"first","seconds":1},
+			{"action":"SLEEP","description":"dup of import","id":"wait","name":"wait","seconds":1}
+		]}
+	]}`)
+	if err := os.WriteFile(rootPath, rootContent, 0o600); err != nil {
+		t.Fatalf("failed to write root flow: %v", err)
+	}
+
+	_, err := LoadDefinition(rootPath)
+	if err == nil {
+		t.Fatal("LoadDefinition() error = nil, want error")
+	}
+	for _, want := range []string{
+		`"wait" (flow "nested.imported" tasks[0], flow "nested.root" tasks[1].tasks[1])`,
+		`"first" (flow "nested.root" tasks[0], flow "nested.root" tasks[1].tasks[0])`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("LoadDefinition() error = %v, want it to contain %s", err, want)
+		}
+	}
+}
+
+func TestLoadDefinitionValidatesGoToTargets(t *testing.T) {
+	setupSchemaProvider(t)
+
+	evaluate := func(id, branch string) string {
+		return `{"action":"EVALUATE","description":"check","id":"` + id + `","name":"` + id + `","if_conditions":[{"left":"1","operation":"=","right":"1"}],"then":{"continue":"ok"},"else":` + branch + `}`
+	}
+	sleep := func(id string) string {
+		return `{"action":"SLEEP","description":"wait","id":"` + id + `","name":"` + id + `","seconds":1}`
+	}
+	parallel := func(id string, tasks ...string) string {
+		return `{"action":"PARALLEL","description":"fan out","id":"` + id + `","name":"` + id + `","tasks":[` + strings.Join(tasks, ",") + `]}`
+	}
+
+	tests := []struct {
+		name    string
+		tasks   []string
+		wantErr string
+	}{
+		{name: "top-level target", tasks: []string{sleep("start"), evaluate("check", `{"gototask":"start"}`)}},
+		{name: "placeholder target", tasks: []string{sleep("start"), evaluate("check", `{"gototaskid":"${next}"}`)}},
+		{
+			name:    "unknown target",
+			tasks:   []string{sleep("start"), evaluate("check", `{"gototaskid":"strat"}`)},
+			wantErr: `flow "goto.flow" tasks[1] (task "check") else.gototaskid "strat" does not match any task`,
+		},
+		{
+			name:    "nested evaluate with unknown target",
+			tasks:   []string{parallel("fan", evaluate("check", `{"gototask":"missing"}`))},
+			wantErr: `flow "goto.flow" tasks[0].tasks[0] (task "check") else.gototask "missing" does not match any task`,
+		},
+		{
+			name:    "target inside parallel",
+			tasks:   []string{parallel("fan", sleep("inner")), evaluate("check", `{"gototask":"inner"}`)},
+			wantErr: `else.gototask "inner" points inside PARALLEL task "fan"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "flow.json")
+			content := `{"description":"goto","id":"goto.flow","name":"goto.flow","tasks":[` + strings.Join(tt.tasks, ",") + `]}`
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("failed to write flow definition: %v", err)
+			}
+
+			_, err := LoadDefinition(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadDefinition() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadDefinition() error = %v, want it to contain %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadDefinitionValidatesDependencies(t *testing.T) {
+	setupSchemaProvider(t)
+
+	sleep := func(id string, dependsOn ...string) string {
+		deps := ""
+		if len(dependsOn) > 0 {
+			deps = `,"depends_on":["` + strings.Join(dependsOn, `","`) + `"]`
+		}
+		return `{"action":"SLEEP","description":"wait","id":"` + id + `","name":"` + id + `","seconds":1` + deps + `}`
+	}
+	parallel := func(id string, tasks ...string) string {
+		return `{"action":"PARALLEL","description":"fan out","id":"` + id + `","name":"` + id + `","tasks":[` + strings.Join(tasks, ",") + `]}`
+	}
+
+	tests := []struct {
+		name      string
+		execution string
+		tasks     []string
+		wantErr   string
+	}{
+		{name: "dag", execution: `"dag"`, tasks: []string{sleep("build"), sleep("test", "build"), sleep("lint"), sleep("deploy", "test", "lint")}},
+		{name: "linear with depends_on", tasks: []string{sleep("build"), sleep("test", "build")}},
+		{name: "unknown dependency", tasks: []string{sleep("build"), sleep("test", "biuld")}, wantErr: `task "test" depends on "biuld", which does not match any top-level task`},
+		{name: "self dependency", tasks: []string{sleep("build", "build")}, wantErr: `task "build" depends on itself`},
+		{name: "nested depends_on", tasks: []string{sleep("build"), parallel("fan", sleep("inner", "build"))}, wantErr: `(task "inner") is nested in PARALLEL task "fan"`},
+		{name: "cycle", tasks: []string{sleep("a", "c"), sleep("b", "a"), sleep("c", "b"), sleep("d", "a")}, wantErr: "depends_on cycle: a -> c -> b -> a"},
+		{name: "unknown execution", execution: `"graph"`, tasks: []string{sleep("build")}, wantErr: "execution"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "flow.json")
+			execution := ""
+			if tt.execution != "" {
+				execution = `"execution":` + tt.execution + `,`
+			}
+			content := `{"description":"deps",` + execution + `"id":"deps.flow","name":"deps.flow","tasks":[` + strings.Join(tt.tasks, ",") + `]}`
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("failed to write flow definition: %v", err)
+			}
+
+			def, err := LoadDefinition(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadDefinition() error = %v", err)
+				}
+				if want := strings.Trim(tt.execution, `"`); want != "" && def.Execution != want {
+					t.Fatalf("Execution = %q, want %q", def.Execution, want)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadDefinition() error = %v, want it to contain %s", err, tt.wantErr)
+			}
+		})
+	}
 }
 
 func TestLoadDefinitionDetectsImportCycles(t *testing.T) {
@@ -423,8 +664,33 @@ func TestLoadDefinitionDetectsImportCycles(t *testing.T) {
 
 	if _, err := LoadDefinition(rootPath); err == nil {
 		t.Fatal("LoadDefinition() error = nil, want error")
-	} else if !strings.Contains(err.Error(), "cycle") {
-		t.Fatalf("expected cycle error, got %v", err)
+	} else if !strings.Contains(err.Error(), "flow import cycle detected: flow.json -> second.json -> flow.json") {
+		t.Fatalf("expected cycle error naming the import chain, got %v", err)
+	}
+}
+
+func TestLoadDefinitionReportsNestedImportCycle(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("failed to create subdirectory: %v", err)
+	}
+
+	files := map[string]string{
+		"flow.json":  `{"description":"root","id":"chain.root","imports":["sub/b.json"],"name":"chain.root","tasks":[]}`,
+		"sub/b.json": `{"description":"b","id":"chain.b","imports":["sub/c.json"],"name":"chain.b","tasks":[]}`,
+		"sub/c.json": `{"description":"c","id":"chain.c","imports":["sub/b.json"],"name":"chain.c","tasks":[]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	_, err := LoadDefinition(filepath.Join(dir, "flow.json"))
+	want := filepath.Join("sub", "b.json") + " -> " + filepath.Join("sub", "c.json") + " -> " + filepath.Join("sub", "b.json")
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("LoadDefinition() error = %v, want cycle %s", err, want)
 	}
 }
 
@@ -518,3 +784,35 @@ func TestLoadDefinitionParallelWithoutTasksIsAccepted(t *testing.T) {
 		t.Fatalf("LoadDefinition() error = %v", err)
 	}
 }
+
+func TestResolveTaskReferenceWalksParallelResult(t *testing.T) {
+	tasks := []Task{{
+		ID:         "fanout",
+		Status:     TaskStatusCompleted,
+		ResultType: ResultTypeJSON,
+		Result: map[string]map[string]any{
+			"listpods": {"pods": []any{map[string]any{"name": "api-0"}}},
+		},
+	}}
+
+	got, err := ResolveTaskReference(tasks, "fanout.result.listpods.pods[0].name")
+	if err != nil {
+		t.Fatalf("ResolveTaskReference() error = %v", err)
+	}
+	if got != "api-0" {
+		t.Fatalf("ResolveTaskReference() = %#v, want api-0", got)
+	}
+
+	_, err = ResolveTaskReference(tasks, "fanout.result.listpods.pods.3.name")
+	var missing *MissingPathError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected MissingPathError, got %v", err)
+	}
+	if missing.Path != "listpods.pods.3" || !strings.Contains(missing.Reason, "out of range") {
+		t.Fatalf("unexpected missing path error: %+v", missing)
+	}
+
+	if _, err := ResolveTaskReference(tasks, "other.result"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected unknown task error, got %v", err)
+	}
+}
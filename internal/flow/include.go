@@ -0,0 +1,284 @@
+package flow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// includeKey marks a task list entry that is replaced by tasks from another
+// file: {"$include": "fragments.json#deploy", "id": "deploy-prod", "params":
+// {"env": "prod"}}.
+const includeKey = "$include"
+
+// resolveIncludes inlines the $include entries of every task list in the flow
+// at path, nested PARALLEL and FOR task lists included, so schema validation
+// and the engine only see regular tasks. Content without includes is returned
+// unchanged. The fragment files read are returned so the result can be cached
+// until one of them changes.
+func resolveIncludes(path, baseDir string, content []byte) ([]byte, []fileStamp, error) {
+	if !bytes.Contains(content, []byte(`"`+includeKey+`"`)) {
+		return content, nil, nil
+	}
+
+	var document map[string]any
+	if err := decodeJSON(content, &document); err != nil {
+		// Leave malformed documents to schema validation and parsing.
+		return content, nil, nil
+	}
+	tasks, ok := document["tasks"].([]any)
+	if !ok {
+		return content, nil, nil
+	}
+
+	resolver := &includeResolver{baseDir: baseDir, fragments: make(map[string][]any)}
+	expanded, err := resolver.expandTasks(tasks, filepath.Dir(path), "tasks", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	document["tasks"] = expanded
+
+	resolved, err := json.Marshal(document)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding resolved includes: %w", err)
+	}
+	return resolved, resolver.stamps, nil
+}
+
+type includeResolver struct {
+	baseDir   string
+	fragments map[string][]any
+	stamps    []fileStamp
+}
+
+// expandTasks replaces the includes in tasks. dir is the directory of the file
+// the tasks come from and stack the chain of includes being expanded, used to
+// report cycles.
+func (r *includeResolver) expandTasks(tasks []any, dir, location string, stack []string) ([]any, error) {
+	expanded := make([]any, 0, len(tasks))
+	for idx, item := range tasks {
+		itemLocation := fmt.Sprintf("%s[%d]", location, idx)
+		task, ok := item.(map[string]any)
+		if !ok {
+			expanded = append(expanded, item)
+			continue
+		}
+
+		if _, isInclude := task[includeKey]; isInclude {
+			included, err := r.include(task, dir, stack)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", itemLocation, err)
+			}
+			expanded = append(expanded, included...)
+			continue
+		}
+
+		if nested, ok := task["tasks"].([]any); ok {
+			nestedExpanded, err := r.expandTasks(nested, dir, itemLocation+".tasks", stack)
+			if err != nil {
+				return nil, err
+			}
+			task["tasks"] = nestedExpanded
+		}
+		expanded = append(expanded, task)
+	}
+	return expanded, nil
+}
+
+// include returns the tasks an $include entry refers to, with its params
+// substituted and its own includes expanded. "file.json#id" selects the
+// top-level task with that id, which the entry's "id" can rename so a fragment
+// is reusable several times; "file.json" selects every top-level task.
+func (r *includeResolver) include(entry map[string]any, dir string, stack []string) ([]any, error) {
+	ref, _ := entry[includeKey].(string)
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New(`$include must be a "file.json#taskId" string`)
+	}
+
+	for key := range entry {
+		if key != includeKey && key != "params" && key != "id" {
+			return nil, fmt.Errorf("$include %q: unsupported field %q (only \"id\" and \"params\" may accompany $include)", ref, key)
+		}
+	}
+
+	params := map[string]any{}
+	if raw, exists := entry["params"]; exists {
+		typed, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("$include %q: params must be an object", ref)
+		}
+		params = typed
+	}
+
+	file, taskID, _ := strings.Cut(ref, "#")
+	file = strings.TrimSpace(file)
+	taskID = strings.TrimSpace(taskID)
+	if file == "" {
+		return nil, fmt.Errorf("$include %q: file path is required", ref)
+	}
+	renamed, hasID := entry["id"]
+	if hasID {
+		if id, ok := renamed.(string); !ok || strings.TrimSpace(id) == "" {
+			return nil, fmt.Errorf("$include %q: id must be a non-empty string", ref)
+		}
+		if taskID == "" {
+			return nil, fmt.Errorf("$include %q: id can only rename a single task selected with #taskId", ref)
+		}
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("$include %q: resolving path: %w", ref, err)
+	}
+
+	key := file + "#" + taskID
+	for idx, including := range stack {
+		if including == key {
+			cycle := append(append([]string{}, stack[idx:]...), key)
+			return nil, fmt.Errorf("include cycle detected: %s", formatImportCycle(cycle, r.baseDir))
+		}
+	}
+
+	tasks, err := r.fragmentTasks(file)
+	if err != nil {
+		return nil, fmt.Errorf("$include %q: %w", ref, err)
+	}
+
+	selected := tasks
+	if taskID != "" {
+		selected = nil
+		for _, item := range tasks {
+			if task, ok := item.(map[string]any); ok {
+				if id, _ := task["id"].(string); strings.TrimSpace(id) == taskID {
+					selected = []any{task}
+					break
+				}
+			}
+		}
+		if selected == nil {
+			return nil, fmt.Errorf("$include %q: task %q not found in %s", ref, taskID, formatImportCycle([]string{file}, r.baseDir))
+		}
+	}
+
+	substituted, _ := substituteIncludeParams(cloneValue(selected), params).([]any)
+	if hasID {
+		if task, ok := substituted[0].(map[string]any); ok {
+			task["id"] = strings.TrimSpace(renamed.(string))
+		}
+	}
+	expanded, err := r.expandTasks(substituted, filepath.Dir(file), "tasks", append(stack, key))
+	if err != nil {
+		return nil, fmt.Errorf("$include %q: %w", ref, err)
+	}
+	return expanded, nil
+}
+
+// fragmentTasks returns the top-level tasks of a fragment file, which is
+// either a flow-like object with a "tasks" array or a bare array of tasks.
+func (r *includeResolver) fragmentTasks(path string) ([]any, error) {
+	if tasks, ok := r.fragments[path]; ok {
+		return tasks, nil
+	}
+
+	stamp, err := statFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fragment: %w", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fragment: %w", err)
+	}
+	r.stamps = append(r.stamps, stamp)
+
+	var document any
+	if err := decodeJSON(content, &document); err != nil {
+		return nil, fmt.Errorf("parsing fragment %s: %w", path, err)
+	}
+
+	var tasks []any
+	switch typed := document.(type) {
+	case []any:
+		tasks = typed
+	case map[string]any:
+		list, ok := typed["tasks"].([]any)
+		if !ok {
+			return nil, fmt.Errorf("fragment %s has no \"tasks\" array", path)
+		}
+		tasks = list
+	default:
+		return nil, fmt.Errorf("fragment %s must be a task array or an object with \"tasks\"", path)
+	}
+
+	r.fragments[path] = tasks
+	return tasks, nil
+}
+
+// substituteIncludeParams replaces ${name} with params[name] in every string
+// of value. A string that is exactly "${name}" takes the parameter value with
+// its JSON type. Placeholders without a matching parameter are left for
+// runtime expansion.
+func substituteIncludeParams(value any, params map[string]any) any {
+	if len(params) == 0 {
+		return value
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			typed[key] = substituteIncludeParams(item, params)
+		}
+		return typed
+	case []any:
+		for i, item := range typed {
+			typed[i] = substituteIncludeParams(item, params)
+		}
+		return typed
+	case string:
+		for name, param := range params {
+			placeholder := "${" + name + "}"
+			if typed == placeholder {
+				return cloneValue(param)
+			}
+			if strings.Contains(typed, placeholder) {
+				typed = strings.ReplaceAll(typed, placeholder, includeParamString(param))
+			}
+		}
+		return typed
+	default:
+		return typed
+	}
+}
+
+func includeParamString(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case json.Number:
+		return typed.String()
+	case nil:
+		return ""
+	case map[string]any, []any:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Sprint(typed)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+// decodeJSON decodes content keeping numbers as json.Number so re-encoding a
+// resolved flow does not lose integer precision.
+func decodeJSON(content []byte, target any) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	return decoder.Decode(target)
+}
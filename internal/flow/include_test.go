@@ -0,0 +1,157 @@
+package flow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeIncludeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDefinitionInlinesIncludedTasks(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := writeIncludeFiles(t, map[string]string{
+		"flow.json": `{"description":"root","id":"include.root","name":"include.root","tasks":[
+			{"$include":"fragments/common.json#wait","id":"wait-prod","params":{"label":"prod","seconds":2}},
+			{"action":"PARALLEL","id":"fan","name":"fan","tasks":[
+				{"$include":"fragments/common.json#wait","id":"wait-nested","params":{"label":"nested","seconds":1}}
+			]},
+			{"$include":"fragments/all.json"}
+		]}`,
+		"fragments/common.json": `{"tasks":[
+			{"action":"SLEEP","id":"wait","name":"wait ${label}","description":"keeps ${runtime}","seconds":"${seconds}"}
+		]}`,
+		"fragments/all.json": `[
+			{"action":"SLEEP","id":"first","name":"first","seconds":1},
+			{"$include":"common.json#wait","params":{"label":"chained","seconds":3}}
+		]`,
+	})
+
+	def, err := LoadDefinition(filepath.Join(dir, "flow.json"))
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+
+	var ids []string
+	for _, task := range def.Tasks {
+		ids = append(ids, task.ID)
+	}
+	if got, want := strings.Join(ids, ","), "wait-prod,fan,first,wait"; got != want {
+		t.Fatalf("task ids = %s, want %s", got, want)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(def.Tasks[0].Payload, &payload); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	if payload["seconds"] != float64(2) {
+		t.Fatalf("seconds = %#v, want the typed parameter 2", payload["seconds"])
+	}
+	if payload["name"] != "wait prod" {
+		t.Fatalf("name = %#v, want the substituted parameter", payload["name"])
+	}
+	if payload["description"] != "keeps ${runtime}" {
+		t.Fatalf("description = %#v, want unknown placeholders left for runtime", payload["description"])
+	}
+
+	nested := nestedTasks(def.Tasks[1])
+	if len(nested) != 1 || nested[0].ID != "wait-nested" {
+		t.Fatalf("nested tasks = %#v, want the included wait-nested task", nested)
+	}
+}
+
+func TestLoadDefinitionRejectsInvalidIncludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "missing fragment id",
+			files: map[string]string{
+				"flow.json": `{"description":"root","id":"root","name":"root","tasks":[{"$include":"frag.json#nope"}]}`,
+				"frag.json": `{"tasks":[{"action":"SLEEP","id":"wait","name":"wait","seconds":1}]}`,
+			},
+			wantErr: `tasks[0]: $include "frag.json#nope": task "nope" not found in frag.json`,
+		},
+		{
+			name: "missing fragment file",
+			files: map[string]string{
+				"flow.json": `{"description":"root","id":"root","name":"root","tasks":[{"$include":"missing.json#wait"}]}`,
+			},
+			wantErr: `$include "missing.json#wait": reading fragment`,
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"flow.json": `{"description":"root","id":"root","name":"root","tasks":[{"$include":"a.json#a"}]}`,
+				"a.json":    `[{"action":"PARALLEL","id":"a","name":"a","tasks":[{"$include":"b.json#b"}]}]`,
+				"b.json":    `[{"action":"PARALLEL","id":"b","name":"b","tasks":[{"$include":"a.json#a"}]}]`,
+			},
+			wantErr: "include cycle detected: a.json#a -> b.json#b -> a.json#a",
+		},
+		{
+			name: "unsupported field",
+			files: map[string]string{
+				"flow.json": `{"description":"root","id":"root","name":"root","tasks":[{"$include":"frag.json#wait","action":"SLEEP"}]}`,
+				"frag.json": `{"tasks":[{"action":"SLEEP","id":"wait","name":"wait","seconds":1}]}`,
+			},
+			wantErr: `unsupported field "action"`,
+		},
+	}
+
+	setupSchemaProvider(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeIncludeFiles(t, tt.files)
+			_, err := LoadDefinition(filepath.Join(dir, "flow.json"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadDefinition() error = %v, want it to contain %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadDefinitionListsFiles(t *testing.T) {
+	setupSchemaProvider(t)
+	dir := writeIncludeFiles(t, map[string]string{
+		"flow.json":                `{"description":"root","id":"files.root","name":"files.root","imports":["sub/sub.json"],"tasks":[{"$include":"fragments/common.json#wait"}]}`,
+		"sub/sub.json":             `{"description":"sub","id":"files.sub","name":"files.sub","tasks":[{"$include":"../fragments/common.json#other"},{"$include":"../fragments/sub.json"}]}`,
+		"fragments/common.json":    `[{"action":"SLEEP","id":"wait","name":"wait","seconds":1},{"action":"SLEEP","id":"other","name":"other","seconds":1}]`,
+		"fragments/sub.json":       `[{"action":"SLEEP","id":"sub-only","name":"sub-only","seconds":1}]`,
+		"fragments/unrelated.json": `[]`,
+	})
+
+	def, err := LoadDefinition(filepath.Join(dir, "flow.json"))
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+
+	var got []string
+	for _, path := range def.Files {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			t.Fatalf("Rel() error = %v", err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := "flow.json,fragments/common.json,sub/sub.json,fragments/sub.json"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("Files = %v, want %s", got, want)
+	}
+}
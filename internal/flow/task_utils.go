@@ -1,6 +1,12 @@
 package flow
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"flowk/internal/shared/jsonpathutil"
+)
 
 // FindTaskByID searches the provided slice of tasks for the entry matching the given identifier.
 // It trims leading and trailing spaces from the identifier before comparing task IDs.
@@ -18,3 +24,97 @@ func FindTaskByID(tasks []Task, id string) *Task {
 
 	return nil
 }
+
+// MissingPathError reports a task reference whose path does not exist in the
+// referenced task result, including array indices that are out of range.
+type MissingPathError struct {
+	TaskID string
+	Path   string
+	Reason string
+}
+
+func (e *MissingPathError) Error() string {
+	return fmt.Sprintf("task %q: path %q not found: %s", e.TaskID, e.Path, e.Reason)
+}
+
+// ResolveTaskReference resolves a dotted reference such as
+// "listpods.result.0.name" against the completed tasks. The longest prefix
+// matching a task ID selects the task; the remaining segments walk its result,
+// with numeric segments (or "[n]" suffixes) indexing arrays. A bare task ID
+// returns the whole result, and "success", "status" and "resulttype" expose
+// the task metadata. Paths that do not exist yield a *MissingPathError.
+func ResolveTaskReference(tasks []Task, ref string) (any, error) {
+	segments := splitReferencePath(ref)
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("placeholder is empty")
+	}
+
+	var task *Task
+	split := 0
+	for i := len(segments); i > 0; i-- {
+		if candidate := FindTaskByID(tasks, strings.Join(segments[:i], ".")); candidate != nil {
+			task, split = candidate, i
+			break
+		}
+	}
+	if task == nil {
+		return nil, fmt.Errorf("referenced task %q not found", strings.TrimSpace(ref))
+	}
+	if task.Status != TaskStatusCompleted {
+		return nil, fmt.Errorf("referenced task %q not completed", task.ID)
+	}
+
+	path := segments[split:]
+	if len(path) == 1 {
+		switch strings.ToLower(path[0]) {
+		case "success":
+			return task.Success, nil
+		case "status":
+			return string(task.Status), nil
+		case "resulttype":
+			return string(task.ResultType), nil
+		}
+	}
+	if len(path) > 0 && path[0] == "result" {
+		path = path[1:]
+	}
+
+	current := jsonpathutil.NormalizeContainer(task.Result)
+	for i, segment := range path {
+		walked := strings.Join(path[:i+1], ".")
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, &MissingPathError{TaskID: task.ID, Path: walked, Reason: fmt.Sprintf("key %q does not exist", segment)}
+			}
+			current = next
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, &MissingPathError{TaskID: task.ID, Path: walked, Reason: fmt.Sprintf("%q is not an array index", segment)}
+			}
+			if idx < 0 || idx >= len(node) {
+				return nil, &MissingPathError{TaskID: task.ID, Path: walked, Reason: fmt.Sprintf("index %d out of range (length %d)", idx, len(node))}
+			}
+			current = node[idx]
+		default:
+			return nil, &MissingPathError{TaskID: task.ID, Path: walked, Reason: fmt.Sprintf("cannot select %q from %T", segment, current)}
+		}
+	}
+
+	return current, nil
+}
+
+// splitReferencePath splits "a.b[0].c" into ["a", "b", "0", "c"].
+func splitReferencePath(ref string) []string {
+	normalized := strings.NewReplacer("[", ".", "]", "").Replace(strings.TrimSpace(ref))
+
+	var segments []string
+	for _, segment := range strings.Split(normalized, ".") {
+		if trimmed := strings.TrimSpace(segment); trimmed != "" {
+			segments = append(segments, trimmed)
+		}
+	}
+	return segments
+}
@@ -3,6 +3,7 @@ package flow
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -34,14 +35,70 @@ func validateDefinitionAgainstSchema(flowPath string, content []byte) error {
 		return nil
 	}
 
+	var document any
+	_ = json.Unmarshal(content, &document)
+
 	var messages []string
 	for _, validationErr := range result.Errors() {
-		messages = append(messages, validationErr.String())
+		if _, wrapper := wrapperSchemaErrors[validationErr.Type()]; wrapper {
+			continue
+		}
+		messages = append(messages, describeSchemaError(document, validationErr))
 	}
 
 	return fmt.Errorf("validating action flow: schema validation failed: %s", strings.Join(messages, "; "))
 }
 
+// wrapperSchemaErrors lists the errors gojsonschema adds on top of the nested
+// errors of an allOf or if/then/else. They only repeat that a combinator
+// failed, so they are dropped in favour of the field-level errors.
+var wrapperSchemaErrors = map[string]struct{}{
+	"number_all_of":  {},
+	"condition_then": {},
+	"condition_else": {},
+}
+
+// describeSchemaError renders a schema error, naming the task it belongs to
+// so errors in long or nested task lists are easy to locate.
+func describeSchemaError(document any, validationErr gojsonschema.ResultError) string {
+	field := validationErr.Field()
+	if id := taskIDForField(document, field); id != "" {
+		return fmt.Sprintf("%s (task %q): %s", field, id, validationErr.Description())
+	}
+	return validationErr.String()
+}
+
+// taskIDForField returns the id of the innermost task on a gojsonschema field
+// path such as "tasks.2.tasks.0.seconds", or "" when the path is not inside a
+// task.
+func taskIDForField(document any, field string) string {
+	var id string
+	current := document
+	segments := strings.Split(field, ".")
+	for i, segment := range segments {
+		switch typed := current.(type) {
+		case map[string]any:
+			current = typed[segment]
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(typed) {
+				return id
+			}
+			current = typed[idx]
+			if i > 0 && segments[i-1] == "tasks" {
+				if task, ok := current.(map[string]any); ok {
+					if taskID, ok := task["id"].(string); ok {
+						id = taskID
+					}
+				}
+			}
+		default:
+			return id
+		}
+	}
+	return id
+}
+
 func CombinedSchema() ([]byte, error) {
 	fragments, _ := schemaFragments()
 	return combineSchemaWithFragments(embeddedBaseSchema, fragments)
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -240,3 +241,41 @@ func TestValidateDefinitionAgainstSchema_SecretProviderVaultAction(t *testing.T)
 		t.Fatalf("flow validation failed: %v", err)
 	}
 }
+
+func TestValidateDefinitionAgainstSchema_ReportsFieldErrorsPerTask(t *testing.T) {
+	setFragments := installSchemaProviderHarness(t)
+
+	kubernetesFragment, err := os.ReadFile(filepath.Join("..", "actions", "infra", "kubernetes", "schema.json"))
+	if err != nil {
+		t.Fatalf("reading kubernetes fragment: %v", err)
+	}
+	setFragments(kubernetesFragment)
+
+	flowContent := []byte(`{
+      "description": "broken tasks",
+      "id": "broken",
+      "name": "broken",
+      "tasks": [
+        {"action": "KUBERNETES", "description": "bad operation", "id": "k8s.typo", "name": "k8s.typo", "operation": "SCAL", "context": "dev"},
+        {"action": "PARALLEL", "description": "fan out", "id": "fan", "name": "fan", "tasks": [
+          {"action": "KUBERNETES", "description": "bad replicas", "id": "k8s.scale", "name": "k8s.scale", "operation": "SCALE", "context": "dev", "namespace": "default", "deployments": ["api"], "replicas": "three"}
+        ]}
+      ]
+    }`)
+
+	err = validateDefinitionAgainstSchema("flow.json", flowContent)
+	if err == nil {
+		t.Fatal("validateDefinitionAgainstSchema() error = nil, want error")
+	}
+	for _, want := range []string{
+		`tasks.0.operation (task "k8s.typo"): `,
+		`tasks.1.tasks.0.replicas (task "k8s.scale"): Invalid type`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("validateDefinitionAgainstSchema() error = %v, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "Must validate") {
+		t.Fatalf("validateDefinitionAgainstSchema() error = %v, want combinator errors dropped", err)
+	}
+}
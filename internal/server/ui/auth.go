@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"crypto/subtle"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventsPath and eventsWebSocketPath carry run events. Browsers cannot set
+// headers on an EventSource or a WebSocket, so both also accept the token as
+// a query parameter.
+const (
+	eventsPath          = "/api/run/events"
+	eventsWebSocketPath = "/api/run/events/ws"
+)
+
+// requireToken rejects requests that do not carry the configured token as
+// "Authorization: Bearer <token>". lang is the default language of the
+// error message.
+func requireToken(token, lang string) gin.HandlerFunc {
+	expected := []byte(token)
+	return func(c *gin.Context) {
+		provided := bearerToken(c.GetHeader("Authorization"))
+		if provided == "" && isEventsPath(c.Request.URL.Path) {
+			provided = c.Query("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), expected) != 1 {
+			unauthorizedResponse(c, lang)
+			return
+		}
+		c.Next()
+	}
+}
+
+func isEventsPath(path string) bool {
+	return path == eventsPath || path == eventsWebSocketPath
+}
+
+func bearerToken(header string) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// isLoopbackAddress reports whether a listen address only accepts local
+// connections. An empty host listens on every interface.
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
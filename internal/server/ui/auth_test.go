@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAPIRequiresTokenWhenConfigured(t *testing.T) {
+	staticDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(staticDir, "index.html"), []byte("<html></html>"), 0o644); err != nil {
+		t.Fatalf("writing index: %v", err)
+	}
+
+	srv, err := NewServer(Config{
+		Address:   "127.0.0.1:0",
+		StaticDir: staticDir,
+		Token:     "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		auth   string
+		want   int
+	}{
+		{name: "missing token", method: http.MethodGet, target: "/api/openapi.json", want: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodGet, target: "/api/openapi.json", auth: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "wrong scheme", method: http.MethodGet, target: "/api/openapi.json", auth: "Basic s3cret", want: http.StatusUnauthorized},
+		{name: "bearer token", method: http.MethodGet, target: "/api/openapi.json", auth: "Bearer s3cret", want: http.StatusOK},
+		{name: "run without token", method: http.MethodPost, target: "/api/run/stop", want: http.StatusUnauthorized},
+		{name: "query token outside the event stream", method: http.MethodGet, target: "/api/openapi.json?token=s3cret", want: http.StatusUnauthorized},
+		{name: "event stream without token", method: http.MethodGet, target: "/api/run/events", want: http.StatusUnauthorized},
+		{name: "static assets stay public", method: http.MethodGet, target: "/", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			srv.Handle().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Fatalf("expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+func TestNewServerRequiresOptInForRemoteAddress(t *testing.T) {
+	if _, err := NewServer(Config{Address: "0.0.0.0:0"}); err == nil {
+		t.Fatalf("expected NewServer to refuse a non-loopback address without AllowRemote")
+	}
+	if _, err := NewServer(Config{Address: "0.0.0.0:0", AllowRemote: true, Token: "s3cret"}); err != nil {
+		t.Fatalf("NewServer with AllowRemote error: %v", err)
+	}
+	if _, err := NewServer(Config{Address: "localhost:0"}); err != nil {
+		t.Fatalf("NewServer on localhost error: %v", err)
+	}
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:8080": true,
+		"localhost:8080": true,
+		"[::1]:8080":     true,
+		"0.0.0.0:8080":   false,
+		":8080":          false,
+		"10.0.0.5:8080":  false,
+	}
+	for address, want := range tests {
+		if got := isLoopbackAddress(address); got != want {
+			t.Errorf("isLoopbackAddress(%q) = %v, want %v", address, got, want)
+		}
+	}
+}
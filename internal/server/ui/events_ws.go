@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = wsPongWait * 9 / 10
+	wsMaxMessageSize = 512
+)
+
+// eventsUpgrader keeps gorilla's default origin check, so only pages served
+// from the same host can open the socket.
+var eventsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// handleEventsWebSocket streams the same events as handleEvents over a
+// WebSocket, one JSON text frame per event. Some proxies buffer SSE
+// responses; WebSocket frames pass through them unchanged.
+func (s *Server) handleEventsWebSocket(c *gin.Context) {
+	if s.cfg.Hub == nil {
+		s.errorJSON(c, http.StatusServiceUnavailable, msgEventStreamUnavailable)
+		return
+	}
+
+	lastID, err := lastEventID(c)
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	stream, cancel, err := s.cfg.Hub.SubscribeAfter(lastID)
+	if err != nil {
+		s.errorJSON(c, http.StatusServiceUnavailable, msgTooManySubscribers)
+		return
+	}
+	defer cancel()
+
+	conn, err := eventsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already replied with an HTTP error.
+		return
+	}
+	defer conn.Close()
+
+	// Clients never send data, but the connection must be read to process
+	// pongs and to notice when the peer goes away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(wsMaxMessageSize)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case evt, ok := <-stream:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "event hub closed"))
+				return
+			}
+			if err := conn.WriteJSON(evt); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
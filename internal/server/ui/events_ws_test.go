@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"flowk/internal/app"
+)
+
+func TestEventsWebSocketStreamsHubEvents(t *testing.T) {
+	hub := NewEventHub()
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowStarted, FlowID: "demo"})
+
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", Hub: hub, Token: "s3cret"})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	httpSrv := httptest.NewServer(srv.Handle())
+	defer httpSrv.Close()
+
+	url := "ws" + strings.TrimPrefix(httpSrv.URL, "http") + eventsWebSocketPath
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got resp=%v err=%v", resp, err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=s3cret", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	readEvent := func() app.FlowEvent {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var evt app.FlowEvent
+		if err := conn.ReadJSON(&evt); err != nil {
+			t.Fatalf("ReadJSON: %v", err)
+		}
+		return evt
+	}
+
+	if evt := readEvent(); evt.Type != app.FlowEventFlowStarted || evt.FlowID != "demo" {
+		t.Fatalf("history event = %+v", evt)
+	}
+
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowFinished, FlowID: "demo"})
+	if evt := readEvent(); evt.Type != app.FlowEventFlowFinished {
+		t.Fatalf("live event = %+v", evt)
+	}
+
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.SubscriberCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscription still registered after disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -54,8 +54,10 @@ type FlowRunner struct {
 	stopSignal          *runcontext.StopSignal
 	stopAtTask          *runcontext.StopAtTask
 
-	mu      sync.Mutex
-	running bool
+	mu        sync.Mutex
+	running   bool
+	cancelRun context.CancelFunc
+	finished  chan struct{}
 }
 
 // NewFlowRunner creates a runner that executes flows using the provided context and observer.
@@ -101,7 +103,10 @@ func (r *FlowRunner) Start(options *RunOptions) (<-chan error, error) {
 	stopSignal := runcontext.NewStopSignal()
 	r.stopSignal = stopSignal
 	done := make(chan error, 1)
-	ctx := r.ctx
+	ctx, cancel := context.WithCancel(r.ctx)
+	finished := make(chan struct{})
+	r.cancelRun = cancel
+	r.finished = finished
 	observer := r.observer
 	logger := r.logger
 	beginFromTask := r.defaultBeginTaskID
@@ -133,7 +138,7 @@ func (r *FlowRunner) Start(options *RunOptions) (<-chan error, error) {
 
 	if resumeFromTaskID != "" {
 		if hasExplicitRunOption {
-			r.mu.Unlock()
+			r.abortStart(cancel)
 			return nil, ErrResumeConflict
 		}
 		beginFromTask = ""
@@ -141,17 +146,17 @@ func (r *FlowRunner) Start(options *RunOptions) (<-chan error, error) {
 		runFlowID = ""
 		runSubtaskID = ""
 		if r.lastRunState == nil || !r.lastRunState.HasData() {
-			r.mu.Unlock()
+			r.abortStart(cancel)
 			return nil, ErrNoRunState
 		}
 		if resumeFromTaskID != "" {
 			snapshot, ok := r.lastRunState.TaskSnapshot(resumeFromTaskID)
 			if !ok {
-				r.mu.Unlock()
+				r.abortStart(cancel)
 				return nil, ErrResumeTaskNotFound
 			}
 			if snapshot.Status != flow.TaskStatusCompleted {
-				r.mu.Unlock()
+				r.abortStart(cancel)
 				return nil, ErrResumeTaskNotCompleted
 			}
 			beginFromTask = resumeFromTaskID
@@ -170,7 +175,11 @@ func (r *FlowRunner) Start(options *RunOptions) (<-chan error, error) {
 			r.running = false
 			r.lastRunState = runState
 			r.stopSignal = nil
+			r.cancelRun = nil
+			r.finished = nil
 			r.mu.Unlock()
+			cancel()
+			close(finished)
 		}()
 
 		runCtx := ctx
@@ -189,6 +198,53 @@ func (r *FlowRunner) Start(options *RunOptions) (<-chan error, error) {
 	return done, nil
 }
 
+// abortStart undoes the run bookkeeping of a Start that fails its checks and
+// releases the runner lock.
+func (r *FlowRunner) abortStart(cancel context.CancelFunc) {
+	r.running = false
+	r.stopSignal = nil
+	r.cancelRun = nil
+	r.finished = nil
+	r.mu.Unlock()
+	cancel()
+}
+
+// Cancel cancels the context of the run in progress. Unlike RequestStop it
+// does not wait for the current task to complete.
+func (r *FlowRunner) Cancel() error {
+	if r == nil {
+		return ErrRunnerUnavailable
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.running || r.cancelRun == nil {
+		return ErrNoRunInProgress
+	}
+	r.cancelRun()
+	return nil
+}
+
+// Restart cancels the run in progress, if any, waits for it to end and
+// starts a new one.
+func (r *FlowRunner) Restart(options *RunOptions) (<-chan error, error) {
+	for {
+		done, err := r.Start(options)
+		if !errors.Is(err, ErrRunInProgress) {
+			return done, err
+		}
+
+		r.mu.Lock()
+		cancel, finished := r.cancelRun, r.finished
+		r.mu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+		if finished != nil {
+			<-finished
+		}
+	}
+}
+
 // Trigger launches a new run without waiting for the outcome.
 func (r *FlowRunner) Trigger(options *RunOptions) error {
 	_, err := r.Start(options)
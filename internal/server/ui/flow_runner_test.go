@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"flowk/internal/app"
+)
+
+func newSleepingRunner(t *testing.T) *FlowRunner {
+	t.Helper()
+	dir := t.TempDir()
+	flowPath := filepath.Join(dir, "flow.json")
+	content := `{"description":"sleep","id":"runner.sleep","name":"runner.sleep","tasks":[{"action":"SLEEP","description":"wait","id":"wait","name":"wait","seconds":30}]}`
+	if err := os.WriteFile(flowPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing flow: %v", err)
+	}
+	ctx := app.WithLogsDir(context.Background(), filepath.Join(dir, "logs"))
+	return NewFlowRunner(ctx, nil, flowPath, "", "", "", "", log.New(io.Discard, "", 0))
+}
+
+func waitRunResult(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not finish")
+		return nil
+	}
+}
+
+func TestFlowRunnerRestartCancelsRunInProgress(t *testing.T) {
+	runner := newSleepingRunner(t)
+	if err := runner.Cancel(); !errors.Is(err, ErrNoRunInProgress) {
+		t.Fatalf("Cancel() without a run error = %v, want %v", err, ErrNoRunInProgress)
+	}
+
+	first, err := runner.Start(nil)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	second, err := runner.Restart(nil)
+	if err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	if err := waitRunResult(t, first); !errors.Is(err, context.Canceled) {
+		t.Fatalf("first run error = %v, want context.Canceled", err)
+	}
+	if !runner.Running() {
+		t.Fatal("Running() = false after Restart")
+	}
+
+	if err := runner.Cancel(); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if err := waitRunResult(t, second); !errors.Is(err, context.Canceled) {
+		t.Fatalf("second run error = %v, want context.Canceled", err)
+	}
+}
+
+func TestFlowRunnerRejectedStartLeavesRunnerIdle(t *testing.T) {
+	runner := newSleepingRunner(t)
+
+	if _, err := runner.Start(&RunOptions{ResumeFromTaskID: "wait"}); !errors.Is(err, ErrNoRunState) {
+		t.Fatalf("Start() error = %v, want %v", err, ErrNoRunState)
+	}
+	if runner.Running() {
+		t.Fatal("Running() = true after a rejected start")
+	}
+}
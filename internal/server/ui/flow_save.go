@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"flowk/internal/flow"
+)
+
+type saveFlowRequest struct {
+	// Path is the destination relative to the save directory.
+	Path string `json:"path"`
+	// Flow is the flow definition to write.
+	Flow json.RawMessage `json:"flow"`
+	// Overwrite allows replacing an existing file.
+	Overwrite bool `json:"overwrite"`
+}
+
+// handleSaveFlow validates a posted flow definition and writes it below the
+// save directory, which must itself lie inside the working directory.
+func (s *Server) handleSaveFlow(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxFlowUploadSize+1))
+	if err != nil {
+		s.errorJSON(c, http.StatusBadRequest, msgReadFlowFailed, err)
+		return
+	}
+	if len(body) > maxFlowUploadSize {
+		s.errorJSON(c, http.StatusRequestEntityTooLarge, msgFlowTooLarge, maxFlowUploadSize)
+		return
+	}
+
+	var req saveFlowRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.errorJSON(c, http.StatusBadRequest, msgInvalidPayload, err)
+		return
+	}
+	if len(bytes.TrimSpace(req.Flow)) == 0 || bytes.Equal(bytes.TrimSpace(req.Flow), []byte("null")) {
+		s.errorJSON(c, http.StatusBadRequest, msgFlowRequired)
+		return
+	}
+
+	target, err := s.resolveSavePath(req.Path)
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	def, err := s.writeFlowFile(target, req.Flow, req.Overwrite)
+	if err != nil {
+		status := http.StatusBadRequest
+		if hasMessageKey(err, msgFlowExists) {
+			status = http.StatusConflict
+		}
+		s.errorResponse(c, status, err)
+		return
+	}
+
+	rel, err := filepath.Rel(s.fsRoot, target)
+	if err != nil {
+		rel = target
+	}
+	c.JSON(http.StatusOK, gin.H{"path": filepath.ToSlash(rel), "flow": buildFlowResponse(def)})
+}
+
+// resolveSavePath maps a relative destination to a .json file inside the
+// save directory.
+func (s *Server) resolveSavePath(relPath string) (string, error) {
+	trimmed := strings.TrimSpace(relPath)
+	if trimmed == "" {
+		return "", newAPIError(msgSavePathRequired)
+	}
+	if filepath.IsAbs(trimmed) || strings.HasPrefix(trimmed, "/") {
+		return "", newAPIError(msgSavePathNotRelative, trimmed)
+	}
+	if !strings.EqualFold(filepath.Ext(trimmed), ".json") {
+		return "", newAPIError(msgSavePathNotJSON, trimmed)
+	}
+
+	target := filepath.Join(s.saveDir, filepath.FromSlash(trimmed))
+	if err := ensureWithinRoot(s.saveDir, target); err != nil {
+		return "", newAPIError(msgSavePathOutsideSaveDir, trimmed)
+	}
+	if err := ensureWithinRoot(s.fsRoot, target); err != nil {
+		return "", newAPIError(msgSavePathOutsideRoot, trimmed)
+	}
+	return target, nil
+}
+
+// writeFlowFile validates data next to target, so relative imports resolve
+// from the final location, and then moves it into place.
+func (s *Server) writeFlowFile(target string, data []byte, overwrite bool) (*flow.Definition, error) {
+	dir := filepath.Dir(target)
+	if err := s.ensureRealPathWithinRoot(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, newAPIError(msgSaveDirFailed, err)
+	}
+
+	if info, err := os.Lstat(target); err == nil {
+		if !info.Mode().IsRegular() {
+			return nil, newAPIError(msgSaveTargetNotRegular, filepath.Base(target))
+		}
+		if !overwrite {
+			return nil, newAPIError(msgFlowExists, filepath.Base(target))
+		}
+	}
+
+	file, err := os.CreateTemp(dir, ".flow-save-*.json")
+	if err != nil {
+		return nil, newAPIError(msgSaveFlowFailed, err)
+	}
+	tmpName := file.Name()
+	defer os.Remove(tmpName)
+
+	content := bytes.TrimSpace(data)
+	if _, err := file.Write(append(content, '\n')); err != nil {
+		file.Close()
+		return nil, newAPIError(msgSaveFlowFailed, err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, newAPIError(msgSaveFlowFailed, err)
+	}
+
+	def, err := flow.LoadDefinition(tmpName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(tmpName, 0o644); err != nil {
+		return nil, newAPIError(msgSaveFlowFailed, err)
+	}
+	if err := os.Rename(tmpName, target); err != nil {
+		return nil, newAPIError(msgSaveFlowFailed, err)
+	}
+	return def, nil
+}
+
+// ensureRealPathWithinRoot resolves symlinks in the nearest existing
+// ancestor of dir and checks that it stays inside the working directory.
+// ensureWithinRoot only compares the paths as written.
+func (s *Server) ensureRealPathWithinRoot(dir string) error {
+	realRoot, err := filepath.EvalSymlinks(s.fsRoot)
+	if err != nil {
+		return newAPIError(msgSaveDirFailed, err)
+	}
+	existing := dir
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	realDir, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return newAPIError(msgSaveDirFailed, err)
+	}
+	if err := ensureWithinRoot(realRoot, realDir); err != nil {
+		return newAPIError(msgSaveDirOutsideRoot)
+	}
+	return nil
+}
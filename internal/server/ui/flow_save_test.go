@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const saveFlowJSON = `{
+	"id": "saved.flow",
+	"name": "saved.flow",
+	"description": "saved flow",
+	"tasks": [
+		{ "id": "t1", "name": "t1", "description": "task", "action": "PRINT", "entries": [{"message": "saved"}] }
+	]
+}`
+
+func postSaveFlow(t *testing.T, srv *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/flow/save", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	srv.Handle().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleSaveFlowWritesValidatedFlow(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", FlowRootDir: "flows"})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	rec := postSaveFlow(t, srv, `{"path": "team/saved.json", "flow": `+saveFlowJSON+`}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Path string `json:"path"`
+		Flow struct {
+			ID string `json:"id"`
+		} `json:"flow"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Path != "flows/team/saved.json" || resp.Flow.ID != "saved.flow" {
+		t.Fatalf("response = %+v", resp)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "flows", "team", "saved.json")); err != nil {
+		t.Fatalf("saved file missing: %v", err)
+	}
+
+	rec = postSaveFlow(t, srv, `{"path": "team/saved.json", "flow": `+saveFlowJSON+`}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("second save status = %d, want 409 (%s)", rec.Code, rec.Body.String())
+	}
+	rec = postSaveFlow(t, srv, `{"path": "team/saved.json", "overwrite": true, "flow": `+saveFlowJSON+`}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("overwrite status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+
+	entries, err := os.ReadDir(filepath.Join(repo, "flows", "team"))
+	if err != nil {
+		t.Fatalf("reading save dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("save dir has %d entries, want only the saved flow", len(entries))
+	}
+}
+
+func TestHandleSaveFlowRejectsInvalidRequests(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+	outside := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(repo, "flows"), 0o755); err != nil {
+		t.Fatalf("creating flows dir: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(repo, "flows", "escape")); err != nil {
+		t.Fatalf("creating symlink: %v", err)
+	}
+
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", FlowRootDir: "flows"})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "missing path", body: `{"flow": ` + saveFlowJSON + `}`, want: http.StatusBadRequest},
+		{name: "missing flow", body: `{"path": "a.json"}`, want: http.StatusBadRequest},
+		{name: "not json extension", body: `{"path": "a.yaml", "flow": ` + saveFlowJSON + `}`, want: http.StatusBadRequest},
+		{name: "absolute path", body: `{"path": "/tmp/a.json", "flow": ` + saveFlowJSON + `}`, want: http.StatusBadRequest},
+		{name: "parent traversal", body: `{"path": "../a.json", "flow": ` + saveFlowJSON + `}`, want: http.StatusBadRequest},
+		{name: "symlink escape", body: `{"path": "escape/a.json", "flow": ` + saveFlowJSON + `}`, want: http.StatusBadRequest},
+		{name: "symlink escape into new directory", body: `{"path": "escape/new/a.json", "flow": ` + saveFlowJSON + `}`, want: http.StatusBadRequest},
+		{name: "invalid flow", body: `{"path": "a.json", "flow": {"id": "x"}}`, want: http.StatusBadRequest},
+		{name: "too large", body: `{"path": "a.json", "flow": "` + strings.Repeat("x", maxFlowUploadSize) + `"}`, want: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postSaveFlow(t, srv, tt.body)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Fatalf("files written outside the working directory: %d", len(entries))
+	}
+	if _, err := os.Stat(filepath.Join(repo, "flows", "a.json")); !os.IsNotExist(err) {
+		t.Fatalf("invalid flow was saved: %v", err)
+	}
+}
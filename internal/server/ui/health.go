@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BuildInfo describes the running binary. It is reported by /api/version.
+type BuildInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+type healthResponse struct {
+	Status     string `json:"status"`
+	FlowLoaded bool   `json:"flowLoaded"`
+	Running    bool   `json:"running"`
+	// Subscribers counts the connected event stream clients.
+	Subscribers int `json:"subscribers"`
+}
+
+// handleHealthz answers readiness probes.
+func (s *Server) handleHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, healthResponse{
+		Status:      "ok",
+		FlowLoaded:  strings.TrimSpace(s.activeFlowPath()) != "",
+		Running:     s.runner.Running(),
+		Subscribers: s.subscriberCount(),
+	})
+}
+
+func (s *Server) handleVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, s.cfg.Build)
+}
+
+func (s *Server) subscriberCount() int {
+	if s.cfg.Hub == nil {
+		return 0
+	}
+	return s.cfg.Hub.SubscriberCount()
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHealthAndVersionSkipToken(t *testing.T) {
+	flowPath := filepath.Join(t.TempDir(), "demo.json")
+	if err := os.WriteFile(flowPath, []byte(`{"id": "demo"}`), 0o600); err != nil {
+		t.Fatalf("writing flow: %v", err)
+	}
+
+	srv, err := NewServer(Config{
+		Address:  "127.0.0.1:0",
+		FlowPath: flowPath,
+		Token:    "s3cret",
+		Build:    BuildInfo{Version: "1.2.3", Commit: "abc123", Date: "2026-01-02"},
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("healthz status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	var health healthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+		t.Fatalf("decoding healthz: %v", err)
+	}
+	if health != (healthResponse{Status: "ok", FlowLoaded: true, Running: false}) {
+		t.Fatalf("healthz = %+v", health)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("version status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	var build BuildInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &build); err != nil {
+		t.Fatalf("decoding version: %v", err)
+	}
+	if build != (BuildInfo{Version: "1.2.3", Commit: "abc123", Date: "2026-01-02"}) {
+		t.Fatalf("version = %+v", build)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/flows", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("flows status = %d, want 401", rec.Code)
+	}
+}
@@ -1,71 +1,181 @@
 package ui
 
 import (
+	"errors"
+	"sort"
 	"strings"
 	"sync"
 
 	"flowk/internal/app"
 )
 
+// maxEventHistory bounds the events kept for replay. Once the history grows
+// past it by a quarter, the oldest events are dropped.
+const maxEventHistory = 10000
+
+// DefaultMaxSubscribers bounds concurrent event stream clients unless
+// SetMaxSubscribers changes it.
+const DefaultMaxSubscribers = 64
+
+// ErrTooManySubscribers is returned by Subscribe when the hub already has
+// the maximum number of subscribers.
+var ErrTooManySubscribers = errors.New("too many event stream subscribers")
+
 type EventHub struct {
 	mu          sync.RWMutex
-	subscribers map[uint64]chan app.FlowEvent
+	subscribers map[uint64]*subscription
 	history     []app.FlowEvent
 	nextID      uint64
+	lastEventID uint64
+	maxSubs     int
+	// listeners see every event synchronously in Publish, without counting
+	// as subscribers or replaying the history.
+	listeners []func(app.FlowEvent)
+}
+
+// subscription queues events for one subscriber and feeds them to its
+// channel in order, so a slow reader never blocks Publish.
+type subscription struct {
+	ch      chan app.FlowEvent
+	mu      sync.Mutex
+	pending []app.FlowEvent
+	wake    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newSubscription(initial []app.FlowEvent) *subscription {
+	return &subscription{
+		ch:      make(chan app.FlowEvent, 32),
+		pending: initial,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *subscription) push(evt app.FlowEvent) {
+	s.mu.Lock()
+	s.pending = append(s.pending, evt)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscription) pump() {
+	defer close(s.ch)
+	for {
+		s.mu.Lock()
+		batch := s.pending
+		s.pending = nil
+		s.mu.Unlock()
+
+		for _, evt := range batch {
+			select {
+			case s.ch <- evt:
+			case <-s.done:
+				return
+			}
+		}
+
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *subscription) close() {
+	s.once.Do(func() { close(s.done) })
 }
 
 func NewEventHub() *EventHub {
 	return &EventHub{
-		subscribers: make(map[uint64]chan app.FlowEvent),
+		subscribers: make(map[uint64]*subscription),
+		maxSubs:     DefaultMaxSubscribers,
 	}
 }
 
+// SetMaxSubscribers changes the subscriber limit. Zero or less removes it.
+func (h *EventHub) SetMaxSubscribers(limit int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maxSubs = limit
+}
+
+// SubscriberCount reports the number of active subscribers.
+func (h *EventHub) SubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// Publish assigns the next event ID to event, records it in the history and
+// queues it for every subscriber.
 func (h *EventHub) Publish(event app.FlowEvent) {
 	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastEventID++
+	event.ID = h.lastEventID
 	h.history = append(h.history, event)
-	subscribers := make([]chan app.FlowEvent, 0, len(h.subscribers))
-	for _, ch := range h.subscribers {
-		subscribers = append(subscribers, ch)
+	if len(h.history) > maxEventHistory+maxEventHistory/4 {
+		h.history = append([]app.FlowEvent(nil), h.history[len(h.history)-maxEventHistory:]...)
 	}
-	h.mu.Unlock()
 
-	for _, ch := range subscribers {
-		select {
-		case ch <- event:
-		default:
-			go func(c chan app.FlowEvent, evt app.FlowEvent) {
-				c <- evt
-			}(ch, event)
-		}
+	for _, listener := range h.listeners {
+		listener(event)
+	}
+	for _, sub := range h.subscribers {
+		sub.push(event)
 	}
 }
 
-func (h *EventHub) Subscribe() (<-chan app.FlowEvent, func()) {
-	ch := make(chan app.FlowEvent, 32)
+// addListener registers fn for every event published from now on. fn runs
+// with the hub locked and must not call back into the hub.
+func (h *EventHub) addListener(fn func(app.FlowEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.listeners = append(h.listeners, fn)
+}
+
+// Subscribe replays the whole history and then streams new events.
+func (h *EventHub) Subscribe() (<-chan app.FlowEvent, func(), error) {
+	return h.SubscribeAfter(0)
+}
 
+// SubscribeAfter replays the recorded events whose ID is greater than
+// lastEventID and then streams new events. Events that have already been
+// dropped from the bounded history cannot be replayed. The returned cancel
+// function must be called once the subscriber stops reading.
+func (h *EventHub) SubscribeAfter(lastEventID uint64) (<-chan app.FlowEvent, func(), error) {
 	h.mu.Lock()
+	if h.maxSubs > 0 && len(h.subscribers) >= h.maxSubs {
+		h.mu.Unlock()
+		return nil, nil, ErrTooManySubscribers
+	}
+	start := sort.Search(len(h.history), func(i int) bool {
+		return h.history[i].ID > lastEventID
+	})
+	sub := newSubscription(append([]app.FlowEvent(nil), h.history[start:]...))
 	id := h.nextID
 	h.nextID++
-	history := append([]app.FlowEvent(nil), h.history...)
-	h.subscribers[id] = ch
+	h.subscribers[id] = sub
 	h.mu.Unlock()
 
-	go func(entries []app.FlowEvent) {
-		for _, evt := range entries {
-			ch <- evt
-		}
-	}(history)
+	go sub.pump()
 
 	cancel := func() {
 		h.mu.Lock()
-		if existing, ok := h.subscribers[id]; ok {
-			delete(h.subscribers, id)
-			close(existing)
-		}
+		delete(h.subscribers, id)
 		h.mu.Unlock()
+		sub.close()
 	}
 
-	return ch, cancel
+	return sub.ch, cancel, nil
 }
 
 func (h *EventHub) ClearHistory(flowID string) {
@@ -95,9 +205,9 @@ func (h *EventHub) Close() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	for id, ch := range h.subscribers {
+	for id, sub := range h.subscribers {
 		delete(h.subscribers, id)
-		close(ch)
+		sub.close()
 	}
 	h.subscribers = nil
 }
@@ -0,0 +1,251 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"flowk/internal/app"
+)
+
+func receive(t *testing.T, stream <-chan app.FlowEvent) app.FlowEvent {
+	t.Helper()
+	select {
+	case evt := <-stream:
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for event")
+		return app.FlowEvent{}
+	}
+}
+
+func TestEventHubSubscribeAfterReplaysMissedEvents(t *testing.T) {
+	hub := NewEventHub()
+	defer hub.Close()
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(app.FlowEvent{Type: app.FlowEventTaskLog, Message: string(rune('a' + i))})
+	}
+
+	stream, cancel, err := hub.SubscribeAfter(1)
+	if err != nil {
+		t.Fatalf("SubscribeAfter error: %v", err)
+	}
+	defer cancel()
+
+	if evt := receive(t, stream); evt.ID != 2 || evt.Message != "b" {
+		t.Fatalf("first replayed event = %+v, want id 2", evt)
+	}
+	if evt := receive(t, stream); evt.ID != 3 {
+		t.Fatalf("second replayed event = %+v, want id 3", evt)
+	}
+
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowFinished})
+	if evt := receive(t, stream); evt.ID != 4 || evt.Type != app.FlowEventFlowFinished {
+		t.Fatalf("live event = %+v, want id 4", evt)
+	}
+}
+
+func TestEventHubKeepsOrderForSlowSubscribers(t *testing.T) {
+	hub := NewEventHub()
+	defer hub.Close()
+
+	stream, cancel, err := hub.Subscribe()
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	defer cancel()
+
+	// Publish far more than the channel buffer before reading anything.
+	const total = 500
+	for i := 0; i < total; i++ {
+		hub.Publish(app.FlowEvent{Type: app.FlowEventTaskLog})
+	}
+	for want := uint64(1); want <= total; want++ {
+		if evt := receive(t, stream); evt.ID != want {
+			t.Fatalf("event id = %d, want %d", evt.ID, want)
+		}
+	}
+}
+
+func TestEventHubBoundsHistory(t *testing.T) {
+	hub := NewEventHub()
+	defer hub.Close()
+
+	total := maxEventHistory*2 + 7
+	for i := 0; i < total; i++ {
+		hub.Publish(app.FlowEvent{Type: app.FlowEventTaskLog})
+	}
+
+	hub.mu.RLock()
+	size := len(hub.history)
+	last := hub.history[size-1].ID
+	hub.mu.RUnlock()
+	if size < maxEventHistory || size > maxEventHistory+maxEventHistory/4 {
+		t.Fatalf("history size = %d, want between %d and %d", size, maxEventHistory, maxEventHistory+maxEventHistory/4)
+	}
+	if last != uint64(total) {
+		t.Fatalf("last event id = %d, want %d", last, total)
+	}
+}
+
+func TestEventHubCancelDuringPublish(t *testing.T) {
+	hub := NewEventHub()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Never reading forces deliveries onto the background path.
+			_, cancel, err := hub.Subscribe()
+			if err != nil {
+				t.Errorf("Subscribe error: %v", err)
+				return
+			}
+			time.Sleep(time.Millisecond)
+			cancel()
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		hub.Publish(app.FlowEvent{Type: app.FlowEventFlowStarted})
+	}
+	wg.Wait()
+	hub.Close()
+}
+
+func TestEventHubLimitsSubscribers(t *testing.T) {
+	hub := NewEventHub()
+	defer hub.Close()
+	hub.SetMaxSubscribers(2)
+
+	_, cancelFirst, err := hub.Subscribe()
+	if err != nil {
+		t.Fatalf("first Subscribe error: %v", err)
+	}
+	_, cancelSecond, err := hub.Subscribe()
+	if err != nil {
+		t.Fatalf("second Subscribe error: %v", err)
+	}
+	defer cancelSecond()
+
+	if _, _, err := hub.Subscribe(); !errors.Is(err, ErrTooManySubscribers) {
+		t.Fatalf("third Subscribe error = %v, want ErrTooManySubscribers", err)
+	}
+	if got := hub.SubscriberCount(); got != 2 {
+		t.Fatalf("SubscriberCount() = %d, want 2", got)
+	}
+
+	cancelFirst()
+	cancelFirst()
+	if got := hub.SubscriberCount(); got != 1 {
+		t.Fatalf("SubscriberCount() after cancel = %d, want 1", got)
+	}
+	_, cancelThird, err := hub.Subscribe()
+	if err != nil {
+		t.Fatalf("Subscribe after cancel error: %v", err)
+	}
+	cancelThird()
+}
+
+func TestEventsStreamRejectsAndReleasesSubscribers(t *testing.T) {
+	hub := NewEventHub()
+	hub.SetMaxSubscribers(1)
+
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", Hub: hub})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	httpSrv := httptest.NewServer(srv.Handle())
+	defer httpSrv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpSrv.URL+eventsPath, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	second, err := http.Get(httpSrv.URL + eventsPath)
+	if err != nil {
+		t.Fatalf("second GET events: %v", err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("second stream status = %d, want 503", second.StatusCode)
+	}
+
+	health := httptest.NewRecorder()
+	srv.Handle().ServeHTTP(health, httptest.NewRequest(http.MethodGet, "/api/healthz", nil))
+	if !strings.Contains(health.Body.String(), `"subscribers":1`) {
+		t.Fatalf("healthz = %s, want one subscriber", health.Body.String())
+	}
+
+	// Disconnecting the client must release its subscription.
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.SubscriberCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscription still registered after disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEventsStreamResumesFromLastEventID(t *testing.T) {
+	hub := NewEventHub()
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowStarted, FlowID: "demo"})
+	hub.Publish(app.FlowEvent{Type: app.FlowEventTaskStarted, FlowID: "demo"})
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowFinished, FlowID: "demo"})
+
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", Hub: hub})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	httpSrv := httptest.NewServer(srv.Handle())
+	defer httpSrv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpSrv.URL+eventsPath, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Last-Event-ID", "2")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) < 3 || lines[0] != "id:3" || lines[1] != "event:flow_finished" || !strings.Contains(lines[2], `"id":3`) {
+		t.Fatalf("first event = %q, want flow_finished with id 3", lines)
+	}
+
+	rec := httptest.NewRecorder()
+	bad := httptest.NewRequest(http.MethodGet, eventsPath, nil)
+	bad.Header.Set("Last-Event-ID", "abc")
+	srv.Handle().ServeHTTP(rec, bad)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid Last-Event-ID status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -60,7 +60,7 @@ func resolveLayoutDir(configPath string) string {
 func layoutFilePath(layoutDir, flowID, sourceName string) (string, error) {
 	trimmedID := strings.TrimSpace(flowID)
 	if trimmedID == "" {
-		return "", errors.New("flowId is required")
+		return "", newAPIError(msgFlowIDRequired)
 	}
 
 	trimmedSource := strings.TrimSpace(sourceName)
@@ -95,7 +95,7 @@ func sanitizeLayoutToken(value string) string {
 func (s *Server) handleGetLayout(c *gin.Context) {
 	layoutDir := s.layoutDir
 	if strings.TrimSpace(layoutDir) == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "layout storage is not configured"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutStorageUnconfigured)
 		return
 	}
 
@@ -103,17 +103,17 @@ func (s *Server) handleGetLayout(c *gin.Context) {
 	sourceName := strings.TrimSpace(c.Query("sourceName"))
 	path, err := layoutFilePath(layoutDir, flowID, sourceName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "layout not found"})
+			s.errorJSON(c, http.StatusNotFound, msgLayoutNotFound)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not read layout"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutReadFailed)
 		return
 	}
 
@@ -123,34 +123,34 @@ func (s *Server) handleGetLayout(c *gin.Context) {
 func (s *Server) handleSaveLayout(c *gin.Context) {
 	layoutDir := s.layoutDir
 	if strings.TrimSpace(layoutDir) == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "layout storage is not configured"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutStorageUnconfigured)
 		return
 	}
 
 	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLayoutPayloadSize))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read layout"})
+		s.errorJSON(c, http.StatusBadRequest, msgLayoutReadFailed)
 		return
 	}
 
 	if len(strings.TrimSpace(string(payload))) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "layout payload is empty"})
+		s.errorJSON(c, http.StatusBadRequest, msgLayoutEmpty)
 		return
 	}
 
 	var req layoutSaveRequest
 	if err := json.Unmarshal(payload, &req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid layout payload"})
+		s.errorJSON(c, http.StatusBadRequest, msgLayoutInvalid)
 		return
 	}
 
 	if strings.TrimSpace(req.FlowID) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "flowId is required"})
+		s.errorJSON(c, http.StatusBadRequest, msgFlowIDRequired)
 		return
 	}
 
 	if req.Snapshot.Version <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid layout version"})
+		s.errorJSON(c, http.StatusBadRequest, msgLayoutVersionInvalid)
 		return
 	}
 
@@ -160,7 +160,7 @@ func (s *Server) handleSaveLayout(c *gin.Context) {
 
 	if req.Snapshot.Viewport != nil {
 		if !isFinite(req.Snapshot.Viewport.X) || !isFinite(req.Snapshot.Viewport.Y) || !isFinite(req.Snapshot.Viewport.Zoom) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid viewport"})
+			s.errorJSON(c, http.StatusBadRequest, msgViewportInvalid)
 			return
 		}
 	}
@@ -171,31 +171,31 @@ func (s *Server) handleSaveLayout(c *gin.Context) {
 			continue
 		}
 		if !isFinite(pos.X) || !isFinite(pos.Y) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coordinates"})
+			s.errorJSON(c, http.StatusBadRequest, msgCoordinatesInvalid)
 			return
 		}
 	}
 
 	path, err := layoutFilePath(layoutDir, req.FlowID, req.SourceName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := os.MkdirAll(layoutDir, 0o700); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create layouts directory"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutDirFailed)
 		return
 	}
 
 	data, err := json.Marshal(req.Snapshot)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not serialize layout"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutSerializeFailed)
 		return
 	}
 
 	temp, err := os.CreateTemp(layoutDir, "layout-*.json")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save layout"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutSaveFailed)
 		return
 	}
 
@@ -203,24 +203,24 @@ func (s *Server) handleSaveLayout(c *gin.Context) {
 	if _, err := temp.Write(data); err != nil {
 		temp.Close()
 		_ = os.Remove(tempName)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save layout"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutSaveFailed)
 		return
 	}
 	if err := temp.Close(); err != nil {
 		_ = os.Remove(tempName)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save layout"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutSaveFailed)
 		return
 	}
 
 	if err := os.Rename(tempName, path); err != nil {
 		_ = os.Remove(tempName)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save layout"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutSaveFailed)
 		return
 	}
 
 	if err := os.Chmod(path, 0o600); err != nil {
 		_ = os.Remove(path)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save layout"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutSaveFailed)
 		return
 	}
 
@@ -230,7 +230,7 @@ func (s *Server) handleSaveLayout(c *gin.Context) {
 func (s *Server) handleDeleteLayout(c *gin.Context) {
 	layoutDir := s.layoutDir
 	if strings.TrimSpace(layoutDir) == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "layout storage is not configured"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutStorageUnconfigured)
 		return
 	}
 
@@ -238,7 +238,7 @@ func (s *Server) handleDeleteLayout(c *gin.Context) {
 	sourceName := strings.TrimSpace(c.Query("sourceName"))
 	path, err := layoutFilePath(layoutDir, flowID, sourceName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -247,7 +247,7 @@ func (s *Server) handleDeleteLayout(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"status": "ok"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete layout"})
+		s.errorJSON(c, http.StatusInternalServerError, msgLayoutDeleteFailed)
 		return
 	}
 
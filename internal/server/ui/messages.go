@@ -0,0 +1,326 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultLanguage is used when neither the request nor Config.Language
+// selects a supported language.
+const defaultLanguage = "en"
+
+// messageKey identifies a user-facing API error message in messageCatalog.
+type messageKey string
+
+const (
+	msgResourceNotFound          messageKey = "resource_not_found"
+	msgAuthRequired              messageKey = "auth_required"
+	msgEventStreamUnavailable    messageKey = "event_stream_unavailable"
+	msgInvalidLastEventID        messageKey = "invalid_last_event_id"
+	msgTooManySubscribers        messageKey = "too_many_subscribers"
+	msgInvalidPayload            messageKey = "invalid_payload"
+	msgReadFlowFailed            messageKey = "read_flow_failed"
+	msgFlowFileEmpty             messageKey = "flow_file_empty"
+	msgFlowTooLarge              messageKey = "flow_too_large"
+	msgFlowRequired              messageKey = "flow_required"
+	msgStoreFlowFailed           messageKey = "store_flow_failed"
+	msgUploadDirFailed           messageKey = "upload_dir_failed"
+	msgSourceNameRequired        messageKey = "source_name_required"
+	msgSourceNameNotRelative     messageKey = "source_name_not_relative"
+	msgFlowRootNotConfigured     messageKey = "flow_root_not_configured"
+	msgFlowRootUnreadable        messageKey = "flow_root_unreadable"
+	msgFlowRootNotDirectory      messageKey = "flow_root_not_directory"
+	msgFlowNotFound              messageKey = "flow_not_found"
+	msgFlowAccessFailed          messageKey = "flow_access_failed"
+	msgFlowIsDirectory           messageKey = "flow_is_directory"
+	msgNoFlowLoaded              messageKey = "no_flow_loaded"
+	msgNoNotes                   messageKey = "no_notes"
+	msgUnknownAction             messageKey = "unknown_action"
+	msgRunnerUnavailable         messageKey = "runner_unavailable"
+	msgRunInProgress             messageKey = "run_in_progress"
+	msgNoRunInProgress           messageKey = "no_run_in_progress"
+	msgNoRunState                messageKey = "no_run_state"
+	msgResumeConflict            messageKey = "resume_conflict"
+	msgResumeTaskNotFound        messageKey = "resume_task_not_found"
+	msgResumeTaskNotCompleted    messageKey = "resume_task_not_completed"
+	msgNoFlowReady               messageKey = "no_flow_ready"
+	msgLayoutStorageUnconfigured messageKey = "layout_storage_unconfigured"
+	msgLayoutNotFound            messageKey = "layout_not_found"
+	msgLayoutReadFailed          messageKey = "layout_read_failed"
+	msgLayoutEmpty               messageKey = "layout_empty"
+	msgLayoutInvalid             messageKey = "layout_invalid"
+	msgFlowIDRequired            messageKey = "flow_id_required"
+	msgLayoutVersionInvalid      messageKey = "layout_version_invalid"
+	msgViewportInvalid           messageKey = "viewport_invalid"
+	msgCoordinatesInvalid        messageKey = "coordinates_invalid"
+	msgLayoutDirFailed           messageKey = "layout_dir_failed"
+	msgLayoutSerializeFailed     messageKey = "layout_serialize_failed"
+	msgLayoutSaveFailed          messageKey = "layout_save_failed"
+	msgLayoutDeleteFailed        messageKey = "layout_delete_failed"
+	msgLogFlowInvalid            messageKey = "log_flow_invalid"
+	msgLogsDirUnreadable         messageKey = "logs_dir_unreadable"
+	msgNoActiveFlowLogs          messageKey = "no_active_flow_logs"
+	msgNoLogsFound               messageKey = "no_logs_found"
+	msgSavePathRequired          messageKey = "save_path_required"
+	msgSavePathNotRelative       messageKey = "save_path_not_relative"
+	msgSavePathNotJSON           messageKey = "save_path_not_json"
+	msgSavePathOutsideSaveDir    messageKey = "save_path_outside_save_dir"
+	msgSavePathOutsideRoot       messageKey = "save_path_outside_root"
+	msgSaveDirOutsideRoot        messageKey = "save_dir_outside_root"
+	msgSaveDirFailed             messageKey = "save_dir_failed"
+	msgSaveTargetNotRegular      messageKey = "save_target_not_regular"
+	msgFlowExists                messageKey = "flow_exists"
+	msgSaveFlowFailed            messageKey = "save_flow_failed"
+)
+
+// messageCatalog holds every user-facing API error message by language.
+// Entries may contain fmt verbs; the English catalog must list every key.
+var messageCatalog = map[string]map[messageKey]string{
+	"en": {
+		msgResourceNotFound:          "resource not found",
+		msgAuthRequired:              "missing or invalid auth token",
+		msgEventStreamUnavailable:    "event stream is not available",
+		msgInvalidLastEventID:        "invalid last event id %q",
+		msgTooManySubscribers:        "too many event stream clients are connected",
+		msgInvalidPayload:            "invalid payload: %v",
+		msgReadFlowFailed:            "could not read flow: %v",
+		msgFlowFileEmpty:             "flow file is empty",
+		msgFlowTooLarge:              "flow exceeds %d bytes",
+		msgFlowRequired:              "flow is required",
+		msgStoreFlowFailed:           "could not store flow: %v",
+		msgUploadDirFailed:           "could not prepare flow upload directory: %v",
+		msgSourceNameRequired:        "sourceName is required",
+		msgSourceNameNotRelative:     "sourceName %q must be a relative path",
+		msgFlowRootNotConfigured:     "flow root directory is not configured",
+		msgFlowRootUnreadable:        "could not read flow root directory %q: %v",
+		msgFlowRootNotDirectory:      "flow root directory %q is not a directory",
+		msgFlowNotFound:              "flow %q was not found",
+		msgFlowAccessFailed:          "could not access flow %q: %v",
+		msgFlowIsDirectory:           "flow %q is a directory",
+		msgNoFlowLoaded:              "no flow is currently loaded",
+		msgNoNotes:                   "no notes are available",
+		msgUnknownAction:             "action %q is not registered",
+		msgRunnerUnavailable:         "flow runner is not available",
+		msgRunInProgress:             "flow execution already in progress",
+		msgNoRunInProgress:           "no execution is currently in progress",
+		msgNoRunState:                "no previous run state is available to resume",
+		msgResumeConflict:            "resume request cannot be combined with other options",
+		msgResumeTaskNotFound:        "the requested task was not executed previously",
+		msgResumeTaskNotCompleted:    "the requested task has not finished yet",
+		msgNoFlowReady:               "no flow is ready to run yet",
+		msgLayoutStorageUnconfigured: "layout storage is not configured",
+		msgLayoutNotFound:            "layout not found",
+		msgLayoutReadFailed:          "could not read layout",
+		msgLayoutEmpty:               "layout payload is empty",
+		msgLayoutInvalid:             "invalid layout payload",
+		msgFlowIDRequired:            "flowId is required",
+		msgLayoutVersionInvalid:      "invalid layout version",
+		msgViewportInvalid:           "invalid viewport",
+		msgCoordinatesInvalid:        "invalid coordinates",
+		msgLayoutDirFailed:           "could not create layouts directory",
+		msgLayoutSerializeFailed:     "could not serialize layout",
+		msgLayoutSaveFailed:          "could not save layout",
+		msgLayoutDeleteFailed:        "could not delete layout",
+		msgLogFlowInvalid:            "flow must be a log directory name",
+		msgLogsDirUnreadable:         "could not read logs directory",
+		msgNoActiveFlowLogs:          "no active flow; pass ?flow=<name>",
+		msgNoLogsFound:               "no logs found for flow %q",
+		msgSavePathRequired:          "path is required",
+		msgSavePathNotRelative:       "path %q must be relative to the save directory",
+		msgSavePathNotJSON:           "path %q must end in .json",
+		msgSavePathOutsideSaveDir:    "path %q is outside the save directory",
+		msgSavePathOutsideRoot:       "path %q is outside the working directory",
+		msgSaveDirOutsideRoot:        "save directory resolves outside the working directory",
+		msgSaveDirFailed:             "could not prepare save directory: %v",
+		msgSaveTargetNotRegular:      "%s is not a regular file",
+		msgFlowExists:                "flow file already exists: %s",
+		msgSaveFlowFailed:            "could not save flow: %v",
+	},
+	"es": {
+		msgResourceNotFound:          "recurso no encontrado",
+		msgAuthRequired:              "falta el token de autenticación o no es válido",
+		msgEventStreamUnavailable:    "el flujo de eventos no está disponible",
+		msgInvalidLastEventID:        "identificador de último evento no válido %q",
+		msgTooManySubscribers:        "hay demasiados clientes conectados al flujo de eventos",
+		msgInvalidPayload:            "payload inválido: %v",
+		msgReadFlowFailed:            "no se pudo leer el flujo: %v",
+		msgFlowFileEmpty:             "el archivo del flujo está vacío",
+		msgFlowTooLarge:              "el flujo supera los %d bytes",
+		msgFlowRequired:              "el flujo es obligatorio",
+		msgStoreFlowFailed:           "no se pudo almacenar el flujo: %v",
+		msgUploadDirFailed:           "no se pudo preparar el directorio de subida de flujos: %v",
+		msgSourceNameRequired:        "sourceName es obligatorio",
+		msgSourceNameNotRelative:     "sourceName %q debe ser una ruta relativa",
+		msgFlowRootNotConfigured:     "el directorio raíz de flujos no está configurado",
+		msgFlowRootUnreadable:        "no se pudo leer el directorio raíz de flujos %q: %v",
+		msgFlowRootNotDirectory:      "el directorio raíz de flujos %q no es un directorio",
+		msgFlowNotFound:              "no se encontró el flujo %q",
+		msgFlowAccessFailed:          "no se pudo acceder al flujo %q: %v",
+		msgFlowIsDirectory:           "el flujo %q es un directorio",
+		msgNoFlowLoaded:              "no hay ningún flujo cargado",
+		msgNoNotes:                   "no hay notas disponibles",
+		msgUnknownAction:             "la acción %q no está registrada",
+		msgRunnerUnavailable:         "el ejecutor de flujos no está disponible",
+		msgRunInProgress:             "ya hay una ejecución del flujo en curso",
+		msgNoRunInProgress:           "no hay ninguna ejecución en curso",
+		msgNoRunState:                "no hay estado de una ejecución anterior para reanudar",
+		msgResumeConflict:            "la reanudación no se puede combinar con otras opciones",
+		msgResumeTaskNotFound:        "la tarea solicitada no se ejecutó anteriormente",
+		msgResumeTaskNotCompleted:    "la tarea solicitada aún no ha terminado",
+		msgNoFlowReady:               "todavía no hay ningún flujo listo para ejecutarse",
+		msgLayoutStorageUnconfigured: "el almacenamiento de diseños no está configurado",
+		msgLayoutNotFound:            "diseño no encontrado",
+		msgLayoutReadFailed:          "no se pudo leer el diseño",
+		msgLayoutEmpty:               "el diseño está vacío",
+		msgLayoutInvalid:             "diseño inválido",
+		msgFlowIDRequired:            "flowId es obligatorio",
+		msgLayoutVersionInvalid:      "versión de diseño inválida",
+		msgViewportInvalid:           "viewport inválido",
+		msgCoordinatesInvalid:        "coordenadas inválidas",
+		msgLayoutDirFailed:           "no se pudo crear el directorio de diseños",
+		msgLayoutSerializeFailed:     "no se pudo serializar el diseño",
+		msgLayoutSaveFailed:          "no se pudo guardar el diseño",
+		msgLayoutDeleteFailed:        "no se pudo eliminar el diseño",
+		msgLogFlowInvalid:            "flow debe ser el nombre de un directorio de logs",
+		msgLogsDirUnreadable:         "no se pudo leer el directorio de logs",
+		msgNoActiveFlowLogs:          "no hay ningún flujo activo; indique ?flow=<nombre>",
+		msgNoLogsFound:               "no se encontraron logs para el flujo %q",
+		msgSavePathRequired:          "path es obligatorio",
+		msgSavePathNotRelative:       "la ruta %q debe ser relativa al directorio de guardado",
+		msgSavePathNotJSON:           "la ruta %q debe terminar en .json",
+		msgSavePathOutsideSaveDir:    "la ruta %q está fuera del directorio de guardado",
+		msgSavePathOutsideRoot:       "la ruta %q está fuera del directorio de trabajo",
+		msgSaveDirOutsideRoot:        "el directorio de guardado apunta fuera del directorio de trabajo",
+		msgSaveDirFailed:             "no se pudo preparar el directorio de guardado: %v",
+		msgSaveTargetNotRegular:      "%s no es un archivo normal",
+		msgFlowExists:                "el archivo del flujo ya existe: %s",
+		msgSaveFlowFailed:            "no se pudo guardar el flujo: %v",
+	},
+}
+
+// apiError is an error whose text comes from messageCatalog, so handlers
+// can return it in the language of the request.
+type apiError struct {
+	key  messageKey
+	args []any
+}
+
+func newAPIError(key messageKey, args ...any) error {
+	return &apiError{key: key, args: args}
+}
+
+func (e *apiError) Error() string {
+	return translate(defaultLanguage, e.key, e.args...)
+}
+
+// hasMessageKey reports whether err is an apiError for key.
+func hasMessageKey(err error, key messageKey) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.key == key
+}
+
+// supportedLanguage maps a language tag such as "es-ES" to a catalog
+// language.
+func supportedLanguage(tag string) (string, bool) {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	if _, ok := messageCatalog[primary]; !ok {
+		return "", false
+	}
+	return primary, true
+}
+
+func translate(lang string, key messageKey, args ...any) string {
+	format, ok := messageCatalog[lang][key]
+	if !ok {
+		format = messageCatalog[defaultLanguage][key]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// requestLanguage picks the supported language the client prefers most in
+// Accept-Language, or fallback when it names none.
+func requestLanguage(c *gin.Context, fallback string) string {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if tag == "" || quality <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: tag, quality: quality})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	for _, cand := range candidates {
+		if lang, ok := supportedLanguage(cand.tag); ok {
+			return lang
+		}
+	}
+	return fallback
+}
+
+// errorJSON replies with a catalog message in the request's language.
+func (s *Server) errorJSON(c *gin.Context, status int, key messageKey, args ...any) {
+	c.JSON(status, gin.H{"error": translate(requestLanguage(c, s.language), key, args...)})
+}
+
+// runnerErrorMessages translates the FlowRunner sentinel errors.
+var runnerErrorMessages = []struct {
+	err error
+	key messageKey
+}{
+	{err: ErrRunInProgress, key: msgRunInProgress},
+	{err: ErrRunnerUnavailable, key: msgRunnerUnavailable},
+	{err: ErrNoRunInProgress, key: msgNoRunInProgress},
+	{err: ErrFlowPathRequired, key: msgNoFlowReady},
+	{err: ErrNoRunState, key: msgNoRunState},
+	{err: ErrResumeConflict, key: msgResumeConflict},
+	{err: ErrResumeTaskNotFound, key: msgResumeTaskNotFound},
+	{err: ErrResumeTaskNotCompleted, key: msgResumeTaskNotCompleted},
+}
+
+// errorResponse replies with err, translated when it is an apiError or a
+// FlowRunner sentinel error. Other errors, such as flow validation
+// failures, are returned as they are.
+func (s *Server) errorResponse(c *gin.Context, status int, err error) {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		s.errorJSON(c, status, apiErr.key, apiErr.args...)
+		return
+	}
+	for _, entry := range runnerErrorMessages {
+		if errors.Is(err, entry.err) {
+			s.errorJSON(c, status, entry.key)
+			return
+		}
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// unauthorizedResponse is used by requireToken, which runs before any
+// handler and only knows the configured default language.
+func unauthorizedResponse(c *gin.Context, fallback string) {
+	c.Header("WWW-Authenticate", `Bearer realm="flowk"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": translate(requestLanguage(c, fallback), msgAuthRequired)})
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMessageCatalogIsComplete(t *testing.T) {
+	english := messageCatalog[defaultLanguage]
+	for lang, messages := range messageCatalog {
+		if len(messages) != len(english) {
+			t.Errorf("%s catalog has %d messages, want %d", lang, len(messages), len(english))
+		}
+		for key, format := range messages {
+			want, ok := english[key]
+			if !ok {
+				t.Errorf("%s catalog has %q, which is missing in %s", lang, key, defaultLanguage)
+				continue
+			}
+			if strings.Count(format, "%") != strings.Count(want, "%") {
+				t.Errorf("%s message %q uses different format verbs than %s", lang, key, defaultLanguage)
+			}
+		}
+	}
+}
+
+func TestRequestLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		fallback string
+		want     string
+	}{
+		{header: "", fallback: "en", want: "en"},
+		{header: "", fallback: "es", want: "es"},
+		{header: "es-ES,es;q=0.9,en;q=0.8", fallback: "en", want: "es"},
+		{header: "fr-FR, en;q=0.5, es;q=0.7", fallback: "en", want: "es"},
+		{header: "fr, de;q=0.8", fallback: "es", want: "es"},
+		{header: "es;q=0, en;q=0.1", fallback: "es", want: "en"},
+		{header: "*", fallback: "en", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set("Accept-Language", tt.header)
+			if got := requestLanguage(c, tt.fallback); got != tt.want {
+				t.Fatalf("requestLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorsFollowLanguage(t *testing.T) {
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", Language: "es", Token: "s3cret"})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		target   string
+		language string
+		auth     bool
+		want     string
+	}{
+		{name: "configured default", target: "/api/flow/notes", auth: true, want: "no hay ningún flujo cargado"},
+		{name: "accept language wins", target: "/api/flow/notes", language: "en-US", auth: true, want: "no flow is currently loaded"},
+		{name: "api error", target: "/api/runs?flow=../x", language: "en", auth: true, want: "flow must be a log directory name"},
+		{name: "token middleware", target: "/api/flow/notes", want: "falta el token de autenticación o no es válido"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.language != "" {
+				req.Header.Set("Accept-Language", tt.language)
+			}
+			if tt.auth {
+				req.Header.Set("Authorization", "Bearer s3cret")
+			}
+			srv.Handle().ServeHTTP(rec, req)
+
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding response %q: %v", rec.Body.String(), err)
+			}
+			if body.Error != tt.want {
+				t.Fatalf("error = %q, want %q", body.Error, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewServer(Config{Address: "127.0.0.1:0", Language: "fr"}); err == nil {
+		t.Fatal("expected NewServer to reject an unsupported language")
+	}
+}
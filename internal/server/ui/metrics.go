@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"flowk/internal/app"
+)
+
+// metricsPath serves the Prometheus metrics when Config.Metrics is set.
+const metricsPath = "/metrics"
+
+// serverMetrics turns the events published on the hub into Prometheus
+// metrics. It uses its own registry so several servers, tests included, do
+// not collide in the global one.
+type serverMetrics struct {
+	registry     *prometheus.Registry
+	flows        *prometheus.CounterVec
+	tasks        *prometheus.CounterVec
+	taskDuration *prometheus.HistogramVec
+	inProgress   prometheus.Gauge
+
+	mu      sync.Mutex
+	running int
+}
+
+func newServerMetrics() *serverMetrics {
+	m := &serverMetrics{
+		registry: prometheus.NewRegistry(),
+		flows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "flowk_flows_total",
+			Help: "Flow runs that finished, by result.",
+		}, []string{"result"}),
+		tasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "flowk_tasks_total",
+			Help: "Tasks that finished, by action and status.",
+		}, []string{"action", "status"}),
+		taskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "flowk_task_duration_seconds",
+			Help:    "Duration of finished tasks, by action.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"action"}),
+		inProgress: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "flowk_runs_in_progress",
+			Help: "Flow runs that have started and not finished yet.",
+		}),
+	}
+	m.registry.MustRegister(
+		m.flows,
+		m.tasks,
+		m.taskDuration,
+		m.inProgress,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// observe updates the metrics for one hub event.
+func (m *serverMetrics) observe(event app.FlowEvent) {
+	switch event.Type {
+	case app.FlowEventFlowStarted:
+		m.mu.Lock()
+		m.running++
+		m.inProgress.Set(float64(m.running))
+		m.mu.Unlock()
+	case app.FlowEventFlowFinished:
+		result := "succeeded"
+		if event.Error != "" {
+			result = "failed"
+		}
+		m.flows.WithLabelValues(result).Inc()
+
+		// Flows that fail while loading finish without having started.
+		m.mu.Lock()
+		if m.running > 0 {
+			m.running--
+		}
+		m.inProgress.Set(float64(m.running))
+		m.mu.Unlock()
+	case app.FlowEventTaskCompleted, app.FlowEventTaskFailed:
+		if event.Task == nil {
+			return
+		}
+		status := "succeeded"
+		if event.Type == app.FlowEventTaskFailed {
+			status = "failed"
+		}
+		m.tasks.WithLabelValues(event.Task.Action, status).Inc()
+		m.taskDuration.WithLabelValues(event.Task.Action).Observe(event.Task.DurationSeconds)
+	}
+}
+
+func (m *serverMetrics) handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
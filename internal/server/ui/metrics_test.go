@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"flowk/internal/app"
+)
+
+func TestMetricsEndpointReportsHubEvents(t *testing.T) {
+	hub := NewEventHub()
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", Hub: hub, Token: "s3cret", Metrics: true})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowStarted, FlowID: "demo"})
+	hub.Publish(app.FlowEvent{Type: app.FlowEventTaskCompleted, FlowID: "demo", Task: &app.TaskSnapshot{ID: "a", Action: "SLEEP", DurationSeconds: 0.5}})
+	hub.Publish(app.FlowEvent{Type: app.FlowEventTaskFailed, FlowID: "demo", Task: &app.TaskSnapshot{ID: "b", Action: "SHELL", DurationSeconds: 2}})
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowStarted, FlowID: "other"})
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowFinished, FlowID: "demo", Error: "task b failed"})
+
+	rec := httptest.NewRecorder()
+	srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without token = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	srv.Handle().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (%s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`flowk_flows_total{result="failed"} 1`,
+		`flowk_tasks_total{action="SLEEP",status="succeeded"} 1`,
+		`flowk_tasks_total{action="SHELL",status="failed"} 1`,
+		`flowk_task_duration_seconds_count{action="SHELL"} 1`,
+		`flowk_runs_in_progress 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsEndpointDisabledByDefault(t *testing.T) {
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", Hub: NewEventHub()})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -15,6 +15,17 @@ func main() {
 			"description": "Contract between the FlowK UI and flowk run -serve-ui backend.",
 			"version":     "1.0.0",
 		},
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Required on every /api route when ui.token is configured. /api/run/events and /api/run/events/ws also accept it as the token query parameter.",
+				},
+			},
+		},
+		// The empty requirement keeps the API usable when no token is configured.
+		"security": []any{map[string]any{}, map[string]any{"bearerAuth": []any{}}},
 		"paths": map[string]any{
 			"/api/flows": map[string]any{
 				"get": map[string]any{"summary": "List available flow definitions", "responses": map[string]any{"200": map[string]any{"description": "Available flows"}}},
@@ -26,15 +37,24 @@ func main() {
 				"get":  map[string]any{"summary": "Get active flow definition", "responses": map[string]any{"200": map[string]any{"description": "Flow definition"}, "204": map[string]any{"description": "No flow loaded"}}},
 				"post": map[string]any{"summary": "Upload/import flow definition", "responses": map[string]any{"200": map[string]any{"description": "Imported flow"}}},
 			},
+			"/api/flow/save": map[string]any{
+				"post": map[string]any{"summary": "Validate a flow definition and save it below the save directory", "responses": map[string]any{"200": map[string]any{"description": "Saved path and flow"}, "400": map[string]any{"description": "Invalid path or flow"}, "409": map[string]any{"description": "File exists and overwrite is not set"}, "413": map[string]any{"description": "Payload too large"}}},
+			},
 			"/api/flow/notes": map[string]any{
 				"get": map[string]any{"summary": "Get flow notes markdown", "responses": map[string]any{"200": map[string]any{"description": "Notes"}, "404": map[string]any{"description": "No notes available"}}},
 			},
+			"/api/runs": map[string]any{
+				"get": map[string]any{"summary": "List the last recorded run of each flow from the logs directory", "responses": map[string]any{"200": map[string]any{"description": "Run summaries, newest first"}, "400": map[string]any{"description": "Invalid flow filter"}}},
+			},
 			"/api/schema": map[string]any{
 				"get": map[string]any{"summary": "Get combined flow schema", "responses": map[string]any{"200": map[string]any{"description": "Schema JSON"}}},
 			},
 			"/api/actions/guide": map[string]any{
 				"get": map[string]any{"summary": "Get actions guide", "responses": map[string]any{"200": map[string]any{"description": "Actions guide"}}},
 			},
+			"/api/actions/{name}": map[string]any{
+				"get": map[string]any{"summary": "Get the fields, operations and allowed values of one action", "parameters": []any{map[string]any{"name": "name", "in": "path", "required": true, "schema": map[string]any{"type": "string"}}}, "responses": map[string]any{"200": map[string]any{"description": "Action documentation"}, "404": map[string]any{"description": "Unknown action"}}},
+			},
 			"/api/run": map[string]any{
 				"post": map[string]any{"summary": "Start flow run", "responses": map[string]any{"202": map[string]any{"description": "Run started"}, "400": map[string]any{"description": "Invalid run request"}, "409": map[string]any{"description": "Run conflict"}}},
 			},
@@ -44,8 +64,14 @@ func main() {
 			"/api/run/stop-at": map[string]any{
 				"post": map[string]any{"summary": "Set/clear stop-at task", "responses": map[string]any{"200": map[string]any{"description": "Stop-at updated"}}},
 			},
+			"/api/run/logs.zip": map[string]any{
+				"get": map[string]any{"summary": "Download the log directory of the active flow, or of ?flow=<name>, as a zip", "responses": map[string]any{"200": map[string]any{"description": "application/zip"}, "400": map[string]any{"description": "Invalid flow filter"}, "404": map[string]any{"description": "No logs found"}}},
+			},
 			"/api/run/events": map[string]any{
-				"get": map[string]any{"summary": "Subscribe to runtime events (SSE)", "responses": map[string]any{"200": map[string]any{"description": "text/event-stream"}}},
+				"get": map[string]any{"summary": "Subscribe to runtime events (SSE), resuming after the Last-Event-ID header when present", "responses": map[string]any{"200": map[string]any{"description": "text/event-stream"}, "400": map[string]any{"description": "Invalid Last-Event-ID"}, "503": map[string]any{"description": "Event stream unavailable or too many clients"}}},
+			},
+			"/api/run/events/ws": map[string]any{
+				"get": map[string]any{"summary": "Subscribe to runtime events over a WebSocket, one JSON text frame per event, resuming after ?lastEventId=<id> when present", "responses": map[string]any{"101": map[string]any{"description": "Switching to the WebSocket protocol"}, "400": map[string]any{"description": "Not a WebSocket handshake or invalid lastEventId"}, "503": map[string]any{"description": "Event stream unavailable or too many clients"}}},
 			},
 			"/api/ui/layout": map[string]any{
 				"get":    map[string]any{"summary": "Get saved layout", "responses": map[string]any{"200": map[string]any{"description": "Layout snapshot"}, "404": map[string]any{"description": "Not found"}}},
@@ -55,6 +81,12 @@ func main() {
 			"/api/ui/close-flow": map[string]any{
 				"post": map[string]any{"summary": "Clear active flow from UI session", "responses": map[string]any{"200": map[string]any{"description": "Flow closed"}}},
 			},
+			"/api/healthz": map[string]any{
+				"get": map[string]any{"summary": "Report server health, whether a flow is loaded and whether a run is in progress", "security": []any{}, "responses": map[string]any{"200": map[string]any{"description": "Health status"}}},
+			},
+			"/api/version": map[string]any{
+				"get": map[string]any{"summary": "Get build version, commit and date", "security": []any{}, "responses": map[string]any{"200": map[string]any{"description": "Build information"}}},
+			},
 			"/api/openapi.json": map[string]any{
 				"get": map[string]any{"summary": "Get API contract", "responses": map[string]any{"200": map[string]any{"description": "OpenAPI spec"}}},
 			},
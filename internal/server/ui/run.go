@@ -2,11 +2,25 @@ package ui
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// selfSignedValidity is short because the certificate is regenerated on
+// every start.
+const selfSignedValidity = 30 * 24 * time.Hour
+
 type Runner struct {
 	server   *http.Server
 	listener net.Listener
@@ -16,6 +30,43 @@ func NewRunner(address string, handler http.Handler) *Runner {
 	return &Runner{server: &http.Server{Addr: address, Handler: handler}}
 }
 
+// EnableTLS serves HTTPS with the given certificate and key files. The pair
+// is loaded immediately so a bad file fails before the server starts.
+func (r *Runner) EnableTLS(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	r.server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert}}
+	return nil
+}
+
+// EnableSelfSignedTLS serves HTTPS with a certificate generated for
+// localhost, the loopback addresses and the configured host. It returns the
+// SHA-256 fingerprint of the certificate so users can check what their
+// browser is shown.
+func (r *Runner) EnableSelfSignedTLS() (string, error) {
+	host, _, err := net.SplitHostPort(r.server.Addr)
+	if err != nil {
+		host = r.server.Addr
+	}
+	cert, err := selfSignedCertificate(host, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("generating self-signed certificate: %w", err)
+	}
+	r.server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert}}
+	sum := sha256.Sum256(cert.Certificate[0])
+	return fmt.Sprintf("%X", sum[:]), nil
+}
+
+// Scheme returns "https" when TLS is enabled and "http" otherwise.
+func (r *Runner) Scheme() string {
+	if r != nil && r.server != nil && r.server.TLSConfig != nil {
+		return "https"
+	}
+	return "http"
+}
+
 func (r *Runner) Bind() error {
 	if r == nil || r.server == nil {
 		return nil
@@ -38,7 +89,14 @@ func (r *Runner) Start() error {
 	if err := r.Bind(); err != nil {
 		return err
 	}
-	if err := r.server.Serve(r.listener); err != nil && err != http.ErrServerClosed {
+	var err error
+	if r.server.TLSConfig != nil {
+		// The certificate is already in TLSConfig.
+		err = r.server.ServeTLS(r.listener, "", "")
+	} else {
+		err = r.server.Serve(r.listener)
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return err
 	}
 	return nil
@@ -52,3 +110,40 @@ func (r *Runner) Shutdown(ctx context.Context) error {
 	defer cancel()
 	return r.server.Shutdown(shutdownCtx)
 }
+
+func selfSignedCertificate(host string, now time.Time) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"FlowK"}, CommonName: "flowk-ui"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	host = strings.TrimSpace(host)
+	if ip := net.ParseIP(host); ip != nil {
+		if !ip.IsLoopback() && !ip.IsUnspecified() {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		}
+	} else if host != "" && !strings.EqualFold(host, "localhost") {
+		template.DNSNames = append(template.DNSNames, host)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
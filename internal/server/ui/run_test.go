@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"flowk/internal/app"
+)
+
+func TestRunnerServesEventsOverSelfSignedTLS(t *testing.T) {
+	hub := NewEventHub()
+	hub.Publish(app.FlowEvent{Type: app.FlowEventFlowStarted, FlowID: "demo"})
+
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", Hub: hub})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	runner := NewRunner("127.0.0.1:0", srv.Handle())
+	if _, err := runner.EnableSelfSignedTLS(); err != nil {
+		t.Fatalf("EnableSelfSignedTLS error: %v", err)
+	}
+	if runner.Scheme() != "https" {
+		t.Fatalf("Scheme() = %q, want https", runner.Scheme())
+	}
+	if err := runner.Bind(); err != nil {
+		t.Fatalf("Bind error: %v", err)
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- runner.Start() }()
+	defer func() {
+		if err := runner.Shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown error: %v", err)
+		}
+		if err := <-errCh; err != nil {
+			t.Errorf("Start error: %v", err)
+		}
+	}()
+
+	leaf, err := x509.ParseCertificate(runner.server.TLSConfig.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	tlsConfig := &tls.Config{RootCAs: roots}
+	address := runner.listener.Addr().String()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}, Timeout: 5 * time.Second}
+	resp, err := client.Get("https://" + address + "/api/openapi.json")
+	if err != nil {
+		t.Fatalf("GET over TLS: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	dialer := websocket.Dialer{TLSClientConfig: tlsConfig, HandshakeTimeout: 5 * time.Second}
+	conn, _, err := dialer.Dial("wss://"+address+eventsWebSocketPath, nil)
+	if err != nil {
+		t.Fatalf("dial wss: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var evt app.FlowEvent
+	if err := conn.ReadJSON(&evt); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	if evt.Type != app.FlowEventFlowStarted {
+		t.Fatalf("event = %+v, want flow_started", evt)
+	}
+}
+
+func TestRunnerEnableTLSRejectsMissingFiles(t *testing.T) {
+	runner := NewRunner("127.0.0.1:0", http.NotFoundHandler())
+	if err := runner.EnableTLS("missing-cert.pem", "missing-key.pem"); err == nil {
+		t.Fatal("expected error for missing certificate files")
+	}
+	if runner.Scheme() != "http" {
+		t.Fatalf("Scheme() = %q, want http", runner.Scheme())
+	}
+}
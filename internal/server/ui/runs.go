@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"flowk/internal/app"
+	"flowk/internal/flow"
+)
+
+const (
+	// maxRunsListed caps how many runs /api/runs returns, newest first.
+	maxRunsListed = 100
+	// maxRunTasksScanned caps the task directories read for a single run.
+	maxRunTasksScanned = 2000
+	// maxTaskLogSize skips task_log.json files too large to summarise.
+	maxTaskLogSize = 4 * 1024 * 1024
+)
+
+// runSummary describes the last recorded run of a flow. Each flow keeps a
+// single run in logs/<flow>, replaced when the flow runs again.
+type runSummary struct {
+	Flow         string          `json:"flow"`
+	Status       string          `json:"status"`
+	StartedAt    *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time      `json:"finishedAt,omitempty"`
+	LastModified time.Time       `json:"lastModified"`
+	Tasks        runTaskCounts   `json:"tasks"`
+	Truncated    bool            `json:"truncated,omitempty"`
+	Actions      map[string]int  `json:"actions,omitempty"`
+	Failed       []runFailedTask `json:"failed,omitempty"`
+}
+
+type runTaskCounts struct {
+	Total      int `json:"total"`
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+	Incomplete int `json:"incomplete"`
+	Unreadable int `json:"unreadable"`
+}
+
+type runFailedTask struct {
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+// taskLogSummary is the subset of task_log.json needed for a run summary.
+type taskLogSummary struct {
+	ID             string          `json:"id"`
+	Action         string          `json:"action"`
+	Status         flow.TaskStatus `json:"status"`
+	Success        bool            `json:"success"`
+	StartTimestamp time.Time       `json:"start_timestamp"`
+	EndTimestamp   time.Time       `json:"end_timestamp"`
+	Error          string          `json:"error"`
+}
+
+const maxFailedTasksListed = 20
+
+func (s *Server) handleRuns(c *gin.Context) {
+	flowFilter := strings.TrimSpace(c.Query("flow"))
+	if flowFilter != "" && !isPlainDirName(flowFilter) {
+		s.errorJSON(c, http.StatusBadRequest, msgLogFlowInvalid)
+		return
+	}
+
+	runs, truncated, err := listRuns(s.logsDir, flowFilter)
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":      runs,
+		"truncated": truncated,
+	})
+}
+
+// listRuns summarises every logs/<flow> directory, newest first. Entries
+// that are not plain directories, symlinks included, are skipped so the
+// scan never leaves the logs directory.
+func listRuns(logsDir, flowFilter string) ([]runSummary, bool, error) {
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []runSummary{}, false, nil
+		}
+		return nil, false, newAPIError(msgLogsDirUnreadable)
+	}
+
+	runs := make([]runSummary, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Type()&fs.ModeSymlink != 0 {
+			continue
+		}
+		if flowFilter != "" && entry.Name() != flowFilter {
+			continue
+		}
+		run, ok := summariseRun(filepath.Join(logsDir, entry.Name()), entry.Name())
+		if ok {
+			runs = append(runs, run)
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		if !runs[i].LastModified.Equal(runs[j].LastModified) {
+			return runs[i].LastModified.After(runs[j].LastModified)
+		}
+		return runs[i].Flow < runs[j].Flow
+	})
+
+	if len(runs) > maxRunsListed {
+		return runs[:maxRunsListed], true, nil
+	}
+	return runs, false, nil
+}
+
+// summariseRun reads the top-level task directories of a run. Tasks nested
+// in PARALLEL or FOR are counted through their parent task.
+func summariseRun(dir, name string) (runSummary, bool) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return runSummary{}, false
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return runSummary{}, false
+	}
+
+	run := runSummary{Flow: name, LastModified: info.ModTime().UTC()}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Type()&fs.ModeSymlink != 0 || !strings.HasPrefix(entry.Name(), "task-") {
+			continue
+		}
+		if run.Tasks.Total == maxRunTasksScanned {
+			run.Truncated = true
+			break
+		}
+		run.Tasks.Total++
+
+		taskDir := filepath.Join(dir, entry.Name())
+		if taskInfo, err := entry.Info(); err == nil && taskInfo.ModTime().After(run.LastModified) {
+			run.LastModified = taskInfo.ModTime().UTC()
+		}
+
+		summary, ok := readTaskLog(filepath.Join(taskDir, "task_log.json"))
+		if !ok {
+			run.Tasks.Unreadable++
+			continue
+		}
+		run.addTask(summary)
+	}
+
+	if run.Tasks.Total == 0 {
+		return runSummary{}, false
+	}
+	run.Status = run.Tasks.status()
+	return run, true
+}
+
+func (r *runSummary) addTask(task taskLogSummary) {
+	switch {
+	case task.Status != flow.TaskStatusCompleted:
+		r.Tasks.Incomplete++
+	case task.Success:
+		r.Tasks.Succeeded++
+	default:
+		r.Tasks.Failed++
+		if len(r.Failed) < maxFailedTasksListed {
+			r.Failed = append(r.Failed, runFailedTask{ID: task.ID, Error: task.Error})
+		}
+	}
+
+	if action := strings.TrimSpace(task.Action); action != "" {
+		if r.Actions == nil {
+			r.Actions = make(map[string]int)
+		}
+		r.Actions[action]++
+	}
+	if started := task.StartTimestamp; !started.IsZero() && (r.StartedAt == nil || started.Before(*r.StartedAt)) {
+		r.StartedAt = &started
+	}
+	if finished := task.EndTimestamp; !finished.IsZero() && (r.FinishedAt == nil || finished.After(*r.FinishedAt)) {
+		r.FinishedAt = &finished
+	}
+}
+
+func (c runTaskCounts) status() string {
+	switch {
+	case c.Failed > 0:
+		return "failed"
+	case c.Incomplete > 0 || c.Unreadable > 0:
+		return "incomplete"
+	default:
+		return "succeeded"
+	}
+}
+
+func readTaskLog(path string) (taskLogSummary, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxTaskLogSize {
+		return taskLogSummary{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return taskLogSummary{}, false
+	}
+	var summary taskLogSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return taskLogSummary{}, false
+	}
+	return summary, true
+}
+
+// isPlainDirName reports whether name is a single path element that cannot
+// point outside of its parent directory.
+func isPlainDirName(name string) bool {
+	return name != "." && name != ".." && !strings.ContainsAny(name, `/\`) && filepath.Base(name) == name && filepath.VolumeName(name) == ""
+}
+
+// handleRunLogsArchive streams a zip of a flow's log directory. The flow is
+// the one named by ?flow=, as listed by /api/runs, or the active flow.
+func (s *Server) handleRunLogsArchive(c *gin.Context) {
+	name := strings.TrimSpace(c.Query("flow"))
+	if name != "" {
+		if !isPlainDirName(name) {
+			s.errorJSON(c, http.StatusBadRequest, msgLogFlowInvalid)
+			return
+		}
+	} else {
+		flowPath := s.activeFlowPath()
+		if strings.TrimSpace(flowPath) == "" {
+			s.errorJSON(c, http.StatusNotFound, msgNoActiveFlowLogs)
+			return
+		}
+		name = app.FlowLogsDirName(flowPath)
+	}
+
+	dir := filepath.Join(s.logsDir, name)
+	info, err := os.Lstat(dir)
+	if err != nil || !info.IsDir() {
+		s.errorJSON(c, http.StatusNotFound, msgNoLogsFound, name)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + "-logs.zip"}))
+	c.Status(http.StatusOK)
+
+	// The status is already sent, so a failure can only cut the archive
+	// short; the truncated zip is rejected by the client.
+	if err := writeLogsZip(c.Writer, dir, name); err != nil {
+		log.Printf("streaming logs of flow %q: %v", name, err)
+	}
+}
+
+// writeLogsZip writes every regular file below dir to w, prefixed with
+// root. Symlinks are skipped so the archive only contains files that live
+// inside the log directory.
+func writeLogsZip(w io.Writer, dir, root string) error {
+	zw := zip.NewWriter(w)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = root + "/" + filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+
+		out, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(out, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
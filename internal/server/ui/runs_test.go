@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func writeTaskLog(t *testing.T, dir string, payload map[string]any) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("creating task dir: %v", err)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal task log: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "task_log.json"), data, 0o644); err != nil {
+		t.Fatalf("writing task log: %v", err)
+	}
+}
+
+func TestHandleRunsSummarisesLogsDirectory(t *testing.T) {
+	logsDir := t.TempDir()
+
+	deploy := filepath.Join(logsDir, "deploy")
+	writeTaskLog(t, filepath.Join(deploy, "task-0000-build"), map[string]any{
+		"id": "build", "action": "SHELL", "status": "completed", "success": true,
+		"start_timestamp": "2026-01-02T10:00:00Z", "end_timestamp": "2026-01-02T10:01:00Z",
+	})
+	writeTaskLog(t, filepath.Join(deploy, "task-0001-push"), map[string]any{
+		"id": "push", "action": "HTTP", "status": "completed", "success": false, "error": "boom",
+		"start_timestamp": "2026-01-02T10:01:00Z", "end_timestamp": "2026-01-02T10:02:00Z",
+	})
+	writeTaskLog(t, filepath.Join(deploy, "task-0001-push", "task_parallel", "task-0002-nested"), map[string]any{
+		"id": "nested", "action": "PRINT", "status": "completed", "success": true,
+	})
+	if err := os.MkdirAll(filepath.Join(deploy, "task-0003-broken"), 0o755); err != nil {
+		t.Fatalf("creating broken task dir: %v", err)
+	}
+
+	smoke := filepath.Join(logsDir, "smoke")
+	writeTaskLog(t, filepath.Join(smoke, "task-0000-ping"), map[string]any{
+		"id": "ping", "action": "HTTP", "status": "completed", "success": true,
+	})
+	if err := os.MkdirAll(filepath.Join(logsDir, "empty"), 0o755); err != nil {
+		t.Fatalf("creating empty flow dir: %v", err)
+	}
+
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", LogsDir: logsDir})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := get("/api/runs")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	var payload struct {
+		Runs      []runSummary `json:"runs"`
+		Truncated bool         `json:"truncated"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(payload.Runs) != 2 || payload.Truncated {
+		t.Fatalf("expected two runs, got %+v", payload)
+	}
+
+	var run runSummary
+	for _, candidate := range payload.Runs {
+		if candidate.Flow == "deploy" {
+			run = candidate
+		}
+	}
+	want := runTaskCounts{Total: 3, Succeeded: 1, Failed: 1, Unreadable: 1}
+	if run.Tasks != want {
+		t.Fatalf("tasks = %+v, want %+v", run.Tasks, want)
+	}
+	if run.Status != "failed" || len(run.Failed) != 1 || run.Failed[0].ID != "push" || run.Failed[0].Error != "boom" {
+		t.Fatalf("unexpected failure summary: %+v", run)
+	}
+	if run.StartedAt == nil || run.FinishedAt == nil || run.FinishedAt.Sub(*run.StartedAt).Minutes() != 2 {
+		t.Fatalf("unexpected run window: %v - %v", run.StartedAt, run.FinishedAt)
+	}
+	if run.Actions["SHELL"] != 1 || run.Actions["HTTP"] != 1 {
+		t.Fatalf("unexpected action counts: %v", run.Actions)
+	}
+
+	rec = get("/api/runs?flow=smoke")
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decoding filtered response: %v", err)
+	}
+	if len(payload.Runs) != 1 || payload.Runs[0].Flow != "smoke" || payload.Runs[0].Status != "succeeded" {
+		t.Fatalf("unexpected filtered runs: %+v", payload.Runs)
+	}
+
+	for _, target := range []string{"/api/runs?flow=..", "/api/runs?flow=../etc", "/api/runs?flow=a%2Fb"} {
+		if rec := get(target); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestHandleRunsWithoutLogsDirectory(t *testing.T) {
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", LogsDir: filepath.Join(t.TempDir(), "missing")})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/runs", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"runs":[],"truncated":false}` {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleRunLogsArchiveStreamsZip(t *testing.T) {
+	logsDir := t.TempDir()
+	flowDir := filepath.Join(logsDir, "my_flow")
+	writeTaskLog(t, filepath.Join(flowDir, "task-0000-first"), map[string]any{"id": "first"})
+	writeTaskLog(t, filepath.Join(flowDir, "task-0001-loop", "task-0002-child"), map[string]any{"id": "child"})
+	if err := os.WriteFile(filepath.Join(flowDir, "task-0000-first", "environment_variables.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatalf("writing variables: %v", err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatalf("writing outside file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(flowDir, "link.txt")); err != nil {
+		t.Fatalf("creating symlink: %v", err)
+	}
+
+	srv, err := NewServer(Config{Address: "127.0.0.1:0", LogsDir: logsDir, FlowPath: filepath.Join("flows", "my flow.json")})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	for _, target := range []string{"/api/run/logs.zip", "/api/run/logs.zip?flow=my_flow"} {
+		rec := httptest.NewRecorder()
+		srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d (%s)", target, rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename=my_flow-logs.zip` {
+			t.Fatalf("%s: Content-Disposition = %q", target, got)
+		}
+
+		reader, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+		if err != nil {
+			t.Fatalf("%s: reading zip: %v", target, err)
+		}
+		var names []string
+		for _, file := range reader.File {
+			names = append(names, file.Name)
+		}
+		sort.Strings(names)
+		want := []string{
+			"my_flow/task-0000-first/environment_variables.json",
+			"my_flow/task-0000-first/task_log.json",
+			"my_flow/task-0001-loop/task-0002-child/task_log.json",
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Fatalf("%s: entries = %v, want %v", target, names, want)
+		}
+	}
+
+	for target, want := range map[string]int{
+		"/api/run/logs.zip?flow=..":      http.StatusBadRequest,
+		"/api/run/logs.zip?flow=missing": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != want {
+			t.Fatalf("%s: expected status %d, got %d", target, want, rec.Code)
+		}
+	}
+}
@@ -7,17 +7,21 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"log"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 
+	"flowk/internal/app"
 	actionhelp "flowk/internal/cli/actionhelp"
 	"flowk/internal/flow"
 )
@@ -25,6 +29,8 @@ import (
 const maxFlowUploadSize = 5 * 1024 * 1024
 const maxFlowNotesSize = 1 * 1024 * 1024
 const defaultFlowRootDir = "./flows"
+const sseHeartbeatInterval = 15 * time.Second
+const sseWriteTimeout = 10 * time.Second
 
 var errImportLocated = errors.New("flow import located")
 var errImportNotFound = errors.New("flow import not found")
@@ -38,6 +44,25 @@ type Config struct {
 	Runner        *FlowRunner
 	FlowUploadDir string
 	ConfigPath    string
+	// Token, when set, is required as a bearer token on every /api route.
+	// Static assets stay public.
+	Token string
+	// AllowRemote permits an Address that is not a loopback address.
+	AllowRemote bool
+	// LogsDir is scanned by /api/runs. It defaults to the engine's logs
+	// directory below the working directory.
+	LogsDir string
+	// FlowSaveDir receives flows posted to /api/flow/save. It defaults to
+	// the flow root directory and must lie inside the working directory.
+	FlowSaveDir string
+	// Language is the default language of API error messages, "en" or
+	// "es". A supported language in Accept-Language takes precedence.
+	Language string
+	// Build is reported by /api/version.
+	Build BuildInfo
+	// Metrics serves Prometheus metrics on /metrics, fed from the Hub
+	// events. The token, when set, guards it like the /api routes.
+	Metrics bool
 }
 
 type Server struct {
@@ -52,8 +77,12 @@ type Server struct {
 	uploadDir        string
 	fsRoot           string
 	layoutDir        string
+	logsDir          string
+	saveDir          string
+	language         string
 	importCache      map[string]string
 	importCacheMu    sync.RWMutex
+	metrics          *serverMetrics
 }
 
 func NewServer(cfg Config) (*Server, error) {
@@ -61,6 +90,28 @@ func NewServer(cfg Config) (*Server, error) {
 		return nil, errors.New("address is required")
 	}
 
+	cfg.Token = strings.TrimSpace(cfg.Token)
+	language := defaultLanguage
+	if trimmed := strings.TrimSpace(cfg.Language); trimmed != "" {
+		lang, ok := supportedLanguage(trimmed)
+		if !ok {
+			return nil, fmt.Errorf("unsupported UI language %q", cfg.Language)
+		}
+		language = lang
+	}
+	if !isLoopbackAddress(cfg.Address) {
+		// /api/run executes any flow, SHELL and SSH tasks included, so
+		// listening beyond loopback has to be requested explicitly.
+		if !cfg.AllowRemote {
+			return nil, fmt.Errorf("refusing to listen on non-loopback address %s: set ui.allow_remote: true in config.yaml to expose the UI server", cfg.Address)
+		}
+		if cfg.Token == "" {
+			log.Printf("WARNING: UI server on %s is reachable from other hosts WITHOUT authentication and can run any flow; set ui.token in config.yaml or FLOWK_UI_TOKEN", cfg.Address)
+		} else {
+			log.Printf("WARNING: UI server on %s is reachable from other hosts; API requests require the configured token", cfg.Address)
+		}
+	}
+
 	uploadDir := strings.TrimSpace(cfg.FlowUploadDir)
 	if uploadDir == "" {
 		uploadDir = filepath.Join(os.TempDir(), "flowk-ui")
@@ -85,6 +136,7 @@ func NewServer(cfg Config) (*Server, error) {
 		runner:      cfg.Runner,
 		uploadDir:   uploadDir,
 		fsRoot:      workingDir,
+		language:    language,
 		importCache: make(map[string]string),
 	}
 	flowRootDir := strings.TrimSpace(cfg.FlowRootDir)
@@ -96,36 +148,79 @@ func NewServer(cfg Config) (*Server, error) {
 	}
 	srv.flowRootDir = filepath.Clean(flowRootDir)
 	srv.layoutDir = resolveLayoutDir(cfg.ConfigPath)
+	logsDir := strings.TrimSpace(cfg.LogsDir)
+	if logsDir == "" {
+		logsDir = app.LogsDir
+	}
+	if !filepath.IsAbs(logsDir) {
+		logsDir = filepath.Join(workingDir, logsDir)
+	}
+	srv.logsDir = filepath.Clean(logsDir)
+	saveDir := strings.TrimSpace(cfg.FlowSaveDir)
+	if saveDir == "" {
+		saveDir = srv.flowRootDir
+	}
+	if !filepath.IsAbs(saveDir) {
+		saveDir = filepath.Join(workingDir, saveDir)
+	}
+	srv.saveDir = filepath.Clean(saveDir)
 	srv.setActiveFlowPath(strings.TrimSpace(cfg.FlowPath), false, "")
+	if cfg.Metrics {
+		srv.metrics = newServerMetrics()
+		if cfg.Hub != nil {
+			cfg.Hub.addListener(srv.metrics.observe)
+		}
+	}
 	srv.registerRoutes()
 
 	return srv, nil
 }
 
 func (s *Server) registerRoutes() {
-	s.engine.GET("/api/flows", s.handleFlows)
-	s.engine.POST("/api/flows/open", s.handleOpenFlow)
-	s.engine.GET("/api/flow", s.handleFlow)
-	s.engine.GET("/api/flow/notes", s.handleFlowNotes)
-	s.engine.GET("/api/schema", s.handleSchema)
-	s.engine.GET("/api/actions/guide", s.handleActionsGuide)
-	s.engine.GET("/api/openapi.json", s.handleOpenAPI)
-	s.engine.GET("/api/run/events", s.handleEvents)
-	s.engine.POST("/api/flow", s.handleImportFlow)
-	s.engine.POST("/api/run", s.handleRun)
-	s.engine.POST("/api/run/stop", s.handleStop)
-	s.engine.POST("/api/run/stop-at", s.handleStopAtTask)
-	s.engine.POST("/api/ui/close-flow", s.handleCloseFlow)
-	s.engine.GET("/api/ui/layout", s.handleGetLayout)
-	s.engine.POST("/api/ui/layout", s.handleSaveLayout)
-	s.engine.DELETE("/api/ui/layout", s.handleDeleteLayout)
+	// Probes and version checks work without the token.
+	s.engine.GET("/api/healthz", s.handleHealthz)
+	s.engine.GET("/api/version", s.handleVersion)
+
+	if s.metrics != nil {
+		handlers := []gin.HandlerFunc{s.metrics.handler()}
+		if s.cfg.Token != "" {
+			handlers = append([]gin.HandlerFunc{requireToken(s.cfg.Token, s.language)}, handlers...)
+		}
+		s.engine.GET(metricsPath, handlers...)
+	}
+
+	api := s.engine.Group("/api")
+	if s.cfg.Token != "" {
+		api.Use(requireToken(s.cfg.Token, s.language))
+	}
+	api.GET("/flows", s.handleFlows)
+	api.POST("/flows/open", s.handleOpenFlow)
+	api.GET("/flow", s.handleFlow)
+	api.GET("/flow/notes", s.handleFlowNotes)
+	api.GET("/runs", s.handleRuns)
+	api.GET("/schema", s.handleSchema)
+	api.GET("/actions/guide", s.handleActionsGuide)
+	api.GET("/actions/:name", s.handleActionDocumentation)
+	api.GET("/openapi.json", s.handleOpenAPI)
+	api.GET("/run/events", s.handleEvents)
+	api.GET("/run/events/ws", s.handleEventsWebSocket)
+	api.POST("/flow", s.handleImportFlow)
+	api.POST("/flow/save", s.handleSaveFlow)
+	api.POST("/run", s.handleRun)
+	api.POST("/run/stop", s.handleStop)
+	api.POST("/run/stop-at", s.handleStopAtTask)
+	api.GET("/run/logs.zip", s.handleRunLogsArchive)
+	api.POST("/ui/close-flow", s.handleCloseFlow)
+	api.GET("/ui/layout", s.handleGetLayout)
+	api.POST("/ui/layout", s.handleSaveLayout)
+	api.DELETE("/ui/layout", s.handleDeleteLayout)
 
 	if handler := s.staticFileHandler(); handler != nil {
 		s.engine.NoRoute(handler)
 		return
 	}
 
-	s.engine.NoRoute(notFoundResponse)
+	s.engine.NoRoute(s.notFoundResponse)
 }
 
 func (s *Server) staticFileHandler() gin.HandlerFunc {
@@ -149,7 +244,7 @@ func (s *Server) staticFileHandler() gin.HandlerFunc {
 
 	serveIndex := func(c *gin.Context) {
 		if _, err := os.Stat(indexPath); err != nil {
-			notFoundResponse(c)
+			s.notFoundResponse(c)
 			return
 		}
 		c.Request.URL.Path = "/"
@@ -158,7 +253,7 @@ func (s *Server) staticFileHandler() gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		if strings.HasPrefix(c.Request.URL.Path, "/api") {
-			notFoundResponse(c)
+			s.notFoundResponse(c)
 			return
 		}
 
@@ -175,7 +270,7 @@ func (s *Server) staticFileHandler() gin.HandlerFunc {
 		}
 
 		if looksLikeAsset(requestPath) {
-			notFoundResponse(c)
+			s.notFoundResponse(c)
 			return
 		}
 
@@ -207,8 +302,8 @@ func normalizeRequestPath(p string) string {
 	return p
 }
 
-func notFoundResponse(c *gin.Context) {
-	c.JSON(http.StatusNotFound, gin.H{"error": "resource not found"})
+func (s *Server) notFoundResponse(c *gin.Context) {
+	s.errorJSON(c, http.StatusNotFound, msgResourceNotFound)
 }
 
 func (s *Server) Handle() http.Handler {
@@ -224,7 +319,7 @@ func (s *Server) handleFlow(c *gin.Context) {
 
 	definition, err := flow.LoadDefinition(path)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -236,7 +331,7 @@ func (s *Server) handleFlow(c *gin.Context) {
 func (s *Server) handleFlows(c *gin.Context) {
 	flows, err := s.discoverAvailableFlows()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -251,25 +346,25 @@ func (s *Server) handleOpenFlow(c *gin.Context) {
 		SourceName string `json:"sourceName"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid payload: %v", err)})
+		s.errorJSON(c, http.StatusBadRequest, msgInvalidPayload, err)
 		return
 	}
 
 	sourceName := strings.TrimSpace(req.SourceName)
 	if sourceName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "sourceName is required"})
+		s.errorJSON(c, http.StatusBadRequest, msgSourceNameRequired)
 		return
 	}
 
 	resolvedPath, err := s.resolveFlowPathFromSourceName(sourceName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
 	definition, err := flow.LoadDefinition(resolvedPath)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -282,7 +377,7 @@ func (s *Server) handleOpenFlow(c *gin.Context) {
 func (s *Server) handleFlowNotes(c *gin.Context) {
 	flowPath := s.activeFlowPath()
 	if strings.TrimSpace(flowPath) == "" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "no flow is currently loaded"})
+		s.errorJSON(c, http.StatusNotFound, msgNoFlowLoaded)
 		return
 	}
 
@@ -296,13 +391,13 @@ func (s *Server) handleFlowNotes(c *gin.Context) {
 		if s.tryNotesFromUploadedName(c) {
 			return
 		}
-		c.JSON(http.StatusNotFound, gin.H{"error": "no notes are available"})
+		s.errorJSON(c, http.StatusNotFound, msgNoNotes)
 		return
 	}
 
 	activeDef, err := flow.LoadDefinition(flowPath)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "no notes are available"})
+		s.errorJSON(c, http.StatusNotFound, msgNoNotes)
 		return
 	}
 
@@ -311,7 +406,7 @@ func (s *Server) handleFlowNotes(c *gin.Context) {
 		if s.tryNotesFromUploadedName(c) {
 			return
 		}
-		c.JSON(http.StatusNotFound, gin.H{"error": "no notes are available"})
+		s.errorJSON(c, http.StatusNotFound, msgNoNotes)
 		return
 	}
 
@@ -320,7 +415,7 @@ func (s *Server) handleFlowNotes(c *gin.Context) {
 		if s.tryNotesFromUploadedName(c) {
 			return
 		}
-		c.JSON(http.StatusNotFound, gin.H{"error": "no notes are available"})
+		s.errorJSON(c, http.StatusNotFound, msgNoNotes)
 		return
 	}
 }
@@ -333,14 +428,14 @@ func (s *Server) respondFlowNotes(c *gin.Context, notesPath string) bool {
 
 	file, err := os.Open(notesPath)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return true
 	}
 	defer file.Close()
 
 	data, err := io.ReadAll(io.LimitReader(file, maxFlowNotesSize))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return true
 	}
 
@@ -372,13 +467,13 @@ func (s *Server) tryNotesFromUploadedName(c *gin.Context) bool {
 func (s *Server) handleSchema(c *gin.Context) {
 	data, err := flow.CombinedSchema()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	var payload map[string]any
 	if err := json.Unmarshal(data, &payload); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -388,7 +483,7 @@ func (s *Server) handleSchema(c *gin.Context) {
 func (s *Server) handleActionsGuide(c *gin.Context) {
 	guide, err := actionhelp.BuildGuide()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -401,22 +496,59 @@ func (s *Server) handleActionsGuide(c *gin.Context) {
 	})
 }
 
+func (s *Server) handleActionDocumentation(c *gin.Context) {
+	name := c.Param("name")
+	doc, err := actionhelp.BuildDocumentation(name)
+	if err != nil {
+		var lookupErr actionhelp.LookupError
+		if errors.As(err, &lookupErr) {
+			s.errorJSON(c, http.StatusNotFound, msgUnknownAction, name)
+			return
+		}
+		s.errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
 func (s *Server) handleOpenAPI(c *gin.Context) {
 	c.Data(http.StatusOK, "application/json; charset=utf-8", OpenAPISpecJSON)
 }
 
 func (s *Server) handleEvents(c *gin.Context) {
 	if s.cfg.Hub == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "event stream is not available"})
+		s.errorJSON(c, http.StatusServiceUnavailable, msgEventStreamUnavailable)
+		return
+	}
+
+	lastID, err := lastEventID(c)
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
+	stream, cancel, err := s.cfg.Hub.SubscribeAfter(lastID)
+	if err != nil {
+		s.errorJSON(c, http.StatusServiceUnavailable, msgTooManySubscribers)
+		return
+	}
+	defer cancel()
+
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Connection", "keep-alive")
-
-	stream, cancel := s.cfg.Hub.Subscribe()
-	defer cancel()
+	// Send the headers now so clients see the stream open before the
+	// first event.
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	// Every write gets a deadline, so a client that stopped reading ends
+	// the stream instead of holding the subscription forever. Heartbeats
+	// make that happen even while no events are published.
+	rc := http.NewResponseController(c.Writer)
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
 
 	c.Stream(func(w io.Writer) bool {
 		select {
@@ -424,17 +556,47 @@ func (s *Server) handleEvents(c *gin.Context) {
 			if !ok {
 				return false
 			}
-			c.SSEvent(string(evt.Type), evt)
-			return true
+			_ = rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+			// The id field makes a reconnecting EventSource send
+			// Last-Event-ID, so the stream resumes after the last event
+			// the browser received.
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatUint(evt.ID, 10),
+				Event: string(evt.Type),
+				Data:  evt,
+			})
+			return !c.IsAborted()
+		case <-heartbeat.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+			_, err := io.WriteString(w, ": keepalive\n\n")
+			return err == nil
 		case <-c.Request.Context().Done():
 			return false
 		}
 	})
 }
 
+// lastEventID reads the ID of the last event a client received, from the
+// Last-Event-ID header that EventSource sends on reconnect or from the
+// lastEventId query parameter. Zero means replay everything.
+func lastEventID(c *gin.Context) (uint64, error) {
+	raw := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(c.Query("lastEventId"))
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, newAPIError(msgInvalidLastEventID, raw)
+	}
+	return id, nil
+}
+
 func (s *Server) handleRun(c *gin.Context) {
 	if s.runner == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "flow runner is not available"})
+		s.errorJSON(c, http.StatusServiceUnavailable, msgRunnerUnavailable)
 		return
 	}
 
@@ -449,7 +611,7 @@ func (s *Server) handleRun(c *gin.Context) {
 	var req runRequest
 	var opts *RunOptions
 	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid payload: %v", err)})
+		s.errorJSON(c, http.StatusBadRequest, msgInvalidPayload, err)
 		return
 	} else if err == nil {
 		candidate := RunOptions{
@@ -467,30 +629,30 @@ func (s *Server) handleRun(c *gin.Context) {
 
 	if err := s.runner.Trigger(opts); err != nil {
 		if errors.Is(err, ErrRunInProgress) {
-			c.JSON(http.StatusConflict, gin.H{"error": "flow execution already in progress"})
+			s.errorJSON(c, http.StatusConflict, msgRunInProgress)
 			return
 		}
 		if errors.Is(err, ErrNoRunState) {
-			c.JSON(http.StatusConflict, gin.H{"error": "no previous run state is available to resume"})
+			s.errorJSON(c, http.StatusConflict, msgNoRunState)
 			return
 		}
 		if errors.Is(err, ErrResumeConflict) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "resume request cannot be combined with other options"})
+			s.errorJSON(c, http.StatusBadRequest, msgResumeConflict)
 			return
 		}
 		if errors.Is(err, ErrResumeTaskNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "the requested task was not executed previously"})
+			s.errorJSON(c, http.StatusBadRequest, msgResumeTaskNotFound)
 			return
 		}
 		if errors.Is(err, ErrResumeTaskNotCompleted) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "the requested task has not finished yet"})
+			s.errorJSON(c, http.StatusBadRequest, msgResumeTaskNotCompleted)
 			return
 		}
 		if errors.Is(err, ErrFlowPathRequired) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "no flow is ready to run yet"})
+			s.errorJSON(c, http.StatusBadRequest, msgNoFlowReady)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -499,16 +661,16 @@ func (s *Server) handleRun(c *gin.Context) {
 
 func (s *Server) handleStop(c *gin.Context) {
 	if s.runner == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "flow runner is not available"})
+		s.errorJSON(c, http.StatusServiceUnavailable, msgRunnerUnavailable)
 		return
 	}
 
 	if err := s.runner.RequestStop(); err != nil {
 		if errors.Is(err, ErrNoRunInProgress) {
-			c.JSON(http.StatusConflict, gin.H{"error": "no execution is currently in progress"})
+			s.errorJSON(c, http.StatusConflict, msgNoRunInProgress)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -517,7 +679,7 @@ func (s *Server) handleStop(c *gin.Context) {
 
 func (s *Server) handleStopAtTask(c *gin.Context) {
 	if s.runner == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "flow runner is not available"})
+		s.errorJSON(c, http.StatusServiceUnavailable, msgRunnerUnavailable)
 		return
 	}
 
@@ -527,12 +689,12 @@ func (s *Server) handleStopAtTask(c *gin.Context) {
 
 	var req stopAtRequest
 	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid payload: %v", err)})
+		s.errorJSON(c, http.StatusBadRequest, msgInvalidPayload, err)
 		return
 	}
 
 	if err := s.runner.SetStopAtTask(strings.TrimSpace(req.TaskID)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -541,7 +703,7 @@ func (s *Server) handleStopAtTask(c *gin.Context) {
 
 func (s *Server) handleCloseFlow(c *gin.Context) {
 	if s.cfg.Hub == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "event stream is not available"})
+		s.errorJSON(c, http.StatusServiceUnavailable, msgEventStreamUnavailable)
 		return
 	}
 
@@ -575,19 +737,19 @@ func (s *Server) handleCloseFlow(c *gin.Context) {
 func (s *Server) handleImportFlow(c *gin.Context) {
 	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxFlowUploadSize))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("could not read flow: %v", err)})
+		s.errorJSON(c, http.StatusBadRequest, msgReadFlowFailed, err)
 		return
 	}
 
 	if len(bytes.TrimSpace(payload)) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "flow file is empty"})
+		s.errorJSON(c, http.StatusBadRequest, msgFlowFileEmpty)
 		return
 	}
 
 	uploadName := filepath.Base(strings.TrimSpace(c.GetHeader("X-Flow-Filename")))
 	path, def, err := s.storeFlowDefinition(payload)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		s.errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -655,12 +817,12 @@ func (s *Server) setActiveFlowPath(path string, fromUpload bool, uploadName stri
 func (s *Server) resolveFlowPathFromSourceName(sourceName string) (string, error) {
 	root := strings.TrimSpace(s.flowRootDir)
 	if root == "" {
-		return "", errors.New("flow root directory is not configured")
+		return "", newAPIError(msgFlowRootNotConfigured)
 	}
 
 	cleanSource := filepath.Clean(filepath.FromSlash(sourceName))
 	if filepath.IsAbs(cleanSource) {
-		return "", fmt.Errorf("sourceName %q must be a relative path", sourceName)
+		return "", newAPIError(msgSourceNameNotRelative, sourceName)
 	}
 
 	target := filepath.Join(root, cleanSource)
@@ -671,12 +833,12 @@ func (s *Server) resolveFlowPathFromSourceName(sourceName string) (string, error
 	info, err := os.Stat(target)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("flow %q was not found", sourceName)
+			return "", newAPIError(msgFlowNotFound, sourceName)
 		}
-		return "", fmt.Errorf("could not access flow %q: %w", sourceName, err)
+		return "", newAPIError(msgFlowAccessFailed, sourceName, err)
 	}
 	if info.IsDir() {
-		return "", fmt.Errorf("flow %q is a directory", sourceName)
+		return "", newAPIError(msgFlowIsDirectory, sourceName)
 	}
 
 	return target, nil
@@ -685,7 +847,7 @@ func (s *Server) resolveFlowPathFromSourceName(sourceName string) (string, error
 func (s *Server) discoverAvailableFlows() ([]FlowResponse, error) {
 	root := strings.TrimSpace(s.flowRootDir)
 	if root == "" {
-		return nil, errors.New("flow root directory is not configured")
+		return nil, newAPIError(msgFlowRootNotConfigured)
 	}
 
 	info, err := os.Stat(root)
@@ -693,10 +855,10 @@ func (s *Server) discoverAvailableFlows() ([]FlowResponse, error) {
 		if errors.Is(err, os.ErrNotExist) {
 			return []FlowResponse{}, nil
 		}
-		return nil, fmt.Errorf("reading flow root directory %q: %w", root, err)
+		return nil, newAPIError(msgFlowRootUnreadable, root, err)
 	}
 	if !info.IsDir() {
-		return nil, fmt.Errorf("flow root directory %q is not a directory", root)
+		return nil, newAPIError(msgFlowRootNotDirectory, root)
 	}
 
 	type discoveredFlow struct {
@@ -749,7 +911,7 @@ func (s *Server) discoverAvailableFlows() ([]FlowResponse, error) {
 		return nil
 	})
 	if walkErr != nil {
-		return nil, fmt.Errorf("walking flow root directory %q: %w", root, walkErr)
+		return nil, newAPIError(msgFlowRootUnreadable, root, walkErr)
 	}
 
 	for _, item := range discovered {
@@ -799,27 +961,27 @@ func (s *Server) withSourceMetadata(response FlowResponse, absPath string) FlowR
 
 func (s *Server) storeFlowDefinition(data []byte) (string, *flow.Definition, error) {
 	if len(data) == 0 {
-		return "", nil, errors.New("flow file is empty")
+		return "", nil, newAPIError(msgFlowFileEmpty)
 	}
 
 	if err := os.MkdirAll(s.uploadDir, 0o755); err != nil {
-		return "", nil, fmt.Errorf("could not prepare flow upload directory: %w", err)
+		return "", nil, newAPIError(msgUploadDirFailed, err)
 	}
 
 	file, err := os.CreateTemp(s.uploadDir, "flow-*.json")
 	if err != nil {
-		return "", nil, fmt.Errorf("could not store flow: %w", err)
+		return "", nil, newAPIError(msgStoreFlowFailed, err)
 	}
 	name := file.Name()
 
 	if _, err := file.Write(data); err != nil {
 		file.Close()
 		_ = os.Remove(name)
-		return "", nil, fmt.Errorf("could not store flow: %w", err)
+		return "", nil, newAPIError(msgStoreFlowFailed, err)
 	}
 	if err := file.Close(); err != nil {
 		_ = os.Remove(name)
-		return "", nil, fmt.Errorf("could not store flow: %w", err)
+		return "", nil, newAPIError(msgStoreFlowFailed, err)
 	}
 
 	if err := s.populateUploadedImports(name, data); err != nil {
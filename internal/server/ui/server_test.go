@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	_ "flowk/internal/app"
+	actionhelp "flowk/internal/cli/actionhelp"
 )
 
 func TestStoreFlowDefinitionCopiesImports(t *testing.T) {
@@ -350,6 +351,38 @@ func TestActionsGuideEndpoint(t *testing.T) {
 	}
 }
 
+func TestActionDocumentationEndpoint(t *testing.T) {
+	srv, err := NewServer(Config{
+		Address: "127.0.0.1:0",
+	})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/actions/print", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	var doc actionhelp.ActionDocumentation
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !strings.EqualFold(doc.Name, "print") || len(doc.Required) == 0 {
+		t.Fatalf("unexpected documentation: %+v", doc)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handle().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/actions/does-not-exist", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for unknown action, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "does-not-exist") {
+		t.Fatalf("expected error to name the action, got %s", rec.Body.String())
+	}
+}
+
 func TestOpenAPIEndpoint(t *testing.T) {
 	srv, err := NewServer(Config{
 		Address: "127.0.0.1:0",
@@ -16,8 +16,11 @@ import (
 // Variable describes a runtime value consumed during expansion operations.
 type Variable = variables.Variable
 
+// WarnFunc receives non-fatal expansion warnings, such as from.task references
+// to result paths that do not exist. A nil WarnFunc discards them.
+type WarnFunc = func(format string, args ...any)
+
 var (
-	variablePattern    = regexp.MustCompile(`\$\{([^{}]+)\}`)
 	rawVariablePattern = regexp.MustCompile(`^\$\{\s*([A-Za-z0-9_.-]+)\s*\}$`)
 
 	secretResolverMu sync.RWMutex
@@ -31,7 +34,7 @@ func SetSecretResolver(resolver secrets.Resolver) {
 	secretResolver = resolver
 }
 
-func ExpandTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []flow.Task) (json.RawMessage, error) {
+func ExpandTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc) (json.RawMessage, error) {
 	if len(raw) == 0 {
 		return raw, nil
 	}
@@ -41,7 +44,7 @@ func ExpandTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []fl
 		return nil, fmt.Errorf("decoding task payload for expansion: %w", err)
 	}
 
-	expanded, err := expandVarsWithTasks(decoded, vars, tasks)
+	expanded, err := expandVarsWithTasks(decoded, vars, tasks, warnf)
 	if err != nil {
 		return nil, err
 	}
@@ -59,59 +62,41 @@ func ExpandTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []fl
 // with the execution context specific to each branch. This prevents placeholders
 // that reference iteration variables from failing during the initial expansion
 // phase.
-func ExpandParallelTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []flow.Task) (json.RawMessage, error) {
-	if len(raw) == 0 {
-		return raw, nil
-	}
-
-	var payload map[string]any
-	if err := json.Unmarshal(raw, &payload); err != nil {
-		return nil, fmt.Errorf("decoding parallel task payload for expansion: %w", err)
-	}
-
-	nestedTasks, hasTasks := payload["tasks"]
-	if hasTasks {
-		delete(payload, "tasks")
-	}
-
-	expandedAny, err := expandVarsWithTasks(payload, vars, tasks)
-	if err != nil {
-		return nil, err
-	}
-
-	expanded, ok := expandedAny.(map[string]any)
-	if !ok {
-		expanded = make(map[string]any)
-	}
-
-	if hasTasks {
-		expanded["tasks"] = nestedTasks
-	}
+func ExpandParallelTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc) (json.RawMessage, error) {
+	return expandTaskPayloadPreserving(raw, vars, tasks, warnf, "parallel", "tasks")
+}
 
-	data, err := json.Marshal(expanded)
-	if err != nil {
-		return nil, fmt.Errorf("encoding expanded parallel task payload: %w", err)
-	}
+func ExpandEvaluateTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc) (json.RawMessage, error) {
+	return expandTaskPayloadPreserving(raw, vars, tasks, warnf, "evaluate", "if_conditions")
+}
 
-	return json.RawMessage(data), nil
+// ExpandWaitUntilTaskPayload leaves the conditions and the check task of a
+// WAIT_UNTIL payload untouched so they are resolved again on every poll.
+func ExpandWaitUntilTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc) (json.RawMessage, error) {
+	return expandTaskPayloadPreserving(raw, vars, tasks, warnf, "wait_until", "conditions", "check")
 }
 
-func ExpandEvaluateTaskPayload(raw json.RawMessage, vars map[string]Variable, tasks []flow.Task) (json.RawMessage, error) {
+// expandTaskPayloadPreserving expands every top-level property of the payload
+// except keys, which are copied as they are.
+func expandTaskPayloadPreserving(raw json.RawMessage, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc, kind string, keys ...string) (json.RawMessage, error) {
 	if len(raw) == 0 {
 		return raw, nil
 	}
 
 	var payload map[string]any
 	if err := json.Unmarshal(raw, &payload); err != nil {
-		return nil, fmt.Errorf("decoding evaluate task payload for expansion: %w", err)
+		return nil, fmt.Errorf("decoding %s task payload for expansion: %w", kind, err)
 	}
 
-	conditions, hasConditions := payload["if_conditions"]
-	if hasConditions {
-		delete(payload, "if_conditions")
+	preserved := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if value, ok := payload[key]; ok {
+			preserved[key] = value
+			delete(payload, key)
+		}
 	}
 
-	expandedAny, err := expandVarsWithTasks(payload, vars, tasks)
+	expandedAny, err := expandVarsWithTasks(payload, vars, tasks, warnf)
 	if err != nil {
 		return nil, err
 	}
@@ -121,37 +106,37 @@ func ExpandEvaluateTaskPayload(raw json.RawMessage, vars map[string]Variable, ta
 		expanded = make(map[string]any)
 	}
 
-	if hasConditions {
-		expanded["if_conditions"] = conditions
+	for key, value := range preserved {
+		expanded[key] = value
 	}
 
 	data, err := json.Marshal(expanded)
 	if err != nil {
-		return nil, fmt.Errorf("encoding expanded evaluate task payload: %w", err)
+		return nil, fmt.Errorf("encoding expanded %s task payload: %w", kind, err)
 	}
 
 	return json.RawMessage(data), nil
 }
 
 func expandVars(value any, vars map[string]Variable) (any, error) {
-	return expandVarsWithStack(value, vars, nil, nil)
+	return expandVarsWithStack(value, vars, nil, nil, nil)
 }
 
 // ExpandValue walks the provided value performing variable interpolation using the supplied map.
 func ExpandValue(value any, vars map[string]Variable) (any, error) {
-	return expandVarsWithStack(value, vars, nil, nil)
+	return expandVarsWithStack(value, vars, nil, nil, nil)
 }
 
-func expandVarsWithTasks(value any, vars map[string]Variable, tasks []flow.Task) (any, error) {
-	return expandVarsWithStack(value, vars, tasks, nil)
+func expandVarsWithTasks(value any, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc) (any, error) {
+	return expandVarsWithStack(value, vars, tasks, warnf, nil)
 }
 
-func expandVarsWithStack(value any, vars map[string]Variable, tasks []flow.Task, stack map[string]struct{}) (any, error) {
+func expandVarsWithStack(value any, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc, stack map[string]struct{}) (any, error) {
 	switch v := value.(type) {
 	case map[string]any:
 		expanded := make(map[string]any, len(v))
 		for key, val := range v {
-			expandedVal, err := expandVarsWithStack(val, vars, tasks, stack)
+			expandedVal, err := expandVarsWithStack(val, vars, tasks, warnf, stack)
 			if err != nil {
 				return nil, err
 			}
@@ -161,7 +146,7 @@ func expandVarsWithStack(value any, vars map[string]Variable, tasks []flow.Task,
 	case []any:
 		expanded := make([]any, len(v))
 		for i, item := range v {
-			expandedVal, err := expandVarsWithStack(item, vars, tasks, stack)
+			expandedVal, err := expandVarsWithStack(item, vars, tasks, warnf, stack)
 			if err != nil {
 				return nil, err
 			}
@@ -169,43 +154,31 @@ func expandVarsWithStack(value any, vars map[string]Variable, tasks []flow.Task,
 		}
 		return expanded, nil
 	case string:
-		return expandStringValueWithStack(v, vars, tasks, stack)
+		return expandStringValueWithStack(v, vars, tasks, warnf, stack)
 	default:
 		return value, nil
 	}
 }
 
 func ExpandStringValue(value string, vars map[string]Variable) (any, error) {
-	return expandStringValueWithStack(value, vars, nil, nil)
+	return expandStringValueWithStack(value, vars, nil, nil, nil)
 }
 
-func expandStringValueWithStack(value string, vars map[string]Variable, tasks []flow.Task, stack map[string]struct{}) (any, error) {
+func expandStringValueWithStack(value string, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc, stack map[string]struct{}) (any, error) {
 	if value == "" {
 		return value, nil
 	}
 
 	if matches := rawVariablePattern.FindStringSubmatch(value); len(matches) == 2 {
-		name := strings.TrimSpace(matches[1])
-		variable, ok := vars[name]
-		if !ok {
-			return nil, fmt.Errorf("variable %q is not defined", name)
-		}
-
-		if stack == nil {
-			stack = make(map[string]struct{})
-		}
-		if _, seen := stack[name]; seen {
-			return nil, fmt.Errorf("variable %q: circular reference detected", name)
-		}
+		return expandRawVariable(strings.TrimSpace(matches[1]), vars, tasks, warnf, stack)
+	}
 
-		stack[name] = struct{}{}
-		expanded, err := expandVarsWithStack(variable.Value, vars, tasks, stack)
-		delete(stack, name)
-		if err != nil {
-			return nil, fmt.Errorf("variable %q: %w", name, err)
+	// A value made of a single ${name:-...} or ${name:?...} placeholder keeps
+	// the variable's type when it is set, just like a plain ${name}.
+	if strings.HasPrefix(value, "${") && matchingBrace(value, 2) == len(value)-1 {
+		if name, op, _ := splitPlaceholder(value[2 : len(value)-1]); op != "" && variableIsSet(name, vars) {
+			return expandRawVariable(name, vars, tasks, warnf, stack)
 		}
-
-		return expanded, nil
 	}
 
 	expanded, err := expandStringWithStack(value, vars, stack)
@@ -215,46 +188,194 @@ func expandStringValueWithStack(value string, vars map[string]Variable, tasks []
 	if tasks == nil {
 		return expanded, nil
 	}
-	resolved, err := variables.ResolveTaskPlaceholders(expanded, tasks)
+	resolved, err := variables.ResolveTaskPlaceholdersWithWarnings(expanded, tasks, warnf)
 	if err != nil {
 		return nil, err
 	}
 	return resolved, nil
 }
 
+func expandRawVariable(name string, vars map[string]Variable, tasks []flow.Task, warnf WarnFunc, stack map[string]struct{}) (any, error) {
+	variable, ok := vars[name]
+	if !ok {
+		return nil, fmt.Errorf("variable %q is not defined", name)
+	}
+
+	if stack == nil {
+		stack = make(map[string]struct{})
+	}
+	if _, seen := stack[name]; seen {
+		return nil, fmt.Errorf("variable %q: circular reference detected", name)
+	}
+
+	stack[name] = struct{}{}
+	expanded, err := expandVarsWithStack(variable.Value, vars, tasks, warnf, stack)
+	delete(stack, name)
+	if err != nil {
+		return nil, fmt.Errorf("variable %q: %w", name, err)
+	}
+
+	return expanded, nil
+}
+
 func ExpandString(value string, vars map[string]Variable) (string, error) {
 	return expandStringWithStack(value, vars, nil)
 }
 
 func expandStringWithStack(value string, vars map[string]Variable, stack map[string]struct{}) (string, error) {
-	if value == "" {
+	if value == "" || !strings.Contains(value, "${") {
 		return value, nil
 	}
 
-	var expandErr error
-	replaced := variablePattern.ReplaceAllStringFunc(value, func(match string) string {
-		if expandErr != nil {
-			return ""
+	var builder strings.Builder
+	for i := 0; i < len(value); {
+		if !strings.HasPrefix(value[i:], "${") {
+			builder.WriteByte(value[i])
+			i++
+			continue
 		}
 
-		if strings.HasPrefix(match, "${") {
-			name := strings.TrimSpace(match[2 : len(match)-1])
-			if strings.HasPrefix(name, "secret:") {
-				return replaceSecret(name, &expandErr)
+		end := matchingBrace(value, i+2)
+		expr := ""
+		if end >= 0 {
+			expr = value[i+2 : end]
+		}
+		name, op, operand := splitPlaceholder(expr)
+		if end < 0 || (op == "" && strings.ContainsAny(expr, "{}")) {
+			// Not a placeholder this expander owns; keep the literal "${" so any
+			// inner placeholder is still expanded on its own.
+			builder.WriteString("${")
+			i += 2
+			continue
+		}
+
+		replaced, err := expandPlaceholder(value[i:end+1], name, op, operand, vars, stack)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(replaced)
+		i = end + 1
+	}
+
+	return builder.String(), nil
+}
+
+// VariableReferences returns the names of the variables that must be defined
+// for value to expand, in order of appearance. from.task and secret
+// placeholders are not variables, and ${name:-default} placeholders are
+// satisfied by their default, so only variables inside the default count.
+func VariableReferences(value string) []string {
+	var names []string
+	for i := 0; i < len(value); i++ {
+		if !strings.HasPrefix(value[i:], "${") {
+			continue
+		}
+		end := matchingBrace(value, i+2)
+		if end < 0 {
+			break
+		}
+		expr := value[i+2 : end]
+		name, op, operand := splitPlaceholder(expr)
+		if op == "" && strings.ContainsAny(expr, "{}") {
+			// Not a variable placeholder; inner placeholders are found as the
+			// scan continues.
+			continue
+		}
+		switch {
+		case strings.HasPrefix(name, "secret:"), strings.HasPrefix(name, "from.task:"):
+		case op == ":-":
+			names = append(names, VariableReferences(operand)...)
+		case name != "":
+			names = append(names, name)
+			names = append(names, VariableReferences(operand)...)
+		}
+		i = end
+	}
+	return names
+}
+
+// matchingBrace returns the index of the "}" closing the placeholder whose
+// body starts at start, honouring nested "${...}" placeholders.
+func matchingBrace(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
 			}
-			if strings.HasPrefix(name, "from.task:") {
-				return match
+			depth--
+		}
+	}
+	return -1
+}
+
+// splitPlaceholder separates a placeholder body into the variable name and an
+// optional ":-" (default) or ":?" (required) modifier with its operand.
+func splitPlaceholder(expr string) (name, op, operand string) {
+	for i := 0; i+1 < len(expr); i++ {
+		if expr[i] == '{' || expr[i] == '}' {
+			break
+		}
+		if expr[i] == ':' && (expr[i+1] == '-' || expr[i+1] == '?') {
+			name = strings.TrimSpace(expr[:i])
+			if name == "" || strings.Contains(name, ":") {
+				break
 			}
-			return replaceVariable(name, vars, &expandErr, stack)
+			return name, expr[i : i+2], expr[i+2:]
+		}
+	}
+	return strings.TrimSpace(expr), "", ""
+}
+
+func expandPlaceholder(match, name, op, operand string, vars map[string]Variable, stack map[string]struct{}) (string, error) {
+	var expandErr error
+	switch {
+	case strings.HasPrefix(name, "secret:"):
+		value := replaceSecret(strings.TrimSpace(match[2:len(match)-1]), &expandErr)
+		return value, expandErr
+	case strings.HasPrefix(name, "from.task:"):
+		return match, nil
+	case op == "":
+		value := replaceVariable(name, vars, &expandErr, stack)
+		return value, expandErr
+	}
+
+	if variableIsSet(name, vars) {
+		value := replaceVariable(name, vars, &expandErr, stack)
+		if expandErr != nil || value != "" {
+			return value, expandErr
 		}
+	}
 
-		return match
-	})
+	expandedOperand, err := expandStringWithStack(operand, vars, stack)
+	if err != nil {
+		return "", fmt.Errorf("variable %q: %w", name, err)
+	}
+	if op == ":-" {
+		return expandedOperand, nil
+	}
 
-	if expandErr != nil {
-		return "", expandErr
+	message := strings.TrimSpace(expandedOperand)
+	if message == "" {
+		message = "is required"
+	}
+	return "", fmt.Errorf("variable %q: %s", name, message)
+}
+
+// variableIsSet reports whether name holds a non-empty value, matching the
+// shell semantics of the ":-" and ":?" modifiers.
+func variableIsSet(name string, vars map[string]Variable) bool {
+	variable, ok := vars[name]
+	if !ok || variable.Value == nil {
+		return false
+	}
+	if str, isString := variable.Value.(string); isString && str == "" {
+		return false
 	}
-	return replaced, nil
+	return true
 }
 
 func replaceSecret(name string, errRef *error) string {
@@ -286,7 +407,7 @@ func replaceVariable(name string, vars map[string]Variable, errRef *error, stack
 	}
 
 	stack[name] = struct{}{}
-	expanded, err := expandVarsWithStack(variable.Value, vars, nil, stack)
+	expanded, err := expandVarsWithStack(variable.Value, vars, nil, nil, stack)
 	delete(stack, name)
 	if err != nil {
 		*errRef = fmt.Errorf("variable %q: %w", name, err)
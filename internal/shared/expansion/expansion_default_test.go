@@ -0,0 +1,132 @@
+package expansion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandStringDefaultModifier(t *testing.T) {
+	vars := map[string]Variable{
+		"env":      {Name: "env", Type: "string", Value: "prod"},
+		"empty":    {Name: "empty", Type: "string", Value: ""},
+		"fallback": {Name: "fallback", Type: "string", Value: "eu-west"},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "set variable wins", input: "deploy-${env:-dev}", want: "deploy-prod"},
+		{name: "unset uses default", input: "deploy-${missing:-dev}", want: "deploy-dev"},
+		{name: "empty uses default", input: "deploy-${empty:-dev}", want: "deploy-dev"},
+		{name: "empty default", input: "x${missing:-}y", want: "xy"},
+		{name: "nested default", input: "region=${missing:-${fallback}}", want: "region=eu-west"},
+		{name: "nested chain", input: "${missing:-${other:-${env}}}", want: "prod"},
+		{name: "default with spaces", input: "${missing:-hello world}", want: "hello world"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandString(tt.input, vars)
+			if err != nil {
+				t.Fatalf("ExpandString() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ExpandString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandStringRequiredModifier(t *testing.T) {
+	vars := map[string]Variable{
+		"env": {Name: "env", Type: "string", Value: "prod"},
+	}
+
+	got, err := ExpandString("${env:?env is required}", vars)
+	if err != nil {
+		t.Fatalf("ExpandString() error = %v", err)
+	}
+	if got != "prod" {
+		t.Fatalf("ExpandString() = %q, want prod", got)
+	}
+
+	_, err = ExpandString("target=${cluster:?set the cluster input}", vars)
+	if err == nil {
+		t.Fatal("expected error for unset required variable")
+	}
+	if !strings.Contains(err.Error(), `variable "cluster": set the cluster input`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = ExpandString("${cluster:?}", vars)
+	if err == nil || !strings.Contains(err.Error(), `variable "cluster": is required`) {
+		t.Fatalf("expected default required message, got %v", err)
+	}
+}
+
+func TestExpandStringValueDefaultKeepsVariableType(t *testing.T) {
+	vars := map[string]Variable{
+		"replicas": {Name: "replicas", Type: "number", Value: float64(3)},
+	}
+
+	got, err := ExpandStringValue("${replicas:-1}", vars)
+	if err != nil {
+		t.Fatalf("ExpandStringValue() error = %v", err)
+	}
+	if got != float64(3) {
+		t.Fatalf("ExpandStringValue() = %#v, want 3", got)
+	}
+
+	got, err = ExpandStringValue("${missing:-1}", vars)
+	if err != nil {
+		t.Fatalf("ExpandStringValue() error = %v", err)
+	}
+	if got != "1" {
+		t.Fatalf("ExpandStringValue() = %#v, want \"1\"", got)
+	}
+}
+
+func TestExpandStringKeepsPlainPlaceholderBehaviour(t *testing.T) {
+	vars := map[string]Variable{
+		"platform_name": {Name: "platform_name", Type: "string", Value: "cassandra"},
+	}
+
+	got, err := ExpandString("tic-${platform_name} ${from.task:prev.result} ${ unterminated", vars)
+	if err != nil {
+		t.Fatalf("ExpandString() error = %v", err)
+	}
+	if got != "tic-cassandra ${from.task:prev.result} ${ unterminated" {
+		t.Fatalf("ExpandString() = %q", got)
+	}
+
+	if _, err := ExpandString("${missing}", vars); err == nil {
+		t.Fatal("expected error for undefined variable")
+	}
+}
+
+func TestVariableReferences(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "plain text", want: nil},
+		{input: "${a} and ${ b }", want: []string{"a", "b"}},
+		{input: "${missing:-dev}", want: nil},
+		{input: "${missing:-${fallback}}", want: []string{"fallback"}},
+		{input: "${token:?set the token with ${hint}}", want: []string{"token", "hint"}},
+		{input: "${from.task:list.result.0} ${secret:vault:apps/db#password}", want: nil},
+		{input: "${from.task:list.result.${idx}}", want: []string{"idx"}},
+		{input: "unterminated ${a", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := VariableReferences(tt.input)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("VariableReferences(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -28,7 +28,7 @@ func TestExpandParallelTaskPayloadSkipsNestedTasks(t *testing.T) {
 		"merge_strategy":     {Name: "merge_strategy", Value: "last_write_wins"},
 	}
 
-	expanded, err := ExpandParallelTaskPayload(raw, vars, nil)
+	expanded, err := ExpandParallelTaskPayload(raw, vars, nil, nil)
 	if err != nil {
 		t.Fatalf("ExpandParallelTaskPayload() error = %v", err)
 	}
@@ -2,6 +2,7 @@ package runcontext
 
 import (
 	"context"
+	"errors"
 	"testing"
 )
 
@@ -67,3 +68,64 @@ func TestStopAtTaskHelpers(t *testing.T) {
 		t.Fatalf("expected empty stop-at-task id, got %q", StopAtTaskID(ctx))
 	}
 }
+
+func TestRunScopeLoadAndClose(t *testing.T) {
+	t.Parallel()
+
+	scope := NewRunScope(context.Background())
+	ctx := WithRunScope(context.Background(), scope)
+	if RunScopeFromContext(ctx) != scope {
+		t.Fatal("run scope not retrievable from context")
+	}
+
+	calls := 0
+	create := func() (any, error) {
+		calls++
+		return calls, nil
+	}
+	first, _ := scope.Load("client", create)
+	second, _ := scope.Load("client", create)
+	if first != 1 || second != 1 || calls != 1 {
+		t.Fatalf("Load() = %v, %v after %d calls, want the first value cached", first, second, calls)
+	}
+
+	failure := errors.New("boom")
+	if _, err := scope.Load("broken", func() (any, error) { return nil, failure }); !errors.Is(err, failure) {
+		t.Fatalf("Load() error = %v, want %v", err, failure)
+	}
+	if value, err := scope.Load("broken", create); err != nil || value != 2 {
+		t.Fatalf("Load() after an error = %v, %v, want a retry", value, err)
+	}
+
+	scope.Close()
+	select {
+	case <-scope.Context().Done():
+	default:
+		t.Fatal("Close must cancel the scope context")
+	}
+	if value, _ := scope.Load("client", create); value != 3 {
+		t.Fatalf("Load() after Close = %v, want a new value", value)
+	}
+}
+
+func TestNilRunScope(t *testing.T) {
+	t.Parallel()
+
+	scope := RunScopeFromContext(context.Background())
+	if scope != nil {
+		t.Fatal("expected no run scope outside a run")
+	}
+	calls := 0
+	for i := 0; i < 2; i++ {
+		if _, err := scope.Load("key", func() (any, error) { calls++; return nil, nil }); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("create called %d times, want 2 without a scope", calls)
+	}
+	if scope.Context().Err() != nil {
+		t.Fatal("nil scope context must not be cancelled")
+	}
+	scope.Close()
+}
@@ -0,0 +1,84 @@
+package runcontext
+
+import (
+	"context"
+	"sync"
+)
+
+type scopeKey struct{}
+
+// RunScope holds resources shared by the tasks of one flow run, such as API
+// clients, and releases them when the run ends.
+type RunScope struct {
+	mu     sync.Mutex
+	values map[any]any
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewRunScope creates a scope whose Context is cancelled with parent or when
+// the scope is closed.
+func NewRunScope(parent context.Context) *RunScope {
+	ctx, cancel := context.WithCancel(parent)
+	return &RunScope{values: make(map[any]any), ctx: ctx, cancel: cancel}
+}
+
+// WithRunScope stores the run scope in the context.
+func WithRunScope(ctx context.Context, scope *RunScope) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// RunScopeFromContext returns the run scope stored in the context, or nil
+// outside a run.
+func RunScopeFromContext(ctx context.Context) *RunScope {
+	if ctx == nil {
+		return nil
+	}
+	scope, _ := ctx.Value(scopeKey{}).(*RunScope)
+	return scope
+}
+
+// Load returns the value stored under key, calling create the first time.
+// Errors are not cached, so a later task retries. A nil scope calls create
+// every time.
+func (s *RunScope) Load(key any, create func() (any, error)) (any, error) {
+	if s == nil {
+		return create()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value, ok := s.values[key]; ok {
+		return value, nil
+	}
+	value, err := create()
+	if err != nil {
+		return nil, err
+	}
+	s.values[key] = value
+	return value, nil
+}
+
+// Context lives until the run ends. Work that outlasts the task that started
+// it, like a port-forward, uses it instead of the task context. A nil scope
+// returns context.Background.
+func (s *RunScope) Context() context.Context {
+	if s == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// Close cancels Context and drops the stored values.
+func (s *RunScope) Close() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	s.mu.Lock()
+	s.values = make(map[any]any)
+	s.mu.Unlock()
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracerName identifies the spans created by flowk.
+const TracerName = "flowk"
+
+// EndpointEnv is the standard OTLP endpoint variable. When set it wins over
+// Config.Endpoint.
+const EndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// DefaultServiceName is reported as service.name when Config leaves it empty.
+const DefaultServiceName = "flowk"
+
+// Config selects whether spans are exported and where to.
+type Config struct {
+	Enabled     bool
+	Endpoint    string
+	ServiceName string
+}
+
+// Setup installs the global tracer provider and returns the function that
+// flushes and stops it. When tracing is disabled a no-op provider is
+// installed, so Start returns non-recording spans and costs next to nothing.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var options []otlptracehttp.Option
+	if endpoint := strings.TrimSpace(cfg.Endpoint); endpoint != "" && strings.TrimSpace(os.Getenv(EndpointEnv)) == "" {
+		options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("building trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start opens a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err as an error event on span, marks the span failed and ends
+// it. A nil err ends the span with an unset status.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func restoreTracerProvider(t *testing.T) {
+	t.Helper()
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+}
+
+func TestSetupDisabledInstallsNoopProvider(t *testing.T) {
+	restoreTracerProvider(t)
+
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer shutdown(context.Background())
+
+	_, span := Start(context.Background(), "noop")
+	defer span.End()
+	if span.IsRecording() {
+		t.Fatal("expected a non-recording span when tracing is disabled")
+	}
+}
+
+func TestEndRecordsErrors(t *testing.T) {
+	restoreTracerProvider(t)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	_, span := Start(context.Background(), "failing")
+	End(span, errors.New("boom"))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error || spans[0].Status().Description != "boom" {
+		t.Fatalf("status = %+v, want error boom", spans[0].Status())
+	}
+	if events := spans[0].Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Fatalf("events = %+v, want one exception event", events)
+	}
+}
@@ -1,6 +1,7 @@
 import { CombinedSchema, FlowDefinition, TaskDefinition } from '../types/flow';
 import { ActionsGuide } from '../types/actionsGuide';
 import { FlowEvent } from '../types/run';
+import i18n from '../i18n/config';
 
 const API_BASE_URL = import.meta.env.VITE_API_BASE_URL ?? '';
 
@@ -11,6 +12,44 @@ const withBase = (path: string): string => {
   return `${API_BASE_URL}${path}`;
 };
 
+const API_TOKEN_STORAGE_KEY = 'flowk.apiToken';
+
+let apiToken: string | undefined;
+
+// The token is read once from the ?token= query parameter the UI was opened
+// with, kept in session storage and removed from the address bar.
+const getApiToken = (): string => {
+  if (apiToken !== undefined) {
+    return apiToken;
+  }
+  apiToken = '';
+  if (typeof window === 'undefined') {
+    return apiToken;
+  }
+  const url = new URL(window.location.href);
+  const fromQuery = url.searchParams.get('token')?.trim();
+  if (fromQuery) {
+    window.sessionStorage.setItem(API_TOKEN_STORAGE_KEY, fromQuery);
+    url.searchParams.delete('token');
+    window.history.replaceState(window.history.state, '', url.toString());
+  }
+  apiToken = window.sessionStorage.getItem(API_TOKEN_STORAGE_KEY) ?? '';
+  return apiToken;
+};
+
+// Error messages come back in the UI language when the server supports it.
+const apiFetch = (path: string, init?: RequestInit): Promise<Response> => {
+  const headers = new Headers(init?.headers);
+  if (i18n.language) {
+    headers.set('Accept-Language', i18n.language);
+  }
+  const token = getApiToken();
+  if (token) {
+    headers.set('Authorization', `Bearer ${token}`);
+  }
+  return fetch(withBase(path), { ...init, headers });
+};
+
 const parseJSON = async <T>(response: Response): Promise<T> => {
   if (!response.ok) {
     const message = await response.text();
@@ -121,7 +160,7 @@ export type AvailableFlowsResponse = {
 };
 
 export const fetchAvailableFlows = async (): Promise<AvailableFlowsResponse> => {
-  const response = await fetch(withBase('/api/flows'));
+  const response = await apiFetch('/api/flows');
   const data = await parseJSON<RawFlowListResponse>(response);
   const items = Array.isArray(data.flows) ? data.flows.filter(isRawFlowResponse) : [];
   return {
@@ -131,7 +170,7 @@ export const fetchAvailableFlows = async (): Promise<AvailableFlowsResponse> =>
 };
 
 export const fetchFlowDefinition = async (): Promise<FlowDefinition | null> => {
-  const response = await fetch(withBase('/api/flow'));
+  const response = await apiFetch('/api/flow');
   if (response.status === 404 || response.status === 204) {
     return null;
   }
@@ -140,7 +179,7 @@ export const fetchFlowDefinition = async (): Promise<FlowDefinition | null> => {
 };
 
 export const fetchFlowNotes = async (): Promise<string | null> => {
-  const response = await fetch(withBase('/api/flow/notes'));
+  const response = await apiFetch('/api/flow/notes');
   if (response.status === 404) {
     return null;
   }
@@ -149,7 +188,7 @@ export const fetchFlowNotes = async (): Promise<string | null> => {
 };
 
 export const openFlowDefinition = async (sourceName: string): Promise<FlowDefinition> => {
-  const response = await fetch(withBase('/api/flows/open'), {
+  const response = await apiFetch('/api/flows/open', {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify({ sourceName }),
@@ -159,17 +198,19 @@ export const openFlowDefinition = async (sourceName: string): Promise<FlowDefini
 };
 
 export const fetchSchema = async (): Promise<CombinedSchema> => {
-  const response = await fetch(withBase('/api/schema'));
+  const response = await apiFetch('/api/schema');
   return parseJSON<CombinedSchema>(response);
 };
 
 export const fetchActionsGuide = async (): Promise<ActionsGuide> => {
-  const response = await fetch(withBase('/api/actions/guide'));
+  const response = await apiFetch('/api/actions/guide');
   return parseJSON<ActionsGuide>(response);
 };
 
 export const createEventSource = (): EventSource => {
-  return new EventSource(withBase('/api/run/events'));
+  const token = getApiToken();
+  const query = token ? `?${new URLSearchParams({ token }).toString()}` : '';
+  return new EventSource(withBase(`/api/run/events${query}`));
 };
 
 export const fetchFlowLayout = async (
@@ -184,7 +225,7 @@ export const fetchFlowLayout = async (
   if (sourceName?.trim()) {
     params.set('sourceName', sourceName.trim());
   }
-  const response = await fetch(withBase(`/api/ui/layout?${params.toString()}`));
+  const response = await apiFetch(`/api/ui/layout?${params.toString()}`);
   if (response.status === 404) {
     return null;
   }
@@ -205,7 +246,7 @@ export const saveFlowLayout = async (
     sourceName: sourceName?.trim() || undefined,
     snapshot
   };
-  const response = await fetch(withBase('/api/ui/layout'), {
+  const response = await apiFetch('/api/ui/layout', {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify(payload)
@@ -235,7 +276,7 @@ export const deleteFlowLayout = async (flowId: string, sourceName?: string): Pro
   if (sourceName?.trim()) {
     params.set('sourceName', sourceName.trim());
   }
-  const response = await fetch(withBase(`/api/ui/layout?${params.toString()}`), {
+  const response = await apiFetch(`/api/ui/layout?${params.toString()}`, {
     method: 'DELETE'
   });
   if (response.status === 404) {
@@ -290,7 +331,7 @@ const buildRunPayload = (options?: RunRequestOptions): Record<string, string> |
 
 export const requestFlowRun = async (options?: RunRequestOptions): Promise<void> => {
   const payload = buildRunPayload(options);
-  const response = await fetch(withBase('/api/run'), {
+  const response = await apiFetch('/api/run', {
     method: 'POST',
     headers: payload ? { 'Content-Type': 'application/json' } : undefined,
     body: payload ? JSON.stringify(payload) : undefined,
@@ -312,7 +353,7 @@ export const requestFlowRun = async (options?: RunRequestOptions): Promise<void>
 };
 
 export const requestFlowStop = async (): Promise<void> => {
-  const response = await fetch(withBase('/api/run/stop'), {
+  const response = await apiFetch('/api/run/stop', {
     method: 'POST',
   });
   if (!response.ok) {
@@ -333,7 +374,7 @@ export const requestFlowStop = async (): Promise<void> => {
 
 export const requestStopAtTask = async (taskId?: string): Promise<void> => {
   const payload = { taskId: taskId?.trim() ?? '' };
-  const response = await fetch(withBase('/api/run/stop-at'), {
+  const response = await apiFetch('/api/run/stop-at', {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify(payload),
@@ -356,7 +397,7 @@ export const requestStopAtTask = async (taskId?: string): Promise<void> => {
 
 export const requestCloseFlow = async (flowId?: string): Promise<void> => {
   const payload = { flowId: flowId?.trim() ?? '' };
-  const response = await fetch(withBase('/api/ui/close-flow'), {
+  const response = await apiFetch('/api/ui/close-flow', {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify(payload),
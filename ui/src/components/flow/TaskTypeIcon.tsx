@@ -245,10 +245,16 @@ const ACTION_VARIANTS: Record<string, BadgeVariant> = {
   EVALUATE: buildVariant('diamond', '#f59e0b', '#fffbeb', 'Evaluate'),
   SLEEP: buildVariant('moon', '#6366f1', '#eef2ff', 'Sleep'),
   FOR: buildVariant('loop', '#06b6d4', '#ecfeff', 'Loop'),
+  WAIT_UNTIL: buildVariant('moon', '#0ea5e9', '#f0f9ff', 'Wait Until'),
   VARIABLES: buildVariant('code', '#3b82f6', '#eff6ff', 'Variables'),
+  TRANSFORM: buildVariant('code', '#db2777', '#fdf2f8', 'Transform'),
+  DATETIME: buildVariant('calendar', '#0f766e', '#f0fdfa', 'Datetime'),
+  RANDOM: buildVariant('code', '#9333ea', '#faf5ff', 'Random'),
+  TEMPLATE_RENDER: buildVariant('document', '#ca8a04', '#fefce8', 'Template'),
   WAIT_FOR_EVENT: buildVariant('calendar', '#8b5cf6', '#f5f3ff', 'Wait Event'),
 
   // Network / System
+  HTTP: buildVariant('arrow', '#0284c7', '#f0f9ff', 'HTTP'),
   HTTP_REQUEST: buildVariant('arrow', '#0ea5e9', '#f0f9ff', 'HTTP Request'),
   SHELL: buildVariant('terminal', '#334155', '#f8fafc', 'Shell'),
   DOCKER: buildVariant('container', '#2496ed', '#e6f3ff', 'Docker'),
@@ -262,12 +268,18 @@ const ACTION_VARIANTS: Record<string, BadgeVariant> = {
   DB_CASSANDRA_OPERATION: buildVariant('database', '#0d9488', '#f0fdfa', 'Cassandra'),
   DB_POSTGRES_OPERATION: buildVariant('database', '#2563eb', '#eff6ff', 'PostgreSQL'),
   DB_MYSQL_OPERATION: buildVariant('database', '#00758f', '#e0f7fa', 'MySQL'),
+  SQL: buildVariant('database', '#4f46e5', '#eef2ff', 'SQL'),
+  REDIS: buildVariant('database', '#dc2626', '#fef2f2', 'Redis'),
   BASE64: buildVariant('file', '#b45309', '#fffbeb', 'Base64'),
+  FILE: buildVariant('document', '#a16207', '#fefce8', 'File'),
+  ARCHIVE: buildVariant('file', '#7c2d12', '#fff7ed', 'Archive'),
+  CHECKSUM: buildVariant('shield', '#15803d', '#f0fdf4', 'Checksum'),
   PGP: buildVariant('shield', '#dc2626', '#fef2f2', 'PGP'),
   OAUTH2: buildVariant('key', '#f59e0b', '#fffbeb', 'OAuth2'),
 
   // Communications / Integrations
   GMAIL: buildVariant('mail', '#ea4335', '#fef2f2', 'Gmail'),
+  EMAIL: buildVariant('mail', '#0891b2', '#ecfeff', 'Email'),
   SLACK: buildVariant('slack', '#4a154b', '#fdf4ff', 'Slack'),
   TELEGRAM: buildVariant('telegram', '#2aabee', '#f0f9ff', 'Telegram'),
   SEND_MESSAGE: buildVariant('bell', '#ec4899', '#fdf2f8', 'Notify'),
@@ -35,17 +35,26 @@ const ACTION_CATEGORY_ORDER: ActionCategory[] = [
 const ACTION_CATEGORY_MAP: Record<string, ActionCategory> = {
   GMAIL: 'auth',
   OAUTH2: 'auth',
+  DATETIME: 'core',
   EVALUATE: 'core',
   FOR: 'core',
   PARALLEL: 'core',
   PRINT: 'core',
+  RANDOM: 'core',
   SLEEP: 'core',
+  TEMPLATE_RENDER: 'core',
+  TRANSFORM: 'core',
   VARIABLES: 'core',
+  WAIT_UNTIL: 'core',
   DB_CASSANDRA_OPERATION: 'db',
   DB_MYSQL_OPERATION: 'db',
   DB_POSTGRES_OPERATION: 'db',
+  REDIS: 'db',
+  SQL: 'db',
   KUBERNETES: 'infra',
   HELM: 'infra',
+  EMAIL: 'network',
+  HTTP: 'network',
   HTTP_REQUEST: 'network',
   SSH: 'network',
   TELNET: 'network',
@@ -54,6 +63,9 @@ const ACTION_CATEGORY_MAP: Record<string, ActionCategory> = {
   SHELL: 'system',
   DOCKER: 'system',
   BASE64: 'system',
+  FILE: 'system',
+  ARCHIVE: 'system',
+  CHECKSUM: 'system',
   SECRET_PROVIDER_VAULT: 'system'
 };
 
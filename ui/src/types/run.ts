@@ -22,6 +22,7 @@ export interface TaskSnapshot {
 }
 
 export interface FlowEvent {
+  id?: number;
   type: FlowEventType;
   timestamp: string;
   flowId?: string;